@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"path"
+	"regexp"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/app"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
+	"github.com/gin-gonic/gin"
+)
+
+// runMigrate applies, rolls back or lists the goose migrations.
+func runMigrate(args []string) int {
+	flags := newFlagSet("migrate", "[up|down|status]")
+	dir := flags.String("dir", "migrations", "directory holding the goose SQL migrations")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	direction := "up"
+	if flags.NArg() > 0 {
+		direction = flags.Arg(0)
+	}
+	if flags.NArg() > 1 || (direction != "up" && direction != "down" && direction != "status") {
+		flags.Usage()
+		return exitUsage
+	}
+
+	cfg, code := loadConfig()
+	if cfg == nil {
+		return code
+	}
+	defer cfg.Close()
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return exitUnavailable
+	}
+	defer database.Close(db)
+
+	migrator, err := database.NewMigrator(db, *dir)
+	if err != nil {
+		log.Printf("Failed to prepare migrations: %v", err)
+		return exitFailure
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch direction {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %s\n", m.Name)
+		}
+		if err != nil {
+			log.Printf("Migration failed: %v", err)
+			return exitFailure
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		m, err := migrator.Down(ctx)
+		if err != nil {
+			log.Printf("Rollback failed: %v", err)
+			return exitFailure
+		}
+		fmt.Printf("rolled back %s\n", m.Name)
+	case "status":
+		migrations, err := migrator.Status(ctx)
+		if err != nil {
+			log.Printf("Failed to read migration status: %v", err)
+			return exitFailure
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "APPLIED AT\tMIGRATION")
+		for _, m := range migrations {
+			appliedAt := "pending"
+			if m.AppliedAt != nil {
+				appliedAt = m.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\n", appliedAt, m.Name)
+		}
+		w.Flush()
+	}
+
+	return exitOK
+}
+
+// runSeed creates the built-in roles and, with -admin-email, an admin
+// account. Existing rows are left alone.
+func runSeed(args []string) int {
+	flags := newFlagSet("seed", "[-admin-email email]")
+	email := flags.String("admin-email", "", "create an admin account with this email")
+	name := flags.String("admin-name", "System Admin", "name of the admin account")
+	password := flags.String("admin-password", os.Getenv("SEED_ADMIN_PASSWORD"), "password of the admin account (default $SEED_ADMIN_PASSWORD)")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() > 0 || (*email != "" && *password == "") {
+		flags.Usage()
+		return exitUsage
+	}
+
+	cfg, code := loadConfig()
+	if cfg == nil {
+		return code
+	}
+	defer cfg.Close()
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return exitUnavailable
+	}
+	defer database.Close(db)
+
+	var admin *app.SeedAdmin
+	if *email != "" {
+		admin = &app.SeedAdmin{Email: *email, Password: *password, Name: *name}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := app.Seed(ctx, db, admin)
+	if err != nil {
+		log.Printf("Seeding failed: %v", err)
+		return exitFailure
+	}
+
+	fmt.Printf("roles created: %d\n", result.RolesCreated)
+	if admin != nil {
+		if result.AdminCreated {
+			fmt.Printf("admin %s created\n", admin.Email)
+		} else {
+			fmt.Printf("admin %s already exists\n", admin.Email)
+		}
+	}
+	return exitOK
+}
+
+// runConfig validates or prints the configuration without connecting to
+// anything.
+func runConfig(args []string) int {
+	flags := newFlagSet("config", "validate|print")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() != 1 || (flags.Arg(0) != "validate" && flags.Arg(0) != "print") {
+		flags.Usage()
+		return exitUsage
+	}
+
+	cfg, code := loadConfig()
+	if cfg == nil {
+		return code
+	}
+	defer cfg.Close()
+
+	if flags.Arg(0) == "print" {
+		out, err := json.MarshalIndent(cfg.MaskSensitive().AsMap(), "", "  ")
+		if err != nil {
+			log.Printf("Failed to encode configuration: %v", err)
+			return exitFailure
+		}
+		fmt.Println(string(out))
+		return exitOK
+	}
+
+	// Building the application offline also checks what the struct rules
+	// cannot, such as JWT keys, mail templates and feature flags
+	a, code := buildOffline()
+	if a == nil {
+		return code
+	}
+	closeOffline(a)
+
+	fmt.Println("configuration is valid")
+	return exitOK
+}
+
+// routeInfo is one row of the routes table. Middleware lists what runs
+// before Handler beyond the middleware every route shares.
+type routeInfo struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"`
+}
+
+// runRoutes prints every registered route with its middleware chain. The
+// router is built offline and each route's chain is read by sending it a
+// request that a leading middleware aborts before anything else runs.
+func runRoutes(args []string) int {
+	flags := newFlagSet("routes", "[-json]")
+	asJSON := flags.Bool("json", false, "print the table as JSON")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var chain []string
+	capture := func(c *gin.Context) {
+		chain = c.HandlerNames()
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+
+	a, code := buildOffline(capture)
+	if a == nil {
+		return code
+	}
+	defer closeOffline(a)
+
+	shared := len(a.Router.Handlers)
+	var global []string
+	routes := make([]routeInfo, 0, len(a.Router.Routes()))
+	for _, r := range a.Router.Routes() {
+		chain = nil
+		a.Router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r.Method, r.Path, nil))
+		if len(chain) <= shared {
+			log.Printf("Could not resolve the handler chain of %s %s", r.Method, r.Path)
+			return exitFailure
+		}
+
+		if global == nil {
+			for _, name := range chain[1:shared] {
+				global = append(global, shortFuncName(name))
+			}
+		}
+		middleware := []string{}
+		for _, name := range chain[shared : len(chain)-1] {
+			middleware = append(middleware, shortFuncName(name))
+		}
+		routes = append(routes, routeInfo{
+			Method:     r.Method,
+			Path:       r.Path,
+			Handler:    shortFuncName(r.Handler),
+			Middleware: middleware,
+		})
+	}
+
+	if *asJSON {
+		out, err := json.MarshalIndent(map[string]any{"middleware": global, "routes": routes}, "", "  ")
+		if err != nil {
+			log.Printf("Failed to encode routes: %v", err)
+			return exitFailure
+		}
+		fmt.Println(string(out))
+		return exitOK
+	}
+
+	fmt.Printf("Every route: %s\n\n", strings.Join(global, " > "))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARE")
+	for _, r := range routes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Method, r.Path, r.Handler, strings.Join(r.Middleware, " > "))
+	}
+	w.Flush()
+	return exitOK
+}
+
+// buildOffline loads the configuration and wires the application without
+// contacting any backing service. On failure a is nil and code is the exit
+// code to report.
+func buildOffline(middleware ...gin.HandlerFunc) (a *app.App, code int) {
+	cfg, code := loadConfig()
+	if cfg == nil {
+		return nil, code
+	}
+
+	// Keep gin's route debug output off stdout
+	gin.SetMode(gin.ReleaseMode)
+
+	a, err := app.New(cfg, app.Options{Offline: true, Middleware: middleware})
+	if err != nil {
+		cfg.Close()
+		log.Printf("Invalid configuration: %v", err)
+		return nil, exitConfig
+	}
+	return a, exitOK
+}
+
+func closeOffline(a *app.App) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.Close(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Failed to close: %v", err)
+	}
+	a.Config.Close()
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// shortFuncName trims a function name as reported by the runtime, such as
+// "github.com/.../middleware.RequireRole.func1", to "middleware.RequireRole".
+func shortFuncName(name string) string {
+	name = strings.TrimSuffix(path.Base(name), "-fm")
+	return closureSuffix.ReplaceAllString(name, "")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/netutil"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// listen opens the listener configured by server.listen. A unix socket
+// left behind by an earlier run is removed first; closing the listener,
+// which graceful shutdown does, removes the socket file again.
+func listen(cfg config.ServerConfig) (net.Listener, error) {
+	network, address := cfg.ListenAddress()
+	if network == "unix" {
+		if err := removeStaleSocket(address); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == "unix" && cfg.SocketMode != "" {
+		mode, err := strconv.ParseUint(cfg.SocketMode, 8, 32)
+		if err == nil {
+			err = os.Chmod(address, fs.FileMode(mode))
+		}
+		if err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("failed to set socket mode: %w", err)
+		}
+	}
+
+	if n := cfg.MaxConnections; n > 0 {
+		ln = netutil.LimitListener(ln, n)
+	}
+	return ln, nil
+}
+
+// removeStaleSocket deletes the socket file at path unless another process
+// is still serving on it. Anything other than a socket is left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
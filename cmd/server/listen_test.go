@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// socketPath returns a path for a unix socket, short enough for the
+// platform's limit on socket paths.
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "umkmai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "api.sock")
+}
+
+// unixClient sends every request to the socket at path, whatever the URL.
+func unixClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+// staleSocket leaves a socket file at path that nothing serves, like a
+// server that was killed.
+func staleSocket(t *testing.T, path string) {
+	t.Helper()
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	tests := []struct {
+		name     string
+		prepare  func(t *testing.T, path string)
+		mode     string
+		wantMode fs.FileMode
+	}{
+		{"new socket", func(*testing.T, string) {}, "", 0},
+		{"stale socket", staleSocket, "", 0},
+		{"socket mode", func(*testing.T, string) {}, "0660", 0o660},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := socketPath(t)
+			tt.prepare(t, path)
+
+			ln, err := listen(config.ServerConfig{Listen: "unix://" + path, SocketMode: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "ok")
+			})}
+			go srv.Serve(ln)
+
+			info, err := os.Lstat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantMode != 0 && info.Mode().Perm() != tt.wantMode {
+				t.Errorf("socket mode = %v, want %v", info.Mode().Perm(), tt.wantMode)
+			}
+
+			resp, err := unixClient(path).Get("http://unix/livez")
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+				t.Errorf("GET through the socket = %d %q, want 200 ok", resp.StatusCode, body)
+			}
+
+			// Graceful shutdown removes the socket file
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := srv.Shutdown(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Lstat(path); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("socket left behind after shutdown: %v", err)
+			}
+		})
+	}
+}
+
+func TestListenUnixSocketRefused(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, path string)
+		wantErr string
+	}{
+		{"in use", func(t *testing.T, path string) {
+			ln, err := net.Listen("unix", path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { ln.Close() })
+		}, "in use"},
+		{"not a socket", func(t *testing.T, path string) {
+			if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}, "not a socket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := socketPath(t)
+			tt.prepare(t, path)
+
+			ln, err := listen(config.ServerConfig{Listen: "unix://" + path})
+			if err == nil {
+				ln.Close()
+				t.Fatal("listen() succeeded")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("listen() error = %v, want %q", err, tt.wantErr)
+			}
+			// The file that was there is kept
+			if _, err := os.Lstat(path); err != nil {
+				t.Errorf("%s removed: %v", path, err)
+			}
+		})
+	}
+}
+
+func TestListenTCP(t *testing.T) {
+	ln, err := listen(config.ServerConfig{Listen: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if ln.Addr().Network() != "tcp" {
+		t.Errorf("listening on %s, want tcp", ln.Addr().Network())
+	}
+}
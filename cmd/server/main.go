@@ -1,26 +1,15 @@
 package main
 
 import (
-	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
-
-	_ "github.com/tomidev23/BE-umkmai/docs"
-	"github.com/tomidev23/BE-umkmai/internal/config"
-	"github.com/tomidev23/BE-umkmai/internal/delivery/http/handler"
-	"github.com/tomidev23/BE-umkmai/internal/delivery/http/routes"
-	"github.com/tomidev23/BE-umkmai/internal/infrastructure/cache"
-	"github.com/tomidev23/BE-umkmai/internal/infrastructure/database"
-	"github.com/tomidev23/BE-umkmai/internal/middleware"
-	postgresRepo "github.com/tomidev23/BE-umkmai/internal/repository/postgres"
-	"github.com/tomidev23/BE-umkmai/internal/usecase/auth"
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
+
+	_ "github.com/Elysian-Rebirth/backend-go/docs"
+	"github.com/Elysian-Rebirth/backend-go/internal/app"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
 )
 
 // @title           umkmai Backend API
@@ -44,105 +33,110 @@ import (
 // @in header
 // @name Authorization
 func main() {
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	log.Printf("Configuration loaded")
-	log.Printf("Environment: %s", cfg.Server.Environment)
-
-	db, err := database.NewPostgresDB(cfg)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
+	os.Exit(run(os.Args[1:]))
+}
 
-	if err := database.HealthCheck(db); err != nil {
-		log.Fatalf("Database health check failed: %v", err)
-	}
-	log.Printf("Database is healthy")
+// Exit codes, so scripts can tell failures apart
+const (
+	exitOK          = 0
+	exitFailure     = 1 // the command ran and failed
+	exitUsage       = 2 // unknown command or bad flags
+	exitConfig      = 3 // the configuration could not be loaded or is invalid
+	exitUnavailable = 4 // a service the command needs could not be reached
+)
 
-	redisCache, err := cache.NewRedisCache(cfg)
-	if err != nil {
-		log.Fatalf("failed to connect to Redis: %v", err)
-	}
-	log.Printf("Redis connectin established")
+const usage = `Usage: server [-print-config] [command]
 
-	userRepo := postgresRepo.NewUserRepository(db)
-	roleRepo := postgresRepo.NewRoleRepository(db)
-	_ = roleRepo
+Commands:
+  serve                      run the API server (default)
+  migrate [up|down|status]   apply, roll back or list the SQL migrations
+  seed                       create the built-in roles and optionally an admin user
+  config validate            load and check the configuration
+  config print               print the configuration with secrets masked
+  routes                     print the route table with each route's middleware
 
-	log.Printf("Repositories initialized")
+Only serve connects to Redis and RabbitMQ; migrate and seed need Postgres.
+Run "server <command> -h" for the flags of a command.
+`
 
-	if cfg.IsProduction() {
-		gin.SetMode(gin.ReleaseMode)
+func run(args []string) int {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
 	}
-
-	router := gin.New()
-	router.Use(middleware.Recovery())
-	router.Use(middleware.Logger())
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     cfg.Security.CORSAllowedOrigins,
-		AllowMethods:     cfg.Security.CORSAllowedMethods,
-		AllowHeaders:     cfg.Security.CORSAllowedHeaders,
-		AllowCredentials: cfg.Security.CORSAllowCredentials,
-		MaxAge:           12 * time.Hour,
-	}))
-
-	passwordSvc := auth.NewPasswordService()
-	jwtSvc := auth.NewJWTService(cfg.JWT)
-	cacheKeyBuilder := cache.NewCacheKeyBuilder("elysian")
-
-	authUseCase := auth.NewAuthUseCase(userRepo, passwordSvc, jwtSvc, redisCache, cacheKeyBuilder)
-
-	healthHandler := handler.NewHealthHandler(cfg, db, redisCache)
-	userHandler := handler.NewUserHandler(userRepo)
-	authHandler := handler.NewAuthHandler(authUseCase, cfg.IsProduction())
-
-	authMiddleware := middleware.AuthMiddleware(jwtSvc, userRepo, roleRepo)
-
-	routes.SetupRoutes(router, healthHandler, userHandler, authHandler, authMiddleware)
-
-	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
-	srv := &http.Server{
-		Addr:         addr,
-		Handler:      router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
+	printConfig := flags.Bool("print-config", false, "print the loaded configuration (secrets masked) and exit")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+	if *printConfig {
+		return runConfig([]string{"print"})
 	}
 
-	go func() {
-		log.Printf("Server starting on %s", addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
-		}
-	}()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
+	cmd, rest := "serve", flags.Args()
+	if len(rest) > 0 {
+		cmd, rest = rest[0], rest[1:]
+	}
 
-	log.Println("Shutting down server...")
+	switch cmd {
+	case "serve":
+		return runServe(rest)
+	case "migrate":
+		return runMigrate(rest)
+	case "seed":
+		return runSeed(rest)
+	case "config":
+		return runConfig(rest)
+	case "routes":
+		return runRoutes(rest)
+	case "help":
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return exitOK
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.GracefulShutdownTimeout)
-	defer cancel()
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", cmd)
+	flags.Usage()
+	return exitUsage
+}
 
-	if err := redisCache.Close(); err != nil {
-		log.Printf("Error closing Redis: %v", err)
-	} else {
-		log.Printf("Redis connection closed")
+// newFlagSet returns a flag set for a subcommand. Errors are returned
+// rather than exiting, so parseFlags can pick the exit code.
+func newFlagSet(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: server %s %s\n", name, args)
+		flags.PrintDefaults()
 	}
+	return flags
+}
 
-	if err := database.Close(db); err != nil {
-		log.Printf("Error closing database: %v", err)
-	} else {
-		log.Println("Database closed")
+// parseFlags parses args; ok is false when the command should exit with code.
+func parseFlags(flags *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
 	}
+	return exitOK, true
+}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+// loadConfig loads the configuration. On failure cfg is nil and code is the
+// exit code to report.
+func loadConfig() (cfg *config.Config, code int) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return nil, exitConfig
 	}
+	return cfg, exitOK
+}
 
-	log.Println("Server stopped gracefully")
+// exitCode maps an error from building the application to an exit code.
+func exitCode(err error) int {
+	var unavailable *app.UnavailableError
+	if errors.As(err, &unavailable) {
+		return exitUnavailable
+	}
+	return exitFailure
 }
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// useTestConfig points the commands at the repository's config, with the
+// in-memory cache so nothing dials Redis.
+func useTestConfig(t *testing.T) {
+	t.Helper()
+	t.Setenv("ENV", "development")
+	t.Setenv("CONFIG_FILE", "../../config/config.yml")
+	t.Setenv("UMKMAI_REDIS_MODE", config.RedisModeMemory)
+}
+
+// captureStdout runs fn and returns what it printed.
+func captureStdout(t *testing.T, fn func() int) (string, int) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	code := fn()
+	w.Close()
+	return <-out, code
+}
+
+func TestRunUsage(t *testing.T) {
+	useTestConfig(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"help", []string{"help"}, exitOK},
+		{"help flag", []string{"-h"}, exitOK},
+		{"unknown command", []string{"deploy"}, exitUsage},
+		{"unknown flag", []string{"-verbose"}, exitUsage},
+		{"migrate direction", []string{"migrate", "sideways"}, exitUsage},
+		{"migrate extra argument", []string{"migrate", "up", "now"}, exitUsage},
+		{"seed admin without a password", []string{"seed", "-admin-email", "admin@umkmai.id"}, exitUsage},
+		{"config without a subcommand", []string{"config"}, exitUsage},
+		{"config subcommand", []string{"config", "edit"}, exitUsage},
+		{"routes argument", []string{"routes", "all"}, exitUsage},
+		{"subcommand help", []string{"routes", "-h"}, exitOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SEED_ADMIN_PASSWORD", "")
+			if _, got := captureStdout(t, func() int { return run(tt.args) }); got != tt.want {
+				t.Errorf("run(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		configFile string
+		want       int
+		wantOut    string
+	}{
+		{"validate", []string{"config", "validate"}, "", exitOK, "configuration is valid"},
+		{"print", []string{"config", "print"}, "", exitOK, `"server"`},
+		{"print flag", []string{"-print-config"}, "", exitOK, `"server"`},
+		{"missing config", []string{"config", "validate"}, "missing.yml", exitConfig, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			t.Setenv("UMKMAI_DATABASE_PASSWORD", "db-secret-value")
+			if tt.configFile != "" {
+				t.Setenv("CONFIG_FILE", tt.configFile)
+			}
+
+			out, code := captureStdout(t, func() int { return run(tt.args) })
+			if code != tt.want {
+				t.Fatalf("run(%q) = %d, want %d", tt.args, code, tt.want)
+			}
+			if !strings.Contains(out, tt.wantOut) {
+				t.Errorf("output doesn't contain %q:\n%.500s", tt.wantOut, out)
+			}
+			if strings.Contains(out, "db-secret-value") {
+				t.Error("output contains the database password")
+			}
+		})
+	}
+}
+
+func TestRunRoutes(t *testing.T) {
+	useTestConfig(t)
+
+	out, code := captureStdout(t, func() int { return run([]string{"routes", "-json"}) })
+	if code != exitOK {
+		t.Fatalf("routes = %d, want %d", code, exitOK)
+	}
+
+	var table struct {
+		Middleware []string    `json:"middleware"`
+		Routes     []routeInfo `json:"routes"`
+	}
+	if err := json.Unmarshal([]byte(out), &table); err != nil {
+		t.Fatalf("output is not JSON: %v\n%.500s", err, out)
+	}
+	if len(table.Middleware) == 0 {
+		t.Error("no shared middleware listed")
+	}
+
+	found := map[string]routeInfo{}
+	for _, r := range table.Routes {
+		found[r.Method+" "+r.Path] = r
+	}
+	tests := []struct {
+		route          string
+		wantHandler    string
+		wantMiddleware string
+	}{
+		{"GET /livez", "handler.(*HealthHandler).Live", ""},
+		{"GET /api/v1/users/me", "handler.(*UserHandler).GetMe", "middleware.Tenant"},
+		{"DELETE /api/v1/admin/cache/ml", "handler.(*AdminHandler).FlushMLCache", "middleware.RequireRole"},
+	}
+	for _, tt := range tests {
+		r, ok := found[tt.route]
+		if !ok {
+			t.Errorf("%s not listed", tt.route)
+			continue
+		}
+		if r.Handler != tt.wantHandler {
+			t.Errorf("%s handler = %s, want %s", tt.route, r.Handler, tt.wantHandler)
+		}
+		if tt.wantMiddleware != "" && !strings.Contains(strings.Join(r.Middleware, " "), tt.wantMiddleware) {
+			t.Errorf("%s middleware = %v, want %s among them", tt.route, r.Middleware, tt.wantMiddleware)
+		}
+	}
+}
+
+func TestRunNeedsDatabase(t *testing.T) {
+	useTestConfig(t)
+	t.Setenv("UMKMAI_DATABASE_HOST", "127.0.0.1")
+	t.Setenv("UMKMAI_DATABASE_PORT", "1")
+
+	for _, args := range [][]string{{"migrate", "status"}, {"seed"}} {
+		if _, code := captureStdout(t, func() int { return run(args) }); code != exitUnavailable {
+			t.Errorf("run(%q) without a database = %d, want %d", args, code, exitUnavailable)
+		}
+	}
+}
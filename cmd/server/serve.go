@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/app"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/logger"
+	"github.com/Elysian-Rebirth/backend-go/internal/lifecycle"
+	"github.com/Elysian-Rebirth/backend-go/internal/version"
+)
+
+// runServe runs the API server until SIGINT or SIGTERM.
+func runServe(args []string) int {
+	flags := newFlagSet("serve", "")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	cfg, code := loadConfig()
+	if cfg == nil {
+		return code
+	}
+	defer cfg.Close()
+
+	logSink, err := logger.Setup(cfg.Logging)
+	if err != nil {
+		log.Printf("Failed to initialize logging: %v", err)
+		return exitConfig
+	}
+	defer logSink.Close()
+
+	log.Printf("Starting server %s", version.Get())
+	log.Printf("Configuration loaded")
+	log.Printf("Environment: %s", cfg.Server.Environment)
+
+	tlsCfg := cfg.Server.TLS
+	var serverTLS *tls.Config
+	if tlsCfg.Enabled {
+		serverTLS, err = tlsCfg.Build()
+		if err != nil {
+			log.Printf("Invalid TLS configuration: %v", err)
+			return exitConfig
+		}
+	}
+
+	if jitter := cfg.Server.StartupJitter; jitter > 0 {
+		delay := rand.N(jitter)
+		log.Printf("Delaying startup by %v", delay.Round(time.Millisecond))
+		time.Sleep(delay)
+	}
+
+	a, err := app.New(cfg, app.Options{})
+	if err != nil {
+		log.Printf("Failed to start: %v", err)
+		return exitCode(err)
+	}
+
+	_, addr := cfg.Server.ListenAddress()
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           a.Router,
+		TLSConfig:         serverTLS,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	configureHTTP2(srv, cfg.Server)
+
+	// Listen up front so an address that is taken fails the command
+	ln, err := listen(cfg.Server)
+	if err != nil {
+		log.Printf("Failed to listen on %s: %v", addr, err)
+		a.Close(context.Background())
+		return exitFailure
+	}
+	version.SetStartTime(time.Now())
+
+	var redirectSrv *http.Server
+	if tlsCfg.Enabled && tlsCfg.RedirectPort != "" {
+		redirectSrv = &http.Server{
+			Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, tlsCfg.RedirectPort),
+			Handler:      httpsRedirectHandler(cfg.Server.Port),
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+		}
+	}
+
+	a.Closers.Register(lifecycle.PhaseServer, "http server", lifecycle.ShutdownServer(srv, a.Requests))
+	if redirectSrv != nil {
+		a.Closers.Register(lifecycle.PhaseServer, "redirect server", redirectSrv.Shutdown)
+	}
+
+	go func() {
+		var err error
+		if tlsCfg.Enabled {
+			log.Printf("Server starting on %s (TLS enabled, min %s, %d configured cipher suites)",
+				addr, tls.VersionName(srv.TLSConfig.MinVersion), len(srv.TLSConfig.CipherSuites))
+			err = srv.ServeTLS(ln, tlsCfg.CertFile, tlsCfg.KeyFile)
+		} else {
+			log.Printf("Server starting on %s (TLS disabled)", addr)
+			err = srv.Serve(ln)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	}()
+
+	if redirectSrv != nil {
+		go func() {
+			log.Printf("HTTP->HTTPS redirect listening on %s", redirectSrv.Addr)
+			if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Redirect server failed to start: %v", err)
+			}
+		}()
+	}
+
+	if consumer := a.Consumer; consumer != nil {
+		consumerCtx, stopConsumer := context.WithCancel(context.Background())
+		consumerDone := make(chan struct{})
+		go func() {
+			defer close(consumerDone)
+			if err := consumer.Run(consumerCtx); err != nil {
+				log.Printf("Message consumer stopped: %v", err)
+			}
+		}()
+		a.Closers.Register(lifecycle.PhaseConsumers, "message consumer", func(ctx context.Context) error {
+			stopConsumer()
+			select {
+			case <-consumerDone:
+				log.Printf("Message consumer stopped (%+v)", consumer.Stats())
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+
+	if cfg.Jobs.Enabled {
+		a.Jobs.Start()
+	}
+
+	warmupCtx, stopWarmup := context.WithCancel(context.Background())
+	var readinessGates []health.Checker
+	if poolWarmup := cfg.Database.Warmup; poolWarmup.MinConns > 0 && cfg.Database.Driver != config.DatabaseDriverMemory {
+		// Idle connections beyond max_idle_conns would be closed right away
+		minConns := min(poolWarmup.MinConns, cfg.Database.MaxIdleConns)
+		poolWarmup.MinConns = minConns
+		readinessGates = append(readinessGates, database.PoolGate(a.DB, minConns))
+		go func() {
+			if err := database.WarmPool(warmupCtx, a.DB, poolWarmup); err != nil && warmupCtx.Err() == nil {
+				log.Printf("Database pool warm-up failed: %v", err)
+			}
+		}()
+	}
+	go a.Readiness.WarmUp(warmupCtx, a.Checks, cfg.Server.Warmup, readinessGates...)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down server...")
+	stopWarmup()
+	a.Readiness.MarkShuttingDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.GracefulShutdownTimeout)
+	defer cancel()
+
+	if err := a.Close(ctx); err != nil {
+		log.Printf("Server did not shut down cleanly: %v", err)
+		return exitFailure
+	}
+
+	log.Println("Server stopped gracefully")
+	return exitOK
+}
+
+// configureHTTP2 turns on h2c when configured and applies the HTTP/2 limits.
+// HTTP/2 over TLS needs no setup; it is negotiated through ALPN.
+func configureHTTP2(srv *http.Server, cfg config.ServerConfig) {
+	if cfg.EnableH2C {
+		log.Printf("HTTP/2 cleartext (h2c) enabled")
+		srv.Protocols = new(http.Protocols)
+		srv.Protocols.SetHTTP1(true)
+		srv.Protocols.SetHTTP2(true)
+		srv.Protocols.SetUnencryptedHTTP2(true)
+	}
+	if cfg.HTTP2MaxConcurrentStreams > 0 {
+		srv.HTTP2 = &http.HTTP2Config{MaxConcurrentStreams: cfg.HTTP2MaxConcurrentStreams}
+	}
+}
+
+// httpsRedirectHandler permanently redirects plain HTTP requests to the same
+// host and path on the HTTPS port.
+func httpsRedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
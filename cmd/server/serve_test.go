@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/handler"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/notify"
+	"github.com/gin-gonic/gin"
+)
+
+// startStreamServer serves the notification stream of user-1 from a server
+// configured by configureHTTP2. Its write timeout is short, so a stream
+// outliving it shows the deadline was lifted.
+func startStreamServer(t *testing.T, cfg config.ServerConfig) (*notify.Service, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+	notifications := notify.New(c, cache.NewCacheKeyBuilder("test"), config.NotificationsConfig{ReplaySize: 10}, clock.New())
+	h := handler.NewNotificationHandler(notifications, 50*time.Millisecond)
+
+	router := gin.New()
+	router.GET("/stream", func(c *gin.Context) {
+		c.Set("user", &domain.User{ID: "user-1"})
+	}, h.Stream)
+
+	srv := &http.Server{Handler: router, WriteTimeout: 200 * time.Millisecond}
+	configureHTTP2(srv, cfg)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+
+	return notifications, "http://" + ln.Addr().String()
+}
+
+// client speaks only HTTP/1.1, or only HTTP/2 with prior knowledge (h2c).
+func client(h2c bool) *http.Client {
+	protocols := new(http.Protocols)
+	if h2c {
+		protocols.SetUnencryptedHTTP2(true)
+	} else {
+		protocols.SetHTTP1(true)
+	}
+	return &http.Client{Transport: &http.Transport{Protocols: protocols}}
+}
+
+func TestEventStreamProtocols(t *testing.T) {
+	tests := []struct {
+		name      string
+		h2c       bool
+		wantProto int
+	}{
+		{"HTTP/1.1", false, 1},
+		{"h2c", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifications, url := startStreamServer(t, config.ServerConfig{EnableH2C: true})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"/stream", nil)
+			resp, err := client(tt.h2c).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.ProtoMajor != tt.wantProto {
+				t.Fatalf("protocol = %s, want HTTP/%d", resp.Proto, tt.wantProto)
+			}
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+				t.Fatalf("stream = %d %s, want an event stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+
+			// Outlive the write timeout before the event is sent; it only
+			// arrives if every write was flushed and the deadline lifted
+			time.Sleep(300 * time.Millisecond)
+			if err := notifications.Notify(ctx, "user-1", notify.Event{Type: "import.completed"}); err != nil {
+				t.Fatal(err)
+			}
+
+			r := bufio.NewReader(resp.Body)
+			heartbeat, event := false, false
+			for !heartbeat || !event {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					t.Fatalf("stream ended (heartbeat %v, event %v): %v", heartbeat, event, err)
+				}
+				heartbeat = heartbeat || strings.HasPrefix(line, ": heartbeat")
+				event = event || strings.HasPrefix(line, "event: import.completed")
+			}
+		})
+	}
+}
+
+func TestH2CDisabled(t *testing.T) {
+	_, url := startStreamServer(t, config.ServerConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"/stream", nil)
+	resp, err := client(true).Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("h2c request to a server without h2c = %s %d, want it refused", resp.Proto, resp.StatusCode)
+	}
+}
@@ -0,0 +1,213 @@
+// Package apierror defines the error responses of the API: one envelope for
+// every error and the machine-readable codes clients can rely on. Codes are
+// part of the API contract, so existing ones must never change meaning.
+package apierror
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/monitoring"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+// Code is a stable machine-readable error reason.
+type Code string
+
+// Generic codes, used when nothing more specific applies
+const (
+	CodeInvalidRequest       Code = "invalid_request"
+	CodeValidationFailed     Code = "validation_failed"
+	CodeUnauthenticated      Code = "authentication_required"
+	CodeForbidden            Code = "forbidden"
+	CodeNotFound             Code = "not_found"
+	CodeMethodNotAllowed     Code = "method_not_allowed"
+	CodeConflict             Code = "conflict"
+	CodePayloadTooLarge      Code = "payload_too_large"
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	CodeRateLimited          Code = "rate_limited"
+	CodeInternal             Code = "internal_error"
+	CodeBadGateway           Code = "bad_gateway"
+	CodeUnavailable          Code = "service_unavailable"
+	CodeTimeout              Code = "timeout"
+)
+
+// Authentication and authorization
+const (
+	CodeInvalidCredentials      Code = "invalid_credentials"
+	CodeInvalidToken            Code = "invalid_token"
+	CodeInvalidAPIKey           Code = "invalid_api_key"
+	CodeTokenRevoked            Code = "token_revoked"
+	CodeSessionExpired          Code = "session_expired"
+	CodeAccountDisabled         Code = "account_disabled"
+	CodeRefreshInProgress       Code = "refresh_in_progress"
+	CodeRefreshTokenRotated     Code = "refresh_token_rotated"
+	CodeInsufficientPermissions Code = "insufficient_permissions"
+	CodeTenantRequired          Code = "tenant_required"
+	CodeInvalidTenant           Code = "invalid_tenant"
+)
+
+// Resources
+const (
+	CodeRouteNotFound       Code = "route_not_found"
+	CodeUserNotFound        Code = "user_not_found"
+	CodeEmailTaken          Code = "email_taken"
+	CodeEmailDomainBlocked  Code = "email_domain_not_allowed"
+	CodeEmailUnchanged      Code = "email_unchanged"
+	CodeEmailChangeDisabled Code = "email_change_disabled"
+	CodeInvalidEmailToken   Code = "invalid_verification_token"
+	CodeTokenAlreadyUsed    Code = "verification_token_used"
+	CodeResetDisabled       Code = "password_reset_disabled"
+	CodeEmailThrottled      Code = "email_throttled"
+	CodeQuotaExceeded       Code = "quota_exceeded"
+	CodeFeatureUnavailable  Code = "feature_unavailable"
+)
+
+// AI
+const (
+	// CodeAIUnavailable is returned while the ML service is down
+	CodeAIUnavailable Code = "ai_unavailable"
+	// CodeInappropriateInput is returned when the input contains a blocked word
+	CodeInappropriateInput Code = "inappropriate_input"
+	// CodeNoUsableOutput is returned when the model's output was empty or
+	// withheld; retrying may succeed
+	CodeNoUsableOutput Code = "no_usable_output"
+)
+
+// defaultRetryAfter is the Retry-After sent with a 503 that doesn't set
+// its own, in seconds
+const defaultRetryAfter = "5"
+
+// Response is the body of every error response.
+type Response struct {
+	Code    Code     `json:"code"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+	// RequestID identifies the request in the logs, for support requests
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Write sends resp with status, filling in the code for status when resp
+// has none and the ID of the request. Server errors are reported to the
+// error tracker.
+func Write(c *gin.Context, status int, resp Response) {
+	write(c, status, resp, nil)
+}
+
+func write(c *gin.Context, status int, resp Response, err error) {
+	if status >= http.StatusInternalServerError {
+		if err == nil {
+			err = errors.New(resp.Message)
+		}
+		monitoring.Report(c.Request.Context(), monitoring.Event{
+			Err:     err,
+			Request: c.Request,
+			Route:   c.FullPath(),
+			Status:  status,
+		})
+	}
+	if resp.Code == "" {
+		resp.Code = CodeForStatus(status)
+	}
+	if resp.RequestID == "" {
+		resp.RequestID, _ = reqctx.RequestID(c.Request.Context())
+	}
+	if status == http.StatusServiceUnavailable && c.Writer.Header().Get("Retry-After") == "" {
+		c.Header("Retry-After", defaultRetryAfter)
+	}
+	c.JSON(status, resp)
+}
+
+// WriteFailure answers for an operation that failed with err: 503 when a
+// backing store is unreachable, otherwise a 500 with message. err is
+// reported but never shown to the client.
+func WriteFailure(c *gin.Context, err error, message string) {
+	if errors.Is(err, domain.ErrUnavailable) {
+		WriteError(c, err)
+		return
+	}
+	write(c, http.StatusInternalServerError, Response{Code: CodeInternal, Message: message}, err)
+}
+
+// Abort writes an error response and stops the handler chain; middleware
+// uses it to reject requests.
+func Abort(c *gin.Context, status int, code Code, message string, details ...string) {
+	Write(c, status, Response{Code: code, Message: message, Details: details})
+	c.Abort()
+}
+
+// AbortWithError answers with an internal error and stops the handler
+// chain. err is reported but never shown to the client.
+func AbortWithError(c *gin.Context, err error) {
+	write(c, http.StatusInternalServerError, Response{Code: CodeInternal, Message: "Internal server error"}, err)
+	c.Abort()
+}
+
+// WriteError maps err to a response with From and writes it.
+func WriteError(c *gin.Context, err error) {
+	status, resp := From(err)
+	write(c, status, resp, err)
+}
+
+// From maps the domain errors to their status and response. Anything else
+// is logged and reported as an internal error, so its text never reaches
+// the client.
+func From(err error) (int, Response) {
+	var validation *domain.ValidationError
+	switch {
+	case errors.As(err, &validation):
+		return http.StatusBadRequest, Response{Code: CodeValidationFailed, Message: validation.Message}
+	case errors.Is(err, domain.ErrUnavailable):
+		return http.StatusServiceUnavailable, Response{Code: CodeUnavailable, Message: "Service temporarily unavailable, try again shortly"}
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, Response{Code: CodeNotFound, Message: "Not found"}
+	case errors.Is(err, domain.ErrEmailTaken):
+		return http.StatusConflict, Response{Code: CodeEmailTaken, Message: "Email already registered"}
+	case errors.Is(err, domain.ErrEmailDomainNotAllowed):
+		return http.StatusForbidden, Response{Code: CodeEmailDomainBlocked, Message: "Registration is not open to this email domain"}
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		return http.StatusUnauthorized, Response{Code: CodeInvalidCredentials, Message: "Invalid email or password"}
+	case errors.Is(err, domain.ErrAccountDisabled):
+		return http.StatusForbidden, Response{Code: CodeAccountDisabled, Message: "Account is disabled"}
+	default:
+		log.Printf("Unhandled error: %v", err)
+		return http.StatusInternalServerError, Response{Code: CodeInternal, Message: "Internal server error"}
+	}
+}
+
+// CodeForStatus is the generic code of an HTTP status.
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway:
+		return CodeBadGateway
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	default:
+		return CodeInternal
+	}
+}
@@ -0,0 +1,152 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    Code
+		wantMessage string
+	}{
+		{"validation", domain.NewValidationError("name is too short"), http.StatusBadRequest, CodeValidationFailed, "name is too short"},
+		{"wrapped validation", fmt.Errorf("register: %w", domain.NewValidationError("weak password")), http.StatusBadRequest, CodeValidationFailed, "weak password"},
+		{"unavailable", fmt.Errorf("find user: %w", domain.ErrUnavailable), http.StatusServiceUnavailable, CodeUnavailable, ""},
+		{"not found", fmt.Errorf("user %w", domain.ErrNotFound), http.StatusNotFound, CodeNotFound, ""},
+		{"email taken", domain.ErrEmailTaken, http.StatusConflict, CodeEmailTaken, ""},
+		{"email domain", domain.ErrEmailDomainNotAllowed, http.StatusForbidden, CodeEmailDomainBlocked, ""},
+		{"invalid credentials", domain.ErrInvalidCredentials, http.StatusUnauthorized, CodeInvalidCredentials, ""},
+		{"account disabled", domain.ErrAccountDisabled, http.StatusForbidden, CodeAccountDisabled, ""},
+		{"unknown", errors.New("pq: connection refused to 10.0.0.5"), http.StatusInternalServerError, CodeInternal, "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := From(tt.err)
+			if status != tt.wantStatus || resp.Code != tt.wantCode {
+				t.Errorf("From() = %d %s, want %d %s", status, resp.Code, tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantMessage != "" && resp.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", resp.Message, tt.wantMessage)
+			}
+			if resp.Message == "" {
+				t.Error("empty message")
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		status         int
+		resp           Response
+		wantCode       Code
+		wantRetryAfter string
+	}{
+		{"code kept", http.StatusConflict, Response{Code: CodeEmailTaken, Message: "taken"}, CodeEmailTaken, ""},
+		{"code from status", http.StatusTooManyRequests, Response{Message: "slow down"}, CodeRateLimited, ""},
+		{"unknown status", http.StatusTeapot, Response{Message: "teapot"}, CodeInternal, ""},
+		{"unavailable", http.StatusServiceUnavailable, Response{Message: "down"}, CodeUnavailable, defaultRetryAfter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request = c.Request.WithContext(reqctx.WithRequestID(c.Request.Context(), "req-1"))
+
+			Write(c, tt.status, tt.resp)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			var body Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode || body.Message != tt.resp.Message || body.RequestID != "req-1" {
+				t.Errorf("body = %+v, want code %s, message %q and request_id req-1", body, tt.wantCode, tt.resp.Message)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestAbort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reached := false
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		Abort(c, http.StatusForbidden, CodeInsufficientPermissions, "Insufficient permissions", "Missing permission: users.write")
+	}, func(c *gin.Context) {
+		reached = true
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if reached {
+		t.Error("Abort() didn't stop the chain")
+	}
+	var body Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusForbidden || body.Code != CodeInsufficientPermissions || len(body.Details) != 1 {
+		t.Errorf("response = %d %+v, want 403 insufficient_permissions with one detail", w.Code, body)
+	}
+}
+
+func TestWriteFailureHidesError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   Code
+	}{
+		{"internal", errors.New("pq: relation users does not exist"), http.StatusInternalServerError, CodeInternal},
+		{"unavailable", fmt.Errorf("dial tcp: %w", domain.ErrUnavailable), http.StatusServiceUnavailable, CodeUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			WriteFailure(c, tt.err, "Failed to fetch users")
+
+			var body Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.wantStatus || body.Code != tt.wantCode {
+				t.Errorf("response = %d %s, want %d %s", w.Code, body.Code, tt.wantStatus, tt.wantCode)
+			}
+			if body.Message == tt.err.Error() {
+				t.Errorf("message %q leaks the error", body.Message)
+			}
+		})
+	}
+}
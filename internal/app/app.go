@@ -0,0 +1,471 @@
+// Package app wires the server's dependencies together. The subcommands of
+// cmd/server share it, so serving, listing routes and validating the config
+// all build the same graph.
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	sentrygin "github.com/getsentry/sentry-go/gin"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/cookie"
+	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/handler"
+	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/routes"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/featureflags"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mail"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mlclient"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/monitoring"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
+	"github.com/Elysian-Rebirth/backend-go/internal/jsonnaming"
+	"github.com/Elysian-Rebirth/backend-go/internal/lifecycle"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/notify"
+	memoryRepo "github.com/Elysian-Rebirth/backend-go/internal/repository/memory"
+	postgresRepo "github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/Elysian-Rebirth/backend-go/internal/scheduler"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/ai"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	businessUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/business"
+	fileUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/file"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	userUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/user"
+	webhookUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/webhook"
+)
+
+// errorReportFlushTimeout bounds how long shutdown waits for error reports
+// still being sent
+const errorReportFlushTimeout = 2 * time.Second
+
+// databaseRecheckInterval is how often a request arriving while the
+// database is marked down triggers another check
+const databaseRecheckInterval = 2 * time.Second
+
+// redisReconnectInterval is how often a server started without Redis
+// retries it
+const redisReconnectInterval = 5 * time.Second
+
+// Options changes how New builds the application.
+type Options struct {
+	// Offline builds everything without contacting Postgres, Redis, the
+	// broker or object storage. The result can be inspected but not served.
+	Offline bool
+	// Middleware is installed ahead of the standard chain
+	Middleware []gin.HandlerFunc
+}
+
+// App is the wired application. Components register how to close
+// themselves on Closers as they are built; see the phases in lifecycle for
+// the order they are shut down in.
+type App struct {
+	Config    *config.Config
+	DB        *gorm.DB
+	Cache     cache.Cache
+	Router    *gin.Engine
+	Requests  *lifecycle.RequestTracker
+	Checks    *health.Registry
+	Readiness *health.Readiness
+	Jobs      *scheduler.Scheduler
+	// Consumer is nil without a broker or when offline
+	Consumer *queue.Consumer
+	Closers  *lifecycle.Registry
+}
+
+// New connects to the backing services and builds the router. On error,
+// whatever was already started is closed again.
+func New(cfg *config.Config, opts Options) (_ *App, err error) {
+	closers := lifecycle.NewRegistry()
+	defer func() {
+		if err != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.GracefulShutdownTimeout)
+			defer cancel()
+			closers.Shutdown(ctx)
+		}
+	}()
+
+	if err := checkJSONNaming(cfg.Server.JSONNaming); err != nil {
+		return nil, err
+	}
+
+	reporter, err := monitoring.New(cfg.Monitoring, cfg.Server.Environment)
+	if err != nil {
+		return nil, err
+	}
+	monitoring.SetDefault(reporter)
+	closers.Register(lifecycle.PhaseQueues, "error reporter", func(ctx context.Context) error {
+		// Pending reports must not hold up the rest of the shutdown
+		ctx, cancel := context.WithTimeout(ctx, errorReportFlushTimeout)
+		defer cancel()
+		return reporter.Flush(ctx)
+	})
+
+	// With the memory driver only users and roles work; the connection is
+	// never opened unless another feature is used
+	memoryDB := cfg.Database.Driver == config.DatabaseDriverMemory
+	var db *gorm.DB
+	if opts.Offline || memoryDB {
+		db, err = database.Open(cfg)
+	} else {
+		db, err = database.NewPostgresDB(cfg)
+	}
+	if err != nil {
+		return nil, &UnavailableError{Service: "database", Err: err}
+	}
+	closers.RegisterCloser(lifecycle.PhaseStores, "database", func() error { return database.Close(db) })
+
+	if !opts.Offline && !memoryDB {
+		if err := database.HealthCheck(db); err != nil {
+			return nil, &UnavailableError{Service: "database", Err: err}
+		}
+		log.Printf("Database is healthy")
+	}
+
+	// redisUp stays unset while a server started without Redis waits for it
+	var redisCache cache.Cache
+	redisUp := &atomic.Bool{}
+	redisUp.Store(true)
+	if cfg.Redis.Mode == config.RedisModeMemory {
+		redisCache = cache.NewMemoryCache()
+		log.Printf("Using the in-memory cache instead of Redis")
+	} else if opts.Offline {
+		redisCache = cache.OpenRedisCache(cfg)
+	} else {
+		redisCache, err = cache.NewRedisCache(cfg)
+		switch {
+		case err == nil:
+			log.Printf("Redis connectin established")
+		case !cfg.Redis.AllowDegradedStart:
+			return nil, &UnavailableError{Service: "redis", Err: err}
+		default:
+			log.Printf("Starting in degraded mode, Redis is unavailable: %v", err)
+			redisCache = cache.OpenRedisCache(cfg)
+			redisUp.Store(false)
+			stop := cache.Reconnect(redisCache, redisReconnectInterval, redisUp)
+			closers.RegisterCloser(lifecycle.PhaseStores, "redis reconnect", func() error {
+				stop()
+				return nil
+			})
+		}
+	}
+	closers.RegisterCloser(lifecycle.PhaseStores, "redis", redisCache.Close)
+
+	cacheKeyBuilder := cache.NewCacheKeyBuilder("elysian")
+
+	mailSender, err := mail.NewSender(cfg.Mail)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail configuration: %w", err)
+	}
+	mailRenderer, err := mail.NewRenderer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mail templates: %w", err)
+	}
+	failedEmails := mail.NewFailedStore(redisCache, cacheKeyBuilder)
+
+	useBroker := cfg.RabbitMQ.URL != "" && !opts.Offline
+	var publisher queue.Publisher = queue.NoopPublisher{}
+	var consumer *queue.Consumer
+	if useBroker {
+		publisher = queue.NewRabbitMQPublisher(cfg.RabbitMQ)
+		log.Printf("RabbitMQ publisher started (exchange %s)", cfg.RabbitMQ.Exchange)
+		closers.RegisterCloser(lifecycle.PhaseQueues, "message publisher", publisher.Close)
+
+		consumer = queue.NewConsumer(cfg.RabbitMQ)
+		consumer.Handle(mail.JobType, mail.NewSendHandler(mailSender, mailRenderer, redisCache, cacheKeyBuilder))
+		consumer.HandleDeadLetter(mail.JobType, mail.NewDeadLetterHandler(failedEmails))
+	} else if !opts.Offline {
+		log.Printf("RabbitMQ not configured, events will not be published")
+	}
+
+	// Without a broker, mail is sent from an in-process queue instead
+	var mailer mail.Mailer
+	if useBroker {
+		mailer = mail.NewBrokerMailer(publisher, mailRenderer)
+		log.Printf("Mail is sent through RabbitMQ (provider %s)", cfg.Mail.Provider)
+	} else {
+		mailQueue := mail.NewQueue(mailSender, mailRenderer, failedEmails, cfg.Mail)
+		mailer = mailQueue
+		closers.RegisterCloser(lifecycle.PhaseQueues, "mail queue", mailQueue.Close)
+		if !opts.Offline {
+			log.Printf("Mail queue started in-process (provider %s)", cfg.Mail.Provider)
+		}
+	}
+
+	var userRepo repository.UserRepository
+	var roleRepo repository.RoleRepository
+	var auditRepo repository.AuditLogRepository
+	// Usage records are only kept with a database; usage is then counted in
+	// the cache alone
+	var usageRecordRepo repository.UsageRecordRepository
+	if memoryDB {
+		store := memoryRepo.NewStore()
+		userRepo = memoryRepo.NewUserRepository(store)
+		roleRepo = memoryRepo.NewRoleRepository(store)
+		auditRepo = memoryRepo.NewAuditLogRepository(store)
+		if err := seedMemory(context.Background(), userRepo, roleRepo); err != nil {
+			return nil, fmt.Errorf("failed to seed the in-memory database: %w", err)
+		}
+		log.Printf("Using in-memory users and roles; features needing other tables are unavailable")
+	} else {
+		userRepo = postgresRepo.NewUserRepository(db)
+		roleRepo = postgresRepo.NewRoleRepository(db)
+		auditRepo = postgresRepo.NewAuditLogRepository(db)
+		usageRecordRepo = postgresRepo.NewUsageRecordRepository(db)
+	}
+	fileRepo := postgresRepo.NewFileRepository(db)
+	webhookRepo := postgresRepo.NewWebhookRepository(db)
+	generationRepo := postgresRepo.NewGenerationRepository(db)
+	businessRepo := postgresRepo.NewBusinessRepository(db)
+
+	log.Printf("Repositories initialized")
+
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	handler.SetMaxPageOffset(cfg.Server.MaxPageOffset)
+
+	router := gin.New()
+	requests := lifecycle.NewRequestTracker()
+	router.Use(opts.Middleware...)
+	router.Use(middleware.RequestID())
+	router.Use(middleware.TrackRequests(requests))
+	if _, ok := reporter.(*monitoring.Sentry); ok {
+		// Gives each request its own hub, carrying the request and trace to
+		// the events reported while serving it. It sits outside Recovery,
+		// which reports panics itself, so it never sees one to report twice.
+		router.Use(sentrygin.New(sentrygin.Options{Repanic: true}))
+	}
+	router.Use(middleware.Recovery())
+	router.Use(middleware.Logger(cfg.Logging.AccessLog))
+	if cfg.IsDevelopment() {
+		router.Use(middleware.QueryCounter(cfg.Database.QueryWarnThreshold))
+	}
+	router.Use(middleware.CORS(cfg.Security))
+	router.Use(middleware.ConcurrencyLimit(cfg.Server.Concurrency))
+
+	clk := clock.New()
+	passwordSvc := auth.NewPasswordService()
+	jwtSvc, err := auth.NewJWTService(cfg.JWT, clk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT configuration: %w", err)
+	}
+	features, err := featureflags.New(cfg.Features, redisCache, cacheKeyBuilder)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feature flag configuration: %w", err)
+	}
+
+	sessionStore := auth.NewSessionStore(redisCache, cacheKeyBuilder, cfg.JWT, clk)
+	emailDomains := auth.NewEmailDomainPolicy(cfg.Security.Registration)
+	names := auth.NewNameSanitizer(cfg.Security.Names)
+	authUseCase := auth.NewAuthUseCase(userRepo, passwordSvc, jwtSvc, redisCache, cacheKeyBuilder, sessionStore, publisher, emailDomains, names, clk)
+	verificationStore := auth.NewVerificationStore(redisCache, cacheKeyBuilder)
+	emailThrottle := auth.NewEmailThrottle(redisCache, cacheKeyBuilder, cfg.Security.EmailThrottle, clk)
+	redirectValidator := auth.NewRedirectValidator(cfg.Security)
+	if cfg.Security.EmailChange.Enabled {
+		if _, err := redirectValidator.Validate(cfg.Security.EmailChange.ConfirmURL); err != nil {
+			return nil, fmt.Errorf("invalid email change confirm URL: %w", err)
+		}
+	}
+	if cfg.Security.PasswordReset.Enabled {
+		if _, err := redirectValidator.Validate(cfg.Security.PasswordReset.ResetURL); err != nil {
+			return nil, fmt.Errorf("invalid password reset URL: %w", err)
+		}
+	}
+	userUC := userUseCase.NewUserUseCase(
+		userRepo, auditRepo, sessionStore, verificationStore, emailThrottle, passwordSvc, redirectValidator, mailer, publisher,
+		redisCache, cacheKeyBuilder, cfg.Security.EmailChange, cfg.Security.PasswordReset, clk,
+	)
+
+	usageUC := usage.NewUsageUseCase(redisCache, cacheKeyBuilder, usageRecordRepo, clk)
+
+	var objectStorage storage.ObjectStorage = storage.Unconfigured{}
+	if cfg.Storage.Endpoint != "" {
+		s3Storage, err := storage.NewS3Storage(cfg.Storage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize object storage: %w", err)
+		}
+		objectStorage = s3Storage
+		if !opts.Offline {
+			log.Printf("Object storage initialized (bucket %s)", cfg.Storage.Bucket)
+		}
+	} else if !opts.Offline {
+		log.Println("Object storage not configured, file uploads are disabled")
+	}
+	fileUC := fileUseCase.NewFileUseCase(fileRepo, objectStorage, cfg.Upload)
+	avatarUC := fileUseCase.NewAvatarUseCase(userRepo, objectStorage, cfg.Upload.Avatar)
+
+	var mlClient *mlclient.Client
+	if cfg.ML.ServiceURL != "" {
+		mlClient = mlclient.New(cfg.ML, clk).WithCache(redisCache, cacheKeyBuilder, cfg.ML.Cache)
+	}
+
+	businessUC := businessUseCase.NewBusinessUseCase(businessRepo, redisCache, cacheKeyBuilder, cfg.Businesses)
+
+	notifications := notify.New(redisCache, cacheKeyBuilder, cfg.Notifications, clk)
+	aiUC := ai.NewAIUseCase(mlClient, usageUC, generationRepo, redisCache, cacheKeyBuilder, notifications, cfg.ML)
+
+	// Lifecycle events fan out to webhooks through the broker, so without
+	// one nothing is delivered
+	webhookUC := webhookUseCase.NewWebhookUseCase(webhookRepo, publisher, cfg.Webhooks, clk)
+	if consumer != nil {
+		consumer.Handle(domain.EventUserRegistered, func(ctx context.Context, msg *queue.Message) error {
+			var event domain.UserRegisteredEvent
+			if err := json.Unmarshal(msg.Payload, &event); err != nil {
+				return fmt.Errorf("%w: %v", queue.ErrPermanent, err)
+			}
+			log.Printf("User registered: %s", event.UserID)
+			return webhookUC.Dispatch(ctx, msg)
+		})
+		consumer.Handle(domain.EventUserVerified, webhookUC.Dispatch)
+		consumer.Handle(domain.EventUserDeactivated, webhookUC.Dispatch)
+		consumer.Handle(domain.EventUserDeleted, webhookUC.Dispatch)
+		consumer.Handle(webhookUseCase.JobType, webhookUC.Deliver)
+		consumer.HandleDeadLetter(webhookUseCase.JobType, webhookUC.DeliveryFailed)
+	}
+
+	jobs := scheduler.New(redisCache, cacheKeyBuilder, clk)
+	orphanCleaner := fileUseCase.NewOrphanCleaner(fileRepo, userRepo, objectStorage, redisCache, cacheKeyBuilder, cfg.Upload.OrphanCleanup, clk)
+	registerJobs(jobs, cfg.Jobs, userUC, webhookUC, orphanCleaner, usageUC)
+	// Once requests are drained and the jobs stopped, so nothing counted
+	// afterwards; the stores close in the next phase. Offline nothing is
+	// counted, and flushing would reach for Redis
+	if !opts.Offline {
+		closers.Register(lifecycle.PhaseQueues, "usage counters", usageUC.Flush)
+	}
+	closers.Register(lifecycle.PhaseJobs, "background jobs", jobs.Stop)
+
+	// The gate fails requests fast while the health checks find the
+	// database unreachable; without a database it stays nil and never closes
+	var dbGate *database.Gate
+	if !memoryDB {
+		dbGate = database.NewGate(db, databaseRecheckInterval)
+	}
+	checks := healthChecks(cfg.Server, dbGate, redisCache, publisher, objectStorage, mlClient)
+	checks.Redact(cfg.Database.Password, cfg.Redis.Password)
+	readiness := health.NewReadiness()
+	healthHandler := handler.NewHealthHandler(cfg, checks, readiness)
+	userHandler := handler.NewUserHandler(userRepo, userUC, names)
+	authHandler := handler.NewAuthHandler(authUseCase, cookie.NewWriter(cfg.Security.Cookie, cfg.IsProduction()))
+	adminHandler := handler.NewAdminHandler(cfg, redisCache, cacheKeyBuilder, auditRepo, features, mailer, failedEmails, jobs, userRepo, roleRepo, businessUC, mlClient)
+	usageHandler := handler.NewUsageHandler(usageUC, userRepo, cfg.MonthlyQuotas)
+	fileHandler := handler.NewFileHandler(fileUC, cfg.Upload.MaxFileSize)
+	aiHandler := handler.NewAIHandler(aiUC)
+	webhookHandler := handler.NewWebhookHandler(webhookUC)
+	notificationHandler := handler.NewNotificationHandler(notifications, cfg.Notifications.Heartbeat)
+	jwksHandler := handler.NewJWKSHandler(jwtSvc)
+	avatarHandler := handler.NewAvatarHandler(avatarUC, cfg.Upload.Avatar.MaxFileSize)
+	businessHandler := handler.NewBusinessHandler(businessUC)
+
+	authMiddleware := middleware.AuthMiddleware(jwtSvc, sessionStore, userRepo, roleRepo)
+	streamAuthMiddleware := middleware.CombinedAuth(roleRepo,
+		middleware.BearerScheme(jwtSvc, sessionStore, userRepo),
+		middleware.QueryTokenScheme(jwtSvc, sessionStore, userRepo),
+	)
+	generationRateLimit := middleware.RateLimit(redisCache, cacheKeyBuilder, ai.FeatureBusinessDescription, cfg.ML.Generation.RateLimitPerMinute, time.Minute)
+	requireCache := middleware.RequireCache(redisUp)
+	requireDatabase := middleware.RequireDatabase(dbGate)
+
+	routes.SetupRoutes(router, cfg, healthHandler, userHandler, authHandler, adminHandler, usageHandler, fileHandler, aiHandler, webhookHandler, notificationHandler, jwksHandler, avatarHandler, businessHandler, features, usageUC, authMiddleware, streamAuthMiddleware, generationRateLimit, requireCache, requireDatabase)
+
+	return &App{
+		Config:    cfg,
+		DB:        db,
+		Cache:     redisCache,
+		Router:    router,
+		Requests:  requests,
+		Checks:    checks,
+		Readiness: readiness,
+		Jobs:      jobs,
+		Consumer:  consumer,
+		Closers:   closers,
+	}, nil
+}
+
+// checkJSONNaming checks the response types for fields that would not be
+// serialized in snake_case, logging them or, in strict mode, failing.
+func checkJSONNaming(mode string) error {
+	if mode == "" || mode == jsonnaming.ModeOff {
+		return nil
+	}
+	violations := jsonnaming.Check(handler.ResponseTypes()...)
+	if len(violations) == 0 {
+		return nil
+	}
+	for _, v := range violations {
+		log.Printf("Response field not in snake_case: %s", v)
+	}
+	if mode == jsonnaming.ModeStrict {
+		return fmt.Errorf("%d response fields are not named in snake_case", len(violations))
+	}
+	return nil
+}
+
+// Close shuts down everything New started.
+func (a *App) Close(ctx context.Context) error {
+	return a.Closers.Shutdown(ctx)
+}
+
+// UnavailableError reports a backing service that could not be reached, as
+// opposed to a configuration problem.
+type UnavailableError struct {
+	Service string
+	Err     error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("%s unavailable: %v", e.Service, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// healthChecks registers the checks of every configured dependency; each
+// component decides its own. Postgres and Redis are required; the others
+// only degrade the service. dbGate, nil with the memory driver, runs the
+// database check and opens and closes with it.
+func healthChecks(cfg config.ServerConfig, dbGate *database.Gate, redisCache cache.Cache, publisher queue.Publisher, objectStorage storage.ObjectStorage, mlClient *mlclient.Client) *health.Registry {
+	checks := health.NewRegistry(health.DefaultTimeout)
+	checks.Add(dbGate, redisCache, publisher, objectStorage, mlClient)
+	checks.SetTimeouts(cfg.HealthCheckTimeouts)
+	checks.SetSlowThreshold(cfg.HealthSlowThreshold)
+	return checks
+}
+
+// registerJobs adds the maintenance jobs. Jobs without a schedule in
+// jobs.schedules can still be run from the admin API, using the defaults.
+func registerJobs(jobs *scheduler.Scheduler, cfg config.JobsConfig, userUC userUseCase.UserUseCase, webhookUC webhookUseCase.WebhookUseCase, orphanCleaner *fileUseCase.OrphanCleaner, usageUC usage.UsageUseCase) {
+	purgeUsers := cfg.Schedules["purge_deleted_users"]
+	if purgeUsers.Retention <= 0 {
+		purgeUsers.Retention = 30 * 24 * time.Hour
+	}
+	jobs.Register("purge_deleted_users", purgeUsers, func(ctx context.Context) error {
+		return userUC.PurgeDeleted(ctx, purgeUsers.Retention)
+	})
+
+	pruneDeliveries := cfg.Schedules["prune_webhook_deliveries"]
+	if pruneDeliveries.Retention <= 0 {
+		pruneDeliveries.Retention = 30 * 24 * time.Hour
+	}
+	jobs.Register("prune_webhook_deliveries", pruneDeliveries, func(ctx context.Context) error {
+		return webhookUC.PruneDeliveries(ctx, pruneDeliveries.Retention)
+	})
+
+	jobs.Register("cleanup_orphaned_uploads", cfg.Schedules["cleanup_orphaned_uploads"], orphanCleaner.Run)
+
+	jobs.Register("flush_usage", cfg.Schedules["flush_usage"], usageUC.Flush)
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// newTestApp builds the full router, routes and middleware included, on the
+// in-memory repositories and cache, from the repository's own config. env
+// overrides UMKMAI_* settings on top of that.
+func newTestApp(t *testing.T, env map[string]string) *App {
+	t.Helper()
+	t.Setenv("ENV", "development")
+	t.Setenv("CONFIG_FILE", "../../config/config.yml")
+	t.Setenv("UMKMAI_DATABASE_DRIVER", config.DatabaseDriverMemory)
+	t.Setenv("UMKMAI_REDIS_MODE", config.RedisModeMemory)
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Close(context.Background()) })
+	return a
+}
+
+// call sends a JSON request through the router and decodes the response
+// into out, if given.
+func call(t *testing.T, a *App, method, path, token string, body, out any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	a.Router.ServeHTTP(w, req)
+
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: %v: %s", method, path, err, w.Body)
+		}
+	}
+	return w
+}
+
+type authResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	User         struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	} `json:"user"`
+}
+
+func TestAccountFlow(t *testing.T) {
+	a := newTestApp(t, nil)
+	const email, password = "shop@example.com", "correct horse battery"
+
+	var registered authResponse
+	if w := call(t, a, http.MethodPost, "/api/v1/auth/register", "",
+		map[string]string{"email": email, "password": password, "name": "Toko Budi"}, &registered); w.Code != http.StatusCreated {
+		t.Fatalf("register = %d, want 201: %s", w.Code, w.Body)
+	}
+	if registered.AccessToken == "" || registered.User.Email != email {
+		t.Fatalf("register returned %+v", registered)
+	}
+
+	var login authResponse
+	if w := call(t, a, http.MethodPost, "/api/v1/auth/login", "",
+		map[string]string{"email": email, "password": password}, &login); w.Code != http.StatusOK {
+		t.Fatalf("login = %d, want 200: %s", w.Code, w.Body)
+	}
+	if login.User.ID != registered.User.ID {
+		t.Fatalf("login user = %q, want %q", login.User.ID, registered.User.ID)
+	}
+
+	steps := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		body     any
+		wantCode int
+		wantName string
+	}{
+		{"me without a token", http.MethodGet, "/api/v1/users/me", "", nil, http.StatusUnauthorized, ""},
+		{"me", http.MethodGet, "/api/v1/users/me", login.AccessToken, nil, http.StatusOK, "Toko Budi"},
+		{"update name", http.MethodPut, "/api/v1/users/me", login.AccessToken, map[string]string{"name": "Toko Budi Jaya"}, http.StatusOK, "Toko Budi Jaya"},
+		{"me after update", http.MethodGet, "/api/v1/users/me", login.AccessToken, nil, http.StatusOK, "Toko Budi Jaya"},
+		{"invalid name", http.MethodPut, "/api/v1/users/me", login.AccessToken, map[string]string{"name": "x"}, http.StatusBadRequest, ""},
+		{"logout", http.MethodPost, "/api/v1/auth/logout", login.AccessToken, map[string]string{"refresh_token": login.RefreshToken}, http.StatusOK, ""},
+		{"refresh after logout", http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": login.RefreshToken}, http.StatusUnauthorized, ""},
+		{"other session still refreshes", http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{"refresh_token": registered.RefreshToken}, http.StatusOK, ""},
+	}
+
+	// Steps run in order, each depending on the state the previous ones left
+	for _, step := range steps {
+		var got map[string]any
+		w := call(t, a, step.method, step.path, step.token, step.body, &got)
+		if w.Code != step.wantCode {
+			t.Fatalf("%s = %d, want %d: %s", step.name, w.Code, step.wantCode, w.Body)
+		}
+		if step.wantName == "" {
+			continue
+		}
+		// GET /users/me returns the user itself, the update wraps it
+		user, _ := got["user"].(map[string]any)
+		if user == nil {
+			user = got
+		}
+		if user["name"] != step.wantName {
+			t.Errorf("%s: name = %v, want %q", step.name, user["name"], step.wantName)
+		}
+	}
+}
+
+// unusedPort returns a local port nothing listens on.
+func unusedPort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+	return port
+}
+
+func TestDegradedStart(t *testing.T) {
+	a := newTestApp(t, map[string]string{
+		"UMKMAI_REDIS_MODE":                 config.RedisModeRedis,
+		"UMKMAI_REDIS_ALLOW_DEGRADED_START": "true",
+		"UMKMAI_REDIS_HOST":                 "127.0.0.1",
+		"UMKMAI_REDIS_PORT":                 unusedPort(t),
+	})
+	a.Readiness.MarkReady()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     any
+		wantCode int
+	}{
+		{"liveness", http.MethodGet, "/livez", nil, http.StatusOK},
+		{"readiness", http.MethodGet, "/readyz", nil, http.StatusServiceUnavailable},
+		{"ping", http.MethodGet, "/api/v1/ping", nil, http.StatusOK},
+		{"login needs sessions", http.MethodPost, "/api/v1/auth/login", map[string]string{"email": "admin@elysian.com", "password": "whatever"}, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]any
+			w := call(t, a, tt.method, tt.path, "", tt.body, &got)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+
+	// Only the cache is reported as failing
+	var ready struct {
+		Status     string                    `json:"status"`
+		Components map[string]map[string]any `json:"components"`
+	}
+	call(t, a, http.MethodGet, "/readyz", "", nil, &ready)
+	if ready.Status != "down" || len(ready.Components) != 1 || ready.Components["cache"] == nil {
+		t.Errorf("/readyz = %+v, want only the cache down", ready)
+	}
+}
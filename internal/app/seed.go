@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	postgresRepo "github.com/Elysian-Rebirth/backend-go/internal/repository/postgres"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+)
+
+// defaultRoles are the built-in roles, as created by the seed migration.
+var defaultRoles = []struct {
+	name        string
+	description string
+	permissions []string
+}{
+	{"admin", "Full system access", []string{"*"}},
+	{"user", "Standard user access", []string{"workflow:read", "workflow:write", "workflow:execute", "workflow:delete"}},
+	{"viewer", "Read-only access", []string{"workflow:read"}},
+}
+
+// SeedAdmin describes the admin account Seed creates.
+type SeedAdmin struct {
+	Email    string
+	Password string
+	Name     string
+}
+
+// SeedResult reports what Seed created. Rows that already existed are left
+// alone, so running Seed again creates nothing.
+type SeedResult struct {
+	RolesCreated int64
+	AdminCreated bool
+}
+
+// Seed creates the built-in roles that are missing and, when admin is not
+// nil, an admin account with the admin role.
+func Seed(ctx context.Context, db *gorm.DB, admin *SeedAdmin) (SeedResult, error) {
+	var result SeedResult
+
+	roles := make([]domain.Role, 0, len(defaultRoles))
+	for _, r := range defaultRoles {
+		permissions, err := json.Marshal(r.permissions)
+		if err != nil {
+			return result, err
+		}
+		description := r.description
+		roles = append(roles, domain.Role{Name: r.name, Description: &description, Permissions: datatypes.JSON(permissions)})
+	}
+	res := db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(&roles)
+	if res.Error != nil {
+		return result, fmt.Errorf("failed to seed roles: %w", res.Error)
+	}
+	result.RolesCreated = res.RowsAffected
+
+	if admin == nil {
+		return result, nil
+	}
+
+	userRepo := postgresRepo.NewUserRepository(db)
+	roleRepo := postgresRepo.NewRoleRepository(db)
+
+	_, err := userRepo.FindByEmail(ctx, admin.Email)
+	if err == nil {
+		return result, nil
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return result, err
+	}
+
+	hash, err := auth.NewPasswordService().HashPassword(admin.Password)
+	if err != nil {
+		return result, fmt.Errorf("failed to hash admin password: %w", err)
+	}
+	user := &domain.User{Email: admin.Email, PasswordHash: hash, Name: admin.Name, IsActive: true}
+	if err := userRepo.Create(ctx, user); err != nil {
+		return result, err
+	}
+	role, err := roleRepo.FindByName(ctx, "admin")
+	if err != nil {
+		return result, err
+	}
+	if err := roleRepo.AssignToUser(ctx, user.ID, role.ID); err != nil {
+		return result, err
+	}
+	result.AdminCreated = true
+
+	return result, nil
+}
+
+// memoryAdminEmail and memoryAdminPasswordHash are the admin account the seed
+// migration creates, so the in-memory database signs in the same way
+const (
+	memoryAdminEmail        = "admin@elysian.com"
+	memoryAdminPasswordHash = "$2a$10$xToTe0TecBkdb8AxHBmH6.7jndz.oXEJz2VSdT6Z3hbaEVI0zeLHO"
+)
+
+// seedMemory creates what the migrations seed in Postgres: the built-in
+// roles and the default admin.
+func seedMemory(ctx context.Context, userRepo repository.UserRepository, roleRepo repository.RoleRepository) error {
+	for _, r := range defaultRoles {
+		permissions, err := json.Marshal(r.permissions)
+		if err != nil {
+			return err
+		}
+		description := r.description
+		role := &domain.Role{Name: r.name, Description: &description, Permissions: datatypes.JSON(permissions)}
+		if err := roleRepo.Create(ctx, role); err != nil {
+			return err
+		}
+	}
+
+	admin := &domain.User{Email: memoryAdminEmail, PasswordHash: memoryAdminPasswordHash, Name: "System Admin", IsActive: true}
+	if err := userRepo.Create(ctx, admin); err != nil {
+		return err
+	}
+	role, err := roleRepo.FindByName(ctx, "admin")
+	if err != nil {
+		return err
+	}
+	return roleRepo.AssignToUser(ctx, admin.ID, role.ID)
+}
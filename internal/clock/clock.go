@@ -0,0 +1,54 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts the current time so time-dependent behavior (token expiry,
+// rotation windows, lockouts) can be tested deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+// New returns a Clock backed by time.Now.
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Mock is a manually controlled Clock for tests. It is safe for concurrent use.
+type Mock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewMock returns a Mock clock frozen at t.
+func NewMock(t time.Time) *Mock {
+	return &Mock{now: t}
+}
+
+func (m *Mock) Now() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.now
+}
+
+// Set moves the clock to t.
+func (m *Mock) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = t
+}
+
+// Advance moves the clock forward by d.
+func (m *Mock) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
@@ -0,0 +1,73 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMock(t *testing.T) {
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		steps []func(m *Mock)
+		want  time.Time
+	}{
+		{"frozen", nil, start},
+		{"advance", []func(*Mock){func(m *Mock) { m.Advance(90 * time.Second) }}, start.Add(90 * time.Second)},
+		{"advance twice", []func(*Mock){
+			func(m *Mock) { m.Advance(time.Hour) },
+			func(m *Mock) { m.Advance(time.Minute) },
+		}, start.Add(time.Hour + time.Minute)},
+		{"set", []func(*Mock){func(m *Mock) { m.Set(start.Add(-24 * time.Hour)) }}, start.Add(-24 * time.Hour)},
+		{"set then advance", []func(*Mock){
+			func(m *Mock) { m.Set(start.Add(time.Hour)) },
+			func(m *Mock) { m.Advance(time.Second) },
+		}, start.Add(time.Hour + time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMock(start)
+			for _, step := range tt.steps {
+				step(m)
+			}
+			if got := m.Now(); !got.Equal(tt.want) {
+				t.Fatalf("Now() = %s, want %s", got, tt.want)
+			}
+			// Reading doesn't move the clock
+			if got := m.Now(); !got.Equal(tt.want) {
+				t.Errorf("second Now() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockConcurrent(t *testing.T) {
+	m := NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	done := make(chan struct{})
+	for range 10 {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for range 100 {
+				m.Advance(time.Millisecond)
+				m.Now()
+			}
+		}()
+	}
+	for range 10 {
+		<-done
+	}
+	if got := m.Now(); !got.Equal(time.Date(2026, 10, 17, 12, 0, 1, 0, time.UTC)) {
+		t.Errorf("Now() = %s after 1000 advances of 1ms, want 12:00:01", got)
+	}
+}
+
+func TestReal(t *testing.T) {
+	before := time.Now()
+	got := New().Now()
+	after := time.Now()
+	if got.Before(before) || got.After(after) {
+		t.Errorf("Now() = %s, want between %s and %s", got, before, after)
+	}
+}
@@ -1,71 +1,303 @@
 package config
 
-import "time"
+import (
+	"net"
+	"strings"
+	"time"
+)
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	RabbitMQ RabbitMQConfig `mapstructure:"rabbitmq"`
-	Storage  StorageConfig  `mapstructure:"storage"`
-	ML       MLConfig       `mapstructure:"ml"`
-	Security SecurityConfig `mapstructure:"security"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	Upload   UploadConfig   `mapstructure:"upload"`
+	Server        ServerConfig        `mapstructure:"server"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Redis         RedisConfig         `mapstructure:"redis"`
+	JWT           JWTConfig           `mapstructure:"jwt"`
+	RabbitMQ      RabbitMQConfig      `mapstructure:"rabbitmq"`
+	Storage       StorageConfig       `mapstructure:"storage"`
+	ML            MLConfig            `mapstructure:"ml"`
+	Security      SecurityConfig      `mapstructure:"security"`
+	Logging       LoggingConfig       `mapstructure:"logging"`
+	Monitoring    MonitoringConfig    `mapstructure:"monitoring"`
+	Upload        UploadConfig        `mapstructure:"upload"`
+	Mail          MailConfig          `mapstructure:"mail"`
+	Webhooks      WebhookConfig       `mapstructure:"webhooks"`
+	Businesses    BusinessConfig      `mapstructure:"businesses"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	Jobs          JobsConfig          `mapstructure:"jobs"`
+	Secrets       SecretsConfig       `mapstructure:"secrets"`
+	Tenancy       TenancyConfig       `mapstructure:"tenancy"`
+	// Features maps flag names to a bool or a rollout percentage (0-100)
+	Features map[string]any `mapstructure:"features"`
+	// Quotas maps metered features to their daily per-user limits
+	Quotas map[string]QuotaConfig `mapstructure:"quotas" validate:"dive"`
+	// MonthlyQuotas maps usage categories (ai_calls, uploads, api_requests)
+	// to their monthly per-user limits
+	MonthlyQuotas map[string]QuotaConfig `mapstructure:"monthly_quotas" validate:"dive"`
+
+	secrets SecretProvider
 }
 
 type ServerConfig struct {
-	Port                    string        `mapstructure:"port" validate:"required"`
-	Host                    string        `mapstructure:"host"`
+	Port string `mapstructure:"port" validate:"required"`
+	Host string `mapstructure:"host"`
+	// Listen overrides host and port with host:port or a unix socket,
+	// unix:///var/run/umkmai.sock
+	Listen string `mapstructure:"listen"`
+	// SocketMode is the octal file mode given to a unix socket
+	SocketMode              string        `mapstructure:"socket_mode"`
 	Environment             string        `mapstructure:"environment" validate:"required,oneof=development staging production"`
 	ReadTimeout             time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout            time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout             time.Duration `mapstructure:"idle_timeout"`
 	GracefulShutdownTimeout time.Duration `mapstructure:"graceful_shutdown_timeout"`
+	// ReadHeaderTimeout bounds reading the request headers; 0 uses read_timeout
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" validate:"min=0"`
+	// MaxHeaderBytes caps the request header size; 0 uses Go's 1 MB default
+	MaxHeaderBytes int `mapstructure:"max_header_bytes" validate:"min=0"`
+	// MaxConnections caps open client connections, idle ones included; further
+	// connections wait to be accepted. 0 means no limit
+	MaxConnections int `mapstructure:"max_connections" validate:"min=0"`
+	// EnableH2C serves HTTP/2 without TLS next to HTTP/1.1, for gateways that
+	// speak h2c to upstreams with prior knowledge (the HTTP/1.1 Upgrade dance
+	// is not supported). TLS listeners negotiate HTTP/2 regardless
+	EnableH2C bool `mapstructure:"enable_h2c"`
+	// HTTP2MaxConcurrentStreams limits the streams per HTTP/2 connection; 0
+	// uses Go's default of 250
+	HTTP2MaxConcurrentStreams int `mapstructure:"http2_max_concurrent_streams" validate:"min=0"`
+	// Warmup delays readiness after the required dependencies are up
+	Warmup time.Duration `mapstructure:"warmup" validate:"min=0"`
+	// HealthCacheTTL is how long /health and /readyz reuse the result of the
+	// dependency checks; 0 runs them on every request
+	HealthCacheTTL time.Duration `mapstructure:"health_cache_ttl" validate:"min=0"`
+	// HealthSlowThreshold marks dependencies answering slower than this as
+	// slow in /health; 0 disables it
+	HealthSlowThreshold time.Duration `mapstructure:"health_slow_threshold" validate:"min=0"`
+	// HealthCheckTimeouts overrides the budget of individual dependency
+	// checks by name, e.g. ml: 5s; the others get 2s
+	HealthCheckTimeouts map[string]time.Duration `mapstructure:"health_check_timeouts" validate:"dive,min=0"`
+	// StartupJitter delays startup by a random duration up to this, so
+	// instances started together don't connect to the database and Redis at
+	// the same moment; 0 disables it
+	StartupJitter time.Duration `mapstructure:"startup_jitter" validate:"min=0"`
+	ExposeConfig  bool          `mapstructure:"expose_config"`
+	// EnablePprof serves the profiler and expvar under /debug, to admins only
+	EnablePprof bool `mapstructure:"enable_pprof"`
+	// JSONNaming checks at startup that response types name their fields
+	// in snake_case: "off", "warn" logs offenders, "strict" refuses to start
+	JSONNaming string `mapstructure:"json_naming" validate:"omitempty,oneof=off warn strict"`
+	// MaxPageOffset is the largest offset list endpoints accept, since
+	// deep offsets make the database read every skipped row; 0 is no limit
+	MaxPageOffset int               `mapstructure:"max_page_offset" validate:"min=0"`
+	Concurrency   ConcurrencyConfig `mapstructure:"concurrency"`
+	TLS           TLSConfig         `mapstructure:"tls"`
+	Swagger       SwaggerConfig     `mapstructure:"swagger"`
+}
+
+// Concurrency limiter modes
+const (
+	ConcurrencyReject = "reject"
+	ConcurrencyWait   = "wait"
+)
+
+// ConcurrencyConfig bounds the requests handled at the same time, to keep
+// the database and CPU from being overrun. Unlike rate limiting it doesn't
+// care who sends the requests.
+type ConcurrencyConfig struct {
+	// MaxInFlight is the number of requests handled at once; 0 disables
+	// the limit
+	MaxInFlight int `mapstructure:"max_in_flight" validate:"min=0"`
+	// Mode is what happens to requests over the limit: "reject" answers 503
+	// right away, "wait" queues them for up to WaitTimeout first
+	Mode        string        `mapstructure:"mode" validate:"oneof=reject wait"`
+	WaitTimeout time.Duration `mapstructure:"wait_timeout" validate:"min=0"`
+	// ExemptPrefixes are paths the limit doesn't apply to, such as the
+	// probes and long-lived streams
+	ExemptPrefixes []string `mapstructure:"exempt_prefixes" validate:"dive,startswith=/"`
+}
+
+// ListenAddress returns the network ("tcp" or "unix") and address the
+// server listens on.
+func (s ServerConfig) ListenAddress() (network, address string) {
+	if path, ok := strings.CutPrefix(s.Listen, "unix://"); ok {
+		return "unix", path
+	}
+	if s.Listen != "" {
+		return "tcp", s.Listen
+	}
+	return "tcp", net.JoinHostPort(s.Host, s.Port)
+}
+
+// Swagger UI access modes
+const (
+	SwaggerPublic   = "public"
+	SwaggerAdmin    = "admin"
+	SwaggerDisabled = "disabled"
+)
+
+type SwaggerConfig struct {
+	// Mode is public, admin (admin bearer token required) or disabled; empty
+	// means public outside production and disabled in production
+	Mode string `mapstructure:"mode" validate:"omitempty,oneof=public admin disabled"`
+	// CacheMaxAge is how long browsers may cache the UI's static assets; the
+	// page and spec are always revalidated
+	CacheMaxAge time.Duration `mapstructure:"cache_max_age" validate:"min=0"`
+}
+
+type TLSConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file" validate:"required_if=Enabled true"`
+	KeyFile  string `mapstructure:"key_file" validate:"required_if=Enabled true"`
+	// MinVersion is 1.2 or 1.3
+	MinVersion string `mapstructure:"min_version" validate:"omitempty,oneof=1.2 1.3"`
+	// CipherSuites restricts TLS 1.2 suites by their Go name; empty uses the Go defaults
+	CipherSuites []string `mapstructure:"cipher_suites"`
+	// RedirectPort, when set, serves a plain HTTP listener that redirects to HTTPS
+	RedirectPort string `mapstructure:"redirect_port"`
 }
 
+// Database drivers
+const (
+	DatabaseDriverPostgres = "postgres"
+	DatabaseDriverMemory   = "memory"
+)
+
 type DatabaseConfig struct {
+	// Driver "memory" keeps users and roles in process instead of Postgres,
+	// for local development of the auth and user flows
+	Driver          string        `mapstructure:"driver" validate:"omitempty,oneof=postgres memory"`
+	URL             string        `mapstructure:"url" mask:"url"`
 	Host            string        `mapstructure:"host" validate:"required"`
 	Port            string        `mapstructure:"port" validate:"required"`
 	User            string        `mapstructure:"user" validate:"required"`
-	Password        string        `mapstructure:"password" validate:"required"`
+	Password        string        `mapstructure:"password" validate:"required" mask:"true" secret:"DB_PASSWORD"`
 	Name            string        `mapstructure:"name" validate:"required"`
 	SSLMode         string        `mapstructure:"ssl_mode"`
 	MaxOpenConns    int           `mapstructure:"max_open_conns" validate:"min=1"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns" validate:"min=1"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	// PrepareStmt caches prepared statements; see database.NewPostgresDB for the trade-off
+	PrepareStmt bool `mapstructure:"prepare_stmt"`
+	// QueryWarnThreshold logs a warning when a single request runs more
+	// queries than this (development only, 0 disables)
+	QueryWarnThreshold int            `mapstructure:"query_warn_threshold" validate:"min=0"`
+	Warmup             DBWarmupConfig `mapstructure:"warmup"`
+	// Params holds extra libpq parameters (e.g. connect_timeout) passed through to the DSN
+	Params map[string]string `mapstructure:"params"`
 }
 
+// DBWarmupConfig opens part of the pool before the instance reports ready.
+type DBWarmupConfig struct {
+	// MinConns is how many connections must be established before the
+	// instance is ready; 0 disables the warm-up. At most max_idle_conns of
+	// them are kept open afterwards
+	MinConns int `mapstructure:"min_conns" validate:"min=0"`
+	// Interval staggers the connections
+	Interval time.Duration `mapstructure:"interval" validate:"min=0"`
+}
+
+// Redis modes
+const (
+	RedisModeRedis  = "redis"
+	RedisModeMemory = "memory"
+)
+
 type RedisConfig struct {
-	Host     string `mapstructure:"host" validate:"required"`
-	Port     string `mapstructure:"port" validate:"required"`
-	Password string `mapstructure:"password"`
-	DB       int    `mapstructure:"db"`
-	PoolSize int    `mapstructure:"pool_size" validate:"min=1"`
+	// Mode "memory" keeps the cache in process instead of connecting to
+	// Redis, for local development with a single instance
+	Mode string `mapstructure:"mode" validate:"omitempty,oneof=redis memory"`
+	// AllowDegradedStart starts the server while Redis is unreachable
+	// instead of refusing to. It then reports the cache down on /readyz and
+	// keeps retrying; the auth routes answer 503 until Redis is back. Off
+	// unless set, so a config without the key still needs Redis
+	AllowDegradedStart bool   `mapstructure:"allow_degraded_start"`
+	URL                string `mapstructure:"url" mask:"url"`
+	Host               string `mapstructure:"host" validate:"required"`
+	Port               string `mapstructure:"port" validate:"required"`
+	Password           string `mapstructure:"password" mask:"true" secret:"REDIS_PASSWORD"`
+	DB                 int    `mapstructure:"db"`
+	PoolSize           int    `mapstructure:"pool_size" validate:"min=1"`
+	TLS                bool   `mapstructure:"tls"`
 }
 
 type JWTConfig struct {
-	Secret             string        `mapstructure:"secret" validate:"required,min=32"`
+	Secret             string        `mapstructure:"secret" validate:"required,min=32" mask:"true" secret:"JWT_SECRET"`
 	AccessTokenExpiry  time.Duration `mapstructure:"access_token_expiry" validate:"required"`
 	RefreshTokenExpiry time.Duration `mapstructure:"refresh_token_expiry" validate:"required"`
 	Issuer             string        `mapstructure:"issuer"`
+	// RefreshIdleExpiry ends a session that wasn't refreshed for this long;
+	// every refresh starts the window again. 0 uses refresh_token_expiry
+	RefreshIdleExpiry time.Duration `mapstructure:"refresh_idle_expiry" validate:"min=0"`
+	// RefreshAbsoluteExpiry ends a session this long after login however
+	// active it is; 0 lets active sessions last indefinitely
+	RefreshAbsoluteExpiry time.Duration `mapstructure:"refresh_absolute_expiry" validate:"min=0"`
+	// WeakSecrets are placeholder words a production secret must not
+	// contain, matched case-insensitively; empty uses defaultWeakSecrets
+	WeakSecrets []string `mapstructure:"weak_secrets"`
+	// SigningKeys switches token signing from the shared secret to RSA or
+	// EC keys. The first key signs new tokens; the others are retired keys
+	// kept so tokens signed before a rotation stay valid
+	SigningKeys []SigningKeyConfig `mapstructure:"signing_keys" validate:"dive"`
+	// TrustedIssuers are external identity providers whose access tokens are
+	// accepted alongside our own
+	TrustedIssuers []TrustedIssuerConfig `mapstructure:"trusted_issuers" validate:"dive"`
+}
+
+// SigningKeyConfig is a private key tokens are signed with. Its public half
+// is published at /.well-known/jwks.json.
+type SigningKeyConfig struct {
+	// ID is the key's kid; never reuse one for a different key
+	ID string `mapstructure:"id" validate:"required"`
+	// PrivateKey is a PEM encoded RSA (2048 bits or more) or EC private key
+	PrivateKey string `mapstructure:"private_key" validate:"required_without=PrivateKeyFile" mask:"true"`
+	// PrivateKeyFile is read instead of PrivateKey
+	PrivateKeyFile string `mapstructure:"private_key_file" validate:"required_without=PrivateKey"`
+}
+
+// TrustedIssuerConfig is an external identity provider. Its tokens must be
+// signed with an RSA or EC key and carry an email claim matching a local
+// user, with email_verified set.
+type TrustedIssuerConfig struct {
+	// Issuer must equal the tokens' iss claim
+	Issuer string `mapstructure:"issuer" validate:"required"`
+	// JWKSURL is where the issuer publishes its signing keys
+	JWKSURL string `mapstructure:"jwks_url" validate:"required_without=PublicKey,omitempty,url"`
+	// PublicKey is a PEM encoded key, used instead of a JWKS URL
+	PublicKey string `mapstructure:"public_key" validate:"required_without=JWKSURL"`
+	// Audience, when set, must be among the tokens' aud claim
+	Audience string `mapstructure:"audience"`
+	// JWKSCacheTTL is how long fetched keys are used before refetching
+	JWKSCacheTTL time.Duration `mapstructure:"jwks_cache_ttl" validate:"min=0"`
 }
 
 type RabbitMQConfig struct {
-	URL         string `mapstructure:"url"`
+	// URL is optional; without it events are not published
+	URL         string `mapstructure:"url" mask:"url"`
 	QueueName   string `mapstructure:"queue_name"`
 	WorkerCount int    `mapstructure:"worker_count" validate:"min=1"`
+	// Exchange is the durable topic exchange events are published to
+	Exchange        string        `mapstructure:"exchange" validate:"required_with=URL"`
+	ChannelPoolSize int           `mapstructure:"channel_pool_size" validate:"min=0"`
+	ReconnectDelay  time.Duration `mapstructure:"reconnect_delay"`
+	// PrefetchCount limits unacknowledged deliveries per consumer channel
+	PrefetchCount int `mapstructure:"prefetch_count" validate:"min=0"`
+	// MaxRetries is how often a failed message is redelivered before it is
+	// dead-lettered to <queue_name>.dlq
+	MaxRetries int `mapstructure:"max_retries" validate:"min=0"`
+	// RetryDelay is how long the first retry waits, doubling with each
+	// further attempt; zero retries immediately
+	RetryDelay time.Duration `mapstructure:"retry_delay" validate:"min=0"`
 }
 
 type StorageConfig struct {
 	Endpoint  string `mapstructure:"endpoint"`
-	AccessKey string `mapstructure:"access_key"`
-	SecretKey string `mapstructure:"secret_key"`
+	AccessKey string `mapstructure:"access_key" mask:"true" secret:"S3_ACCESS_KEY"`
+	SecretKey string `mapstructure:"secret_key" mask:"true" secret:"S3_SECRET_KEY"`
 	Bucket    string `mapstructure:"bucket"`
 	Region    string `mapstructure:"region"`
 	UseSSL    bool   `mapstructure:"use_ssl"`
+	// PublicURL is the base URL objects are served from (e.g. a CDN); empty
+	// uses <endpoint>/<bucket>
+	PublicURL string `mapstructure:"public_url"`
 }
 
 type MLConfig struct {
@@ -73,6 +305,61 @@ type MLConfig struct {
 	Timeout    time.Duration `mapstructure:"timeout"`
 	RetryCount int           `mapstructure:"retry_count" validate:"min=0"`
 	RetryDelay time.Duration `mapstructure:"retry_delay"`
+	// ServiceToken is sent as a bearer token on every request to the service
+	ServiceToken   string               `mapstructure:"service_token" mask:"true" secret:"ML_SERVICE_TOKEN"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	Chat           ChatConfig           `mapstructure:"chat"`
+	Generation     GenerationConfig     `mapstructure:"generation"`
+	Cache          MLCacheConfig        `mapstructure:"cache"`
+}
+
+// MLCacheConfig controls caching of ML responses in Redis, so identical
+// requests aren't recomputed.
+type MLCacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ModelVersion is part of every cache key; change it when the service
+	// deploys a new model so old answers aren't served
+	ModelVersion string `mapstructure:"model_version"`
+	// TTLs maps endpoints (predict, chat) to how long their responses are
+	// kept; endpoints not listed, or with 0, aren't cached
+	TTLs map[string]time.Duration `mapstructure:"ttls"`
+}
+
+type ChatConfig struct {
+	// HistorySize is how many messages of a conversation are kept and sent
+	// to the model as context
+	HistorySize int `mapstructure:"history_size" validate:"min=1"`
+	// HistoryTTL is how long an idle conversation is kept
+	HistoryTTL time.Duration `mapstructure:"history_ttl" validate:"min=0"`
+	// MaxMessageLength caps a single user message, in characters
+	MaxMessageLength int `mapstructure:"max_message_length" validate:"min=1"`
+}
+
+type GenerationConfig struct {
+	MaxTokens   int     `mapstructure:"max_tokens" validate:"min=1"`
+	Temperature float64 `mapstructure:"temperature" validate:"min=0,max=2"`
+	// CacheTTL is how long a result is reused for an identical request,
+	// absorbing double submits; 0 disables caching
+	CacheTTL time.Duration `mapstructure:"cache_ttl" validate:"min=0"`
+	// RateLimitPerMinute caps generation requests per user, on top of the
+	// daily quota; 0 disables it
+	RateLimitPerMinute int `mapstructure:"rate_limit_per_minute" validate:"min=0"`
+	// BlockedWords are rejected in the input and withheld from the output,
+	// matched as whole words regardless of case
+	BlockedWords []string `mapstructure:"blocked_words"`
+}
+
+type CircuitBreakerConfig struct {
+	// FailureThreshold is how many failures within Window open the breaker;
+	// 0 disables it
+	FailureThreshold int           `mapstructure:"failure_threshold" validate:"min=0"`
+	Window           time.Duration `mapstructure:"window" validate:"min=0"`
+	// OpenTimeout is how long the breaker stays open before letting probe
+	// requests through
+	OpenTimeout time.Duration `mapstructure:"open_timeout" validate:"min=0"`
+	// HalfOpenRequests is how many probes may run at once while half-open;
+	// the first success closes the breaker, any failure reopens it
+	HalfOpenRequests int `mapstructure:"half_open_requests" validate:"min=0"`
 }
 
 type SecurityConfig struct {
@@ -82,15 +369,350 @@ type SecurityConfig struct {
 	CORSAllowedMethods         []string `mapstructure:"cors_allowed_methods"`
 	CORSAllowedHeaders         []string `mapstructure:"cors_allowed_headers"`
 	CORSAllowCredentials       bool     `mapstructure:"cors_allow_credentials"`
+	// CORSPolicies are named policies that replace the one above for the
+	// route groups under their path prefixes, e.g. stricter origins for the
+	// admin API
+	CORSPolicies map[string]CORSPolicy `mapstructure:"cors_policies" validate:"dive"`
+	// RedirectAllowedOrigins lists origins (https://app.example.com), bare hosts,
+	// or https-only wildcards (*.example.com) that client-facing links may point to
+	RedirectAllowedOrigins []string            `mapstructure:"redirect_allowed_origins"`
+	DefaultRedirectURL     string              `mapstructure:"default_redirect_url"`
+	Cookie                 CookieConfig        `mapstructure:"cookie"`
+	EmailChange            EmailChangeConfig   `mapstructure:"email_change"`
+	PasswordReset          PasswordResetConfig `mapstructure:"password_reset"`
+	EmailThrottle          EmailThrottleConfig `mapstructure:"email_throttle"`
+	Registration           RegistrationConfig  `mapstructure:"registration"`
+	Names                  NameConfig          `mapstructure:"names"`
+}
+
+// EmailThrottleConfig caps how many emails of each kind one address can be
+// sent, so the endpoints that send them can't be used to flood an inbox.
+// An empty list leaves that kind unlimited.
+type EmailThrottleConfig struct {
+	Verification  []EmailSendLimit `mapstructure:"verification" validate:"dive"`
+	PasswordReset []EmailSendLimit `mapstructure:"password_reset" validate:"dive"`
+}
+
+// EmailSendLimit allows Max emails per Window, counted in fixed windows.
+type EmailSendLimit struct {
+	Max    int           `mapstructure:"max" validate:"min=1"`
+	Window time.Duration `mapstructure:"window" validate:"required"`
+}
+
+type CORSPolicy struct {
+	// PathPrefixes are the route groups the policy applies to, e.g.
+	// /api/v1/admin; the longest matching prefix of any policy wins
+	PathPrefixes   []string `mapstructure:"path_prefixes" validate:"required,min=1,dive,startswith=/"`
+	AllowedOrigins []string `mapstructure:"allowed_origins" validate:"required,min=1"`
+	// AllowedMethods and AllowedHeaders default to the global ones when empty
+	AllowedMethods   []string `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+}
+
+// DefaultCORSPolicy is the policy of the routes no named policy covers.
+func (s SecurityConfig) DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		PathPrefixes:     []string{"/"},
+		AllowedOrigins:   s.CORSAllowedOrigins,
+		AllowedMethods:   s.CORSAllowedMethods,
+		AllowedHeaders:   s.CORSAllowedHeaders,
+		AllowCredentials: s.CORSAllowCredentials,
+	}
+}
+
+type EmailChangeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TokenTTL is how long the confirmation link sent to the new address stays valid
+	TokenTTL time.Duration `mapstructure:"token_ttl" validate:"required_if=Enabled true"`
+	// ConfirmURL is the client page that receives the token as ?token=; a
+	// relative path is resolved against default_redirect_url
+	ConfirmURL string `mapstructure:"confirm_url" validate:"required_if=Enabled true"`
+}
+
+type PasswordResetConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// TokenTTL is how long the reset link stays valid
+	TokenTTL time.Duration `mapstructure:"token_ttl" validate:"required_if=Enabled true"`
+	// ResetURL is the client page that receives the token as ?token=; a
+	// relative path is resolved against default_redirect_url
+	ResetURL string `mapstructure:"reset_url" validate:"required_if=Enabled true"`
+	// UsedTokenTTL is how long a used token is remembered, so reusing it is
+	// reported as already used rather than invalid; 0 uses token_ttl
+	UsedTokenTTL time.Duration `mapstructure:"used_token_ttl" validate:"min=0"`
+}
+
+// RegistrationConfig restricts which email domains can sign up. A listed
+// domain also covers its subdomains. Both lists are optional; an empty
+// allowlist admits every domain that isn't denied.
+type RegistrationConfig struct {
+	AllowedDomains []string `mapstructure:"allowed_domains" validate:"dive,hostname_rfc1123"`
+	DeniedDomains  []string `mapstructure:"denied_domains" validate:"dive,hostname_rfc1123"`
+	// BlockDisposable also denies the built-in list of disposable-email
+	// providers
+	BlockDisposable bool `mapstructure:"block_disposable"`
+}
+
+// NameConfig applies to display names given at registration and on profile
+// updates.
+type NameConfig struct {
+	// BlockedWords are rejected as whole words, ignoring case
+	BlockedWords []string `mapstructure:"blocked_words"`
+}
+
+// CookieConfig sets the attributes of the cookies the API issues. Settings
+// left unset get the defaults of WithDefaults.
+type CookieConfig struct {
+	RefreshTokenName string `mapstructure:"refresh_token_name"`
+	Domain           string `mapstructure:"domain"`
+	Path             string `mapstructure:"path"`
+	// SameSite is lax, strict or none; empty leaves the attribute unset
+	SameSite string `mapstructure:"same_site" validate:"omitempty,oneof=lax strict none"`
+	Secure   *bool  `mapstructure:"secure"`
+	HTTPOnly *bool  `mapstructure:"http_only"`
+	// MaxAge of zero issues session cookies. The refresh token cookie
+	// otherwise lives as long as its token, see jwt.refresh_idle_expiry
+	MaxAge time.Duration `mapstructure:"max_age" validate:"min=0"`
+}
+
+// WithDefaults fills in the settings left unset: the refresh_token name, path
+// /, HttpOnly, and Secure in production only.
+func (c CookieConfig) WithDefaults(production bool) CookieConfig {
+	if c.RefreshTokenName == "" {
+		c.RefreshTokenName = "refresh_token"
+	}
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if c.HTTPOnly == nil {
+		httpOnly := true
+		c.HTTPOnly = &httpOnly
+	}
+	if c.Secure == nil {
+		c.Secure = &production
+	}
+	return c
 }
 
 type LoggingConfig struct {
 	Level  string `mapstructure:"level" validate:"required,oneof=debug info warn error"`
 	Format string `mapstructure:"format" validate:"required,oneof=json text"`
 	Output string `mapstructure:"output" validate:"required,oneof=stdout stderr file"`
+	// File rotation settings, used when Output is "file"
+	FilePath   string `mapstructure:"file_path" validate:"required_if=Output file"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb" validate:"min=0"`
+	MaxBackups int    `mapstructure:"max_backups" validate:"min=0"`
+	MaxAgeDays int    `mapstructure:"max_age_days" validate:"min=0"`
+	Compress   bool   `mapstructure:"compress"`
+	// AccessLog samples the per-request log lines
+	AccessLog AccessLogConfig `mapstructure:"access_log"`
+}
+
+// AccessLogConfig samples successful, fast requests so high-volume routes
+// such as health probes don't drown the rest. Other requests are always
+// logged.
+type AccessLogConfig struct {
+	// SampleRate is the share of requests logged, 0-1, unless a route
+	// overrides it. Unset logs every request
+	SampleRate *float64 `mapstructure:"sample_rate" validate:"omitempty,min=0,max=1"`
+	// SlowThreshold logs every request taking at least this long; 0
+	// disables the rule
+	SlowThreshold time.Duration          `mapstructure:"slow_threshold" validate:"min=0"`
+	Routes        []AccessLogRouteConfig `mapstructure:"routes" validate:"dive"`
+}
+
+// AccessLogRouteConfig overrides the sample rate for paths starting with
+// Prefix. The longest matching prefix wins.
+type AccessLogRouteConfig struct {
+	Prefix     string  `mapstructure:"prefix" validate:"required,startswith=/"`
+	SampleRate float64 `mapstructure:"sample_rate" validate:"min=0,max=1"`
+}
+
+// MonitoringConfig configures error reporting to Sentry. Reporting is off
+// while DSN is empty.
+type MonitoringConfig struct {
+	DSN string `mapstructure:"dsn" validate:"omitempty,url" mask:"true"`
+	// Environment defaults to server.environment
+	Environment string  `mapstructure:"environment"`
+	SampleRate  float64 `mapstructure:"sample_rate" validate:"min=0,max=1"`
 }
 
 type UploadConfig struct {
-	MaxFileSize      int64    `mapstructure:"max_file_size" validate:"min=1"`
-	AllowedFileTypes []string `mapstructure:"allowed_file_types"`
+	MaxFileSize      int64               `mapstructure:"max_file_size" validate:"min=1"`
+	AllowedFileTypes []string            `mapstructure:"allowed_file_types"`
+	OrphanCleanup    OrphanCleanupConfig `mapstructure:"orphan_cleanup"`
+	Avatar           AvatarConfig        `mapstructure:"avatar"`
+}
+
+// AvatarConfig controls avatar uploads, which are decoded, cropped to
+// squares and stored re-encoded in each of Sizes.
+type AvatarConfig struct {
+	MaxFileSize int64 `mapstructure:"max_file_size" validate:"min=1"`
+	// MaxPixels caps width x height of the decoded image, so a small file
+	// can't expand into a huge bitmap
+	MaxPixels int `mapstructure:"max_pixels" validate:"min=1"`
+	// Sizes are the edge lengths of the stored variants; the avatar URL
+	// points to the largest
+	Sizes   []int `mapstructure:"sizes" validate:"min=1,dive,min=16,max=2048"`
+	Quality int   `mapstructure:"quality" validate:"min=1,max=100"`
+	// Timeout bounds the processing of a single upload
+	Timeout time.Duration `mapstructure:"timeout" validate:"min=0"`
+	// MaxConcurrent caps how many uploads are processed at once, which
+	// bounds the memory spent on decoded images
+	MaxConcurrent int `mapstructure:"max_concurrent" validate:"min=1"`
+}
+
+// OrphanCleanupConfig controls the job removing stored objects that no file
+// or avatar refers to.
+type OrphanCleanupConfig struct {
+	// Prefixes are the key prefixes scanned, in order
+	Prefixes []string `mapstructure:"prefixes"`
+	// MinAge spares recent objects whose record may not be written yet
+	MinAge time.Duration `mapstructure:"min_age" validate:"min=0"`
+	// Delete removes orphans; when false the job only logs what it would remove
+	Delete bool `mapstructure:"delete"`
+	// PageSize is how many objects are listed and looked up at a time
+	PageSize int `mapstructure:"page_size" validate:"min=0,max=1000"`
+	// Budget bounds a single run; the next run resumes where it stopped
+	Budget time.Duration `mapstructure:"budget" validate:"min=0"`
+}
+
+// WebhookConfig controls outbound webhooks. Failed deliveries are retried by
+// the message consumer, following rabbitmq.max_retries and retry_delay.
+type BusinessConfig struct {
+	// MaxPerUser caps how many businesses a user can own
+	MaxPerUser int `mapstructure:"max_per_user" validate:"min=1"`
+	// CacheTTL is how long a business is cached after being read; 0
+	// disables caching
+	CacheTTL time.Duration `mapstructure:"cache_ttl" validate:"min=0"`
+}
+
+type WebhookConfig struct {
+	// Timeout bounds a single delivery attempt
+	Timeout time.Duration `mapstructure:"timeout" validate:"min=0"`
+	// DisableAfter switches a webhook off after this many deliveries in a
+	// row have failed; 0 never disables
+	DisableAfter int `mapstructure:"disable_after" validate:"min=0"`
+	// MaxPerUser caps how many webhooks a user can register
+	MaxPerUser int `mapstructure:"max_per_user" validate:"min=1"`
+	// AllowInsecureURLs accepts http:// targets; keep it off in production
+	AllowInsecureURLs bool `mapstructure:"allow_insecure_urls"`
+	// AllowPrivateTargets lets deliveries reach loopback, private and
+	// link-local addresses, e.g. a receiver on localhost in development;
+	// keep it off in production
+	AllowPrivateTargets bool `mapstructure:"allow_private_targets"`
+}
+
+// NotificationsConfig controls the real-time notification stream.
+type NotificationsConfig struct {
+	// ReplaySize is how many recent events per user are kept for clients
+	// that reconnect; 0 disables replay
+	ReplaySize int64 `mapstructure:"replay_size" validate:"min=0"`
+	// ReplayTTL is how long the replay buffer outlives the last event
+	ReplayTTL time.Duration `mapstructure:"replay_ttl" validate:"min=0"`
+	// Heartbeat is how often an idle stream gets a keep-alive comment
+	Heartbeat time.Duration `mapstructure:"heartbeat" validate:"min=0"`
+}
+
+// JobsConfig controls the in-process job scheduler.
+type JobsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Schedules maps job names to their settings; jobs not listed only run
+	// when triggered from the admin API
+	Schedules map[string]JobConfig `mapstructure:"schedules" validate:"dive"`
+}
+
+type JobConfig struct {
+	// Interval between runs; 0 only runs the job when triggered
+	Interval time.Duration `mapstructure:"interval" validate:"min=0"`
+	// Timeout cancels a run that takes longer
+	Timeout time.Duration `mapstructure:"timeout" validate:"min=0"`
+	// Retention is how old records must be before a cleanup job removes them
+	Retention time.Duration `mapstructure:"retention" validate:"min=0"`
+}
+
+type MailConfig struct {
+	// Provider is smtp, ses (via the SES SMTP interface) or log
+	Provider    string        `mapstructure:"provider" validate:"required,oneof=smtp ses log"`
+	Host        string        `mapstructure:"host" validate:"required_unless=Provider log"`
+	Port        int           `mapstructure:"port" validate:"required_unless=Provider log,min=0,max=65535"`
+	Username    string        `mapstructure:"username"`
+	Password    string        `mapstructure:"password" mask:"true" secret:"SMTP_PASSWORD"`
+	FromAddress string        `mapstructure:"from_address" validate:"required"`
+	FromName    string        `mapstructure:"from_name"`
+	TLSMode     string        `mapstructure:"tls_mode" validate:"oneof=none starttls tls"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+	// Messages are sent from an in-process queue; a failed send is retried
+	// up to MaxAttempts times, doubling RetryDelay each time
+	QueueSize   int           `mapstructure:"queue_size" validate:"min=0"`
+	Workers     int           `mapstructure:"workers" validate:"min=0"`
+	MaxAttempts int           `mapstructure:"max_attempts" validate:"min=0"`
+	RetryDelay  time.Duration `mapstructure:"retry_delay"`
+}
+
+// QuotaConfig is the per-user limit of one feature in its window, a day
+// for quotas and a month for monthly_quotas. A limit of 0 means
+// unlimited. Users get the most generous limit of their roles, or Default if
+// none of their roles is listed.
+type QuotaConfig struct {
+	Default int64            `mapstructure:"default" validate:"min=0"`
+	Roles   map[string]int64 `mapstructure:"roles" validate:"dive,min=0"`
+}
+
+// LimitFor returns the limit that applies to a user with the given roles.
+func (q QuotaConfig) LimitFor(roles []string) int64 {
+	limit, matched := q.Default, false
+	for _, role := range roles {
+		roleLimit, ok := q.Roles[strings.ToLower(role)]
+		if !ok {
+			continue
+		}
+		switch {
+		case roleLimit == 0:
+			return 0
+		case !matched || roleLimit > limit:
+			limit, matched = roleLimit, true
+		}
+	}
+	return limit
+}
+
+// TenancyConfig scopes users and roles to tenants. When disabled every
+// request shares one user space, as before tenants existed.
+type TenancyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Header names the request header carrying the tenant ID
+	Header string `mapstructure:"header" validate:"required_if=Enabled true"`
+	// BaseDomain lets the tenant be taken from the subdomain, e.g.
+	// warung.umkmai.id with base domain umkmai.id; the header wins when both
+	// are present
+	BaseDomain string `mapstructure:"base_domain"`
+}
+
+type SecretsConfig struct {
+	// Backend is env (the default), vault, or a backend added with
+	// RegisterSecretBackend
+	Backend string      `mapstructure:"backend"`
+	Vault   VaultConfig `mapstructure:"vault"`
+	// Options configure registered backends, e.g. a region or secret ID
+	Options map[string]string `mapstructure:"options"`
+}
+
+type VaultConfig struct {
+	Address string `mapstructure:"address"`
+	// AuthMethod is token, approle or kubernetes
+	AuthMethod string `mapstructure:"auth_method" validate:"omitempty,oneof=token approle kubernetes"`
+	Token      string `mapstructure:"token" mask:"true"`
+	RoleID     string `mapstructure:"role_id"`
+	SecretID   string `mapstructure:"secret_id" mask:"true"`
+	// Role is the Vault role bound to the service account for kubernetes auth
+	Role      string `mapstructure:"role"`
+	Namespace string `mapstructure:"namespace"`
+	// MountPath is the KV mount and SecretPath the secret read from it;
+	// {env} in SecretPath is replaced with server.environment
+	MountPath  string `mapstructure:"mount_path"`
+	SecretPath string `mapstructure:"secret_path"`
+	// KVVersion is the KV engine version of the mount, 1 or 2; 0 asks Vault
+	KVVersion int           `mapstructure:"kv_version" validate:"omitempty,oneof=1 2"`
+	Timeout   time.Duration `mapstructure:"timeout"`
 }
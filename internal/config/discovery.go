@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrConfigNotFound is returned (wrapped) when the base config file can't be
+// found
+var ErrConfigNotFound = errors.New("config file not found")
+
+// defaultConfigDirs are searched, in order, when neither CONFIG_FILE nor
+// CONFIG_PATH is set
+var defaultConfigDirs = []string{"./config", "."}
+
+var configExtensions = []string{".yaml", ".yml"}
+
+// configFiles are the files a config is built from. Overlay is empty when
+// there is no environment-specific file.
+type configFiles struct {
+	Base    string
+	Overlay string
+}
+
+// findConfigFiles locates the base config and the overlay for env. CONFIG_FILE
+// names the base file directly; CONFIG_PATH names a directory to search
+// instead of the defaults, or a file, e.g. a mounted ConfigMap. The overlay is
+// looked for next to the base file, named after it with the environment
+// inserted before the extension (config.yml -> config.production.yml).
+func findConfigFiles(env string) (configFiles, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return explicitConfigFile("CONFIG_FILE", path, env)
+	}
+
+	dirs := defaultConfigDirs
+	if path := os.Getenv("CONFIG_PATH"); path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return configFiles{}, fmt.Errorf("%w: CONFIG_PATH %s: %v", ErrConfigNotFound, path, err)
+		}
+		if !info.IsDir() {
+			return explicitConfigFile("CONFIG_PATH", path, env)
+		}
+		dirs = []string{path}
+	}
+
+	for _, dir := range dirs {
+		for _, ext := range configExtensions {
+			base := filepath.Join(dir, "config"+ext)
+			if isFile(base) {
+				return configFiles{Base: base, Overlay: findOverlay(base, env)}, nil
+			}
+		}
+	}
+
+	return configFiles{}, fmt.Errorf("%w: looked for config%s in %s",
+		ErrConfigNotFound, strings.Join(configExtensions, ", config"), strings.Join(dirs, ", "))
+}
+
+func explicitConfigFile(source, path, env string) (configFiles, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return configFiles{}, fmt.Errorf("%w: %s %s: %v", ErrConfigNotFound, source, path, err)
+	}
+	if info.IsDir() {
+		return configFiles{}, fmt.Errorf("%w: %s %s is a directory", ErrConfigNotFound, source, path)
+	}
+	return configFiles{Base: path, Overlay: findOverlay(path, env)}, nil
+}
+
+// findOverlay returns the environment-specific file next to base, if any.
+// Both extensions are tried, so config.yaml can be paired with
+// config.production.yml.
+func findOverlay(base, env string) string {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for _, candidate := range append([]string{ext}, configExtensions...) {
+		path := fmt.Sprintf("%s.%s%s", stem, env, candidate)
+		if isFile(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindConfigFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		// files are created under the test directory, which is also the
+		// working directory
+		files       []string
+		configFile  string
+		configPath  string
+		wantBase    string
+		wantOverlay string
+		wantErr     bool
+	}{
+		{"default dir", []string{"config/config.yaml", "config/config.production.yaml"}, "", "", "config/config.yaml", "config/config.production.yaml", false},
+		{"default dir without overlay", []string{"config/config.yaml", "config/config.staging.yaml"}, "", "", "config/config.yaml", "", false},
+		{"working dir", []string{"config.yml"}, "", "", "config.yml", "", false},
+		{"config dir wins", []string{"config.yaml", "config/config.yml"}, "", "", "config/config.yml", "", false},
+		{"overlay with the other extension", []string{"config/config.yaml", "config/config.production.yml"}, "", "", "config/config.yaml", "config/config.production.yml", false},
+		{"nothing found", []string{"settings.yaml"}, "", "", "", "", true},
+
+		{"CONFIG_FILE", []string{"etc/app.yaml", "etc/app.production.yaml", "config/config.yaml"}, "etc/app.yaml", "", "etc/app.yaml", "etc/app.production.yaml", false},
+		{"CONFIG_FILE wins over CONFIG_PATH", []string{"etc/app.yaml", "mnt/config.yaml"}, "etc/app.yaml", "mnt", "etc/app.yaml", "", false},
+		{"CONFIG_FILE missing", []string{"config/config.yaml"}, "etc/app.yaml", "", "", "", true},
+		{"CONFIG_FILE is a directory", []string{"etc/app.yaml"}, "etc", "", "", "", true},
+
+		{"CONFIG_PATH dir", []string{"mnt/config.yaml", "mnt/config.production.yaml", "config/config.yaml"}, "", "mnt", "mnt/config.yaml", "mnt/config.production.yaml", false},
+		{"CONFIG_PATH file", []string{"mnt/base.yml"}, "", "mnt/base.yml", "mnt/base.yml", "", false},
+		{"CONFIG_PATH dir without a config", []string{"mnt/other.yaml", "config/config.yaml"}, "", "mnt", "", "", true},
+		{"CONFIG_PATH missing", []string{"config/config.yaml"}, "", "mnt", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			for _, name := range tt.files {
+				if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(name, []byte("server:\n  port: 8080\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("CONFIG_FILE", tt.configFile)
+			t.Setenv("CONFIG_PATH", tt.configPath)
+
+			files, err := findConfigFiles("production")
+			if tt.wantErr {
+				if !errors.Is(err, ErrConfigNotFound) {
+					t.Fatalf("findConfigFiles() error = %v, want %v", err, ErrConfigNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if files.Base != tt.wantBase || files.Overlay != tt.wantOverlay {
+				t.Errorf("findConfigFiles() = %+v, want base %q and overlay %q", files, tt.wantBase, tt.wantOverlay)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// defaultEnvPrefix namespaces config environment variables, e.g.
+// UMKMAI_SERVER_READ_TIMEOUT for server.read_timeout. It can be changed with
+// CONFIG_ENV_PREFIX.
+const defaultEnvPrefix = "UMKMAI"
+
+func envPrefix() string {
+	if v := os.Getenv("CONFIG_ENV_PREFIX"); v != "" {
+		return strings.TrimSuffix(strings.ToUpper(v), "_")
+	}
+	return defaultEnvPrefix
+}
+
+// bindEnv binds every config key to PREFIX_SECTION_FIELD only; unprefixed
+// names such as SERVER_PORT are ignored, apart from the legacy ones applied
+// by overrideWithEnv. AutomaticEnv alone only sees keys that already appear
+// in a config file, so the keys are taken from the Config struct as well as
+// from the files that were read.
+func bindEnv(v *viper.Viper, prefix string) error {
+	keys := configKeys(reflect.TypeOf(Config{}), "")
+	keys = append(keys, v.AllKeys()...)
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if err := v.BindEnv(key, prefix+"_"+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// configKeys lists the dotted mapstructure keys of every leaf field.
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("mapstructure")
+		if !sf.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		switch sf.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, configKeys(sf.Type, name)...)
+		case reflect.Map:
+			// map keys are only known once the config files are read
+		default:
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
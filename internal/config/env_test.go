@@ -0,0 +1,125 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// baseYAML stands in for config.yml; keys missing from it must still bind.
+const baseYAML = `
+server:
+  read_timeout: 15s
+database:
+  max_open_conns: 25
+jwt:
+  issuer: "elysian"
+`
+
+func TestBindEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		check func(cfg *Config) bool
+	}{
+		{"no overrides", nil, func(cfg *Config) bool {
+			return cfg.Server.ReadTimeout == 15*time.Second && cfg.Database.MaxOpenConns == 25 && cfg.JWT.Issuer == "elysian"
+		}},
+		{"prefixed duration", map[string]string{"UMKMAI_SERVER_READ_TIMEOUT": "45s"}, func(cfg *Config) bool {
+			return cfg.Server.ReadTimeout == 45*time.Second
+		}},
+		{"prefixed int", map[string]string{"UMKMAI_DATABASE_MAX_OPEN_CONNS": "42"}, func(cfg *Config) bool {
+			return cfg.Database.MaxOpenConns == 42
+		}},
+		{"key missing from the file", map[string]string{"UMKMAI_REDIS_POOL_SIZE": "7"}, func(cfg *Config) bool {
+			return cfg.Redis.PoolSize == 7
+		}},
+		{"bool", map[string]string{"UMKMAI_REDIS_ALLOW_DEGRADED_START": "true"}, func(cfg *Config) bool {
+			return cfg.Redis.AllowDegradedStart
+		}},
+		// A config that predates the key must not start without Redis
+		{"degraded start unset", nil, func(cfg *Config) bool {
+			return !cfg.Redis.AllowDegradedStart
+		}},
+		{"deeply nested", map[string]string{"UMKMAI_SECURITY_COOKIE_SAME_SITE": "strict"}, func(cfg *Config) bool {
+			return cfg.Security.Cookie.SameSite == "strict"
+		}},
+		// Plain names such as SERVER_PORT are too easily set for something else
+		{"unprefixed ignored", map[string]string{"SERVER_PORT": "9999", "JWT_ISSUER": "plain"}, func(cfg *Config) bool {
+			return cfg.Server.Port == "" && cfg.JWT.Issuer == "elysian"
+		}},
+		{"custom prefix", map[string]string{"CONFIG_ENV_PREFIX": "shop_", "SHOP_JWT_ISSUER": "shop", "UMKMAI_JWT_ISSUER": "ignored"}, func(cfg *Config) bool {
+			return cfg.JWT.Issuer == "shop"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			v := viper.New()
+			v.SetConfigType("yaml")
+			if err := v.ReadConfig(strings.NewReader(baseYAML)); err != nil {
+				t.Fatal(err)
+			}
+			if err := bindEnv(v, envPrefix()); err != nil {
+				t.Fatal(err)
+			}
+			var cfg Config
+			if err := v.Unmarshal(&cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.check(&cfg) {
+				t.Errorf("env %v not applied: server %+v, database max_open_conns %d, redis %+v, jwt issuer %q, cookie same_site %q",
+					tt.env, cfg.Server, cfg.Database.MaxOpenConns, cfg.Redis, cfg.JWT.Issuer, cfg.Security.Cookie.SameSite)
+			}
+		})
+	}
+}
+
+func TestLegacyEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(cfg *Config) bool
+		wantErr bool
+	}{
+		{"port and host", map[string]string{"PORT": "9090", "HOST": "127.0.0.1"}, func(cfg *Config) bool {
+			return cfg.Server.Port == "9090" && cfg.Server.Host == "127.0.0.1"
+		}, false},
+		{"database", map[string]string{"DB_HOST": "db", "DB_SSL_MODE": "require"}, func(cfg *Config) bool {
+			return cfg.Database.Host == "db" && cfg.Database.SSLMode == "require"
+		}, false},
+		{"smtp port", map[string]string{"SMTP_PORT": "2525"}, func(cfg *Config) bool {
+			return cfg.Mail.Port == 2525
+		}, false},
+		{"invalid smtp port", map[string]string{"SMTP_PORT": "twenty"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			cfg := &Config{}
+			err := overrideWithEnv(cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("overrideWithEnv() accepted an invalid value")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("env %v not applied", tt.env)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes one invalid configuration value. Key is the path used
+// in the config files (e.g. "database.max_open_conns").
+type FieldError struct {
+	Key     string
+	Value   any
+	Message string
+}
+
+func (e FieldError) String() string {
+	if e.Value == nil {
+		return fmt.Sprintf("%s: %s", e.Key, e.Message)
+	}
+	return fmt.Sprintf("%s: %s (got %q)", e.Key, e.Message, fmt.Sprint(e.Value))
+}
+
+// ValidationError collects every problem found while validating the config so
+// they can all be fixed in one go.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if len(e.Errors) == 1 {
+		b.WriteString("1 configuration error:")
+	} else {
+		fmt.Fprintf(&b, "%d configuration errors:", len(e.Errors))
+	}
+	for i, fe := range e.Errors {
+		fmt.Fprintf(&b, "\n  %d. %s", i+1, fe)
+	}
+	return b.String()
+}
+
+// add records a problem with key. Only the first problem per key is kept, so
+// a value rejected by its struct tag is not reported again by a custom rule.
+// Values of fields tagged with `mask` are masked.
+func (e *ValidationError) add(key string, value any, format string, args ...any) {
+	for _, existing := range e.Errors {
+		if existing.Key == key {
+			return
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		switch maskedKeys[key] {
+		case "true":
+			if s != "" {
+				value = maskedValue
+			}
+		case "url":
+			value = maskURL(s)
+		}
+	}
+
+	e.Errors = append(e.Errors, FieldError{
+		Key:     key,
+		Value:   value,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// errOrNil returns e as an error, or nil when nothing was recorded.
+func (e *ValidationError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// addStructErrors translates validator errors into FieldErrors keyed by
+// config path instead of Go struct path.
+func (e *ValidationError) addStructErrors(err error) {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		e.Errors = append(e.Errors, FieldError{Key: "config", Message: err.Error()})
+		return
+	}
+
+	for _, fe := range verrs {
+		// Namespace is "Config.<key path>" thanks to the mapstructure tag name func
+		_, key, _ := strings.Cut(fe.Namespace(), ".")
+
+		var value any
+		if fe.Tag() != "required" && !strings.HasPrefix(fe.Tag(), "required_") {
+			value = fe.Value()
+		}
+		e.add(key, value, "%s", describeTag(fe))
+	}
+}
+
+func describeTag(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return fmt.Sprintf("is required when %s", describeCondition(fe.Param()))
+	case "required_unless":
+		return fmt.Sprintf("is required unless %s", describeCondition(fe.Param()))
+	case "required_with":
+		return fmt.Sprintf("is required when %s is set", strings.ToLower(fe.Param()))
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed '%s' validation", fe.Tag())
+	}
+}
+
+// describeCondition turns a "Field value" validator param into "field is value".
+func describeCondition(param string) string {
+	field, value, _ := strings.Cut(param, " ")
+	return fmt.Sprintf("%s is %s", strings.ToLower(field), value)
+}
+
+// maskedKeys maps config paths to their `mask` tag, so validation output
+// never echoes a secret.
+var maskedKeys = collectMaskedKeys(reflect.TypeOf(Config{}), "")
+
+func collectMaskedKeys(t reflect.Type, prefix string) map[string]string {
+	keys := make(map[string]string)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type.PkgPath() == t.PkgPath() {
+			for k, v := range collectMaskedKeys(sf.Type, name) {
+				keys[k] = v
+			}
+			continue
+		}
+		if mode := sf.Tag.Get("mask"); mode != "" {
+			keys[name] = mode
+		}
+	}
+	return keys
+}
+
+// mapstructureTagName makes validator report fields by their config key.
+func mapstructureTagName(sf reflect.StructField) string {
+	name := sf.Tag.Get("mapstructure")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
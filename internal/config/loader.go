@@ -1,9 +1,12 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -11,7 +14,13 @@ import (
 	"github.com/spf13/viper"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(mapstructureTagName)
+	return v
+}
 
 // load reads configuration from multiple sources and returns a validated Config
 func Load() (*Config, error) {
@@ -24,27 +33,37 @@ func Load() (*Config, error) {
 		env = "development"
 	}
 
+	files, err := findConfigFiles(env)
+	if err != nil {
+		return nil, err
+	}
+
 	// setup Viper
 	v := viper.New()
-	v.SetConfigName("config")
 	v.SetConfigType("yaml")
-	v.AddConfigPath("./config")
-	v.AddConfigPath(".")
 
-	// read default config
+	// read base config
+	v.SetConfigFile(files.Base)
 	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read default config: %w", err)
+		return nil, fmt.Errorf("failed to read config %s: %w", files.Base, err)
 	}
+	log.Printf("Loaded config from %s", files.Base)
 
 	// merge environment-specific config
-	v.SetConfigName(fmt.Sprintf("config.%s", env))
-	if err := v.MergeInConfig(); err != nil {
-		log.Printf("No environment-specific config found for '%s', using defaults", env)
+	if files.Overlay != "" {
+		v.SetConfigFile(files.Overlay)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to merge config %s: %w", files.Overlay, err)
+		}
+		log.Printf("Merged %s config from %s", env, files.Overlay)
+	} else {
+		log.Printf("No config overlay for environment '%s' next to %s, using base config", env, files.Base)
 	}
 
-	// enable environment variable override
-	v.AutomaticEnv()
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	// bind every key to UMKMAI_SECTION_FIELD
+	if err := bindEnv(v, envPrefix()); err != nil {
+		return nil, fmt.Errorf("failed to bind environment variables: %w", err)
+	}
 
 	// unmarshal config into struct
 	var cfg Config
@@ -52,24 +71,45 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// expand DATABASE_URL / REDIS_URL into individual fields
+	if err := applyConnectionURLs(&cfg); err != nil {
+		return nil, err
+	}
+
 	// overide with environment variables
-	overrideWithEnv(&cfg)
+	if err := overrideWithEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to apply environment overrides: %w", err)
+	}
 
-	// validate configuration
-	if err := validate.Struct(&cfg); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	// resolve secret fields from the configured backend
+	provider, err := newSecretProvider(context.Background(), &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize secrets backend: %w", err)
 	}
+	if err := resolveSecrets(context.Background(), &cfg, provider); err != nil {
+		provider.Close()
+		return nil, err
+	}
+	cfg.secrets = provider
 
-	// additional custom validation
-	if err := validateCustomRules(&cfg); err != nil {
-		return nil, fmt.Errorf("custom validation failed: %w", err)
+	// validate configuration, collecting every problem before failing
+	errs := &ValidationError{}
+	if err := validate.Struct(&cfg); err != nil {
+		errs.addStructErrors(err)
+	}
+	validateCustomRules(&cfg, errs)
+	if err := errs.errOrNil(); err != nil {
+		provider.Close()
+		return nil, err
 	}
 
 	return &cfg, nil
 }
 
-// overrideWithEnv overrides config values with environment variables
-func overrideWithEnv(cfg *Config) {
+// overrideWithEnv applies the legacy environment variable names that do not
+// follow the SECTION_FIELD scheme handled by bindEnv. They are applied last and
+// therefore win over prefixed variables.
+func overrideWithEnv(cfg *Config) error {
 	// Server
 	if v := os.Getenv("PORT"); v != "" {
 		cfg.Server.Port = v
@@ -101,7 +141,7 @@ func overrideWithEnv(cfg *Config) {
 		cfg.Database.SSLMode = v
 	}
 
-	// Redis
+	// Redis, re-applied so they still win over REDIS_URL
 	if v := os.Getenv("REDIS_HOST"); v != "" {
 		cfg.Redis.Host = v
 	}
@@ -112,16 +152,6 @@ func overrideWithEnv(cfg *Config) {
 		cfg.Redis.Password = v
 	}
 
-	// JWT
-	if v := os.Getenv("JWT_SECRET"); v != "" {
-		cfg.JWT.Secret = v
-	}
-
-	// RabbitMQ
-	if v := os.Getenv("RABBITMQ_URL"); v != "" {
-		cfg.RabbitMQ.URL = v
-	}
-
 	// Storage
 	if v := os.Getenv("S3_ENDPOINT"); v != "" {
 		cfg.Storage.Endpoint = v
@@ -136,26 +166,76 @@ func overrideWithEnv(cfg *Config) {
 		cfg.Storage.Bucket = v
 	}
 
-	// ML Service
-	if v := os.Getenv("ML_SERVICE_URL"); v != "" {
-		cfg.ML.ServiceURL = v
+	// Secrets
+	if v := os.Getenv("VAULT_ADDR"); v != "" {
+		cfg.Secrets.Vault.Address = v
+	}
+	if v := os.Getenv("VAULT_NAMESPACE"); v != "" {
+		cfg.Secrets.Vault.Namespace = v
+	}
+	if v, err := envOrFile("VAULT_TOKEN"); err != nil {
+		return err
+	} else if v != "" {
+		cfg.Secrets.Vault.Token = v
+	}
+	if v := os.Getenv("VAULT_ROLE_ID"); v != "" {
+		cfg.Secrets.Vault.RoleID = v
+	}
+	if v, err := envOrFile("VAULT_SECRET_ID"); err != nil {
+		return err
+	} else if v != "" {
+		cfg.Secrets.Vault.SecretID = v
+	}
+
+	// Mail
+	if v := os.Getenv("SMTP_HOST"); v != "" {
+		cfg.Mail.Host = v
+	}
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP_PORT '%s'", v)
+		}
+		cfg.Mail.Port = port
 	}
+	if v, err := envOrFile("SMTP_USERNAME"); err != nil {
+		return err
+	} else if v != "" {
+		cfg.Mail.Username = v
+	}
+	if v, err := envOrFile("SMTP_PASSWORD"); err != nil {
+		return err
+	} else if v != "" {
+		cfg.Mail.Password = v
+	}
+
+	return nil
 }
 
-// MaskSensitive returns a copy of the config with sensitive values masked
-func (c *Config) MaskSensitive() *Config {
-	masked := *c
-	masked.Database.Password = "***MASKED***"
-	masked.Redis.Password = "***MASKED***"
-	masked.JWT.Secret = "***MASKED***"
-	masked.Storage.AccessKey = "***MASKED***"
-	masked.Storage.SecretKey = "***MASKED***"
-	return &masked
+// envOrFile returns the value of the environment variable name, or, when it is
+// unset, the trimmed contents of the file named by name_FILE (the convention
+// used for Docker and Kubernetes secrets).
+func envOrFile(name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
 }
 
 // GetDatabaseDSN returns the database connection string
 func (c *Config) GetDatabaseDSN() string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host,
 		c.Database.Port,
@@ -164,6 +244,17 @@ func (c *Config) GetDatabaseDSN() string {
 		c.Database.Name,
 		c.Database.SSLMode,
 	)
+
+	keys := make([]string, 0, len(c.Database.Params))
+	for k := range c.Database.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		dsn += fmt.Sprintf(" %s=%s", k, c.Database.Params[k])
+	}
+
+	return dsn
 }
 
 // GetRedisDSN returns the Redis connection string
@@ -174,6 +265,19 @@ func (c *Config) GetRedisDSN() string {
 	return fmt.Sprintf("%s:%s", c.Redis.Host, c.Redis.Port)
 }
 
+// SecretProvider returns the backend secrets were resolved from
+func (c *Config) SecretProvider() SecretProvider {
+	return c.secrets
+}
+
+// Close releases the secrets backend, stopping any background token renewal
+func (c *Config) Close() error {
+	if c.secrets == nil {
+		return nil
+	}
+	return c.secrets.Close()
+}
+
 // IsDevelopment returns true if environment is development
 func (c *Config) IsDevelopment() bool {
 	return c.Server.Environment == "development"
@@ -183,3 +287,15 @@ func (c *Config) IsDevelopment() bool {
 func (c *Config) IsProduction() bool {
 	return c.Server.Environment == "production"
 }
+
+// SwaggerMode returns the configured Swagger UI mode, defaulting to disabled
+// in production and public elsewhere
+func (c *Config) SwaggerMode() string {
+	if c.Server.Swagger.Mode != "" {
+		return c.Server.Swagger.Mode
+	}
+	if c.IsProduction() {
+		return SwaggerDisabled
+	}
+	return SwaggerPublic
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+)
+
+const maskedValue = "***MASKED***"
+
+// MaskSensitive returns a copy of the config with sensitive values masked.
+// Fields are selected by their `mask` struct tag: `mask:"true"` replaces the
+// whole value, `mask:"url"` only hides the password part of a connection URL.
+// Tagging new secret fields is all that is needed to keep them out of output.
+func (c *Config) MaskSensitive() *Config {
+	masked := *c
+	maskStruct(reflect.ValueOf(&masked).Elem())
+	return &masked
+}
+
+func maskStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.Struct:
+			maskStruct(field)
+		case reflect.Slice:
+			// Slices share their backing array with the original config,
+			// so struct elements are masked on a fresh copy.
+			if field.IsNil() || field.Type().Elem().Kind() != reflect.Struct {
+				continue
+			}
+			copied := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+			reflect.Copy(copied, field)
+			for j := 0; j < copied.Len(); j++ {
+				maskStruct(copied.Index(j))
+			}
+			field.Set(copied)
+		case reflect.Map:
+			if field.IsNil() || field.Type().Elem().Kind() != reflect.Struct {
+				continue
+			}
+			copied := reflect.MakeMapWithSize(field.Type(), field.Len())
+			iter := field.MapRange()
+			for iter.Next() {
+				elem := reflect.New(field.Type().Elem()).Elem()
+				elem.Set(iter.Value())
+				maskStruct(elem)
+				copied.SetMapIndex(iter.Key(), elem)
+			}
+			field.Set(copied)
+		case reflect.String:
+			if field.String() == "" {
+				continue
+			}
+			switch t.Field(i).Tag.Get("mask") {
+			case "true":
+				field.SetString(maskedValue)
+			case "url":
+				field.SetString(maskURL(field.String()))
+			}
+		}
+	}
+}
+
+// maskURL hides the password of a URL's userinfo, or the whole value if it
+// cannot be parsed.
+func maskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return maskedValue
+	}
+	return u.Redacted()
+}
+
+// AsMap converts the config into a map keyed by the same names used in the
+// config files, suitable for JSON or YAML output. Durations are rendered as
+// strings (e.g. "15m0s").
+func (c *Config) AsMap() map[string]any {
+	return structToMap(reflect.ValueOf(*c))
+}
+
+func structToMap(v reflect.Value) map[string]any {
+	t := v.Type()
+	out := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			name = sf.Name
+		}
+		out[name] = valueToAny(v.Field(i))
+	}
+	return out
+}
+
+func valueToAny(v reflect.Value) any {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return structToMap(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return valueToAny(v.Elem())
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = valueToAny(iter.Value())
+		}
+		return m
+	case reflect.Slice:
+		if v.IsNil() {
+			return []any{}
+		}
+		s := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s[i] = valueToAny(v.Index(i))
+		}
+		return s
+	default:
+		return v.Interface()
+	}
+}
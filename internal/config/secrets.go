@@ -0,0 +1,153 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrSecretNotFound is returned by a SecretProvider that has no value for a
+// key, in which case the value from the config files and environment is kept.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves config fields tagged with `secret:"NAME"`. NAME is
+// the environment variable for the env backend and the key inside the secret
+// for Vault.
+type SecretProvider interface {
+	Name() string
+	Get(ctx context.Context, key string) (string, error)
+	Close() error
+}
+
+// EnvSecretProvider reads secrets from environment variables, or from the
+// file named by NAME_FILE.
+type EnvSecretProvider struct{}
+
+func (EnvSecretProvider) Name() string { return "env" }
+
+func (EnvSecretProvider) Get(_ context.Context, key string) (string, error) {
+	v, err := envOrFile(key)
+	if err != nil {
+		return "", err
+	}
+	if v == "" {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (EnvSecretProvider) Close() error { return nil }
+
+// StaticSecretProvider serves secrets from a fixed map. It is meant for tests
+// and local tooling.
+type StaticSecretProvider map[string]string
+
+func (StaticSecretProvider) Name() string { return "static" }
+
+func (p StaticSecretProvider) Get(_ context.Context, key string) (string, error) {
+	v, ok := p[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (StaticSecretProvider) Close() error { return nil }
+
+// SecretBackend builds a SecretProvider from the loaded config, before
+// secrets are resolved. Backends read their settings from secrets.options.
+type SecretBackend func(ctx context.Context, cfg *Config) (SecretProvider, error)
+
+var (
+	secretBackendsMu sync.RWMutex
+	secretBackends   = map[string]SecretBackend{}
+)
+
+// RegisterSecretBackend makes an external secrets manager, such as AWS
+// Secrets Manager, selectable with secrets.backend. It must be called before
+// Load, typically from an init function, and can't replace env or vault.
+func RegisterSecretBackend(name string, backend SecretBackend) {
+	if name == "" || name == "env" || name == "vault" || backend == nil {
+		panic(fmt.Sprintf("config: invalid secret backend registration '%s'", name))
+	}
+
+	secretBackendsMu.Lock()
+	defer secretBackendsMu.Unlock()
+	if _, exists := secretBackends[name]; exists {
+		panic(fmt.Sprintf("config: secret backend '%s' registered twice", name))
+	}
+	secretBackends[name] = backend
+}
+
+// newSecretProvider builds the provider selected by secrets.backend. When
+// Vault cannot be reached outside production it falls back to env with a
+// warning; in production that is fatal.
+func newSecretProvider(ctx context.Context, cfg *Config) (SecretProvider, error) {
+	switch cfg.Secrets.Backend {
+	case "", "env":
+		return EnvSecretProvider{}, nil
+	case "vault":
+		vaultCfg := cfg.Secrets.Vault
+		vaultCfg.SecretPath = strings.ReplaceAll(vaultCfg.SecretPath, "{env}", cfg.Server.Environment)
+		provider, err := NewVaultProvider(ctx, vaultCfg)
+		if errors.Is(err, errVaultUnreachable) && !cfg.IsProduction() {
+			log.Printf("WARNING: %v; falling back to environment secrets", err)
+			return EnvSecretProvider{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	default:
+		secretBackendsMu.RLock()
+		backend, ok := secretBackends[cfg.Secrets.Backend]
+		secretBackendsMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown secrets backend '%s'", cfg.Secrets.Backend)
+		}
+		return backend(ctx, cfg)
+	}
+}
+
+// resolveSecrets replaces every `secret`-tagged field the provider has a value
+// for.
+func resolveSecrets(ctx context.Context, cfg *Config, provider SecretProvider) error {
+	return resolveStructSecrets(ctx, reflect.ValueOf(cfg).Elem(), provider)
+}
+
+func resolveStructSecrets(ctx context.Context, v reflect.Value, provider SecretProvider) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		if field.Kind() == reflect.Struct {
+			if err := resolveStructSecrets(ctx, field, provider); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := t.Field(i).Tag.Get("secret")
+		if key == "" || field.Kind() != reflect.String {
+			continue
+		}
+
+		value, err := provider.Get(ctx, key)
+		if errors.Is(err, ErrSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve secret %s from %s: %w", key, provider.Name(), err)
+		}
+		field.SetString(value)
+	}
+
+	return nil
+}
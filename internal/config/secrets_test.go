@@ -0,0 +1,227 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// mockProvider serves values from a map, failing keys listed in fail, and
+// records the keys asked for.
+type mockProvider struct {
+	values map[string]string
+	fail   map[string]bool
+	asked  []string
+}
+
+func (p *mockProvider) Name() string { return "mock" }
+
+func (p *mockProvider) Get(_ context.Context, key string) (string, error) {
+	p.asked = append(p.asked, key)
+	if p.fail[key] {
+		return "", errors.New("access denied")
+	}
+	if v, ok := p.values[key]; ok {
+		return v, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+func (p *mockProvider) Close() error { return nil }
+
+// registerTestBackend registers backend for the duration of the test.
+func registerTestBackend(t *testing.T, name string, backend SecretBackend) {
+	t.Helper()
+	RegisterSecretBackend(name, backend)
+	t.Cleanup(func() {
+		secretBackendsMu.Lock()
+		defer secretBackendsMu.Unlock()
+		delete(secretBackends, name)
+	})
+}
+
+func TestResolveSecrets(t *testing.T) {
+	provider := &mockProvider{values: map[string]string{
+		"JWT_SECRET":     "jwt-from-provider",
+		"DB_PASSWORD":    "db-from-provider",
+		"REDIS_PASSWORD": "",
+		"S3_SECRET_KEY":  "s3-from-provider",
+	}}
+
+	cfg := &Config{}
+	cfg.JWT.Secret = "jwt-from-file"
+	cfg.Database.Password = "db-from-file"
+	cfg.Redis.Password = "redis-from-file"
+	cfg.Storage.AccessKey = "s3-access-from-file"
+	cfg.Storage.SecretKey = "s3-secret-from-file"
+	cfg.Database.Host = "db.internal"
+
+	if err := resolveSecrets(context.Background(), cfg, provider); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		field string
+		got   string
+		want  string
+	}{
+		{"JWT.Secret", cfg.JWT.Secret, "jwt-from-provider"},
+		{"Database.Password", cfg.Database.Password, "db-from-provider"},
+		// A value the provider has, even empty, wins
+		{"Redis.Password", cfg.Redis.Password, ""},
+		// Keys the provider doesn't have keep the file value
+		{"Storage.AccessKey", cfg.Storage.AccessKey, "s3-access-from-file"},
+		{"Storage.SecretKey", cfg.Storage.SecretKey, "s3-from-provider"},
+		// Untagged fields are never looked up
+		{"Database.Host", cfg.Database.Host, "db.internal"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+
+	for _, key := range []string{"JWT_SECRET", "DB_PASSWORD", "REDIS_PASSWORD", "S3_ACCESS_KEY", "S3_SECRET_KEY", "ML_SERVICE_TOKEN", "SMTP_PASSWORD"} {
+		if !slices.Contains(provider.asked, key) {
+			t.Errorf("%s was not looked up", key)
+		}
+	}
+}
+
+func TestResolveSecretsError(t *testing.T) {
+	provider := &mockProvider{fail: map[string]bool{"DB_PASSWORD": true}}
+
+	err := resolveSecrets(context.Background(), &Config{}, provider)
+	if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD") || !strings.Contains(err.Error(), "mock") {
+		t.Errorf("resolveSecrets() error = %v, want it to name the key and provider", err)
+	}
+}
+
+func TestEnvSecretProvider(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr error
+	}{
+		{"variable", map[string]string{"JWT_SECRET": "from-env"}, "from-env", nil},
+		{"file", map[string]string{"JWT_SECRET_FILE": file}, "from-file", nil},
+		{"variable wins over file", map[string]string{"JWT_SECRET": "from-env", "JWT_SECRET_FILE": file}, "from-env", nil},
+		{"unset", nil, "", ErrSecretNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", "")
+			t.Setenv("JWT_SECRET_FILE", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			got, err := EnvSecretProvider{}.Get(context.Background(), "JWT_SECRET")
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("Get() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("unreadable file", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "")
+		t.Setenv("JWT_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+		if _, err := (EnvSecretProvider{}).Get(context.Background(), "JWT_SECRET"); err == nil || errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("Get() error = %v, want the read failure", err)
+		}
+	})
+}
+
+func TestNewSecretProvider(t *testing.T) {
+	t.Setenv("VAULT_MAX_RETRIES", "0")
+
+	mock := &mockProvider{}
+	var gotOptions map[string]string
+	registerTestBackend(t, "test-mock", func(_ context.Context, cfg *Config) (SecretProvider, error) {
+		gotOptions = cfg.Secrets.Options
+		return mock, nil
+	})
+
+	unreachable := VaultConfig{Address: "http://127.0.0.1:1", Token: "root-token", SecretPath: "backend"}
+
+	tests := []struct {
+		name        string
+		backend     string
+		environment string
+		vault       VaultConfig
+		wantName    string
+		wantErr     bool
+	}{
+		{"default", "", "production", VaultConfig{}, "env", false},
+		{"env", "env", "production", VaultConfig{}, "env", false},
+		{"registered backend", "test-mock", "production", VaultConfig{}, "mock", false},
+		{"unknown backend", "aws", "development", VaultConfig{}, "", true},
+		{"vault unreachable in development", "vault", "development", unreachable, "env", false},
+		{"vault unreachable in production", "vault", "production", unreachable, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Server.Environment = tt.environment
+			cfg.Secrets = SecretsConfig{Backend: tt.backend, Vault: tt.vault, Options: map[string]string{"region": "ap-southeast-3"}}
+
+			p, err := newSecretProvider(context.Background(), cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("newSecretProvider() = %s, want an error", p.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Name() != tt.wantName {
+				t.Errorf("provider = %s, want %s", p.Name(), tt.wantName)
+			}
+		})
+	}
+
+	if gotOptions["region"] != "ap-southeast-3" {
+		t.Errorf("registered backend got options %v, want secrets.options", gotOptions)
+	}
+}
+
+func TestRegisterSecretBackendRejects(t *testing.T) {
+	backend := func(context.Context, *Config) (SecretProvider, error) { return StaticSecretProvider{}, nil }
+	registerTestBackend(t, "test-once", backend)
+
+	tests := []struct {
+		name    string
+		backend string
+		fn      SecretBackend
+	}{
+		{"empty name", "", backend},
+		{"env", "env", backend},
+		{"vault", "vault", backend},
+		{"nil backend", "test-nil", nil},
+		{"registered twice", "test-once", backend},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterSecretBackend(%q) didn't panic", tt.backend)
+				}
+			}()
+			RegisterSecretBackend(tt.backend, tt.fn)
+		})
+	}
+}
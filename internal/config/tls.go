@@ -0,0 +1,58 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// Build returns the tls.Config for the HTTPS server. Certificates are loaded
+// by ListenAndServeTLS.
+func (t TLSConfig) Build() (*tls.Config, error) {
+	minVersion, err := parseTLSVersion(t.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	suites, err := parseCipherSuites(t.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: suites,
+	}, nil
+}
+
+func parseTLSVersion(v string) (uint16, error) {
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls min_version '%s', expected 1.2 or 1.3", v)
+	}
+}
+
+// parseCipherSuites maps suite names to IDs. Insecure suites are rejected.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite '%s'", name)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
@@ -2,44 +2,160 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
 )
 
-// validateCustomRules performs additional validation beyond struct tags
-func validateCustomRules(cfg *Config) error {
-	// Validate port is a valid number
-	if port, err := strconv.Atoi(cfg.Server.Port); err != nil || port < 1 || port > 65535 {
-		return fmt.Errorf("invalid server port '%s', must be between 1-65535", cfg.Server.Port)
+// validateCustomRules performs additional validation beyond struct tags,
+// recording every violation in errs
+func validateCustomRules(cfg *Config, errs *ValidationError) {
+	// Validate ports are valid numbers
+	for _, p := range []struct{ key, value string }{
+		{"server.port", cfg.Server.Port},
+		{"database.port", cfg.Database.Port},
+		{"redis.port", cfg.Redis.Port},
+	} {
+		if port, err := strconv.Atoi(p.value); err != nil || port < 1 || port > 65535 {
+			errs.add(p.key, p.value, "must be a port between 1-65535")
+		}
 	}
 
-	// Validate database port
-	if port, err := strconv.Atoi(cfg.Database.Port); err != nil || port < 1 || port > 65535 {
-		return fmt.Errorf("invalid database port '%s', must be between 1-65535", cfg.Database.Port)
+	if network, address := cfg.Server.ListenAddress(); network == "unix" {
+		if address == "" {
+			errs.add("server.listen", cfg.Server.Listen, "expected unix:///path/to/socket")
+		}
+	} else if cfg.Server.Listen != "" {
+		if _, port, err := net.SplitHostPort(address); err != nil || port == "" {
+			errs.add("server.listen", cfg.Server.Listen, "expected host:port or unix:///path/to/socket")
+		}
+	}
+	if cfg.Server.SocketMode != "" {
+		if mode, err := strconv.ParseUint(cfg.Server.SocketMode, 8, 32); err != nil || mode > 0o777 {
+			errs.add("server.socket_mode", cfg.Server.SocketMode, "must be an octal file mode such as 0660")
+		}
 	}
 
-	// Validate Redis port
-	if port, err := strconv.Atoi(cfg.Redis.Port); err != nil || port < 1 || port > 65535 {
-		return fmt.Errorf("invalid redis port '%s', must be between 1-65535", cfg.Redis.Port)
+	if cfg.Database.Driver == DatabaseDriverMemory && !cfg.IsDevelopment() {
+		errs.add("database.driver", cfg.Database.Driver, "is only available in development; nothing is persisted")
+	}
+	if cfg.Redis.Mode == RedisModeMemory && cfg.IsProduction() {
+		errs.add("redis.mode", cfg.Redis.Mode, "cannot be used in production; sessions and rate limits would not be shared between instances")
 	}
 
-	// Validate JWT secret length in production
+	// Validate JWT secret length and quality in production
 	if cfg.IsProduction() && len(cfg.JWT.Secret) < 32 {
-		return fmt.Errorf("JWT secret must be at least 32 characters in production, got %d", len(cfg.JWT.Secret))
+		errs.add("jwt.secret", cfg.JWT.Secret, "must be at least 32 characters in production, got %d", len(cfg.JWT.Secret))
+	} else if cfg.IsProduction() {
+		if reason := weakSecretReason(cfg.JWT.Secret, cfg.JWT.WeakSecrets); reason != "" {
+			errs.add("jwt.secret", cfg.JWT.Secret, "is too weak for production (%s); generate one with `openssl rand -base64 48`", reason)
+		}
 	}
 
 	// Validate timeout values are positive
 	if cfg.Server.ReadTimeout <= 0 {
-		return fmt.Errorf("server read_timeout must be positive, got %v", cfg.Server.ReadTimeout)
+		errs.add("server.read_timeout", cfg.Server.ReadTimeout, "must be positive")
 	}
 	if cfg.Server.WriteTimeout <= 0 {
-		return fmt.Errorf("server write_timeout must be positive, got %v", cfg.Server.WriteTimeout)
+		errs.add("server.write_timeout", cfg.Server.WriteTimeout, "must be positive")
+	}
+
+	// Validate redirect allowlist entries
+	for i, origin := range cfg.Security.RedirectAllowedOrigins {
+		if strings.HasPrefix(origin, "*.") || !strings.Contains(origin, "://") {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			errs.add(fmt.Sprintf("security.redirect_allowed_origins[%d]", i), origin, "expected scheme://host[:port]")
+		}
+	}
+	if cfg.Security.DefaultRedirectURL != "" {
+		u, err := url.Parse(cfg.Security.DefaultRedirectURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs.add("security.default_redirect_url", cfg.Security.DefaultRedirectURL, "must be an absolute http(s) url")
+		}
+	}
+
+	// Validate cookie settings
+	cookie := cfg.Security.Cookie.WithDefaults(cfg.IsProduction())
+	if strings.EqualFold(cookie.SameSite, "none") && !*cookie.Secure {
+		errs.add("security.cookie.secure", *cookie.Secure, "must be true when same_site is 'none'")
+	}
+	if strings.Contains(cfg.Security.Cookie.Domain, "://") || strings.ContainsAny(cfg.Security.Cookie.Domain, "/:") {
+		errs.add("security.cookie.domain", cfg.Security.Cookie.Domain, "must be a bare domain without scheme, port or path")
+	}
+
+	// Validate mail sender address
+	if _, err := mail.ParseAddress(cfg.Mail.FromAddress); err != nil {
+		errs.add("mail.from_address", cfg.Mail.FromAddress, "invalid address: %v", err)
 	}
 
 	// Validate database pool settings
 	if cfg.Database.MaxOpenConns < cfg.Database.MaxIdleConns {
-		return fmt.Errorf("database max_open_conns (%d) must be >= max_idle_conns (%d)",
-			cfg.Database.MaxOpenConns, cfg.Database.MaxIdleConns)
+		errs.add("database.max_open_conns", cfg.Database.MaxOpenConns, "must be >= database.max_idle_conns (%d)", cfg.Database.MaxIdleConns)
+	}
+
+	// Validate TLS settings
+	if tlsCfg := cfg.Server.TLS; tlsCfg.Enabled {
+		for _, f := range []struct{ key, path string }{
+			{"server.tls.cert_file", tlsCfg.CertFile},
+			{"server.tls.key_file", tlsCfg.KeyFile},
+		} {
+			if f.path == "" {
+				continue
+			}
+			if _, err := os.Stat(f.path); err != nil {
+				errs.add(f.key, f.path, "cannot be read: %v", err)
+			}
+		}
+		if _, err := parseCipherSuites(tlsCfg.CipherSuites); err != nil {
+			errs.add("server.tls.cipher_suites", strings.Join(tlsCfg.CipherSuites, ","), "%v", err)
+		}
+		if tlsCfg.RedirectPort != "" {
+			if port, err := strconv.Atoi(tlsCfg.RedirectPort); err != nil || port < 1 || port > 65535 {
+				errs.add("server.tls.redirect_port", tlsCfg.RedirectPort, "must be a port between 1-65535")
+			} else if tlsCfg.RedirectPort == cfg.Server.Port {
+				errs.add("server.tls.redirect_port", tlsCfg.RedirectPort, "must differ from server.port")
+			}
+		}
 	}
+}
+
+// minSecretUniqueChars is the fewest distinct characters a production JWT
+// secret may have; random secrets of 32 characters or more have far more
+const minSecretUniqueChars = 10
 
-	return nil
+// defaultWeakSecrets are placeholders seen in example configs and tutorials
+var defaultWeakSecrets = []string{
+	"changeme", "change_me", "change-me", "change_in_production",
+	"secret", "password", "placeholder", "example", "default",
+	"your_jwt", "your-jwt", "jwt_key", "12345678", "qwerty",
+}
+
+// weakSecretReason describes why secret looks like a placeholder rather than
+// a random value, or returns "" if it doesn't.
+func weakSecretReason(secret string, blocklist []string) string {
+	if len(blocklist) == 0 {
+		blocklist = defaultWeakSecrets
+	}
+
+	lower := strings.ToLower(secret)
+	for _, weak := range blocklist {
+		if weak = strings.ToLower(strings.TrimSpace(weak)); weak != "" && strings.Contains(lower, weak) {
+			return fmt.Sprintf("contains '%s'", weak)
+		}
+	}
+
+	unique := make(map[rune]bool)
+	for _, r := range secret {
+		unique[r] = true
+	}
+	if len(unique) < minSecretUniqueChars {
+		return fmt.Sprintf("only %d distinct characters", len(unique))
+	}
+	return ""
 }
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJWTSecretStrength(t *testing.T) {
+	const strong = "q7Zp2LxV9mRk4TfWc8HnY3bJd6GsA1uE"
+
+	tests := []struct {
+		name        string
+		environment string
+		secret      string
+		blocklist   []string
+		wantErr     string
+	}{
+		{"strong secret", "production", strong, nil, ""},
+		{"strong secret with a custom blocklist", "production", strong, []string{"umkm"}, ""},
+		{"too short", "production", "q7Zp2LxV9mRk", nil, "at least 32 characters"},
+		{"placeholder", "production", "changeme-q7Zp2LxV9mRk4TfWc8HnY3bJd", nil, "contains 'changeme'"},
+		{"placeholder in another case", "production", "MY-SUPER-SECRET-KEY-q7Zp2LxV9mRk4T", nil, "contains 'secret'"},
+		{"single repeated character", "production", strings.Repeat("a", 40), nil, "only 1 distinct characters"},
+		{"low variety", "production", strings.Repeat("abc123", 8), nil, "only 6 distinct characters"},
+		{"custom blocklist entry", "production", "umkm-" + strong, []string{" UMKM "}, "contains 'umkm'"},
+		{"custom blocklist replaces the defaults", "production", "secret-" + strong, []string{"umkm"}, ""},
+		{"weak secret outside production", "development", "changeme", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Server.Environment = tt.environment
+			cfg.JWT.Secret = tt.secret
+			cfg.JWT.WeakSecrets = tt.blocklist
+
+			var errs ValidationError
+			validateCustomRules(cfg, &errs)
+
+			var got *FieldError
+			for i := range errs.Errors {
+				if errs.Errors[i].Key == "jwt.secret" {
+					got = &errs.Errors[i]
+				}
+			}
+			if tt.wantErr == "" {
+				if got != nil {
+					t.Fatalf("secret rejected: %s", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("secret accepted, want %q", tt.wantErr)
+			}
+			if !strings.Contains(got.Message, tt.wantErr) {
+				t.Errorf("message = %q, want it to mention %q", got.Message, tt.wantErr)
+			}
+			// The rejected secret itself never reaches the startup log
+			if strings.Contains(got.String(), tt.secret) {
+				t.Errorf("error %q leaks the secret", got)
+			}
+		})
+	}
+}
+
+func TestRedirectSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		origins    []string
+		defaultURL string
+		wantKeys   []string
+	}{
+		{"valid", []string{"https://app.umkm.id", "https://app.umkm.id/", "partner.example.com", "*.toko.id"}, "https://app.umkm.id/home", nil},
+		{"origin with a path", []string{"https://app.umkm.id", "https://app.umkm.id/callback"}, "", []string{"security.redirect_allowed_origins[1]"}},
+		{"origin without a host", []string{"https://"}, "", []string{"security.redirect_allowed_origins[0]"}},
+		{"relative default", nil, "/home", []string{"security.default_redirect_url"}},
+		{"default on another scheme", nil, "ftp://app.umkm.id/", []string{"security.default_redirect_url"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Security.RedirectAllowedOrigins = tt.origins
+			cfg.Security.DefaultRedirectURL = tt.defaultURL
+
+			var errs ValidationError
+			validateCustomRules(cfg, &errs)
+
+			var got []string
+			for _, err := range errs.Errors {
+				if strings.HasPrefix(err.Key, "security.") {
+					got = append(got, err.Key)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("rejected %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestListenAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		listen      string
+		socketMode  string
+		wantNetwork string
+		wantAddress string
+		wantErr     string
+	}{
+		{"host and port", "", "", "tcp", "0.0.0.0:8080", ""},
+		{"listen address", "127.0.0.1:9090", "", "tcp", "127.0.0.1:9090", ""},
+		{"unix socket", "unix:///var/run/umkmai.sock", "0660", "unix", "/var/run/umkmai.sock", ""},
+		{"unix socket without a path", "unix://", "", "unix", "", "server.listen"},
+		{"missing port", "127.0.0.1", "", "tcp", "127.0.0.1", "server.listen"},
+		{"socket mode not octal", "unix:///tmp/a.sock", "0968", "unix", "/tmp/a.sock", "server.socket_mode"},
+		{"socket mode too wide", "unix:///tmp/a.sock", "1777", "unix", "/tmp/a.sock", "server.socket_mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Server = ServerConfig{Host: "0.0.0.0", Port: "8080", Listen: tt.listen, SocketMode: tt.socketMode}
+
+			network, address := cfg.Server.ListenAddress()
+			if network != tt.wantNetwork || address != tt.wantAddress {
+				t.Errorf("ListenAddress() = %s %q, want %s %q", network, address, tt.wantNetwork, tt.wantAddress)
+			}
+
+			var errs ValidationError
+			validateCustomRules(cfg, &errs)
+			var keys []string
+			for _, e := range errs.Errors {
+				if e.Key == "server.listen" || e.Key == "server.socket_mode" {
+					keys = append(keys, e.Key)
+				}
+			}
+			if got := strings.Join(keys, ","); got != tt.wantErr {
+				t.Errorf("rejected %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemoryBackends(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		driver      string
+		redisMode   string
+		wantKeys    []string
+	}{
+		{"memory stack in development", "development", DatabaseDriverMemory, RedisModeMemory, nil},
+		{"memory driver in staging", "staging", DatabaseDriverMemory, "", []string{"database.driver"}},
+		{"memory cache in staging", "staging", "", RedisModeMemory, nil},
+		{"memory driver in production", "production", DatabaseDriverMemory, "", []string{"database.driver"}},
+		{"memory cache in production", "production", "", RedisModeMemory, []string{"redis.mode"}},
+		{"memory stack in production", "production", DatabaseDriverMemory, RedisModeMemory, []string{"database.driver", "redis.mode"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Server.Environment = tt.environment
+			cfg.Database.Driver = tt.driver
+			cfg.Redis.Mode = tt.redisMode
+
+			var errs ValidationError
+			validateCustomRules(cfg, &errs)
+
+			var got []string
+			for _, err := range errs.Errors {
+				if err.Key == "database.driver" || err.Key == "redis.mode" {
+					got = append(got, err.Key)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("rejected %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/api/auth/approle"
+	"github.com/hashicorp/vault/api/auth/kubernetes"
+)
+
+const vaultRetryInterval = 30 * time.Second
+
+// errVaultUnreachable marks transport failures, as opposed to Vault rejecting
+// the request, so development can fall back to env secrets.
+var errVaultUnreachable = errors.New("vault unreachable")
+
+// VaultProvider reads secrets from a single KV secret, v1 or v2, and keeps
+// its token renewed in the background until Close is called.
+type VaultProvider struct {
+	cfg    VaultConfig
+	client *vault.Client
+
+	mu   sync.RWMutex
+	data map[string]string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewVaultProvider logs in, reads the configured secret and starts token
+// renewal.
+func NewVaultProvider(ctx context.Context, cfg VaultConfig) (*VaultProvider, error) {
+	if cfg.AuthMethod == "" {
+		cfg.AuthMethod = "token"
+	}
+	if cfg.MountPath == "" {
+		cfg.MountPath = "secret"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if err := validateVaultConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	clientCfg := vault.DefaultConfig()
+	if clientCfg.Error != nil {
+		return nil, fmt.Errorf("invalid vault client config: %w", clientCfg.Error)
+	}
+	clientCfg.Address = cfg.Address
+	clientCfg.Timeout = cfg.Timeout
+	client, err := vault.NewClient(clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+	if cfg.Namespace != "" {
+		client.SetNamespace(cfg.Namespace)
+	}
+
+	p := &VaultProvider{
+		cfg:    cfg,
+		client: client,
+		done:   make(chan struct{}),
+	}
+
+	auth, err := p.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.load(ctx); err != nil {
+		return nil, err
+	}
+
+	renewCtx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.renewLoop(renewCtx, auth)
+
+	return p, nil
+}
+
+func validateVaultConfig(cfg VaultConfig) error {
+	if cfg.Address == "" {
+		return fmt.Errorf("secrets.vault.address is required")
+	}
+	if cfg.SecretPath == "" {
+		return fmt.Errorf("secrets.vault.secret_path is required")
+	}
+
+	switch cfg.AuthMethod {
+	case "token":
+		if cfg.Token == "" {
+			return fmt.Errorf("secrets.vault.token is required for token auth")
+		}
+	case "approle":
+		if cfg.RoleID == "" || cfg.SecretID == "" {
+			return fmt.Errorf("secrets.vault.role_id and secret_id are required for approle auth")
+		}
+	case "kubernetes":
+		if cfg.Role == "" {
+			return fmt.Errorf("secrets.vault.role is required for kubernetes auth")
+		}
+	default:
+		return fmt.Errorf("unsupported vault auth method '%s'", cfg.AuthMethod)
+	}
+
+	switch cfg.KVVersion {
+	case 0, 1, 2:
+	default:
+		return fmt.Errorf("secrets.vault.kv_version must be 1 or 2")
+	}
+
+	return nil
+}
+
+func (p *VaultProvider) Name() string { return "vault" }
+
+func (p *VaultProvider) Get(_ context.Context, key string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	v, ok := p.data[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+// Close stops token renewal.
+func (p *VaultProvider) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+		<-p.done
+	}
+	return nil
+}
+
+// login authenticates with the configured method and returns the token as
+// an auth secret, which the lifetime watcher renews.
+func (p *VaultProvider) login(ctx context.Context) (*vault.Secret, error) {
+	var method vault.AuthMethod
+	switch p.cfg.AuthMethod {
+	case "token":
+		p.client.SetToken(p.cfg.Token)
+		self, err := p.client.Auth().Token().LookupSelfWithContext(ctx)
+		if err != nil {
+			return nil, vaultError("vault token lookup failed", err)
+		}
+		ttl, err := self.TokenTTL()
+		if err != nil {
+			return nil, fmt.Errorf("vault token lookup failed: %w", err)
+		}
+		renewable, _ := self.TokenIsRenewable()
+		return &vault.Secret{Auth: &vault.SecretAuth{
+			ClientToken:   p.cfg.Token,
+			LeaseDuration: int(ttl.Seconds()),
+			Renewable:     renewable,
+		}}, nil
+	case "approle":
+		auth, err := approle.NewAppRoleAuth(p.cfg.RoleID, &approle.SecretID{FromString: p.cfg.SecretID})
+		if err != nil {
+			return nil, fmt.Errorf("vault login failed: %w", err)
+		}
+		method = auth
+	case "kubernetes":
+		auth, err := kubernetes.NewKubernetesAuth(p.cfg.Role)
+		if err != nil {
+			return nil, fmt.Errorf("vault login failed: %w", err)
+		}
+		method = auth
+	}
+
+	secret, err := p.client.Auth().Login(ctx, method)
+	if err != nil {
+		return nil, vaultError("vault login failed", err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("vault login returned no token")
+	}
+	return secret, nil
+}
+
+// load reads the secret. Non-string values are rendered with fmt.
+func (p *VaultProvider) load(ctx context.Context) error {
+	mount := strings.Trim(p.cfg.MountPath, "/")
+	path := strings.Trim(p.cfg.SecretPath, "/")
+
+	var (
+		secret *vault.KVSecret
+		err    error
+	)
+	if p.kvVersion(ctx, mount) == 1 {
+		secret, err = p.client.KVv1(mount).Get(ctx, path)
+	} else {
+		secret, err = p.client.KVv2(mount).Get(ctx, path)
+	}
+	if err != nil {
+		return vaultError(fmt.Sprintf("failed to read vault secret %s/%s", mount, path), err)
+	}
+
+	data := make(map[string]string, len(secret.Data))
+	for k, v := range secret.Data {
+		if s, ok := v.(string); ok {
+			data[k] = s
+		} else {
+			data[k] = fmt.Sprint(v)
+		}
+	}
+
+	p.mu.Lock()
+	p.data = data
+	p.mu.Unlock()
+
+	return nil
+}
+
+// kvVersion returns the configured KV version, or asks Vault for the
+// mount's, as the vault CLI does. Tokens that may not look the mount up get
+// v2, the default of current Vault servers.
+func (p *VaultProvider) kvVersion(ctx context.Context, mount string) int {
+	if p.cfg.KVVersion != 0 {
+		return p.cfg.KVVersion
+	}
+
+	secret, err := p.client.Logical().ReadWithContext(ctx, "sys/internal/ui/mounts/"+mount)
+	if err != nil || secret == nil {
+		return 2
+	}
+	// A mount without a version option is KV v1
+	options, _ := secret.Data["options"].(map[string]any)
+	if version, _ := options["version"].(string); version == "2" {
+		return 2
+	}
+	return 1
+}
+
+// renewLoop keeps the token alive with a lifetime watcher, which renews it
+// ahead of expiry. When the token can no longer be renewed, approle and
+// kubernetes auth log in again; a static token is left to expire.
+func (p *VaultProvider) renewLoop(ctx context.Context, auth *vault.Secret) {
+	defer close(p.done)
+
+	for {
+		// Root and other non-expiring tokens need no renewal
+		if auth.Auth.LeaseDuration <= 0 {
+			return
+		}
+
+		watcher, err := p.client.NewLifetimeWatcher(&vault.LifetimeWatcherInput{Secret: auth})
+		if err != nil {
+			log.Printf("Failed to watch vault token: %v", err)
+			return
+		}
+		go watcher.Start()
+
+		err = p.watch(ctx, watcher)
+		watcher.Stop()
+		if ctx.Err() != nil {
+			return
+		}
+		if p.cfg.AuthMethod == "token" {
+			log.Printf("Vault token can no longer be renewed: %v", err)
+			return
+		}
+		if err != nil {
+			log.Printf("Vault token renewal failed, logging in again: %v", err)
+		}
+
+		for {
+			auth, err = p.login(ctx)
+			if err == nil {
+				break
+			}
+			log.Printf("Failed to log in to vault: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(vaultRetryInterval):
+			}
+		}
+	}
+}
+
+// watch waits until the watcher gives up on the token, or ctx is done.
+func (p *VaultProvider) watch(ctx context.Context, watcher *vault.LifetimeWatcher) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-watcher.DoneCh():
+			return err
+		case <-watcher.RenewCh():
+		}
+	}
+}
+
+// vaultError describes a failed Vault call, marking it with
+// errVaultUnreachable unless Vault itself answered.
+func vaultError(action string, err error) error {
+	var respErr *vault.ResponseError
+	if errors.As(err, &respErr) || errors.Is(err, vault.ErrSecretNotFound) {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	return fmt.Errorf("%s: %w: %v", action, errVaultUnreachable, redactURLError(err))
+}
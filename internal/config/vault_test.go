@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeVault serves the Vault endpoints the provider uses, with the secret
+// {"JWT_SECRET": "from-vault", "DB_PORT": 5432} in a KV mount of kvVersion.
+func fakeVault(t *testing.T, kvVersion int) *httptest.Server {
+	t.Helper()
+
+	reply := func(w http.ResponseWriter, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}
+	secret := map[string]any{"JWT_SECRET": "from-vault", "DB_PORT": 5432}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role" || body["secret_id"] != "secret-id" {
+			w.WriteHeader(http.StatusBadRequest)
+			reply(w, map[string]any{"errors": []string{"invalid role or secret ID"}})
+			return
+		}
+		reply(w, map[string]any{"auth": map[string]any{"client_token": "approle-token"}})
+	})
+
+	authed := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if token := r.Header.Get("X-Vault-Token"); token != "root-token" && token != "approle-token" {
+				w.WriteHeader(http.StatusForbidden)
+				reply(w, map[string]any{"errors": []string{"permission denied"}})
+				return
+			}
+			next(w, r)
+		}
+	}
+	mux.HandleFunc("GET /v1/auth/token/lookup-self", authed(func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]any{"data": map[string]any{"ttl": 0, "renewable": false}})
+	}))
+	mux.HandleFunc("GET /v1/sys/internal/ui/mounts/secret", authed(func(w http.ResponseWriter, r *http.Request) {
+		options := map[string]any{}
+		if kvVersion == 2 {
+			options["version"] = "2"
+		}
+		reply(w, map[string]any{"data": map[string]any{"type": "kv", "options": options}})
+	}))
+	if kvVersion == 2 {
+		mux.HandleFunc("GET /v1/secret/data/backend", authed(func(w http.ResponseWriter, r *http.Request) {
+			reply(w, map[string]any{"data": map[string]any{"data": secret, "metadata": map[string]any{"version": 1}}})
+		}))
+	} else {
+		mux.HandleFunc("GET /v1/secret/backend", authed(func(w http.ResponseWriter, r *http.Request) {
+			reply(w, map[string]any{"data": secret})
+		}))
+	}
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVaultProvider(t *testing.T) {
+	// Fail fast instead of retrying unreachable addresses
+	t.Setenv("VAULT_MAX_RETRIES", "0")
+
+	tests := []struct {
+		name      string
+		kvVersion int
+		cfg       VaultConfig
+		// wantErr is nil for success, errVaultUnreachable for a transport
+		// failure and errRejected when Vault answered with an error
+		wantErr error
+	}{
+		{"token on kv v2", 2, VaultConfig{Token: "root-token"}, nil},
+		{"token on kv v1", 1, VaultConfig{Token: "root-token"}, nil},
+		{"pinned kv version", 1, VaultConfig{Token: "root-token", KVVersion: 1}, nil},
+		{"approle", 2, VaultConfig{AuthMethod: "approle", RoleID: "role", SecretID: "secret-id"}, nil},
+		{"rejected token", 2, VaultConfig{Token: "wrong"}, errRejected},
+		{"rejected approle", 2, VaultConfig{AuthMethod: "approle", RoleID: "role", SecretID: "wrong"}, errRejected},
+		{"unreachable", 2, VaultConfig{Token: "root-token", Address: "http://127.0.0.1:1"}, errVaultUnreachable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if cfg.Address == "" {
+				cfg.Address = fakeVault(t, tt.kvVersion).URL
+			}
+			cfg.SecretPath = "backend"
+
+			p, err := NewVaultProvider(context.Background(), cfg)
+			switch tt.wantErr {
+			case nil:
+				if err != nil {
+					t.Fatalf("NewVaultProvider() = %v", err)
+				}
+			case errRejected:
+				if err == nil || errors.Is(err, errVaultUnreachable) {
+					t.Fatalf("NewVaultProvider() = %v, want Vault's rejection", err)
+				}
+				return
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewVaultProvider() = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			defer p.Close()
+
+			want := map[string]string{"JWT_SECRET": "from-vault", "DB_PORT": "5432"}
+			for key, value := range want {
+				if got, err := p.Get(context.Background(), key); err != nil || got != value {
+					t.Errorf("Get(%s) = %q, %v, want %q", key, got, err, value)
+				}
+			}
+			if _, err := p.Get(context.Background(), "MISSING"); !errors.Is(err, ErrSecretNotFound) {
+				t.Errorf("Get(MISSING) = %v, want ErrSecretNotFound", err)
+			}
+		})
+	}
+}
+
+// errRejected stands for any error Vault answered with.
+var errRejected = errors.New("rejected")
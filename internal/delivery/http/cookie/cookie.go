@@ -0,0 +1,81 @@
+package cookie
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Writer sets and clears cookies using the attributes from CookieConfig so
+// every cookie the API issues shares the same policy.
+type Writer struct {
+	cfg config.CookieConfig
+}
+
+// NewWriter fills in the settings cfg leaves unset, see
+// config.CookieConfig.WithDefaults; production turns Secure on by default.
+func NewWriter(cfg config.CookieConfig, production bool) *Writer {
+	return &Writer{
+		cfg: cfg.WithDefaults(production),
+	}
+}
+
+// RefreshTokenName returns the configured name of the refresh token cookie.
+func (w *Writer) RefreshTokenName() string {
+	return w.cfg.RefreshTokenName
+}
+
+// Set writes a cookie that lives for the configured max age, or for the
+// browser session when max age is zero.
+func (w *Writer) Set(c *gin.Context, name, value string) {
+	w.write(c, name, value, int(w.cfg.MaxAge.Seconds()))
+}
+
+// SetUntil writes a cookie that lives until expiresAt, such as the expiry of
+// the token it holds, or for the browser session when max age is zero.
+func (w *Writer) SetUntil(c *gin.Context, name, value string, expiresAt time.Time) {
+	if w.cfg.MaxAge == 0 {
+		w.write(c, name, value, 0)
+		return
+	}
+	maxAge := int(time.Until(expiresAt).Seconds())
+	if maxAge <= 0 {
+		maxAge = -1
+	}
+	w.write(c, name, value, maxAge)
+}
+
+// Clear expires a cookie immediately.
+func (w *Writer) Clear(c *gin.Context, name string) {
+	w.write(c, name, "", -1)
+}
+
+func (w *Writer) write(c *gin.Context, name, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		MaxAge:   maxAge,
+		Path:     w.cfg.Path,
+		Domain:   w.cfg.Domain,
+		SameSite: sameSite(w.cfg.SameSite),
+		Secure:   *w.cfg.Secure,
+		HttpOnly: *w.cfg.HTTPOnly,
+	})
+}
+
+func sameSite(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteDefaultMode
+	}
+}
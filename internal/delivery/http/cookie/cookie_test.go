@@ -0,0 +1,86 @@
+package cookie
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetUntil(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		maxAge    time.Duration
+		expiresIn time.Duration
+		// wantMaxAge is in seconds; a positive one may be a second less, for
+		// the time the test takes
+		wantMaxAge int
+	}{
+		{"lives as long as the token", 7 * 24 * time.Hour, 24 * time.Hour, 24 * 60 * 60},
+		{"shortened by the absolute limit", 7 * 24 * time.Hour, 90 * time.Minute, 90 * 60},
+		{"already expired", 7 * 24 * time.Hour, -time.Minute, -1},
+		{"session cookie", 0, 24 * time.Hour, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWriter(config.CookieConfig{MaxAge: tt.maxAge}, false)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+
+			w.SetUntil(c, w.RefreshTokenName(), "token", time.Now().Add(tt.expiresIn))
+
+			cookies := rec.Result().Cookies()
+			if len(cookies) != 1 {
+				t.Fatalf("got %d cookies, want 1", len(cookies))
+			}
+			got := cookies[0].MaxAge
+			if got != tt.wantMaxAge && (tt.wantMaxAge <= 0 || got != tt.wantMaxAge-1) {
+				t.Errorf("Max-Age = %d, want %d", got, tt.wantMaxAge)
+			}
+		})
+	}
+}
+
+func TestWriterDefaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	off := false
+
+	tests := []struct {
+		name         string
+		cfg          config.CookieConfig
+		production   bool
+		wantSecure   bool
+		wantHTTPOnly bool
+	}{
+		{"development", config.CookieConfig{}, false, false, true},
+		{"production", config.CookieConfig{}, true, true, true},
+		{"explicit settings win", config.CookieConfig{Secure: &off, HTTPOnly: &off}, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWriter(tt.cfg, tt.production)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+
+			w.Set(c, w.RefreshTokenName(), "token")
+
+			cookies := rec.Result().Cookies()
+			if len(cookies) != 1 {
+				t.Fatalf("got %d cookies, want 1", len(cookies))
+			}
+			got := cookies[0]
+			if got.Name != "refresh_token" || got.Path != "/" {
+				t.Errorf("cookie %q on path %q, want refresh_token on /", got.Name, got.Path)
+			}
+			if got.Secure != tt.wantSecure || got.HttpOnly != tt.wantHTTPOnly {
+				t.Errorf("Secure = %v, HttpOnly = %v, want %v, %v", got.Secure, got.HttpOnly, tt.wantSecure, tt.wantHTTPOnly)
+			}
+		})
+	}
+}
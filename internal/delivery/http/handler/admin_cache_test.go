@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/gin-gonic/gin"
+)
+
+// auditRecorder keeps the audit entries written.
+type auditRecorder struct {
+	repository.AuditLogRepository
+	entries []*domain.AuditLog
+}
+
+func (r *auditRecorder) Create(ctx context.Context, entry *domain.AuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// seededCache returns an in-memory cache holding a cached user with and
+// without a TTL, a business, a refresh token and a rate limit.
+func seededCache(t *testing.T) *cache.MemoryCache {
+	t.Helper()
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+
+	ctx := context.Background()
+	for key, ttl := range map[string]time.Duration{
+		"umkm:user:id:u-1":          10 * time.Minute,
+		"umkm:user:id:u-2":          0,
+		"umkm:business:b-1":         time.Hour,
+		"umkm:refresh_token:secret": time.Hour,
+		"umkm:rate_limit:10.0.0.1":  time.Minute,
+	} {
+		if err := c.Set(ctx, key, "cached", ttl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func TestListCacheKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantKeys   []string
+	}{
+		{"by namespace", "?pattern=user:id:*", http.StatusOK, []string{"umkm:user:id:u-1", "umkm:user:id:u-2"}},
+		{"refresh tokens are hidden", "?pattern=*", http.StatusOK, []string{"umkm:business:b-1", "umkm:rate_limit:10.0.0.1", "umkm:user:id:u-1", "umkm:user:id:u-2"}},
+		{"no match", "?pattern=workflow:*", http.StatusOK, []string{}},
+		{"missing pattern", "", http.StatusBadRequest, nil},
+		{"limit too high", "?pattern=*&limit=500", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewAdminHandler(&config.Config{}, seededCache(t), cache.NewCacheKeyBuilder("umkm"), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/cache/keys"+tt.query, nil)
+			h.ListCacheKeys(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body CacheKeyListResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			keys := make([]string, 0, len(body.Data))
+			for _, entry := range body.Data {
+				keys = append(keys, entry.Key)
+				switch entry.Key {
+				case "umkm:user:id:u-1":
+					if entry.TTLSeconds <= 0 || entry.TTLSeconds > 600 {
+						t.Errorf("%s ttl = %d, want up to 600", entry.Key, entry.TTLSeconds)
+					}
+				case "umkm:user:id:u-2":
+					if entry.TTLSeconds != -1 {
+						t.Errorf("%s ttl = %d, want -1", entry.Key, entry.TTLSeconds)
+					}
+				}
+			}
+			slices.Sort(keys)
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+
+	t.Run("limit", func(t *testing.T) {
+		h := NewAdminHandler(&config.Config{}, seededCache(t), cache.NewCacheKeyBuilder("umkm"), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/cache/keys?pattern=user:id:*&limit=1", nil)
+		h.ListCacheKeys(c)
+
+		var body CacheKeyListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Data) != 1 {
+			t.Errorf("got %d keys, want 1", len(body.Data))
+		}
+	})
+}
+
+func TestDeleteCacheKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantDeleted []string
+	}{
+		{"keys", `{"keys":["umkm:user:id:u-1","umkm:business:b-1"]}`, http.StatusOK, []string{"umkm:user:id:u-1", "umkm:business:b-1"}},
+		{"entity", `{"type":"user","id":"u-2"}`, http.StatusOK, []string{"umkm:user:id:u-2"}},
+		{"refresh token", `{"keys":["umkm:refresh_token:secret"]}`, http.StatusForbidden, nil},
+		{"rate limit", `{"keys":["umkm:rate_limit:10.0.0.1"]}`, http.StatusForbidden, nil},
+		{"one refused key refuses all", `{"keys":["umkm:user:id:u-1","umkm:refresh_token:secret"]}`, http.StatusForbidden, nil},
+		{"key without the prefix", `{"keys":["user:id:u-1"]}`, http.StatusForbidden, nil},
+		{"unknown entity type", `{"type":"session","id":"s-1"}`, http.StatusBadRequest, nil},
+		{"type without id", `{"type":"user"}`, http.StatusBadRequest, nil},
+		{"keys and entity", `{"keys":["umkm:user:id:u-1"],"type":"user","id":"u-2"}`, http.StatusBadRequest, nil},
+		{"nothing", `{}`, http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := seededCache(t)
+			audit := &auditRecorder{}
+			h := NewAdminHandler(&config.Config{}, store, cache.NewCacheKeyBuilder("umkm"), audit, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/cache/keys", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user", &domain.User{ID: "admin-1"})
+			h.DeleteCacheKeys(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			remaining, err := store.Exists(context.Background(), "umkm:user:id:u-1", "umkm:user:id:u-2", "umkm:business:b-1", "umkm:refresh_token:secret", "umkm:rate_limit:10.0.0.1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := int64(5 - len(tt.wantDeleted)); remaining != want {
+				t.Errorf("%d keys left, want %d", remaining, want)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				if len(audit.entries) != 0 {
+					t.Errorf("refused request wrote %d audit entries", len(audit.entries))
+				}
+				return
+			}
+			var body DeleteCacheKeysResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(body.Deleted, tt.wantDeleted) {
+				t.Errorf("deleted = %v, want %v", body.Deleted, tt.wantDeleted)
+			}
+
+			if len(audit.entries) != 1 {
+				t.Fatalf("wrote %d audit entries, want 1", len(audit.entries))
+			}
+			entry := audit.entries[0]
+			var changes struct {
+				Keys []string `json:"keys"`
+			}
+			if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+				t.Fatal(err)
+			}
+			if entry.Action != "cache.invalidate" || entry.UserID == nil || *entry.UserID != "admin-1" || !slices.Equal(changes.Keys, tt.wantDeleted) {
+				t.Errorf("audit entry = %s by %v with %s", entry.Action, entry.UserID, entry.Changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,633 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/featureflags"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mail"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mlclient"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/scheduler"
+	businessUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/business"
+	"github.com/Elysian-Rebirth/backend-go/internal/version"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	cfg          *config.Config
+	cache        cache.Cache
+	keys         *cache.CacheKeyBuilder
+	auditRepo    repository.AuditLogRepository
+	features     *featureflags.Service
+	mailer       mail.Mailer
+	failedEmails *mail.FailedStore
+	jobs         *scheduler.Scheduler
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	businessUC   businessUseCase.BusinessUseCase
+	ml           *mlclient.Client
+}
+
+func NewAdminHandler(cfg *config.Config, c cache.Cache, keys *cache.CacheKeyBuilder, auditRepo repository.AuditLogRepository, features *featureflags.Service, mailer mail.Mailer, failedEmails *mail.FailedStore, jobs *scheduler.Scheduler, userRepo repository.UserRepository, roleRepo repository.RoleRepository, businessUC businessUseCase.BusinessUseCase, ml *mlclient.Client) *AdminHandler {
+	return &AdminHandler{
+		cfg:          cfg,
+		cache:        c,
+		keys:         keys,
+		auditRepo:    auditRepo,
+		features:     features,
+		mailer:       mailer,
+		failedEmails: failedEmails,
+		jobs:         jobs,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		businessUC:   businessUC,
+		ml:           ml,
+	}
+}
+
+type FeatureListResponse struct {
+	Data []featureflags.FlagState `json:"data"`
+}
+
+type FailedEmailListResponse struct {
+	Data []mail.FailedJob `json:"data"`
+}
+
+type JobListResponse struct {
+	Data []scheduler.Status `json:"data"`
+}
+
+type StatsResponse struct {
+	Users      int64 `json:"users"`
+	Businesses int64 `json:"businesses"`
+}
+
+type RuntimeResponse struct {
+	GoVersion     string  `json:"go_version"`
+	Goroutines    int     `json:"goroutines"`
+	GOMAXPROCS    int     `json:"gomaxprocs"`
+	UptimeSeconds int64   `json:"uptime_seconds"`
+	HeapAlloc     uint64  `json:"heap_alloc_bytes"`
+	HeapInuse     uint64  `json:"heap_inuse_bytes"`
+	HeapSys       uint64  `json:"heap_sys_bytes"`
+	HeapObjects   uint64  `json:"heap_objects"`
+	NumGC         uint32  `json:"num_gc"`
+	GCPauseTotal  float64 `json:"gc_pause_total_ms"`
+	// RecentGCPauses are the latest pauses, newest first
+	RecentGCPauses []float64 `json:"recent_gc_pauses_ms"`
+}
+
+type RoleMatrixQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+type RoleMatrixEntry struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	TenantID    *string  `json:"tenant_id,omitempty"`
+	Permissions []string `json:"permissions"`
+	Users       int64    `json:"users"`
+}
+
+type RoleMatrixResponse struct {
+	Data []RoleMatrixEntry `json:"data"`
+}
+
+type CacheKeysQuery struct {
+	// Pattern is a Redis glob matched below the key prefix, e.g. user:id:*
+	Pattern string `form:"pattern" binding:"required,max=200"`
+	Limit   int    `form:"limit,default=50" binding:"min=1,max=200"`
+}
+
+type CacheKey struct {
+	Key string `json:"key"`
+	// TTLSeconds is -1 for keys without an expiry
+	TTLSeconds int64 `json:"ttl_seconds"`
+}
+
+type CacheKeyListResponse struct {
+	Data []CacheKey `json:"data"`
+}
+
+// DeleteCacheKeysRequest names the keys to delete, either directly or as the
+// cached entity of Type with ID.
+type DeleteCacheKeysRequest struct {
+	Keys []string `json:"keys" binding:"omitempty,max=100,dive,required"`
+	Type string   `json:"type" binding:"required_with=ID"`
+	ID   string   `json:"id" binding:"required_with=Type"`
+}
+
+type DeleteCacheKeysResponse struct {
+	Deleted []string `json:"deleted"`
+}
+
+type UpdateFeaturesRequest struct {
+	// Flags maps flag names to true/false, a rollout percentage, or null to clear the override
+	Flags map[string]any `json:"flags" binding:"required"`
+}
+
+// GetConfig godoc
+// @Summary      Get loaded configuration
+// @Description  Returns the configuration this instance loaded, with secrets masked. Disabled unless server.expose_config is true.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/admin/config [get]
+func (h *AdminHandler) GetConfig(c *gin.Context) {
+	if !h.cfg.Server.ExposeConfig {
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "Not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.cfg.MaskSensitive().AsMap())
+}
+
+// ListFeatures godoc
+// @Summary      List feature flags
+// @Description  Lists configured feature flags with their defaults and runtime overrides
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  FeatureListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/features [get]
+func (h *AdminHandler) ListFeatures(c *gin.Context) {
+	flags, err := h.features.List(c.Request.Context())
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to load feature flags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, FeatureListResponse{Data: flags})
+}
+
+// UpdateFeatures godoc
+// @Summary      Override feature flags
+// @Description  Sets runtime overrides for configured feature flags. A null value clears the override.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body UpdateFeaturesRequest true "Flag overrides"
+// @Success      200  {object}  FeatureListResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/features [put]
+func (h *AdminHandler) UpdateFeatures(c *gin.Context) {
+	var req UpdateFeaturesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	values := make(map[string]*featureflags.Value, len(req.Flags))
+	var details []string
+	for name, raw := range req.Flags {
+		if raw == nil {
+			values[name] = nil
+			continue
+		}
+		v, err := featureflags.ParseValue(raw)
+		if err != nil {
+			details = append(details, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		values[name] = &v
+	}
+	if len(details) > 0 {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid flag values", Details: details})
+		return
+	}
+
+	ctx := c.Request.Context()
+	for name, v := range values {
+		var err error
+		if v == nil {
+			err = h.features.ClearOverride(ctx, name)
+		} else {
+			err = h.features.SetOverride(ctx, name, *v)
+		}
+		if errors.Is(err, featureflags.ErrUnknownFlag) {
+			apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Unknown feature flag", Details: []string{name}})
+			return
+		}
+		if err != nil {
+			apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to update feature flags"})
+			return
+		}
+	}
+
+	h.ListFeatures(c)
+}
+
+// ListFailedEmails godoc
+// @Summary      List failed emails
+// @Description  Lists emails that ran out of delivery attempts, most recent first
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  FailedEmailListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/emails/failed [get]
+func (h *AdminHandler) ListFailedEmails(c *gin.Context) {
+	jobs, err := h.failedEmails.List(c.Request.Context())
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to fetch failed emails"})
+		return
+	}
+
+	c.JSON(http.StatusOK, FailedEmailListResponse{Data: jobs})
+}
+
+// RetryFailedEmail godoc
+// @Summary      Re-enqueue a failed email
+// @Description  Removes the email from the failed list and queues it for delivery again
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Job ID"
+// @Success      202  {object}  SuccessResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/emails/failed/{id}/retry [post]
+func (h *AdminHandler) RetryFailedEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	failed, err := h.failedEmails.Take(ctx, c.Param("id"))
+	if errors.Is(err, mail.ErrFailedJobNotFound) {
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "Failed email not found"})
+		return
+	}
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to fetch failed email"})
+		return
+	}
+
+	if err := h.mailer.Requeue(ctx, &failed.Job); err != nil {
+		// Put it back so it isn't lost
+		if addErr := h.failedEmails.Add(ctx, &failed.Job, err); addErr != nil {
+			log.Printf("Failed to restore failed email %s: %v", failed.Job.ID, addErr)
+		}
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to re-enqueue email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{Message: "Email re-enqueued"})
+}
+
+// ListJobs godoc
+// @Summary      List background jobs
+// @Description  Lists scheduled jobs with the time, duration and error of their last run on any instance
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  JobListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/jobs [get]
+func (h *AdminHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.jobs.Status(c.Request.Context())
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to fetch jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, JobListResponse{Data: jobs})
+}
+
+// RunJob godoc
+// @Summary      Run a background job
+// @Description  Starts a job immediately on this instance. Its outcome shows up in the job list once it finishes.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string  true  "Job name"
+// @Success      202   {object}  SuccessResponse
+// @Failure      404   {object}  ErrorResponse
+// @Failure      409   {object}  ErrorResponse
+// @Failure      503   {object}  ErrorResponse
+// @Router       /api/v1/admin/jobs/{name}/run [post]
+func (h *AdminHandler) RunJob(c *gin.Context) {
+	err := h.jobs.Trigger(c.Param("name"))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusAccepted, SuccessResponse{Message: "Job started"})
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "Job not found"})
+	case errors.Is(err, scheduler.ErrJobRunning):
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "Job is already running"})
+	default:
+		apierror.Write(c, http.StatusServiceUnavailable, ErrorResponse{Message: "Jobs are not running"})
+	}
+}
+
+// GetStats godoc
+// @Summary      Get platform statistics
+// @Description  Returns the number of users and businesses, excluding deleted ones
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  StatsResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/stats [get]
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	users, err := h.userRepo.Count(c.Request.Context())
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to fetch stats")
+		return
+	}
+	businesses, err := h.businessUC.Count(c.Request.Context())
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to fetch stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, StatsResponse{Users: users, Businesses: businesses})
+}
+
+// GetRuntime godoc
+// @Summary      Runtime diagnostics
+// @Description  Returns goroutine, heap and garbage collector statistics and the uptime of this instance
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  RuntimeResponse
+// @Router       /api/v1/admin/runtime [get]
+func (h *AdminHandler) GetRuntime(c *gin.Context) {
+	const recentPauses = 10
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	pauses := make([]float64, 0, recentPauses)
+	for i := 0; i < recentPauses && uint32(i) < mem.NumGC; i++ {
+		// PauseNs is a circular buffer with the latest pause at (NumGC+255)%256
+		pause := mem.PauseNs[(int(mem.NumGC)-1-i+len(mem.PauseNs))%len(mem.PauseNs)]
+		pauses = append(pauses, float64(pause)/float64(time.Millisecond))
+	}
+
+	c.JSON(http.StatusOK, RuntimeResponse{
+		GoVersion:      runtime.Version(),
+		Goroutines:     runtime.NumGoroutine(),
+		GOMAXPROCS:     runtime.GOMAXPROCS(0),
+		UptimeSeconds:  int64(version.Uptime().Seconds()),
+		HeapAlloc:      mem.HeapAlloc,
+		HeapInuse:      mem.HeapInuse,
+		HeapSys:        mem.HeapSys,
+		HeapObjects:    mem.HeapObjects,
+		NumGC:          mem.NumGC,
+		GCPauseTotal:   float64(mem.PauseTotalNs) / float64(time.Millisecond),
+		RecentGCPauses: pauses,
+	})
+}
+
+// GetRoleMatrix godoc
+// @Summary      Export the role/permission matrix
+// @Description  Returns every role with its permissions and the number of users assigned to it. Send format=csv, or Accept: text/csv, for a CSV download with the permissions separated by semicolons.
+// @Tags         admin
+// @Produce      json
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        format  query     string  false  "Output format"  Enums(json, csv)
+// @Success      200     {object}  RoleMatrixResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /api/v1/admin/rbac/matrix [get]
+func (h *AdminHandler) GetRoleMatrix(c *gin.Context) {
+	var query RoleMatrixQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	roles, err := h.roleRepo.List(c.Request.Context())
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to fetch roles")
+		return
+	}
+	counts, err := h.roleRepo.CountUsers(c.Request.Context())
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to fetch roles")
+		return
+	}
+
+	entries := make([]RoleMatrixEntry, 0, len(roles))
+	for _, role := range roles {
+		permissions, err := role.GetPermissionsE()
+		if err != nil {
+			log.Printf("Role matrix lists no permissions for role %s (%s): %v", role.Name, role.ID, err)
+			permissions = []string{}
+		}
+		entries = append(entries, RoleMatrixEntry{
+			ID:          role.ID,
+			Name:        role.Name,
+			TenantID:    role.TenantID,
+			Permissions: permissions,
+			Users:       counts[role.ID],
+		})
+	}
+
+	format := query.Format
+	if format == "" && c.NegotiateFormat(gin.MIMEJSON, "text/csv") == "text/csv" {
+		format = "csv"
+	}
+	if format != "csv" {
+		c.JSON(http.StatusOK, RoleMatrixResponse{Data: entries})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="role-matrix.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "name", "tenant_id", "permissions", "users"})
+	for _, entry := range entries {
+		var tenantID string
+		if entry.TenantID != nil {
+			tenantID = *entry.TenantID
+		}
+		_ = w.Write([]string{
+			entry.ID,
+			entry.Name,
+			tenantID,
+			strings.Join(entry.Permissions, ";"),
+			strconv.FormatInt(entry.Users, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Failed to write role matrix: %v", err)
+	}
+}
+
+// FlushMLCache godoc
+// @Summary      Flush the ML response cache
+// @Description  Discards every cached ML service response, e.g. after the model changed without a model_version bump. Old entries stop being served at once and expire with their TTL.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  SuccessResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/cache/ml [delete]
+func (h *AdminHandler) FlushMLCache(c *gin.Context) {
+	if err := h.ml.FlushCache(c.Request.Context()); err != nil {
+		log.Printf("Failed to flush ML cache: %v", err)
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to flush ML cache"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "ML cache flushed"})
+}
+
+// ListCacheKeys godoc
+// @Summary      Inspect cache keys
+// @Description  Lists up to limit keys matching pattern, a Redis glob below the key prefix such as user:id:*, with their TTLs. The keyspace is scanned incrementally, so a rare pattern may return fewer keys than exist. Keys that embed tokens, such as refresh tokens, are never listed.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        pattern  query     string  true   "Key pattern"
+// @Param        limit    query     int     false  "Maximum keys (1-200)"  default(50)
+// @Success      200      {object}  CacheKeyListResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/admin/cache/keys [get]
+func (h *AdminHandler) ListCacheKeys(c *gin.Context) {
+	var query CacheKeysQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	keys := h.keys.For(ctx)
+	found, err := h.cache.Scan(ctx, keys.Custom(query.Pattern), query.Limit)
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to list cache keys")
+		return
+	}
+
+	entries := make([]CacheKey, 0, len(found))
+	for _, key := range found {
+		if keys.Secret(key) {
+			continue
+		}
+		ttl, err := h.cache.TTL(ctx, key)
+		if err != nil {
+			apierror.WriteFailure(c, err, "Failed to list cache keys")
+			return
+		}
+		if ttl == -2 {
+			// Expired since the scan
+			continue
+		}
+		seconds := int64(-1)
+		if ttl >= 0 {
+			seconds = int64(ttl.Seconds())
+		}
+		entries = append(entries, CacheKey{Key: key, TTLSeconds: seconds})
+	}
+
+	c.JSON(http.StatusOK, CacheKeyListResponse{Data: entries})
+}
+
+// DeleteCacheKeys godoc
+// @Summary      Invalidate cache entries
+// @Description  Deletes cache entries, e.g. after fixing a record in the database by hand. Send either keys as listed by GET /admin/cache/keys, or a type (user, business, workflow or execution) and id to delete that entity's entries without knowing the key format. Only cached entities can be deleted; sessions, refresh tokens and rate limits are refused. Every deletion is audited.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      DeleteCacheKeysRequest  true  "Keys or entity"
+// @Success      200      {object}  DeleteCacheKeysResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/admin/cache/keys [delete]
+func (h *AdminHandler) DeleteCacheKeys(c *gin.Context) {
+	var req DeleteCacheKeysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+	if (len(req.Keys) == 0) == (req.Type == "") {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Send either keys or a type and id", Code: apierror.CodeValidationFailed})
+		return
+	}
+
+	ctx := c.Request.Context()
+	builder := h.keys.For(ctx)
+	keys := req.Keys
+	if req.Type != "" {
+		var ok bool
+		keys, ok = builder.EntityKeys(req.Type, req.ID)
+		if !ok {
+			apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Unknown cache entry type", Code: apierror.CodeValidationFailed})
+			return
+		}
+	}
+
+	var refused []string
+	for _, key := range keys {
+		if !builder.Invalidatable(key) {
+			refused = append(refused, key)
+		}
+	}
+	if len(refused) > 0 {
+		apierror.Write(c, http.StatusForbidden, ErrorResponse{
+			Message: "Only cached entities can be invalidated here",
+			Details: refused,
+		})
+		return
+	}
+
+	if err := h.cache.Delete(ctx, keys...); err != nil {
+		apierror.WriteFailure(c, err, "Failed to delete cache keys")
+		return
+	}
+
+	h.auditCacheDeletion(c, keys)
+	c.JSON(http.StatusOK, DeleteCacheKeysResponse{Deleted: keys})
+}
+
+// auditCacheDeletion records which keys an admin deleted. A failure is only
+// logged, since the keys are already gone.
+func (h *AdminHandler) auditCacheDeletion(c *gin.Context, keys []string) {
+	changes, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		log.Printf("Failed to encode audit changes: %v", err)
+		return
+	}
+
+	entry := &domain.AuditLog{
+		Action:     "cache.invalidate",
+		EntityType: "cache",
+		Changes:    changes,
+	}
+	if user, ok := middleware.GetUserFromContext(c); ok {
+		entry.UserID = &user.ID
+	}
+	if ip := c.ClientIP(); ip != "" {
+		entry.IPAddress = &ip
+	}
+	if userAgent := c.Request.UserAgent(); userAgent != "" {
+		entry.UserAgent = &userAgent
+	}
+
+	if err := h.auditRepo.Create(c.Request.Context(), entry); err != nil {
+		log.Printf("Failed to write audit log for %s: %v", entry.Action, err)
+	}
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+)
+
+func TestGetConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secret = "q7Zp2LxV9mRk4TfWc8HnY3bJd6GsA1uE"
+
+	tests := []struct {
+		name       string
+		expose     bool
+		wantStatus int
+	}{
+		{"exposed", true, http.StatusOK},
+		{"hidden", false, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Server.ExposeConfig = tt.expose
+			cfg.JWT.Secret = secret
+			cfg.Database.Password = secret
+			cfg.Database.URL = "postgres://app:" + secret + "@db:5432/umkm"
+			cfg.Mail.Password = secret
+			h := NewAdminHandler(cfg, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/config", nil)
+
+			h.GetConfig(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if strings.Contains(w.Body.String(), secret) {
+				t.Errorf("response contains the secret: %s", w.Body)
+			}
+		})
+	}
+}
+
+// matrixRoles is a repository.RoleRepository serving List and CountUsers,
+// counting the calls. Any other method panics, so a per-role lookup fails
+// the test.
+type matrixRoles struct {
+	repository.RoleRepository
+	roles  []*domain.Role
+	counts map[string]int64
+	calls  int
+}
+
+func (r *matrixRoles) List(ctx context.Context) ([]*domain.Role, error) {
+	r.calls++
+	return r.roles, nil
+}
+
+func (r *matrixRoles) CountUsers(ctx context.Context) (map[string]int64, error) {
+	r.calls++
+	return r.counts, nil
+}
+
+func TestGetRoleMatrix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tenant := "warung"
+	roles := &matrixRoles{
+		roles: []*domain.Role{
+			{ID: "r1", Name: "admin", Permissions: datatypes.JSON(`["users:read","users:write"]`)},
+			{ID: "r2", Name: "kasir", TenantID: &tenant, Permissions: datatypes.JSON(`["sales:write"]`)},
+			{ID: "r3", Name: "unused", Permissions: datatypes.JSON(`[]`)},
+			{ID: "r4", Name: "broken", Permissions: datatypes.JSON(`{"not":"a list"}`)},
+		},
+		counts: map[string]int64{"r1": 2, "r2": 5},
+	}
+	h := NewAdminHandler(&config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, roles, nil, nil)
+
+	wantEntries := []RoleMatrixEntry{
+		{ID: "r1", Name: "admin", Permissions: []string{"users:read", "users:write"}, Users: 2},
+		{ID: "r2", Name: "kasir", TenantID: &tenant, Permissions: []string{"sales:write"}, Users: 5},
+		{ID: "r3", Name: "unused", Permissions: []string{}, Users: 0},
+		{ID: "r4", Name: "broken", Permissions: []string{}, Users: 0},
+	}
+	wantCSV := "id,name,tenant_id,permissions,users\n" +
+		"r1,admin,,users:read;users:write,2\n" +
+		"r2,kasir,warung,sales:write,5\n" +
+		"r3,unused,,,0\n" +
+		"r4,broken,,,0\n"
+
+	tests := []struct {
+		name       string
+		query      string
+		accept     string
+		wantStatus int
+		wantCSV    bool
+	}{
+		{"json by default", "", "", http.StatusOK, false},
+		{"csv by format", "?format=csv", "", http.StatusOK, true},
+		{"csv by Accept", "", "text/csv", http.StatusOK, true},
+		{"format wins over Accept", "?format=json", "text/csv", http.StatusOK, false},
+		{"unknown format", "?format=xml", "", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles.calls = 0
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/rbac/matrix"+tt.query, nil)
+			if tt.accept != "" {
+				c.Request.Header.Set("Accept", tt.accept)
+			}
+
+			h.GetRoleMatrix(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			// One list and one aggregate, however many roles there are
+			if roles.calls != 2 {
+				t.Errorf("repository called %d times, want 2", roles.calls)
+			}
+
+			if tt.wantCSV {
+				if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+					t.Errorf("Content-Type = %q, want text/csv", got)
+				}
+				if w.Body.String() != wantCSV {
+					t.Errorf("body = %q, want %q", w.Body.String(), wantCSV)
+				}
+				return
+			}
+
+			var body RoleMatrixResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(body.Data, wantEntries) {
+				t.Errorf("data = %+v, want %+v", body.Data, wantEntries)
+			}
+		})
+	}
+}
+
+func TestGetRuntime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runtime.GC()
+	h := NewAdminHandler(&config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/runtime", nil)
+	h.GetRuntime(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var body RuntimeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.GoVersion != runtime.Version() || body.Goroutines < 1 || body.GOMAXPROCS < 1 {
+		t.Errorf("runtime = %+v", body)
+	}
+	if body.HeapAlloc == 0 || body.HeapSys < body.HeapInuse || body.NumGC < 1 {
+		t.Errorf("heap stats = %+v, want them filled in after a GC", body)
+	}
+	if len(body.RecentGCPauses) < 1 || len(body.RecentGCPauses) > 10 {
+		t.Errorf("recent GC pauses = %v, want 1 to 10", body.RecentGCPauses)
+	}
+}
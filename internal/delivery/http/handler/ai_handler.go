@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/ai"
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHeader tells whether a generated result was served from cache (HIT)
+// or freshly generated (MISS).
+const CacheHeader = "X-Cache"
+
+type AIHandler struct {
+	aiUseCase ai.AIUseCase
+}
+
+func NewAIHandler(uc ai.AIUseCase) *AIHandler {
+	return &AIHandler{
+		aiUseCase: uc,
+	}
+}
+
+type ChatRequest struct {
+	Message        string `json:"message" binding:"required"`
+	ConversationID string `json:"conversation_id"`
+}
+
+type ChatDelta struct {
+	Delta string `json:"delta"`
+}
+
+// Chat godoc
+// @Summary      Chat with the AI assistant
+// @Description  Sends a message to the AI assistant. Clients that accept text/event-stream receive the reply as server-sent events: "delta" events carry pieces of the reply, a final "done" event carries the full result, and an "error" event is sent if the reply fails part way. Other clients receive the complete reply as JSON. Omit conversation_id to start a new conversation.
+// @Tags         ai
+// @Accept       json
+// @Produce      json,text/event-stream
+// @Security     BearerAuth
+// @Param        request body ChatRequest true "Chat message"
+// @Success      200  {object}  ai.ChatResult
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      429  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Router       /api/v1/ai/chat [post]
+func (h *AIHandler) Chat(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	var req ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	chatReq := ai.ChatRequest{
+		UserID:         user.ID,
+		ConversationID: req.ConversationID,
+		Message:        req.Message,
+	}
+
+	if !strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		result, err := h.aiUseCase.Chat(c.Request.Context(), chatReq, func(string) error { return nil })
+		if err != nil {
+			h.chatError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	// The stream only starts with the first piece of the reply, so errors
+	// before that still get a proper status code
+	streaming := false
+	result, err := h.aiUseCase.Chat(c.Request.Context(), chatReq, func(delta string) error {
+		if !streaming {
+			startEventStream(c)
+			streaming = true
+		}
+		return writeEvent(c, "delta", ChatDelta{Delta: delta})
+	})
+
+	if !streaming {
+		if err != nil {
+			h.chatError(c, err)
+			return
+		}
+		startEventStream(c)
+	}
+	if err != nil {
+		if c.Request.Context().Err() == nil {
+			log.Printf("AI chat failed mid-stream: %v", err)
+			writeEvent(c, "error", ErrorResponse{Message: "The reply was interrupted", Code: apierror.CodeAIUnavailable})
+		}
+		return
+	}
+	writeEvent(c, "done", result)
+}
+
+func (h *AIHandler) chatError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ai.ErrEmptyMessage):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Message is empty"})
+	case errors.Is(err, ai.ErrMessageTooLong):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Message is too long"})
+	case errors.Is(err, ai.ErrInvalidConversation):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid conversation ID"})
+	case errors.Is(err, ai.ErrInvalidInput):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "The AI assistant could not process this message"})
+	case errors.Is(err, ai.ErrUnavailable):
+		c.Header("Retry-After", "30")
+		apierror.Write(c, http.StatusServiceUnavailable, ErrorResponse{Message: "AI temporarily unavailable", Code: apierror.CodeAIUnavailable})
+	default:
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to chat with the AI assistant"})
+	}
+}
+
+type BusinessDescriptionRequest struct {
+	BusinessName string   `json:"business_name" binding:"required,max=100"`
+	Category     string   `json:"category" binding:"required,max=50"`
+	Products     []string `json:"products" binding:"max=10,dive,max=100"`
+	Tone         string   `json:"tone" binding:"omitempty,oneof=friendly professional casual persuasive" enums:"friendly,professional,casual,persuasive" default:"friendly"`
+	Language     string   `json:"language" binding:"omitempty,oneof=id en" enums:"id,en" default:"id"`
+}
+
+type RegenerateRequest struct {
+	Feedback string `json:"feedback" binding:"max=500"`
+}
+
+// BusinessDescription godoc
+// @Summary      Generate a business description
+// @Description  Writes a short description of the user's business from its name, category and products. Identical requests within a short window return the same result. Use the returned generation_id to regenerate it with feedback.
+// @Tags         ai
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body BusinessDescriptionRequest true "Business details"
+// @Success      200  {object}  ai.GenerationResult
+// @Header       200  {string}  X-Cache  "HIT when the result was served from cache, otherwise MISS"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      429  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Failure      502  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Router       /api/v1/ai/business-description [post]
+func (h *AIHandler) BusinessDescription(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	var req BusinessDescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{
+			Message: "Invalid request body",
+			Details: validationDetails(err),
+		})
+		return
+	}
+
+	result, err := h.aiUseCase.GenerateBusinessDescription(c.Request.Context(), ai.BusinessDescriptionRequest{
+		UserID:       user.ID,
+		BusinessName: req.BusinessName,
+		Category:     req.Category,
+		Products:     req.Products,
+		Tone:         req.Tone,
+		Language:     req.Language,
+	})
+	if err != nil {
+		h.generationError(c, err)
+		return
+	}
+
+	setCacheHeader(c, result.Cached)
+	c.JSON(http.StatusOK, result)
+}
+
+// RegenerateBusinessDescription godoc
+// @Summary      Regenerate a business description
+// @Description  Writes a new version of one of the user's earlier business descriptions, taking the optional feedback into account. The new version gets its own generation_id.
+// @Tags         ai
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Generation ID"
+// @Param        request body RegenerateRequest false "Feedback"
+// @Success      200  {object}  ai.GenerationResult
+// @Header       200  {string}  X-Cache  "HIT when the result was served from cache, otherwise MISS"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      429  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Failure      502  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Router       /api/v1/ai/business-description/{id}/regenerate [post]
+func (h *AIHandler) RegenerateBusinessDescription(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	var req RegenerateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Write(c, http.StatusBadRequest, ErrorResponse{
+				Message: "Invalid request body",
+				Details: validationDetails(err),
+			})
+			return
+		}
+	}
+
+	result, err := h.aiUseCase.RegenerateBusinessDescription(c.Request.Context(), ai.RegenerateRequest{
+		UserID:       user.ID,
+		GenerationID: c.Param("id"),
+		Feedback:     req.Feedback,
+	})
+	if err != nil {
+		h.generationError(c, err)
+		return
+	}
+
+	setCacheHeader(c, result.Cached)
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *AIHandler) generationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ai.ErrInvalidBusinessInput):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid business details"})
+	case errors.Is(err, ai.ErrInappropriateInput):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Please remove inappropriate language and try again", Code: apierror.CodeInappropriateInput})
+	case errors.Is(err, ai.ErrGenerationNotFound):
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "Generation not found"})
+	case errors.Is(err, ai.ErrNotRegenerable):
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "This generation has no text to regenerate"})
+	case errors.Is(err, ai.ErrNoUsableOutput):
+		apierror.Write(c, http.StatusBadGateway, ErrorResponse{Message: "The AI could not write a description, please try again", Code: apierror.CodeNoUsableOutput})
+	case errors.Is(err, ai.ErrInvalidInput):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "The AI could not process these details"})
+	case errors.Is(err, ai.ErrUnavailable):
+		c.Header("Retry-After", "30")
+		apierror.Write(c, http.StatusServiceUnavailable, ErrorResponse{Message: "AI temporarily unavailable", Code: apierror.CodeAIUnavailable})
+	default:
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to generate description"})
+	}
+}
+
+func setCacheHeader(c *gin.Context, hit bool) {
+	if hit {
+		c.Header(CacheHeader, "HIT")
+	} else {
+		c.Header(CacheHeader, "MISS")
+	}
+}
+
+// startEventStream sends the SSE response headers. Streams outlive the
+// server's write timeout, so it is lifted for this response.
+func startEventStream(c *gin.Context) {
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Failed to lift write deadline for event stream: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	// Connection headers are forbidden in HTTP/2, where streams never close
+	// the connection anyway
+	if c.Request.ProtoMajor == 1 {
+		c.Header("Connection", "keep-alive")
+	}
+	// Stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+}
+
+// writeEvent writes one server-sent event and flushes it to the client.
+func writeEvent(c *gin.Context, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event, err)
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"strings"
+	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/cookie"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
 	"github.com/gin-gonic/gin"
@@ -11,16 +14,16 @@ import (
 )
 
 type AuthHandler struct {
-	authUseCase  auth.AuthUseCase
-	validate     *validator.Validate
-	isProduction bool
+	authUseCase auth.AuthUseCase
+	validate    *validator.Validate
+	cookies     *cookie.Writer
 }
 
-func NewAuthHandler(authUseCase auth.AuthUseCase, isProduction bool) *AuthHandler {
+func NewAuthHandler(authUseCase auth.AuthUseCase, cookies *cookie.Writer) *AuthHandler {
 	return &AuthHandler{
-		authUseCase:  authUseCase,
-		validate:     validator.New(),
-		isProduction: isProduction,
+		authUseCase: authUseCase,
+		validate:    validator.New(),
+		cookies:     cookies,
 	}
 }
 
@@ -34,10 +37,13 @@ type LogoutRequest struct {
 }
 
 type AuthResponse struct {
-	Message      string       `json:"message"`
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token,omitempty"`
-	User         *domain.User `json:"user,omitempty"`
+	Message      string `json:"message"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// RefreshExpiresAt is when the refresh token runs out unless it is used;
+	// every refresh moves it forward, up to the session's absolute limit
+	RefreshExpiresAt *time.Time   `json:"refresh_expires_at,omitempty"`
+	User             *domain.User `json:"user,omitempty"`
 }
 
 // Register godoc
@@ -49,33 +55,33 @@ type AuthResponse struct {
 // @Param        request body auth.RegisterRequest true "Register Request"
 // @Success      201  {object}  AuthResponse
 // @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse  "email_domain_not_allowed"
 // @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
 // @Router       /api/v1/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req auth.RegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
 		return
 	}
 
 	res, err := h.authUseCase.Register(c.Request.Context(), req)
 	if err != nil {
-		if strings.Contains(err.Error(), "already registered") {
-			c.JSON(http.StatusConflict, ErrorResponse{Error: "Email already registered"})
-			return
-		}
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		// Validation failures, a taken email or an internal error
+		apierror.WriteError(c, err)
 		return
 	}
 
-	h.setRefreshTokenCookie(c, res.RefreshToken)
+	h.setRefreshTokenCookie(c, res.RefreshToken, res.RefreshExpiresAt)
 
 	c.JSON(http.StatusCreated, AuthResponse{
-		Message:      "User registered successfully",
-		AccessToken:  res.AccessToken,
-		RefreshToken: res.RefreshToken,
-		User:         res.User,
+		Message:          "User registered successfully",
+		AccessToken:      res.AccessToken,
+		RefreshToken:     res.RefreshToken,
+		RefreshExpiresAt: &res.RefreshExpiresAt,
+		User:             res.User,
 	})
 }
 
@@ -89,28 +95,32 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success      200  {object}  AuthResponse
 // @Failure      400  {object}  ErrorResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
 // @Router       /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req auth.LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
 		return
 	}
 
 	res, err := h.authUseCase.Login(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid email or password"})
+		// Wrong credentials, a disabled account or an internal error
+		apierror.WriteError(c, err)
 		return
 	}
 
-	h.setRefreshTokenCookie(c, res.RefreshToken)
+	h.setRefreshTokenCookie(c, res.RefreshToken, res.RefreshExpiresAt)
 
 	c.JSON(http.StatusOK, AuthResponse{
-		Message:      "Login successful",
-		AccessToken:  res.AccessToken,
-		RefreshToken: res.RefreshToken,
-		User:         res.User,
+		Message:          "Login successful",
+		AccessToken:      res.AccessToken,
+		RefreshToken:     res.RefreshToken,
+		RefreshExpiresAt: &res.RefreshExpiresAt,
+		User:             res.User,
 	})
 }
 
@@ -123,12 +133,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Param        request body RefreshTokenRequest false "Refresh Token Request"
 // @Success      200  {object}  AuthResponse
 // @Failure      400  {object}  ErrorResponse
-// @Failure      401  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse  "invalid_token or session_expired"
+// @Failure      403  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse  "refresh_in_progress or refresh_token_rotated"
+// @Failure      503  {object}  ErrorResponse
 // @Router       /api/v1/auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var refreshToken string
 
-	cookieToken, err := c.Cookie("refresh_token")
+	cookieToken, err := c.Cookie(h.cookies.RefreshTokenName())
 	if err == nil && cookieToken != "" {
 		refreshToken = cookieToken
 	} else {
@@ -139,25 +152,52 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	}
 
 	if refreshToken == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Refresh token is required"})
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Refresh token is required", Code: apierror.CodeValidationFailed})
 		return
 	}
 
 	res, err := h.authUseCase.RefreshToken(c.Request.Context(), refreshToken)
+	if errors.Is(err, auth.ErrAccountDisabled) {
+		h.cookies.Clear(c, h.cookies.RefreshTokenName())
+		apierror.Write(c, http.StatusForbidden, ErrorResponse{Message: "Account is disabled", Code: apierror.CodeAccountDisabled})
+		return
+	}
+	if errors.Is(err, auth.ErrSessionExpired) {
+		h.cookies.Clear(c, h.cookies.RefreshTokenName())
+		apierror.Write(c, http.StatusUnauthorized, ErrorResponse{Message: "Session expired, please log in again", Code: apierror.CodeSessionExpired})
+		return
+	}
+	if errors.Is(err, auth.ErrRefreshInProgress) {
+		c.Header("Retry-After", "1")
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "Refresh already in progress, please retry", Code: apierror.CodeRefreshInProgress})
+		return
+	}
+	if errors.Is(err, auth.ErrRefreshTokenRotated) {
+		// The cookie is left alone: the request that rotated the token may
+		// already have replaced it with the new one
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "Refresh token was already rotated, use the latest one", Code: apierror.CodeRefreshTokenRotated})
+		return
+	}
+	if errors.Is(err, auth.ErrInvalidRefreshToken) {
+		apierror.Write(c, http.StatusUnauthorized, ErrorResponse{Message: "Invalid or expired refresh token", Code: apierror.CodeInvalidToken})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid or expired refresh token"})
+		// An outage answers 503, so the client keeps its token and retries
+		apierror.WriteError(c, err)
 		return
 	}
 
 	if cookieToken != "" {
-		h.setRefreshTokenCookie(c, res.RefreshToken)
+		h.setRefreshTokenCookie(c, res.RefreshToken, res.RefreshExpiresAt)
 	}
 
 	c.JSON(http.StatusOK, AuthResponse{
-		Message:      "Token refreshed successfully",
-		AccessToken:  res.AccessToken,
-		RefreshToken: res.RefreshToken,
-		User:         res.User,
+		Message:          "Token refreshed successfully",
+		AccessToken:      res.AccessToken,
+		RefreshToken:     res.RefreshToken,
+		RefreshExpiresAt: &res.RefreshExpiresAt,
+		User:             res.User,
 	})
 }
 
@@ -171,7 +211,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 // @Success      200  {object}  SuccessResponse
 // @Router       /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	refreshToken, _ := c.Cookie("refresh_token")
+	refreshToken, _ := c.Cookie(h.cookies.RefreshTokenName())
 	if refreshToken == "" {
 		var req LogoutRequest
 		c.ShouldBindJSON(&req)
@@ -182,19 +222,11 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		h.authUseCase.Logout(c.Request.Context(), refreshToken)
 	}
 
-	c.SetCookie("refresh_token", "", -1, "/", "", h.isProduction, true)
+	h.cookies.Clear(c, h.cookies.RefreshTokenName())
 
 	c.JSON(http.StatusOK, SuccessResponse{Message: "Logged out successfully"})
 }
 
-func (h *AuthHandler) setRefreshTokenCookie(c *gin.Context, token string) {
-	c.SetCookie(
-		"refresh_token",
-		token,
-		7*24*60*60,
-		"/",
-		"",
-		h.isProduction,
-		true,
-	)
+func (h *AuthHandler) setRefreshTokenCookie(c *gin.Context, token string, expiresAt time.Time) {
+	h.cookies.SetUntil(c, h.cookies.RefreshTokenName(), token, expiresAt)
 }
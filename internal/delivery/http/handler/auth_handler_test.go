@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/cookie"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// refreshStub is an AuthUseCase whose RefreshToken fails with err.
+type refreshStub struct {
+	auth.AuthUseCase
+	err error
+}
+
+func (s refreshStub) RefreshToken(context.Context, string) (*auth.AuthResponse, error) {
+	return nil, s.err
+}
+
+func TestRefreshTokenErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    apierror.Code
+		wantCleared bool
+	}{
+		{"invalid token", auth.ErrInvalidRefreshToken, http.StatusUnauthorized, apierror.CodeInvalidToken, false},
+		{"session expired", auth.ErrSessionExpired, http.StatusUnauthorized, apierror.CodeSessionExpired, true},
+		{"account disabled", auth.ErrAccountDisabled, http.StatusForbidden, apierror.CodeAccountDisabled, true},
+		{"rotation in progress", auth.ErrRefreshInProgress, http.StatusConflict, apierror.CodeRefreshInProgress, false},
+		{"already rotated", auth.ErrRefreshTokenRotated, http.StatusConflict, apierror.CodeRefreshTokenRotated, false},
+		{"database down", fmt.Errorf("failed to find user: %w", domain.ErrUnavailable), http.StatusServiceUnavailable, apierror.CodeUnavailable, false},
+		{"unexpected", errors.New("boom"), http.StatusInternalServerError, apierror.CodeInternal, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies := cookie.NewWriter(config.CookieConfig{}, false)
+			h := NewAuthHandler(refreshStub{err: tt.err}, cookies)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", strings.NewReader(`{"refresh_token":"old"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.RefreshToken(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+			if cleared := strings.Contains(w.Header().Get("Set-Cookie"), "refresh_token=;"); cleared != tt.wantCleared {
+				t.Errorf("cookie cleared = %v, want %v", cleared, tt.wantCleared)
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+				t.Error("503 without Retry-After")
+			}
+		})
+	}
+}
+
+// loginStub is an AuthUseCase whose Login fails with err.
+type loginStub struct {
+	auth.AuthUseCase
+	err error
+}
+
+func (s loginStub) Login(context.Context, auth.LoginRequest) (*auth.AuthResponse, error) {
+	return nil, s.err
+}
+
+func TestLoginErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   apierror.Code
+	}{
+		{"wrong credentials", domain.ErrInvalidCredentials, http.StatusUnauthorized, apierror.CodeInvalidCredentials},
+		{"account disabled", auth.ErrAccountDisabled, http.StatusForbidden, apierror.CodeAccountDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies := cookie.NewWriter(config.CookieConfig{}, false)
+			h := NewAuthHandler(loginStub{err: tt.err}, cookies)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"owner@example.com","password":"secret"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.Login(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+			if w.Header().Get("Set-Cookie") != "" {
+				t.Error("refused login set a cookie")
+			}
+		})
+	}
+}
+
+// registerStub is an AuthUseCase whose Register fails with err.
+type registerStub struct {
+	auth.AuthUseCase
+	err error
+}
+
+func (s registerStub) Register(context.Context, auth.RegisterRequest) (*auth.AuthResponse, error) {
+	return nil, s.err
+}
+
+func TestRegisterErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	valid := `{"email":"owner@example.com","password":"Secret123!","name":"Toko Budi"}`
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+		wantCode   apierror.Code
+	}{
+		{"malformed body", `{"email":`, nil, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"weak password", valid, domain.NewValidationError("password is too weak"), http.StatusBadRequest, apierror.CodeValidationFailed},
+		{"email taken", valid, fmt.Errorf("register: %w", domain.ErrEmailTaken), http.StatusConflict, apierror.CodeEmailTaken},
+		{"email domain blocked", valid, domain.ErrEmailDomainNotAllowed, http.StatusForbidden, apierror.CodeEmailDomainBlocked},
+		{"database down", valid, fmt.Errorf("failed to create user: %w", domain.ErrUnavailable), http.StatusServiceUnavailable, apierror.CodeUnavailable},
+		{"unexpected", valid, errors.New("duplicate key value violates unique constraint"), http.StatusInternalServerError, apierror.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies := cookie.NewWriter(config.CookieConfig{}, false)
+			h := NewAuthHandler(registerStub{err: tt.err}, cookies)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.Register(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+			if tt.err != nil && tt.wantCode == apierror.CodeInternal && body.Message == tt.err.Error() {
+				t.Errorf("message %q leaks the error", body.Message)
+			}
+		})
+	}
+}
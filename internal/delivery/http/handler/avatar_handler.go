@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	fileUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/file"
+	"github.com/gin-gonic/gin"
+)
+
+type AvatarHandler struct {
+	avatarUseCase fileUseCase.AvatarUseCase
+	maxFileSize   int64
+}
+
+func NewAvatarHandler(uc fileUseCase.AvatarUseCase, maxFileSize int64) *AvatarHandler {
+	return &AvatarHandler{
+		avatarUseCase: uc,
+		maxFileSize:   maxFileSize,
+	}
+}
+
+type AvatarVariant struct {
+	Size int    `json:"size"`
+	URL  string `json:"url"`
+}
+
+type AvatarResponse struct {
+	AvatarURL string          `json:"avatar_url"`
+	Variants  []AvatarVariant `json:"variants"`
+}
+
+// Upload godoc
+// @Summary      Upload current user's avatar
+// @Description  Sets the current user's avatar from a JPEG, PNG, GIF or WebP image sent in the "file" field. The image is turned upright according to its EXIF orientation, cropped to a centered square and stored as JPEG in each configured size, without metadata. Only the first frame of an animated image is used. avatar_url points to the largest variant; variants lists every size, largest first. Images larger than upload.avatar.max_pixels are rejected.
+// @Tags         users
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file formData file true "Avatar image"
+// @Success      200  {object}  AvatarResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      413  {object}  ErrorResponse
+// @Failure      415  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Router       /api/v1/users/me/avatar [put]
+func (h *AvatarHandler) Upload(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxFileSize+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Expected a multipart/form-data request"})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Missing file field"})
+			return
+		}
+		if err != nil {
+			h.uploadError(c, err)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		result, err := h.avatarUseCase.Upload(c.Request.Context(), fileUseCase.AvatarUploadRequest{
+			User: user,
+			Body: part,
+		})
+		part.Close()
+		if err != nil {
+			h.uploadError(c, err)
+			return
+		}
+
+		variants := make([]AvatarVariant, len(result.Variants))
+		for i, v := range result.Variants {
+			variants[i] = AvatarVariant{Size: v.Size, URL: v.URL}
+		}
+		c.JSON(http.StatusOK, AvatarResponse{
+			AvatarURL: *result.User.AvatarURL,
+			Variants:  variants,
+		})
+		return
+	}
+}
+
+func (h *AvatarHandler) uploadError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, fileUseCase.ErrFileTooLarge), errors.As(err, &maxBytesErr):
+		apierror.Write(c, http.StatusRequestEntityTooLarge, ErrorResponse{Message: "File exceeds the maximum allowed size"})
+	case errors.Is(err, fileUseCase.ErrImageTooLarge):
+		apierror.Write(c, http.StatusRequestEntityTooLarge, ErrorResponse{Message: "Image dimensions exceed the maximum allowed"})
+	case errors.Is(err, fileUseCase.ErrFileTypeNotAllowed):
+		apierror.Write(c, http.StatusUnsupportedMediaType, ErrorResponse{Message: "Avatar must be a JPEG, PNG, GIF or WebP image"})
+	case errors.Is(err, fileUseCase.ErrInvalidImage):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Image is corrupt or unreadable"})
+	case errors.Is(err, fileUseCase.ErrEmptyFile):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "File is empty"})
+	case errors.Is(err, fileUseCase.ErrProcessingTimeout):
+		c.Header("Retry-After", "10")
+		apierror.Write(c, http.StatusServiceUnavailable, ErrorResponse{Message: "Image processing is busy, try again later"})
+	case errors.Is(err, storage.ErrNotConfigured):
+		apierror.Write(c, http.StatusServiceUnavailable, ErrorResponse{Message: "File storage is not available"})
+	default:
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to upload avatar"})
+	}
+}
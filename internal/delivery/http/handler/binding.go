@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report fields by their query/JSON names instead of Go struct names.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"form", "json"} {
+		name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// DefaultMaxPageOffset is how deep the list endpoints page unless
+// configured otherwise
+const DefaultMaxPageOffset = 10000
+
+// maxPageOffset bounds the offset of list queries, since the database still
+// reads every skipped row; 0 means no bound
+var maxPageOffset atomic.Int64
+
+func init() {
+	maxPageOffset.Store(DefaultMaxPageOffset)
+}
+
+// SetMaxPageOffset sets the largest offset the list endpoints accept; 0
+// removes the bound.
+func SetMaxPageOffset(n int) {
+	maxPageOffset.Store(int64(n))
+}
+
+// pagedQuery is implemented by the list queries so bindQuery can bound how
+// deep they page.
+type pagedQuery interface {
+	pageOffset() int
+}
+
+// bindQuery binds the query string into req and validates it using the
+// `form` and `binding` struct tags. On failure it writes a 400 with one
+// detail per invalid field and returns false.
+func bindQuery(c *gin.Context, req any) bool {
+	if err := c.ShouldBindQuery(req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{
+			Message: "Invalid query parameters",
+			Details: validationDetails(err),
+		})
+		return false
+	}
+	if q, ok := req.(pagedQuery); ok {
+		if limit := maxPageOffset.Load(); limit > 0 && int64(q.pageOffset()) > limit {
+			apierror.Write(c, http.StatusBadRequest, ErrorResponse{
+				Message: "Invalid query parameters",
+				Details: []string{fmt.Sprintf("offset must be at most %d; narrow the query instead of paging this deep", limit)},
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// validationDetails turns binding and validation errors into human readable
+// messages keyed by field name.
+func validationDetails(err error) []string {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return []string{err.Error()}
+	}
+
+	details := make([]string, 0, len(verrs))
+	for _, fe := range verrs {
+		details = append(details, describeFieldError(fe))
+	}
+	return details
+}
+
+func describeFieldError(fe validator.FieldError) string {
+	field := fe.Field()
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min":
+		if fe.Kind() == reflect.Slice || fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must have at least %s items or characters", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		if fe.Kind() == reflect.Slice || fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must have at most %s items or characters", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", field, fe.Param())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "uuid":
+		return fmt.Sprintf("%s must be a valid UUID", field)
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL", field)
+	case "len=0|url":
+		return fmt.Sprintf("%s must be a valid URL or empty", field)
+	default:
+		return fmt.Sprintf("%s failed the '%s' rule", field, fe.Tag())
+	}
+}
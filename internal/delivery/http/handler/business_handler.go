@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	businessUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/business"
+	"github.com/gin-gonic/gin"
+)
+
+type BusinessHandler struct {
+	businessUseCase businessUseCase.BusinessUseCase
+}
+
+func NewBusinessHandler(uc businessUseCase.BusinessUseCase) *BusinessHandler {
+	return &BusinessHandler{
+		businessUseCase: uc,
+	}
+}
+
+type CreateBusinessRequest struct {
+	Name        string  `json:"name" binding:"required,max=100" example:"Warung Bu Sri"`
+	Category    string  `json:"category" binding:"required,max=50" example:"food"`
+	Description string  `json:"description" binding:"max=2000"`
+	Address     string  `json:"address" binding:"max=500"`
+	Phone       string  `json:"phone" binding:"max=30" example:"+6281234567890"`
+	LogoURL     *string `json:"logo_url" binding:"omitnil,max=2048,len=0|url"`
+}
+
+type UpdateBusinessRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitnil,min=1,max=100"`
+	Category    *string `json:"category,omitempty" binding:"omitnil,min=1,max=50"`
+	Description *string `json:"description,omitempty" binding:"omitnil,max=2000"`
+	Address     *string `json:"address,omitempty" binding:"omitnil,max=500"`
+	Phone       *string `json:"phone,omitempty" binding:"omitnil,max=30"`
+	// LogoURL set to "" removes the logo
+	LogoURL *string `json:"logo_url,omitempty" binding:"omitnil,max=2048,len=0|url"`
+}
+
+type BusinessListResponse struct {
+	Data []*domain.Business `json:"data"`
+}
+
+// Create godoc
+// @Summary      Create a business
+// @Description  Adds a business profile owned by the current user. Each user can own a limited number of businesses.
+// @Tags         businesses
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateBusinessRequest true "Business"
+// @Success      201  {object}  domain.Business
+// @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/businesses [post]
+func (h *BusinessHandler) Create(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	var req CreateBusinessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+
+	business, err := h.businessUseCase.Create(c.Request.Context(), businessUseCase.CreateRequest{
+		OwnerID:     user.ID,
+		Name:        req.Name,
+		Category:    req.Category,
+		Description: req.Description,
+		Address:     req.Address,
+		Phone:       req.Phone,
+		LogoURL:     req.LogoURL,
+	})
+	if err != nil {
+		h.writeError(c, err, "Failed to create business")
+		return
+	}
+
+	c.JSON(http.StatusCreated, business)
+}
+
+// ListMine godoc
+// @Summary      List my businesses
+// @Tags         businesses
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  BusinessListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/users/me/businesses [get]
+func (h *BusinessHandler) ListMine(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	businesses, err := h.businessUseCase.ListByOwner(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to fetch businesses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, BusinessListResponse{Data: businesses})
+}
+
+// Get godoc
+// @Summary      Get a business
+// @Tags         businesses
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Business ID"
+// @Success      200  {object}  domain.Business
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/businesses/{id} [get]
+func (h *BusinessHandler) Get(c *gin.Context) {
+	business, err := h.businessUseCase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch business")
+		return
+	}
+
+	c.JSON(http.StatusOK, business)
+}
+
+// Update godoc
+// @Summary      Update a business
+// @Description  Changes the fields given. Only the owner or an admin can update a business.
+// @Tags         businesses
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path  string                 true  "Business ID"
+// @Param        request body  UpdateBusinessRequest  true  "Fields to change"
+// @Success      200  {object}  domain.Business
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/businesses/{id} [put]
+func (h *BusinessHandler) Update(c *gin.Context) {
+	business, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateBusinessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+
+	updated, err := h.businessUseCase.Update(c.Request.Context(), business.ID, businessUseCase.UpdateRequest{
+		Name:        req.Name,
+		Category:    req.Category,
+		Description: req.Description,
+		Address:     req.Address,
+		Phone:       req.Phone,
+		LogoURL:     req.LogoURL,
+	})
+	if err != nil {
+		h.writeError(c, err, "Failed to update business")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete godoc
+// @Summary      Delete a business
+// @Description  Only the owner or an admin can delete a business.
+// @Tags         businesses
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Business ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/businesses/{id} [delete]
+func (h *BusinessHandler) Delete(c *gin.Context) {
+	business, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	if err := h.businessUseCase.Delete(c.Request.Context(), business.ID); err != nil {
+		h.writeError(c, err, "Failed to delete business")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Business deleted successfully"})
+}
+
+// authorize loads the business in the :id path parameter and checks the
+// caller owns it or is an admin. It writes the error response and returns
+// false otherwise.
+func (h *BusinessHandler) authorize(c *gin.Context) (*domain.Business, bool) {
+	business, err := h.businessUseCase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch business")
+		return nil, false
+	}
+
+	if !middleware.HasRole(c, "admin") {
+		middleware.MustCheckOwnership(c, business.UserID)
+		if c.IsAborted() {
+			return nil, false
+		}
+	}
+
+	return business, true
+}
+
+func (h *BusinessHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, businessUseCase.ErrLimitReached):
+		apierror.Write(c, http.StatusConflict, ErrorResponse{
+			Message: "Business limit reached",
+			Details: []string{fmt.Sprintf("a user can own at most %d businesses", h.businessUseCase.MaxPerUser())},
+		})
+	case errors.Is(err, repository.ErrBusinessNotFound):
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "Business not found"})
+	default:
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: fallback})
+	}
+}
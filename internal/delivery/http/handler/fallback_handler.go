@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// NoRoute answers requests for paths no route matches.
+func NoRoute(c *gin.Context) {
+	apierror.Write(c, http.StatusNotFound, ErrorResponse{
+		Message: "Route not found",
+		Code:    apierror.CodeRouteNotFound,
+	})
+}
+
+// NoMethod answers requests for a known path with a method it doesn't
+// support. The router has already set the Allow header.
+func NoMethod(c *gin.Context) {
+	resp := ErrorResponse{
+		Message: "Method not allowed",
+		Code:    apierror.CodeMethodNotAllowed,
+	}
+	if allow := c.Writer.Header().Get("Allow"); allow != "" {
+		resp.Details = []string{"Allowed methods: " + allow}
+	}
+	apierror.Write(c, http.StatusMethodNotAllowed, resp)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+func TestFallbacks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Configured the way SetupRoutes configures the real router
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(NoRoute)
+	router.NoMethod(NoMethod)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/users/:id", ok)
+	router.PUT("/api/v1/users/:id", ok)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantCode   apierror.Code
+		wantAllow  string
+	}{
+		{"known route", http.MethodGet, "/api/v1/users/42", http.StatusOK, "", ""},
+		{"unknown path", http.MethodGet, "/api/v1/nope", http.StatusNotFound, apierror.CodeRouteNotFound, ""},
+		{"unknown path, any method", http.MethodDelete, "/nope", http.StatusNotFound, apierror.CodeRouteNotFound, ""},
+		{"wrong method", http.MethodPost, "/api/v1/users/42", http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "GET, PUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+
+			var body ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q is not the error envelope: %v", w.Body.String(), err)
+			}
+			if body.Code != tt.wantCode || body.Message == "" {
+				t.Errorf("body = %+v, want code %q", body, tt.wantCode)
+			}
+			if tt.wantAllow != "" && (len(body.Details) != 1 || body.Details[0] != "Allowed methods: "+tt.wantAllow) {
+				t.Errorf("details = %v, want the allowed methods", body.Details)
+			}
+		})
+	}
+}
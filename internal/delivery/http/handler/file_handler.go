@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	fileUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/file"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for part headers and boundaries on top of the
+// file itself when capping the request body.
+const multipartOverhead = 64 * 1024
+
+type FileHandler struct {
+	fileUseCase fileUseCase.FileUseCase
+	maxFileSize int64
+}
+
+func NewFileHandler(uc fileUseCase.FileUseCase, maxFileSize int64) *FileHandler {
+	return &FileHandler{
+		fileUseCase: uc,
+		maxFileSize: maxFileSize,
+	}
+}
+
+type FileResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Filename    string    `json:"filename"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Upload godoc
+// @Summary      Upload a file
+// @Description  Streams a multipart upload to object storage. The file must be sent in the "file" field; its extension, declared type and content must match the upload allowlist, and its size must not exceed upload.max_file_size. HTML, XML and SVG content is always rejected. Files other than images are served as attachments.
+// @Tags         files
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        file formData file true "File to upload"
+// @Success      201  {object}  FileResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      413  {object}  ErrorResponse
+// @Failure      415  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Router       /api/v1/files [post]
+func (h *FileHandler) Upload(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxFileSize+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Expected a multipart/form-data request"})
+		return
+	}
+
+	// Read parts one at a time so the file is never buffered in full
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Missing file field"})
+			return
+		}
+		if err != nil {
+			h.uploadError(c, err)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		file, err := h.fileUseCase.Upload(c.Request.Context(), fileUseCase.UploadRequest{
+			OwnerID:     user.ID,
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Body:        part,
+		})
+		part.Close()
+		if err != nil {
+			h.uploadError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, FileResponse{
+			ID:          file.ID,
+			URL:         h.fileUseCase.URL(file),
+			Filename:    file.OriginalFilename,
+			Size:        file.FileSize,
+			ContentType: file.MimeType,
+			CreatedAt:   file.CreatedAt,
+		})
+		return
+	}
+}
+
+func (h *FileHandler) uploadError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, fileUseCase.ErrFileTooLarge), errors.As(err, &maxBytesErr):
+		apierror.Write(c, http.StatusRequestEntityTooLarge, ErrorResponse{Message: "File exceeds the maximum allowed size"})
+	case errors.Is(err, fileUseCase.ErrFileTypeNotAllowed):
+		apierror.Write(c, http.StatusUnsupportedMediaType, ErrorResponse{Message: "File type not allowed"})
+	case errors.Is(err, fileUseCase.ErrEmptyFile):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "File is empty"})
+	case errors.Is(err, storage.ErrNotConfigured):
+		apierror.Write(c, http.StatusServiceUnavailable, ErrorResponse{Message: "File storage is not available"})
+	default:
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to upload file"})
+	}
+}
+
+// Delete godoc
+// @Summary      Delete a file
+// @Description  Deletes an uploaded file and its stored object. Only the owner or an admin can delete a file.
+// @Tags         files
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "File ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Failure      503  {object}  ErrorResponse
+// @Router       /api/v1/files/{id} [delete]
+func (h *FileHandler) Delete(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	file, err := h.fileUseCase.GetByID(ctx, c.Param("id"))
+	if errors.Is(err, repository.ErrFileNotFound) {
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "File not found"})
+		return
+	}
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to fetch file"})
+		return
+	}
+
+	if !middleware.HasRole(c, "admin") {
+		middleware.MustCheckOwnership(c, file.UserID)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	err = h.fileUseCase.Delete(ctx, file.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, repository.ErrFileNotFound):
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "File not found"})
+		return
+	case errors.Is(err, storage.ErrNotConfigured):
+		apierror.Write(c, http.StatusServiceUnavailable, ErrorResponse{Message: "File storage is not available"})
+		return
+	default:
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to delete file"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "File deleted successfully"})
+}
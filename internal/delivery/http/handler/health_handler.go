@@ -1,37 +1,76 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
 	"github.com/Elysian-Rebirth/backend-go/internal/config"
-	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
-	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	"github.com/Elysian-Rebirth/backend-go/internal/version"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
+	"golang.org/x/sync/singleflight"
 )
 
 type HealthHandler struct {
-	cfg   *config.Config
-	db    *gorm.DB
-	cache cache.Cache
+	cfg       *config.Config
+	checks    *health.Registry
+	readiness *health.Readiness
+
+	// probes coalesces concurrent checks, so a burst of probes reaches the
+	// dependencies once
+	probes   singleflight.Group
+	mu       sync.Mutex
+	cached   health.Report
+	cachedAt time.Time
 }
 
-func NewHealthHandler(cfg *config.Config, db *gorm.DB, cache cache.Cache) *HealthHandler {
+func NewHealthHandler(cfg *config.Config, checks *health.Registry, readiness *health.Readiness) *HealthHandler {
 	return &HealthHandler{
-		cfg:   cfg,
-		db:    db,
-		cache: cache,
+		cfg:       cfg,
+		checks:    checks,
+		readiness: readiness,
 	}
 }
 
-// Request and Response structs
+// report returns the result of the dependency checks, reusing one up to
+// server.health_cache_ttl old unless fresh is set.
+func (h *HealthHandler) report(ctx context.Context, fresh bool) health.Report {
+	ttl := h.cfg.Server.HealthCacheTTL
+	if !fresh && ttl > 0 {
+		h.mu.Lock()
+		report, at := h.cached, h.cachedAt
+		h.mu.Unlock()
+		if !at.IsZero() && time.Since(at) < ttl {
+			return report
+		}
+	}
 
-type ErrorResponse struct {
-	Error   string   `json:"error"`
-	Details []string `json:"details,omitempty"`
+	// Not tied to the caller's context: every coalesced request shares the
+	// outcome, even if the first one goes away
+	run := func() (any, error) {
+		report := h.checks.Check(context.WithoutCancel(ctx))
+		h.mu.Lock()
+		h.cached, h.cachedAt = report, time.Now()
+		h.mu.Unlock()
+		return report, nil
+	}
+	if fresh {
+		report, _ := run()
+		return report.(health.Report)
+	}
+	report, _, _ := h.probes.Do("report", run)
+	return report.(health.Report)
 }
 
+// Request and Response structs
+
+// ErrorResponse is the body of every error response; see package apierror
+// for the codes.
+type ErrorResponse = apierror.Response
+
 type SuccessResponse struct {
 	Message string `json:"message"`
 }
@@ -41,68 +80,114 @@ type PingResponse struct {
 }
 
 type HealthResponse struct {
-	Status      string                 `json:"status"`
-	Environment string                 `json:"environment"`
-	Timestamp   int64                  `json:"timestamp"`
-	Database    DatabaseHealthResponse `json:"database"`
-	Cache       CacheHealthResponse    `json:"cache"`
-}
-
-type DatabaseHealthResponse struct {
-	Healthy bool                   `json:"healthy"`
-	Stats   map[string]interface{} `json:"stats"`
-}
-
-type CacheHealthResponse struct {
-	Healthy bool                   `json:"healthy"`
-	Stats   map[string]interface{} `json:"stats"`
+	Status      string `json:"status" enums:"ok,degraded,down"`
+	Environment string `json:"environment"`
+	Version     string `json:"version"`
+	Commit      string `json:"commit"`
+	Timestamp   int64  `json:"timestamp"`
+	// StartedAt is when this instance started serving
+	StartedAt     time.Time                   `json:"started_at"`
+	UptimeSeconds int64                       `json:"uptime_seconds"`
+	Components    map[string]health.Component `json:"components"`
 }
 
 // Check godoc
 // @Summary      Health Check
-// @Description  Check the health of the application (database and cache)
+// @Description  Checks every configured dependency concurrently. The status is down (503) when a required dependency (database, cache) fails, and degraded (200) when only an optional one (message broker, object storage, ML service) does. Results are reused for server.health_cache_ttl; admins can send fresh=true with a bearer token to bypass that.
 // @Tags         health
 // @Produce      json
+// @Param        fresh  query  bool  false  "Run the checks now (admin only)"
 // @Success      200  {object}  HealthResponse
 // @Failure      503  {object}  HealthResponse
 // @Router       /health [get]
 func (h *HealthHandler) Check(c *gin.Context) {
-	dbHealthy := true
-	if err := database.HealthCheck(h.db); err != nil {
-		dbHealthy = false
-	}
-
-	cacheHealthy := true
-	if err := h.cache.Ping(c.Request.Context()); err != nil {
-		cacheHealthy = false
-	}
+	// The route only lets admins through with fresh set
+	report := h.report(c.Request.Context(), c.Query("fresh") == "true")
 
-	status := "ok"
 	httpStatus := http.StatusOK
-	if !dbHealthy || !cacheHealthy {
-		status = "degraded"
+	if report.Status == health.StatusDown {
 		httpStatus = http.StatusServiceUnavailable
 	}
 
-	dbStats, _ := database.GetStats(h.db)
-
-	cacheStats, _ := h.cache.(*cache.RedisCache).GetStats(c.Request.Context())
-
+	build := version.Get()
 	c.JSON(httpStatus, HealthResponse{
-		Status:      status,
-		Environment: h.cfg.Server.Environment,
-		Timestamp:   time.Now().Unix(),
-		Database: DatabaseHealthResponse{
-			Healthy: dbHealthy,
-			Stats:   dbStats,
-		},
-		Cache: CacheHealthResponse{
-			Healthy: cacheHealthy,
-			Stats:   cacheStats,
-		},
+		Status:        report.Status,
+		Environment:   h.cfg.Server.Environment,
+		Version:       build.Version,
+		Commit:        build.Commit,
+		Timestamp:     time.Now().Unix(),
+		StartedAt:     version.StartTime().UTC(),
+		UptimeSeconds: int64(version.Uptime().Seconds()),
+		Components:    report.Components,
 	})
 }
 
+type ReadinessResponse struct {
+	Status string `json:"status" enums:"starting,ready,shutting_down,down"`
+	// Components lists the required dependencies that are failing
+	Components map[string]health.Component `json:"components,omitempty"`
+}
+
+type LivenessResponse struct {
+	Status string `json:"status" enums:"ok"`
+}
+
+// Live godoc
+// @Summary      Liveness
+// @Description  Confirms the process is serving requests. It checks no dependencies, so an outage of the database or Redis never gets a healthy instance restarted.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  LivenessResponse
+// @Router       /livez [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, LivenessResponse{Status: health.StatusOK})
+}
+
+// Ready godoc
+// @Summary      Readiness
+// @Description  Reports whether the instance should receive traffic. It is 503 until startup has confirmed the required dependencies and finished warming up, while a required dependency is down, and again from the moment shutdown begins.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  ReadinessResponse
+// @Failure      503  {object}  ReadinessResponse
+// @Router       /readyz [get]
+// @Router       /ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	state := h.readiness.State()
+	if state != health.StateReady {
+		c.JSON(http.StatusServiceUnavailable, ReadinessResponse{Status: state})
+		return
+	}
+
+	report := h.report(c.Request.Context(), false)
+	if report.Status == health.StatusDown {
+		failing := make(map[string]health.Component)
+		for name, component := range report.Components {
+			if component.Required && component.Status == health.StatusDown {
+				failing[name] = component
+			}
+		}
+		c.JSON(http.StatusServiceUnavailable, ReadinessResponse{Status: health.StatusDown, Components: failing})
+		return
+	}
+
+	c.JSON(http.StatusOK, ReadinessResponse{Status: state})
+}
+
+// Version godoc
+// @Summary      Build info
+// @Description  Returns the version, commit and build time of the running server
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  version.Info
+// @Router       /version [get]
+func (h *HealthHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get())
+}
+
 // Ping godoc
 // @Summary      Ping
 // @Description  Simple ping endpoint
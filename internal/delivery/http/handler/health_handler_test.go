@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	"github.com/Elysian-Rebirth/backend-go/internal/version"
+	"github.com/gin-gonic/gin"
+)
+
+func TestReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	up := health.CheckerFunc(func(context.Context) (map[string]any, error) { return nil, nil })
+	down := health.CheckerFunc(func(context.Context) (map[string]any, error) { return nil, errors.New("connection refused") })
+
+	tests := []struct {
+		name          string
+		mark          func(r *health.Readiness)
+		database      health.Checker
+		broker        health.Checker
+		wantStatus    int
+		wantState     string
+		wantComponent string
+	}{
+		{"starting", func(*health.Readiness) {}, up, up, http.StatusServiceUnavailable, health.StateStarting, ""},
+		{"ready", (*health.Readiness).MarkReady, up, up, http.StatusOK, health.StateReady, ""},
+		{"optional dependency down", (*health.Readiness).MarkReady, up, down, http.StatusOK, health.StateReady, ""},
+		{"required dependency down", (*health.Readiness).MarkReady, down, up, http.StatusServiceUnavailable, health.StatusDown, "database"},
+		{"shutting down", func(r *health.Readiness) {
+			r.MarkReady()
+			r.MarkShuttingDown()
+		}, up, up, http.StatusServiceUnavailable, health.StateShuttingDown, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := health.NewRegistry(time.Second)
+			checks.Register("database", true, tt.database)
+			checks.Register("broker", false, tt.broker)
+			readiness := health.NewReadiness()
+			tt.mark(readiness)
+			h := NewHealthHandler(&config.Config{}, checks, readiness)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+			h.Ready(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", got)
+			}
+			var body ReadinessResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Status != tt.wantState {
+				t.Errorf("status = %q, want %q", body.Status, tt.wantState)
+			}
+			if tt.wantComponent != "" {
+				if _, ok := body.Components[tt.wantComponent]; !ok || len(body.Components) != 1 {
+					t.Errorf("components = %v, want only %s", body.Components, tt.wantComponent)
+				}
+			}
+		})
+	}
+}
+
+// setBuild sets the ldflags-injected build variables for the duration of
+// the test.
+func setBuild(t *testing.T, v, commit, built string) {
+	t.Helper()
+	old := [3]string{version.Version, version.Commit, version.BuildTime}
+	version.Version, version.Commit, version.BuildTime = v, commit, built
+	t.Cleanup(func() { version.Version, version.Commit, version.BuildTime = old[0], old[1], old[2] })
+}
+
+func TestVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name                   string
+		version, commit, built string
+	}{
+		// Test binaries carry no VCS settings, so the defaults show through
+		{"without ldflags", "dev", "dev", "dev"},
+		{"injected", "v1.4.2", "9f2c1e7", "2026-10-17T08:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setBuild(t, tt.version, tt.commit, tt.built)
+
+			router := gin.New()
+			h := NewHealthHandler(&config.Config{}, health.NewRegistry(time.Second), health.NewReadiness())
+			router.GET("/version", h.Version)
+			router.GET("/health", h.Check)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET /version = %d, want 200", w.Code)
+			}
+			var info version.Info
+			if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+				t.Fatal(err)
+			}
+			want := version.Info{Version: tt.version, Commit: tt.commit, BuildTime: tt.built, GoVersion: runtime.Version()}
+			if info != want {
+				t.Errorf("GET /version = %+v, want %+v", info, want)
+			}
+
+			// The health report carries the same build
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+			var report HealthResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Version != tt.version || report.Commit != tt.commit {
+				t.Errorf("health version = %s (%s), want %s (%s)", report.Version, report.Commit, tt.version, tt.commit)
+			}
+		})
+	}
+}
+
+// countingChecks returns a registry whose database and cache checks take
+// delay and count their calls.
+func countingChecks(delay time.Duration) (*health.Registry, *atomic.Int64, *atomic.Int64) {
+	var database, cache atomic.Int64
+	checker := func(calls *atomic.Int64) health.Checker {
+		return health.CheckerFunc(func(context.Context) (map[string]any, error) {
+			calls.Add(1)
+			time.Sleep(delay)
+			return nil, nil
+		})
+	}
+	checks := health.NewRegistry(time.Second)
+	checks.Register("database", true, checker(&database))
+	checks.Register("cache", true, checker(&cache))
+	return checks, &database, &cache
+}
+
+func newHealthRouter(ttl time.Duration, checks *health.Registry) *gin.Engine {
+	cfg := &config.Config{}
+	cfg.Server.HealthCacheTTL = ttl
+	readiness := health.NewReadiness()
+	readiness.MarkReady()
+	h := NewHealthHandler(cfg, checks, readiness)
+
+	router := gin.New()
+	router.GET("/health", h.Check)
+	router.GET("/readyz", h.Ready)
+	return router
+}
+
+func getStatus(router *gin.Engine, path string) int {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func TestHealthBurst(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	checks, database, cache := countingChecks(50 * time.Millisecond)
+	router := newHealthRouter(time.Minute, checks)
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	var failed atomic.Int64
+	for i := range 50 {
+		path := "/health"
+		if i%2 == 1 {
+			path = "/readyz"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if getStatus(router, path) != http.StatusOK {
+				failed.Add(1)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if failed.Load() != 0 {
+		t.Fatalf("%d requests failed", failed.Load())
+	}
+	if database.Load() != 1 || cache.Load() != 1 {
+		t.Fatalf("50 requests ran the database check %d times and the cache check %d times, want once", database.Load(), cache.Load())
+	}
+
+	// Later requests within the TTL reuse the result; fresh runs the checks
+	getStatus(router, "/readyz")
+	if database.Load() != 1 {
+		t.Errorf("cached request ran the checks again: %d calls", database.Load())
+	}
+	getStatus(router, "/health?fresh=true")
+	if database.Load() != 2 || cache.Load() != 2 {
+		t.Errorf("fresh request: %d database and %d cache calls, want 2", database.Load(), cache.Load())
+	}
+}
+
+func TestHealthCacheTTL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		wait      time.Duration
+		wantCalls int64
+	}{
+		{"within the TTL", time.Minute, 0, 1},
+		{"expired", 20 * time.Millisecond, 30 * time.Millisecond, 3},
+		{"disabled", 0, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, database, _ := countingChecks(0)
+			router := newHealthRouter(tt.ttl, checks)
+
+			for range 3 {
+				getStatus(router, "/health")
+				time.Sleep(tt.wait)
+			}
+			if got := database.Load(); got != tt.wantCalls {
+				t.Errorf("database checked %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHealthUptime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	old := version.StartTime()
+	t.Cleanup(func() { version.SetStartTime(old) })
+	started := time.Now().Add(-10 * time.Second).Truncate(time.Second)
+	version.SetStartTime(started)
+	setBuild(t, "v1.4.2", "9f2c1e7", "2026-10-17T08:00:00Z")
+
+	checks, _, _ := countingChecks(0)
+	router := newHealthRouter(time.Minute, checks)
+	getHealth := func() HealthResponse {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var body HealthResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	first := getHealth()
+	if !first.StartedAt.Equal(started) || first.Version != "v1.4.2" || first.Commit != "9f2c1e7" {
+		t.Errorf("health = started %v, %s %s, want started %v, v1.4.2 9f2c1e7", first.StartedAt, first.Version, first.Commit, started)
+	}
+	if first.UptimeSeconds < 10 {
+		t.Errorf("uptime_seconds = %d, want at least 10", first.UptimeSeconds)
+	}
+
+	// Uptime is reported in seconds, and isn't part of the cached result
+	time.Sleep(time.Second)
+	if second := getHealth(); second.UptimeSeconds <= first.UptimeSeconds {
+		t.Errorf("uptime_seconds went from %d to %d, want it to increase", first.UptimeSeconds, second.UptimeSeconds)
+	}
+}
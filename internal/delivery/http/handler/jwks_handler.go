@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	jwtService *auth.JWTService
+}
+
+func NewJWKSHandler(jwtService *auth.JWTService) *JWKSHandler {
+	return &JWKSHandler{
+		jwtService: jwtService,
+	}
+}
+
+// JWKS godoc
+// @Summary      Get token signing keys
+// @Description  Publishes the public keys access tokens are signed with, in JWKS format, so other services can verify tokens themselves. Keys retired by a rotation stay listed while tokens signed with them can still be valid; pick the key by the token's kid. The set is empty while tokens are signed with a shared secret. Responses may be cached for 15 minutes.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  auth.JWKSet
+// @Failure      500  {object}  ErrorResponse
+// @Router       /.well-known/jwks.json [get]
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	set, err := h.jwtService.JWKS()
+	if err != nil {
+		log.Printf("Failed to build JWKS: %v", err)
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to load signing keys"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=900")
+	c.JSON(http.StatusOK, set)
+}
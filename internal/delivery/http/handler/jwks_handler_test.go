@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	"github.com/gin-gonic/gin"
+)
+
+func TestJWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtSvc, err := auth.NewJWTService(config.JWTConfig{
+		Secret:            "test-secret-that-is-long-enough-to-sign",
+		AccessTokenExpiry: 15 * time.Minute,
+	}, clock.NewMock(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewJWKSHandler(jwtSvc)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+	h.JWKS(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=900" {
+		t.Errorf("Cache-Control = %q, want public, max-age=900", got)
+	}
+	// Shared secret tokens publish an empty set rather than null
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(body["keys"]); got != "[]" {
+		t.Errorf("keys = %s, want []", got)
+	}
+}
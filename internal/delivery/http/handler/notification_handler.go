@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/notify"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultHeartbeat = 25 * time.Second
+
+type NotificationHandler struct {
+	notifications *notify.Service
+	heartbeat     time.Duration
+}
+
+func NewNotificationHandler(notifications *notify.Service, heartbeat time.Duration) *NotificationHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+
+	return &NotificationHandler{
+		notifications: notifications,
+		heartbeat:     heartbeat,
+	}
+}
+
+// Stream godoc
+// @Summary      Stream notifications
+// @Description  Streams the current user's notifications as server-sent events. Each event is named after its type and carries the notification as JSON, with the notification ID as the event ID. Recent notifications newer than Last-Event-ID are sent first; without the header every buffered notification is. Idle streams get a comment every 25 seconds to keep proxies from closing them. EventSource clients, which can't set headers, may pass the access token as the token query parameter instead.
+// @Tags         notifications
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        token          query   string  false  "Access token, for clients that can't send the Authorization header"
+// @Param        Last-Event-ID  header  string  false  "ID of the last notification received"
+// @Success      200  {object}  notify.Event
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/notifications/stream [get]
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+	ctx := c.Request.Context()
+
+	stream, err := h.notifications.Subscribe(ctx, user.ID, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		log.Printf("Failed to open notification stream for user %s: %v", user.ID, err)
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to open notification stream"})
+		return
+	}
+	defer stream.Close()
+
+	startEventStream(c)
+	for _, event := range stream.Replay {
+		if err := writeNotification(c, event); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-stream.Events():
+			if !ok {
+				// The subscription dropped; the client reconnects with
+				// Last-Event-ID and picks up what it missed
+				return
+			}
+			if err := writeNotification(c, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeNotification writes event with its ID, so a reconnecting EventSource
+// sends it back as Last-Event-ID.
+func writeNotification(c *gin.Context, event notify.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+	if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
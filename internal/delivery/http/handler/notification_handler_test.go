@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/notify"
+	"github.com/gin-gonic/gin"
+)
+
+// newStreamServer serves the notification stream for whichever user the
+// X-User header names.
+func newStreamServer(t *testing.T, heartbeat time.Duration) (*notify.Service, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+	notifications := notify.New(c, cache.NewCacheKeyBuilder("test"), config.NotificationsConfig{ReplaySize: 10}, clock.New())
+	h := NewNotificationHandler(notifications, heartbeat)
+
+	router := gin.New()
+	router.GET("/stream", func(c *gin.Context) {
+		c.Set("user", &domain.User{ID: c.GetHeader("X-User")})
+	}, h.Stream)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	return notifications, srv.Listener.Addr().String()
+}
+
+// openStream connects as userID and reads up to the end of the response
+// headers.
+func openStream(t *testing.T, addr, userID, lastEventID string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(conn, "GET /stream HTTP/1.1\r\nHost: test\r\nX-User: %s\r\nLast-Event-ID: %s\r\n\r\n", userID, lastEventID)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream = %d %s, want an event stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return conn, r
+}
+
+// readEvent reads the next SSE frame, skipping chunk sizes.
+func readEvent(t *testing.T, conn net.Conn, r *bufio.Reader) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var frame []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "" && len(frame) > 0:
+			return strings.Join(frame, "\n")
+		case strings.HasPrefix(line, "id:"), strings.HasPrefix(line, "event:"), strings.HasPrefix(line, "data:"), strings.HasPrefix(line, ":"):
+			frame = append(frame, line)
+		}
+	}
+}
+
+func TestNotificationStream(t *testing.T) {
+	notifications, addr := newStreamServer(t, 50*time.Millisecond)
+	ctx := context.Background()
+
+	if err := notifications.Notify(ctx, "user-1", notify.Event{Type: "import.completed"}); err != nil {
+		t.Fatal(err)
+	}
+	conn, r := openStream(t, addr, "user-1", "")
+	defer conn.Close()
+
+	if got := readEvent(t, conn, r); !strings.HasPrefix(got, "id: 1\nevent: import.completed\ndata: {") {
+		t.Errorf("replayed %q, want the buffered event", got)
+	}
+
+	if err := notifications.Notify(ctx, "user-1", notify.Event{Type: "ai.generated"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readEvent(t, conn, r); !strings.HasPrefix(got, "id: 2\nevent: ai.generated\n") {
+		t.Errorf("live event %q, want ai.generated", got)
+	}
+
+	// Idle streams get heartbeats
+	if got := readEvent(t, conn, r); got != ": heartbeat" {
+		t.Errorf("idle stream got %q, want a heartbeat", got)
+	}
+}
+
+func TestNotificationStreamDisconnects(t *testing.T) {
+	notifications, addr := newStreamServer(t, time.Hour)
+	ctx := context.Background()
+	openStreams := expvar.Get("notification_streams").(*expvar.Int)
+
+	// Let the server's own goroutines start before measuring
+	conn, _ := openStream(t, addr, "warmup", "")
+	conn.Close()
+	waitForStreams(t, openStreams, 0)
+	baseline := runtime.NumGoroutine()
+
+	const clients = 50
+	conns := make([]net.Conn, clients)
+	for i := range conns {
+		conns[i], _ = openStream(t, addr, fmt.Sprintf("user-%d", i%5), "")
+	}
+	waitForStreams(t, openStreams, clients)
+
+	// Events queued for streams whose clients vanish mid-delivery
+	for i := range 5 {
+		notifications.Notify(ctx, fmt.Sprintf("user-%d", i), notify.Event{Type: "import.completed"})
+	}
+	for _, conn := range conns {
+		conn.(*net.TCPConn).SetLinger(0)
+		conn.Close()
+	}
+
+	waitForStreams(t, openStreams, 0)
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("%d goroutines, want at most %d:\n%s", runtime.NumGoroutine(), baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitForStreams waits until want notification streams are open.
+func waitForStreams(t *testing.T, open *expvar.Int, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for open.Value() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d streams open, want %d", open.Value(), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setPaginationLinks sets an RFC 5988 Link header with the first, prev, next
+// and last pages of a limit/offset list, built from the request URL so other
+// query parameters are kept. prev is left out on the first page and next on
+// the last.
+func setPaginationLinks(c *gin.Context, meta Meta) {
+	if meta.Limit <= 0 {
+		return
+	}
+
+	var last int64
+	if meta.Total > 0 {
+		last = (meta.Total - 1) / int64(meta.Limit) * int64(meta.Limit)
+	}
+
+	links := []string{pageLink(c, "first", 0, meta.Limit)}
+	if meta.Offset > 0 {
+		prev := max(meta.Offset-meta.Limit, 0)
+		links = append(links, pageLink(c, "prev", int64(prev), meta.Limit))
+	}
+	if int64(meta.Offset+meta.Limit) < meta.Total {
+		links = append(links, pageLink(c, "next", int64(meta.Offset+meta.Limit), meta.Limit))
+	}
+	links = append(links, pageLink(c, "last", last, meta.Limit))
+
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+func pageLink(c *gin.Context, rel string, offset int64, limit int) string {
+	u := *c.Request.URL
+	query := u.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.FormatInt(offset, 10))
+	u.RawQuery = query.Encode()
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetPaginationLinks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		target string
+		meta   Meta
+		want   string
+	}{
+		{
+			name:   "first page",
+			target: "/api/v1/users?limit=10",
+			meta:   Meta{Total: 25, Limit: 10, Offset: 0},
+			want: `</api/v1/users?limit=10&offset=0>; rel="first", ` +
+				`</api/v1/users?limit=10&offset=10>; rel="next", ` +
+				`</api/v1/users?limit=10&offset=20>; rel="last"`,
+		},
+		{
+			name:   "middle page",
+			target: "/api/v1/users?limit=10&offset=10",
+			meta:   Meta{Total: 25, Limit: 10, Offset: 10},
+			want: `</api/v1/users?limit=10&offset=0>; rel="first", ` +
+				`</api/v1/users?limit=10&offset=0>; rel="prev", ` +
+				`</api/v1/users?limit=10&offset=20>; rel="next", ` +
+				`</api/v1/users?limit=10&offset=20>; rel="last"`,
+		},
+		{
+			name:   "last page",
+			target: "/api/v1/users?limit=10&offset=20",
+			meta:   Meta{Total: 25, Limit: 10, Offset: 20},
+			want: `</api/v1/users?limit=10&offset=0>; rel="first", ` +
+				`</api/v1/users?limit=10&offset=10>; rel="prev", ` +
+				`</api/v1/users?limit=10&offset=20>; rel="last"`,
+		},
+		{
+			name:   "offset between pages",
+			target: "/api/v1/users?limit=10&offset=5",
+			meta:   Meta{Total: 25, Limit: 10, Offset: 5},
+			want: `</api/v1/users?limit=10&offset=0>; rel="first", ` +
+				`</api/v1/users?limit=10&offset=0>; rel="prev", ` +
+				`</api/v1/users?limit=10&offset=15>; rel="next", ` +
+				`</api/v1/users?limit=10&offset=20>; rel="last"`,
+		},
+		{
+			name:   "total a multiple of the limit",
+			target: "/api/v1/users",
+			meta:   Meta{Total: 20, Limit: 10, Offset: 0},
+			want: `</api/v1/users?limit=10&offset=0>; rel="first", ` +
+				`</api/v1/users?limit=10&offset=10>; rel="next", ` +
+				`</api/v1/users?limit=10&offset=10>; rel="last"`,
+		},
+		{
+			name:   "empty list",
+			target: "/api/v1/users",
+			meta:   Meta{Total: 0, Limit: 10, Offset: 0},
+			want: `</api/v1/users?limit=10&offset=0>; rel="first", ` +
+				`</api/v1/users?limit=10&offset=0>; rel="last"`,
+		},
+		{
+			name:   "other query parameters are kept",
+			target: "/api/v1/users?include_roles=true&limit=10&offset=10",
+			meta:   Meta{Total: 15, Limit: 10, Offset: 10},
+			want: `</api/v1/users?include_roles=true&limit=10&offset=0>; rel="first", ` +
+				`</api/v1/users?include_roles=true&limit=10&offset=0>; rel="prev", ` +
+				`</api/v1/users?include_roles=true&limit=10&offset=10>; rel="last"`,
+		},
+		{
+			name:   "no limit",
+			target: "/api/v1/users",
+			meta:   Meta{Total: 25},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, tt.target, nil)
+
+			setPaginationLinks(c, tt.meta)
+
+			if got := w.Header().Get("Link"); got != tt.want {
+				t.Errorf("Link =\n  %s\nwant\n  %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/ai"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	"github.com/Elysian-Rebirth/backend-go/internal/version"
+)
+
+// ResponseTypes lists a value of every type the handlers serialize as a
+// response body, for the server.json_naming check. Add new response types
+// here.
+func ResponseTypes() []any {
+	return []any{
+		apierror.Response{},
+		// Domain models returned as they are
+		domain.User{},
+		domain.Business{},
+		domain.Webhook{},
+		domain.WebhookDelivery{},
+		version.Info{},
+		auth.JWKSet{},
+		ai.ChatResult{},
+		ai.GenerationResult{},
+		ChatDelta{},
+
+		SuccessResponse{},
+		PingResponse{},
+		HealthResponse{},
+		ReadinessResponse{},
+		LivenessResponse{},
+		AuthResponse{},
+		UserResponse{},
+		UserListResponse{},
+		UpdateUserResponse{},
+		RevokeSessionsResponse{},
+		BulkUserResponse{},
+		UsageResponse{},
+		AvatarResponse{},
+		FileResponse{},
+		BusinessListResponse{},
+		CreateWebhookResponse{},
+		WebhookListResponse{},
+		WebhookDeliveryListResponse{},
+		FeatureListResponse{},
+		FailedEmailListResponse{},
+		JobListResponse{},
+		StatsResponse{},
+		RuntimeResponse{},
+		RoleMatrixResponse{},
+		CacheKeyListResponse{},
+		DeleteCacheKeysResponse{},
+	}
+}
@@ -0,0 +1,11 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/jsonnaming"
+)
+
+func TestResponseTypesSnakeCase(t *testing.T) {
+	jsonnaming.AssertSnakeCase(t, ResponseTypes()...)
+}
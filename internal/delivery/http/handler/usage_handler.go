@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	"github.com/gin-gonic/gin"
+)
+
+type UsageHandler struct {
+	usageUseCase  usage.UsageUseCase
+	userRepo      repository.UserRepository
+	monthlyQuotas map[string]config.QuotaConfig
+}
+
+func NewUsageHandler(uc usage.UsageUseCase, userRepo repository.UserRepository, monthlyQuotas map[string]config.QuotaConfig) *UsageHandler {
+	return &UsageHandler{
+		usageUseCase:  uc,
+		userRepo:      userRepo,
+		monthlyQuotas: monthlyQuotas,
+	}
+}
+
+type UsageResponse struct {
+	Data []usage.Usage `json:"data"`
+}
+
+// GetMyUsage godoc
+// @Summary      Get current user's usage
+// @Description  Returns the current user's daily and monthly usage counts per feature, with the user's monthly quotas (ai_calls, uploads, ...). Daily counts reset at midnight UTC, monthly counts on the first of the month.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  UsageResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/users/me/usage [get]
+func (h *UsageHandler) GetMyUsage(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	var roles []string
+	if userRoles, ok := middleware.GetUserRolesFromContext(c); ok {
+		for _, role := range userRoles {
+			roles = append(roles, role.Name)
+		}
+	}
+
+	h.writeUsage(c, user.ID, roles)
+}
+
+// GetUserUsage godoc
+// @Summary      Get a user's usage
+// @Description  Admin variant of /users/me/usage for any user.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  UsageResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/users/{id}/usage [get]
+func (h *UsageHandler) GetUserUsage(c *gin.Context) {
+	user, err := h.userRepo.FindByIDWithRoles(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "User not found", Code: apierror.CodeUserNotFound})
+		return
+	}
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to fetch user")
+		return
+	}
+
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+
+	h.writeUsage(c, user.ID, roles)
+}
+
+func (h *UsageHandler) writeUsage(c *gin.Context, userID string, roles []string) {
+	limits := make(map[string]int64, len(h.monthlyQuotas))
+	for feature, quota := range h.monthlyQuotas {
+		limits[feature] = quota.LimitFor(roles)
+	}
+
+	counts, err := h.usageUseCase.GetUsage(c.Request.Context(), userID, limits)
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to fetch usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, UsageResponse{Data: counts})
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetMyUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+	uc := usage.NewUsageUseCase(c, cache.NewCacheKeyBuilder("test"), nil, clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+	h := NewUsageHandler(uc, newTestUserRepo(t, 0), map[string]config.QuotaConfig{
+		domain.UsageAICalls: {Default: 10, Roles: map[string]int64{"paid": 100}},
+	})
+
+	for range 3 {
+		if _, err := uc.IncrUsage(context.Background(), "user-1", domain.UsageAICalls); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		userID        string
+		roles         []*domain.Role
+		wantMonthly   int64
+		wantLimit     int64
+		wantRemaining int64
+	}{
+		{"free tier", "user-1", nil, 3, 10, 7},
+		{"paid tier", "user-1", []*domain.Role{{Name: "paid"}}, 3, 100, 97},
+		{"unused", "user-2", nil, 0, 10, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/me/usage", nil)
+			ctx.Set("user", &domain.User{ID: tt.userID})
+			ctx.Set("user_roles", tt.roles)
+
+			h.GetMyUsage(ctx)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+			}
+			var body UsageResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			var got *usage.Usage
+			for i := range body.Data {
+				if body.Data[i].Feature == domain.UsageAICalls {
+					got = &body.Data[i]
+				}
+			}
+			if got == nil {
+				t.Fatalf("usage = %+v, want an %s entry", body.Data, domain.UsageAICalls)
+			}
+			if got.Monthly != tt.wantMonthly || got.MonthlyLimit != tt.wantLimit ||
+				got.MonthlyRemaining == nil || *got.MonthlyRemaining != tt.wantRemaining {
+				t.Errorf("usage = %+v, want %d used of %d with %d remaining", got, tt.wantMonthly, tt.wantLimit, tt.wantRemaining)
+			}
+		})
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/users/missing/usage", nil)
+		ctx.Params = gin.Params{{Key: "id", Value: "00000000-0000-4000-8000-000000000000"}}
+
+		h.GetUserUsage(ctx)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", w.Code)
+		}
+	})
+}
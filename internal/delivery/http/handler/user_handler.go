@@ -1,23 +1,32 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
 	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	userUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/user"
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	userUseCase userUseCase.UserUseCase
+	names       *auth.NameSanitizer
 }
 
-func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
+func NewUserHandler(userRepo repository.UserRepository, uc userUseCase.UserUseCase, names *auth.NameSanitizer) *UserHandler {
 	return &UserHandler{
-		userRepo: userRepo,
+		userRepo:    userRepo,
+		userUseCase: uc,
+		names:       names,
 	}
 }
 
@@ -36,6 +45,31 @@ type UserResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type ChangeEmailRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type ListUsersQuery struct {
+	Limit        int  `form:"limit,default=10" binding:"min=1,max=100"`
+	Offset       int  `form:"offset,default=0" binding:"min=0"`
+	IncludeRoles bool `form:"include_roles"`
+}
+
+func (q ListUsersQuery) pageOffset() int { return q.Offset }
+
 type UserListResponse struct {
 	Data []*domain.User `json:"data"`
 	Meta Meta           `json:"meta"`
@@ -52,6 +86,35 @@ type UpdateUserResponse struct {
 	User    UserResponse `json:"user"`
 }
 
+type BulkUserRequest struct {
+	IDs    []string `json:"ids" binding:"required,min=1,max=100"`
+	Action string   `json:"action" binding:"omitempty,oneof=delete deactivate" enums:"delete,deactivate" default:"delete"`
+	Atomic bool     `json:"atomic"`
+}
+
+type RevokeSessionsRequest struct {
+	RevokeAccessTokens bool `json:"revoke_access_tokens"`
+}
+
+type RevokeSessionsResponse struct {
+	Message             string `json:"message"`
+	RevokedSessions     int    `json:"revoked_sessions"`
+	AccessTokensRevoked bool   `json:"access_tokens_revoked"`
+}
+
+type BulkUserFailure struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type BulkUserResponse struct {
+	Message      string            `json:"message"`
+	Processed    int               `json:"processed"`
+	ProcessedIDs []string          `json:"processed_ids"`
+	FailedCount  int               `json:"failed_count"`
+	Failed       []BulkUserFailure `json:"failed"`
+}
+
 // GetByID godoc
 // @Summary      Get user by ID
 // @Description  Get user details by ID
@@ -65,8 +128,12 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
 	user, err := h.userRepo.FindByID(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "User not found", Code: apierror.CodeUserNotFound})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		apierror.WriteFailure(c, err, "Failed to fetch user")
 		return
 	}
 
@@ -78,41 +145,68 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 // @Description  Get list of users
 // @Tags         users
 // @Produce      json
-// @Param        limit   query     int     false  "Limit"
-// @Param        offset  query     int     false  "Offset"
+// @Param        limit   query     int     false  "Limit (1-100)"  default(10)
+// @Param        offset  query     int     false  "Offset"         default(0)
+// @Param        include_roles  query  bool  false  "Include each user's roles"
 // @Success      200     {object}  UserListResponse
+// @Header       200     {string}  Link  "RFC 5988 links to the first, prev, next and last pages"
+// @Failure      400     {object}  ErrorResponse
 // @Failure      500     {object}  ErrorResponse
 // @Router       /api/v1/users [get]
 func (h *UserHandler) List(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 || limit > 100 {
-		limit = 10
+	var query ListUsersQuery
+	if !bindQuery(c, &query) {
+		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+	list := h.userRepo.List
+	if query.IncludeRoles {
+		list = h.userRepo.ListWithRoles
 	}
 
-	users, total, err := h.userRepo.List(c.Request.Context(), limit, offset)
+	users, total, err := list(c.Request.Context(), query.Limit, query.Offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch users"})
+		apierror.WriteFailure(c, err, "Failed to fetch users")
 		return
 	}
 
+	meta := Meta{
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	setPaginationLinks(c, meta)
 	c.JSON(http.StatusOK, UserListResponse{
 		Data: users,
-		Meta: Meta{
-			Total:  total,
-			Limit:  limit,
-			Offset: offset,
-		},
+		Meta: meta,
 	})
 }
 
+// GetWithRoles godoc
+// @Summary      Get user with roles
+// @Description  Get user details including assigned roles (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  domain.User
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/users/{id} [get]
+func (h *UserHandler) GetWithRoles(c *gin.Context) {
+	user, err := h.userRepo.FindByIDWithRoles(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "User not found", Code: apierror.CodeUserNotFound})
+		return
+	}
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to fetch user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
 // GetByEmail godoc
 // @Summary      Get user by email
 // @Description  Get user details by email
@@ -126,8 +220,12 @@ func (h *UserHandler) GetByEmail(c *gin.Context) {
 	email := c.Param("email")
 
 	user, err := h.userRepo.FindByEmail(c.Request.Context(), email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "User not found", Code: apierror.CodeUserNotFound})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		apierror.WriteFailure(c, err, "Failed to fetch user")
 		return
 	}
 
@@ -173,19 +271,26 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 	var req UpdateUserRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
 		return
 	}
 
 	if req.Name != "" {
-		user.Name = req.Name
+		name, err := h.names.Sanitize(req.Name)
+		if err != nil {
+			apierror.WriteError(c, err)
+			return
+		}
+		user.Name = name
 	}
 	if req.AvatarURL != nil {
 		user.AvatarURL = req.AvatarURL
+		// The uploaded avatar, if any, is left to the orphan cleanup
+		user.AvatarKey = nil
 	}
 
 	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update profile"})
+		apierror.WriteFailure(c, err, "Failed to update profile")
 		return
 	}
 
@@ -200,6 +305,227 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 	})
 }
 
+// ChangeEmail godoc
+// @Summary      Change current user's email
+// @Description  Sends a confirmation link to the new address. The current email stays active until the link is used.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body ChangeEmailRequest true "Change Email Request"
+// @Success      202  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      429  {object}  ErrorResponse  "email_throttled"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/users/me/email [put]
+func (h *UserHandler) ChangeEmail(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+
+	err := h.userUseCase.RequestEmailChange(c.Request.Context(), userUseCase.EmailChangeRequest{
+		UserID:    user.ID,
+		NewEmail:  req.Email,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, userUseCase.ErrEmailChangeDisabled):
+		apierror.Write(c, http.StatusForbidden, ErrorResponse{Message: "Email change is disabled", Code: apierror.CodeEmailChangeDisabled})
+		return
+	case errors.Is(err, auth.ErrInvalidEmail):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid email format", Code: apierror.CodeValidationFailed})
+		return
+	case errors.Is(err, userUseCase.ErrEmailUnchanged):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "New email is the same as the current one", Code: apierror.CodeEmailUnchanged})
+		return
+	case errors.Is(err, auth.ErrEmailThrottled):
+		writeEmailThrottled(c, err)
+		return
+	case errors.Is(err, userUseCase.ErrEmailTaken):
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "Email already registered", Code: apierror.CodeEmailTaken})
+		return
+	default:
+		apierror.WriteFailure(c, err, "Failed to request email change")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: "Confirmation link sent to the new email address",
+	})
+}
+
+// ConfirmEmailChange godoc
+// @Summary      Confirm an email change
+// @Description  Applies a pending email change using the token from the confirmation link and marks the new address as verified
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request body ConfirmEmailChangeRequest true "Confirm Request"
+// @Success      200  {object}  UpdateUserResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/users/email/confirm [post]
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+
+	user, err := h.userUseCase.ConfirmEmailChange(c.Request.Context(), userUseCase.ConfirmEmailChangeRequest{
+		Token:     req.Token,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, userUseCase.ErrEmailChangeDisabled):
+		apierror.Write(c, http.StatusForbidden, ErrorResponse{Message: "Email change is disabled", Code: apierror.CodeEmailChangeDisabled})
+		return
+	case errors.Is(err, auth.ErrInvalidVerificationToken), errors.Is(err, repository.ErrUserNotFound):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid or expired confirmation token", Code: apierror.CodeInvalidEmailToken})
+		return
+	case errors.Is(err, userUseCase.ErrEmailTaken):
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "Email already registered", Code: apierror.CodeEmailTaken})
+		return
+	default:
+		apierror.WriteFailure(c, err, "Failed to confirm email change")
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateUserResponse{
+		Message: "Email changed successfully",
+		User: UserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			Name:      user.Name,
+			AvatarURL: user.AvatarURL,
+			IsActive:  user.IsActive,
+			CreatedAt: user.CreatedAt,
+		},
+	})
+}
+
+// writeEmailThrottled answers 429 for an auth.EmailThrottledError, with
+// Retry-After set to when the next email to the address is allowed.
+func writeEmailThrottled(c *gin.Context, err error) {
+	resp := ErrorResponse{Message: "Too many emails sent to this address, try again later", Code: apierror.CodeEmailThrottled}
+	var throttled *auth.EmailThrottledError
+	if errors.As(err, &throttled) {
+		retryAfter := int(throttled.RetryAfter.Seconds()) + 1
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		resp.Details = []string{fmt.Sprintf("The next email can be sent in %d seconds", retryAfter)}
+	}
+	apierror.Write(c, http.StatusTooManyRequests, resp)
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Emails a password reset link if an active account uses the address. The response is the same either way.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body ForgotPasswordRequest true "Forgot Password Request"
+// @Success      202  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse  "password_reset_disabled"
+// @Failure      429  {object}  ErrorResponse  "email_throttled"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/auth/password/forgot [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+
+	err := h.userUseCase.RequestPasswordReset(c.Request.Context(), userUseCase.PasswordResetRequest{
+		Email:     req.Email,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, userUseCase.ErrPasswordResetDisabled):
+		apierror.Write(c, http.StatusForbidden, ErrorResponse{Message: "Password reset is disabled", Code: apierror.CodeResetDisabled})
+		return
+	case errors.Is(err, auth.ErrEmailThrottled):
+		writeEmailThrottled(c, err)
+		return
+	case errors.Is(err, auth.ErrInvalidEmail):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid email format", Code: apierror.CodeValidationFailed})
+		return
+	default:
+		apierror.WriteFailure(c, err, "Failed to request password reset")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Message: "If the address belongs to an account, a reset link is on its way",
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset a forgotten password
+// @Description  Sets a new password using the token from the reset link and signs out every session. Each link works once.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body ResetPasswordRequest true "Reset Password Request"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse  "invalid_verification_token"
+// @Failure      403  {object}  ErrorResponse  "password_reset_disabled"
+// @Failure      409  {object}  ErrorResponse  "verification_token_used"
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/auth/password/reset [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+
+	err := h.userUseCase.ResetPassword(c.Request.Context(), userUseCase.ResetPasswordRequest{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+		IPAddress:   c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+	})
+	var validation *domain.ValidationError
+	switch {
+	case err == nil:
+	case errors.Is(err, userUseCase.ErrPasswordResetDisabled):
+		apierror.Write(c, http.StatusForbidden, ErrorResponse{Message: "Password reset is disabled", Code: apierror.CodeResetDisabled})
+		return
+	case errors.As(err, &validation):
+		apierror.WriteError(c, err)
+		return
+	case errors.Is(err, auth.ErrVerificationTokenUsed):
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "Reset link has already been used", Code: apierror.CodeTokenAlreadyUsed})
+		return
+	case errors.Is(err, auth.ErrInvalidVerificationToken):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid or expired reset token", Code: apierror.CodeInvalidEmailToken})
+		return
+	default:
+		apierror.WriteFailure(c, err, "Failed to reset password")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Message: "Password reset successfully, please log in again",
+	})
+}
+
 // DeleteMe godoc
 // @Summary      Delete current user
 // @Description  Delete currently logged in user account
@@ -212,8 +538,13 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 func (h *UserHandler) DeleteMe(c *gin.Context) {
 	user := middleware.MustGetUserFromContext(c)
 
-	if err := h.userRepo.Delete(c.Request.Context(), user.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete account"})
+	err := h.userUseCase.DeleteAccount(c.Request.Context(), userUseCase.DeleteAccountRequest{
+		UserID:    user.ID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to delete account")
 		return
 	}
 
@@ -221,3 +552,111 @@ func (h *UserHandler) DeleteMe(c *gin.Context) {
 		Message: "Account deleted successfully",
 	})
 }
+
+// BulkDelete godoc
+// @Summary      Bulk delete or deactivate users
+// @Description  Soft-delete or deactivate up to 100 users at once. With atomic set, nothing is changed unless every ID can be processed.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body BulkUserRequest true "Bulk Request"
+// @Success      200  {object}  BulkUserResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      409  {object}  BulkUserResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/users/bulk-delete [post]
+func (h *UserHandler) BulkDelete(c *gin.Context) {
+	actor := middleware.MustGetUserFromContext(c)
+
+	var req BulkUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+	if req.Action == "" {
+		req.Action = userUseCase.BulkActionDelete
+	}
+
+	res, err := h.userUseCase.Bulk(c.Request.Context(), userUseCase.BulkRequest{
+		ActorID:   actor.ID,
+		Action:    req.Action,
+		IDs:       req.IDs,
+		Atomic:    req.Atomic,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to process users")
+		return
+	}
+
+	failed := make([]BulkUserFailure, 0, len(res.Failed))
+	for _, f := range res.Failed {
+		failed = append(failed, BulkUserFailure{ID: f.ID, Reason: f.Reason})
+	}
+
+	resp := BulkUserResponse{
+		Message:      "Users processed successfully",
+		Processed:    len(res.Processed),
+		ProcessedIDs: res.Processed,
+		FailedCount:  len(failed),
+		Failed:       failed,
+	}
+
+	if res.Aborted {
+		resp.Message = "Atomic batch rejected, no users were modified"
+		c.JSON(http.StatusConflict, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeSessions godoc
+// @Summary      Revoke a user's sessions
+// @Description  Revokes every refresh token of the user. With revoke_access_tokens set, access tokens issued so far are rejected as well instead of running until expiry.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "User ID"
+// @Param        request body RevokeSessionsRequest false "Revoke Request"
+// @Success      200  {object}  RevokeSessionsResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/users/{id}/revoke-sessions [post]
+func (h *UserHandler) RevokeSessions(c *gin.Context) {
+	actor := middleware.MustGetUserFromContext(c)
+
+	var req RevokeSessionsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+			return
+		}
+	}
+
+	res, err := h.userUseCase.RevokeSessions(c.Request.Context(), userUseCase.RevokeSessionsRequest{
+		ActorID:            actor.ID,
+		UserID:             c.Param("id"),
+		RevokeAccessTokens: req.RevokeAccessTokens,
+		IPAddress:          c.ClientIP(),
+		UserAgent:          c.Request.UserAgent(),
+	})
+	if errors.Is(err, repository.ErrUserNotFound) {
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "User not found", Code: apierror.CodeUserNotFound})
+		return
+	}
+	if err != nil {
+		apierror.WriteFailure(c, err, "Failed to revoke sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeSessionsResponse{
+		Message:             "Sessions revoked successfully",
+		RevokedSessions:     res.RevokedSessions,
+		AccessTokensRevoked: res.AccessTokensRevoked,
+	})
+}
@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/memory"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	userUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/user"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestUserRepo returns an in-memory user repository holding n users.
+func newTestUserRepo(t *testing.T, n int) repository.UserRepository {
+	t.Helper()
+	repo := memory.NewUserRepository(memory.NewStore())
+	for i := range n {
+		user := &domain.User{Email: fmt.Sprintf("user%d@example.com", i), Name: "User", PasswordHash: "hash", IsActive: true}
+		if err := repo.Create(context.Background(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func TestListUsersQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewUserHandler(newTestUserRepo(t, 3), nil, nil)
+
+	tests := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantMeta    Meta
+		wantDetails []string
+	}{
+		{"defaults", "", http.StatusOK, Meta{Total: 3, Limit: 10, Offset: 0}, nil},
+		{"explicit page", "?limit=2&offset=1&include_roles=true", http.StatusOK, Meta{Total: 3, Limit: 2, Offset: 1}, nil},
+		{"limit too small", "?limit=0", http.StatusBadRequest, Meta{}, []string{"limit must be at least 1"}},
+		{"limit too large", "?limit=101", http.StatusBadRequest, Meta{}, []string{"limit must be at most 100"}},
+		{"negative offset", "?offset=-1", http.StatusBadRequest, Meta{}, []string{"offset must be at least 0"}},
+		{"both invalid", "?limit=500&offset=-5", http.StatusBadRequest, Meta{}, []string{"limit must be at most 100", "offset must be at least 0"}},
+		{"offset too deep", "?offset=10001", http.StatusBadRequest, Meta{}, []string{"offset must be at most 10000; narrow the query instead of paging this deep"}},
+		{"not a number", "?limit=ten", http.StatusBadRequest, Meta{}, nil},
+		{"not a bool", "?include_roles=maybe", http.StatusBadRequest, Meta{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users"+tt.query, nil)
+
+			h.List(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var body UserListResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				if body.Meta != tt.wantMeta {
+					t.Errorf("meta = %+v, want %+v", body.Meta, tt.wantMeta)
+				}
+				if want := min(tt.wantMeta.Limit, int(tt.wantMeta.Total)-tt.wantMeta.Offset); len(body.Data) != want {
+					t.Errorf("got %d users, want %d", len(body.Data), want)
+				}
+				if w.Header().Get("Link") == "" {
+					t.Error("list without a Link header")
+				}
+				return
+			}
+
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != apierror.CodeInvalidRequest {
+				t.Errorf("code = %q, want %q", body.Code, apierror.CodeInvalidRequest)
+			}
+			if len(body.Details) == 0 {
+				t.Fatal("400 without details")
+			}
+			if tt.wantDetails != nil && fmt.Sprint(body.Details) != fmt.Sprint(tt.wantDetails) {
+				t.Errorf("details = %q, want %q", body.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestGetUserNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewUserHandler(newTestUserRepo(t, 0), nil, nil)
+
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		param   string
+	}{
+		{"by id", h.GetByID, "id"},
+		{"with roles", h.GetWithRoles, "id"},
+		{"by email", h.GetByEmail, "email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/missing", nil)
+			c.Params = gin.Params{{Key: tt.param, Value: "missing@example.com"}}
+
+			tt.handler(c)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusNotFound, w.Body)
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != apierror.CodeUserNotFound {
+				t.Errorf("code = %q, want %q", body.Code, apierror.CodeUserNotFound)
+			}
+		})
+	}
+}
+
+// emailChangeUseCase answers RequestEmailChange with err.
+type emailChangeUseCase struct {
+	userUseCase.UserUseCase
+	err error
+}
+
+func (u emailChangeUseCase) RequestEmailChange(context.Context, userUseCase.EmailChangeRequest) error {
+	return u.err
+}
+
+func TestChangeEmailErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+		wantCode   apierror.Code
+	}{
+		{"missing email", `{}`, nil, http.StatusBadRequest, apierror.CodeInvalidRequest},
+		{"disabled", `{"email":"new@example.com"}`, userUseCase.ErrEmailChangeDisabled, http.StatusForbidden, apierror.CodeEmailChangeDisabled},
+		{"invalid email", `{"email":"new@"}`, auth.ErrInvalidEmail, http.StatusBadRequest, apierror.CodeValidationFailed},
+		{"unchanged", `{"email":"user0@example.com"}`, userUseCase.ErrEmailUnchanged, http.StatusBadRequest, apierror.CodeEmailUnchanged},
+		{"throttled", `{"email":"new@example.com"}`, auth.ErrEmailThrottled, http.StatusTooManyRequests, apierror.CodeEmailThrottled},
+		{"taken", `{"email":"taken@example.com"}`, userUseCase.ErrEmailTaken, http.StatusConflict, apierror.CodeEmailTaken},
+		{"database down", `{"email":"new@example.com"}`, fmt.Errorf("find user: %w", domain.ErrUnavailable), http.StatusServiceUnavailable, apierror.CodeUnavailable},
+		{"unexpected", `{"email":"new@example.com"}`, errors.New("smtp: 554"), http.StatusInternalServerError, apierror.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewUserHandler(nil, emailChangeUseCase{err: tt.err}, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/users/me/email", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user", &domain.User{ID: "user-1", Email: "user0@example.com"})
+
+			h.ChangeEmail(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMaxPageOffset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Cleanup(func() { SetMaxPageOffset(DefaultMaxPageOffset) })
+
+	tests := []struct {
+		name       string
+		maxOffset  int
+		query      string
+		newQuery   func() any
+		wantStatus int
+		wantDetail string
+	}{
+		{"users at the default bound", DefaultMaxPageOffset, "?offset=10000", func() any { return &ListUsersQuery{} }, http.StatusOK, ""},
+		{"users past the default bound", DefaultMaxPageOffset, "?offset=10001", func() any { return &ListUsersQuery{} }, http.StatusBadRequest, "offset must be at most 10000"},
+		{"users past a configured bound", 50, "?limit=10&offset=51", func() any { return &ListUsersQuery{} }, http.StatusBadRequest, "offset must be at most 50"},
+		{"deliveries past a configured bound", 50, "?offset=60", func() any { return &ListDeliveriesQuery{} }, http.StatusBadRequest, "offset must be at most 50"},
+		{"deliveries within a configured bound", 50, "?offset=50", func() any { return &ListDeliveriesQuery{} }, http.StatusOK, ""},
+		{"no bound", 0, "?offset=1000000", func() any { return &ListUsersQuery{} }, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetMaxPageOffset(tt.maxOffset)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/list"+tt.query, nil)
+
+			ok := bindQuery(c, tt.newQuery())
+
+			if wantOK := tt.wantStatus == http.StatusOK; ok != wantOK {
+				t.Fatalf("bindQuery() = %v, want %v: %s", ok, wantOK, w.Body)
+			}
+			if ok {
+				return
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Details) != 1 || !strings.HasPrefix(body.Details[0], tt.wantDetail) {
+				t.Errorf("details = %q, want %q", body.Details, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestUpdateMeName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantName   string
+	}{
+		{"trimmed and collapsed", `{"name":"  Toko   Budi "}`, http.StatusOK, "Toko Budi"},
+		{"name left out", `{}`, http.StatusOK, "User"},
+		{"too short", `{"name":" J "}`, http.StatusBadRequest, "User"},
+		{"control character", `{"name":"Toko\u0007Budi"}`, http.StatusBadRequest, "User"},
+		{"blocked word", `{"name":"Scam Shop"}`, http.StatusBadRequest, "User"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestUserRepo(t, 1)
+			user, err := repo.FindByEmail(context.Background(), "user0@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := NewUserHandler(repo, nil, auth.NewNameSanitizer(config.NameConfig{BlockedWords: []string{"scam"}}))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/users/me", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user", user)
+
+			h.UpdateMe(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			stored, err := repo.FindByID(context.Background(), user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Name != tt.wantName {
+				t.Errorf("stored name = %q, want %q", stored.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+// resetUseCase answers RequestPasswordReset with err.
+type resetUseCase struct {
+	userUseCase.UserUseCase
+	err error
+}
+
+func (u resetUseCase) RequestPasswordReset(context.Context, userUseCase.PasswordResetRequest) error {
+	return u.err
+}
+
+func TestForgotPasswordThrottled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantCode       apierror.Code
+		wantRetryAfter string
+	}{
+		{"sent", nil, http.StatusAccepted, "", ""},
+		{"throttled", &auth.EmailThrottledError{RetryAfter: 90 * time.Second}, http.StatusTooManyRequests, apierror.CodeEmailThrottled, "91"},
+		{"throttled without a wait", auth.ErrEmailThrottled, http.StatusTooManyRequests, apierror.CodeEmailThrottled, ""},
+		{"disabled", userUseCase.ErrPasswordResetDisabled, http.StatusForbidden, apierror.CodeResetDisabled, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewUserHandler(nil, resetUseCase{err: tt.err}, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/password/forgot", strings.NewReader(`{"email":"owner@example.com"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			h.ForgotPassword(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
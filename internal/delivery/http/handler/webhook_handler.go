@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	webhookUseCase "github.com/Elysian-Rebirth/backend-go/internal/usecase/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookUseCase webhookUseCase.WebhookUseCase
+}
+
+func NewWebhookHandler(uc webhookUseCase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUseCase: uc,
+	}
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required" example:"https://example.com/hooks/umkmai"`
+	Events []string `json:"events" binding:"required,min=1" example:"user.registered,user.deleted"`
+}
+
+type UpdateWebhookRequest struct {
+	URL      *string  `json:"url,omitempty"`
+	Events   []string `json:"events,omitempty"`
+	IsActive *bool    `json:"is_active,omitempty"`
+}
+
+// CreateWebhookResponse includes the signing secret, which is not returned
+// again after creation.
+type CreateWebhookResponse struct {
+	*domain.Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhookListResponse struct {
+	Data []*domain.Webhook `json:"data"`
+}
+
+type ListDeliveriesQuery struct {
+	Limit  int `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int `form:"offset,default=0" binding:"min=0"`
+}
+
+func (q ListDeliveriesQuery) pageOffset() int { return q.Offset }
+
+type WebhookDeliveryListResponse struct {
+	Data []*domain.WebhookDelivery `json:"data"`
+	Meta Meta                      `json:"meta"`
+}
+
+// Create godoc
+// @Summary      Register a webhook
+// @Description  Registers a URL to receive the lifecycle events of every user (admin only). Deliveries are POSTed as JSON and signed with HMAC-SHA256 in the X-Webhook-Signature header ("t=<unix>,v1=<hex>" over "<unix>.<body>"). The signing secret is only returned in this response.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateWebhookRequest true "Webhook"
+// @Success      201  {object}  CreateWebhookResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+
+	webhook, err := h.webhookUseCase.Create(c.Request.Context(), webhookUseCase.CreateRequest{
+		OwnerID: user.ID,
+		URL:     req.URL,
+		Events:  req.Events,
+	})
+	if err != nil {
+		h.writeError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// List godoc
+// @Summary      List my webhooks
+// @Tags         webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  WebhookListResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	user := middleware.MustGetUserFromContext(c)
+
+	webhooks, err := h.webhookUseCase.List(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookListResponse{Data: webhooks})
+}
+
+// ListAll godoc
+// @Summary      List all webhooks
+// @Description  Lists the webhooks of every user (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  WebhookListResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/admin/webhooks [get]
+func (h *WebhookHandler) ListAll(c *gin.Context) {
+	webhooks, err := h.webhookUseCase.List(c.Request.Context(), "")
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookListResponse{Data: webhooks})
+}
+
+// Get godoc
+// @Summary      Get a webhook
+// @Tags         webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      200  {object}  domain.Webhook
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) Get(c *gin.Context) {
+	webhook, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// Update godoc
+// @Summary      Update a webhook
+// @Description  Changes the URL, subscribed events or active flag of a webhook. Reactivating a webhook that was disabled for failing resets its failure count.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path  string                true  "Webhook ID"
+// @Param        request body  UpdateWebhookRequest  true  "Fields to change"
+// @Success      200  {object}  domain.Webhook
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	webhook, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid request body", Details: validationDetails(err)})
+		return
+	}
+
+	updated, err := h.webhookUseCase.Update(c.Request.Context(), webhook.ID, webhookUseCase.UpdateRequest{
+		URL:      req.URL,
+		Events:   req.Events,
+		IsActive: req.IsActive,
+	})
+	if err != nil {
+		h.writeError(c, err, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete godoc
+// @Summary      Delete a webhook
+// @Description  Deletes a webhook and its delivery history
+// @Tags         webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	webhook, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookUseCase.Delete(c.Request.Context(), webhook.ID); err != nil {
+		h.writeError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Webhook deleted successfully"})
+}
+
+// ListDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  Lists the delivery log of a webhook, most recent first
+// @Tags         webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path   string  true   "Webhook ID"
+// @Param        limit   query  int     false  "Page size"  default(20)  minimum(1)  maximum(100)
+// @Param        offset  query  int     false  "Offset"     default(0)   minimum(0)
+// @Success      200  {object}  WebhookDeliveryListResponse
+// @Header       200  {string}  Link  "RFC 5988 links to the first, prev, next and last pages"
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhook, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var query ListDeliveriesQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	deliveries, total, err := h.webhookUseCase.ListDeliveries(c.Request.Context(), webhook.ID, query.Limit, query.Offset)
+	if err != nil {
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: "Failed to fetch deliveries"})
+		return
+	}
+
+	meta := Meta{
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	setPaginationLinks(c, meta)
+	c.JSON(http.StatusOK, WebhookDeliveryListResponse{
+		Data: deliveries,
+		Meta: meta,
+	})
+}
+
+// Redeliver godoc
+// @Summary      Redeliver a webhook event
+// @Description  Queues a past delivery to be sent again. The webhook must be active.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path  string  true  "Webhook ID"
+// @Param        deliveryId  path  string  true  "Delivery ID"
+// @Success      202  {object}  domain.WebhookDelivery
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	webhook, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookUseCase.Redeliver(c.Request.Context(), webhook.ID, c.Param("deliveryId"))
+	if err != nil {
+		h.writeError(c, err, "Failed to queue delivery")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// authorize loads the webhook in the :id path parameter and checks the
+// caller owns it or is an admin. It writes the error response and returns
+// false otherwise.
+func (h *WebhookHandler) authorize(c *gin.Context) (*domain.Webhook, bool) {
+	webhook, err := h.webhookUseCase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, err, "Failed to fetch webhook")
+		return nil, false
+	}
+
+	if !middleware.HasRole(c, "admin") {
+		middleware.MustCheckOwnership(c, webhook.UserID)
+		if c.IsAborted() {
+			return nil, false
+		}
+	}
+
+	return webhook, true
+}
+
+func (h *WebhookHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, webhookUseCase.ErrInvalidURL):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid webhook URL", Details: []string{err.Error()}})
+	case errors.Is(err, webhookUseCase.ErrInvalidEvents):
+		apierror.Write(c, http.StatusBadRequest, ErrorResponse{Message: "Invalid webhook events", Details: []string{err.Error()}})
+	case errors.Is(err, webhookUseCase.ErrLimitReached):
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "Webhook limit reached"})
+	case errors.Is(err, webhookUseCase.ErrWebhookInactive):
+		apierror.Write(c, http.StatusConflict, ErrorResponse{Message: "Webhook is inactive"})
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "Webhook not found"})
+	case errors.Is(err, repository.ErrDeliveryNotFound):
+		apierror.Write(c, http.StatusNotFound, ErrorResponse{Message: "Delivery not found"})
+	default:
+		apierror.Write(c, http.StatusInternalServerError, ErrorResponse{Message: fallback})
+	}
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+)
+
+func TestCacheControlHeaders(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+		want       string
+	}{
+		{"version is public", "/version", "", http.StatusOK, middleware.PublicShort},
+		{"public API route", "/api/v1/ping", "", http.StatusOK, middleware.NoStore},
+		{"authenticated route without a token", "/api/v1/users/me", "", http.StatusUnauthorized, middleware.NoStore},
+		{"admin route as a user", "/api/v1/admin/config", "user", http.StatusForbidden, middleware.NoStore},
+		// Probes answer with no-store themselves
+		{"liveness", "/livez", "", http.StatusOK, middleware.NoStore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GET %s = %d, want %d: %s", tt.path, w.Code, tt.wantStatus, w.Body)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/handler"
+	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	"github.com/gin-gonic/gin"
+)
+
+// noUsage drops the usage MeterRequests records for authenticated requests.
+type noUsage struct{ usage.UsageUseCase }
+
+func (noUsage) IncrUsage(context.Context, string, string) (*usage.Usage, error) { return nil, nil }
+
+// newTestRouter sets up the routes the way the app does, with CORS in front.
+// Only the health handler is real; nothing here calls the others.
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.Server.Environment = "development"
+	cfg.Server.Swagger = config.SwaggerConfig{Mode: config.SwaggerPublic, CacheMaxAge: time.Hour}
+	cfg.Security = config.SecurityConfig{
+		CORSAllowedOrigins: []string{"https://app.umkmai.id"},
+		CORSAllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		CORSAllowedHeaders: []string{"Authorization", "Content-Type"},
+	}
+
+	pass := func(c *gin.Context) { c.Next() }
+	router := gin.New()
+	router.Use(middleware.CORS(cfg.Security))
+	SetupRoutes(router, cfg, handler.NewHealthHandler(cfg, nil, nil),
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, noUsage{},
+		fakeAuth, fakeAuth, pass, pass, pass)
+	return router
+}
+
+func TestFallbackRoutes(t *testing.T) {
+	router := newTestRouter(t)
+	const origin = "https://app.umkmai.id"
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		preflight  bool
+		wantStatus int
+		wantCode   apierror.Code
+		wantAllow  string
+	}{
+		{"unknown path", http.MethodGet, "/api/v1/nope", false, http.StatusNotFound, apierror.CodeRouteNotFound, ""},
+		{"wrong verb", http.MethodPatch, "/livez", false, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "GET"},
+		{"preflight to an unknown path", http.MethodOptions, "/api/v1/nope", true, http.StatusNoContent, "", ""},
+		{"liveness", http.MethodGet, "/livez", false, http.StatusOK, "", ""},
+		{"version", http.MethodGet, "/version", false, http.StatusOK, "", ""},
+		{"swagger", http.MethodGet, "/swagger/index.html", false, http.StatusOK, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", origin)
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("%s %s = %d, want %d: %s", tt.method, tt.path, w.Code, tt.wantStatus, w.Body)
+			}
+			// Browsers can only read the response, error or not, with this
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, origin)
+			}
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q is not JSON: %v", w.Body, err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
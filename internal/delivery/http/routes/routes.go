@@ -1,8 +1,19 @@
 package routes
 
 import (
+	"expvar"
+	"fmt"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
 	"github.com/Elysian-Rebirth/backend-go/internal/delivery/http/handler"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/featureflags"
 	"github.com/Elysian-Rebirth/backend-go/internal/middleware"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/ai"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -10,28 +21,73 @@ import (
 
 func SetupRoutes(
 	router *gin.Engine,
+	cfg *config.Config,
 	healthHandler *handler.HealthHandler,
 	userHandler *handler.UserHandler,
 	authHandler *handler.AuthHandler,
+	adminHandler *handler.AdminHandler,
+	usageHandler *handler.UsageHandler,
+	fileHandler *handler.FileHandler,
+	aiHandler *handler.AIHandler,
+	webhookHandler *handler.WebhookHandler,
+	notificationHandler *handler.NotificationHandler,
+	jwksHandler *handler.JWKSHandler,
+	avatarHandler *handler.AvatarHandler,
+	businessHandler *handler.BusinessHandler,
+	features *featureflags.Service,
+	usageUC usage.UsageUseCase,
 	authMiddleware gin.HandlerFunc,
+	streamAuthMiddleware gin.HandlerFunc,
+	generationRateLimit gin.HandlerFunc,
+	requireCache gin.HandlerFunc,
+	requireDatabase gin.HandlerFunc,
 ) {
+	// Unknown paths and methods get the same JSON errors as everything else.
+	// The fallback handlers only run the global middleware registered before
+	// this point, which must include CORS so browsers can read the errors
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(handler.NoRoute)
+	router.NoMethod(handler.NoMethod)
+
 	// Swagger
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	setupSwagger(router, cfg, authMiddleware)
+
+	// Profiling
+	setupPprof(router, cfg, authMiddleware)
 
-	// Health check
-	router.GET("/health", healthHandler.Check)
+	// Health check. /livez and /readyz are the probes, and sit outside the
+	// API groups so no auth or rate limiting applies to them
+	router.GET("/health", whenFresh(authMiddleware), whenFresh(middleware.RequireRole("admin")), healthHandler.Check)
+	router.GET("/livez", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+	router.GET("/ready", healthHandler.Ready)
+	router.GET("/version", middleware.CacheControl(middleware.PublicShort), healthHandler.Version)
 
-	// API v1
+	// Public keys for verifying our tokens
+	router.GET("/.well-known/jwks.json", jwksHandler.JWKS)
+
+	// Identical in-flight GETs to the read-only routes below share one
+	// response, which takes the load off dashboards polling them
+	coalesce := middleware.Coalesce()
+
+	// API v1. Its responses carry user data, so no cache may keep them, and
+	// it answers 503 at once while the database is known to be down. Every
+	// authenticated request counts towards the user's api_requests
 	v1 := router.Group("/api/v1")
+	v1.Use(middleware.CacheControl(middleware.NoStore), requireDatabase, middleware.Tenant(cfg.Tenancy), middleware.MeterRequests(usageUC))
 	{
 		v1.GET("/ping", healthHandler.Ping)
 
+		// Sessions live in Redis, so these answer 503 until it is reachable
 		auth := v1.Group("/auth")
+		auth.Use(requireCache)
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
 			auth.POST("/logout", authHandler.Logout)
+			auth.POST("/password/forgot", userHandler.ForgotPassword)
+			auth.POST("/password/reset", userHandler.ResetPassword)
 		}
 
 		// Users
@@ -39,6 +95,7 @@ func SetupRoutes(
 		{
 			users.GET("/:id", userHandler.GetByID)
 			users.GET("/email/:email", userHandler.GetByEmail)
+			users.POST("/email/confirm", userHandler.ConfirmEmailChange)
 
 			protected := users.Group("")
 			protected.Use(authMiddleware) // Apply auth middleware
@@ -46,14 +103,172 @@ func SetupRoutes(
 				protected.GET("/me", userHandler.GetMe)       // Get current user
 				protected.PUT("/me", userHandler.UpdateMe)    // Update current user
 				protected.DELETE("/me", userHandler.DeleteMe) // Delete current user
+				protected.PUT("/me/email", userHandler.ChangeEmail)
+				protected.PUT("/me/avatar", middleware.Meter(usageUC, domain.UsageUploads), avatarHandler.Upload)
+				protected.GET("/me/usage", coalesce, usageHandler.GetMyUsage)
+				protected.GET("/me/businesses", coalesce, businessHandler.ListMine)
 
 				// Admin only routes
 				admin := protected.Group("")
 				admin.Use(middleware.RequireRole("admin"))
 				{
-					admin.GET("", userHandler.List)
+					admin.GET("", coalesce, userHandler.List)
+					admin.POST("/bulk-delete", userHandler.BulkDelete)
 				}
 			}
 		}
+
+		// Files
+		files := v1.Group("/files")
+		files.Use(authMiddleware)
+		{
+			files.POST("", middleware.Meter(usageUC, domain.UsageUploads), fileHandler.Upload)
+			files.DELETE("/:id", middleware.RequireOwnership("file"), fileHandler.Delete)
+		}
+
+		// Businesses
+		businesses := v1.Group("/businesses")
+		businesses.Use(authMiddleware)
+		{
+			businesses.POST("", businessHandler.Create)
+			businesses.GET("/:id", businessHandler.Get)
+			businesses.PUT("/:id", middleware.RequireOwnership("business"), businessHandler.Update)
+			businesses.DELETE("/:id", middleware.RequireOwnership("business"), businessHandler.Delete)
+		}
+
+		// AI features, metered per user; chat is behind the ai_chat flag.
+		// Each call counts towards the daily quota of its feature and the
+		// monthly ai_calls quota
+		aiGroup := v1.Group("/ai")
+		aiGroup.Use(authMiddleware)
+		{
+			aiCalls := middleware.MonthlyQuota(usageUC, domain.UsageAICalls, cfg.MonthlyQuotas[domain.UsageAICalls])
+
+			aiGroup.POST("/chat", middleware.RequireFeature(features, ai.FeatureChat), middleware.Quota(usageUC, ai.FeatureChat, cfg.Quotas[ai.FeatureChat]), aiCalls, aiHandler.Chat)
+
+			descriptionQuota := middleware.Quota(usageUC, ai.FeatureBusinessDescription, cfg.Quotas[ai.FeatureBusinessDescription])
+			aiGroup.POST("/business-description", generationRateLimit, descriptionQuota, aiCalls, aiHandler.BusinessDescription)
+			aiGroup.POST("/business-description/:id/regenerate", generationRateLimit, descriptionQuota, aiCalls, aiHandler.RegenerateBusinessDescription)
+		}
+
+		// Webhooks receive every user's lifecycle events, so only admins
+		// manage them
+		webhooks := v1.Group("/webhooks")
+		webhooks.Use(authMiddleware, middleware.RequireRole("admin"))
+		{
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.GET("", webhookHandler.List)
+
+			owned := webhooks.Group("/:id")
+			owned.Use(middleware.RequireOwnership("webhook"))
+			{
+				owned.GET("", webhookHandler.Get)
+				owned.PUT("", webhookHandler.Update)
+				owned.DELETE("", webhookHandler.Delete)
+				owned.GET("/deliveries", webhookHandler.ListDeliveries)
+				owned.POST("/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+			}
+		}
+
+		// Notifications; EventSource can't send headers, so the stream also
+		// takes the token from the query
+		notifications := v1.Group("/notifications")
+		notifications.Use(streamAuthMiddleware)
+		{
+			notifications.GET("/stream", notificationHandler.Stream)
+		}
+
+		// Admin
+		admin := v1.Group("/admin")
+		admin.Use(authMiddleware, middleware.RequireRole("admin"))
+		{
+			admin.GET("/config", adminHandler.GetConfig)
+			admin.GET("/stats", coalesce, adminHandler.GetStats)
+			admin.GET("/runtime", adminHandler.GetRuntime)
+			admin.GET("/rbac/matrix", adminHandler.GetRoleMatrix)
+			admin.DELETE("/cache/ml", adminHandler.FlushMLCache)
+			admin.GET("/cache/keys", adminHandler.ListCacheKeys)
+			admin.DELETE("/cache/keys", adminHandler.DeleteCacheKeys)
+			admin.GET("/features", adminHandler.ListFeatures)
+			admin.PUT("/features", adminHandler.UpdateFeatures)
+			admin.GET("/emails/failed", adminHandler.ListFailedEmails)
+			admin.POST("/emails/failed/:id/retry", adminHandler.RetryFailedEmail)
+			admin.GET("/jobs", adminHandler.ListJobs)
+			admin.POST("/jobs/:name/run", adminHandler.RunJob)
+			admin.GET("/users/:id", userHandler.GetWithRoles)
+			admin.GET("/users/:id/usage", usageHandler.GetUserUsage)
+			admin.POST("/users/:id/revoke-sessions", userHandler.RevokeSessions)
+			admin.GET("/webhooks", webhookHandler.ListAll)
+		}
+	}
+}
+
+// setupSwagger registers the Swagger UI according to server.swagger. When
+// disabled the route is not registered at all, so it 404s like any other
+// unknown path.
+func setupSwagger(router *gin.Engine, cfg *config.Config, authMiddleware gin.HandlerFunc) {
+	mode := cfg.SwaggerMode()
+	if mode == config.SwaggerDisabled {
+		return
+	}
+
+	swagger := router.Group("/swagger")
+	if mode == config.SwaggerAdmin {
+		swagger.Use(authMiddleware, middleware.RequireRole("admin"))
+	}
+	swagger.Use(swaggerCacheControl(cfg.Server.Swagger.CacheMaxAge, mode == config.SwaggerAdmin), middleware.Gzip())
+	swagger.GET("/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+}
+
+// whenFresh runs handler only for requests asking for fresh results with
+// ?fresh=true, e.g. to reserve bypassing a cache to admins.
+func whenFresh(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("fresh") == "true" {
+			handler(c)
+		}
+	}
+}
+
+// setupPprof serves net/http/pprof and expvar under /debug to admins, when
+// enabled. Without it the paths don't exist at all.
+func setupPprof(router *gin.Engine, cfg *config.Config, authMiddleware gin.HandlerFunc) {
+	if !cfg.Server.EnablePprof {
+		return
+	}
+
+	debug := router.Group("/debug")
+	debug.Use(middleware.CacheControl(middleware.NoStore), authMiddleware, middleware.RequireRole("admin"))
+	debug.GET("/vars", gin.WrapH(expvar.Handler()))
+
+	pprofGroup := debug.Group("/pprof")
+	pprofGroup.GET("/", gin.WrapF(pprof.Index))
+	pprofGroup.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	pprofGroup.GET("/profile", gin.WrapF(pprof.Profile))
+	pprofGroup.GET("/symbol", gin.WrapF(pprof.Symbol))
+	pprofGroup.POST("/symbol", gin.WrapF(pprof.Symbol))
+	pprofGroup.GET("/trace", gin.WrapF(pprof.Trace))
+	// Index serves the named profiles: heap, goroutine, allocs and so on
+	pprofGroup.GET("/:name", gin.WrapF(pprof.Index))
+}
+
+// swaggerCacheControl lets browsers cache the bundled UI assets, which only
+// change with a dependency upgrade. The page and the generated spec change
+// with every deploy, so they are always revalidated.
+func swaggerCacheControl(maxAge time.Duration, private bool) gin.HandlerFunc {
+	scope := "public"
+	if private {
+		scope = "private"
+	}
+	assets := fmt.Sprintf("%s, max-age=%d", scope, int(maxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		path := c.Param("any")
+		if maxAge > 0 && !strings.HasSuffix(path, "/") && !strings.HasSuffix(path, "index.html") && !strings.HasSuffix(path, "doc.json") {
+			c.Header("Cache-Control", assets)
+		} else {
+			c.Header("Cache-Control", "no-cache")
+		}
+		c.Next()
 	}
 }
@@ -0,0 +1,236 @@
+package routes
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAuth authenticates requests by their bearer token: "admin" and "user"
+// get the role of that name, anything else is rejected.
+func fakeAuth(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token != "admin" && token != "user" {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	c.Set("user", &domain.User{ID: "user-1"})
+	c.Set("user_roles", []*domain.Role{{Name: token}})
+	c.Next()
+}
+
+func TestSwaggerRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		environment string
+		mode        string
+		token       string
+		wantStatus  int
+	}{
+		{"public", "development", config.SwaggerPublic, "", http.StatusOK},
+		{"public by default", "development", "", "", http.StatusOK},
+		{"disabled", "development", config.SwaggerDisabled, "", http.StatusNotFound},
+		{"disabled by default in production", "production", "", "", http.StatusNotFound},
+		{"disabled ignores an admin token", "production", config.SwaggerDisabled, "admin", http.StatusNotFound},
+		{"public when enabled in production", "production", config.SwaggerPublic, "", http.StatusOK},
+		{"admin without a token", "production", config.SwaggerAdmin, "", http.StatusUnauthorized},
+		{"admin as a user", "production", config.SwaggerAdmin, "user", http.StatusForbidden},
+		{"admin as an admin", "production", config.SwaggerAdmin, "admin", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Server.Environment = tt.environment
+			cfg.Server.Swagger = config.SwaggerConfig{Mode: tt.mode, CacheMaxAge: time.Hour}
+
+			router := gin.New()
+			setupSwagger(router, cfg, fakeAuth)
+
+			req := httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GET /swagger/index.html = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSwaggerCaching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		mode      string
+		maxAge    time.Duration
+		path      string
+		wantCache string
+	}{
+		{"asset", config.SwaggerPublic, time.Hour, "/swagger/swagger-ui.css", "public, max-age=3600"},
+		{"asset behind admin auth", config.SwaggerAdmin, time.Hour, "/swagger/swagger-ui.css", "private, max-age=3600"},
+		{"page", config.SwaggerPublic, time.Hour, "/swagger/index.html", "no-cache"},
+		{"spec", config.SwaggerPublic, time.Hour, "/swagger/doc.json", "no-cache"},
+		{"caching off", config.SwaggerPublic, 0, "/swagger/swagger-ui.css", "no-cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Server.Swagger = config.SwaggerConfig{Mode: tt.mode, CacheMaxAge: tt.maxAge}
+
+			router := gin.New()
+			setupSwagger(router, cfg, fakeAuth)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer admin")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestSwaggerCompression(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Server.Swagger = config.SwaggerConfig{Mode: config.SwaggerPublic}
+
+	router := gin.New()
+	setupSwagger(router, cfg, fakeAuth)
+
+	plain := httptest.NewRecorder()
+	router.ServeHTTP(plain, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	if plain.Code != http.StatusOK || plain.Header().Get("Content-Encoding") != "" {
+		t.Fatalf("GET without Accept-Encoding = %d, encoding %q, want an uncompressed 200", plain.Code, plain.Header().Get("Content-Encoding"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("headers = %v, want gzip with Vary: Accept-Encoding", w.Header())
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != plain.Body.String() {
+		t.Error("decompressed body differs from the uncompressed response")
+	}
+}
+
+func TestPprofRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		token      string
+		wantStatus int
+	}{
+		{"disabled", false, "", http.StatusNotFound},
+		{"disabled for admins too", false, "admin", http.StatusNotFound},
+		{"unauthenticated", true, "", http.StatusUnauthorized},
+		{"not an admin", true, "user", http.StatusForbidden},
+		{"admin", true, "admin", http.StatusOK},
+	}
+	paths := []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/pprof/heap", "/debug/pprof/cmdline", "/debug/vars"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Server.EnablePprof = tt.enabled
+
+			router := gin.New()
+			setupPprof(router, cfg, fakeAuth)
+
+			for _, path := range paths {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if tt.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tt.token)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if w.Code != tt.wantStatus {
+					t.Errorf("GET %s = %d, want %d", path, w.Code, tt.wantStatus)
+				}
+				// Only admins ever see a profile
+				if tt.wantStatus != http.StatusOK && strings.Contains(w.Body.String(), "goroutine") {
+					t.Errorf("GET %s leaked profile data: %.100s", path, w.Body.String())
+				}
+				if tt.wantStatus == http.StatusOK && w.Header().Get("Cache-Control") != "no-store" {
+					t.Errorf("GET %s Cache-Control = %q, want no-store", path, w.Header().Get("Cache-Control"))
+				}
+			}
+		})
+	}
+}
+
+func TestHealthFreshIsAdminOnly(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"unauthenticated", "", http.StatusUnauthorized},
+		{"not an admin", "user", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/health?fresh=true", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GET /health?fresh=true = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPprofOffByDefault(t *testing.T) {
+	// The config leaves server.enable_pprof unset
+	router := newTestRouter(t)
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine", "/debug/vars"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer admin")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), `"route_not_found"`) {
+			t.Errorf("GET %s = %d %.100s, want the route_not_found error", path, w.Code, w.Body.String())
+		}
+	}
+}
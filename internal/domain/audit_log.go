@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type AuditLog struct {
+	ID         string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID     *string        `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Action     string         `gorm:"type:varchar(100);not null;index" json:"action"`
+	EntityType string         `gorm:"type:varchar(100);not null" json:"entity_type"`
+	EntityID   *string        `gorm:"type:uuid" json:"entity_id,omitempty"`
+	Changes    datatypes.JSON `gorm:"type:jsonb" json:"changes,omitempty"`
+	IPAddress  *string        `gorm:"type:inet" json:"ip_address,omitempty"`
+	UserAgent  *string        `gorm:"type:text" json:"user_agent,omitempty"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Business is the profile of a small business (UMKM) run by a user. A user
+// may own several.
+type Business struct {
+	ID          string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID      string         `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name        string         `gorm:"type:varchar(100);not null" json:"name"`
+	Category    string         `gorm:"type:varchar(50);not null" json:"category"`
+	Description string         `gorm:"type:text" json:"description"`
+	Address     string         `gorm:"type:varchar(500)" json:"address"`
+	Phone       string         `gorm:"type:varchar(30)" json:"phone"`
+	LogoURL     *string        `gorm:"type:varchar(2048)" json:"logo_url,omitempty"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Business) TableName() string {
+	return "businesses"
+}
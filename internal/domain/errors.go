@@ -0,0 +1,42 @@
+package domain
+
+import "errors"
+
+// Errors shared by the use cases and repositories. Callers match them with
+// errors.Is, and the HTTP layer maps them to status codes and error codes.
+var (
+	// ErrNotFound means the requested record doesn't exist. Repositories wrap
+	// it in their own errors, e.g. repository.ErrUserNotFound
+	ErrNotFound = errors.New("not found")
+
+	// ErrEmailTaken means another account already uses the address
+	ErrEmailTaken = errors.New("email already registered")
+
+	// ErrEmailDomainNotAllowed means signups from the address's domain are
+	// not accepted
+	ErrEmailDomainNotAllowed = errors.New("email domain not allowed")
+
+	// ErrInvalidCredentials means the email or password is wrong
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// ErrAccountDisabled means the account exists but has been deactivated
+	ErrAccountDisabled = errors.New("account is disabled")
+
+	// ErrUnavailable means a backing store could not be reached. It is
+	// usually brief, so the request may succeed when retried
+	ErrUnavailable = errors.New("temporarily unavailable")
+)
+
+// ValidationError is input that breaks a business rule. Its message is meant
+// for the client.
+type ValidationError struct {
+	Message string
+}
+
+func NewValidationError(message string) *ValidationError {
+	return &ValidationError{Message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,34 @@
+package domain
+
+// Event types published to the message broker
+const (
+	EventUserRegistered  = "user.registered"
+	EventUserVerified    = "user.verified"
+	EventUserDeactivated = "user.deactivated"
+	EventUserDeleted     = "user.deleted"
+)
+
+type UserRegisteredEvent struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+}
+
+// UserVerifiedEvent is published when a user confirms an email address.
+type UserVerifiedEvent struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// UserDeactivatedEvent is published when an admin deactivates an account.
+type UserDeactivatedEvent struct {
+	UserID  string `json:"user_id"`
+	ActorID string `json:"actor_id"`
+}
+
+// UserDeletedEvent is published when an account is deleted, by its owner or
+// an admin.
+type UserDeletedEvent struct {
+	UserID  string `json:"user_id"`
+	ActorID string `json:"actor_id"`
+}
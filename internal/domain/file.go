@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type File struct {
+	ID               string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID           string         `gorm:"type:uuid;not null;index" json:"user_id"`
+	WorkflowID       *string        `gorm:"type:uuid;index" json:"workflow_id,omitempty"`
+	ExecutionID      *string        `gorm:"type:uuid;index" json:"execution_id,omitempty"`
+	Filename         string         `gorm:"type:varchar(255);not null" json:"filename"`
+	OriginalFilename string         `gorm:"type:varchar(255);not null" json:"original_filename"`
+	FileSize         int64          `gorm:"not null" json:"file_size"`
+	MimeType         string         `gorm:"type:varchar(100);not null" json:"mime_type"`
+	StoragePath      string         `gorm:"type:varchar(500);not null" json:"storage_path"`
+	StorageProvider  string         `gorm:"type:varchar(50);default:'s3';not null" json:"storage_provider"`
+	Metadata         datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"metadata,omitempty" swaggertype:"object"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+func (File) TableName() string {
+	return "files"
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Generation kinds
+const (
+	GenerationBusinessDescription = "business_description"
+)
+
+// Generation statuses
+const (
+	GenerationSucceeded = "succeeded"
+	GenerationFailed    = "failed"
+	// GenerationRejected means the model's output was withheld from the user
+	GenerationRejected = "rejected"
+)
+
+// Generation records one call to a text-generation model, kept for usage
+// analytics and so a result can be regenerated with feedback.
+type Generation struct {
+	ID     string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID string `gorm:"type:uuid;not null;index" json:"user_id"`
+	Kind   string `gorm:"type:varchar(50);not null;index" json:"kind"`
+	// Input is the structured request; InputHash identifies identical ones
+	Input     datatypes.JSON `gorm:"type:jsonb;not null" json:"input" swaggertype:"object"`
+	InputHash string         `gorm:"type:varchar(64);not null" json:"input_hash"`
+	// ParentID is the generation this one regenerates
+	ParentID         *string   `gorm:"type:uuid" json:"parent_id,omitempty"`
+	Output           string    `gorm:"type:text" json:"output"`
+	Status           string    `gorm:"type:varchar(20);not null" json:"status" enums:"succeeded,failed,rejected"`
+	Error            string    `gorm:"type:text" json:"error,omitempty"`
+	PromptTokens     int       `gorm:"default:0;not null" json:"prompt_tokens"`
+	CompletionTokens int       `gorm:"default:0;not null" json:"completion_tokens"`
+	LatencyMs        int64     `gorm:"default:0;not null" json:"latency_ms"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Generation) TableName() string {
+	return "generations"
+}
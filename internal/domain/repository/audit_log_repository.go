@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *domain.AuditLog) error
+}
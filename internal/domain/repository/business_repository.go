@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// ErrBusinessNotFound is returned when no business matches the lookup
+var ErrBusinessNotFound = fmt.Errorf("business %w", domain.ErrNotFound)
+
+type BusinessRepository interface {
+	Create(ctx context.Context, business *domain.Business) error
+	FindByID(ctx context.Context, id string) (*domain.Business, error)
+	// ListByUser returns the user's businesses, oldest first
+	ListByUser(ctx context.Context, userID string) ([]*domain.Business, error)
+	Update(ctx context.Context, business *domain.Business) error
+	Delete(ctx context.Context, id string) error
+	// Count returns the number of businesses of userID, or of every user
+	// when userID is empty
+	Count(ctx context.Context, userID string) (int64, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// ErrFileNotFound is returned when no file matches the lookup
+var ErrFileNotFound = fmt.Errorf("file %w", domain.ErrNotFound)
+
+type FileRepository interface {
+	Create(ctx context.Context, file *domain.File) error
+	FindByID(ctx context.Context, id string) (*domain.File, error)
+	// Delete removes the row permanently
+	Delete(ctx context.Context, id string) error
+	// StoragePathsInUse reports which of paths belong to a file, including
+	// soft-deleted ones
+	StoragePathsInUse(ctx context.Context, paths []string) (map[string]bool, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// ErrGenerationNotFound is returned when no generation matches the lookup
+var ErrGenerationNotFound = fmt.Errorf("generation %w", domain.ErrNotFound)
+
+type GenerationRepository interface {
+	Create(ctx context.Context, generation *domain.Generation) error
+	FindByID(ctx context.Context, id string) (*domain.Generation, error)
+}
@@ -2,10 +2,20 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 )
 
+var (
+	// ErrRoleNotFound is returned when no role matches the lookup
+	ErrRoleNotFound = fmt.Errorf("role %w", domain.ErrNotFound)
+
+	// ErrRoleAssignmentNotFound is returned when removing a role the user
+	// does not have
+	ErrRoleAssignmentNotFound = fmt.Errorf("user role assignment %w", domain.ErrNotFound)
+)
+
 type RoleRepository interface {
 	Create(ctx context.Context, role *domain.Role) error
 	FindByID(ctx context.Context, id string) (*domain.Role, error)
@@ -13,7 +23,12 @@ type RoleRepository interface {
 	Update(ctx context.Context, role *domain.Role) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]*domain.Role, error)
+	// AssignToUser gives the user the role; assigning a role the user
+	// already has is a no-op
 	AssignToUser(ctx context.Context, userID, roleID string) error
 	RemoveFromUser(ctx context.Context, userID, roleID string) error
 	GetUserRoles(ctx context.Context, userID string) ([]*domain.Role, error)
+	// CountUsers returns the number of users assigned to each role, keyed by
+	// role ID. Roles nobody has are left out.
+	CountUsers(ctx context.Context) (map[string]int64, error)
 }
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+type UsageRecordRepository interface {
+	// Save stores the counts of records. A record that already exists keeps
+	// the higher of the two counts, so saving the same totals again, or
+	// older ones, never inflates it.
+	Save(ctx context.Context, records []*domain.UsageRecord) error
+	// ListByUser returns the user's records of one period
+	ListByUser(ctx context.Context, userID, period string) ([]*domain.UsageRecord, error)
+}
@@ -2,16 +2,62 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 )
 
+// ErrUserNotFound is returned when no user matches the lookup
+var ErrUserNotFound = fmt.Errorf("user %w", domain.ErrNotFound)
+
+// ErrBatchIncomplete is returned by atomic bulk operations when some of the
+// requested IDs do not match an existing record, in which case nothing is changed.
+var ErrBatchIncomplete = errors.New("batch contains unknown ids")
+
+// BatchRowErrors is returned by non-atomic bulk operations when some rows
+// could not be changed, keyed by their ID. The rows that were changed are
+// returned alongside it.
+type BatchRowErrors map[string]error
+
+func (e BatchRowErrors) Error() string {
+	return fmt.Sprintf("%d rows of the batch failed", len(e))
+}
+
+func (e BatchRowErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
 type UserRepository interface {
+	// Create fails with domain.ErrEmailTaken when any user, even a deleted
+	// one, has the email
 	Create(ctx context.Context, user *domain.User) error
 	FindByID(ctx context.Context, id string) (*domain.User, error)
+	FindByIDWithRoles(ctx context.Context, id string) (*domain.User, error)
+	// FindByEmail matches the email exactly; callers normalize it first
+	// with auth.NormalizeEmail
 	FindByEmail(ctx context.Context, email string) (*domain.User, error)
 	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]*domain.User, int64, error)
+	ListWithRoles(ctx context.Context, limit, offset int) ([]*domain.User, int64, error)
+	// Count returns the number of users that aren't deleted
+	Count(ctx context.Context) (int64, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	BulkDelete(ctx context.Context, ids []string, atomic bool) ([]*domain.User, error)
+	BulkDeactivate(ctx context.Context, ids []string, atomic bool) ([]*domain.User, error)
+	// PurgeDeleted permanently removes users soft-deleted before the given
+	// time and returns how many were removed
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// AvatarURLsInUse reports which of urls are the avatar of a user,
+	// including soft-deleted ones
+	AvatarURLsInUse(ctx context.Context, urls []string) (map[string]bool, error)
+	// AvatarKeysInUse reports which of keys are the uploaded avatar of a
+	// user, including soft-deleted ones
+	AvatarKeysInUse(ctx context.Context, keys []string) (map[string]bool, error)
 }
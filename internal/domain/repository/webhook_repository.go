@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+var (
+	// ErrWebhookNotFound is returned when no webhook matches the lookup
+	ErrWebhookNotFound = fmt.Errorf("webhook %w", domain.ErrNotFound)
+
+	// ErrDeliveryNotFound is returned when no webhook delivery matches the lookup
+	ErrDeliveryNotFound = fmt.Errorf("webhook delivery %w", domain.ErrNotFound)
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	FindByID(ctx context.Context, id string) (*domain.Webhook, error)
+	// List returns webhooks newest first; an empty userID lists every user's
+	List(ctx context.Context, userID string) ([]*domain.Webhook, error)
+	// ListActiveForEvent returns the active webhooks subscribed to eventType
+	ListActiveForEvent(ctx context.Context, eventType string) ([]*domain.Webhook, error)
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, id string) error
+
+	// RecordFailure increments the webhook's consecutive failures and
+	// disables it once they reach disableAfter (0 never disables). It
+	// returns the updated webhook.
+	RecordFailure(ctx context.Context, id string, disableAfter int) (*domain.Webhook, error)
+	// ResetFailures clears the consecutive failure count
+	ResetFailures(ctx context.Context, id string) error
+
+	// CreateDelivery stores delivery unless one already exists for the same
+	// webhook and event, in which case delivery is filled from the
+	// existing row
+	CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	FindDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// ListDeliveries returns a webhook's deliveries newest first, with the total count
+	ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, int64, error)
+	// PruneDeliveries removes finished deliveries created before the given
+	// time and returns how many were removed
+	PruneDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
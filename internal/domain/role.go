@@ -2,16 +2,20 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"gorm.io/datatypes"
 )
 
 type Role struct {
-	ID          string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ID string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	// TenantID limits a custom role to one tenant; nil roles, such as the
+	// built-in ones, are shared by every tenant
+	TenantID    *string        `gorm:"type:varchar(63);index" json:"tenant_id,omitempty"`
 	Name        string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
 	Description *string        `gorm:"type:text" json:"description,omitempty"`
-	Permissions datatypes.JSON `gorm:"type:jsonb;default:'[]';not null" json:"permissions"`
+	Permissions datatypes.JSON `gorm:"type:jsonb;default:'[]';not null" json:"permissions" swaggertype:"array,string"`
 	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -20,16 +24,35 @@ func (Role) TableName() string {
 	return "roles"
 }
 
+// GetPermissions is GetPermissionsE for best-effort callers: a role whose
+// permissions can't be parsed has none.
 func (r *Role) GetPermissions() []string {
-	var perms []string
-
-	if err := json.Unmarshal(r.Permissions, &perms); err != nil {
+	perms, err := r.GetPermissionsE()
+	if err != nil {
 		return []string{}
 	}
 
 	return perms
 }
 
+// GetPermissionsE returns the role's permissions, or an error when the stored
+// value isn't a JSON array of strings, e.g. because the column is corrupted.
+func (r *Role) GetPermissionsE() ([]string, error) {
+	if len(r.Permissions) == 0 {
+		return []string{}, nil
+	}
+
+	var perms []string
+	if err := json.Unmarshal(r.Permissions, &perms); err != nil {
+		return nil, fmt.Errorf("invalid permissions of role %s: %w", r.Name, err)
+	}
+	if perms == nil {
+		perms = []string{}
+	}
+
+	return perms, nil
+}
+
 func (r *Role) HasPermission(permission string) bool {
 	perms := r.GetPermissions()
 
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions string
+		want        []string
+		wantErr     bool
+	}{
+		{"list", `["users.read","users.write"]`, []string{"users.read", "users.write"}, false},
+		{"wildcard", `["*"]`, []string{"*"}, false},
+		{"empty list", `[]`, []string{}, false},
+		{"unset", ``, []string{}, false},
+		{"null", `null`, []string{}, false},
+		{"malformed", `["users.read",`, nil, true},
+		{"object", `{"users":"read"}`, nil, true},
+		{"not strings", `[1,2]`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &Role{ID: "role-1", Name: "editor", Permissions: []byte(tt.permissions)}
+
+			got, err := role.GetPermissionsE()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "editor") {
+					t.Fatalf("GetPermissionsE() = %v, %v, want an error naming the role", got, err)
+				}
+				// Best-effort callers see a role without permissions
+				if perms := role.GetPermissions(); perms == nil || len(perms) != 0 {
+					t.Errorf("GetPermissions() = %#v, want empty", perms)
+				}
+				if role.HasPermission("users.read") {
+					t.Error("HasPermission() granted a permission of a malformed role")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil || !slices.Equal(got, tt.want) {
+				t.Errorf("GetPermissionsE() = %#v, want %#v", got, tt.want)
+			}
+			if perms := role.GetPermissions(); !slices.Equal(perms, tt.want) {
+				t.Errorf("GetPermissions() = %#v, want %#v", perms, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Categories of usage metered per user and month
+const (
+	UsageAICalls     = "ai_calls"
+	UsageUploads     = "uploads"
+	UsageAPIRequests = "api_requests"
+)
+
+// UsageRecord is a user's count of one category in one month, e.g.
+// 2026-10. The live counters are kept in Redis and copied here
+// periodically, so the counts survive losing the cache.
+type UsageRecord struct {
+	UserID    string    `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Period    string    `gorm:"type:varchar(7);primaryKey" json:"period"`
+	Category  string    `gorm:"type:varchar(50);primaryKey" json:"category"`
+	Count     int64     `gorm:"default:0;not null" json:"count"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (UsageRecord) TableName() string {
+	return "usage_records"
+}
@@ -7,17 +7,26 @@ import (
 )
 
 type User struct {
-	ID              string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	Email           string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
-	PasswordHash    string         `gorm:"type:varchar(255);not null" json:"-"`
-	Name            string         `gorm:"type:varchar(255);not null" json:"name"`
-	AvatarURL       *string        `gorm:"type:varchar(500)" json:"avatar_url,omitempty"`
+	ID string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	// TenantID is the tenant the user signed up under; nil when tenancy is
+	// disabled
+	TenantID     *string `gorm:"type:varchar(63);index" json:"tenant_id,omitempty"`
+	Email        string  `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	PasswordHash string  `gorm:"type:varchar(255);not null" json:"-"`
+	Name         string  `gorm:"type:varchar(255);not null" json:"name"`
+	AvatarURL    *string `gorm:"type:varchar(500)" json:"avatar_url,omitempty"`
+	// AvatarKey is the storage key prefix of an uploaded avatar's variants;
+	// nil when AvatarURL was set directly
+	AvatarKey       *string        `gorm:"type:varchar(500)" json:"-"`
 	IsActive        bool           `gorm:"default:true;not null" json:"is_active"`
 	EmailVerifiedAt *time.Time     `json:"email_verified_at,omitempty"`
 	LastLoginAt     *time.Time     `json:"last_login_at,omitempty"`
 	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+
+	// Roles is only populated by the *WithRoles repository methods
+	Roles []Role `gorm:"many2many:user_roles;joinForeignKey:UserID;joinReferences:RoleID" json:"roles,omitempty"`
 }
 
 func (User) TableName() string {
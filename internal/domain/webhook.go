@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// WebhookEvents are the event types a webhook can subscribe to.
+var WebhookEvents = []string{
+	EventUserRegistered,
+	EventUserVerified,
+	EventUserDeactivated,
+	EventUserDeleted,
+}
+
+type Webhook struct {
+	ID     string `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID string `gorm:"type:uuid;not null;index" json:"user_id"`
+	URL    string `gorm:"type:varchar(2048);not null" json:"url"`
+	// Secret signs deliveries; it is only shown once, when the webhook is created
+	Secret              string                      `gorm:"type:varchar(255);not null" json:"-"`
+	Events              datatypes.JSONSlice[string] `gorm:"type:jsonb;default:'[]';not null" json:"events" swaggertype:"array,string"`
+	IsActive            bool                        `gorm:"default:true;not null" json:"is_active"`
+	ConsecutiveFailures int                         `gorm:"default:0;not null" json:"consecutive_failures"`
+	// DisabledAt is set when the webhook was switched off for failing too often
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one webhook, across all its attempts.
+type WebhookDelivery struct {
+	ID             string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	WebhookID      string         `gorm:"type:uuid;not null;uniqueIndex:uq_webhook_deliveries_event" json:"webhook_id"`
+	EventID        string         `gorm:"type:uuid;not null;uniqueIndex:uq_webhook_deliveries_event" json:"event_id"`
+	EventType      string         `gorm:"type:varchar(100);not null" json:"event_type"`
+	Payload        datatypes.JSON `gorm:"type:jsonb;not null" json:"payload" swaggertype:"object"`
+	Status         string         `gorm:"type:varchar(20);default:'pending';not null" json:"status" enums:"pending,succeeded,failed"`
+	Attempts       int            `gorm:"default:0;not null" json:"attempts"`
+	ResponseStatus *int           `json:"response_status,omitempty"`
+	Error          *string        `gorm:"type:text" json:"error,omitempty"`
+	LastAttemptAt  *time.Time     `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,206 @@
+// Package featureflags evaluates the flags declared in the `features` config
+// section, with runtime overrides stored in a Redis hash so flags can be
+// flipped without a deploy.
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Value is either a plain on/off switch or a percentage rollout. A boolean
+// flag is stored as 0 (off) or 100 (on) with Rollout false.
+type Value struct {
+	Percentage int
+	Rollout    bool
+}
+
+func (v Value) String() string {
+	if v.Rollout {
+		return strconv.Itoa(v.Percentage)
+	}
+	return strconv.FormatBool(v.Percentage == 100)
+}
+
+// MarshalJSON renders booleans as true/false and rollouts as a number.
+func (v Value) MarshalJSON() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// ParseValue accepts a bool, a whole number between 0 and 100, or their
+// string forms.
+func ParseValue(raw any) (Value, error) {
+	switch v := raw.(type) {
+	case bool:
+		return boolValue(v), nil
+	case int:
+		return percentValue(v)
+	case int64:
+		return percentValue(int(v))
+	case float64:
+		if v != float64(int(v)) {
+			return Value{}, fmt.Errorf("rollout percentage must be a whole number, got %v", v)
+		}
+		return percentValue(int(v))
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return boolValue(b), nil
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(v, "%"))
+		if err != nil {
+			return Value{}, fmt.Errorf("invalid flag value '%s', expected a bool or a percentage", v)
+		}
+		return percentValue(n)
+	default:
+		return Value{}, fmt.Errorf("invalid flag value %v, expected a bool or a percentage", raw)
+	}
+}
+
+func boolValue(b bool) Value {
+	if b {
+		return Value{Percentage: 100}
+	}
+	return Value{Percentage: 0}
+}
+
+func percentValue(n int) (Value, error) {
+	if n < 0 || n > 100 {
+		return Value{}, fmt.Errorf("rollout percentage must be between 0 and 100, got %d", n)
+	}
+	return Value{Percentage: n, Rollout: true}, nil
+}
+
+// FlagState describes a flag's configured default and runtime override.
+type FlagState struct {
+	Name     string `json:"name"`
+	Default  Value  `json:"default"`
+	Override *Value `json:"override,omitempty"`
+}
+
+type Service struct {
+	defaults    map[string]Value
+	cache       cache.Cache
+	keyBuilder  *cache.CacheKeyBuilder
+	loggedFlags sync.Map
+}
+
+// New builds a Service from the raw `features` config map.
+func New(features map[string]any, c cache.Cache, kb *cache.CacheKeyBuilder) (*Service, error) {
+	defaults := make(map[string]Value, len(features))
+	for name, raw := range features {
+		v, err := ParseValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("feature %s: %w", name, err)
+		}
+		defaults[strings.ToLower(name)] = v
+	}
+
+	return &Service{
+		defaults:   defaults,
+		cache:      c,
+		keyBuilder: kb,
+	}, nil
+}
+
+// Enabled reports whether the flag is on for the current request. Percentage
+// rollouts bucket the user ID from ctx so a user gets a stable answer;
+// requests without a user only see rollouts at 100%. Unknown flags are off.
+func (s *Service) Enabled(ctx context.Context, name string) bool {
+	name = strings.ToLower(name)
+
+	v, ok := s.defaults[name]
+	if !ok {
+		if _, logged := s.loggedFlags.LoadOrStore(name, true); !logged {
+			log.Printf("Unknown feature flag %q evaluated, treating as disabled", name)
+		}
+		return false
+	}
+
+	if override, err := s.override(ctx, name); err == nil && override != nil {
+		v = *override
+	}
+
+	switch v.Percentage {
+	case 0:
+		return false
+	case 100:
+		return true
+	}
+
+	userID, ok := reqctx.UserID(ctx)
+	if !ok {
+		return false
+	}
+	return bucket(name, userID) < v.Percentage
+}
+
+// List returns every configured flag with its override, sorted by name.
+func (s *Service) List(ctx context.Context) ([]FlagState, error) {
+	overrides, err := s.cache.HGetAll(ctx, s.keyBuilder.FeatureOverrides())
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]FlagState, 0, len(s.defaults))
+	for name, def := range s.defaults {
+		state := FlagState{Name: name, Default: def}
+		if raw, ok := overrides[name]; ok {
+			if v, err := ParseValue(raw); err == nil {
+				state.Override = &v
+			}
+		}
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states, nil
+}
+
+// SetOverride stores a runtime override for a configured flag.
+func (s *Service) SetOverride(ctx context.Context, name string, v Value) error {
+	name = strings.ToLower(name)
+	if _, ok := s.defaults[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	return s.cache.HSet(ctx, s.keyBuilder.FeatureOverrides(), name, v.String())
+}
+
+// ClearOverride reverts a flag to its configured default.
+func (s *Service) ClearOverride(ctx context.Context, name string) error {
+	name = strings.ToLower(name)
+	if _, ok := s.defaults[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	return s.cache.HDel(ctx, s.keyBuilder.FeatureOverrides(), name)
+}
+
+func (s *Service) override(ctx context.Context, name string) (*Value, error) {
+	raw, err := s.cache.HGet(ctx, s.keyBuilder.FeatureOverrides(), name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := ParseValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// bucket maps a flag/user pair to a stable number in [0, 100).
+func bucket(flag, userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(flag + ":" + userID))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,193 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	okCheck   = CheckerFunc(func(context.Context) (map[string]any, error) { return nil, nil })
+	downCheck = CheckerFunc(func(context.Context) (map[string]any, error) { return nil, errors.New("connection refused") })
+)
+
+// sleepCheck answers after d, ignoring ctx like a badly behaved client.
+func sleepCheck(d time.Duration) Checker {
+	return CheckerFunc(func(context.Context) (map[string]any, error) {
+		time.Sleep(d)
+		return nil, nil
+	})
+}
+
+// testSource registers the checks it holds.
+type testSource []Check
+
+func (s testSource) HealthChecks() []Check { return s }
+
+func TestRegistryCheck(t *testing.T) {
+	type registered struct {
+		name     string
+		required bool
+		checker  Checker
+	}
+	tests := []struct {
+		name       string
+		checks     []registered
+		timeouts   map[string]time.Duration
+		slow       time.Duration
+		redact     []string
+		wantStatus string
+		// want maps component names to their status and a part of their
+		// error, which is empty for components that must not report one
+		want map[string][2]string
+	}{
+		{"nothing registered", nil, nil, 0, nil, StatusOK, map[string][2]string{}},
+		{"all up", []registered{{"database", true, okCheck}, {"cache", true, okCheck}, {"broker", false, okCheck}}, nil, 0, nil,
+			StatusOK, map[string][2]string{"database": {StatusOK}, "cache": {StatusOK}, "broker": {StatusOK}}},
+		{"optional down", []registered{{"database", true, okCheck}, {"broker", false, downCheck}}, nil, 0, nil,
+			StatusDegraded, map[string][2]string{"database": {StatusOK}, "broker": {StatusDown, "connection refused"}}},
+		{"required down", []registered{{"database", true, downCheck}, {"broker", false, okCheck}}, nil, 0, nil,
+			StatusDown, map[string][2]string{"database": {StatusDown, "connection refused"}, "broker": {StatusOK}}},
+		{"required and optional down", []registered{{"database", true, downCheck}, {"broker", false, downCheck}}, nil, 0, nil,
+			StatusDown, map[string][2]string{"database": {StatusDown, "connection refused"}, "broker": {StatusDown, "connection refused"}}},
+		{"check ignoring its timeout", []registered{{"ml", false, sleepCheck(time.Second)}}, map[string]time.Duration{"ml": 20 * time.Millisecond}, 0, nil,
+			StatusDegraded, map[string][2]string{"ml": {StatusDown, "timed out after 20ms"}}},
+		{"check panics", []registered{{"storage", false, CheckerFunc(func(context.Context) (map[string]any, error) { panic("nil bucket") })}}, nil, 0, nil,
+			StatusDegraded, map[string][2]string{"storage": {StatusDown, "check panicked: nil bucket"}}},
+		{"slow but up", []registered{{"database", true, sleepCheck(30 * time.Millisecond)}}, nil, 10 * time.Millisecond, nil,
+			StatusOK, map[string][2]string{"database": {StatusSlow}}},
+		{"credentials scrubbed", []registered{{"broker", false, CheckerFunc(func(context.Context) (map[string]any, error) {
+			return nil, errors.New("dial amqp://guest:hunter2@mq:5672/ failed, password=hunter2 token=abc")
+		})}}, nil, 0, nil, StatusDegraded, map[string][2]string{"broker": {StatusDown, "amqp://***@mq:5672/ failed, password=*** token=***"}}},
+		{"configured secrets redacted", []registered{{"cache", true, CheckerFunc(func(context.Context) (map[string]any, error) {
+			return nil, errors.New("auth failed for s3cr3t-value")
+		})}}, nil, 0, []string{"s3cr3t-value", ""}, StatusDown, map[string][2]string{"cache": {StatusDown, "auth failed for ***"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(time.Second)
+			r.SetTimeouts(tt.timeouts)
+			r.SetSlowThreshold(tt.slow)
+			r.Redact(tt.redact...)
+			for _, c := range tt.checks {
+				r.Register(c.name, c.required, c.checker)
+			}
+
+			report := r.Check(context.Background())
+
+			if report.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", report.Status, tt.wantStatus)
+			}
+			if len(report.Components) != len(tt.want) {
+				t.Fatalf("got %d components, want %d", len(report.Components), len(tt.want))
+			}
+			for name, want := range tt.want {
+				got, ok := report.Components[name]
+				if !ok {
+					t.Fatalf("component %q missing", name)
+				}
+				if got.Status != want[0] {
+					t.Errorf("%s: status = %q, want %q", name, got.Status, want[0])
+				}
+				if !strings.Contains(got.Error, want[1]) || (want[1] == "" && got.Error != "") {
+					t.Errorf("%s: error = %q, want %q", name, got.Error, want[1])
+				}
+				if got.Status == StatusDown && got.LastSuccessAt != nil {
+					t.Errorf("%s: never passed but reports a last success", name)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryRunsChecksConcurrently(t *testing.T) {
+	r := NewRegistry(time.Second)
+	for _, name := range []string{"database", "cache", "broker", "storage"} {
+		r.Register(name, false, sleepCheck(100*time.Millisecond))
+	}
+
+	start := time.Now()
+	report := r.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("Check() took %s, want the checks to overlap", elapsed)
+	}
+	for name, c := range report.Components {
+		if c.LatencyMs < 100 {
+			t.Errorf("%s: latency %dms, want at least 100ms", name, c.LatencyMs)
+		}
+	}
+}
+
+func TestRegistryAdd(t *testing.T) {
+	var nilSource testSource
+	r := NewRegistry(time.Second)
+	r.SetTimeouts(map[string]time.Duration{"ml": 20 * time.Millisecond})
+	r.Add(
+		testSource{{Name: "database", Required: true, Checker: okCheck}},
+		testSource{
+			{Name: "ml", Checker: sleepCheck(time.Second)},
+			// Its own timeout yields to the configured one
+			{Name: "storage", Timeout: 10 * time.Millisecond, Checker: sleepCheck(time.Second)},
+		},
+		nilSource,
+		"not a source",
+		nil,
+	)
+
+	report := r.Check(context.Background())
+
+	tests := []struct {
+		name         string
+		wantStatus   string
+		wantRequired bool
+		wantError    string
+	}{
+		{"database", StatusOK, true, ""},
+		{"ml", StatusDown, false, "timed out after 20ms"},
+		{"storage", StatusDown, false, "timed out after 10ms"},
+	}
+	if len(report.Components) != len(tests) {
+		t.Fatalf("components = %v, want %d", report.Components, len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := report.Components[tt.name]
+			if got.Status != tt.wantStatus || got.Required != tt.wantRequired || !strings.Contains(got.Error, tt.wantError) || (tt.wantError == "" && got.Error != "") {
+				t.Errorf("component = %+v, want status %q, required %v, error %q", got, tt.wantStatus, tt.wantRequired, tt.wantError)
+			}
+		})
+	}
+	if report.Status != StatusDegraded {
+		t.Errorf("status = %q, want %q", report.Status, StatusDegraded)
+	}
+}
+
+func TestRegistryLastSuccess(t *testing.T) {
+	var failing atomic.Bool
+	r := NewRegistry(time.Second)
+	r.Register("cache", true, CheckerFunc(func(context.Context) (map[string]any, error) {
+		if failing.Load() {
+			return map[string]any{"pool": "exhausted"}, errors.New("timeout")
+		}
+		return nil, nil
+	}))
+
+	first := r.Check(context.Background()).Components["cache"]
+	if first.LastSuccessAt == nil {
+		t.Fatal("passing check without a last success")
+	}
+
+	// A failure keeps the time of the last success and the check's details
+	failing.Store(true)
+	second := r.Check(context.Background()).Components["cache"]
+	if second.LastSuccessAt == nil || !second.LastSuccessAt.Equal(*first.LastSuccessAt) {
+		t.Errorf("last success = %v after a failure, want %v", second.LastSuccessAt, first.LastSuccessAt)
+	}
+	if second.Details["pool"] != "exhausted" {
+		t.Errorf("details = %v, want the failing check's details", second.Details)
+	}
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Readiness states
+const (
+	StateStarting     = "starting"
+	StateReady        = "ready"
+	StateShuttingDown = "shutting_down"
+)
+
+// Readiness tells load balancers whether to route traffic to this instance.
+// It starts out not ready, becomes ready once startup completes, and stops
+// being ready as soon as shutdown begins so the instance is drained.
+type Readiness struct {
+	state atomic.Value
+}
+
+func NewReadiness() *Readiness {
+	r := &Readiness{}
+	r.state.Store(StateStarting)
+	return r
+}
+
+func (r *Readiness) State() string {
+	return r.state.Load().(string)
+}
+
+func (r *Readiness) Ready() bool {
+	return r.State() == StateReady
+}
+
+// MarkReady flips the gate to ready, unless shutdown has already begun.
+func (r *Readiness) MarkReady() {
+	r.state.CompareAndSwap(StateStarting, StateReady)
+}
+
+func (r *Readiness) MarkShuttingDown() {
+	r.state.Store(StateShuttingDown)
+}
+
+// WarmUp waits until every required check in checks and every gate passes,
+// then for the warmup period, and marks r ready. Gates are conditions only
+// startup waits for, such as a warmed-up connection pool. It gives up when
+// ctx is cancelled.
+func (r *Readiness) WarmUp(ctx context.Context, checks *Registry, warmup time.Duration, gates ...Checker) {
+	const retryInterval = time.Second
+
+	for {
+		report := checks.Check(ctx)
+		if report.Status != StatusDown {
+			err := passGates(ctx, gates)
+			if err == nil {
+				break
+			}
+			log.Printf("Waiting for startup to complete before accepting traffic: %v", err)
+		} else {
+			log.Printf("Waiting for required dependencies before accepting traffic")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+
+	if warmup > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(warmup):
+		}
+	}
+
+	r.MarkReady()
+	if r.Ready() {
+		log.Printf("Instance is ready")
+	}
+}
+
+func passGates(ctx context.Context, gates []Checker) error {
+	for _, gate := range gates {
+		if _, err := gate.Check(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
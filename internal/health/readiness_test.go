@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadinessStates(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []func(r *Readiness)
+		want  string
+	}{
+		{"new", nil, StateStarting},
+		{"ready", []func(*Readiness){(*Readiness).MarkReady}, StateReady},
+		{"shutting down", []func(*Readiness){(*Readiness).MarkReady, (*Readiness).MarkShuttingDown}, StateShuttingDown},
+		{"shutting down before ready", []func(*Readiness){(*Readiness).MarkShuttingDown}, StateShuttingDown},
+		// A late end of startup must not put a draining instance back in rotation
+		{"ready after shutdown began", []func(*Readiness){(*Readiness).MarkShuttingDown, (*Readiness).MarkReady}, StateShuttingDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReadiness()
+			for _, step := range tt.steps {
+				step(r)
+			}
+			if got := r.State(); got != tt.want {
+				t.Fatalf("State() = %q, want %q", got, tt.want)
+			}
+			if r.Ready() != (tt.want == StateReady) {
+				t.Errorf("Ready() = %v in state %q", r.Ready(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	up := CheckerFunc(func(context.Context) (map[string]any, error) { return nil, nil })
+	down := CheckerFunc(func(context.Context) (map[string]any, error) { return nil, errors.New("connection refused") })
+
+	t.Run("ready once checks and gates pass", func(t *testing.T) {
+		checks := NewRegistry(time.Second)
+		checks.Register("database", true, up)
+		// An optional dependency being down doesn't hold up startup
+		checks.Register("broker", false, down)
+
+		// The gate passes on its second attempt
+		var attempts atomic.Int32
+		gate := CheckerFunc(func(context.Context) (map[string]any, error) {
+			if attempts.Add(1) < 2 {
+				return nil, errors.New("pool not warmed up")
+			}
+			return nil, nil
+		})
+
+		r := NewReadiness()
+		done := make(chan struct{})
+		go func() {
+			r.WarmUp(context.Background(), checks, 10*time.Millisecond, gate)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("WarmUp() didn't return")
+		}
+		if !r.Ready() || attempts.Load() != 2 {
+			t.Fatalf("state = %q after %d gate attempts, want ready after 2", r.State(), attempts.Load())
+		}
+	})
+
+	t.Run("required dependency down", func(t *testing.T) {
+		checks := NewRegistry(time.Second)
+		checks.Register("database", true, down)
+
+		r := NewReadiness()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		r.WarmUp(ctx, checks, 0)
+
+		if got := r.State(); got != StateStarting {
+			t.Fatalf("State() = %q, want %q", got, StateStarting)
+		}
+	})
+}
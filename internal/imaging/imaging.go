@@ -0,0 +1,96 @@
+// Package imaging decodes untrusted images and produces resized copies of
+// them. Decoding checks the declared dimensions first, so a small file that
+// expands to an enormous bitmap is rejected before any pixels are allocated.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	// Registered decoders
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for data that isn't a JPEG, PNG, GIF
+	// or WebP image
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+
+	// ErrInvalidImage is returned when the image header or pixel data is
+	// corrupt
+	ErrInvalidImage = errors.New("invalid image")
+
+	// ErrTooManyPixels is returned when the image's dimensions exceed the
+	// allowed pixel count
+	ErrTooManyPixels = errors.New("image dimensions too large")
+)
+
+// Image is a decoded image with its EXIF orientation already applied.
+type Image struct {
+	image.Image
+	// Format is the name of the decoder used, e.g. "jpeg"
+	Format string
+}
+
+// Decode decodes data, refusing images of more than maxPixels pixels. Only
+// the first frame of an animated GIF or WebP is kept. Metadata is dropped
+// apart from the EXIF orientation, which is applied to the pixels.
+func Decode(data []byte, maxPixels int) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if maxPixels > 0 && cfg.Width > maxPixels/cfg.Height {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooManyPixels, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	return &Image{Image: img, Format: format}, nil
+}
+
+// Square center-crops img to a square and scales it to size x size pixels.
+// Transparent areas are flattened onto white, since the result is meant to
+// be encoded as JPEG.
+func Square(img image.Image, size int) *image.RGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		b.Min.X+(b.Dx()-side)/2,
+		b.Min.Y+(b.Dy()-side)/2,
+	))
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Over, nil)
+	return dst
+}
+
+// EncodeJPEG writes img as a baseline JPEG without metadata.
+func EncodeJPEG(w io.Writer, img image.Image, quality int) error {
+	if err := jpeg.Encode(w, img, &jpeg.Options{Quality: quality}); err != nil {
+		return fmt.Errorf("failed to encode jpeg: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{255, 0, 0, 255}
+	blue = color.RGBA{0, 0, 255, 255}
+)
+
+// halves returns a w x h image whose left half is left and right half right.
+func halves(w, h int, left, right color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, left)
+			} else {
+				img.Set(x, y, right)
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// exifJPEG encodes img as a JPEG carrying the given EXIF orientation.
+func exifJPEG(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Big-endian TIFF header with one IFD holding the orientation tag
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = append(tiff, 0, 3, 0, 0, 0, 1) // SHORT, count 1
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // padding, no next IFD
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(payload)+2))
+	app1 = append(app1, payload...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// near reports whether c is within a JPEG's rounding of want.
+func near(c color.Color, want color.RGBA) bool {
+	r, g, b, _ := c.RGBA()
+	diff := func(a uint32, b uint8) bool {
+		d := int(a>>8) - int(b)
+		return d > -40 && d < 40
+	}
+	return diff(r, want.R) && diff(g, want.G) && diff(b, want.B)
+}
+
+func TestDecode(t *testing.T) {
+	animated := &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{red, blue}),
+			image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{blue, red}),
+		},
+		Delay: []int{10, 10},
+	}
+	var gifBuf bytes.Buffer
+	if err := gif.EncodeAll(&gifBuf, animated); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := encodePNG(t, halves(40, 20, red, blue))
+
+	tests := []struct {
+		name       string
+		data       []byte
+		maxPixels  int
+		wantErr    error
+		wantFormat string
+		wantSize   image.Point
+	}{
+		{"png", valid, 1000, nil, "png", image.Pt(40, 20)},
+		{"at the pixel limit", valid, 800, nil, "png", image.Pt(40, 20)},
+		{"over the pixel limit", valid, 799, ErrTooManyPixels, "", image.Point{}},
+		{"no pixel limit", valid, 0, nil, "png", image.Pt(40, 20)},
+		{"animated gif keeps the first frame", gifBuf.Bytes(), 1000, nil, "gif", image.Pt(4, 4)},
+		{"text", []byte("<html><script>alert(1)</script></html>"), 1000, ErrUnsupportedFormat, "", image.Point{}},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 1000, ErrUnsupportedFormat, "", image.Point{}},
+		{"empty", nil, 1000, ErrUnsupportedFormat, "", image.Point{}},
+		{"truncated header", valid[:20], 1000, ErrInvalidImage, "", image.Point{}},
+		{"truncated pixels", valid[:len(valid)-30], 1000, ErrInvalidImage, "", image.Point{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Decode(tt.data, tt.maxPixels)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if img.Format != tt.wantFormat || img.Bounds().Size() != tt.wantSize {
+				t.Errorf("Decode() = %s %v, want %s %v", img.Format, img.Bounds().Size(), tt.wantFormat, tt.wantSize)
+			}
+		})
+	}
+
+	t.Run("first gif frame", func(t *testing.T) {
+		img, err := Decode(gifBuf.Bytes(), 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !near(img.At(0, 0), red) {
+			t.Errorf("pixel = %v, want the first frame's red", img.At(0, 0))
+		}
+	})
+}
+
+func TestDecodeBombRejectedBeforeDecoding(t *testing.T) {
+	// A PNG header claiming 100000x100000 pixels with no pixel data: only
+	// the header may be read
+	data := encodePNG(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+	binary.BigEndian.PutUint32(data[16:], 100000)
+	binary.BigEndian.PutUint32(data[20:], 100000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, err := Decode(data, 10_000_000); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Decode() error = %v, want %v", err, ErrTooManyPixels)
+	}
+}
+
+func TestDecodeOrientation(t *testing.T) {
+	// 32x16: red on the left, blue on the right
+	src := halves(32, 16, red, blue)
+
+	tests := []struct {
+		name        string
+		orientation uint16
+		wantSize    image.Point
+		// where the red and blue halves end up
+		redAt, blueAt image.Point
+	}{
+		{"upright", 1, image.Pt(32, 16), image.Pt(4, 8), image.Pt(28, 8)},
+		{"mirrored", 2, image.Pt(32, 16), image.Pt(28, 8), image.Pt(4, 8)},
+		{"rotated 180", 3, image.Pt(32, 16), image.Pt(28, 8), image.Pt(4, 8)},
+		{"rotated 90 clockwise", 6, image.Pt(16, 32), image.Pt(8, 4), image.Pt(8, 28)},
+		{"rotated 90 counter-clockwise", 8, image.Pt(16, 32), image.Pt(8, 28), image.Pt(8, 4)},
+		{"invalid orientation", 9, image.Pt(32, 16), image.Pt(4, 8), image.Pt(28, 8)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Decode(exifJPEG(t, src, tt.orientation), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.Bounds().Size(); got != tt.wantSize {
+				t.Fatalf("size = %v, want %v", got, tt.wantSize)
+			}
+			if !near(img.At(tt.redAt.X, tt.redAt.Y), red) || !near(img.At(tt.blueAt.X, tt.blueAt.Y), blue) {
+				t.Errorf("pixels at %v and %v = %v and %v, want red and blue",
+					tt.redAt, tt.blueAt, img.At(tt.redAt.X, tt.redAt.Y), img.At(tt.blueAt.X, tt.blueAt.Y))
+			}
+		})
+	}
+}
+
+func TestSquare(t *testing.T) {
+	// 60x20 in three bands: only the middle one survives the center crop
+	img := image.NewRGBA(image.Rect(0, 0, 60, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 60; x++ {
+			c := blue
+			if x >= 20 && x < 40 {
+				c = red
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	got := Square(img, 10)
+	if got.Bounds().Size() != image.Pt(10, 10) {
+		t.Fatalf("size = %v, want 10x10", got.Bounds().Size())
+	}
+	for _, p := range []image.Point{{0, 0}, {9, 9}, {5, 5}} {
+		if !near(got.At(p.X, p.Y), red) {
+			t.Errorf("pixel %v = %v, want the red center", p, got.At(p.X, p.Y))
+		}
+	}
+
+	// Transparency is flattened onto white
+	transparent := Square(image.NewRGBA(image.Rect(0, 0, 4, 4)), 2)
+	if !near(transparent.At(0, 0), color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("transparent pixel = %v, want white", transparent.At(0, 0))
+	}
+}
+
+func TestEncodeJPEGStripsMetadata(t *testing.T) {
+	img, err := Decode(exifJPEG(t, halves(16, 16, red, blue), 6), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeJPEG(&buf, img, 85); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("Exif")) {
+		t.Error("encoded JPEG still carries EXIF data")
+	}
+	if got := jpegOrientation(buf.Bytes()); got != orientationTopLeft {
+		t.Errorf("orientation = %d, want %d", got, orientationTopLeft)
+	}
+}
@@ -2,9 +2,13 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrKeyNotFound is returned (wrapped) when a key does not exist
+var ErrKeyNotFound = errors.New("key not found")
+
 // Cache defines the interface for cache operations
 type Cache interface {
 	// Get retrieves a value from cache
@@ -13,12 +17,22 @@ type Cache interface {
 	// Set stores a value in cache with optional TTL
 	Set(ctx context.Context, key string, value any, ttl time.Duration) error
 
-	// Delete removes a key from cache
+	// SetNX stores a value only if key does not exist yet, reporting whether
+	// it was set
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+
+	// GetDel atomically retrieves a value and removes the key
+	GetDel(ctx context.Context, key string) (string, error)
+
+	// Delete removes keys from cache; no keys is a no-op
 	Delete(ctx context.Context, keys ...string) error
 
-	// Exists checks if a key exists
+	// Exists counts how many of keys exist; no keys returns 0
 	Exists(ctx context.Context, keys ...string) (int64, error)
 
+	// ExistsMap reports for each of keys whether it exists
+	ExistsMap(ctx context.Context, keys ...string) (map[string]bool, error)
+
 	// Expire sets an expiration on a key
 	Expire(ctx context.Context, key string, ttl time.Duration) error
 
@@ -28,6 +42,9 @@ type Cache interface {
 	// Increment increments a key's value by 1
 	Increment(ctx context.Context, key string) (int64, error)
 
+	// IncrementBy increments a key's value by n
+	IncrementBy(ctx context.Context, key string, n int64) (int64, error)
+
 	// Decrement decrements a key's value by 1
 	Decrement(ctx context.Context, key string) (int64, error)
 
@@ -37,6 +54,51 @@ type Cache interface {
 	// MSet sets multiple key-value pairs
 	MSet(ctx context.Context, pairs map[string]any) error
 
+	// HGet retrieves a single field of a hash
+	HGet(ctx context.Context, key, field string) (string, error)
+
+	// HGetAll retrieves every field of a hash
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+
+	// HSet sets a single field of a hash
+	HSet(ctx context.Context, key, field string, value any) error
+
+	// HIncrBy adds n to the integer in a field of a hash and returns the
+	// new value
+	HIncrBy(ctx context.Context, key, field string, n int64) (int64, error)
+
+	// HDel removes fields from a hash
+	HDel(ctx context.Context, key string, fields ...string) error
+
+	// SAdd adds members to a set
+	SAdd(ctx context.Context, key string, members ...any) error
+
+	// SMembers returns every member of a set
+	SMembers(ctx context.Context, key string) ([]string, error)
+
+	// SRem removes members from a set
+	SRem(ctx context.Context, key string, members ...any) error
+
+	// LPush prepends values to a list
+	LPush(ctx context.Context, key string, values ...any) error
+
+	// LTrim keeps only the elements of a list between start and stop
+	LTrim(ctx context.Context, key string, start, stop int64) error
+
+	// LRange returns the elements of a list between start and stop
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+
+	// Publish sends a message to every subscriber of channel
+	Publish(ctx context.Context, channel string, message any) error
+
+	// Subscribe listens for messages published to channel until the
+	// subscription is closed
+	Subscribe(ctx context.Context, channel string) (Subscription, error)
+
+	// Scan returns up to limit keys matching the glob pattern, walking the
+	// keyspace incrementally rather than blocking the server like KEYS
+	Scan(ctx context.Context, pattern string, limit int) ([]string, error)
+
 	// FlushAll clears all keys (use with caution!)
 	FlushAll(ctx context.Context) error
 
@@ -46,3 +108,12 @@ type Cache interface {
 	// Close closes the cache connection
 	Close() error
 }
+
+// Subscription is an open pub/sub subscription.
+type Subscription interface {
+	// Messages delivers published payloads; it is closed after Close
+	Messages() <-chan string
+
+	// Close ends the subscription
+	Close() error
+}
@@ -0,0 +1,681 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisCache returns a RedisCache talking to an in-process miniredis.
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	host, port, _ := net.SplitHostPort(mr.Addr())
+
+	c := OpenRedisCache(&config.Config{Redis: config.RedisConfig{Host: host, Port: port, PoolSize: 10}})
+	t.Cleanup(func() { c.Close() })
+	return c, mr
+}
+
+// forEachCache runs fn against the memory cache and against Redis, so both
+// implementations are held to the same behavior.
+func forEachCache(t *testing.T, fn func(t *testing.T, c Cache)) {
+	t.Run("memory", func(t *testing.T) {
+		c := NewMemoryCache()
+		t.Cleanup(func() { c.Close() })
+		fn(t, c)
+	})
+	t.Run("redis", func(t *testing.T) {
+		c, _ := newTestRedisCache(t)
+		fn(t, c)
+	})
+}
+
+// noGetDelHook makes the server look older than Redis 6.2 by rejecting
+// GETDEL as unknown, counting the attempts.
+type noGetDelHook struct {
+	attempts atomic.Int64
+}
+
+func (h *noGetDelHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *noGetDelHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "getdel" {
+			h.attempts.Add(1)
+			cmd.SetErr(errors.New("ERR unknown command 'getdel', with args beginning with:"))
+			return cmd.Err()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *noGetDelHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestGetDel(t *testing.T) {
+	ctx := context.Background()
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		if err := c.Set(ctx, "token", "session", 0); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name    string
+			want    string
+			wantErr error
+		}{
+			{"first call returns the value", "session", nil},
+			{"second call finds nothing", "", ErrKeyNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := c.GetDel(ctx, "token")
+				if got != tt.want || !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetDel() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+				}
+			})
+		}
+
+		if n, err := c.Exists(ctx, "token"); err != nil || n != 0 {
+			t.Errorf("Exists() after GetDel = %d, %v, want 0", n, err)
+		}
+	})
+}
+
+func TestGetDelConcurrent(t *testing.T) {
+	ctx := context.Background()
+	const callers = 20
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		if err := c.Set(ctx, "token", "session", 0); err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		var winners atomic.Int64
+		for range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.GetDel(ctx, "token"); err == nil {
+					winners.Add(1)
+				} else if !errors.Is(err, ErrKeyNotFound) {
+					t.Errorf("GetDel() = %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got := winners.Load(); got != 1 {
+			t.Fatalf("%d callers consumed the key, want exactly 1", got)
+		}
+	})
+}
+
+func TestGetDelFallback(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestRedisCache(t)
+	hook := &noGetDelHook{}
+	c.client.AddHook(hook)
+
+	for _, key := range []string{"a", "b"} {
+		if err := c.Set(ctx, key, "value-"+key, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr error
+	}{
+		{"a", "value-a", nil},
+		{"a", "", ErrKeyNotFound},
+		{"b", "value-b", nil},
+	}
+	for _, tt := range tests {
+		got, err := c.GetDel(ctx, tt.key)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Fatalf("GetDel(%s) = %q, %v, want %q, %v", tt.key, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if n, err := c.Exists(ctx, "a", "b"); err != nil || n != 0 {
+		t.Errorf("Exists() after GetDel = %d, %v, want 0", n, err)
+	}
+	// GETDEL is only tried until the server first rejects it
+	if got := hook.attempts.Load(); got != 1 {
+		t.Errorf("GETDEL sent %d times, want 1", got)
+	}
+}
+
+func TestSetNX(t *testing.T) {
+	ctx := context.Background()
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		steps := []struct {
+			name      string
+			run       func() (bool, error)
+			wantSet   bool
+			wantValue string
+		}{
+			{"first set", func() (bool, error) { return c.SetNX(ctx, "lock", "owner-1", time.Minute) }, true, "owner-1"},
+			{"second set on the same key", func() (bool, error) { return c.SetNX(ctx, "lock", "owner-2", time.Minute) }, false, "owner-1"},
+			{"after delete", func() (bool, error) {
+				if err := c.Delete(ctx, "lock"); err != nil {
+					return false, err
+				}
+				return c.SetNX(ctx, "lock", "owner-3", time.Minute)
+			}, true, "owner-3"},
+		}
+		for _, step := range steps {
+			set, err := step.run()
+			if err != nil {
+				t.Fatalf("%s: %v", step.name, err)
+			}
+			if set != step.wantSet {
+				t.Errorf("%s: SetNX() = %v, want %v", step.name, set, step.wantSet)
+			}
+			if got, err := c.Get(ctx, "lock"); err != nil || got != step.wantValue {
+				t.Errorf("%s: Get() = %q, %v, want %q", step.name, got, err, step.wantValue)
+			}
+		}
+
+		// A key set with Set counts as present too
+		if err := c.Set(ctx, "plain", "value", 0); err != nil {
+			t.Fatal(err)
+		}
+		if set, err := c.SetNX(ctx, "plain", "other", 0); err != nil || set {
+			t.Errorf("SetNX() on a key set with Set = %v, %v, want false", set, err)
+		}
+
+		if ttl, err := c.TTL(ctx, "lock"); err != nil || ttl <= 0 || ttl > time.Minute {
+			t.Errorf("TTL() = %v, %v, want up to a minute", ttl, err)
+		}
+	})
+}
+
+func TestSetNXConcurrent(t *testing.T) {
+	ctx := context.Background()
+	const callers = 20
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		var wg sync.WaitGroup
+		var winners atomic.Int64
+		for i := range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				set, err := c.SetNX(ctx, "idempotency", i, time.Minute)
+				if err != nil {
+					t.Error(err)
+				}
+				if set {
+					winners.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got := winners.Load(); got != 1 {
+			t.Fatalf("%d callers set the key, want exactly 1", got)
+		}
+	})
+}
+
+func TestExistsMap(t *testing.T) {
+	ctx := context.Background()
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		if err := c.Set(ctx, "present", "1", 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.HSet(ctx, "hash", "field", "1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SAdd(ctx, "set", "member"); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name string
+			keys []string
+			want map[string]bool
+		}{
+			{"no keys", nil, map[string]bool{}},
+			{"all absent", []string{"a", "b"}, map[string]bool{"a": false, "b": false}},
+			{"mixed", []string{"present", "absent", "hash", "set"}, map[string]bool{"present": true, "absent": false, "hash": true, "set": true}},
+			{"repeated key", []string{"present", "present"}, map[string]bool{"present": true}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := c.ExistsMap(ctx, tt.keys...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("ExistsMap() = %v, want %v", got, tt.want)
+				}
+				for key, want := range tt.want {
+					if present, ok := got[key]; !ok || present != want {
+						t.Errorf("ExistsMap()[%s] = %v, %v, want %v", key, present, ok, want)
+					}
+				}
+
+				// Exists still counts across the keys
+				var wantCount int64
+				for _, key := range tt.keys {
+					if tt.want[key] {
+						wantCount++
+					}
+				}
+				if n, err := c.Exists(ctx, tt.keys...); err != nil || n != wantCount {
+					t.Errorf("Exists() = %d, %v, want %d", n, err, wantCount)
+				}
+			})
+		}
+	})
+}
+
+func TestDeleteKeys(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		delete []string
+		// wantLeft are the keys of a, b and c still present afterwards
+		wantLeft []string
+	}{
+		{"no keys", nil, []string{"a", "b", "c"}},
+		{"one key", []string{"a"}, []string{"b", "c"}},
+		{"several keys", []string{"a", "c"}, []string{"b"}},
+		{"absent keys", []string{"x", "y"}, []string{"a", "b", "c"}},
+		{"present and absent", []string{"b", "x"}, []string{"a", "c"}},
+	}
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				for _, key := range []string{"a", "b", "c"} {
+					if err := c.Set(ctx, key, "1", 0); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				if err := c.Delete(ctx, tt.delete...); err != nil {
+					t.Fatalf("Delete(%v) error = %v", tt.delete, err)
+				}
+
+				n, err := c.Exists(ctx, "a", "b", "c")
+				if err != nil || n != int64(len(tt.wantLeft)) {
+					t.Errorf("Exists() = %d, %v, want %d", n, err, len(tt.wantLeft))
+				}
+				for _, key := range tt.wantLeft {
+					if n, err := c.Exists(ctx, key); err != nil || n != 1 {
+						t.Errorf("Exists(%s) = %d, %v, want 1", key, n, err)
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestEmptyKeysSkipServer(t *testing.T) {
+	ctx := context.Background()
+	c, mr := newTestRedisCache(t)
+	// Without keys neither call reaches the server, so they succeed even
+	// with nothing to talk to
+	mr.Close()
+
+	if err := c.Delete(ctx); err != nil {
+		t.Errorf("Delete() with no keys error = %v", err)
+	}
+	if n, err := c.Exists(ctx); err != nil || n != 0 {
+		t.Errorf("Exists() with no keys = %d, %v, want 0, nil", n, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := c.Delete(ctx, "a"); err == nil {
+		t.Error("Delete(a) with the server down succeeded")
+	}
+}
+
+// forEachCacheTimed is forEachCache with a way to let time pass: the memory
+// cache runs on the wall clock, miniredis on a clock the test moves.
+func forEachCacheTimed(t *testing.T, fn func(t *testing.T, c Cache, advance func(time.Duration))) {
+	t.Run("memory", func(t *testing.T) {
+		c := NewMemoryCache()
+		t.Cleanup(func() { c.Close() })
+		fn(t, c, time.Sleep)
+	})
+	t.Run("redis", func(t *testing.T) {
+		c, mr := newTestRedisCache(t)
+		fn(t, c, mr.FastForward)
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	forEachCacheTimed(t, func(t *testing.T, c Cache, advance func(time.Duration)) {
+		for key, ttl := range map[string]time.Duration{"short": time.Second, "long": time.Hour, "forever": 0} {
+			if err := c.Set(ctx, key, "value", ttl); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := c.HSet(ctx, "hash", "field", "value"); err != nil {
+			t.Fatal(err)
+		}
+		// Expire works in whole seconds on Redis
+		if err := c.Expire(ctx, "hash", time.Second); err != nil {
+			t.Fatal(err)
+		}
+		advance(1100 * time.Millisecond)
+
+		tests := []struct {
+			key     string
+			present bool
+			wantTTL time.Duration
+		}{
+			{"short", false, -2},
+			{"hash", false, -2},
+			{"missing", false, -2},
+			{"long", true, time.Hour},
+			{"forever", true, -1},
+		}
+		for _, tt := range tests {
+			t.Run(tt.key, func(t *testing.T) {
+				if n, err := c.Exists(ctx, tt.key); err != nil || (n == 1) != tt.present {
+					t.Errorf("Exists() = %d, %v, want present %v", n, err, tt.present)
+				}
+				ttl, err := c.TTL(ctx, tt.key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// TTL is reported in whole seconds
+				if tt.wantTTL > 0 && (ttl > tt.wantTTL || ttl < tt.wantTTL-2*time.Second) {
+					t.Errorf("TTL() = %v, want about %v", ttl, tt.wantTTL)
+				} else if tt.wantTTL < 0 && ttl != tt.wantTTL {
+					t.Errorf("TTL() = %v, want %v", ttl, tt.wantTTL)
+				}
+			})
+		}
+
+		if _, err := c.Get(ctx, "short"); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("Get() of an expired key = %v, want ErrKeyNotFound", err)
+		}
+		// An expired counter starts over
+		if n, err := c.Increment(ctx, "short"); err != nil || n != 1 {
+			t.Errorf("Increment() of an expired key = %d, %v, want 1", n, err)
+		}
+		// Expire with no time left removes the key
+		if err := c.Expire(ctx, "forever", 0); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := c.Exists(ctx, "forever"); err != nil || n != 0 {
+			t.Errorf("Exists() after Expire(0) = %d, %v, want 0", n, err)
+		}
+	})
+}
+
+func TestIncrement(t *testing.T) {
+	ctx := context.Background()
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		if err := c.Set(ctx, "text", "abc", 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Set(ctx, "expiring", "5", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SAdd(ctx, "set", "member"); err != nil {
+			t.Fatal(err)
+		}
+
+		steps := []struct {
+			name    string
+			run     func() (int64, error)
+			want    int64
+			wantErr bool
+		}{
+			{"increment a missing key", func() (int64, error) { return c.Increment(ctx, "counter") }, 1, false},
+			{"increment again", func() (int64, error) { return c.Increment(ctx, "counter") }, 2, false},
+			{"increment by n", func() (int64, error) { return c.IncrementBy(ctx, "counter", 5) }, 7, false},
+			{"increment by a negative n", func() (int64, error) { return c.IncrementBy(ctx, "counter", -10) }, -3, false},
+			{"decrement a missing key", func() (int64, error) { return c.Decrement(ctx, "down") }, -1, false},
+			{"decrement again", func() (int64, error) { return c.Decrement(ctx, "down") }, -2, false},
+			{"increment an expiring key", func() (int64, error) { return c.Increment(ctx, "expiring") }, 6, false},
+			{"increment text", func() (int64, error) { return c.Increment(ctx, "text") }, 0, true},
+			{"increment a set", func() (int64, error) { return c.Increment(ctx, "set") }, 0, true},
+		}
+		for _, step := range steps {
+			got, err := step.run()
+			if (err != nil) != step.wantErr || got != step.want {
+				t.Errorf("%s = %d, %v, want %d, error %v", step.name, got, err, step.want, step.wantErr)
+			}
+		}
+
+		// Counters are plain strings and keep their expiry
+		if got, err := c.Get(ctx, "counter"); err != nil || got != "-3" {
+			t.Errorf("Get(counter) = %q, %v, want -3", got, err)
+		}
+		if ttl, err := c.TTL(ctx, "expiring"); err != nil || ttl <= 0 {
+			t.Errorf("TTL() after Increment = %v, %v, want the expiry kept", ttl, err)
+		}
+		if got, err := c.Get(ctx, "text"); err != nil || got != "abc" {
+			t.Errorf("Get(text) after a failed Increment = %q, %v, want abc", got, err)
+		}
+	})
+}
+
+func TestMGetMSet(t *testing.T) {
+	ctx := context.Background()
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		if err := c.Set(ctx, "a", "old", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.HSet(ctx, "hash", "field", "value"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.MSet(ctx, map[string]any{"a": "1", "b": 2, "c": true, "d": ""}); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name string
+			keys []string
+			want []any
+		}{
+			{"present", []string{"a", "b"}, []any{"1", "2"}},
+			{"formatted values", []string{"c", "d"}, []any{"1", ""}},
+			{"missing keys are nil", []string{"a", "missing", "b"}, []any{"1", nil, "2"}},
+			{"other kinds are nil", []string{"hash", "a"}, []any{nil, "1"}},
+			{"repeated key", []string{"a", "a"}, []any{"1", "1"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := c.MGet(ctx, tt.keys...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("MGet() = %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Errorf("MGet()[%d] = %#v, want %#v", i, got[i], tt.want[i])
+					}
+				}
+			})
+		}
+
+		// MSet overwrites the value and its expiry
+		if ttl, err := c.TTL(ctx, "a"); err != nil || ttl != -1 {
+			t.Errorf("TTL() after MSet = %v, %v, want -1", ttl, err)
+		}
+		if err := c.MSet(ctx, map[string]any{}); err == nil {
+			t.Error("MSet() without pairs succeeded, want an error")
+		}
+	})
+}
+
+func TestWrongType(t *testing.T) {
+	ctx := context.Background()
+
+	forEachCache(t, func(t *testing.T, c Cache) {
+		if err := c.Set(ctx, "string", "value", 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SAdd(ctx, "set", "member"); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name string
+			call func() error
+		}{
+			{"Get on a set", func() error { _, err := c.Get(ctx, "set"); return err }},
+			{"HGet on a string", func() error { _, err := c.HGet(ctx, "string", "field"); return err }},
+			{"HSet on a string", func() error { return c.HSet(ctx, "string", "field", "value") }},
+			{"SAdd on a string", func() error { return c.SAdd(ctx, "string", "member") }},
+			{"LPush on a set", func() error { return c.LPush(ctx, "set", "value") }},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := tt.call()
+				if err == nil || errors.Is(err, ErrKeyNotFound) {
+					t.Fatalf("%s = %v, want a wrong type error", tt.name, err)
+				}
+				if !strings.Contains(err.Error(), "WRONGTYPE") {
+					t.Errorf("%s = %v, want the WRONGTYPE error", tt.name, err)
+				}
+			})
+		}
+
+		// Set replaces a value of any kind
+		if err := c.Set(ctx, "set", "value", 0); err != nil {
+			t.Fatalf("Set() over a set = %v", err)
+		}
+	})
+}
+
+func TestReconnect(t *testing.T) {
+	tests := []struct {
+		name        string
+		redisReturn bool
+		wantUp      bool
+	}{
+		{"redis comes back", true, true},
+		{"stopped while redis is down", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mr := newTestRedisCache(t)
+			mr.Close()
+
+			up := &atomic.Bool{}
+			stop := Reconnect(c, 10*time.Millisecond, up)
+
+			time.Sleep(50 * time.Millisecond)
+			if up.Load() {
+				t.Fatal("up set while redis is down")
+			}
+
+			if tt.redisReturn {
+				if err := mr.Restart(); err != nil {
+					t.Fatal(err)
+				}
+				deadline := time.Now().Add(2 * time.Second)
+				for !up.Load() && time.Now().Before(deadline) {
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+			stop()
+
+			if up.Load() != tt.wantUp {
+				t.Errorf("up = %v, want %v", up.Load(), tt.wantUp)
+			}
+		})
+	}
+}
+
+// failHook fails every command with err before it reaches the server.
+type failHook struct {
+	err error
+}
+
+func (h failHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h failHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		cmd.SetErr(h.err)
+		return h.err
+	}
+}
+
+func (h failHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			cmd.SetErr(h.err)
+		}
+		return h.err
+	}
+}
+
+func TestRedisErrorWrapping(t *testing.T) {
+	ctx := context.Background()
+	errBackend := errors.New("LOADING Redis is loading the dataset in memory")
+	c, _ := newTestRedisCache(t)
+	c.client.AddHook(failHook{err: errBackend})
+
+	tests := []struct {
+		name       string
+		call       func() error
+		wantPrefix string
+	}{
+		{"Get", func() error { _, err := c.Get(ctx, "k"); return err }, "failed to get key k: "},
+		{"GetDel", func() error { _, err := c.GetDel(ctx, "k"); return err }, "failed to get and delete key k: "},
+		{"Set", func() error { return c.Set(ctx, "k", "v", 0) }, "failed to set key k: "},
+		{"SetNX", func() error { _, err := c.SetNX(ctx, "k", "v", 0); return err }, "failed to set key k if absent: "},
+		{"Delete", func() error { return c.Delete(ctx, "a", "b") }, "failed to delete keys [a b]: "},
+		{"Exists", func() error { _, err := c.Exists(ctx, "a", "b"); return err }, "failed to check if keys [a b] exist: "},
+		{"Expire", func() error { return c.Expire(ctx, "k", time.Minute) }, "failed to set expiration on key k: "},
+		{"TTL", func() error { _, err := c.TTL(ctx, "k"); return err }, "failed to get TTL of key k: "},
+		{"Increment", func() error { _, err := c.Increment(ctx, "k"); return err }, "failed to increment key k: "},
+		{"Decrement", func() error { _, err := c.Decrement(ctx, "k"); return err }, "failed to decrement key k: "},
+		{"MGet", func() error { _, err := c.MGet(ctx, "a", "b"); return err }, "failed to get multiple keys: "},
+		{"HGet", func() error { _, err := c.HGet(ctx, "h", "f"); return err }, "failed to get hash field h[f]: "},
+		{"HSet", func() error { return c.HSet(ctx, "h", "f", "v") }, "failed to set hash field h[f]: "},
+		{"SAdd", func() error { return c.SAdd(ctx, "s", "m") }, "failed to add set members to s: "},
+		{"Ping", func() error { return c.Ping(ctx) }, "failed to ping Redis: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil {
+				t.Fatal("call succeeded, want an error")
+			}
+			if !strings.HasPrefix(err.Error(), tt.wantPrefix) || !strings.HasSuffix(err.Error(), errBackend.Error()) {
+				t.Errorf("error = %q, want %q followed by the cause", err, tt.wantPrefix)
+			}
+			if errors.Unwrap(err) != errBackend {
+				t.Errorf("errors.Unwrap(%q) = %v, want the Redis error", err, errors.Unwrap(err))
+			}
+		})
+	}
+}
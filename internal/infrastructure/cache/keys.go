@@ -1,6 +1,13 @@
 package cache
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
 
 type CacheKeyBuilder struct {
 	prefix string
@@ -12,6 +19,24 @@ func NewCacheKeyBuilder(prefix string) *CacheKeyBuilder {
 	}
 }
 
+// ForTenant returns a builder whose keys all include tenantID, so identical
+// logical keys of two tenants never collide. An empty tenantID returns b.
+func (b *CacheKeyBuilder) ForTenant(tenantID string) *CacheKeyBuilder {
+	if tenantID == "" {
+		return b
+	}
+	return &CacheKeyBuilder{
+		prefix: fmt.Sprintf("%s:tenant:%s", b.prefix, tenantID),
+	}
+}
+
+// For returns the builder for the tenant of the request on ctx, or b when
+// there is none.
+func (b *CacheKeyBuilder) For(ctx context.Context) *CacheKeyBuilder {
+	tenantID, _ := reqctx.TenantID(ctx)
+	return b.ForTenant(tenantID)
+}
+
 func (b *CacheKeyBuilder) UserByID(id string) string {
 	return fmt.Sprintf("%s:user:id:%s", b.prefix, id)
 }
@@ -28,6 +53,74 @@ func (b *CacheKeyBuilder) RefreshToken(token string) string {
 	return fmt.Sprintf("%s:refresh_token:%s", b.prefix, token)
 }
 
+func (b *CacheKeyBuilder) RefreshTokenRotation(token string) string {
+	return fmt.Sprintf("%s:refresh_token:rotated:%s", b.prefix, token)
+}
+
+func (b *CacheKeyBuilder) UserSessions(userID string) string {
+	return fmt.Sprintf("%s:user:sessions:%s", b.prefix, userID)
+}
+
+func (b *CacheKeyBuilder) AccessTokensRevokedAt(userID string) string {
+	return fmt.Sprintf("%s:user:tokens_revoked_at:%s", b.prefix, userID)
+}
+
+func (b *CacheKeyBuilder) VerificationToken(purpose, tokenHash string) string {
+	return fmt.Sprintf("%s:verification:%s:token:%s", b.prefix, purpose, tokenHash)
+}
+
+func (b *CacheKeyBuilder) VerificationUsed(purpose, tokenHash string) string {
+	return fmt.Sprintf("%s:verification:%s:used:%s", b.prefix, purpose, tokenHash)
+}
+
+func (b *CacheKeyBuilder) PendingVerification(purpose, subject string) string {
+	return fmt.Sprintf("%s:verification:%s:subject:%s", b.prefix, purpose, subject)
+}
+
+func (b *CacheKeyBuilder) EmailBudget(kind, email string, window time.Duration, start int64) string {
+	return fmt.Sprintf("%s:email_budget:%s:%s:%s:%d", b.prefix, kind, email, window, start)
+}
+
+func (b *CacheKeyBuilder) EmailSent(jobID string) string {
+	return fmt.Sprintf("%s:mail:sent:%s", b.prefix, jobID)
+}
+
+func (b *CacheKeyBuilder) FailedEmails() string {
+	return fmt.Sprintf("%s:mail:failed", b.prefix)
+}
+
+func (b *CacheKeyBuilder) Usage(userID, feature, window, period string) string {
+	return fmt.Sprintf("%s:usage:%s:%s:%s:%s", b.prefix, userID, feature, window, period)
+}
+
+func (b *CacheKeyBuilder) UsageMonth(userID, period string) string {
+	return fmt.Sprintf("%s:usage:%s:month:%s", b.prefix, userID, period)
+}
+
+func (b *CacheKeyBuilder) UsageUsers(period string) string {
+	return fmt.Sprintf("%s:usage_users:%s", b.prefix, period)
+}
+
+func (b *CacheKeyBuilder) Business(id string) string {
+	return fmt.Sprintf("%s:business:%s", b.prefix, id)
+}
+
+func (b *CacheKeyBuilder) AIConversation(userID, conversationID string) string {
+	return fmt.Sprintf("%s:ai:conversation:%s:%s", b.prefix, userID, conversationID)
+}
+
+func (b *CacheKeyBuilder) AIGeneration(userID, inputHash string) string {
+	return fmt.Sprintf("%s:ai:generation:%s:%s", b.prefix, userID, inputHash)
+}
+
+func (b *CacheKeyBuilder) MLResponse(namespace int64, op, hash string) string {
+	return fmt.Sprintf("%s:ml:%d:%s:%s", b.prefix, namespace, op, hash)
+}
+
+func (b *CacheKeyBuilder) MLNamespace() string {
+	return fmt.Sprintf("%s:ml:namespace", b.prefix)
+}
+
 func (b *CacheKeyBuilder) Workflow(id string) string {
 	return fmt.Sprintf("%s:workflow:%s", b.prefix, id)
 }
@@ -44,6 +137,34 @@ func (b *CacheKeyBuilder) RateLimit(identifier string) string {
 	return fmt.Sprintf("%s:rate_limit:%s", b.prefix, identifier)
 }
 
+func (b *CacheKeyBuilder) FeatureOverrides() string {
+	return fmt.Sprintf("%s:features:overrides", b.prefix)
+}
+
+func (b *CacheKeyBuilder) JobLock(name string) string {
+	return fmt.Sprintf("%s:jobs:lock:%s", b.prefix, name)
+}
+
+func (b *CacheKeyBuilder) JobRuns() string {
+	return fmt.Sprintf("%s:jobs:runs", b.prefix)
+}
+
+func (b *CacheKeyBuilder) OrphanCleanupCursor(prefix string) string {
+	return fmt.Sprintf("%s:storage:orphan_cursor:%s", b.prefix, prefix)
+}
+
+func (b *CacheKeyBuilder) NotificationSeq(userID string) string {
+	return fmt.Sprintf("%s:notifications:seq:%s", b.prefix, userID)
+}
+
+func (b *CacheKeyBuilder) NotificationReplay(userID string) string {
+	return fmt.Sprintf("%s:notifications:replay:%s", b.prefix, userID)
+}
+
+func (b *CacheKeyBuilder) NotificationChannel(userID string) string {
+	return fmt.Sprintf("%s:notifications:channel:%s", b.prefix, userID)
+}
+
 func (b *CacheKeyBuilder) Custom(parts ...string) string {
 	key := b.prefix
 	for _, part := range parts {
@@ -52,3 +173,65 @@ func (b *CacheKeyBuilder) Custom(parts ...string) string {
 
 	return key
 }
+
+// entityKeys maps the entity types admins may invalidate by ID to the keys
+// caching them. Sessions, refresh tokens and rate limits are deliberately
+// absent: clearing them would log users out or lift limits.
+var entityKeys = map[string]func(b *CacheKeyBuilder, id string) []string{
+	"user":      func(b *CacheKeyBuilder, id string) []string { return []string{b.UserByID(id)} },
+	"business":  func(b *CacheKeyBuilder, id string) []string { return []string{b.Business(id)} },
+	"workflow":  func(b *CacheKeyBuilder, id string) []string { return []string{b.Workflow(id)} },
+	"execution": func(b *CacheKeyBuilder, id string) []string { return []string{b.Execution(id)} },
+}
+
+// invalidatableNamespaces are the key namespaces, after the prefix, that
+// admins may delete keys from by name
+var invalidatableNamespaces = []string{"user:id:", "user:email:", "business:", "workflow:", "execution:"}
+
+// secretNamespaces hold keys that embed tokens, which must never be listed
+var secretNamespaces = []string{"refresh_token:", "session:", "verification:"}
+
+// EntityKeys returns the keys caching the entity of entityType with id, so
+// callers need not know the key formats. ok is false for types that can't
+// be invalidated.
+func (b *CacheKeyBuilder) EntityKeys(entityType, id string) (keys []string, ok bool) {
+	build, ok := entityKeys[entityType]
+	if !ok {
+		return nil, false
+	}
+	return build(b, id), true
+}
+
+// Invalidatable reports whether key is in a namespace admins may delete
+// from, for b or any of its tenants.
+func (b *CacheKeyBuilder) Invalidatable(key string) bool {
+	return hasNamespace(b.namespace(key), invalidatableNamespaces)
+}
+
+// Secret reports whether key embeds a token, such as a refresh token, and
+// so must not be shown.
+func (b *CacheKeyBuilder) Secret(key string) bool {
+	return hasNamespace(b.namespace(key), secretNamespaces)
+}
+
+// namespace strips the prefix and any tenant from key; keys without the
+// prefix come back empty.
+func (b *CacheKeyBuilder) namespace(key string) string {
+	rest, ok := strings.CutPrefix(key, b.prefix+":")
+	if !ok {
+		return ""
+	}
+	if tenant, ok := strings.CutPrefix(rest, "tenant:"); ok {
+		_, rest, _ = strings.Cut(tenant, ":")
+	}
+	return rest
+}
+
+func hasNamespace(rest string, namespaces []string) bool {
+	for _, ns := range namespaces {
+		if strings.HasPrefix(rest, ns) {
+			return true
+		}
+	}
+	return false
+}
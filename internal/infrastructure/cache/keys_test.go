@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+func TestForTenant(t *testing.T) {
+	base := NewCacheKeyBuilder("umkm")
+	tenantA := base.ForTenant("warung")
+	tenantB := base.ForTenant("toko")
+
+	keys := []struct {
+		name string
+		key  func(b *CacheKeyBuilder) string
+	}{
+		{"UserByID", func(b *CacheKeyBuilder) string { return b.UserByID("user-1") }},
+		{"UserByEmail", func(b *CacheKeyBuilder) string { return b.UserByEmail("owner@example.com") }},
+		{"RefreshToken", func(b *CacheKeyBuilder) string { return b.RefreshToken("token") }},
+		{"UserSessions", func(b *CacheKeyBuilder) string { return b.UserSessions("user-1") }},
+		{"AccessTokensRevokedAt", func(b *CacheKeyBuilder) string { return b.AccessTokensRevokedAt("user-1") }},
+		{"Business", func(b *CacheKeyBuilder) string { return b.Business("business-1") }},
+		{"Custom", func(b *CacheKeyBuilder) string { return b.Custom("a", "b") }},
+	}
+
+	for _, tt := range keys {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, shared := tt.key(tenantA), tt.key(tenantB), tt.key(base)
+			if a == b || a == shared || b == shared {
+				t.Fatalf("keys collide: %q, %q, %q", a, b, shared)
+			}
+			if !strings.HasPrefix(a, "umkm:tenant:warung:") {
+				t.Errorf("key = %q, want it under umkm:tenant:warung:", a)
+			}
+			// The tenant segment only prefixes the key; the rest is unchanged
+			if strings.TrimPrefix(a, "umkm:tenant:warung") != strings.TrimPrefix(shared, "umkm") {
+				t.Errorf("tenant key %q does not extend %q", a, shared)
+			}
+		})
+	}
+
+	if base.ForTenant("") != base {
+		t.Error("ForTenant(\"\") returned a new builder, want the same one")
+	}
+}
+
+func TestForContext(t *testing.T) {
+	base := NewCacheKeyBuilder("umkm")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"no tenant", context.Background(), "umkm:user:id:user-1"},
+		{"empty tenant", reqctx.WithTenantID(context.Background(), ""), "umkm:user:id:user-1"},
+		{"tenant", reqctx.WithTenantID(context.Background(), "warung"), "umkm:tenant:warung:user:id:user-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.For(tt.ctx).UserByID("user-1"); got != tt.want {
+				t.Errorf("UserByID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
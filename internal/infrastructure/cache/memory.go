@@ -0,0 +1,820 @@
+package cache
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+)
+
+// KeepTTL makes Set keep the key's current expiry, like Redis' KEEPTTL
+const KeepTTL = -1
+
+var (
+	errWrongType  = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInteger = errors.New("ERR value is not an integer or out of range")
+	errClosed     = errors.New("cache is closed")
+)
+
+// memorySweepInterval is how often expired keys are removed from memory;
+// reads never see them either way
+const memorySweepInterval = time.Minute
+
+// memorySubscriptionBuffer is how many messages a subscriber can fall
+// behind before further messages to it are dropped
+const memorySubscriptionBuffer = 100
+
+type entryKind int
+
+const (
+	kindString entryKind = iota
+	kindHash
+	kindSet
+	kindList
+)
+
+type memoryEntry struct {
+	kind      entryKind
+	str       string
+	hash      map[string]string
+	set       map[string]struct{}
+	list      []string
+	expiresAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryCache is a Cache held in process memory. It mirrors the Redis
+// commands RedisCache uses, down to their edge cases, so it can stand in for
+// Redis in tests and in local development (redis.mode: memory). Nothing is
+// shared between processes or survives a restart.
+type MemoryCache struct {
+	mu          sync.Mutex
+	data        map[string]*memoryEntry
+	subscribers map[string]map[*memorySubscription]struct{}
+	closed      bool
+	done        chan struct{}
+}
+
+func NewMemoryCache() *MemoryCache {
+	c := &MemoryCache{
+		data:        make(map[string]*memoryEntry),
+		subscribers: make(map[string]map[*memorySubscription]struct{}),
+		done:        make(chan struct{}),
+	}
+	go c.sweep()
+	return c
+}
+
+// sweep drops expired keys until the cache is closed.
+func (c *MemoryCache) sweep() {
+	ticker := time.NewTicker(memorySweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			now := time.Now()
+			for key, e := range c.data {
+				if e.expired(now) {
+					delete(c.data, key)
+				}
+			}
+			c.mu.Unlock()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// lookup returns the live entry under key, dropping it if it has expired.
+// The caller holds c.mu.
+func (c *MemoryCache) lookup(key string) *memoryEntry {
+	e, ok := c.data[key]
+	if !ok {
+		return nil
+	}
+	if e.expired(time.Now()) {
+		delete(c.data, key)
+		return nil
+	}
+	return e
+}
+
+// lookupKind is lookup that fails when the key holds another kind of value.
+func (c *MemoryCache) lookupKind(key string, kind entryKind) (*memoryEntry, error) {
+	e := c.lookup(key)
+	if e != nil && e.kind != kind {
+		return nil, errWrongType
+	}
+	return e, nil
+}
+
+// lookupOrCreate returns the entry of kind under key, creating it when the
+// key doesn't exist.
+func (c *MemoryCache) lookupOrCreate(key string, kind entryKind) (*memoryEntry, error) {
+	e, err := c.lookupKind(key, kind)
+	if err != nil || e != nil {
+		return e, err
+	}
+	e = &memoryEntry{kind: kind}
+	switch kind {
+	case kindHash:
+		e.hash = make(map[string]string)
+	case kindSet:
+		e.set = make(map[string]struct{})
+	}
+	c.data[key] = e
+	return e, nil
+}
+
+// dropIfEmpty removes a hash, set or list with no elements left, as Redis
+// does.
+func (c *MemoryCache) dropIfEmpty(key string, e *memoryEntry) {
+	if len(e.hash) == 0 && len(e.set) == 0 && len(e.list) == 0 {
+		delete(c.data, key)
+	}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindString)
+	if err != nil {
+		return "", fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+	if e == nil {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	return e.str, nil
+}
+
+func (c *MemoryCache) GetDel(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindString)
+	if err != nil {
+		return "", fmt.Errorf("failed to get and delete key %s: %w", key, err)
+	}
+	if e == nil {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	delete(c.data, key)
+	return e.str, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	s, err := formatValue(value)
+	if err != nil {
+		return fmt.Errorf("failed to set key %s: %w", key, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &memoryEntry{kind: kindString, str: s}
+	if ttl == KeepTTL {
+		if old := c.lookup(key); old != nil {
+			e.expiresAt = old.expiresAt
+		}
+	} else if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+	c.data[key] = e
+	return nil
+}
+
+func (c *MemoryCache) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	s, err := formatValue(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to set key %s if absent: %w", key, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookup(key) != nil {
+		return false, nil
+	}
+	e := &memoryEntry{kind: kindString, str: s}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+	c.data[key] = e
+	return true, nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.data, key)
+	}
+	return nil
+}
+
+// Exists counts a key given twice twice, like Redis.
+func (c *MemoryCache) Exists(ctx context.Context, keys ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var count int64
+	for _, key := range keys {
+		if c.lookup(key) != nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (c *MemoryCache) ExistsMap(ctx context.Context, keys ...string) (map[string]bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		result[key] = c.lookup(key) != nil
+	}
+	return result, nil
+}
+
+// Expire does nothing to a missing key and deletes the key when ttl is not
+// positive, like Redis.
+func (c *MemoryCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(key)
+	if e == nil {
+		return nil
+	}
+	if ttl <= 0 {
+		delete(c.data, key)
+		return nil
+	}
+	e.expiresAt = time.Now().Add(ttl)
+	return nil
+}
+
+// TTL returns -2 for a missing key and -1 for a key without expiry, the
+// values go-redis reports for Redis' TTL command. Like Redis it answers in
+// whole seconds.
+func (c *MemoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.lookup(key)
+	if e == nil {
+		return -2, nil
+	}
+	if e.expiresAt.IsZero() {
+		return -1, nil
+	}
+	return (time.Until(e.expiresAt) + 500*time.Millisecond).Truncate(time.Second), nil
+}
+
+func (c *MemoryCache) Increment(ctx context.Context, key string) (int64, error) {
+	return c.incrementBy(key, 1, "increment")
+}
+
+func (c *MemoryCache) IncrementBy(ctx context.Context, key string, n int64) (int64, error) {
+	return c.incrementBy(key, n, "increment")
+}
+
+func (c *MemoryCache) Decrement(ctx context.Context, key string) (int64, error) {
+	return c.incrementBy(key, -1, "decrement")
+}
+
+// incrementBy adds n to the integer under key, starting from 0 when the key
+// is missing. The key's expiry is kept.
+func (c *MemoryCache) incrementBy(key string, n int64, op string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindString)
+	if err != nil {
+		return 0, fmt.Errorf("failed to %s key %s: %w", op, key, err)
+	}
+	if e == nil {
+		e = &memoryEntry{kind: kindString, str: "0"}
+		c.data[key] = e
+	}
+
+	current, err := strconv.ParseInt(e.str, 10, 64)
+	if err != nil || (n > 0 && current > math.MaxInt64-n) || (n < 0 && current < math.MinInt64-n) {
+		return 0, fmt.Errorf("failed to %s key %s: %w", op, key, errNotInteger)
+	}
+	current += n
+	e.str = strconv.FormatInt(current, 10)
+	return current, nil
+}
+
+// MGet returns nil for keys that are missing or don't hold a string.
+func (c *MemoryCache) MGet(ctx context.Context, keys ...string) ([]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vals := make([]any, len(keys))
+	for i, key := range keys {
+		if e := c.lookup(key); e != nil && e.kind == kindString {
+			vals[i] = e.str
+		}
+	}
+	return vals, nil
+}
+
+// MSet overwrites the keys whatever they held, and clears their expiry.
+func (c *MemoryCache) MSet(ctx context.Context, pairs map[string]any) error {
+	if len(pairs) == 0 {
+		return fmt.Errorf("failed to set multiple keys: ERR wrong number of arguments for 'mset' command")
+	}
+	values := make(map[string]string, len(pairs))
+	for k, v := range pairs {
+		s, err := formatValue(v)
+		if err != nil {
+			return fmt.Errorf("failed to set multiple keys: %w", err)
+		}
+		values[k] = s
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, s := range values {
+		c.data[k] = &memoryEntry{kind: kindString, str: s}
+	}
+	return nil
+}
+
+func (c *MemoryCache) HGet(ctx context.Context, key, field string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get hash field %s[%s]: %w", key, field, err)
+	}
+	if e == nil {
+		return "", fmt.Errorf("%w: %s[%s]", ErrKeyNotFound, key, field)
+	}
+	value, ok := e.hash[field]
+	if !ok {
+		return "", fmt.Errorf("%w: %s[%s]", ErrKeyNotFound, key, field)
+	}
+	return value, nil
+}
+
+func (c *MemoryCache) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hash %s: %w", key, err)
+	}
+	values := make(map[string]string)
+	if e != nil {
+		for k, v := range e.hash {
+			values[k] = v
+		}
+	}
+	return values, nil
+}
+
+func (c *MemoryCache) HSet(ctx context.Context, key, field string, value any) error {
+	s, err := formatValue(value)
+	if err != nil {
+		return fmt.Errorf("failed to set hash field %s[%s]: %w", key, field, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupOrCreate(key, kindHash)
+	if err != nil {
+		return fmt.Errorf("failed to set hash field %s[%s]: %w", key, field, err)
+	}
+	e.hash[field] = s
+	return nil
+}
+
+// HIncrBy adds n to a hash field, starting from 0 when the hash or the
+// field is missing.
+func (c *MemoryCache) HIncrBy(ctx context.Context, key, field string, n int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupOrCreate(key, kindHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment hash field %s[%s]: %w", key, field, err)
+	}
+
+	current := int64(0)
+	if s, ok := e.hash[field]; ok {
+		current, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || (n > 0 && current > math.MaxInt64-n) || (n < 0 && current < math.MinInt64-n) {
+			return 0, fmt.Errorf("failed to increment hash field %s[%s]: %w", key, field, errNotInteger)
+		}
+	}
+	current += n
+	e.hash[field] = strconv.FormatInt(current, 10)
+	return current, nil
+}
+
+func (c *MemoryCache) HDel(ctx context.Context, key string, fields ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindHash)
+	if err != nil {
+		return fmt.Errorf("failed to delete hash fields of %s: %w", key, err)
+	}
+	if e == nil {
+		return nil
+	}
+	for _, field := range fields {
+		delete(e.hash, field)
+	}
+	c.dropIfEmpty(key, e)
+	return nil
+}
+
+func (c *MemoryCache) SAdd(ctx context.Context, key string, members ...any) error {
+	formatted, err := formatValues(members)
+	if err != nil {
+		return fmt.Errorf("failed to add set members to %s: %w", key, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupOrCreate(key, kindSet)
+	if err != nil {
+		return fmt.Errorf("failed to add set members to %s: %w", key, err)
+	}
+	for _, m := range formatted {
+		e.set[m] = struct{}{}
+	}
+	c.dropIfEmpty(key, e)
+	return nil
+}
+
+func (c *MemoryCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get set members of %s: %w", key, err)
+	}
+	members := []string{}
+	if e != nil {
+		for m := range e.set {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func (c *MemoryCache) SRem(ctx context.Context, key string, members ...any) error {
+	formatted, err := formatValues(members)
+	if err != nil {
+		return fmt.Errorf("failed to remove set members from %s: %w", key, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindSet)
+	if err != nil {
+		return fmt.Errorf("failed to remove set members from %s: %w", key, err)
+	}
+	if e == nil {
+		return nil
+	}
+	for _, m := range formatted {
+		delete(e.set, m)
+	}
+	c.dropIfEmpty(key, e)
+	return nil
+}
+
+// LPush prepends values one at a time, so the last one ends up first.
+func (c *MemoryCache) LPush(ctx context.Context, key string, values ...any) error {
+	formatted, err := formatValues(values)
+	if err != nil {
+		return fmt.Errorf("failed to push to list %s: %w", key, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupOrCreate(key, kindList)
+	if err != nil {
+		return fmt.Errorf("failed to push to list %s: %w", key, err)
+	}
+	list := make([]string, 0, len(formatted)+len(e.list))
+	for i := len(formatted) - 1; i >= 0; i-- {
+		list = append(list, formatted[i])
+	}
+	e.list = append(list, e.list...)
+	c.dropIfEmpty(key, e)
+	return nil
+}
+
+func (c *MemoryCache) LTrim(ctx context.Context, key string, start, stop int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindList)
+	if err != nil {
+		return fmt.Errorf("failed to trim list %s: %w", key, err)
+	}
+	if e == nil {
+		return nil
+	}
+	from, to, ok := listRange(len(e.list), start, stop)
+	if !ok {
+		e.list = nil
+	} else {
+		e.list = append([]string(nil), e.list[from:to]...)
+	}
+	c.dropIfEmpty(key, e)
+	return nil
+}
+
+func (c *MemoryCache) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, err := c.lookupKind(key, kindList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get range of list %s: %w", key, err)
+	}
+	if e == nil {
+		return []string{}, nil
+	}
+	from, to, ok := listRange(len(e.list), start, stop)
+	if !ok {
+		return []string{}, nil
+	}
+	return append([]string(nil), e.list[from:to]...), nil
+}
+
+// listRange converts Redis' inclusive, possibly negative start and stop
+// into slice bounds. ok is false when the range is empty.
+func listRange(length int, start, stop int64) (from, to int, ok bool) {
+	n := int64(length)
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop = n + stop
+	}
+	stop = min(stop, n-1)
+	if start > stop || start >= n {
+		return 0, 0, false
+	}
+	return int(start), int(stop) + 1, true
+}
+
+// Publish drops the message for subscribers that are too far behind, as
+// Redis does with clients over their output buffer limit.
+func (c *MemoryCache) Publish(ctx context.Context, channel string, message any) error {
+	payload, err := formatValue(message)
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", channel, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for sub := range c.subscribers[channel] {
+		select {
+		case sub.messages <- payload:
+		default:
+		}
+	}
+	return nil
+}
+
+func (c *MemoryCache) Subscribe(ctx context.Context, channel string) (Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", channel, errClosed)
+	}
+	sub := &memorySubscription{
+		cache:    c,
+		channel:  channel,
+		messages: make(chan string, memorySubscriptionBuffer),
+	}
+	if c.subscribers[channel] == nil {
+		c.subscribers[channel] = make(map[*memorySubscription]struct{})
+	}
+	c.subscribers[channel][sub] = struct{}{}
+	return sub, nil
+}
+
+type memorySubscription struct {
+	cache    *MemoryCache
+	channel  string
+	messages chan string
+}
+
+func (s *memorySubscription) Messages() <-chan string {
+	return s.messages
+}
+
+func (s *memorySubscription) Close() error {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	subs := s.cache.subscribers[s.channel]
+	if _, ok := subs[s]; !ok {
+		return nil
+	}
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(s.cache.subscribers, s.channel)
+	}
+	close(s.messages)
+	return nil
+}
+
+func (c *MemoryCache) Scan(ctx context.Context, pattern string, limit int) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var keys []string
+	for key := range c.data {
+		if len(keys) == limit {
+			break
+		}
+		if c.lookup(key) != nil && globMatch(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// globMatch reports whether s matches pattern in Redis' glob syntax: * is
+// any run of characters, ? any single one, and \ escapes the next. Unlike
+// path.Match, * also spans separators.
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if pattern[0] == '\\' && len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return s == ""
+}
+
+func (c *MemoryCache) FlushAll(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data = make(map[string]*memoryEntry)
+	return nil
+}
+
+func (c *MemoryCache) HealthChecks() []health.Check {
+	return []health.Check{{
+		Name:     "cache",
+		Required: true,
+		Checker: health.CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+			return nil, c.Ping(ctx)
+		}),
+	}}
+}
+
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return fmt.Errorf("failed to ping cache: %w", errClosed)
+	}
+	return nil
+}
+
+// Close stops the expiry sweep and ends every subscription.
+func (c *MemoryCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	for channel, subs := range c.subscribers {
+		for sub := range subs {
+			close(sub.messages)
+		}
+		delete(c.subscribers, channel)
+	}
+	return nil
+}
+
+// formatValue turns value into the string Redis would store, following the
+// argument encoding of go-redis.
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10), nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("can't marshal %T (implement encoding.BinaryMarshaler)", value)
+	}
+}
+
+func formatValues(values []any) ([]string, error) {
+	formatted := make([]string, len(values))
+	for i, v := range values {
+		s, err := formatValue(v)
+		if err != nil {
+			return nil, err
+		}
+		formatted[i] = s
+	}
+	return formatted, nil
+}
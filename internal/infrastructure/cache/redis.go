@@ -2,20 +2,44 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
 	"github.com/redis/go-redis/v9"
 )
 
 type RedisCache struct {
 	client *redis.Client
+
+	// noGetDel is set once the server is known not to support GETDEL
+	noGetDel atomic.Bool
 }
 
 func NewRedisCache(cfg *config.Config) (Cache, error) {
-	client := redis.NewClient(&redis.Options{
+	c := OpenRedisCache(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		c.client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return c, nil
+}
+
+// OpenRedisCache creates the client without contacting the server;
+// connections are made on first use.
+func OpenRedisCache(cfg *config.Config) *RedisCache {
+	opts := &redis.Options{
 		Addr:         cfg.GetRedisDSN(),
 		Password:     cfg.Redis.Password,
 		DB:           cfg.Redis.DB,
@@ -23,24 +47,62 @@ func NewRedisCache(cfg *config.Config) (Cache, error) {
 		DialTimeout:  5 * time.Second,
 		ReadTimeout:  3 * time.Second,
 		WriteTimeout: 3 * time.Second,
-	})
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	if cfg.Redis.TLS {
+		opts.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ServerName: cfg.Redis.Host,
+		}
 	}
 
 	return &RedisCache{
-		client: client,
-	}, nil
+		client: redis.NewClient(opts),
+	}
+}
+
+// Reconnect pings c every interval until it answers, then sets up. It is
+// for a server started while Redis was unreachable; call the returned stop
+// to give up early.
+func Reconnect(c Cache, interval time.Duration, up *atomic.Bool) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for attempt := 1; ; attempt++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			pingCtx, pingCancel := context.WithTimeout(ctx, interval)
+			err := c.Ping(pingCtx)
+			pingCancel()
+			if err == nil {
+				up.Store(true)
+				log.Printf("Redis is reachable after %d attempts, leaving degraded mode", attempt)
+				return
+			}
+			if attempt%10 == 1 {
+				log.Printf("Redis still unreachable (attempt %d): %v", attempt, err)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
 func (c *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	value, err := c.client.Get(ctx, key).Result()
 	if err == redis.Nil {
-		return "", fmt.Errorf("key not found: %s", key)
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
 	}
 	if err != nil {
 		return "", fmt.Errorf("failed to get key %s: %w", key, err)
@@ -49,6 +111,41 @@ func (c *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	return value, nil
 }
 
+// getDelScript reads and removes a key in one atomic step on servers older
+// than Redis 6.2, which lack the GETDEL command
+var getDelScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value then
+	redis.call('DEL', KEYS[1])
+end
+return value
+`)
+
+// GetDel uses the native GETDEL command and permanently switches to the Lua
+// fallback the first time the server rejects it as unknown.
+func (c *RedisCache) GetDel(ctx context.Context, key string) (string, error) {
+	var value string
+	var err error
+	if !c.noGetDel.Load() {
+		value, err = c.client.GetDel(ctx, key).Result()
+		if err != nil && isUnknownCommand(err) {
+			c.noGetDel.Store(true)
+		}
+	}
+	if c.noGetDel.Load() {
+		value, err = getDelScript.Run(ctx, c.client, []string{key}).Text()
+	}
+
+	if err == redis.Nil {
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get and delete key %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
 func (c *RedisCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
 	err := c.client.Set(ctx, key, value, ttl).Err()
 	if err != nil {
@@ -58,28 +155,68 @@ func (c *RedisCache) Set(ctx context.Context, key string, value any, ttl time.Du
 	return nil
 }
 
+func (c *RedisCache) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	set, err := c.client.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to set key %s if absent: %w", key, err)
+	}
+
+	return set, nil
+}
+
 func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	// DEL without keys is a syntax error
+	if len(keys) == 0 {
+		return nil
+	}
+
 	err := c.client.Del(ctx, keys...).Err()
 	if err != nil {
-		return fmt.Errorf("failed to delete key %w: ", err)
+		return fmt.Errorf("failed to delete keys %v: %w", keys, err)
 	}
 
 	return nil
 }
 
 func (c *RedisCache) Exists(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
 	count, err := c.client.Exists(ctx, keys...).Result()
 	if err != nil {
-		return 0, fmt.Errorf("failed to check if key exist %w: ", err)
+		return 0, fmt.Errorf("failed to check if keys %v exist: %w", keys, err)
 	}
 
 	return count, nil
 }
 
+// ExistsMap checks every key in a single pipeline round trip.
+func (c *RedisCache) ExistsMap(ctx context.Context, keys ...string) (map[string]bool, error) {
+	result := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Exists(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to check if keys %v exist: %w", keys, err)
+	}
+
+	for i, key := range keys {
+		result[key] = cmds[i].Val() > 0
+	}
+	return result, nil
+}
+
 func (c *RedisCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
 	err := c.client.Expire(ctx, key, ttl).Err()
 	if err != nil {
-		return fmt.Errorf("failed to set expiration key %s: %w", key, err)
+		return fmt.Errorf("failed to set expiration on key %s: %w", key, err)
 	}
 
 	return nil
@@ -88,7 +225,7 @@ func (c *RedisCache) Expire(ctx context.Context, key string, ttl time.Duration)
 func (c *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
 	ttl, err := c.client.TTL(ctx, key).Result()
 	if err != nil {
-		return ttl, fmt.Errorf("failed to get TTL key %s: %w", key, err)
+		return ttl, fmt.Errorf("failed to get TTL of key %s: %w", key, err)
 	}
 
 	return ttl, nil
@@ -97,7 +234,16 @@ func (c *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error)
 func (c *RedisCache) Increment(ctx context.Context, key string) (int64, error) {
 	inc, err := c.client.Incr(ctx, key).Result()
 	if err != nil {
-		return inc, fmt.Errorf("failed to inc key %s: %w", key, err)
+		return 0, fmt.Errorf("failed to increment key %s: %w", key, err)
+	}
+
+	return inc, nil
+}
+
+func (c *RedisCache) IncrementBy(ctx context.Context, key string, n int64) (int64, error) {
+	inc, err := c.client.IncrBy(ctx, key, n).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment key %s: %w", key, err)
 	}
 
 	return inc, nil
@@ -106,7 +252,7 @@ func (c *RedisCache) Increment(ctx context.Context, key string) (int64, error) {
 func (c *RedisCache) Decrement(ctx context.Context, key string) (int64, error) {
 	dec, err := c.client.Decr(ctx, key).Result()
 	if err != nil {
-		return dec, fmt.Errorf("failed to dec key %s: %w", key, err)
+		return 0, fmt.Errorf("failed to decrement key %s: %w", key, err)
 	}
 
 	return dec, nil
@@ -135,10 +281,200 @@ func (c *RedisCache) MSet(ctx context.Context, pairs map[string]any) error {
 	return nil
 }
 
+func (c *RedisCache) HGet(ctx context.Context, key, field string) (string, error) {
+	value, err := c.client.HGet(ctx, key, field).Result()
+	if err == redis.Nil {
+		return "", fmt.Errorf("%w: %s[%s]", ErrKeyNotFound, key, field)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get hash field %s[%s]: %w", key, field, err)
+	}
+
+	return value, nil
+}
+
+func (c *RedisCache) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	values, err := c.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hash %s: %w", key, err)
+	}
+
+	return values, nil
+}
+
+func (c *RedisCache) HSet(ctx context.Context, key, field string, value any) error {
+	err := c.client.HSet(ctx, key, field, value).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set hash field %s[%s]: %w", key, field, err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) HIncrBy(ctx context.Context, key, field string, n int64) (int64, error) {
+	value, err := c.client.HIncrBy(ctx, key, field, n).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment hash field %s[%s]: %w", key, field, err)
+	}
+
+	return value, nil
+}
+
+func (c *RedisCache) HDel(ctx context.Context, key string, fields ...string) error {
+	err := c.client.HDel(ctx, key, fields...).Err()
+	if err != nil {
+		return fmt.Errorf("failed to delete hash fields of %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) SAdd(ctx context.Context, key string, members ...any) error {
+	err := c.client.SAdd(ctx, key, members...).Err()
+	if err != nil {
+		return fmt.Errorf("failed to add set members to %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	members, err := c.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get set members of %s: %w", key, err)
+	}
+
+	return members, nil
+}
+
+func (c *RedisCache) SRem(ctx context.Context, key string, members ...any) error {
+	err := c.client.SRem(ctx, key, members...).Err()
+	if err != nil {
+		return fmt.Errorf("failed to remove set members from %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) LPush(ctx context.Context, key string, values ...any) error {
+	err := c.client.LPush(ctx, key, values...).Err()
+	if err != nil {
+		return fmt.Errorf("failed to push to list %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) LTrim(ctx context.Context, key string, start, stop int64) error {
+	err := c.client.LTrim(ctx, key, start, stop).Err()
+	if err != nil {
+		return fmt.Errorf("failed to trim list %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	values, err := c.client.LRange(ctx, key, start, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get range of list %s: %w", key, err)
+	}
+
+	return values, nil
+}
+
+func (c *RedisCache) Publish(ctx context.Context, channel string, message any) error {
+	err := c.client.Publish(ctx, channel, message).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", channel, err)
+	}
+
+	return nil
+}
+
+func (c *RedisCache) Subscribe(ctx context.Context, channel string) (Subscription, error) {
+	ps := c.client.Subscribe(ctx, channel)
+	// Wait for the confirmation so messages published after this returns
+	// are not missed
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+	}
+
+	sub := &redisSubscription{
+		ps:       ps,
+		messages: make(chan string),
+		done:     make(chan struct{}),
+	}
+	go sub.forward()
+
+	return sub, nil
+}
+
+type redisSubscription struct {
+	ps        *redis.PubSub
+	messages  chan string
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *redisSubscription) forward() {
+	defer close(s.messages)
+	for msg := range s.ps.Channel() {
+		select {
+		case s.messages <- msg.Payload:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *redisSubscription) Messages() <-chan string {
+	return s.messages
+}
+
+func (s *redisSubscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.ps.Close()
+	})
+	return err
+}
+
+// scanBatch is the COUNT hint of each SCAN call; scanMaxCalls bounds how
+// much of a large keyspace one Scan walks when few keys match
+const (
+	scanBatch    = 100
+	scanMaxCalls = 1000
+)
+
+func (c *RedisCache) Scan(ctx context.Context, pattern string, limit int) ([]string, error) {
+	var keys []string
+	var cursor uint64
+	for range scanMaxCalls {
+		batch, next, err := c.client.Scan(ctx, cursor, pattern, scanBatch).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan keys: %w", err)
+		}
+		for _, key := range batch {
+			if len(keys) == limit {
+				return keys, nil
+			}
+			keys = append(keys, key)
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	return keys, nil
+}
+
 func (c *RedisCache) FlushAll(ctx context.Context) error {
 	err := c.client.FlushAll(ctx).Err()
 	if err != nil {
-		return fmt.Errorf("failed to flush: %w", err)
+		return fmt.Errorf("failed to flush all keys: %w", err)
 	}
 
 	return nil
@@ -147,7 +483,7 @@ func (c *RedisCache) FlushAll(ctx context.Context) error {
 func (c *RedisCache) Ping(ctx context.Context) error {
 	err := c.client.Ping(ctx).Err()
 	if err != nil {
-		return fmt.Errorf("failed to flush: %w", err)
+		return fmt.Errorf("failed to ping Redis: %w", err)
 	}
 
 	return nil
@@ -158,6 +494,21 @@ func (c *RedisCache) Close() error {
 }
 
 // GetStats returns Redis statistics in a structured format
+// HealthChecks reports the cache as required, with the pool statistics as
+// details.
+func (c *RedisCache) HealthChecks() []health.Check {
+	return []health.Check{{
+		Name:     "cache",
+		Required: true,
+		Checker: health.CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+			if err := c.Ping(ctx); err != nil {
+				return nil, err
+			}
+			return c.GetStats(ctx)
+		}),
+	}}
+}
+
 func (c *RedisCache) GetStats(ctx context.Context) (map[string]interface{}, error) {
 	// Get pool stats
 	poolStats := c.client.PoolStats()
@@ -196,6 +547,10 @@ func (c *RedisCache) GetStats(ctx context.Context) (map[string]interface{}, erro
 	}, nil
 }
 
+func isUnknownCommand(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "unknown command")
+}
+
 func parseRedisInfo(info string) map[string]string {
 	result := make(map[string]string)
 	lines := strings.Split(info, "\r\n")
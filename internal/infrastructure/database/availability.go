@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// IsUnavailable reports whether err means the database could not be
+// reached or dropped the connection, as opposed to rejecting the query.
+// Such failures are usually brief, e.g. during a failover.
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection exceptions; 57P01-57P03 are the server
+		// shutting down or still starting up
+		return strings.HasPrefix(pgErr.Code, "08") || pgErr.Code == "57P01" || pgErr.Code == "57P02" || pgErr.Code == "57P03"
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &connectErr),
+		errors.As(err, &netErr),
+		pgconn.Timeout(err),
+		errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, ErrDown):
+		return true
+	}
+	return false
+}
+
+// ErrDown is returned while the Gate has the database marked down.
+var ErrDown = errors.New("database is down")
+
+// Gate remembers whether the last health check reached the database, so
+// requests can fail fast while it is down instead of each waiting out the
+// dial timeout. While down, a request arriving after recheckInterval
+// triggers another check in the background, so the gate reopens on its
+// own even if nothing else probes the database.
+type Gate struct {
+	db              *gorm.DB
+	recheckInterval time.Duration
+
+	down      atomic.Bool
+	checking  atomic.Bool
+	checkedAt atomic.Int64
+}
+
+func NewGate(db *gorm.DB, recheckInterval time.Duration) *Gate {
+	return &Gate{db: db, recheckInterval: recheckInterval}
+}
+
+// Check pings the database and records the outcome. The database health
+// check calls it, so the gate follows /health and /readyz.
+func (g *Gate) Check() error {
+	err := HealthCheck(g.db)
+	g.checkedAt.Store(time.Now().UnixNano())
+	if err != nil && IsUnavailable(err) {
+		if !g.down.Swap(true) {
+			log.Printf("Database marked down, failing requests fast: %v", err)
+		}
+		return err
+	}
+	if g.down.Swap(false) {
+		log.Printf("Database is reachable again")
+	}
+	return err
+}
+
+// Down reports whether the database is known to be unreachable. A nil
+// Gate, as used without a database, is never down.
+func (g *Gate) Down() bool {
+	if g == nil || !g.down.Load() {
+		return false
+	}
+
+	last := time.Unix(0, g.checkedAt.Load())
+	if time.Since(last) >= g.recheckInterval && g.checking.CompareAndSwap(false, true) {
+		go func() {
+			defer g.checking.Store(false)
+			_ = g.Check()
+		}()
+	}
+	return true
+}
+
+// HealthChecks reports the database as required, with the pool statistics
+// as details. Running the check also opens and closes the gate. A nil Gate
+// has no checks.
+func (g *Gate) HealthChecks() []health.Check {
+	if g == nil {
+		return nil
+	}
+	return []health.Check{{
+		Name:     "database",
+		Required: true,
+		Checker: health.CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+			stats, _ := GetStats(g.db)
+			return stats, g.Check()
+		}),
+	}}
+}
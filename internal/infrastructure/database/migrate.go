@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"gorm.io/gorm"
+)
+
+// ErrNoMigration is returned by Migrator.Down when nothing is applied.
+var ErrNoMigration = errors.New("no migration to roll back")
+
+// Migration is one goose SQL file from the migrations directory.
+type Migration struct {
+	Version int64
+	Name    string
+	// AppliedAt is nil for pending migrations
+	AppliedAt *time.Time
+}
+
+// Migrator applies the goose SQL migrations in a directory. It runs them
+// through goose itself and shares its version table, so it can be mixed
+// with the goose CLI (make migrate-up).
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func NewMigrator(db *gorm.DB, dir string) (*Migrator, error) {
+	// The raw pool bypasses prepared statements, which reject the
+	// multi-statement scripts migrations consist of
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	// An advisory lock keeps two instances started at once from applying
+	// the same migration twice
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, sqlDB, os.DirFS(dir),
+		goose.WithSessionLocker(locker),
+	)
+	if errors.Is(err, goose.ErrNoMigrations) {
+		return nil, fmt.Errorf("no migrations found in %s", dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrator{provider: provider}, nil
+}
+
+// Status lists every migration in the directory, oldest first.
+func (m *Migrator) Status(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(statuses))
+	for _, status := range statuses {
+		mig := migrationOf(status.Source)
+		if status.State == goose.StateApplied {
+			at := status.AppliedAt
+			mig.AppliedAt = &at
+		}
+		migrations = append(migrations, mig)
+	}
+	return migrations, nil
+}
+
+// Up applies every pending migration in version order and returns the ones
+// it applied. It stops at the first failure.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	results, err := m.provider.Up(ctx)
+
+	var partial *goose.PartialError
+	if errors.As(err, &partial) {
+		results = partial.Applied
+		err = fmt.Errorf("migration %s: %w", filepath.Base(partial.Failed.Source.Path), partial.Err)
+	}
+
+	done := make([]Migration, 0, len(results))
+	for _, result := range results {
+		done = append(done, migrationOf(result.Source))
+	}
+	return done, err
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	result, err := m.provider.Down(ctx)
+	if errors.Is(err, goose.ErrNoNextVersion) {
+		return Migration{}, ErrNoMigration
+	}
+
+	var partial *goose.PartialError
+	if errors.As(err, &partial) {
+		return migrationOf(partial.Failed.Source), fmt.Errorf("migration %s: %w", filepath.Base(partial.Failed.Source.Path), partial.Err)
+	}
+	if err != nil {
+		return Migration{}, err
+	}
+	return migrationOf(result.Source), nil
+}
+
+func migrationOf(source *goose.Source) Migration {
+	return Migration{Version: source.Version, Name: filepath.Base(source.Path)}
+}
@@ -13,8 +13,33 @@ import (
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 )
 
-// NewPostgresDB creates a new PostgreSQL database connection using GORM
+// NewPostgresDB creates a new PostgreSQL database connection using GORM.
+// With database.prepare_stmt enabled, statements are prepared once per
+// connection and reused; cached plans invalidated by a schema change are
+// dropped automatically (see registerStalePlanRecovery).
 func NewPostgresDB(cfg *config.Config) (*gorm.DB, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	log.Println("Database connection established")
+
+	return db, nil
+}
+
+// Open configures the connection pool like NewPostgresDB but does not
+// connect; connections are made on first use.
+func Open(cfg *config.Config) (*gorm.DB, error) {
 	dsn := cfg.GetDatabaseDSN()
 
 	var gormLogger logger.Interface
@@ -27,7 +52,8 @@ func NewPostgresDB(cfg *config.Config) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger:                 gormLogger,
 		SkipDefaultTransaction: true,
-		PrepareStmt:            true,
+		DisableAutomaticPing:   true,
+		PrepareStmt:            cfg.Database.PrepareStmt,
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
@@ -36,6 +62,21 @@ func NewPostgresDB(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Map User.Roles onto the UserRole join model instead of a generated table
+	if err := db.SetupJoinTable(&domain.User{}, "Roles", &domain.UserRole{}); err != nil {
+		return nil, fmt.Errorf("failed to set up user roles join table: %w", err)
+	}
+
+	if err := registerStalePlanRecovery(db); err != nil {
+		return nil, fmt.Errorf("failed to register prepared statement recovery: %w", err)
+	}
+
+	if cfg.IsDevelopment() {
+		if err := registerQueryCounter(db); err != nil {
+			return nil, fmt.Errorf("failed to register query counter: %w", err)
+		}
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database instance: %w", err)
@@ -46,12 +87,6 @@ func NewPostgresDB(cfg *config.Config) (*gorm.DB, error) {
 	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 	sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
 
-	if err := sqlDB.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	log.Println("Database connection established")
-
 	return db, nil
 }
 
@@ -63,6 +98,12 @@ func AutoMigrate(db *gorm.DB) error {
 		&domain.User{},
 		&domain.Role{},
 		&domain.UserRole{},
+		&domain.File{},
+		&domain.Webhook{},
+		&domain.WebhookDelivery{},
+		&domain.Generation{},
+		&domain.Business{},
+		&domain.UsageRecord{},
 	)
 
 	if err != nil {
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// pgFeatureNotSupported is the SQLSTATE Postgres uses for
+// "cached plan must not change result type"
+const pgFeatureNotSupported = "0A000"
+
+const stalePlanCallback = "database:reset_stale_plans"
+
+// registerStalePlanRecovery clears GORM's prepared statement cache when
+// Postgres reports that a cached plan no longer matches the table, which
+// happens after a migration alters a table the statement reads. Only the
+// statement that hit the error fails; the next one is prepared again.
+func registerStalePlanRecovery(db *gorm.DB) error {
+	stmts, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		return nil
+	}
+
+	reset := func(tx *gorm.DB) {
+		if tx.Error == nil || !isStalePlanError(tx.Error) {
+			return
+		}
+		log.Printf("Schema change detected (%v), clearing prepared statement cache", tx.Error)
+		stmts.Close()
+	}
+
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("gorm:create").Register(stalePlanCallback, reset),
+		cb.Query().After("gorm:query").Register(stalePlanCallback, reset),
+		cb.Update().After("gorm:update").Register(stalePlanCallback, reset),
+		cb.Delete().After("gorm:delete").Register(stalePlanCallback, reset),
+		cb.Row().After("gorm:row").Register(stalePlanCallback, reset),
+		cb.Raw().After("gorm:raw").Register(stalePlanCallback, reset),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isStalePlanError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgFeatureNotSupported && strings.Contains(pgErr.Message, "cached plan must not change result type")
+}
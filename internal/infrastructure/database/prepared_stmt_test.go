@@ -0,0 +1,167 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// planDriver prepares statements against a schema version; a statement
+// prepared before the schema changed fails the way Postgres fails a cached
+// plan after a migration alters the table.
+type planDriver struct {
+	schema   atomic.Int64
+	prepares atomic.Int64
+}
+
+func (d *planDriver) Open(string) (driver.Conn, error) { return planConn{d}, nil }
+
+type planConn struct{ d *planDriver }
+
+func (c planConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.prepares.Add(1)
+	return planStmt{d: c.d, schema: c.d.schema.Load()}, nil
+}
+func (planConn) Close() error              { return nil }
+func (planConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type planStmt struct {
+	d      *planDriver
+	schema int64
+}
+
+func (planStmt) Close() error  { return nil }
+func (planStmt) NumInput() int { return -1 }
+func (planStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s planStmt) Query([]driver.Value) (driver.Rows, error) {
+	if s.schema != s.d.schema.Load() {
+		return nil, &pgconn.PgError{Severity: "ERROR", Code: "0A000", Message: "cached plan must not change result type"}
+	}
+	return &planRows{}, nil
+}
+
+// planRows is a single row with one column.
+type planRows struct{ done bool }
+
+func (*planRows) Columns() []string { return []string{"id"} }
+func (*planRows) Close() error      { return nil }
+func (r *planRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+var planDriverSeq atomic.Int64
+
+// newPlanDB returns a GORM handle with prepared statements over a fresh
+// planDriver.
+func newPlanDB(t *testing.T, prepare bool) (*gorm.DB, *planDriver) {
+	t.Helper()
+	d := &planDriver{}
+	name := fmt.Sprintf("stale-plan-fake-%d", planDriverSeq.Add(1))
+	sql.Register(name, d)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DriverName: name}), &gorm.Config{
+		DisableAutomaticPing: true,
+		PrepareStmt:          prepare,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registerStalePlanRecovery(db); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db, d
+}
+
+func TestStalePlanRecovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare bool
+		// wantStale is whether the first query after the migration fails
+		wantStale    bool
+		wantPrepares int64
+	}{
+		// Prepared once, failed once after the migration, prepared again
+		{"prepared statements", true, true, 2},
+		// Every query is prepared afresh by database/sql itself
+		{"without prepared statements", false, false, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, d := newPlanDB(t, tt.prepare)
+			query := func() error {
+				var ids []int64
+				return db.Raw("SELECT id FROM users").Scan(&ids).Error
+			}
+
+			if err := query(); err != nil {
+				t.Fatalf("query before the migration = %v", err)
+			}
+			d.schema.Add(1)
+
+			err := query()
+			if tt.wantStale {
+				if !isStalePlanError(err) {
+					t.Fatalf("query after the migration = %v, want the stale plan error", err)
+				}
+			} else if err != nil {
+				t.Fatalf("query after the migration = %v", err)
+			}
+
+			// The cache was cleared, so the statement is prepared again
+			for range 2 {
+				if err := query(); err != nil {
+					t.Fatalf("query after recovery = %v", err)
+				}
+			}
+			if got := d.prepares.Load(); got != tt.wantPrepares {
+				t.Errorf("prepared %d times, want %d", got, tt.wantPrepares)
+			}
+		})
+	}
+}
+
+func TestIsStalePlanError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"stale plan", &pgconn.PgError{Code: "0A000", Message: "cached plan must not change result type"}, true},
+		{"wrapped", fmt.Errorf("find user: %w", &pgconn.PgError{Code: "0A000", Message: "cached plan must not change result type"}), true},
+		{"other unsupported feature", &pgconn.PgError{Code: "0A000", Message: "cannot use subquery in check constraint"}, false},
+		{"other error", &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}, false},
+		{"not from postgres", errors.New("cached plan must not change result type"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStalePlanError(tt.err); got != tt.want {
+				t.Errorf("isStalePlanError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
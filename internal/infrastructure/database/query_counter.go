@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+const queryCounterCallback = "database:count_queries"
+
+type queryCounterKey struct{}
+
+// QueryCounter counts the statements executed on behalf of one request.
+type QueryCounter struct {
+	n atomic.Int64
+}
+
+func (q *QueryCounter) Count() int64 {
+	return q.n.Load()
+}
+
+// WithQueryCounter returns a context whose GORM queries are counted by the
+// returned counter.
+func WithQueryCounter(ctx context.Context) (context.Context, *QueryCounter) {
+	q := &QueryCounter{}
+	return context.WithValue(ctx, queryCounterKey{}, q), q
+}
+
+// registerQueryCounter increments the context's QueryCounter for every
+// statement. It is only registered in development.
+func registerQueryCounter(db *gorm.DB) error {
+	count := func(tx *gorm.DB) {
+		if tx.Statement.Context == nil {
+			return
+		}
+		if q, ok := tx.Statement.Context.Value(queryCounterKey{}).(*QueryCounter); ok {
+			q.n.Add(1)
+		}
+	}
+
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register(queryCounterCallback, count),
+		cb.Query().Before("gorm:query").Register(queryCounterCallback, count),
+		cb.Update().Before("gorm:update").Register(queryCounterCallback, count),
+		cb.Delete().Before("gorm:delete").Register(queryCounterCallback, count),
+		cb.Row().Before("gorm:row").Register(queryCounterCallback, count),
+		cb.Raw().Before("gorm:raw").Register(queryCounterCallback, count),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
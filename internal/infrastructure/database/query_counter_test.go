@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newDryRunDB returns a Postgres GORM handle that builds statements without
+// sending them, with the query counter registered.
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=unused"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registerQueryCounter(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestQueryCounter(t *testing.T) {
+	db := newDryRunDB(t)
+
+	tests := []struct {
+		name string
+		run  func(db *gorm.DB)
+		want int64
+	}{
+		{"none", func(*gorm.DB) {}, 0},
+		{"create", func(db *gorm.DB) { db.Create(&domain.User{Email: "a@example.com"}) }, 1},
+		{"query", func(db *gorm.DB) { db.Find(&[]domain.User{}) }, 1},
+		{"update", func(db *gorm.DB) { db.Model(&domain.User{ID: "1"}).Update("name", "b") }, 1},
+		{"delete", func(db *gorm.DB) { db.Delete(&domain.User{ID: "1"}) }, 1},
+		{"raw", func(db *gorm.DB) { db.Exec("SELECT 1") }, 1},
+		{"one query per listed user", func(db *gorm.DB) {
+			for range 5 {
+				db.First(&domain.User{})
+			}
+		}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, counter := WithQueryCounter(context.Background())
+			tt.run(db.WithContext(ctx))
+			if got := counter.Count(); got != tt.want {
+				t.Errorf("Count() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("contexts are counted apart", func(t *testing.T) {
+		ctxA, a := WithQueryCounter(context.Background())
+		ctxB, b := WithQueryCounter(context.Background())
+		db.WithContext(ctxA).Find(&[]domain.User{})
+		db.WithContext(ctxA).Find(&[]domain.User{})
+		db.WithContext(ctxB).Find(&[]domain.User{})
+		// Queries without a counter are ignored
+		db.WithContext(context.Background()).Find(&[]domain.User{})
+
+		if a.Count() != 2 || b.Count() != 1 {
+			t.Errorf("counts = %d, %d, want 2, 1", a.Count(), b.Count())
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	"gorm.io/gorm"
+)
+
+// WarmPool opens connections one at a time, cfg.Interval apart, until the
+// pool holds cfg.MinConns, so instances starting together during a deploy
+// don't open their whole pools at once. The connections are then returned
+// to the pool as idle ones.
+func WarmPool(ctx context.Context, db *gorm.DB, cfg config.DBWarmupConfig) error {
+	if cfg.MinConns <= 0 {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	conns := make([]*sql.Conn, 0, cfg.MinConns)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	for len(conns) < cfg.MinConns {
+		conn, err := sqlDB.Conn(ctx)
+		if err == nil {
+			err = conn.PingContext(ctx)
+		}
+		if err != nil {
+			if conn != nil {
+				conn.Close()
+			}
+			return fmt.Errorf("failed to open warm-up connection %d: %w", len(conns)+1, err)
+		}
+		conns = append(conns, conn)
+
+		if len(conns) < cfg.MinConns && cfg.Interval > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(cfg.Interval):
+			}
+		}
+	}
+
+	log.Printf("Database pool warmed up with %d connections", len(conns))
+	return nil
+}
+
+// PoolGate is a readiness gate that passes once the pool has at least
+// minConns established connections.
+func PoolGate(db *gorm.DB, minConns int) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get database instance: %w", err)
+		}
+		open := sqlDB.Stats().OpenConnections
+		details := map[string]any{"open_connections": open, "min_connections": minConns}
+		if open < minConns {
+			return details, fmt.Errorf("%d of %d connections established", open, minConns)
+		}
+		return details, nil
+	})
+}
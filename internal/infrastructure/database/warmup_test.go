@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeDriver hands out connections that only answer pings, and refuses new
+// connections once down is set.
+type fakeDriver struct {
+	down atomic.Bool
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	if d.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+func (fakeConn) Ping(context.Context) error          { return nil }
+
+var warmupDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("warmup-fake", warmupDriver)
+}
+
+// newFakePoolDB returns a GORM handle over the fake driver whose pool keeps
+// up to five idle connections.
+func newFakePoolDB(t *testing.T) (*gorm.DB, *sql.DB) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DriverName: "warmup-fake"}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxIdleConns(5)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db, sqlDB
+}
+
+func TestPoolGate(t *testing.T) {
+	db, _ := newFakePoolDB(t)
+	if err := WarmPool(context.Background(), db, config.DBWarmupConfig{MinConns: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		minConns int
+		wantErr  bool
+	}{
+		{"no minimum", 0, false},
+		{"below the pool size", 1, false},
+		{"at the pool size", 2, false},
+		{"above the pool size", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, err := PoolGate(db, tt.minConns).Check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() = %v, want error %v", err, tt.wantErr)
+			}
+			if details["open_connections"] != 2 || details["min_connections"] != tt.minConns {
+				t.Errorf("details = %v", details)
+			}
+		})
+	}
+}
+
+func TestWarmPool(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		cfg       config.DBWarmupConfig
+		down      bool
+		wantOpen  int
+		wantErr   string
+		wantCause error
+	}{
+		{"disabled", context.Background(), config.DBWarmupConfig{}, false, 0, "", nil},
+		{"opens the minimum", context.Background(), config.DBWarmupConfig{MinConns: 3, Interval: time.Millisecond}, false, 3, "", nil},
+		{"database down", context.Background(), config.DBWarmupConfig{MinConns: 3}, true, 0, "warm-up connection 1", nil},
+		{"cancelled", cancelled, config.DBWarmupConfig{MinConns: 3, Interval: time.Minute}, false, 0, "", context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warmupDriver.down.Store(tt.down)
+			t.Cleanup(func() { warmupDriver.down.Store(false) })
+			db, sqlDB := newFakePoolDB(t)
+
+			err := WarmPool(tt.ctx, db, tt.cfg)
+			switch {
+			case tt.wantCause != nil:
+				if !errors.Is(err, tt.wantCause) {
+					t.Fatalf("WarmPool() = %v, want %v", err, tt.wantCause)
+				}
+				return
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("WarmPool() = %v, want it to mention %q", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("WarmPool() = %v", err)
+			}
+
+			// The warmed connections stay in the pool as idle ones
+			stats := sqlDB.Stats()
+			if stats.OpenConnections != tt.wantOpen || stats.InUse != 0 {
+				t.Errorf("pool has %d open, %d in use, want %d idle", stats.OpenConnections, stats.InUse, tt.wantOpen)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Setup points the standard logger (used by the application and the access
+// log middleware alike) at the sink selected by cfg.Output. For file output
+// the returned closer stops the SIGHUP handler and closes the file.
+func Setup(cfg config.LoggingConfig) (io.Closer, error) {
+	switch cfg.Output {
+	case "stderr":
+		log.SetOutput(os.Stderr)
+		return nopCloser{}, nil
+	case "file":
+		return setupFile(cfg)
+	default:
+		log.SetOutput(os.Stdout)
+		return nopCloser{}, nil
+	}
+}
+
+func setupFile(cfg config.LoggingConfig) (io.Closer, error) {
+	if err := checkWritable(cfg.FilePath); err != nil {
+		return nil, err
+	}
+
+	w := &lumberjack.Logger{
+		Filename:   cfg.FilePath,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		LocalTime:  false,
+		Compress:   cfg.Compress,
+	}
+	log.SetOutput(w)
+
+	// logrotate sends SIGHUP after moving the file away; rotating reopens
+	// cfg.FilePath so writes go to the new file.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-hup:
+				if err := w.Rotate(); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to reopen log file %s: %v\n", cfg.FilePath, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return &fileSink{w: w, hup: hup, done: done}, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+type fileSink struct {
+	w    *lumberjack.Logger
+	hup  chan os.Signal
+	done chan struct{}
+}
+
+func (s *fileSink) Close() error {
+	signal.Stop(s.hup)
+	close(s.done)
+	log.SetOutput(os.Stderr)
+	return s.w.Close()
+}
+
+// checkWritable fails early with a clear error instead of losing logs later.
+func checkWritable(path string) error {
+	if path == "" {
+		return fmt.Errorf("logging output is 'file' but file_path is empty")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("log directory for %s is not writable: %w", path, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("log file %s is not writable: %w", path, err)
+	}
+	return f.Close()
+}
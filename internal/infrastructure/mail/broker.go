@@ -0,0 +1,105 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+)
+
+// sentMarkerTTL must outlast every redelivery of a job, including retries.
+const sentMarkerTTL = 24 * time.Hour
+
+// BrokerMailer publishes jobs to the message broker. They are sent by the
+// handler from NewSendHandler, which the queue consumer retries with backoff
+// and dead-letters once the attempts are used up.
+type BrokerMailer struct {
+	publisher queue.Publisher
+	renderer  *Renderer
+}
+
+func NewBrokerMailer(publisher queue.Publisher, renderer *Renderer) *BrokerMailer {
+	return &BrokerMailer{
+		publisher: publisher,
+		renderer:  renderer,
+	}
+}
+
+func (m *BrokerMailer) Enqueue(ctx context.Context, to string, data TemplateData) error {
+	job, err := NewJob(ctx, to, data)
+	if err != nil {
+		return err
+	}
+	return m.Requeue(ctx, job)
+}
+
+func (m *BrokerMailer) Requeue(ctx context.Context, job *Job) error {
+	// Render once up front so template errors reach the caller
+	if _, err := m.renderer.RenderJob(job); err != nil {
+		return err
+	}
+
+	return m.publisher.Publish(ctx, JobType, job)
+}
+
+// NewSendHandler returns the consumer handler for JobType messages. A job is
+// marked as sent in the cache before it is sent, so a redelivered copy is
+// skipped; the mark is removed again if sending fails.
+func NewSendHandler(sender MailSender, renderer *Renderer, c cache.Cache, kb *cache.CacheKeyBuilder) queue.Handler {
+	return func(ctx context.Context, msg *queue.Message) error {
+		var job Job
+		if err := json.Unmarshal(msg.Payload, &job); err != nil {
+			return fmt.Errorf("%w: %v", queue.ErrPermanent, err)
+		}
+
+		// Claim the job before sending, so a copy redelivered while this one
+		// is still in flight is skipped as well
+		sentKey := kb.EmailSent(job.ID)
+		claimed, err := c.SetNX(ctx, sentKey, 1, sentMarkerTTL)
+		if err != nil {
+			// Rather risk a duplicate than drop the mail
+			log.Printf("[mail] failed to claim job %s: %v", job.ID, err)
+			claimed = true
+		}
+		if !claimed {
+			log.Printf("[mail] job %s was already sent, skipping redelivery", job.ID)
+			return nil
+		}
+
+		rendered, err := renderer.RenderJob(&job)
+		if err != nil {
+			return fmt.Errorf("%w: %v", queue.ErrPermanent, err)
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		defer cancel()
+		if err := sender.Send(sendCtx, rendered); err != nil {
+			// Release the claim so the retry can send it
+			if delErr := c.Delete(ctx, sentKey); delErr != nil {
+				log.Printf("[mail] failed to release job %s: %v", job.ID, delErr)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// NewDeadLetterHandler records JobType messages the consumer gave up on in
+// failed, so they can be re-enqueued from the admin API.
+func NewDeadLetterHandler(failed *FailedStore) queue.DeadLetterHandler {
+	return func(ctx context.Context, msg *queue.Message, cause error) {
+		var job Job
+		if err := json.Unmarshal(msg.Payload, &job); err != nil {
+			log.Printf("[mail] dead-lettered message %s has no readable job: %v", msg.ID, err)
+			return
+		}
+
+		if err := failed.Add(ctx, &job, cause); err != nil {
+			log.Printf("[mail] failed to record failed job %s: %v", job.ID, err)
+		}
+	}
+}
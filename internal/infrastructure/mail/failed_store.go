@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+// ErrFailedJobNotFound is returned when no failed job has the given ID
+var ErrFailedJobNotFound = errors.New("failed email not found")
+
+// FailedJob is an email that ran out of delivery attempts.
+type FailedJob struct {
+	Job      Job       `json:"job"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// FailedStore keeps jobs that could not be delivered so an admin can inspect
+// and re-enqueue them. Jobs are kept in a single cache hash keyed by job ID.
+type FailedStore struct {
+	cache cache.Cache
+	key   string
+}
+
+func NewFailedStore(c cache.Cache, kb *cache.CacheKeyBuilder) *FailedStore {
+	return &FailedStore{
+		cache: c,
+		key:   kb.FailedEmails(),
+	}
+}
+
+func (s *FailedStore) Add(ctx context.Context, job *Job, cause error) error {
+	data, err := json.Marshal(FailedJob{
+		Job:      *job,
+		Error:    cause.Error(),
+		FailedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode failed email: %w", err)
+	}
+
+	return s.cache.HSet(ctx, s.key, job.ID, data)
+}
+
+// List returns failed jobs, most recent first.
+func (s *FailedStore) List(ctx context.Context) ([]FailedJob, error) {
+	entries, err := s.cache.HGetAll(ctx, s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]FailedJob, 0, len(entries))
+	for id, raw := range entries {
+		var job FailedJob
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			log.Printf("Skipping unreadable failed email %s: %v", id, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].FailedAt.After(jobs[j].FailedAt)
+	})
+
+	return jobs, nil
+}
+
+// Take removes a failed job and returns it.
+func (s *FailedStore) Take(ctx context.Context, id string) (*FailedJob, error) {
+	raw, err := s.cache.HGet(ctx, s.key, id)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, ErrFailedJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job FailedJob
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode failed email: %w", err)
+	}
+	if err := s.cache.HDel(ctx, s.key, id); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
@@ -0,0 +1,68 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/google/uuid"
+)
+
+// JobType is the message type outbound email is queued under.
+const JobType = "email.send"
+
+// Job is a queued email. The template is rendered when the job is processed,
+// and ID doubles as the idempotency key, so a redelivered job is only sent
+// once.
+type Job struct {
+	ID        string          `json:"id"`
+	Template  string          `json:"template"`
+	To        string          `json:"to"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	RequestID string          `json:"request_id,omitempty"`
+}
+
+func NewJob(ctx context.Context, to string, data TemplateData) (*Job, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s data: %w", data.TemplateName(), err)
+	}
+
+	job := &Job{
+		ID:       uuid.NewString(),
+		Template: data.TemplateName(),
+		To:       to,
+		Data:     raw,
+	}
+	if id, ok := reqctx.RequestID(ctx); ok {
+		job.RequestID = id
+	}
+
+	return job, nil
+}
+
+// templateData returns an empty data struct for each template, used to
+// decode queued jobs back into their typed form.
+var templateData = map[string]func() TemplateData{
+	TemplateVerifyEmail:     func() TemplateData { return &VerifyEmailData{} },
+	TemplateResetPassword:   func() TemplateData { return &ResetPasswordData{} },
+	TemplateInvitation:      func() TemplateData { return &InvitationData{} },
+	TemplateAccountDeletion: func() TemplateData { return &AccountDeletionData{} },
+	TemplateEmailChange:     func() TemplateData { return &EmailChangeData{} },
+}
+
+// RenderJob decodes the job's data and renders it.
+func (r *Renderer) RenderJob(job *Job) (Message, error) {
+	newData, ok := templateData[job.Template]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown mail template: %s", job.Template)
+	}
+
+	data := newData()
+	if err := json.Unmarshal(job.Data, data); err != nil {
+		return Message{}, fmt.Errorf("failed to decode %s data: %w", job.Template, err)
+	}
+
+	return r.Render(data, job.To)
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// LogSender prints messages instead of sending them. Intended for development.
+type LogSender struct {
+	from string
+}
+
+func NewLogSender(cfg config.MailConfig) *LogSender {
+	return &LogSender{
+		from: formatAddress(cfg.FromName, cfg.FromAddress),
+	}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	body := msg.TextBody
+	if body == "" {
+		body = msg.HTMLBody
+	}
+
+	log.Printf("[mail] from=%s to=%s subject=%q\n%s",
+		s.from,
+		strings.Join(msg.To, ","),
+		msg.Subject,
+		body,
+	)
+
+	return nil
+}
+
+func (s *LogSender) HealthCheck(ctx context.Context) error {
+	return nil
+}
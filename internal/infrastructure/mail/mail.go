@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// Message is a single outbound email. At least one of TextBody or HTMLBody
+// must be set; when both are present a multipart/alternative message is sent.
+type Message struct {
+	To       []string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// MailSender delivers outbound email.
+type MailSender interface {
+	// Send delivers msg to its recipients
+	Send(ctx context.Context, msg Message) error
+
+	// HealthCheck verifies the provider is reachable. Senders connect lazily,
+	// so this is the hook for health endpoints rather than a startup check.
+	HealthCheck(ctx context.Context) error
+}
+
+// NewSender returns the MailSender selected by cfg.Provider. The ses provider
+// uses the SES SMTP interface, so it shares the SMTP implementation.
+func NewSender(cfg config.MailConfig) (MailSender, error) {
+	switch cfg.Provider {
+	case "smtp", "ses":
+		return NewSMTPSender(cfg), nil
+	case "log":
+		return NewLogSender(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail provider: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,181 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+const (
+	defaultQueueSize   = 100
+	defaultMaxAttempts = 3
+	defaultRetryDelay  = 2 * time.Second
+	sendTimeout        = 30 * time.Second
+)
+
+var (
+	// ErrQueueFull is returned when the mail backlog is at capacity
+	ErrQueueFull = errors.New("mail queue is full")
+
+	// ErrQueueClosed is returned by Enqueue after Close
+	ErrQueueClosed = errors.New("mail queue is closed")
+)
+
+// Mailer sends templated mail without blocking the caller.
+type Mailer interface {
+	// Enqueue renders data for to and schedules it for delivery. Rendering
+	// errors are returned immediately; delivery errors are only logged, and
+	// jobs that run out of attempts end up in the FailedStore.
+	Enqueue(ctx context.Context, to string, data TemplateData) error
+
+	// Requeue schedules a previously failed job again
+	Requeue(ctx context.Context, job *Job) error
+}
+
+type queuedMessage struct {
+	job *Job
+	msg Message
+}
+
+// Queue delivers mail from an in-process buffer with a bounded number of
+// attempts, backing off exponentially between them. It is the fallback when
+// no message broker is configured; see BrokerMailer.
+type Queue struct {
+	sender   MailSender
+	renderer *Renderer
+	failed   *FailedStore
+	cfg      config.MailConfig
+
+	mu      sync.RWMutex
+	closed  bool
+	jobs    chan queuedMessage
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+func NewQueue(sender MailSender, renderer *Renderer, failed *FailedStore, cfg config.MailConfig) *Queue {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+
+	q := &Queue{
+		sender:   sender,
+		renderer: renderer,
+		failed:   failed,
+		cfg:      cfg,
+		jobs:     make(chan queuedMessage, cfg.QueueSize),
+		closing:  make(chan struct{}),
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+func (q *Queue) Enqueue(ctx context.Context, to string, data TemplateData) error {
+	job, err := NewJob(ctx, to, data)
+	if err != nil {
+		return err
+	}
+	return q.Requeue(ctx, job)
+}
+
+func (q *Queue) Requeue(ctx context.Context, job *Job) error {
+	msg, err := q.renderer.RenderJob(job)
+	if err != nil {
+		return err
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.jobs <- queuedMessage{job: job, msg: msg}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		q.deliver(job)
+	}
+}
+
+func (q *Queue) deliver(qm queuedMessage) {
+	job := qm.job
+	delay := q.cfg.RetryDelay
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := q.sender.Send(ctx, qm.msg)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		if attempt >= q.cfg.MaxAttempts {
+			log.Printf("[mail] giving up on %s to %s after %d attempts (job %s, request %s): %v",
+				job.Template, job.To, attempt, job.ID, job.RequestID, err)
+			q.recordFailure(job, err)
+			return
+		}
+		log.Printf("[mail] sending %s to %s failed, attempt %d/%d, retrying in %v: %v",
+			job.Template, job.To, attempt, q.cfg.MaxAttempts, delay, err)
+
+		select {
+		case <-q.closing:
+			log.Printf("[mail] shutting down, giving up on %s to %s (job %s)", job.Template, job.To, job.ID)
+			q.recordFailure(job, err)
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (q *Queue) recordFailure(job *Job, cause error) {
+	if q.failed == nil {
+		return
+	}
+	if err := q.failed.Add(context.Background(), job, cause); err != nil {
+		log.Printf("[mail] failed to record failed job %s: %v", job.ID, err)
+	}
+}
+
+// Close stops accepting messages and waits for queued ones to get their
+// current attempt. Jobs still waiting for a retry are recorded as failed.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.closing)
+	close(q.jobs)
+	q.mu.Unlock()
+
+	q.wg.Wait()
+	return nil
+}
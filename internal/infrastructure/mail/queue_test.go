@@ -0,0 +1,179 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// fakeSender fails the first failures sends and records every attempt.
+type fakeSender struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	sent     []Message
+	// block, when set, holds every send until it is closed
+	block chan struct{}
+}
+
+func (s *fakeSender) Send(ctx context.Context, msg Message) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.attempts <= s.failures {
+		return errors.New("connection refused")
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func (s *fakeSender) HealthCheck(ctx context.Context) error { return nil }
+
+func (s *fakeSender) counts() (attempts, sent int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts, len(s.sent)
+}
+
+var testData = VerifyEmailData{Name: "Budi", VerifyURL: "https://app.umkm.id/verify", ExpiresIn: time.Hour}
+
+func TestQueueRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		maxAttempts  int
+		wantAttempts int
+		wantSent     int
+	}{
+		{"first attempt", 0, 3, 1, 1},
+		{"succeeds on retry", 2, 3, 3, 1},
+		{"gives up", 5, 3, 3, 0},
+		{"single attempt", 1, 1, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &fakeSender{failures: tt.failures}
+			q := NewQueue(sender, newTestRenderer(t), nil, config.MailConfig{
+				MaxAttempts: tt.maxAttempts,
+				RetryDelay:  time.Millisecond,
+			})
+
+			if err := q.Enqueue(context.Background(), "budi@umkm.id", testData); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				attempts, sent := sender.counts()
+				if attempts >= tt.wantAttempts && sent == tt.wantSent {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("got %d attempts and %d sent, want %d and %d", attempts, sent, tt.wantAttempts, tt.wantSent)
+				}
+				time.Sleep(time.Millisecond)
+			}
+			q.Close()
+
+			if attempts, _ := sender.counts(); attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if tt.wantSent > 0 && sender.sent[0].To[0] != "budi@umkm.id" {
+				t.Errorf("sent to %v, want budi@umkm.id", sender.sent[0].To)
+			}
+		})
+	}
+}
+
+func TestQueueEnqueue(t *testing.T) {
+	sender := &fakeSender{block: make(chan struct{})}
+	q := NewQueue(sender, newTestRenderer(t), nil, config.MailConfig{QueueSize: 1, Workers: 1})
+	defer func() {
+		close(sender.block)
+		q.Close()
+	}()
+
+	// The worker takes the first message and blocks on it, the second fills
+	// the buffer
+	if err := q.Enqueue(context.Background(), "a@umkm.id", testData); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(q.jobs) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker did not pick up the first message")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := q.Enqueue(context.Background(), "b@umkm.id", testData); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Enqueue(context.Background(), "c@umkm.id", testData); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Enqueue() on a full queue error = %v, want %v", err, ErrQueueFull)
+	}
+	if err := q.Requeue(context.Background(), &Job{Template: "newsletter"}); err == nil {
+		t.Error("Requeue() with an unknown template succeeded")
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	sender := &fakeSender{}
+	q := NewQueue(sender, newTestRenderer(t), nil, config.MailConfig{})
+
+	for _, to := range []string{"a@umkm.id", "b@umkm.id"} {
+		if err := q.Enqueue(context.Background(), to, testData); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Messages queued before Close are still delivered
+	if _, sent := sender.counts(); sent != 2 {
+		t.Errorf("sent %d messages, want 2", sent)
+	}
+	if err := q.Enqueue(context.Background(), "c@umkm.id", testData); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Enqueue() after Close error = %v, want %v", err, ErrQueueClosed)
+	}
+	if err := q.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestQueueCloseDuringRetry(t *testing.T) {
+	sender := &fakeSender{failures: 1}
+	q := NewQueue(sender, newTestRenderer(t), nil, config.MailConfig{MaxAttempts: 3, RetryDelay: time.Hour})
+
+	if err := q.Enqueue(context.Background(), "budi@umkm.id", testData); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for attempts, _ := sender.counts(); attempts == 0; attempts, _ = sender.counts() {
+		if time.Now().After(deadline) {
+			t.Fatal("message was never attempted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Close gives up on the hour-long retry wait instead of sitting it out
+	done := make(chan error, 1)
+	go func() { done <- q.Close() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() waited for the retry delay")
+	}
+	if attempts, sent := sender.counts(); attempts != 1 || sent != 0 {
+		t.Errorf("got %d attempts and %d sent, want 1 and 0", attempts, sent)
+	}
+}
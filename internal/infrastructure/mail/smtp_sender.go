@@ -0,0 +1,217 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+const defaultSMTPTimeout = 10 * time.Second
+
+// SMTPSender delivers mail over SMTP, opening a connection per message.
+type SMTPSender struct {
+	cfg config.MailConfig
+}
+
+func NewSMTPSender(cfg config.MailConfig) *SMTPSender {
+	return &SMTPSender{
+		cfg: cfg,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("message has no recipients")
+	}
+	if msg.TextBody == "" && msg.HTMLBody == "" {
+		return errors.New("message has no body")
+	}
+
+	body, err := s.build(msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(s.cfg.FromAddress); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (s *SMTPSender) HealthCheck(ctx context.Context) error {
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Noop(); err != nil {
+		return fmt.Errorf("smtp NOOP failed: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial connects, negotiates TLS according to TLSMode and authenticates.
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	timeout := s.cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.TLSMode == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start smtp session: %w", err)
+	}
+
+	if s.cfg.TLSMode == "starttls" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp STARTTLS failed: %w", err)
+		}
+	}
+
+	if s.cfg.Username != "" {
+		auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+func (s *SMTPSender) build(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	headers := []string{
+		"From: " + formatAddress(s.cfg.FromName, s.cfg.FromAddress),
+		"To: " + strings.Join(msg.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+	}
+	for _, h := range headers {
+		buf.WriteString(h + "\r\n")
+	}
+
+	switch {
+	case msg.TextBody != "" && msg.HTMLBody != "":
+		boundary, err := randomBoundary()
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("Content-Type: multipart/alternative; boundary=\"" + boundary + "\"\r\n\r\n")
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain", msg.TextBody},
+			{"text/html", msg.HTMLBody},
+		} {
+			buf.WriteString("--" + boundary + "\r\n")
+			if err := writePart(&buf, part.contentType, part.body); err != nil {
+				return nil, err
+			}
+		}
+		buf.WriteString("--" + boundary + "--\r\n")
+	case msg.HTMLBody != "":
+		if err := writePart(&buf, "text/html", msg.HTMLBody); err != nil {
+			return nil, err
+		}
+	default:
+		if err := writePart(&buf, "text/plain", msg.TextBody); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writePart(buf *bytes.Buffer, contentType, body string) error {
+	buf.WriteString("Content-Type: " + contentType + "; charset=\"utf-8\"\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode message body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode message body: %w", err)
+	}
+	buf.WriteString("\r\n")
+
+	return nil
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate mime boundary: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func formatAddress(name, address string) string {
+	if name == "" {
+		return address
+	}
+	return (&mail.Address{Name: name, Address: address}).String()
+}
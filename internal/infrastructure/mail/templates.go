@@ -0,0 +1,178 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Template names. Each has a <name>.txt file defining the subject and plain
+// text body and a <name>.html file rendered inside layout.html.
+const (
+	TemplateVerifyEmail     = "verify_email"
+	TemplateResetPassword   = "reset_password"
+	TemplateInvitation      = "invitation"
+	TemplateAccountDeletion = "account_deletion"
+	TemplateEmailChange     = "email_change"
+)
+
+var templateNames = []string{
+	TemplateVerifyEmail,
+	TemplateResetPassword,
+	TemplateInvitation,
+	TemplateAccountDeletion,
+	TemplateEmailChange,
+}
+
+//go:embed templates
+var templateFS embed.FS
+
+// TemplateData is implemented by the data struct of each template, so a
+// message can only be rendered with the fields its template expects.
+type TemplateData interface {
+	TemplateName() string
+}
+
+type VerifyEmailData struct {
+	Name      string
+	VerifyURL string
+	ExpiresIn time.Duration
+}
+
+func (VerifyEmailData) TemplateName() string { return TemplateVerifyEmail }
+
+type ResetPasswordData struct {
+	Name      string
+	ResetURL  string
+	ExpiresIn time.Duration
+}
+
+func (ResetPasswordData) TemplateName() string { return TemplateResetPassword }
+
+type InvitationData struct {
+	InviterName      string
+	OrganizationName string
+	InviteURL        string
+	ExpiresIn        time.Duration
+}
+
+func (InvitationData) TemplateName() string { return TemplateInvitation }
+
+// AccountDeletionData is the notice sent when a deleted account enters its
+// grace period.
+type AccountDeletionData struct {
+	Name       string
+	DeleteAt   time.Time
+	RestoreURL string
+}
+
+func (AccountDeletionData) TemplateName() string { return TemplateAccountDeletion }
+
+// EmailChangeData is sent to the new address when a user changes their email.
+type EmailChangeData struct {
+	Name       string
+	NewEmail   string
+	ConfirmURL string
+	ExpiresIn  time.Duration
+}
+
+func (EmailChangeData) TemplateName() string { return TemplateEmailChange }
+
+var templateFuncs = map[string]any{
+	"duration": formatDuration,
+	"date":     formatDate,
+}
+
+// Renderer turns TemplateData into a Message. Templates are parsed once, so
+// a broken template fails at startup rather than when the mail is sent.
+type Renderer struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+func NewRenderer() (*Renderer, error) {
+	r := &Renderer{
+		text: make(map[string]*texttemplate.Template, len(templateNames)),
+		html: make(map[string]*htmltemplate.Template, len(templateNames)),
+	}
+
+	for _, name := range templateNames {
+		text, err := texttemplate.New(name+".txt").
+			Funcs(templateFuncs).
+			Option("missingkey=error").
+			ParseFS(templateFS, "templates/"+name+".txt")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s text template: %w", name, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("%s text template does not define a subject", name)
+		}
+
+		html, err := htmltemplate.New("layout.html").
+			Funcs(templateFuncs).
+			Option("missingkey=error").
+			ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s html template: %w", name, err)
+		}
+
+		r.text[name] = text
+		r.html[name] = html
+	}
+
+	return r, nil
+}
+
+// Render builds the message for data addressed to the given recipients.
+func (r *Renderer) Render(data TemplateData, to ...string) (Message, error) {
+	name := data.TemplateName()
+	text, ok := r.text[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown mail template: %s", name)
+	}
+
+	var subject, textBody, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	if err := text.Execute(&textBody, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s text body: %w", name, err)
+	}
+	if err := r.html[name].Execute(&htmlBody, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s html body: %w", name, err)
+	}
+
+	return Message{
+		To:       to,
+		Subject:  strings.TrimSpace(subject.String()),
+		TextBody: textBody.String(),
+		HTMLBody: htmlBody.String(),
+	}, nil
+}
+
+// formatDuration renders link lifetimes such as "2 days" or "30 minutes".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return plural(int(d/(24*time.Hour)), "day")
+	case d >= time.Hour && d%time.Hour == 0:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d.Round(time.Minute)/time.Minute), "minute")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format("2 January 2006")
+}
@@ -0,0 +1,155 @@
+package mail
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+	return r
+}
+
+// golden compares got with testdata/<name>, rewriting it with -update.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the golden file:\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	r := newTestRenderer(t)
+
+	tests := []struct {
+		data TemplateData
+	}{
+		{VerifyEmailData{Name: "Budi", VerifyURL: "https://app.umkm.id/verify?token=abc", ExpiresIn: 24 * time.Hour}},
+		{ResetPasswordData{Name: "Budi", ResetURL: "https://app.umkm.id/reset?token=abc", ExpiresIn: 30 * time.Minute}},
+		{InvitationData{InviterName: "Siti", OrganizationName: "Toko Siti", InviteURL: "https://app.umkm.id/invite/abc", ExpiresIn: 7 * 24 * time.Hour}},
+		{AccountDeletionData{Name: "Budi", DeleteAt: time.Date(2026, 11, 16, 12, 0, 0, 0, time.UTC), RestoreURL: "https://app.umkm.id/restore"}},
+		{EmailChangeData{Name: "Budi", NewEmail: "budi@toko.id", ConfirmURL: "https://app.umkm.id/confirm?token=abc", ExpiresIn: 2 * time.Hour}},
+	}
+
+	// Every template must have a golden case
+	if len(tests) != len(templateNames) {
+		t.Fatalf("%d golden cases for %d templates", len(tests), len(templateNames))
+	}
+
+	for _, tt := range tests {
+		name := tt.data.TemplateName()
+		t.Run(name, func(t *testing.T) {
+			msg, err := r.Render(tt.data, "budi@umkm.id")
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if len(msg.To) != 1 || msg.To[0] != "budi@umkm.id" {
+				t.Errorf("To = %v, want [budi@umkm.id]", msg.To)
+			}
+			if msg.Subject == "" || strings.ContainsAny(msg.Subject, "\r\n") {
+				t.Errorf("Subject = %q, want a single non-empty line", msg.Subject)
+			}
+
+			golden(t, name+".txt.golden", "Subject: "+msg.Subject+"\n\n"+msg.TextBody)
+			golden(t, name+".html.golden", msg.HTMLBody)
+		})
+	}
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	r := newTestRenderer(t)
+
+	msg, err := r.Render(VerifyEmailData{
+		Name:      `<script>alert("x")</script>`,
+		VerifyURL: `javascript:alert(1)`,
+		ExpiresIn: time.Hour,
+	}, "budi@umkm.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(msg.HTMLBody, "<script>") {
+		t.Errorf("HTML body contains an unescaped name:\n%s", msg.HTMLBody)
+	}
+	if strings.Contains(msg.HTMLBody, `href="javascript:`) {
+		t.Errorf("HTML body links to a javascript URL:\n%s", msg.HTMLBody)
+	}
+	// The text body is sent as text/plain and stays as given
+	if !strings.Contains(msg.TextBody, `<script>alert("x")</script>`) {
+		t.Errorf("text body = %q, want the name verbatim", msg.TextBody)
+	}
+}
+
+func TestRenderJob(t *testing.T) {
+	r := newTestRenderer(t)
+
+	tests := []struct {
+		name    string
+		job     *Job
+		wantErr bool
+	}{
+		{"known template", &Job{Template: TemplateResetPassword, To: "budi@umkm.id", Data: []byte(`{"Name":"Budi","ResetURL":"https://app.umkm.id/reset","ExpiresIn":1800000000000}`)}, false},
+		{"unknown template", &Job{Template: "newsletter", To: "budi@umkm.id", Data: []byte(`{}`)}, true},
+		{"malformed data", &Job{Template: TemplateResetPassword, To: "budi@umkm.id", Data: []byte(`{`)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := r.RenderJob(tt.job)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderJob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !strings.Contains(msg.TextBody, "30 minutes") {
+				t.Errorf("text body = %q, want the decoded expiry", msg.TextBody)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{24 * time.Hour, "1 day"},
+		{72 * time.Hour, "3 days"},
+		{time.Hour, "1 hour"},
+		{36 * time.Hour, "36 hours"},
+		{90 * time.Minute, "90 minutes"},
+		{time.Minute, "1 minute"},
+		{89 * time.Second, "1 minute"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.d.String(), func(t *testing.T) {
+			if got := formatDuration(tt.d); got != tt.want {
+				t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package mlclient
+
+import (
+	"expvar"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every request immediately
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of probes through
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+const (
+	defaultBreakerWindow      = 30 * time.Second
+	defaultBreakerOpenTimeout = 15 * time.Second
+)
+
+// breakerMetrics is published under /debug/vars when that endpoint is
+// served.
+var breakerMetrics = expvar.NewMap("ml_circuit_breaker")
+
+// Breaker stops calls to the ML service after repeated failures so callers
+// fail fast instead of waiting out timeouts against a service that is down.
+// It opens after FailureThreshold failures within Window, rejects calls for
+// OpenTimeout, then lets probe calls through: a successful probe closes it,
+// a failed one opens it again.
+type Breaker struct {
+	cfg   config.CircuitBreakerConfig
+	clock clock.Clock
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures []time.Time
+	openedAt time.Time
+	probes   int
+}
+
+func NewBreaker(cfg config.CircuitBreakerConfig, clk clock.Clock) *Breaker {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultBreakerWindow
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+
+	b := &Breaker{cfg: cfg, clock: clk}
+	breakerMetrics.Set("state", expvar.Func(func() any { return b.State().String() }))
+	return b
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by exactly one Record.
+func (b *Breaker) Allow() bool {
+	if b.cfg.FailureThreshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.currentState() {
+	case BreakerOpen:
+		breakerMetrics.Add("rejected", 1)
+		return false
+	case BreakerHalfOpen:
+		if b.probes >= b.cfg.HalfOpenRequests {
+			breakerMetrics.Add("rejected", 1)
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+// Record reports the outcome of an allowed call. Only failures that say
+// something about the service's health should be recorded as failures.
+func (b *Breaker) Record(success bool) {
+	if b.cfg.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	switch b.currentState() {
+	case BreakerHalfOpen:
+		b.probes--
+		if success {
+			b.setState(BreakerClosed)
+			b.failures = nil
+			return
+		}
+		b.open(now)
+
+	case BreakerClosed:
+		if success {
+			return
+		}
+		b.failures = append(b.failures, now)
+		b.pruneFailures(now)
+		if len(b.failures) >= b.cfg.FailureThreshold {
+			b.open(now)
+		}
+
+	case BreakerOpen:
+		// A call allowed before the breaker opened finished late; nothing
+		// to learn from it
+	}
+}
+
+// State returns the breaker's current state.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState()
+}
+
+// currentState moves an open breaker to half-open once its timeout has
+// passed. Callers must hold mu.
+func (b *Breaker) currentState() BreakerState {
+	if b.state == BreakerOpen && b.clock.Now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.setState(BreakerHalfOpen)
+		b.probes = 0
+	}
+	return b.state
+}
+
+func (b *Breaker) open(now time.Time) {
+	b.setState(BreakerOpen)
+	b.openedAt = now
+	b.failures = nil
+	breakerMetrics.Add("opened", 1)
+}
+
+func (b *Breaker) setState(state BreakerState) {
+	if b.state != state {
+		log.Printf("[ml] circuit breaker %s -> %s", b.state, state)
+	}
+	b.state = state
+}
+
+func (b *Breaker) pruneFailures(now time.Time) {
+	cutoff := now.Add(-b.cfg.Window)
+	i := 0
+	for i < len(b.failures) && !b.failures[i].After(cutoff) {
+		i++
+	}
+	b.failures = b.failures[i:]
+}
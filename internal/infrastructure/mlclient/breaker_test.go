@@ -0,0 +1,224 @@
+package mlclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+var testBreakerConfig = config.CircuitBreakerConfig{
+	FailureThreshold: 3,
+	Window:           10 * time.Second,
+	OpenTimeout:      30 * time.Second,
+	HalfOpenRequests: 1,
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	// Each step advances the clock, asks the breaker for a call and, when
+	// it was allowed, records the outcome
+	type step struct {
+		name      string
+		advance   time.Duration
+		success   bool
+		wantAllow bool
+		wantState BreakerState
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "closed, open, half-open, closed",
+			steps: []step{
+				{"first failure", 0, false, true, BreakerClosed},
+				{"second failure", time.Second, false, true, BreakerClosed},
+				{"third failure opens", time.Second, false, true, BreakerOpen},
+				{"rejected while open", time.Second, true, false, BreakerOpen},
+				{"still open before the timeout", 28 * time.Second, true, false, BreakerOpen},
+				{"probe succeeds", time.Second, true, true, BreakerClosed},
+				{"closed again", 0, true, true, BreakerClosed},
+			},
+		},
+		{
+			name: "failed probe reopens",
+			steps: []step{
+				{"failure", 0, false, true, BreakerClosed},
+				{"failure", 0, false, true, BreakerClosed},
+				{"failure opens", 0, false, true, BreakerOpen},
+				{"probe fails", 30 * time.Second, false, true, BreakerOpen},
+				{"rejected for a new timeout", 29 * time.Second, true, false, BreakerOpen},
+				{"next probe succeeds", time.Second, true, true, BreakerClosed},
+			},
+		},
+		{
+			name: "failures outside the window",
+			steps: []step{
+				{"failure", 0, false, true, BreakerClosed},
+				{"failure", 0, false, true, BreakerClosed},
+				{"first two aged out", 11 * time.Second, false, true, BreakerClosed},
+				{"second in the window", time.Second, false, true, BreakerClosed},
+				{"third in the window opens", time.Second, false, true, BreakerOpen},
+			},
+		},
+		{
+			name: "successes don't reset the count",
+			steps: []step{
+				{"failure", 0, false, true, BreakerClosed},
+				{"success", 0, true, true, BreakerClosed},
+				{"failure", 0, false, true, BreakerClosed},
+				{"success", 0, true, true, BreakerClosed},
+				{"failure opens", 0, false, true, BreakerOpen},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+			b := NewBreaker(testBreakerConfig, clk)
+
+			for i, s := range tt.steps {
+				clk.Advance(s.advance)
+				allowed := b.Allow()
+				if allowed != s.wantAllow {
+					t.Fatalf("step %d (%s): Allow() = %v, want %v", i, s.name, allowed, s.wantAllow)
+				}
+				if allowed {
+					b.Record(s.success)
+				}
+				if got := b.State(); got != s.wantState {
+					t.Fatalf("step %d (%s): state = %s, want %s", i, s.name, got, s.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestBreakerHalfOpenProbes(t *testing.T) {
+	clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	cfg := testBreakerConfig
+	cfg.HalfOpenRequests = 2
+	b := NewBreaker(cfg, clk)
+
+	for range cfg.FailureThreshold {
+		b.Allow()
+		b.Record(false)
+	}
+	clk.Advance(cfg.OpenTimeout)
+
+	// Only HalfOpenRequests probes run at once
+	if !b.Allow() || !b.Allow() {
+		t.Fatal("half-open breaker rejected a probe")
+	}
+	if b.Allow() {
+		t.Fatal("half-open breaker allowed more probes than configured")
+	}
+	if got := b.State(); got != BreakerHalfOpen {
+		t.Fatalf("state = %s, want %s", got, BreakerHalfOpen)
+	}
+
+	b.Record(true)
+	if got := b.State(); got != BreakerClosed {
+		t.Errorf("state after a successful probe = %s, want %s", got, BreakerClosed)
+	}
+	// The other probe finishing late changes nothing
+	b.Record(true)
+	if got := b.State(); got != BreakerClosed {
+		t.Errorf("state after the second probe = %s, want %s", got, BreakerClosed)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := NewBreaker(config.CircuitBreakerConfig{}, clock.NewMock(time.Now()))
+	for range 100 {
+		if !b.Allow() {
+			t.Fatal("disabled breaker rejected a call")
+		}
+		b.Record(false)
+	}
+	if got := b.State(); got != BreakerClosed {
+		t.Errorf("state = %s, want %s", got, BreakerClosed)
+	}
+}
+
+// switchable fails with 500 until healthy is set.
+func switchable(calls *atomic.Int32, healthy *atomic.Bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(predictOK))
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	cfg := testMLConfig
+	cfg.RetryCount = 0
+	cfg.CircuitBreaker = testBreakerConfig
+	c := newTestClient(t, cfg, switchable(&calls, &healthy))
+	clk := c.breaker.clock.(*clock.Mock)
+	ctx := context.Background()
+	req := PredictRequest{Model: "demand-v1"}
+
+	for range cfg.CircuitBreaker.FailureThreshold {
+		if _, err := c.Predict(ctx, req); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("Predict() error = %v, want %v", err, ErrUnavailable)
+		}
+	}
+	if got := c.BreakerState(); got != BreakerOpen {
+		t.Fatalf("state = %s, want %s", got, BreakerOpen)
+	}
+
+	// While open, calls fail without reaching the service
+	before := calls.Load()
+	_, err := c.Predict(ctx, req)
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Predict() while open error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if calls.Load() != before {
+		t.Error("service was called while the breaker was open")
+	}
+
+	// The health check bypasses the breaker and reports its state
+	checks := c.HealthChecks()
+	details, err := checks[0].Checker.Check(ctx)
+	if !errors.Is(err, ErrUnavailable) || details["circuit_state"] != "open" {
+		t.Errorf("check = %v, %v, want the service down and the breaker open", details, err)
+	}
+
+	healthy.Store(true)
+	clk.Advance(cfg.CircuitBreaker.OpenTimeout)
+	if _, err := c.Predict(ctx, req); err != nil {
+		t.Fatalf("Predict() probe error = %v", err)
+	}
+	if got := c.BreakerState(); got != BreakerClosed {
+		t.Errorf("state after the probe = %s, want %s", got, BreakerClosed)
+	}
+}
+
+func TestClientRejectionsKeepBreakerClosed(t *testing.T) {
+	var calls atomic.Int32
+	cfg := testMLConfig
+	cfg.CircuitBreaker = testBreakerConfig
+	c := newTestClient(t, cfg, sequence(&calls, response{400, `{"detail":"bad input"}`, 0}))
+
+	for range 2 * cfg.CircuitBreaker.FailureThreshold {
+		if _, err := c.Predict(context.Background(), PredictRequest{Model: "demand-v1"}); !errors.Is(err, ErrRejected) {
+			t.Fatalf("Predict() error = %v, want %v", err, ErrRejected)
+		}
+	}
+	if got := c.BreakerState(); got != BreakerClosed {
+		t.Errorf("state = %s, want %s", got, BreakerClosed)
+	}
+}
@@ -0,0 +1,118 @@
+package mlclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+// cacheMetrics counts hits and misses per endpoint, published under
+// /debug/vars when that endpoint is served.
+var cacheMetrics = expvar.NewMap("ml_cache")
+
+// responseCache keeps ML responses keyed by a hash of the model version and
+// the request. Keys live under a namespace number; flushing bumps it, and the
+// old entries age out with their TTL.
+type responseCache struct {
+	cache      cache.Cache
+	keyBuilder *cache.CacheKeyBuilder
+	cfg        config.MLCacheConfig
+}
+
+// WithCache makes the client cache responses of the endpoints with a TTL in
+// cfg. It returns the client for chaining.
+func (c *Client) WithCache(cc cache.Cache, kb *cache.CacheKeyBuilder, cfg config.MLCacheConfig) *Client {
+	if cfg.Enabled {
+		c.responses = &responseCache{cache: cc, keyBuilder: kb, cfg: cfg}
+	}
+	return c
+}
+
+// FlushCache discards every cached response. It is safe to call on a nil
+// client.
+func (c *Client) FlushCache(ctx context.Context) error {
+	if c == nil || c.responses == nil {
+		return nil
+	}
+	if _, err := c.responses.cache.Increment(ctx, c.responses.keyBuilder.MLNamespace()); err != nil {
+		return fmt.Errorf("failed to flush ml cache: %w", err)
+	}
+	return nil
+}
+
+// cached serves out from the cache for identical requests to op, or calls
+// fetch and caches its result. key is the part of the request that
+// determines the response. It reports whether the response was a hit.
+// Cache failures only cost the saving, so they are logged and ignored.
+func (c *Client) cached(ctx context.Context, op string, key any, noCache bool, out any, fetch func() error) (bool, error) {
+	rc := c.responses
+	if rc == nil || noCache || rc.cfg.TTLs[op] <= 0 {
+		return false, fetch()
+	}
+
+	cacheKey, err := rc.key(ctx, op, key)
+	if err != nil {
+		log.Printf("[ml] %s: skipping cache: %v", op, err)
+		return false, fetch()
+	}
+
+	raw, err := rc.cache.Get(ctx, cacheKey)
+	if err == nil && json.Unmarshal([]byte(raw), out) == nil {
+		cacheMetrics.Add(op+"_hits", 1)
+		return true, nil
+	}
+	if err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+		log.Printf("[ml] %s: cache read failed: %v", op, err)
+	}
+	cacheMetrics.Add(op+"_misses", 1)
+
+	if err := fetch(); err != nil {
+		return false, err
+	}
+	if r, ok := out.(interface{ cacheable() bool }); ok && !r.cacheable() {
+		return false, nil
+	}
+
+	data, err := json.Marshal(out)
+	if err == nil {
+		err = rc.cache.Set(ctx, cacheKey, data, rc.cfg.TTLs[op])
+	}
+	if err != nil {
+		log.Printf("[ml] %s: cache write failed: %v", op, err)
+	}
+	return false, nil
+}
+
+func (rc *responseCache) key(ctx context.Context, op string, key any) (string, error) {
+	namespace := int64(0)
+	raw, err := rc.cache.Get(ctx, rc.keyBuilder.MLNamespace())
+	switch {
+	case err == nil:
+		if namespace, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid namespace %q", raw)
+		}
+	case !errors.Is(err, cache.ErrKeyNotFound):
+		return "", err
+	}
+
+	// Struct fields marshal in declaration order and map keys sorted, so
+	// equal requests always produce the same bytes
+	payload, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s\x00%s\x00", rc.cfg.ModelVersion, op)
+	sum.Write(payload)
+
+	return rc.keyBuilder.MLResponse(namespace, op, hex.EncodeToString(sum.Sum(nil))), nil
+}
@@ -0,0 +1,249 @@
+// Package mlclient is the HTTP client for the Python ML service.
+package mlclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+
+	defaultTimeout = 30 * time.Second
+
+	// maxResponseSize bounds how much of a response body is read
+	maxResponseSize = 10 * 1024 * 1024
+)
+
+// Client calls the ML service. Failed calls are retried with exponential
+// backoff on connection errors, timeouts and 5xx responses, never on 4xx.
+// Those failures also feed a circuit breaker, and while it is open calls
+// fail immediately with ErrCircuitOpen.
+type Client struct {
+	baseURL    string
+	token      string
+	timeout    time.Duration
+	retries    int
+	retryDelay time.Duration
+	httpClient *http.Client
+	breaker    *Breaker
+	// responses is nil unless WithCache enabled caching
+	responses *responseCache
+}
+
+func New(cfg config.MLConfig, clk clock.Clock) *Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.ServiceURL, "/"),
+		token:      cfg.ServiceToken,
+		timeout:    timeout,
+		retries:    cfg.RetryCount,
+		retryDelay: cfg.RetryDelay,
+		httpClient: &http.Client{},
+		breaker:    NewBreaker(cfg.CircuitBreaker, clk),
+	}
+}
+
+// BreakerState returns the state of the client's circuit breaker.
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+func (c *Client) Predict(ctx context.Context, req PredictRequest) (*PredictResponse, error) {
+	var resp PredictResponse
+	hit, err := c.cached(ctx, "predict", req, req.NoCache, &resp, func() error {
+		return c.do(ctx, "predict", http.MethodPost, "/predict", req, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Cached = hit
+	return &resp, nil
+}
+
+func (c *Client) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	// Who asks, and in which conversation, doesn't change the answer
+	key := req
+	key.ConversationID, key.UserID = "", ""
+
+	var resp ChatResponse
+	hit, err := c.cached(ctx, "chat", key, req.NoCache, &resp, func() error {
+		return c.do(ctx, "chat", http.MethodPost, "/chat", req, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Cached = hit
+	return &resp, nil
+}
+
+// Health checks that the service is up. It is not retried and bypasses the
+// circuit breaker.
+func (c *Client) Health(ctx context.Context) error {
+	_, err := c.attempt(ctx, "health", http.MethodGet, "/healthz", nil, nil)
+	return err
+}
+
+// HealthChecks reports the ML service as optional, with the state of the
+// circuit breaker as details. A nil client, when the service isn't
+// configured, has no checks.
+func (c *Client) HealthChecks() []health.Check {
+	if c == nil {
+		return nil
+	}
+	return []health.Check{{
+		Name: "ml",
+		Checker: health.CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+			details := map[string]any{"circuit_state": c.BreakerState().String()}
+			return details, c.Health(ctx)
+		}),
+	}}
+}
+
+func (c *Client) do(ctx context.Context, op, method, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode request: %w", op, err)
+	}
+
+	return c.withRetries(ctx, op, func() (bool, error) {
+		return c.attempt(ctx, op, method, path, body, out)
+	})
+}
+
+// withRetries runs attempt until it succeeds, fails for good or the retry
+// budget runs out, checking the circuit breaker before every attempt.
+func (c *Client) withRetries(ctx context.Context, op string, attempt func() (bool, error)) error {
+	delay := c.retryDelay
+	for n := 0; ; n++ {
+		if !c.breaker.Allow() {
+			return ErrCircuitOpen
+		}
+		retry, err := attempt()
+		// Rejected input and callers giving up say nothing about the
+		// service's health
+		c.breaker.Record(err == nil || errors.Is(err, ErrRejected) || ctx.Err() != nil)
+		if err == nil || !retry || n >= c.retries {
+			return err
+		}
+
+		log.Printf("[ml] %s failed, attempt %d/%d, retrying in %v: %v", op, n+1, c.retries+1, delay, err)
+		select {
+		case <-ctx.Done():
+			return &Error{Op: op, kind: ErrUnavailable, cause: ctx.Err()}
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// attempt makes a single request and reports whether a failure is worth
+// retrying.
+func (c *Client) attempt(ctx context.Context, op, method, path string, body []byte, out any) (bool, error) {
+	// Each attempt gets its own deadline, so a slow response is retried as
+	// long as the caller's context allows
+	attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := c.newRequest(attemptCtx, method, path, body, "application/json")
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to build request: %w", op, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Retrying is pointless once the caller has given up
+		return ctx.Err() == nil, &Error{Op: op, kind: ErrUnavailable, cause: err}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return true, &Error{Op: op, StatusCode: resp.StatusCode, kind: ErrUnavailable, cause: err}
+	}
+	if resp.StatusCode >= 400 {
+		return statusError(op, resp.StatusCode, data)
+	}
+
+	if out == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return false, &Error{Op: op, StatusCode: resp.StatusCode, Message: "malformed response", kind: ErrUnavailable, cause: err}
+	}
+	return false, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body []byte, accept string) (*http.Request, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", accept)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if id, ok := reqctx.RequestID(ctx); ok {
+		req.Header.Set(requestIDHeader, id)
+	}
+
+	return req, nil
+}
+
+// statusError classifies an error response and reports whether it is worth
+// retrying.
+func statusError(op string, status int, data []byte) (bool, error) {
+	switch {
+	case status >= 500:
+		return true, &Error{Op: op, StatusCode: status, Message: errorMessage(data), kind: ErrUnavailable}
+	case status == http.StatusTooManyRequests:
+		return false, &Error{Op: op, StatusCode: status, Message: errorMessage(data), kind: ErrUnavailable}
+	default:
+		return false, &Error{Op: op, StatusCode: status, Message: errorMessage(data), kind: ErrRejected}
+	}
+}
+
+// errorMessage extracts the service's error message, falling back to the
+// start of the raw body.
+func errorMessage(data []byte) string {
+	var body errorBody
+	if err := json.Unmarshal(data, &body); err == nil {
+		if body.Detail != "" {
+			return body.Detail
+		}
+		if body.Error != "" {
+			return body.Error
+		}
+	}
+
+	msg := strings.TrimSpace(string(data))
+	if len(msg) > 200 {
+		msg = msg[:200]
+	}
+	return msg
+}
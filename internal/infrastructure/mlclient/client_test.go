@@ -0,0 +1,248 @@
+package mlclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+var testMLConfig = config.MLConfig{
+	Timeout:      200 * time.Millisecond,
+	RetryCount:   2,
+	RetryDelay:   time.Millisecond,
+	ServiceToken: "service-token",
+}
+
+// newTestClient points a client configured with cfg at handler.
+func newTestClient(t *testing.T, cfg config.MLConfig, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg.ServiceURL = srv.URL + "/"
+	return New(cfg, clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+}
+
+type response struct {
+	status int
+	body   string
+	delay  time.Duration
+}
+
+// sequence answers each call with the next of responses, repeating the
+// last, and counts the calls.
+func sequence(calls *atomic.Int32, responses ...response) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		resp := responses[min(n, len(responses)-1)]
+		if resp.delay > 0 {
+			select {
+			case <-time.After(resp.delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}
+}
+
+const predictOK = `{"model":"demand-v1","prediction":[12,15],"confidence":0.87}`
+
+func TestPredictRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []response
+		wantErr   error
+		wantCalls int32
+	}{
+		{"success", []response{{200, predictOK, 0}}, nil, 1},
+		{"500 then success", []response{{500, `{"detail":"worker crashed"}`, 0}, {200, predictOK, 0}}, nil, 2},
+		{"503 twice then success", []response{{503, "", 0}, {503, "", 0}, {200, predictOK, 0}}, nil, 3},
+		{"500 until retries run out", []response{{500, "", 0}}, ErrUnavailable, 3},
+		{"slow then success", []response{{200, predictOK, time.Second}, {200, predictOK, 0}}, nil, 2},
+		{"always slow", []response{{200, predictOK, time.Second}}, ErrUnavailable, 3},
+		{"400 is not retried", []response{{400, `{"detail":"inputs.sku is required"}`, 0}}, ErrRejected, 1},
+		{"422 is not retried", []response{{422, `{"error":"unknown model"}`, 0}}, ErrRejected, 1},
+		{"429 is not retried", []response{{429, "", 0}}, ErrUnavailable, 1},
+		{"malformed JSON", []response{{200, `{"model":`, 0}}, ErrUnavailable, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c := newTestClient(t, testMLConfig, sequence(&calls, tt.responses...))
+
+			resp, err := c.Predict(context.Background(), PredictRequest{Model: "demand-v1", Inputs: map[string]any{"sku": "A1"}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Predict() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("service called %d times, want %d", got, tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				// The two kinds never overlap
+				if errors.Is(err, ErrUnavailable) && errors.Is(err, ErrRejected) {
+					t.Errorf("error %v is both unavailable and rejected", err)
+				}
+				return
+			}
+			if resp.Model != "demand-v1" || resp.Confidence != 0.87 || string(resp.Prediction) != "[12,15]" {
+				t.Errorf("Predict() = %+v", resp)
+			}
+		})
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+	}{
+		{"detail", 400, `{"detail":"inputs.sku is required"}`, "inputs.sku is required"},
+		{"error", 422, `{"error":"unknown model"}`, "unknown model"},
+		{"plain text", 400, "bad request\n", "bad request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c := newTestClient(t, testMLConfig, sequence(&calls, response{tt.status, tt.body, 0}))
+
+			_, err := c.Predict(context.Background(), PredictRequest{Model: "demand-v1"})
+			var mlErr *Error
+			if !errors.As(err, &mlErr) {
+				t.Fatalf("Predict() error = %v, want an *Error", err)
+			}
+			if mlErr.StatusCode != tt.status || mlErr.Message != tt.wantMessage || mlErr.Op != "predict" {
+				t.Errorf("error = %+v, want status %d and message %q", mlErr, tt.status, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	var got http.Header
+	var path string
+	c := newTestClient(t, testMLConfig, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		path = r.URL.Path
+		w.Write([]byte(`{"message":{"role":"assistant","content":"Halo!"},"usage":{"prompt_tokens":3,"completion_tokens":2}}`))
+	})
+
+	ctx := reqctx.WithRequestID(context.Background(), "req-42")
+	resp, err := c.Chat(ctx, ChatRequest{Messages: []ChatMessage{{Role: RoleUser, Content: "Halo"}}})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Message.Content != "Halo!" || resp.Usage.CompletionTokens != 2 {
+		t.Errorf("Chat() = %+v", resp)
+	}
+
+	if path != "/chat" {
+		t.Errorf("path = %q, want /chat", path)
+	}
+	want := map[string]string{
+		"Authorization": "Bearer service-token",
+		"X-Request-Id":  "req-42",
+		"Content-Type":  "application/json",
+		"Accept":        "application/json",
+	}
+	for header, value := range want {
+		if got.Get(header) != value {
+			t.Errorf("%s = %q, want %q", header, got.Get(header), value)
+		}
+	}
+}
+
+func TestNoTokenNoRequestID(t *testing.T) {
+	var got http.Header
+	cfg := testMLConfig
+	cfg.ServiceToken = ""
+	c := newTestClient(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(predictOK))
+	})
+
+	if _, err := c.Predict(context.Background(), PredictRequest{Model: "demand-v1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Authorization") != "" || got.Get("X-Request-Id") != "" {
+		t.Errorf("headers = %v, want no Authorization or X-Request-ID", got)
+	}
+}
+
+func TestConnectionRefused(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	cfg := testMLConfig
+	cfg.ServiceURL = url
+	c := New(cfg, clock.NewMock(time.Now()))
+
+	_, err := c.Predict(context.Background(), PredictRequest{Model: "demand-v1"})
+	if !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrRejected) {
+		t.Errorf("Predict() error = %v, want %v", err, ErrUnavailable)
+	}
+}
+
+func TestCallerDeadline(t *testing.T) {
+	var calls atomic.Int32
+	cfg := testMLConfig
+	cfg.RetryCount = 5
+	cfg.RetryDelay = time.Hour
+	c := newTestClient(t, cfg, sequence(&calls, response{500, "", 0}))
+
+	// The caller giving up ends the backoff wait
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.Predict(ctx, PredictRequest{Model: "demand-v1"})
+	if !errors.Is(err, ErrUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Predict() error = %v, want %v caused by the deadline", err, ErrUnavailable)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Predict() took %v, want it to stop at the caller's deadline", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("service called %d times, want 1", got)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{"up", 200, nil},
+		{"down", 503, ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c := newTestClient(t, testMLConfig, sequence(&calls, response{tt.status, `{"status":"ok"}`, 0}))
+
+			if err := c.Health(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Health() error = %v, want %v", err, tt.wantErr)
+			}
+			// Health checks are not retried
+			if got := calls.Load(); got != 1 {
+				t.Errorf("service called %d times, want 1", got)
+			}
+		})
+	}
+}
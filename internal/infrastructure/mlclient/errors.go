@@ -0,0 +1,51 @@
+package mlclient
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnavailable means the ML service could not be reached, timed out or
+	// failed on its side; retrying later may succeed
+	ErrUnavailable = errors.New("ml service unavailable")
+
+	// ErrRejected means the ML service refused the request as invalid;
+	// retrying the same input will fail again
+	ErrRejected = errors.New("ml service rejected input")
+
+	// ErrCircuitOpen is returned without calling the service while the
+	// circuit breaker is open. It matches ErrUnavailable.
+	ErrCircuitOpen = &Error{Op: "call", Message: "circuit breaker open", kind: ErrUnavailable}
+)
+
+// Error describes a failed call. It matches ErrUnavailable or ErrRejected
+// with errors.Is.
+type Error struct {
+	Op         string
+	StatusCode int
+	Message    string
+	kind       error
+	cause      error
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Op, e.kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.kind}
+	}
+	return []error{e.kind, e.cause}
+}
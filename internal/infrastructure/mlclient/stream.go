@@ -0,0 +1,138 @@
+package mlclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// maxChunkSize bounds a single line of a streamed response.
+const maxChunkSize = 1024 * 1024
+
+// ChatStream reads a streamed chat reply. The service sends one JSON object
+// per line (NDJSON), ending with a chunk that has Done set.
+type ChatStream struct {
+	op      string
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	cancel  context.CancelFunc
+	idle    *time.Timer
+	timeout time.Duration
+	done    bool
+	// timedOut is set when the idle timer cancelled the request
+	timedOut atomic.Bool
+}
+
+// ChatStream starts a streamed chat. Opening the stream is retried like any
+// other call; once it is open, the configured timeout applies to the gap
+// between chunks rather than to the whole reply. Cancelling ctx aborts the
+// upstream request. The caller must Close the stream.
+func (c *Client) ChatStream(ctx context.Context, req ChatRequest) (*ChatStream, error) {
+	const op = "chat stream"
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to encode request: %w", op, err)
+	}
+
+	var stream *ChatStream
+	err = c.withRetries(ctx, op, func() (bool, error) {
+		var retry bool
+		stream, retry, err = c.openStream(ctx, op, "/chat/stream", body)
+		return retry, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (c *Client) openStream(ctx context.Context, op, path string, body []byte) (*ChatStream, bool, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	stream := &ChatStream{op: op, cancel: cancel, timeout: c.timeout}
+	idle := time.AfterFunc(c.timeout, func() {
+		stream.timedOut.Store(true)
+		cancel()
+	})
+	stream.idle = idle
+
+	req, err := c.newRequest(streamCtx, http.MethodPost, path, body, "application/x-ndjson")
+	if err != nil {
+		idle.Stop()
+		cancel()
+		return nil, false, fmt.Errorf("%s: failed to build request: %w", op, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		idle.Stop()
+		cancel()
+		return nil, ctx.Err() == nil, &Error{Op: op, kind: ErrUnavailable, cause: err}
+	}
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		resp.Body.Close()
+		idle.Stop()
+		cancel()
+		retry, err := statusError(op, resp.StatusCode, data)
+		return nil, retry, err
+	}
+
+	stream.body = resp.Body
+	stream.scanner = bufio.NewScanner(resp.Body)
+	stream.scanner.Buffer(make([]byte, 0, 4096), maxChunkSize)
+	idle.Reset(c.timeout)
+
+	return stream, false, nil
+}
+
+// Next returns the next chunk, or io.EOF after the final one.
+func (s *ChatStream) Next() (*ChatChunk, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+
+	for s.scanner.Scan() {
+		s.idle.Reset(s.timeout)
+
+		line := s.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, &Error{Op: s.op, Message: "malformed chunk", kind: ErrUnavailable, cause: err}
+		}
+		if chunk.Error != "" {
+			return nil, &Error{Op: s.op, Message: chunk.Error, kind: ErrUnavailable}
+		}
+		if chunk.Done {
+			s.done = true
+		}
+		return &chunk, nil
+	}
+
+	err := s.scanner.Err()
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	if s.timedOut.Load() {
+		err = fmt.Errorf("no data for %v: %w", s.timeout, err)
+	}
+	return nil, &Error{Op: s.op, Message: "stream ended early", kind: ErrUnavailable, cause: err}
+}
+
+// Close releases the connection, aborting the upstream request if the reply
+// hasn't been read to the end.
+func (s *ChatStream) Close() error {
+	s.idle.Stop()
+	s.cancel()
+	return s.body.Close()
+}
@@ -0,0 +1,78 @@
+package mlclient
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type PredictRequest struct {
+	Model  string         `json:"model"`
+	Inputs map[string]any `json:"inputs"`
+	// NoCache skips the response cache for this call
+	NoCache bool `json:"-"`
+}
+
+type PredictResponse struct {
+	Model      string          `json:"model"`
+	Prediction json.RawMessage `json:"prediction"`
+	Confidence float64         `json:"confidence,omitempty"`
+	// Cached is set when the response came from the cache
+	Cached bool `json:"-"`
+}
+
+// Chat roles
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatRequest struct {
+	// ConversationID lets the service correlate turns of one conversation
+	ConversationID string        `json:"conversation_id,omitempty"`
+	Messages       []ChatMessage `json:"messages"`
+	MaxTokens      int           `json:"max_tokens,omitempty"`
+	Temperature    *float64      `json:"temperature,omitempty"`
+	// UserID lets the service attribute usage; it is never shown to the model
+	UserID string `json:"user_id,omitempty"`
+	// NoCache skips the response cache for this call; streamed chats are
+	// never cached
+	NoCache bool `json:"-"`
+}
+
+type ChatResponse struct {
+	Message ChatMessage `json:"message"`
+	Usage   TokenUsage  `json:"usage"`
+	// Cached is set when the response came from the cache
+	Cached bool `json:"-"`
+}
+
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// cacheable keeps empty replies out of the cache, so a retry can do better.
+func (r *ChatResponse) cacheable() bool {
+	return strings.TrimSpace(r.Message.Content) != ""
+}
+
+// ChatChunk is one line of a streamed chat reply. The last chunk has Done
+// set and carries the token usage.
+type ChatChunk struct {
+	Delta string      `json:"delta"`
+	Done  bool        `json:"done"`
+	Usage *TokenUsage `json:"usage,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// errorBody is the error shape returned by the service.
+type errorBody struct {
+	Error  string `json:"error"`
+	Detail string `json:"detail"`
+}
@@ -0,0 +1,174 @@
+// Package monitoring reports unexpected errors (panics, 5xx responses and
+// failures use cases choose to capture) to an error tracker. Without a DSN
+// configured every report is dropped.
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+// Levels of an event
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// Event is an error to report with what is known about where it happened.
+// The user, request and tenant IDs are read from the context it is
+// reported with.
+type Event struct {
+	Err error
+	// Level defaults to LevelFatal for panics and LevelError otherwise
+	Level string
+	// Request is the request being served, if any. Credentials in its
+	// headers and query string are filtered out before sending.
+	Request *http.Request
+	// Route is the matched route pattern, e.g. /api/v1/users/:id
+	Route  string
+	Status int
+	// Extra is attached as is, except for values under sensitive keys
+	Extra map[string]any
+}
+
+// ErrorReporter sends events to an error tracker. Report must not block.
+type ErrorReporter interface {
+	Report(ctx context.Context, event Event)
+	// Flush waits until the events reported so far are sent or ctx is done
+	Flush(ctx context.Context) error
+}
+
+// Noop drops every event.
+type Noop struct{}
+
+func (Noop) Report(context.Context, Event) {}
+
+func (Noop) Flush(context.Context) error { return nil }
+
+// New returns the reporter for cfg, or Noop when no DSN is configured or
+// the sample rate is 0 (the SDK would read that as 1). environment is used
+// when cfg doesn't name one.
+func New(cfg config.MonitoringConfig, environment string) (ErrorReporter, error) {
+	if cfg.DSN == "" || cfg.SampleRate == 0 {
+		return Noop{}, nil
+	}
+	if cfg.Environment == "" {
+		cfg.Environment = environment
+	}
+	return NewSentry(cfg)
+}
+
+var defaultReporter atomic.Pointer[ErrorReporter]
+
+// SetDefault sets the reporter used by Report and Capture.
+func SetDefault(r ErrorReporter) {
+	defaultReporter.Store(&r)
+}
+
+// Default returns the reporter set with SetDefault, or Noop.
+func Default() ErrorReporter {
+	if r := defaultReporter.Load(); r != nil {
+		return *r
+	}
+	return Noop{}
+}
+
+// Report sends event to the default reporter.
+func Report(ctx context.Context, event Event) {
+	Default().Report(ctx, event)
+}
+
+// Capture reports err from code outside the HTTP layer. The user, request
+// and route of the request ctx belongs to are attached.
+func Capture(ctx context.Context, err error, extra map[string]any) {
+	if err == nil {
+		return
+	}
+	Default().Report(ctx, Event{Err: err, Extra: extra})
+}
+
+// PanicError is a recovered panic, with the stack it was raised on.
+type PanicError struct {
+	Value any
+	pcs   []uintptr
+}
+
+// NewPanicError wraps the value returned by recover. Call it from the
+// deferred function so the panicking frames are still on the stack.
+func NewPanicError(value any) *PanicError {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	return &PanicError{Value: value, pcs: pcs[:n]}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Filtered replaces sensitive values in captured data
+const Filtered = "[Filtered]"
+
+// sensitiveHeaders are never sent, whatever their value
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+var sensitiveKey = regexp.MustCompile(`(?i)pass(word|wd)?|secret|token|api[_-]?key|authorization|credential`)
+
+// scrubHeaders copies h with credentials filtered out.
+func scrubHeaders(h map[string]string) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, value := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] || sensitiveKey.MatchString(name) {
+			out[name] = Filtered
+			continue
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// scrubValues filters values under sensitive keys, recursing into maps.
+func scrubValues(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		if sensitiveKey.MatchString(k) {
+			out[k] = Filtered
+			continue
+		}
+		if nested, ok := v.(map[string]any); ok {
+			v = scrubValues(nested)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// levelOf returns the level event is reported at.
+func levelOf(event Event) string {
+	if event.Level != "" {
+		return event.Level
+	}
+	var panicErr *PanicError
+	if errors.As(event.Err, &panicErr) {
+		return LevelFatal
+	}
+	return LevelError
+}
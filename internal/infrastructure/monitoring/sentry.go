@@ -0,0 +1,171 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/Elysian-Rebirth/backend-go/internal/version"
+	"github.com/getsentry/sentry-go"
+)
+
+const maxStackFrames = 50
+
+// Sentry reports events through the Sentry SDK, whose transport sends them
+// from a background goroutine, so reporting never waits on the network.
+// Events of a request are captured on the hub sentrygin attached to it, so
+// they carry its trace; others on a clone of the global hub.
+type Sentry struct{}
+
+// NewSentry initializes the SDK's global client for cfg.
+func NewSentry(cfg config.MonitoringConfig) (*Sentry, error) {
+	return newSentry(cfg, nil)
+}
+
+// newSentry is NewSentry sending through transport, or the SDK's HTTP
+// transport when nil.
+func newSentry(cfg config.MonitoringConfig, transport sentry.Transport) (*Sentry, error) {
+	serverName, _ := os.Hostname()
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         cfg.DSN,
+		Environment: cfg.Environment,
+		Release:     version.Get().Version,
+		ServerName:  serverName,
+		SampleRate:  cfg.SampleRate,
+		BeforeSend:  scrubEvent,
+		Transport:   transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid monitoring DSN: %w", err)
+	}
+	return &Sentry{}, nil
+}
+
+func (s *Sentry) Report(ctx context.Context, event Event) {
+	if event.Err == nil {
+		return
+	}
+	if event.Request != nil {
+		ctx = event.Request.Context()
+	}
+
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub().Clone()
+	}
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.Level(levelOf(event)))
+
+		route := event.Route
+		if route == "" {
+			route, _ = reqctx.Route(ctx)
+		}
+		if route != "" {
+			scope.SetTag("route", route)
+		}
+		if event.Status != 0 {
+			scope.SetTag("status", fmt.Sprint(event.Status))
+		}
+		if id, ok := reqctx.RequestID(ctx); ok {
+			scope.SetTag("request_id", id)
+		}
+		if id, ok := reqctx.TenantID(ctx); ok {
+			scope.SetTag("tenant_id", id)
+		}
+		if id, ok := reqctx.UserID(ctx); ok {
+			scope.SetUser(sentry.User{ID: id})
+		}
+		if event.Request != nil {
+			scope.SetRequest(event.Request)
+		}
+		if len(event.Extra) > 0 {
+			scope.SetContext("extra", scrubValues(event.Extra))
+		}
+
+		var panicErr *PanicError
+		if errors.As(event.Err, &panicErr) {
+			hub.CaptureEvent(panicEvent(panicErr))
+			return
+		}
+		hub.CaptureException(event.Err)
+	})
+}
+
+func (s *Sentry) Flush(ctx context.Context) error {
+	if !sentry.FlushWithContext(ctx) {
+		return errors.New("error reports not flushed before the deadline")
+	}
+	return nil
+}
+
+// scrubEvent filters credentials out of the request attached to an event
+// before it leaves the process.
+func scrubEvent(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+	r := event.Request
+	if r == nil {
+		return event
+	}
+
+	r.Headers = scrubHeaders(r.Headers)
+	if r.Cookies != "" {
+		r.Cookies = Filtered
+	}
+	if query, err := url.ParseQuery(r.QueryString); err == nil {
+		for key := range query {
+			if sensitiveKey.MatchString(key) {
+				query.Set(key, Filtered)
+			}
+		}
+		r.QueryString = query.Encode()
+	}
+	return event
+}
+
+// panicEvent describes a recovered panic with the stack it was raised on,
+// rather than the stack of the code reporting it.
+func panicEvent(err *PanicError) *sentry.Event {
+	mechanism := &sentry.Mechanism{Type: "panic"}
+	mechanism.SetUnhandled()
+
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelFatal
+	event.Exception = []sentry.Exception{{
+		Type:       "panic",
+		Value:      fmt.Sprint(err.Value),
+		Stacktrace: stacktraceOf(err.pcs),
+		Mechanism:  mechanism,
+	}}
+	return event
+}
+
+func stacktraceOf(pcs []uintptr) *sentry.Stacktrace {
+	var frames []sentry.Frame
+	iter := runtime.CallersFrames(pcs)
+	for {
+		frame, more := iter.Next()
+		// Skip the runtime and this package, so the stack ends where the
+		// panic was raised
+		if !strings.HasPrefix(frame.Function, "runtime.") && !strings.Contains(frame.Function, "/internal/infrastructure/monitoring.") {
+			frames = append(frames, sentry.NewFrame(frame))
+		}
+		if !more || len(frames) >= maxStackFrames {
+			break
+		}
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+
+	// Sentry lists frames oldest first
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
@@ -0,0 +1,90 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/getsentry/sentry-go"
+)
+
+func TestSentryReport(t *testing.T) {
+	panicked := func() (err *PanicError) {
+		defer func() { err = NewPanicError(recover()) }()
+		panic("boom")
+	}()
+
+	tests := []struct {
+		name      string
+		err       error
+		wantLevel sentry.Level
+		wantType  string
+	}{
+		{"error", errors.New("query failed"), sentry.LevelError, "*errors.errorString"},
+		{"panic", panicked, sentry.LevelFatal, "panic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &sentry.MockTransport{}
+			s, err := newSentry(config.MonitoringConfig{DSN: "https://key@sentry.example.com/1", SampleRate: 1}, transport)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest("GET", "/api/v1/users?token=secret&page=2", nil)
+			r.Header.Set("Authorization", "Bearer secret")
+			r.Header.Set("X-Api-Key", "secret")
+			r.Header.Set("Cookie", "refresh_token=secret")
+			r.Header.Set("Accept", "application/json")
+
+			s.Report(context.Background(), Event{
+				Err:     tt.err,
+				Request: r,
+				Route:   "/api/v1/users",
+				Status:  500,
+				Extra:   map[string]any{"password": "secret", "attempt": 2},
+			})
+			if err := s.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			events := transport.Events()
+			if len(events) != 1 {
+				t.Fatalf("%d events sent, want 1", len(events))
+			}
+			ev := events[0]
+
+			if ev.Level != tt.wantLevel {
+				t.Errorf("level = %q, want %q", ev.Level, tt.wantLevel)
+			}
+			if len(ev.Exception) == 0 || ev.Exception[len(ev.Exception)-1].Type != tt.wantType {
+				t.Errorf("exception = %+v, want type %q", ev.Exception, tt.wantType)
+			}
+			if ev.Tags["route"] != "/api/v1/users" || ev.Tags["status"] != "500" {
+				t.Errorf("tags = %v", ev.Tags)
+			}
+			if ev.Contexts["extra"]["password"] != Filtered || ev.Contexts["extra"]["attempt"] != 2 {
+				t.Errorf("extra = %v", ev.Contexts["extra"])
+			}
+
+			if ev.Request == nil {
+				t.Fatal("request not attached")
+			}
+			for name, value := range ev.Request.Headers {
+				if strings.Contains(value, "secret") {
+					t.Errorf("header %s leaked: %q", name, value)
+				}
+			}
+			if ev.Request.Headers["Accept"] != "application/json" {
+				t.Errorf("Accept header = %q", ev.Request.Headers["Accept"])
+			}
+			if strings.Contains(ev.Request.QueryString, "secret") || strings.Contains(ev.Request.Cookies, "secret") {
+				t.Errorf("query %q or cookies %q leaked", ev.Request.QueryString, ev.Request.Cookies)
+			}
+		})
+	}
+}
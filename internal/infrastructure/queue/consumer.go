@@ -0,0 +1,323 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	retryCountHeader     = "x-retry-count"
+	defaultPrefetchCount = 10
+	consumerTag          = "umkmai-worker"
+	maxRetryDelay        = time.Hour
+)
+
+// ErrPermanent marks handler errors that retrying cannot fix (wrap it with
+// %w); such messages go straight to the dead-letter queue.
+var ErrPermanent = errors.New("permanent failure")
+
+// Handler processes one message. Returning an error schedules a retry.
+type Handler func(ctx context.Context, msg *Message) error
+
+// DeadLetterHandler is called with the last error before a message is moved
+// to the dead-letter queue.
+type DeadLetterHandler func(ctx context.Context, msg *Message, err error)
+
+// ConsumerStats are cumulative message counts since the consumer started.
+type ConsumerStats struct {
+	Processed    uint64 `json:"processed"`
+	Failed       uint64 `json:"failed"`
+	Retried      uint64 `json:"retried"`
+	DeadLettered uint64 `json:"dead_lettered"`
+}
+
+// Consumer reads the configured queue with WorkerCount goroutines and
+// dispatches messages to handlers by type. A failed message is republished
+// with an incremented x-retry-count header, after an exponentially growing
+// delay, until MaxRetries is reached; then it is rejected into the
+// dead-letter queue.
+type Consumer struct {
+	cfg config.RabbitMQConfig
+
+	mu           sync.RWMutex
+	handlers     map[string]Handler
+	deadLetterFn map[string]DeadLetterHandler
+
+	processed    atomic.Uint64
+	failed       atomic.Uint64
+	retried      atomic.Uint64
+	deadLettered atomic.Uint64
+}
+
+func NewConsumer(cfg config.RabbitMQConfig) *Consumer {
+	if cfg.PrefetchCount <= 0 {
+		cfg.PrefetchCount = defaultPrefetchCount
+	}
+	if cfg.WorkerCount <= 0 {
+		cfg.WorkerCount = 1
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = defaultReconnectDelay
+	}
+
+	return &Consumer{
+		cfg:          cfg,
+		handlers:     make(map[string]Handler),
+		deadLetterFn: make(map[string]DeadLetterHandler),
+	}
+}
+
+// Handle registers h for messages of eventType. The queue is bound to the
+// exchange with eventType as routing key, so register handlers before Run.
+func (c *Consumer) Handle(eventType string, h Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[eventType] = h
+}
+
+// HandleDeadLetter registers h to be told about messages of eventType that
+// failed permanently or ran out of retries.
+func (c *Consumer) HandleDeadLetter(eventType string, h DeadLetterHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadLetterFn[eventType] = h
+}
+
+func (c *Consumer) Stats() ConsumerStats {
+	return ConsumerStats{
+		Processed:    c.processed.Load(),
+		Failed:       c.failed.Load(),
+		Retried:      c.retried.Load(),
+		DeadLettered: c.deadLettered.Load(),
+	}
+}
+
+// Run consumes until ctx is cancelled, reconnecting when the broker goes
+// away. On cancellation it stops taking new deliveries, lets in-flight
+// handlers finish and returns.
+func (c *Consumer) Run(ctx context.Context) error {
+	if c.cfg.QueueName == "" {
+		return fmt.Errorf("rabbitmq queue_name is required to consume")
+	}
+
+	for {
+		err := c.consume(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("RabbitMQ consumer stopped, reconnecting in %v: %v", c.cfg.ReconnectDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.cfg.ReconnectDelay):
+		}
+	}
+}
+
+// consume runs one connection's worth of consuming.
+func (c *Consumer) consume(ctx context.Context) error {
+	conn, err := amqp.Dial(c.cfg.URL)
+	if err != nil {
+		return fmt.Errorf("failed to dial: %w", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := c.setup(ch); err != nil {
+		return err
+	}
+
+	deliveries, err := ch.Consume(c.cfg.QueueName, consumerTag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", c.cfg.QueueName, err)
+	}
+	log.Printf("RabbitMQ consumer started on %s with %d workers", c.cfg.QueueName, c.cfg.WorkerCount)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.cfg.WorkerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range deliveries {
+				c.process(ch, d)
+			}
+		}()
+	}
+
+	lost := conn.NotifyClose(make(chan *amqp.Error, 1))
+	select {
+	case <-ctx.Done():
+		// Stop prefetching; the deliveries channel closes once the broker
+		// confirms, and unacknowledged prefetched messages are requeued.
+		if err := ch.Cancel(consumerTag, false); err != nil {
+			log.Printf("Failed to cancel RabbitMQ consumer: %v", err)
+		}
+		wg.Wait()
+		return nil
+	case amqpErr := <-lost:
+		wg.Wait()
+		return fmt.Errorf("connection lost: %v", amqpErr)
+	}
+}
+
+func (c *Consumer) setup(ch *amqp.Channel) error {
+	if err := declareTopology(ch, c.cfg); err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for eventType := range c.handlers {
+		if err := ch.QueueBind(c.cfg.QueueName, eventType, c.cfg.Exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind %s to %s: %w", eventType, c.cfg.QueueName, err)
+		}
+	}
+
+	if err := ch.Qos(c.cfg.PrefetchCount, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch: %w", err)
+	}
+
+	return nil
+}
+
+// process runs the handler outside the consumer's context so a shutdown
+// never interrupts a message half way.
+func (c *Consumer) process(ch *amqp.Channel, d amqp.Delivery) {
+	var msg Message
+	if err := json.Unmarshal(d.Body, &msg); err != nil {
+		log.Printf("Dropping malformed message %s: %v", d.MessageId, err)
+		c.deadLetter(d)
+		return
+	}
+
+	c.mu.RLock()
+	handler, ok := c.handlers[msg.Type]
+	c.mu.RUnlock()
+	if !ok {
+		log.Printf("No handler for message type %s (%s)", msg.Type, msg.ID)
+		c.deadLetter(d)
+		return
+	}
+
+	ctx := context.Background()
+	if msg.RequestID != "" {
+		ctx = reqctx.WithRequestID(ctx, msg.RequestID)
+	}
+
+	err := handler(ctx, &msg)
+	if err == nil {
+		c.processed.Add(1)
+		if err := d.Ack(false); err != nil {
+			log.Printf("Failed to ack message %s: %v", msg.ID, err)
+		}
+		return
+	}
+
+	c.failed.Add(1)
+	retries := retryCount(d)
+	if retries >= c.cfg.MaxRetries || errors.Is(err, ErrPermanent) {
+		log.Printf("Message %s (%s) failed after %d retries, dead-lettering: %v", msg.ID, msg.Type, retries, err)
+		c.mu.RLock()
+		onDeadLetter := c.deadLetterFn[msg.Type]
+		c.mu.RUnlock()
+		if onDeadLetter != nil {
+			onDeadLetter(ctx, &msg, err)
+		}
+		c.deadLetter(d)
+		return
+	}
+
+	delay := c.retryDelay(retries + 1)
+	log.Printf("Message %s (%s) failed, retry %d/%d in %v: %v", msg.ID, msg.Type, retries+1, c.cfg.MaxRetries, delay, err)
+	if err := c.retry(ch, d, retries+1, delay); err != nil {
+		log.Printf("Failed to schedule retry for %s, requeueing: %v", msg.ID, err)
+		if err := d.Nack(false, true); err != nil {
+			log.Printf("Failed to nack message %s: %v", msg.ID, err)
+		}
+		return
+	}
+	c.retried.Add(1)
+}
+
+// retry republishes the delivery with the retry count bumped, then acks the
+// original. Nack with requeue can't carry the header, which is why the
+// message is copied. With a delay the copy waits in the retry queue until
+// its expiration dead-letters it back onto the work queue.
+func (c *Consumer) retry(ch *amqp.Channel, d amqp.Delivery, attempt int, delay time.Duration) error {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[retryCountHeader] = int32(attempt)
+
+	msg := amqp.Publishing{
+		Headers:       headers,
+		ContentType:   d.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     d.MessageId,
+		Type:          d.Type,
+		Timestamp:     d.Timestamp,
+		CorrelationId: d.CorrelationId,
+		Body:          d.Body,
+	}
+
+	target := c.cfg.QueueName
+	if delay > 0 {
+		target = retryQueue(c.cfg.QueueName)
+		msg.Expiration = strconv.FormatInt(delay.Milliseconds(), 10)
+	}
+
+	if err := ch.PublishWithContext(context.Background(), "", target, false, false, msg); err != nil {
+		return err
+	}
+
+	return d.Ack(false)
+}
+
+// retryDelay doubles RetryDelay for every attempt after the first. Messages
+// in the retry queue only expire from its head, so a long delay can hold up
+// shorter ones behind it; the cap keeps that bounded.
+func (c *Consumer) retryDelay(attempt int) time.Duration {
+	delay := c.cfg.RetryDelay
+	for i := 1; i < attempt && delay > 0 && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+func (c *Consumer) deadLetter(d amqp.Delivery) {
+	c.deadLettered.Add(1)
+	if err := d.Nack(false, false); err != nil {
+		log.Printf("Failed to dead-letter message %s: %v", d.MessageId, err)
+	}
+}
+
+func retryCount(d amqp.Delivery) int {
+	switch v := d.Headers[retryCountHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,241 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// recorder is an amqp.Acknowledger that remembers how a delivery was settled.
+type recorder struct {
+	settled string
+}
+
+func (r *recorder) Ack(uint64, bool) error { r.settled = "ack"; return nil }
+
+func (r *recorder) Nack(_ uint64, _ bool, requeue bool) error {
+	r.settled = "nack"
+	if requeue {
+		r.settled = "requeue"
+	}
+	return nil
+}
+
+func (r *recorder) Reject(_ uint64, requeue bool) error { return r.Nack(0, false, requeue) }
+
+func delivery(t *testing.T, ack amqp.Acknowledger, body string, retries any) amqp.Delivery {
+	t.Helper()
+	d := amqp.Delivery{Acknowledger: ack, Body: []byte(body), Headers: amqp.Table{}}
+	if retries != nil {
+		d.Headers[retryCountHeader] = retries
+	}
+	return d
+}
+
+func envelope(t *testing.T, eventType, requestID string) string {
+	t.Helper()
+	ctx := context.Background()
+	if requestID != "" {
+		ctx = reqctx.WithRequestID(ctx, requestID)
+	}
+	msg, err := NewMessage(ctx, eventType, map[string]string{"order": "o-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestConsumerProcess(t *testing.T) {
+	errTransient := errors.New("smtp timeout")
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		body       string
+		retries    any
+		handlerErr error
+		// wantHandled is whether the handler should have been called
+		wantHandled    bool
+		wantSettled    string
+		wantDeadLetter bool
+		wantStats      ConsumerStats
+	}{
+		{
+			name: "handled", maxRetries: 3, body: envelope(t, "order.created", ""),
+			wantHandled: true, wantSettled: "ack",
+			wantStats: ConsumerStats{Processed: 1},
+		},
+		{
+			name: "permanent failure skips retries", maxRetries: 3, body: envelope(t, "order.created", ""),
+			handlerErr:  fmt.Errorf("invalid order: %w", ErrPermanent),
+			wantHandled: true, wantSettled: "nack", wantDeadLetter: true,
+			wantStats: ConsumerStats{Failed: 1, DeadLettered: 1},
+		},
+		{
+			name: "retries exhausted", maxRetries: 3, body: envelope(t, "order.created", ""), retries: int32(3),
+			handlerErr:  errTransient,
+			wantHandled: true, wantSettled: "nack", wantDeadLetter: true,
+			wantStats: ConsumerStats{Failed: 1, DeadLettered: 1},
+		},
+		{
+			name: "no retries configured", maxRetries: 0, body: envelope(t, "order.created", ""),
+			handlerErr:  errTransient,
+			wantHandled: true, wantSettled: "nack", wantDeadLetter: true,
+			wantStats: ConsumerStats{Failed: 1, DeadLettered: 1},
+		},
+		{
+			name: "unknown type", maxRetries: 3, body: envelope(t, "order.cancelled", ""),
+			wantSettled: "nack",
+			wantStats:   ConsumerStats{DeadLettered: 1},
+		},
+		{
+			name: "malformed body", maxRetries: 3, body: "{not json",
+			wantSettled: "nack",
+			wantStats:   ConsumerStats{DeadLettered: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConsumer(config.RabbitMQConfig{QueueName: "work", MaxRetries: tt.maxRetries})
+
+			var handled bool
+			c.Handle("order.created", func(ctx context.Context, msg *Message) error {
+				handled = true
+				return tt.handlerErr
+			})
+			var deadErr error
+			c.HandleDeadLetter("order.created", func(ctx context.Context, msg *Message, err error) {
+				deadErr = err
+			})
+
+			ack := &recorder{}
+			c.process(nil, delivery(t, ack, tt.body, tt.retries))
+
+			if handled != tt.wantHandled {
+				t.Errorf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+			if ack.settled != tt.wantSettled {
+				t.Errorf("settled with %q, want %q", ack.settled, tt.wantSettled)
+			}
+			if tt.wantDeadLetter && !errors.Is(deadErr, tt.handlerErr) {
+				t.Errorf("dead-letter handler got %v, want %v", deadErr, tt.handlerErr)
+			}
+			if !tt.wantDeadLetter && deadErr != nil {
+				t.Errorf("dead-letter handler called with %v", deadErr)
+			}
+			if got := c.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestConsumerProcessRequestID(t *testing.T) {
+	c := NewConsumer(config.RabbitMQConfig{QueueName: "work"})
+
+	var got string
+	c.Handle("order.created", func(ctx context.Context, msg *Message) error {
+		got, _ = reqctx.RequestID(ctx)
+		return nil
+	})
+
+	c.process(nil, delivery(t, &recorder{}, envelope(t, "order.created", "req-7"), nil))
+	if got != "req-7" {
+		t.Errorf("handler saw request ID %q, want %q", got, "req-7")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{"first attempt", time.Second, 1, time.Second},
+		{"doubles", time.Second, 2, 2 * time.Second},
+		{"doubles again", time.Second, 4, 8 * time.Second},
+		{"capped", time.Minute, 10, maxRetryDelay},
+		{"capped at the start", 2 * time.Hour, 1, maxRetryDelay},
+		{"immediate", 0, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConsumer(config.RabbitMQConfig{RetryDelay: tt.delay})
+			if got := c.retryDelay(tt.attempt); got != tt.want {
+				t.Errorf("retryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		header any
+		want   int
+	}{
+		{"missing", nil, 0},
+		{"int32", int32(2), 2},
+		{"int64", int64(3), 3},
+		{"int", 4, 4},
+		{"wrong type", "5", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryCount(delivery(t, nil, "", tt.header)); got != tt.want {
+				t.Errorf("retryCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewConsumerDefaults(t *testing.T) {
+	c := NewConsumer(config.RabbitMQConfig{})
+	if c.cfg.PrefetchCount != defaultPrefetchCount || c.cfg.WorkerCount != 1 || c.cfg.ReconnectDelay != defaultReconnectDelay {
+		t.Errorf("config = %+v, want the defaults filled in", c.cfg)
+	}
+
+	c = NewConsumer(config.RabbitMQConfig{PrefetchCount: 50, WorkerCount: 8, ReconnectDelay: time.Second})
+	if c.cfg.PrefetchCount != 50 || c.cfg.WorkerCount != 8 || c.cfg.ReconnectDelay != time.Second {
+		t.Errorf("config = %+v, want the configured values kept", c.cfg)
+	}
+}
+
+func TestConsumerRun(t *testing.T) {
+	c := NewConsumer(config.RabbitMQConfig{})
+	if err := c.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "queue_name") {
+		t.Errorf("Run() without a queue error = %v, want it to name queue_name", err)
+	}
+
+	// A cancelled context stops the reconnect loop
+	c = NewConsumer(config.RabbitMQConfig{URL: unusedURL(t), QueueName: "work", ReconnectDelay: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+}
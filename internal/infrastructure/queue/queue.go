@@ -0,0 +1,65 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotConnected is returned while the broker connection is down
+	ErrNotConnected = errors.New("message broker not connected")
+
+	// ErrNotConfirmed is returned when the broker nacks a published message
+	ErrNotConfirmed = errors.New("message not confirmed by broker")
+)
+
+// Message is the envelope every event is published in.
+type Message struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	RequestID string          `json:"request_id,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// NewMessage wraps payload in an envelope, taking the request ID from ctx.
+func NewMessage(ctx context.Context, eventType string, payload any) (*Message, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+
+	msg := &Message{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Payload:   data,
+	}
+	if id, ok := reqctx.RequestID(ctx); ok {
+		msg.RequestID = id
+	}
+
+	return msg, nil
+}
+
+// Publisher publishes events. Implementations must be safe for concurrent use.
+type Publisher interface {
+	// Publish sends payload as an event of the given type and returns once the
+	// broker has confirmed it.
+	Publish(ctx context.Context, eventType string, payload any) error
+
+	Close() error
+}
+
+// NoopPublisher drops every event. It is used when no broker is configured.
+type NoopPublisher struct{}
+
+func (NoopPublisher) Publish(context.Context, string, any) error { return nil }
+
+func (NoopPublisher) Close() error { return nil }
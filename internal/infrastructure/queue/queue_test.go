@@ -0,0 +1,173 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+func TestNewMessage(t *testing.T) {
+	type order struct {
+		ID    string `json:"id"`
+		Total int    `json:"total"`
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		payload       any
+		wantPayload   string
+		wantRequestID string
+		wantErr       bool
+	}{
+		{"struct payload", context.Background(), order{ID: "o-1", Total: 15000}, `{"id":"o-1","total":15000}`, "", false},
+		{"request ID from context", reqctx.WithRequestID(context.Background(), "req-42"), map[string]string{"a": "b"}, `{"a":"b"}`, "req-42", false},
+		{"nil payload", context.Background(), nil, `null`, "", false},
+		{"unencodable payload", context.Background(), make(chan int), "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now().UTC()
+			msg, err := NewMessage(tt.ctx, "order.created", tt.payload)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewMessage() = %+v, want an error", msg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMessage() error = %v", err)
+			}
+
+			if msg.ID == "" {
+				t.Error("ID is empty")
+			}
+			if msg.Type != "order.created" {
+				t.Errorf("Type = %q, want %q", msg.Type, "order.created")
+			}
+			if msg.Timestamp.Before(before) || msg.Timestamp.Location() != time.UTC {
+				t.Errorf("Timestamp = %v, want a UTC time after %v", msg.Timestamp, before)
+			}
+			if msg.RequestID != tt.wantRequestID {
+				t.Errorf("RequestID = %q, want %q", msg.RequestID, tt.wantRequestID)
+			}
+			if string(msg.Payload) != tt.wantPayload {
+				t.Errorf("Payload = %s, want %s", msg.Payload, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestMessageJSON(t *testing.T) {
+	ctx := reqctx.WithRequestID(context.Background(), "req-1")
+	msg, err := NewMessage(ctx, "user.registered", map[string]string{"email": "budi@umkm.id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewMessage(context.Background(), "user.registered", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID == other.ID {
+		t.Errorf("two messages share ID %q", msg.ID)
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"id", "type", "timestamp", "request_id", "payload"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("envelope %s is missing %q", body, key)
+		}
+	}
+
+	var decoded Message
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != msg.ID || decoded.RequestID != msg.RequestID || !decoded.Timestamp.Equal(msg.Timestamp) {
+		t.Errorf("decoded %+v, want %+v", decoded, msg)
+	}
+
+	// Without a request ID the field is left out
+	body, err = json.Marshal(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields = nil
+	if err := json.Unmarshal(body, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["request_id"]; ok {
+		t.Errorf("envelope %s has a request_id", body)
+	}
+}
+
+func TestNoopPublisher(t *testing.T) {
+	var p Publisher = NoopPublisher{}
+	if err := p.Publish(context.Background(), "order.created", make(chan int)); err != nil {
+		t.Errorf("Publish() error = %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+// unusedURL returns an AMQP URL nothing is listening on.
+func unusedURL(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "amqp://guest:guest@" + addr + "/"
+}
+
+func TestRabbitMQPublisherNotConnected(t *testing.T) {
+	p := NewRabbitMQPublisher(config.RabbitMQConfig{
+		URL:            unusedURL(t),
+		Exchange:       "events",
+		ReconnectDelay: time.Hour,
+	})
+
+	if err := p.Publish(context.Background(), "order.created", nil); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Publish() error = %v, want %v", err, ErrNotConnected)
+	}
+	if err := p.HealthCheck(context.Background()); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("HealthCheck() error = %v, want %v", err, ErrNotConnected)
+	}
+
+	checks := p.HealthChecks()
+	if len(checks) != 1 || checks[0].Name != "rabbitmq" || checks[0].Required {
+		t.Fatalf("HealthChecks() = %+v, want one optional rabbitmq check", checks)
+	}
+	if _, err := checks[0].Checker.Check(context.Background()); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("check error = %v, want %v", err, ErrNotConnected)
+	}
+
+	// Close interrupts the reconnect wait instead of sleeping it out
+	done := make(chan error, 1)
+	go func() { done <- p.Close() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not return")
+	}
+}
@@ -0,0 +1,254 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	defaultChannelPoolSize = 4
+	defaultReconnectDelay  = 5 * time.Second
+)
+
+// RabbitMQPublisher publishes to a durable topic exchange using the event
+// type as routing key. The connection is re-established in the background,
+// so a broker outage only makes Publish fail with ErrNotConnected.
+type RabbitMQPublisher struct {
+	cfg config.RabbitMQConfig
+
+	mu   sync.RWMutex
+	conn *amqp.Connection
+	// channels holds idle confirm-mode channels of the current connection
+	channels chan *amqp.Channel
+
+	closing chan struct{}
+	done    chan struct{}
+}
+
+func NewRabbitMQPublisher(cfg config.RabbitMQConfig) *RabbitMQPublisher {
+	if cfg.ChannelPoolSize <= 0 {
+		cfg.ChannelPoolSize = defaultChannelPoolSize
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = defaultReconnectDelay
+	}
+
+	p := &RabbitMQPublisher{
+		cfg:      cfg,
+		channels: make(chan *amqp.Channel, cfg.ChannelPoolSize),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+
+	return p
+}
+
+// run keeps the connection alive until Close is called.
+func (p *RabbitMQPublisher) run() {
+	defer close(p.done)
+
+	for {
+		conn, err := p.connect()
+		if err != nil {
+			log.Printf("RabbitMQ connection failed, retrying in %v: %v", p.cfg.ReconnectDelay, err)
+			select {
+			case <-p.closing:
+				return
+			case <-time.After(p.cfg.ReconnectDelay):
+			}
+			continue
+		}
+
+		log.Printf("RabbitMQ connection established")
+		lost := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-p.closing:
+			p.setConn(nil)
+			if err := conn.Close(); err != nil {
+				log.Printf("Error closing RabbitMQ connection: %v", err)
+			}
+			return
+		case amqpErr := <-lost:
+			log.Printf("RabbitMQ connection lost: %v", amqpErr)
+			p.setConn(nil)
+		}
+	}
+}
+
+// connect dials the broker and declares the exchange and queue.
+func (p *RabbitMQPublisher) connect() (*amqp.Connection, error) {
+	conn, err := amqp.Dial(p.cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	err = declareTopology(ch, p.cfg)
+	ch.Close()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	p.setConn(conn)
+	return conn, nil
+}
+
+// HealthCheck reports whether the broker connection is currently up.
+func (p *RabbitMQPublisher) HealthCheck(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.conn == nil || p.conn.IsClosed() {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+// HealthChecks reports the broker as optional: while it is down, events
+// are lost but requests still succeed.
+func (p *RabbitMQPublisher) HealthChecks() []health.Check {
+	return []health.Check{{
+		Name: "rabbitmq",
+		Checker: health.CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+			return nil, p.HealthCheck(ctx)
+		}),
+	}}
+}
+
+// setConn swaps the current connection and drops channels of the old one.
+func (p *RabbitMQPublisher) setConn(conn *amqp.Connection) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.conn = conn
+	for {
+		select {
+		case ch := <-p.channels:
+			ch.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (p *RabbitMQPublisher) Publish(ctx context.Context, eventType string, payload any) error {
+	msg, err := NewMessage(ctx, eventType, payload)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	ch, err := p.acquire()
+	if err != nil {
+		return err
+	}
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, p.cfg.Exchange, eventType, false, false, amqp.Publishing{
+		ContentType:   "application/json",
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     msg.ID,
+		Type:          msg.Type,
+		Timestamp:     msg.Timestamp,
+		CorrelationId: msg.RequestID,
+		Body:          body,
+	})
+	if err != nil {
+		ch.Close()
+		return fmt.Errorf("failed to publish %s: %w", eventType, err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		// The confirmation is still outstanding; don't reuse the channel
+		ch.Close()
+		return fmt.Errorf("failed to confirm %s: %w", eventType, err)
+	}
+	p.release(ch)
+
+	if !acked {
+		return ErrNotConfirmed
+	}
+	return nil
+}
+
+// acquire returns an idle channel or opens a new one in confirm mode.
+func (p *RabbitMQPublisher) acquire() (*amqp.Channel, error) {
+	if ch := p.idleChannel(); ch != nil {
+		return ch, nil
+	}
+
+	p.mu.RLock()
+	conn := p.conn
+	p.mu.RUnlock()
+	if conn == nil || conn.IsClosed() {
+		return nil, ErrNotConnected
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	return ch, nil
+}
+
+func (p *RabbitMQPublisher) idleChannel() *amqp.Channel {
+	for {
+		select {
+		case ch := <-p.channels:
+			if !ch.IsClosed() {
+				return ch
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *RabbitMQPublisher) release(ch *amqp.Channel) {
+	if ch.IsClosed() {
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.conn == nil {
+		ch.Close()
+		return
+	}
+
+	select {
+	case p.channels <- ch:
+	default:
+		ch.Close()
+	}
+}
+
+// Close stops reconnecting and closes the connection.
+func (p *RabbitMQPublisher) Close() error {
+	close(p.closing)
+	<-p.done
+	return nil
+}
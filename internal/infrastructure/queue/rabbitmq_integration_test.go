@@ -0,0 +1,145 @@
+//go:build integration
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// newTestBroker starts RabbitMQ in a container and returns its URL. The test
+// is skipped with -short or when Docker isn't available.
+func newTestBroker(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping RabbitMQ integration test in short mode")
+	}
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	ctr, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "rabbitmq:3-alpine",
+			ExposedPorts: []string{"5672/tcp"},
+			WaitingFor:   wait.ForLog("Server startup complete").WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, ctr)
+	if err != nil {
+		t.Fatalf("failed to start RabbitMQ: %v", err)
+	}
+
+	endpoint, err := ctr.PortEndpoint(ctx, "5672/tcp", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "amqp://guest:guest@" + endpoint + "/"
+}
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestPublishConsume(t *testing.T) {
+	cfg := config.RabbitMQConfig{
+		URL:            newTestBroker(t),
+		Exchange:       "events",
+		QueueName:      "work",
+		WorkerCount:    2,
+		MaxRetries:     2,
+		ReconnectDelay: 100 * time.Millisecond,
+	}
+
+	consumer := NewConsumer(cfg)
+
+	var mu sync.Mutex
+	received := map[string]int{}
+	var deadLettered []string
+	consumer.Handle("order.created", func(ctx context.Context, msg *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received[msg.Type]++
+		return nil
+	})
+	consumer.Handle("order.flaky", func(ctx context.Context, msg *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received[msg.Type]++
+		return errors.New("downstream unavailable")
+	})
+	consumer.HandleDeadLetter("order.flaky", func(ctx context.Context, msg *Message, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deadLettered = append(deadLettered, msg.ID)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- consumer.Run(ctx) }()
+
+	publisher := NewRabbitMQPublisher(cfg)
+	t.Cleanup(func() { publisher.Close() })
+	waitFor(t, "the publisher to connect", func() bool {
+		return publisher.HealthCheck(context.Background()) == nil
+	})
+	// The consumer binds its queue after connecting; until then the exchange
+	// drops unroutable messages
+	waitFor(t, "the queue binding", func() bool {
+		if err := publisher.Publish(context.Background(), "order.created", map[string]int{"n": 0}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		return consumer.Stats().Processed > 0
+	})
+	processed := consumer.Stats().Processed
+
+	for i := range 5 {
+		if err := publisher.Publish(context.Background(), "order.created", map[string]int{"n": i + 1}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	if err := publisher.Publish(context.Background(), "order.flaky", nil); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	waitFor(t, "all messages to be processed", func() bool {
+		s := consumer.Stats()
+		return s.Processed >= processed+5 && s.DeadLettered == 1
+	})
+
+	cancel()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+
+	stats := consumer.Stats()
+	// The flaky message runs once and then once per retry
+	if stats.Failed != 3 || stats.Retried != 2 {
+		t.Errorf("Stats() = %+v, want 3 failures and 2 retries", stats)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if received["order.flaky"] != 3 || len(deadLettered) != 1 {
+		t.Errorf("flaky handler ran %d times and dead-lettered %v, want 3 runs and one dead letter", received["order.flaky"], deadLettered)
+	}
+}
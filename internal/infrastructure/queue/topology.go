@@ -0,0 +1,61 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// deadLetterQueue returns the name of the queue rejected messages end up in.
+func deadLetterQueue(queue string) string {
+	return queue + ".dlq"
+}
+
+// retryQueue returns the name of the queue failed messages wait in before
+// they are redelivered.
+func retryQueue(queue string) string {
+	return queue + ".retry"
+}
+
+// declareTopology declares the durable exchange and, when configured, the
+// work queue with its retry and dead-letter queues. Publisher and Consumer
+// both call it so either can start first.
+func declareTopology(ch *amqp.Channel, cfg config.RabbitMQConfig) error {
+	if err := ch.ExchangeDeclare(cfg.Exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", cfg.Exchange, err)
+	}
+
+	if cfg.QueueName == "" {
+		return nil
+	}
+
+	dlq := deadLetterQueue(cfg.QueueName)
+	if _, err := ch.QueueDeclare(dlq, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", dlq, err)
+	}
+
+	// Messages nacked without requeue are routed to the DLQ by the broker
+	args := amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": dlq,
+	}
+	if _, err := ch.QueueDeclare(cfg.QueueName, true, false, false, false, args); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", cfg.QueueName, err)
+	}
+	// Messages expiring in the retry queue go back to the work queue
+	retry := retryQueue(cfg.QueueName)
+	retryArgs := amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": cfg.QueueName,
+	}
+	if _, err := ch.QueueDeclare(retry, true, false, false, false, retryArgs); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", retry, err)
+	}
+
+	if err := ch.QueueBind(cfg.QueueName, cfg.QueueName, cfg.Exchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %w", cfg.QueueName, err)
+	}
+
+	return nil
+}
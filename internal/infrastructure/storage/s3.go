@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/health"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// partSize is the smallest part S3 accepts. Uploads of unknown length are
+// buffered one part at a time, so this bounds the memory used per upload.
+const partSize = 5 * 1024 * 1024
+
+// S3Storage stores objects in an S3-compatible bucket (AWS S3, MinIO, R2).
+type S3Storage struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+func NewS3Storage(cfg config.StorageConfig) (*S3Storage, error) {
+	// The endpoint may be configured with or without a scheme
+	endpoint := cfg.Endpoint
+	secure := cfg.UseSSL
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		endpoint = u.Host
+		secure = secure || u.Scheme == "https"
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: secure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+
+	baseURL := strings.TrimSuffix(cfg.PublicURL, "/")
+	if baseURL == "" {
+		scheme := "http"
+		if secure {
+			scheme = "https"
+		}
+		baseURL = fmt.Sprintf("%s://%s/%s", scheme, endpoint, cfg.Bucket)
+	}
+
+	return &S3Storage{
+		client:  client,
+		bucket:  cfg.Bucket,
+		baseURL: baseURL,
+	}, nil
+}
+
+// Put uploads r without knowing its length up front. An error from r, such
+// as a size limit being hit, aborts the multipart upload.
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, meta ObjectMeta) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, -1, minio.PutObjectOptions{
+		ContentType:        meta.ContentType,
+		ContentDisposition: meta.ContentDisposition,
+		PartSize:           partSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix, after string, limit int) ([]ObjectInfo, error) {
+	// Stop the listing goroutine once the page is full
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objects := make([]ObjectInfo, 0, limit)
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:     prefix,
+		StartAfter: after,
+		Recursive:  true,
+		MaxKeys:    limit,
+	}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, obj.Err)
+		}
+		objects = append(objects, ObjectInfo{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
+		})
+		if len(objects) == limit {
+			break
+		}
+	}
+	return objects, nil
+}
+
+// HealthCheck checks that the bucket is reachable.
+func (s *S3Storage) HealthCheck(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("failed to reach bucket %s: %w", s.bucket, err)
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.bucket)
+	}
+	return nil
+}
+
+// HealthChecks reports the bucket as optional; only uploads and downloads
+// need it.
+func (s *S3Storage) HealthChecks() []health.Check {
+	return []health.Check{{
+		Name: "storage",
+		Checker: health.CheckerFunc(func(ctx context.Context) (map[string]any, error) {
+			return nil, s.HealthCheck(ctx)
+		}),
+	}}
+}
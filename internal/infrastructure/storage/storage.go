@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotConfigured is returned by every operation when no object storage
+// endpoint is configured
+var ErrNotConfigured = errors.New("object storage not configured")
+
+// ObjectStorage stores uploaded files. Implementations must be safe for
+// concurrent use.
+type ObjectStorage interface {
+	// Put streams r to key. A failed Put leaves no partial object behind.
+	Put(ctx context.Context, key string, r io.Reader, meta ObjectMeta) error
+
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the address the object can be fetched from
+	URL(key string) string
+
+	// List returns up to limit objects under prefix whose keys sort after
+	// the given key, in key order. Pass the last key of a page to get the
+	// next one; an empty result means the listing is complete.
+	List(ctx context.Context, prefix, after string, limit int) ([]ObjectInfo, error)
+}
+
+// ObjectMeta is the metadata stored with an object and sent back as headers
+// when it is fetched.
+type ObjectMeta struct {
+	ContentType string
+	// ContentDisposition, when set, is e.g. "attachment" to stop browsers
+	// from rendering the object
+	ContentDisposition string
+}
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Unconfigured is used when storage.endpoint is empty, so upload endpoints
+// fail with ErrNotConfigured instead of the server refusing to start.
+type Unconfigured struct{}
+
+func (Unconfigured) Put(context.Context, string, io.Reader, ObjectMeta) error {
+	return ErrNotConfigured
+}
+
+func (Unconfigured) Delete(context.Context, string) error { return ErrNotConfigured }
+
+func (Unconfigured) URL(string) string { return "" }
+
+func (Unconfigured) List(context.Context, string, string, int) ([]ObjectInfo, error) {
+	return nil, ErrNotConfigured
+}
@@ -0,0 +1,141 @@
+// Package jsonnaming checks that types serialized in API responses name
+// every field explicitly in snake_case, so a new field without a json tag
+// doesn't leak into the API as PascalCase.
+package jsonnaming
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Modes of server.json_naming
+const (
+	ModeOff    = "off"
+	ModeWarn   = "warn"
+	ModeStrict = "strict"
+)
+
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+var (
+	jsonMarshaler = reflect.TypeFor[json.Marshaler]()
+	textMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Violation is a field that would not be serialized under a snake_case name.
+type Violation struct {
+	// Path locates the field from the checked type, e.g.
+	// handler.UserListResponse.Data[].Roles[].Name
+	Path    string
+	Problem string
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Problem
+}
+
+// Check walks the types of values, following struct fields, pointers,
+// slices, arrays and map values, and reports every exported field that has
+// no json tag, has a tag without a name, or is named other than in
+// snake_case. Types that marshal themselves are not looked into.
+func Check(values ...any) []Violation {
+	var violations []Violation
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		if t == nil {
+			continue
+		}
+		c := &checker{seen: make(map[reflect.Type]bool)}
+		c.walk(t, t.String())
+		violations = append(violations, c.violations...)
+	}
+	return violations
+}
+
+type checker struct {
+	seen       map[reflect.Type]bool
+	violations []Violation
+}
+
+func (c *checker) walk(t reflect.Type, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler) ||
+		t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		c.walk(t.Elem(), path+"[]")
+	case reflect.Map:
+		c.walk(t.Elem(), path+"{}")
+	case reflect.Struct:
+		if c.seen[t] {
+			return
+		}
+		c.seen[t] = true
+		c.walkStruct(t, path)
+	}
+}
+
+func (c *checker) walkStruct(t reflect.Type, path string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Untagged embedded structs have their fields promoted
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				c.walkStruct(ft, path)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		fieldPath := path + "." + f.Name
+		switch {
+		case !hasTag:
+			c.add(fieldPath, "has no json tag")
+		case name == "":
+			c.add(fieldPath, "has no name in its json tag")
+		case !snakeCase.MatchString(name):
+			c.add(fieldPath, fmt.Sprintf("json name %q is not snake_case", name))
+		}
+		c.walk(f.Type, fieldPath)
+	}
+}
+
+func (c *checker) add(path, problem string) {
+	c.violations = append(c.violations, Violation{Path: path, Problem: problem})
+}
+
+// TB is the part of testing.TB AssertSnakeCase uses.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AssertSnakeCase fails t for every violation Check finds in values, for
+// use in unit tests of response types.
+func AssertSnakeCase(t TB, values ...any) {
+	t.Helper()
+	for _, v := range Check(values...) {
+		t.Errorf("%s", v)
+	}
+}
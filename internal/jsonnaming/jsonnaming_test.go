@@ -0,0 +1,131 @@
+package jsonnaming
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+type good struct {
+	ID        string            `json:"id"`
+	CreatedAt time.Time         `json:"created_at"`
+	Note      *string           `json:"note,omitempty"`
+	Secret    string            `json:"-"`
+	Labels    map[string]string `json:"labels"`
+	Items     []goodItem        `json:"items"`
+	internal  string
+}
+
+type goodItem struct {
+	SKU2 string `json:"sku2"`
+}
+
+type untagged struct {
+	Name string
+}
+
+type unnamed struct {
+	Name string `json:",omitempty"`
+}
+
+type pascal struct {
+	UserID string `json:"UserID"`
+	Camel  string `json:"userId"`
+}
+
+type nested struct {
+	Users []*untagged        `json:"users"`
+	ByID  map[string]pascal  `json:"by_id"`
+	Pairs [2]struct{ X int } `json:"pairs"`
+}
+
+type base struct {
+	TenantID string
+}
+
+type embedding struct {
+	base
+	Name string `json:"name"`
+}
+
+type tree struct {
+	Name     string  `json:"name"`
+	Children []*tree `json:"children"`
+}
+
+// selfMarshaled names its own fields.
+type selfMarshaled struct {
+	Value string
+}
+
+func (selfMarshaled) MarshalJSON() ([]byte, error) { return []byte(`{}`), nil }
+
+type wrapper struct {
+	Custom selfMarshaled `json:"custom"`
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  []string
+	}{
+		{"snake_case", good{}, nil},
+		{"pointer", &good{}, nil},
+		{"nil", nil, nil},
+		{"no tag", untagged{}, []string{"jsonnaming.untagged.Name: has no json tag"}},
+		{"no name in tag", unnamed{}, []string{"jsonnaming.unnamed.Name: has no name in its json tag"}},
+		{"not snake_case", pascal{}, []string{
+			`jsonnaming.pascal.UserID: json name "UserID" is not snake_case`,
+			`jsonnaming.pascal.Camel: json name "userId" is not snake_case`,
+		}},
+		{"nested", nested{}, []string{
+			"jsonnaming.nested.Users[].Name: has no json tag",
+			`jsonnaming.nested.ByID{}.UserID: json name "UserID" is not snake_case`,
+			`jsonnaming.nested.ByID{}.Camel: json name "userId" is not snake_case`,
+			"jsonnaming.nested.Pairs[].X: has no json tag",
+		}},
+		{"promoted fields", embedding{}, []string{"jsonnaming.embedding.TenantID: has no json tag"}},
+		{"recursive", tree{}, nil},
+		{"marshals itself", wrapper{}, nil},
+		{"slice", []untagged{}, []string{"[]jsonnaming.untagged[].Name: has no json tag"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range Check(tt.value) {
+				got = append(got, v.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// recorder is a TB that records failures.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSnakeCase(t *testing.T) {
+	r := &recorder{}
+	AssertSnakeCase(r, good{}, untagged{}, pascal{})
+	if len(r.errors) != 3 {
+		t.Errorf("AssertSnakeCase() reported %q, want 3 violations", r.errors)
+	}
+
+	r = &recorder{}
+	AssertSnakeCase(r, good{}, tree{})
+	if len(r.errors) != 0 {
+		t.Errorf("AssertSnakeCase() reported %q for clean types", r.errors)
+	}
+}
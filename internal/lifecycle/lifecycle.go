@@ -0,0 +1,105 @@
+// Package lifecycle shuts the server's components down in dependency order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Phase orders shutdown: every component of a phase is closed before the
+// next phase starts, so nothing is closed while something still uses it.
+type Phase int
+
+const (
+	// PhaseServer stops accepting requests and drains in-flight ones
+	PhaseServer Phase = iota
+	// PhaseConsumers stops taking messages off the broker
+	PhaseConsumers
+	// PhaseJobs waits for background jobs
+	PhaseJobs
+	// PhaseQueues flushes outbound queues and publishers
+	PhaseQueues
+	// PhaseStores closes Redis, the database and other connections
+	PhaseStores
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseServer:
+		return "server"
+	case PhaseConsumers:
+		return "consumers"
+	case PhaseJobs:
+		return "jobs"
+	case PhaseQueues:
+		return "queues"
+	case PhaseStores:
+		return "stores"
+	default:
+		return fmt.Sprintf("phase %d", int(p))
+	}
+}
+
+// CloseFunc stops a component. It should return once ctx is done even if the
+// component hasn't finished.
+type CloseFunc func(ctx context.Context) error
+
+type closer struct {
+	phase Phase
+	name  string
+	close CloseFunc
+}
+
+// Registry collects the close functions of the running components.
+type Registry struct {
+	mu      sync.Mutex
+	closers []closer
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a component. Within a phase, components close in the order
+// they were registered.
+func (r *Registry) Register(phase Phase, name string, fn CloseFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closers = append(r.closers, closer{phase: phase, name: name, close: fn})
+}
+
+// RegisterCloser adds a component whose Close takes no context.
+func (r *Registry) RegisterCloser(phase Phase, name string, close func() error) {
+	r.Register(phase, name, func(context.Context) error { return close() })
+}
+
+// Shutdown closes every component, sharing ctx's deadline. A failing
+// component is logged and doesn't stop the rest from closing; all errors
+// are returned joined.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	closers := append([]closer(nil), r.closers...)
+	r.closers = nil
+	r.mu.Unlock()
+
+	sort.SliceStable(closers, func(i, j int) bool {
+		return closers[i].phase < closers[j].phase
+	})
+
+	var errs []error
+	for _, c := range closers {
+		start := time.Now()
+		if err := c.close(ctx); err != nil {
+			log.Printf("[shutdown] %s (%s) failed after %v: %v", c.name, c.phase, time.Since(start), err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+			continue
+		}
+		log.Printf("[shutdown] %s closed in %v", c.name, time.Since(start))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,213 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	errFlush := errors.New("flush failed")
+
+	var mu sync.Mutex
+	var order []string
+	closeFn := func(name string, err error) CloseFunc {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return err
+		}
+	}
+
+	r := NewRegistry()
+	r.Register(PhaseStores, "database", closeFn("database", nil))
+	r.Register(PhaseQueues, "mail queue", closeFn("mail queue", errFlush))
+	r.Register(PhaseServer, "http server", closeFn("http server", nil))
+	r.Register(PhaseStores, "redis", closeFn("redis", nil))
+	r.Register(PhaseJobs, "scheduler", closeFn("scheduler", nil))
+	r.RegisterCloser(PhaseConsumers, "consumer", func() error { return closeFn("consumer", nil)(context.Background()) })
+	r.Register(PhaseServer, "redirect server", closeFn("redirect server", nil))
+
+	err := r.Shutdown(context.Background())
+	if !errors.Is(err, errFlush) {
+		t.Errorf("Shutdown() error = %v, want the mail queue error", err)
+	}
+
+	// Phases in order, registration order within a phase, and a failure
+	// doesn't stop the later phases
+	want := []string{"http server", "redirect server", "consumer", "scheduler", "mail queue", "database", "redis"}
+	if !slices.Equal(order, want) {
+		t.Errorf("closed %v, want %v", order, want)
+	}
+
+	// Everything is closed once
+	order = nil
+	if err := r.Shutdown(context.Background()); err != nil || len(order) != 0 {
+		t.Errorf("second Shutdown() = %v and closed %v, want nothing", err, order)
+	}
+}
+
+func TestShutdownSharesDeadline(t *testing.T) {
+	r := NewRegistry()
+	var deadlines []time.Time
+	for _, phase := range []Phase{PhaseServer, PhaseStores} {
+		r.Register(phase, phase.String(), func(ctx context.Context) error {
+			d, _ := ctx.Deadline()
+			deadlines = append(deadlines, d)
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i, d := range deadlines {
+		if !d.Equal(want) {
+			t.Errorf("closer %d got deadline %v, want %v", i, d, want)
+		}
+	}
+}
+
+// startServer serves handler on a random port, with a RequestTracker
+// tracking every request.
+func startServer(t *testing.T, handler http.HandlerFunc) (*http.Server, *RequestTracker, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := NewRequestTracker()
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done := tracker.Start(ActiveRequest{Method: r.Method, Route: r.URL.Path, StartedAt: time.Now()})
+		defer done()
+		handler(w, r)
+	})}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+
+	return srv, tracker, "http://" + ln.Addr().String()
+}
+
+func TestInFlightRequestSurvivesSIGTERM(t *testing.T) {
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	srv, tracker, url := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("done"))
+		close(finished)
+	})
+
+	r := NewRegistry()
+	r.Register(PhaseServer, "http server", ShutdownServer(srv, tracker))
+	var storeClosedEarly bool
+	r.RegisterCloser(PhaseStores, "database", func() error {
+		select {
+		case <-finished:
+		default:
+			storeClosedEarly = true
+		}
+		return nil
+	})
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	res := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			res <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		res <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	<-started
+
+	// Shut down the way the server does on SIGTERM
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	got := <-res
+	if got.err != nil || got.status != http.StatusOK || got.body != "done" {
+		t.Errorf("in-flight request = %d %q, %v, want 200 done", got.status, got.body, got.err)
+	}
+	if storeClosedEarly {
+		t.Error("database closed before the in-flight request finished")
+	}
+
+	// New requests are refused once the server has shut down
+	if _, err := http.Get(url + "/slow"); err == nil {
+		t.Error("request after shutdown succeeded")
+	}
+}
+
+func TestShutdownServerDeadline(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	srv, tracker, url := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(url + "/stuck")
+		if err == nil {
+			resp.Body.Close()
+		}
+		errs <- err
+	}()
+	<-started
+
+	if active := tracker.Active(); len(active) != 1 || active[0].Route != "/stuck" {
+		t.Fatalf("Active() = %+v, want the stuck request", active)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := ShutdownServer(srv, tracker)(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ShutdownServer() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The stuck request's connection is closed rather than left hanging
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("stuck request succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stuck request's connection was not closed")
+	}
+}
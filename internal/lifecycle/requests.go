@@ -0,0 +1,91 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ActiveRequest is a request the server is still handling.
+type ActiveRequest struct {
+	Method    string
+	Route     string
+	RequestID string
+	StartedAt time.Time
+}
+
+// RequestTracker records the requests being handled, so a shutdown that
+// times out can report which handlers were still running.
+type RequestTracker struct {
+	mu     sync.Mutex
+	nextID uint64
+	active map[uint64]ActiveRequest
+}
+
+func NewRequestTracker() *RequestTracker {
+	return &RequestTracker{active: make(map[uint64]ActiveRequest)}
+}
+
+// Start records req and returns the function to call once it's done.
+func (t *RequestTracker) Start(req ActiveRequest) (done func()) {
+	t.mu.Lock()
+	t.nextID++
+	id := t.nextID
+	t.active[id] = req
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		delete(t.active, id)
+		t.mu.Unlock()
+	}
+}
+
+// Active returns the requests being handled, oldest first.
+func (t *RequestTracker) Active() []ActiveRequest {
+	t.mu.Lock()
+	active := make([]ActiveRequest, 0, len(t.active))
+	for _, req := range t.active {
+		active = append(active, req)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].StartedAt.Before(active[j].StartedAt)
+	})
+	return active
+}
+
+// maxReportedRequests bounds the shutdown report of a server that was busy
+const maxReportedRequests = 20
+
+// ShutdownServer drains srv. If ctx runs out first, it logs the requests
+// still in flight, per tracker, and closes their connections.
+func ShutdownServer(srv *http.Server, tracker *RequestTracker) CloseFunc {
+	return func(ctx context.Context) error {
+		err := srv.Shutdown(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+
+		active := tracker.Active()
+		log.Printf("[shutdown] %d requests still in flight, closing their connections", len(active))
+		now := time.Now()
+		for i, req := range active {
+			if i == maxReportedRequests {
+				log.Printf("[shutdown]   ... and %d more", len(active)-i)
+				break
+			}
+			log.Printf("[shutdown]   %s %s running for %v (request %s)", req.Method, req.Route, now.Sub(req.StartedAt).Round(time.Millisecond), req.RequestID)
+		}
+
+		if closeErr := srv.Close(); closeErr != nil {
+			return errors.Join(err, closeErr)
+		}
+		return err
+	}
+}
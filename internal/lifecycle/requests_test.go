@@ -0,0 +1,129 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequestTracker(t *testing.T) {
+	tracker := NewRequestTracker()
+	now := time.Now()
+
+	doneB := tracker.Start(ActiveRequest{Route: "/b", StartedAt: now.Add(-time.Second)})
+	doneA := tracker.Start(ActiveRequest{Route: "/a", StartedAt: now.Add(-time.Minute)})
+	doneC := tracker.Start(ActiveRequest{Route: "/c", StartedAt: now})
+
+	routes := func() string {
+		var got []string
+		for _, req := range tracker.Active() {
+			got = append(got, req.Route)
+		}
+		return strings.Join(got, " ")
+	}
+
+	if got := routes(); got != "/a /b /c" {
+		t.Errorf("Active() = %s, want oldest first", got)
+	}
+	doneB()
+	doneB()
+	if got := routes(); got != "/a /c" {
+		t.Errorf("after /b finished, Active() = %s", got)
+	}
+	doneA()
+	doneC()
+	if got := routes(); got != "" {
+		t.Errorf("after every request finished, Active() = %s", got)
+	}
+}
+
+// captureLog redirects the standard logger for the duration of the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(prev)
+		log.SetFlags(flags)
+	})
+	return &buf
+}
+
+func TestShutdownServerReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		stuck     int
+		wantLines []string
+		wantErr   error
+	}{
+		{"drained in time", 0, nil, nil},
+		{"one stuck handler", 1, []string{
+			"[shutdown] 1 requests still in flight, closing their connections",
+			"[shutdown]   GET /slow/0 running for ",
+		}, context.DeadlineExceeded},
+		{"report is bounded", 25, []string{
+			"[shutdown] 25 requests still in flight, closing their connections",
+			"[shutdown]   ... and 5 more",
+		}, context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			defer close(release)
+			srv, tracker, url := startServer(t, func(w http.ResponseWriter, r *http.Request) {
+				<-release
+			})
+
+			var wg sync.WaitGroup
+			defer wg.Wait()
+			for i := range tt.stuck {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if resp, err := http.Get(fmt.Sprintf("%s/slow/%d", url, i)); err == nil {
+						resp.Body.Close()
+					}
+				}()
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for len(tracker.Active()) < tt.stuck {
+				if time.Now().After(deadline) {
+					t.Fatalf("%d of %d requests started", len(tracker.Active()), tt.stuck)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			logs := captureLog(t)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			err := ShutdownServer(srv, tracker)(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ShutdownServer() error = %v, want %v", err, tt.wantErr)
+			}
+
+			got := logs.String()
+			for _, line := range tt.wantLines {
+				if !strings.Contains(got, line) {
+					t.Errorf("log doesn't contain %q:\n%s", line, got)
+				}
+			}
+			if tt.stuck == 0 && got != "" {
+				t.Errorf("drained shutdown logged:\n%s", got)
+			}
+			// At most maxReportedRequests requests are listed one by one
+			if listed := strings.Count(got, "running for"); listed != min(tt.stuck, maxReportedRequests) {
+				t.Errorf("listed %d requests, want %d", listed, min(tt.stuck, maxReportedRequests))
+			}
+		})
+	}
+}
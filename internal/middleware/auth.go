@@ -1,75 +1,19 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
 	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtSvc *auth.JWTService, userRepo repository.UserRepository, roleRepo repository.RoleRepository) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header required",
-			})
-			c.Abort()
-			return
-		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
-			})
-			c.Abort()
-			return
-		}
-
-		token := parts[1]
-
-		claims, err := jwtSvc.ValidateToken(token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid or expired token",
-			})
-			c.Abort()
-			return
-		}
-
-		user, err := userRepo.FindByID(c.Request.Context(), claims.UserID)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "User not found",
-			})
-			c.Abort()
-			return
-		}
-
-		if !user.IsActive {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Account is disabled",
-			})
-			c.Abort()
-			return
-		}
-
-		roles, err := roleRepo.GetUserRoles(c.Request.Context(), user.ID)
-		if err != nil {
-			roles = []*domain.Role{}
-		}
-
-		c.Set("user", user)
-		c.Set("user_id", user.ID)
-		c.Set("user_email", user.Email)
-		c.Set("user_roles", roles)
-
-		c.Next()
-	}
+// AuthMiddleware requires a valid bearer access token. Use CombinedAuth for
+// routes that also accept other credentials.
+func AuthMiddleware(jwtSvc *auth.JWTService, sessions *auth.SessionStore, userRepo repository.UserRepository, roleRepo repository.RoleRepository) gin.HandlerFunc {
+	return CombinedAuth(roleRepo, BearerScheme(jwtSvc, sessions, userRepo))
 }
 
 func OptionalAuth(jwtSvc *auth.JWTService, userRepo repository.UserRepository, roleRepo repository.RoleRepository) gin.HandlerFunc {
@@ -104,6 +48,7 @@ func OptionalAuth(jwtSvc *auth.JWTService, userRepo repository.UserRepository, r
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
 		c.Set("user_roles", roles)
+		c.Request = c.Request.WithContext(reqctx.WithUserID(c.Request.Context(), user.ID))
 		c.Next()
 	}
 }
@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	"github.com/gin-gonic/gin"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// AuthScheme authenticates a request by one kind of credential.
+type AuthScheme interface {
+	// Name identifies the scheme, e.g. "bearer"; it is stored in the context
+	// as auth_scheme
+	Name() string
+
+	// Authenticate returns the user the credentials belong to. It returns an
+	// *AuthError with Missing set when the request carries no credentials
+	// for this scheme.
+	Authenticate(c *gin.Context) (*domain.User, error)
+}
+
+// AuthError is an authentication failure and the response it maps to.
+type AuthError struct {
+	Status  int
+	Code    apierror.Code
+	Message string
+	// Missing means the request didn't attempt this scheme at all
+	Missing bool
+}
+
+func (e *AuthError) Error() string {
+	return e.Message
+}
+
+// CombinedAuth tries each scheme in order and authenticates the request with
+// the first one that succeeds. If none do, the response reflects the first
+// scheme whose credentials were present but rejected, so a client sending a
+// bad token learns why rather than being asked to authenticate.
+func CombinedAuth(roleRepo repository.RoleRepository, schemes ...AuthScheme) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var failure *AuthError
+
+		for _, scheme := range schemes {
+			user, err := scheme.Authenticate(c)
+			if err == nil {
+				if !user.IsActive {
+					apierror.Abort(c, http.StatusForbidden, apierror.CodeAccountDisabled, "Account is disabled")
+					return
+				}
+
+				setAuthenticatedUser(c, user, loadRoles(c.Request.Context(), roleRepo, user.ID))
+				c.Set("auth_scheme", scheme.Name())
+				c.Next()
+				return
+			}
+
+			var authErr *AuthError
+			if !errors.As(err, &authErr) {
+				log.Printf("%s authentication failed: %v", scheme.Name(), err)
+				authErr = &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeUnauthenticated, Message: "Authentication failed"}
+			}
+			if failure == nil || (failure.Missing && !authErr.Missing) {
+				failure = authErr
+			}
+		}
+
+		if failure == nil || (failure.Missing && len(schemes) > 1) {
+			failure = &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeUnauthenticated, Message: "Authentication required"}
+		}
+		apierror.Abort(c, failure.Status, failure.Code, failure.Message)
+	}
+}
+
+func loadRoles(ctx context.Context, roleRepo repository.RoleRepository, userID string) []*domain.Role {
+	roles, err := roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return []*domain.Role{}
+	}
+	return roles
+}
+
+func setAuthenticatedUser(c *gin.Context, user *domain.User, roles []*domain.Role) {
+	c.Set("user", user)
+	c.Set("user_id", user.ID)
+	c.Set("user_email", user.Email)
+	c.Set("user_roles", roles)
+	c.Request = c.Request.WithContext(reqctx.WithUserID(c.Request.Context(), user.ID))
+}
+
+type bearerScheme struct {
+	jwtSvc   *auth.JWTService
+	sessions *auth.SessionStore
+	userRepo repository.UserRepository
+}
+
+// BearerScheme authenticates an access token sent as
+// "Authorization: Bearer <token>".
+func BearerScheme(jwtSvc *auth.JWTService, sessions *auth.SessionStore, userRepo repository.UserRepository) AuthScheme {
+	return &bearerScheme{
+		jwtSvc:   jwtSvc,
+		sessions: sessions,
+		userRepo: userRepo,
+	}
+}
+
+func (s *bearerScheme) Name() string {
+	return "bearer"
+}
+
+func (s *bearerScheme) Authenticate(c *gin.Context) (*domain.User, error) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeUnauthenticated, Message: "Authorization header required", Missing: true}
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeInvalidToken, Message: "Invalid authorization header format"}
+	}
+
+	return s.authenticateToken(c, parts[1])
+}
+
+// authenticateToken validates an access token issued here or by a trusted
+// issuer.
+func (s *bearerScheme) authenticateToken(c *gin.Context, token string) (*domain.User, error) {
+	if !s.jwtSvc.IsLocalToken(token) {
+		return s.authenticateExternal(c, token)
+	}
+
+	claims, err := s.jwtSvc.ValidateToken(token)
+	if err != nil {
+		return nil, &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeInvalidToken, Message: "Invalid or expired token"}
+	}
+
+	// A token that can't be checked against revocation is refused; the 503
+	// tells the client to retry rather than discard it.
+	revoked, err := s.sessions.IsAccessTokenRevoked(c.Request.Context(), claims)
+	if err != nil {
+		log.Printf("Failed to check access token revocation: %v", err)
+		return nil, &AuthError{Status: http.StatusServiceUnavailable, Code: apierror.CodeUnavailable, Message: "Service temporarily unavailable, try again shortly"}
+	}
+	if revoked {
+		return nil, &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeTokenRevoked, Message: "Token has been revoked"}
+	}
+
+	user, err := s.userRepo.FindByID(c.Request.Context(), claims.UserID)
+	if err != nil {
+		return nil, userLookupError(err)
+	}
+
+	return user, nil
+}
+
+// authenticateExternal accepts a token from a trusted issuer for the local
+// user with the same email. Such tokens can't be revoked from here; they
+// expire on the issuer's terms.
+func (s *bearerScheme) authenticateExternal(c *gin.Context, token string) (*domain.User, error) {
+	claims, err := s.jwtSvc.ValidateExternalToken(c.Request.Context(), token)
+	if err != nil {
+		if !errors.Is(err, auth.ErrUntrustedIssuer) {
+			log.Printf("Rejected external token: %v", err)
+		}
+		return nil, &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeInvalidToken, Message: "Invalid or expired token"}
+	}
+
+	user, err := s.userRepo.FindByEmail(c.Request.Context(), claims.Email)
+	if err != nil {
+		return nil, userLookupError(err)
+	}
+
+	return user, nil
+}
+
+// userLookupError rejects a token whose user can't be loaded. A database
+// outage answers 503 so clients retry instead of discarding valid tokens.
+func userLookupError(err error) *AuthError {
+	if errors.Is(err, domain.ErrUnavailable) {
+		return &AuthError{Status: http.StatusServiceUnavailable, Code: apierror.CodeUnavailable, Message: "Service temporarily unavailable, try again shortly"}
+	}
+	return &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeInvalidToken, Message: "User not found"}
+}
+
+// APIKeyValidator resolves an API key to the user it was issued to. It
+// returns ErrInvalidAPIKey for unknown or revoked keys.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (*domain.User, error)
+}
+
+// ErrInvalidAPIKey is returned by an APIKeyValidator for keys it doesn't accept
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+type apiKeyScheme struct {
+	validator APIKeyValidator
+}
+
+// APIKeyScheme authenticates a key sent in the X-API-Key header.
+func APIKeyScheme(validator APIKeyValidator) AuthScheme {
+	return &apiKeyScheme{validator: validator}
+}
+
+func (s *apiKeyScheme) Name() string {
+	return "api_key"
+}
+
+func (s *apiKeyScheme) Authenticate(c *gin.Context) (*domain.User, error) {
+	key := c.GetHeader(APIKeyHeader)
+	if key == "" {
+		return nil, &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeUnauthenticated, Message: "API key required", Missing: true}
+	}
+
+	user, err := s.validator.ValidateAPIKey(c.Request.Context(), key)
+	if errors.Is(err, ErrInvalidAPIKey) {
+		return nil, &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeInvalidAPIKey, Message: "Invalid API key"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+type queryTokenScheme struct {
+	bearer *bearerScheme
+}
+
+// QueryTokenScheme authenticates an access token sent as the token query
+// parameter. It exists for clients such as EventSource that can't set
+// headers; only use it on routes that need it, since URLs end up in logs
+// and browser history.
+func QueryTokenScheme(jwtSvc *auth.JWTService, sessions *auth.SessionStore, userRepo repository.UserRepository) AuthScheme {
+	return &queryTokenScheme{
+		bearer: &bearerScheme{
+			jwtSvc:   jwtSvc,
+			sessions: sessions,
+			userRepo: userRepo,
+		},
+	}
+}
+
+func (s *queryTokenScheme) Name() string {
+	return "query_token"
+}
+
+func (s *queryTokenScheme) Authenticate(c *gin.Context) (*domain.User, error) {
+	token := c.Query("token")
+	if token == "" {
+		return nil, &AuthError{Status: http.StatusUnauthorized, Code: apierror.CodeUnauthenticated, Message: "Token query parameter required", Missing: true}
+	}
+
+	return s.bearer.authenticateToken(c, token)
+}
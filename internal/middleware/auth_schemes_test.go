@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/memory"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeys is an APIKeyValidator over a fixed set of keys; the key "broken"
+// fails as a store outage would.
+type apiKeys map[string]*domain.User
+
+func (k apiKeys) ValidateAPIKey(_ context.Context, key string) (*domain.User, error) {
+	if key == "broken" {
+		return nil, errors.New("connection refused")
+	}
+	user, ok := k[key]
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	return user, nil
+}
+
+type authFixture struct {
+	router   *gin.Engine
+	jwtSvc   *auth.JWTService
+	sessions *auth.SessionStore
+	active   *domain.User
+	disabled *domain.User
+	revoked  *domain.User
+}
+
+// newAuthFixture serves GET /me behind CombinedAuth with the bearer and API
+// key schemes, answering with the user ID and scheme.
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.JWTConfig{
+		Secret:             "test-secret-that-is-long-enough-to-sign",
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+	}
+	clk := clock.NewMock(time.Now())
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+
+	jwtSvc, err := auth.NewJWTService(cfg, clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := auth.NewSessionStore(c, cache.NewCacheKeyBuilder("test"), cfg, clk)
+
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	f := &authFixture{
+		jwtSvc:   jwtSvc,
+		sessions: sessions,
+		active:   &domain.User{Email: "active@example.com", Name: "Active", IsActive: true},
+		disabled: &domain.User{Email: "disabled@example.com", Name: "Disabled", IsActive: true},
+		revoked:  &domain.User{Email: "revoked@example.com", Name: "Revoked", IsActive: true},
+	}
+	for _, u := range []*domain.User{f.active, f.disabled, f.revoked} {
+		if err := users.Create(context.Background(), u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.disabled.IsActive = false
+	if err := users.Update(context.Background(), f.disabled); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := apiKeys{"good-key": f.active, "disabled-key": f.disabled}
+	f.router = gin.New()
+	f.router.GET("/me",
+		CombinedAuth(memory.NewRoleRepository(store), BearerScheme(jwtSvc, sessions, users), APIKeyScheme(keys)),
+		func(c *gin.Context) {
+			user := MustGetUserFromContext(c)
+			c.JSON(http.StatusOK, gin.H{"user_id": user.ID, "scheme": c.GetString("auth_scheme")})
+		})
+	return f
+}
+
+func (f *authFixture) token(t *testing.T, user *domain.User) string {
+	t.Helper()
+	token, err := f.jwtSvc.GenerateAccessToken(user.ID, user.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestCombinedAuth(t *testing.T) {
+	f := newAuthFixture(t)
+
+	// Access tokens issued so far to the revoked user are rejected
+	revokedToken := f.token(t, f.revoked)
+	if err := f.sessions.RevokeAccessTokens(context.Background(), f.revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		headers    func(t *testing.T) map[string]string
+		wantStatus int
+		wantScheme string
+		wantCode   apierror.Code
+	}{
+		{
+			name: "bearer token",
+			headers: func(t *testing.T) map[string]string {
+				return map[string]string{"Authorization": "Bearer " + f.token(t, f.active)}
+			},
+			wantStatus: http.StatusOK,
+			wantScheme: "bearer",
+		},
+		{
+			name:       "api key",
+			headers:    func(*testing.T) map[string]string { return map[string]string{APIKeyHeader: "good-key"} },
+			wantStatus: http.StatusOK,
+			wantScheme: "api_key",
+		},
+		{
+			name: "both, the first scheme wins",
+			headers: func(t *testing.T) map[string]string {
+				return map[string]string{"Authorization": "Bearer " + f.token(t, f.active), APIKeyHeader: "good-key"}
+			},
+			wantStatus: http.StatusOK,
+			wantScheme: "bearer",
+		},
+		{
+			name: "bad token, good api key",
+			headers: func(*testing.T) map[string]string {
+				return map[string]string{"Authorization": "Bearer not-a-jwt", APIKeyHeader: "good-key"}
+			},
+			wantStatus: http.StatusOK,
+			wantScheme: "api_key",
+		},
+		{
+			name:       "no credentials",
+			headers:    func(*testing.T) map[string]string { return nil },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apierror.CodeUnauthenticated,
+		},
+		{
+			name:       "bad token",
+			headers:    func(*testing.T) map[string]string { return map[string]string{"Authorization": "Bearer not-a-jwt"} },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apierror.CodeInvalidToken,
+		},
+		{
+			name:       "not a bearer header",
+			headers:    func(*testing.T) map[string]string { return map[string]string{"Authorization": "Basic dXNlcjpwYXNz"} },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apierror.CodeInvalidToken,
+		},
+		{
+			name:       "bad api key",
+			headers:    func(*testing.T) map[string]string { return map[string]string{APIKeyHeader: "wrong-key"} },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apierror.CodeInvalidAPIKey,
+		},
+		{
+			name: "both rejected, the first reason is reported",
+			headers: func(*testing.T) map[string]string {
+				return map[string]string{"Authorization": "Bearer not-a-jwt", APIKeyHeader: "wrong-key"}
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apierror.CodeInvalidToken,
+		},
+		{
+			name:       "api key store failing",
+			headers:    func(*testing.T) map[string]string { return map[string]string{APIKeyHeader: "broken"} },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apierror.CodeUnauthenticated,
+		},
+		{
+			name: "revoked token",
+			headers: func(*testing.T) map[string]string {
+				return map[string]string{"Authorization": "Bearer " + revokedToken}
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apierror.CodeTokenRevoked,
+		},
+		{
+			name: "disabled account by token",
+			headers: func(t *testing.T) map[string]string {
+				return map[string]string{"Authorization": "Bearer " + f.token(t, f.disabled)}
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   apierror.CodeAccountDisabled,
+		},
+		{
+			name:       "disabled account by api key",
+			headers:    func(*testing.T) map[string]string { return map[string]string{APIKeyHeader: "disabled-key"} },
+			wantStatus: http.StatusForbidden,
+			wantCode:   apierror.CodeAccountDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			for k, v := range tt.headers(t) {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			f.router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var body struct {
+					UserID string `json:"user_id"`
+					Scheme string `json:"scheme"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				if body.UserID != f.active.ID || body.Scheme != tt.wantScheme {
+					t.Errorf("authenticated %s by %s, want %s by %s", body.UserID, body.Scheme, f.active.ID, tt.wantScheme)
+				}
+				return
+			}
+
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// unreadableCache fails every read, as Redis does while unreachable.
+type unreadableCache struct {
+	cache.Cache
+}
+
+func (unreadableCache) Get(context.Context, string) (string, error) {
+	return "", errors.New("dial tcp: connection refused")
+}
+
+func TestBearerSchemeRevocationCheckFailing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.JWTConfig{
+		Secret:             "test-secret-that-is-long-enough-to-sign",
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+	}
+	clk := clock.NewMock(time.Now())
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+
+	jwtSvc, err := auth.NewJWTService(cfg, clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := auth.NewSessionStore(unreadableCache{c}, cache.NewCacheKeyBuilder("test"), cfg, clk)
+
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	user := &domain.User{Email: "active@example.com", Name: "Active", IsActive: true}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwtSvc.GenerateAccessToken(user.ID, user.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/me", CombinedAuth(memory.NewRoleRepository(store), BearerScheme(jwtSvc, sessions, users)),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// The token may have been revoked, so it isn't accepted unchecked
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusServiceUnavailable, w.Body)
+	}
+	var body apierror.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != apierror.CodeUnavailable {
+		t.Errorf("code = %q, want %q", body.Code, apierror.CodeUnavailable)
+	}
+}
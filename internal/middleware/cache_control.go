@@ -0,0 +1,21 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// Cache-Control directives for CacheControl
+const (
+	// NoStore keeps user data out of browser and proxy caches
+	NoStore = "no-store"
+	// PublicShort lets anyone cache rarely-changing public responses briefly
+	PublicShort = "public, max-age=300"
+)
+
+// CacheControl sets the Cache-Control header of every response on the route
+// or group. It is set before the handler runs, so a handler can still
+// override it for its own response.
+func CacheControl(directive string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", directive)
+		c.Next()
+	}
+}
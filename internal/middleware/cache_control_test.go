@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCacheControl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name      string
+		directive string
+		handler   gin.HandlerFunc
+		want      string
+	}{
+		{"public", PublicShort, func(c *gin.Context) { c.Status(http.StatusOK) }, PublicShort},
+		{"no-store", NoStore, func(c *gin.Context) { c.Status(http.StatusOK) }, NoStore},
+		{"error response", NoStore, func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) }, NoStore},
+		{"handler override", PublicShort, func(c *gin.Context) {
+			c.Header("Cache-Control", "private, max-age=60")
+			c.Status(http.StatusOK)
+		}, "private, max-age=60"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/resource", CacheControl(tt.directive), tt.handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/resource", nil))
+
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"bytes"
+	"expvar"
+	"net/http"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// coalesceMetrics counts the GET requests answered with another request's
+// response
+var coalesceMetrics = expvar.NewMap("http_coalesced")
+
+// coalescedResponse is what the request that did the work answered.
+type coalescedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// Coalesce lets identical GET requests that arrive while one is in flight
+// share its response instead of doing the same work again. Requests are
+// identical when they have the same URL, the same authenticated user and
+// tenant, and accept the same encodings, so one user's response is never
+// served to another. Only use it on read-only handlers whose response
+// depends on nothing else, and after the auth middleware.
+func Coalesce() gin.HandlerFunc {
+	var group singleflight.Group
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		subject := "anonymous"
+		if user, ok := GetUserFromContext(c); ok {
+			subject = "user:" + user.ID
+		}
+		tenant, _ := reqctx.TenantID(c.Request.Context())
+		key := strings.Join([]string{subject, tenant, c.GetHeader("Accept"), c.GetHeader("Accept-Encoding"), c.Request.URL.RequestURI()}, "\x00")
+
+		leader := false
+		v, _, _ := group.Do(key, func() (any, error) {
+			leader = true
+			rec := &recordingWriter{ResponseWriter: c.Writer}
+			c.Writer = rec
+			c.Next()
+			c.Writer = rec.ResponseWriter
+
+			return &coalescedResponse{
+				status: rec.Status(),
+				header: rec.Header().Clone(),
+				body:   rec.body.Bytes(),
+			}, nil
+		})
+		if leader {
+			return
+		}
+
+		coalesceMetrics.Add("shared", 1)
+		resp := v.(*coalescedResponse)
+		header := c.Writer.Header()
+		for name, values := range resp.header {
+			// Keep this request's own correlation ID
+			if name == RequestIDHeader {
+				continue
+			}
+			header[name] = values
+		}
+		c.Status(resp.status)
+		c.Writer.Write(resp.body)
+		c.Abort()
+	}
+}
+
+// recordingWriter keeps a copy of the body written through it.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCoalesce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		method string
+		path   string
+		user   string
+	}
+	same := request{http.MethodGet, "/dashboard", "user-1"}
+
+	tests := []struct {
+		name      string
+		requests  []request
+		wantCalls int64
+	}{
+		{"identical requests", []request{same, same, same, same, same}, 1},
+		{"identical anonymous requests", []request{{http.MethodGet, "/dashboard", ""}, {http.MethodGet, "/dashboard", ""}}, 1},
+		{"different users", []request{same, {http.MethodGet, "/dashboard", "user-2"}, {http.MethodGet, "/dashboard", "user-3"}}, 3},
+		{"different queries", []request{same, {http.MethodGet, "/dashboard?range=7d", "user-1"}}, 2},
+		{"not GET", []request{{http.MethodPost, "/dashboard", "user-1"}, {http.MethodPost, "/dashboard", "user-1"}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var arrived, calls atomic.Int64
+			release := make(chan struct{})
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if id := c.GetHeader("X-Test-User"); id != "" {
+					c.Set("user", &domain.User{ID: id})
+				}
+				arrived.Add(1)
+				c.Next()
+			}, Coalesce())
+			handler := func(c *gin.Context) {
+				calls.Add(1)
+				<-release
+				c.String(http.StatusOK, "report for %s", c.GetHeader("X-Test-User"))
+			}
+			router.GET("/dashboard", handler)
+			router.POST("/dashboard", handler)
+
+			recorders := make([]*httptest.ResponseRecorder, len(tt.requests))
+			var wg sync.WaitGroup
+			for i, r := range tt.requests {
+				recorders[i] = httptest.NewRecorder()
+				req := httptest.NewRequest(r.method, r.path, nil)
+				if r.user != "" {
+					req.Header.Set("X-Test-User", r.user)
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					router.ServeHTTP(recorders[i], req)
+				}()
+			}
+
+			// Hold the first call until every request has reached the
+			// middleware, so the others join it rather than start after it
+			deadline := time.Now().Add(5 * time.Second)
+			for arrived.Load() < int64(len(tt.requests)) && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", got, tt.wantCalls)
+			}
+			for i, r := range tt.requests {
+				want := "report for " + r.user
+				if recorders[i].Code != http.StatusOK || recorders[i].Body.String() != want {
+					t.Errorf("request %d got %d %q, want %q", i, recorders[i].Code, recorders[i].Body, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"expvar"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// concurrencyMetrics reports the in-flight requests against the limit, and
+// how many requests waited for or were turned away by it
+var concurrencyMetrics = expvar.NewMap("http_concurrency")
+
+// ConcurrencyLimit lets at most cfg.MaxInFlight requests through at once.
+// The rest are rejected with 503 or, in wait mode, queued until a slot
+// frees up or the wait times out.
+func ConcurrencyLimit(cfg config.ConcurrencyConfig) gin.HandlerFunc {
+	if cfg.MaxInFlight <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	slots := make(chan struct{}, cfg.MaxInFlight)
+	limit := new(expvar.Int)
+	limit.Set(int64(cfg.MaxInFlight))
+	concurrencyMetrics.Set("limit", limit)
+	inFlight := new(expvar.Int)
+	concurrencyMetrics.Set("in_flight", inFlight)
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		for _, prefix := range cfg.ExemptPrefixes {
+			if strings.HasPrefix(path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		if !acquireSlot(c, slots, cfg) {
+			concurrencyMetrics.Add("rejected", 1)
+			c.Header("Retry-After", "1")
+			apierror.Abort(c, http.StatusServiceUnavailable, apierror.CodeUnavailable, "Server is busy, try again shortly")
+			return
+		}
+		inFlight.Add(1)
+		defer func() {
+			inFlight.Add(-1)
+			<-slots
+		}()
+
+		c.Next()
+	}
+}
+
+// acquireSlot takes a slot, waiting for one in wait mode until the timeout
+// or until the client goes away.
+func acquireSlot(c *gin.Context, slots chan struct{}, cfg config.ConcurrencyConfig) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+	if cfg.Mode != config.ConcurrencyWait || cfg.WaitTimeout <= 0 {
+		return false
+	}
+
+	concurrencyMetrics.Add("waited", 1)
+	timer := time.NewTimer(cfg.WaitTimeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		cfg  config.ConcurrencyConfig
+		// releaseAfter frees the busy slot this long after the second
+		// request arrives; 0 keeps it until the second request is done
+		releaseAfter time.Duration
+		path         string
+		wantStatus   int
+	}{
+		{"reject when full", config.ConcurrencyConfig{MaxInFlight: 1, Mode: config.ConcurrencyReject}, 0, "/work", http.StatusServiceUnavailable},
+		{"wait then proceed", config.ConcurrencyConfig{MaxInFlight: 1, Mode: config.ConcurrencyWait, WaitTimeout: 5 * time.Second}, 20 * time.Millisecond, "/work", http.StatusOK},
+		{"wait times out", config.ConcurrencyConfig{MaxInFlight: 1, Mode: config.ConcurrencyWait, WaitTimeout: 20 * time.Millisecond}, 0, "/work", http.StatusServiceUnavailable},
+		{"wait mode without a timeout rejects", config.ConcurrencyConfig{MaxInFlight: 1, Mode: config.ConcurrencyWait}, 0, "/work", http.StatusServiceUnavailable},
+		{"exempt path", config.ConcurrencyConfig{MaxInFlight: 1, Mode: config.ConcurrencyReject, ExemptPrefixes: []string{"/healthz"}}, 0, "/healthz", http.StatusOK},
+		{"disabled", config.ConcurrencyConfig{Mode: config.ConcurrencyReject}, 0, "/work", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{}, 1)
+			release := make(chan struct{})
+
+			router := gin.New()
+			router.Use(ConcurrencyLimit(tt.cfg))
+			router.GET("/busy", func(c *gin.Context) {
+				started <- struct{}{}
+				<-release
+				c.Status(http.StatusOK)
+			})
+			router.GET("/work", func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			busyDone := make(chan struct{})
+			go func() {
+				defer close(busyDone)
+				router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/busy", nil))
+			}()
+			<-started
+			if tt.cfg.MaxInFlight > 0 {
+				if got := concurrencyMetrics.Get("in_flight").String(); got != "1" {
+					t.Errorf("in_flight = %s while busy, want 1", got)
+				}
+			}
+
+			if tt.releaseAfter > 0 {
+				time.AfterFunc(tt.releaseAfter, func() { close(release) })
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if tt.releaseAfter == 0 {
+				close(release)
+			}
+			<-busyDone
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+				t.Error("503 without Retry-After")
+			}
+
+			// The slot is free again once the busy request is done
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("status after release = %d, want 200", w.Code)
+			}
+		})
+	}
+}
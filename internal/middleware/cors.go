@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig builds the cors.Config of policy, with the methods and headers
+// of fallback where policy has none.
+func CORSConfig(policy, fallback config.CORSPolicy) cors.Config {
+	methods := policy.AllowedMethods
+	if len(methods) == 0 {
+		methods = fallback.AllowedMethods
+	}
+	headers := policy.AllowedHeaders
+	if len(headers) == 0 {
+		headers = fallback.AllowedHeaders
+	}
+
+	return cors.Config{
+		AllowOrigins:     policy.AllowedOrigins,
+		AllowMethods:     methods,
+		AllowHeaders:     headers,
+		AllowCredentials: policy.AllowCredentials,
+		// Let browser clients follow pagination links
+		ExposeHeaders: []string{"Link"},
+		MaxAge:        12 * time.Hour,
+	}
+}
+
+type corsRoute struct {
+	prefix  string
+	handler gin.HandlerFunc
+}
+
+// CORS applies the CORS policy of the route group a request is for: the
+// named policy with the longest matching path prefix, or the global one. It
+// is installed on the router rather than on the groups, so preflight
+// requests, which match no route, get the same policy as the request after.
+func CORS(security config.SecurityConfig) gin.HandlerFunc {
+	fallback := security.DefaultCORSPolicy()
+	defaultHandler := cors.New(CORSConfig(fallback, fallback))
+
+	var routes []corsRoute
+	for _, policy := range security.CORSPolicies {
+		handler := cors.New(CORSConfig(policy, fallback))
+		for _, prefix := range policy.PathPrefixes {
+			routes = append(routes, corsRoute{prefix: strings.TrimSuffix(prefix, "/"), handler: handler})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return len(routes[i].prefix) > len(routes[j].prefix)
+	})
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		for _, route := range routes {
+			if path == route.prefix || strings.HasPrefix(path, route.prefix+"/") {
+				route.handler(c)
+				return
+			}
+		}
+		defaultHandler(c)
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSPolicies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		app     = "https://app.umkmai.id"
+		console = "https://console.umkmai.id"
+		partner = "https://partner.example.com"
+	)
+	security := config.SecurityConfig{
+		CORSAllowedOrigins: []string{app, console},
+		CORSAllowedMethods: []string{"GET", "POST", "DELETE"},
+		CORSAllowedHeaders: []string{"Authorization", "Content-Type"},
+		CORSPolicies: map[string]config.CORSPolicy{
+			"admin": {
+				PathPrefixes:     []string{"/api/v1/admin/"},
+				AllowedOrigins:   []string{console},
+				AllowCredentials: true,
+			},
+			"reports": {
+				PathPrefixes:   []string{"/api/v1/admin/reports"},
+				AllowedOrigins: []string{partner},
+				AllowedMethods: []string{"GET"},
+			},
+		},
+	}
+
+	router := gin.New()
+	router.Use(CORS(security))
+	for _, path := range []string{"/api/v1/users", "/api/v1/admin", "/api/v1/admin/users", "/api/v1/adminx", "/api/v1/admin/reports/daily"} {
+		router.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		wantStatus  int
+		wantAllowed bool
+		wantMethods string
+	}{
+		{"global policy", http.MethodGet, "/api/v1/users", app, http.StatusOK, true, ""},
+		{"global origin on admin routes", http.MethodGet, "/api/v1/admin/users", app, http.StatusForbidden, false, ""},
+		{"admin origin on admin routes", http.MethodGet, "/api/v1/admin/users", console, http.StatusOK, true, ""},
+		{"group root", http.MethodGet, "/api/v1/admin", app, http.StatusForbidden, false, ""},
+		{"prefix is matched by segment", http.MethodGet, "/api/v1/adminx", app, http.StatusOK, true, ""},
+		{"longest prefix wins", http.MethodGet, "/api/v1/admin/reports/daily", partner, http.StatusOK, true, ""},
+		{"shorter prefix's origin under a longer one", http.MethodGet, "/api/v1/admin/reports/daily", console, http.StatusForbidden, false, ""},
+		{"preflight rejected", http.MethodOptions, "/api/v1/admin/users", app, http.StatusForbidden, false, ""},
+		{"preflight with the global methods", http.MethodOptions, "/api/v1/admin/users", console, http.StatusNoContent, true, "GET,POST,DELETE"},
+		{"preflight with the policy's methods", http.MethodOptions, "/api/v1/admin/reports/daily", partner, http.StatusNoContent, true, "GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			allowed := w.Header().Get("Access-Control-Allow-Origin") == tt.origin
+			if allowed != tt.wantAllowed {
+				t.Errorf("Access-Control-Allow-Origin = %q, want allowed %v", w.Header().Get("Access-Control-Allow-Origin"), tt.wantAllowed)
+			}
+			if tt.wantMethods != "" {
+				if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+					t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+				}
+			}
+		})
+	}
+}
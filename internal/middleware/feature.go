@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/featureflags"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireFeature hides a route while the feature flag name is off for the
+// current user, responding 404 as if it didn't exist. Run it after
+// AuthMiddleware so percentage rollouts can bucket the user.
+func RequireFeature(features *featureflags.Service, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !features.Enabled(c.Request.Context(), name) {
+			apierror.Abort(c, http.StatusNotFound, apierror.CodeNotFound, "Not found")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,13 +1,27 @@
 package middleware
 
 import (
+	"expvar"
+	"hash/fnv"
 	"log"
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
 	"github.com/gin-gonic/gin"
 )
 
-func Logger() gin.HandlerFunc {
+// suppressedLogLines counts the access log lines dropped by sampling
+var suppressedLogLines = expvar.NewInt("access_log_suppressed")
+
+// Logger writes one access log line per request, sampled as cfg says.
+func Logger(cfg config.AccessLogConfig) gin.HandlerFunc {
+	sampler := newAccessLogSampler(cfg)
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -18,8 +32,13 @@ func Logger() gin.HandlerFunc {
 		latency := time.Since(start)
 		statusCode := c.Writer.Status()
 
+		if !sampler.keep(path, c.GetString("request_id"), statusCode, latency) {
+			suppressedLogLines.Add(1)
+			return
+		}
+
 		if raw != "" {
-			path = path + "?" + raw
+			path = path + "?" + redactQuery(raw)
 		}
 
 		log.Printf("[%s] %d | %v | %s %s",
@@ -31,3 +50,66 @@ func Logger() gin.HandlerFunc {
 		)
 	}
 }
+
+// redactQuery hides access tokens passed as query parameters, see
+// QueryTokenScheme.
+func redactQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil || !values.Has("token") {
+		return raw
+	}
+	values.Set("token", "REDACTED")
+	return values.Encode()
+}
+
+// accessLogSampler decides which access log lines are written.
+type accessLogSampler struct {
+	rate          float64
+	slowThreshold time.Duration
+	// routes is sorted longest prefix first, so the first match wins
+	routes []config.AccessLogRouteConfig
+}
+
+func newAccessLogSampler(cfg config.AccessLogConfig) *accessLogSampler {
+	routes := append([]config.AccessLogRouteConfig(nil), cfg.Routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].Prefix) > len(routes[j].Prefix)
+	})
+	rate := 1.0
+	if cfg.SampleRate != nil {
+		rate = *cfg.SampleRate
+	}
+	return &accessLogSampler{rate: rate, slowThreshold: cfg.SlowThreshold, routes: routes}
+}
+
+// keep reports whether to log the request. Failed and slow requests are
+// always logged. The rest are sampled on their request ID, so every line
+// logged for a request is kept or dropped together.
+func (s *accessLogSampler) keep(path, requestID string, status int, latency time.Duration) bool {
+	if status < 200 || status >= 300 {
+		return true
+	}
+	if s.slowThreshold > 0 && latency >= s.slowThreshold {
+		return true
+	}
+
+	rate := s.rate
+	for _, route := range s.routes {
+		if strings.HasPrefix(path, route.Prefix) {
+			rate = route.SampleRate
+			break
+		}
+	}
+
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	case requestID == "":
+		return rand.Float64() < rate
+	}
+	h := fnv.New32a()
+	h.Write([]byte(requestID))
+	return float64(h.Sum32()) < rate*math.MaxUint32
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLogSampler(t *testing.T) {
+	sampler := newAccessLogSampler(config.AccessLogConfig{
+		SlowThreshold: time.Second,
+		Routes: []config.AccessLogRouteConfig{
+			{Prefix: "/health", SampleRate: 0},
+			{Prefix: "/api/v1/ping", SampleRate: 0},
+			// Longer prefixes win whatever their order
+			{Prefix: "/api/v1", SampleRate: 1},
+			{Prefix: "/api/v1/admin/stats", SampleRate: 0},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		status  int
+		latency time.Duration
+		want    bool
+	}{
+		{"default rate", "/api/v1/users/me", http.StatusOK, time.Millisecond, true},
+		{"sampled out route", "/health", http.StatusOK, time.Millisecond, false},
+		{"prefix covers sub-paths", "/healthz", http.StatusOK, time.Millisecond, false},
+		{"longest prefix wins", "/api/v1/admin/stats", http.StatusOK, time.Millisecond, false},
+		{"shorter prefix elsewhere", "/api/v1/admin/config", http.StatusOK, time.Millisecond, true},
+		{"client error always logged", "/health", http.StatusNotFound, time.Millisecond, true},
+		{"server error always logged", "/api/v1/ping", http.StatusServiceUnavailable, time.Millisecond, true},
+		{"redirect always logged", "/health", http.StatusFound, time.Millisecond, true},
+		{"slow request always logged", "/health", http.StatusOK, 2 * time.Second, true},
+		{"at the slow threshold", "/health", http.StatusOK, time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sampler.keep(tt.path, "req-1", tt.status, tt.latency); got != tt.want {
+				t.Errorf("keep(%s, %d, %v) = %v, want %v", tt.path, tt.status, tt.latency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessLogDefaultRate(t *testing.T) {
+	zero := 0.0
+	tests := []struct {
+		name string
+		rate *float64
+		want bool
+	}{
+		{"unset logs everything", nil, true},
+		{"explicit zero", &zero, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampler := newAccessLogSampler(config.AccessLogConfig{SampleRate: tt.rate})
+			if got := sampler.keep("/api/v1/users/me", "req-1", http.StatusOK, 0); got != tt.want {
+				t.Errorf("keep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessLogSampleRate(t *testing.T) {
+	sampler := newAccessLogSampler(config.AccessLogConfig{
+		Routes: []config.AccessLogRouteConfig{{Prefix: "/health", SampleRate: 0.1}},
+	})
+
+	const n = 10000
+	kept := 0
+	for i := range n {
+		id := fmt.Sprintf("req-%d", i)
+		keep := sampler.keep("/health", id, http.StatusOK, 0)
+		if keep {
+			kept++
+		}
+		// The decision follows the request ID, so lines of one request
+		// are kept or dropped together
+		if again := sampler.keep("/health", id, http.StatusOK, 0); again != keep {
+			t.Fatalf("keep() for %s changed from %v to %v", id, keep, again)
+		}
+	}
+	if kept < n*8/100 || kept > n*12/100 {
+		t.Errorf("kept %d of %d lines, want about 10%%", kept, n)
+	}
+}
+
+func TestLoggerSuppressedCount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(prev) })
+
+	router := gin.New()
+	router.Use(Logger(config.AccessLogConfig{
+		Routes: []config.AccessLogRouteConfig{{Prefix: "/health", SampleRate: 0}},
+	}))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/v1/stream", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	before := suppressedLogLines.Value()
+	for _, path := range []string{"/health", "/health", "/api/v1/stream?token=secret-token", "/nope"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := suppressedLogLines.Value() - before; got != 2 {
+		t.Errorf("suppressed %d lines, want 2", got)
+	}
+	out := logs.String()
+	if strings.Count(out, "\n") != 2 || strings.Contains(out, "/health") {
+		t.Errorf("logged:\n%s\nwant the stream and the 404 only", out)
+	}
+	if strings.Contains(out, "secret-token") || !strings.Contains(out, "token=REDACTED") {
+		t.Errorf("logged:\n%s\nwant the token redacted", out)
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
+	"github.com/gin-gonic/gin"
+)
+
+const QueryCountHeader = "X-Debug-Query-Count"
+
+// QueryCounter counts the database queries of each request, reports the
+// count in the X-Debug-Query-Count response header and logs a warning when it
+// exceeds threshold (0 disables the warning). Development only: it helps
+// spot N+1 patterns such as loading roles once per listed user.
+func QueryCounter(threshold int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, counter := database.WithQueryCounter(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &queryCountWriter{ResponseWriter: c.Writer, counter: counter}
+
+		c.Next()
+
+		if n := counter.Count(); threshold > 0 && n > int64(threshold) {
+			log.Printf("WARNING: %s %s ran %d queries (threshold %d, request %s), possible N+1",
+				c.Request.Method, c.FullPath(), n, threshold, c.GetString("request_id"))
+		}
+	}
+}
+
+// queryCountWriter sets the count header just before the response is
+// written, since headers can't change afterwards.
+type queryCountWriter struct {
+	gin.ResponseWriter
+	counter *database.QueryCounter
+}
+
+func (w *queryCountWriter) setHeader() {
+	if !w.Written() {
+		w.Header().Set(QueryCountHeader, strconv.FormatInt(w.counter.Count(), 10))
+	}
+}
+
+func (w *queryCountWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *queryCountWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *queryCountWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	QuotaLimitHeader     = "X-Quota-Limit"
+	QuotaRemainingHeader = "X-Quota-Remaining"
+	QuotaResetHeader     = "X-Quota-Reset"
+)
+
+// Quota enforces the daily per-user limit of feature, taken from quota for
+// the user's roles. Every allowed request counts towards the user's usage;
+// once the limit is reached requests are rejected with 429 until the daily
+// window resets. Must run after AuthMiddleware.
+//
+// If the counter store is unavailable requests are let through, so a Redis
+// outage doesn't take the metered features down with it.
+func Quota(usageUC usage.UsageUseCase, feature string, quota config.QuotaConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := GetUserFromContext(c)
+		if !exists {
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthenticated, "Authentication required")
+			return
+		}
+
+		limit := quota.LimitFor(roleNames(c))
+
+		result, err := usageUC.ConsumeQuota(c.Request.Context(), user.ID, feature, limit)
+		if err != nil {
+			log.Printf("Quota check for %s failed, allowing request: %v", feature, err)
+			c.Next()
+			return
+		}
+
+		if limit > 0 {
+			c.Header(QuotaLimitHeader, strconv.FormatInt(result.Limit, 10))
+			c.Header(QuotaRemainingHeader, strconv.FormatInt(result.Remaining, 10))
+			c.Header(QuotaResetHeader, strconv.FormatInt(result.ResetAt.Unix(), 10))
+		}
+
+		if !result.Allowed {
+			retryAfter := int(time.Until(result.ResetAt).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			apierror.Abort(c, http.StatusTooManyRequests, apierror.CodeQuotaExceeded, "Daily quota exceeded",
+				fmt.Sprintf("%s is limited to %d requests a day, resetting at %s", feature, result.Limit, result.ResetAt.UTC().Format(time.RFC3339)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// roleNames returns the names of the authenticated user's roles.
+func roleNames(c *gin.Context) []string {
+	var names []string
+	if roles, ok := GetUserRolesFromContext(c); ok {
+		for _, role := range roles {
+			names = append(names, role.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	"github.com/gin-gonic/gin"
+)
+
+// downCache fails every increment, as Redis does while unreachable.
+type downCache struct {
+	cache.Cache
+}
+
+func (downCache) IncrementBy(context.Context, string, int64) (int64, error) {
+	return 0, errors.New("dial tcp: connection refused")
+}
+
+// newQuotaRouter serves GET /chat behind Quota, signed in as a user with
+// roles.
+func newQuotaRouter(c cache.Cache, quota config.QuotaConfig, roles ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	uc := usage.NewUsageUseCase(c, cache.NewCacheKeyBuilder("test"), nil, clock.NewMock(time.Now()))
+
+	var userRoles []*domain.Role
+	for _, name := range roles {
+		userRoles = append(userRoles, &domain.Role{Name: name})
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user", &domain.User{ID: "user-1"})
+		c.Set("user_roles", userRoles)
+	})
+	r.GET("/chat", Quota(uc, "chat", quota), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestQuota(t *testing.T) {
+	quota := config.QuotaConfig{Default: 2, Roles: map[string]int64{"paid": 3, "admin": 0}}
+
+	type want struct {
+		status    int
+		remaining string
+	}
+	tests := []struct {
+		name  string
+		roles []string
+		want  []want
+	}{
+		{"free tier", nil, []want{{200, "1"}, {200, "0"}, {429, "0"}, {429, "0"}}},
+		{"paid tier", []string{"paid"}, []want{{200, "2"}, {200, "1"}, {200, "0"}, {429, "0"}}},
+		{"unlimited role", []string{"admin", "paid"}, []want{{200, ""}, {200, ""}, {200, ""}, {200, ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.NewMemoryCache()
+			t.Cleanup(func() { c.Close() })
+			r := newQuotaRouter(c, quota, tt.roles...)
+
+			for i, want := range tt.want {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/chat", nil))
+
+				if w.Code != want.status {
+					t.Fatalf("request %d: status = %d, want %d", i+1, w.Code, want.status)
+				}
+				if got := w.Header().Get(QuotaRemainingHeader); got != want.remaining {
+					t.Errorf("request %d: %s = %q, want %q", i+1, QuotaRemainingHeader, got, want.remaining)
+				}
+				limited := want.remaining != ""
+				if got := w.Header().Get(QuotaResetHeader) != ""; got != limited {
+					t.Errorf("request %d: %s set = %v, want %v", i+1, QuotaResetHeader, got, limited)
+				}
+				if got := w.Header().Get("Retry-After") != ""; got != (want.status == http.StatusTooManyRequests) {
+					t.Errorf("request %d: Retry-After set = %v", i+1, got)
+				}
+			}
+		})
+	}
+
+	t.Run("counter store down", func(t *testing.T) {
+		c := cache.NewMemoryCache()
+		t.Cleanup(func() { c.Close() })
+		r := newQuotaRouter(downCache{c}, quota)
+
+		for i := range 3 {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/chat", nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("request %d: status = %d, want 200 while the store is down", i+1, w.Code)
+			}
+		}
+	})
+}
@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,19 +13,13 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		_, exists := GetUserFromContext(c)
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authentication required",
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthenticated, "Authentication required")
 			return
 		}
 
 		userRoles, exists := GetUserRolesFromContext(c)
 		if !exists || len(userRoles) == 0 {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusForbidden, apierror.CodeInsufficientPermissions, "Insufficient permissions")
 			return
 		}
 
@@ -41,11 +37,8 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 		}
 
 		if !hasRole {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":         "Insufficient permissions",
-				"required_role": roles,
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusForbidden, apierror.CodeInsufficientPermissions, "Insufficient permissions",
+				"Requires one of the roles: "+strings.Join(roles, ", "))
 			return
 		}
 
@@ -61,19 +54,13 @@ func RequireAllRoles(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		_, exists := GetUserFromContext(c)
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authentication required",
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthenticated, "Authentication required")
 			return
 		}
 
 		userRoles, exists := GetUserRolesFromContext(c)
 		if !exists || len(userRoles) == 0 {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusForbidden, apierror.CodeInsufficientPermissions, "Insufficient permissions")
 			return
 		}
 
@@ -84,12 +71,8 @@ func RequireAllRoles(roles ...string) gin.HandlerFunc {
 
 		for _, requiredRole := range roles {
 			if !userRoleMap[strings.ToLower(requiredRole)] {
-				c.JSON(http.StatusForbidden, gin.H{
-					"error":          "Insufficient permissions",
-					"required_roles": roles,
-					"missing_role":   requiredRole,
-				})
-				c.Abort()
+				apierror.Abort(c, http.StatusForbidden, apierror.CodeInsufficientPermissions, "Insufficient permissions",
+					"Missing role: "+requiredRole)
 				return
 			}
 		}
@@ -102,47 +85,38 @@ func RequirePermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		_, exists := GetUserFromContext(c)
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authentication required",
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthenticated, "Authentication required")
 			return
 		}
 
 		userRoles, exists := GetUserRolesFromContext(c)
 		if !exists || len(userRoles) == 0 {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusForbidden, apierror.CodeInsufficientPermissions, "Insufficient permissions")
 			return
 		}
 
 		userPermissions := make(map[string]bool)
 		for _, role := range userRoles {
-			perms := role.GetPermissions()
+			perms, err := role.GetPermissionsE()
+			if err != nil {
+				// Deny what the role would grant, but make the corruption visible
+				log.Printf("Ignoring permissions of role %s (%s): %v", role.Name, role.ID, err)
+				continue
+			}
 			for _, perm := range perms {
 				userPermissions[perm] = true
 			}
 		}
 
-		hasAllPermissions := true
-		missingPermissions := []string{}
-
+		var missing []string
 		for _, requiredPerm := range permissions {
 			if !userPermissions[requiredPerm] && !userPermissions["*"] {
-				hasAllPermissions = false
-				missingPermissions = append(missingPermissions, requiredPerm)
+				missing = append(missing, "Missing permission: "+requiredPerm)
 			}
 		}
 
-		if !hasAllPermissions {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":                "Insufficient permissions",
-				"required_permissions": permissions,
-				"missing_permissions":  missingPermissions,
-			})
-			c.Abort()
+		if len(missing) > 0 {
+			apierror.Abort(c, http.StatusForbidden, apierror.CodeInsufficientPermissions, "Insufficient permissions", missing...)
 			return
 		}
 
@@ -154,19 +128,13 @@ func RequireOwnership(resourceType string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		_, exists := GetUserFromContext(c)
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authentication required",
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthenticated, "Authentication required")
 			return
 		}
 
 		resourceID := c.Param("id")
 		if resourceID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Resource ID is required",
-			})
-			c.Abort()
+			apierror.Abort(c, http.StatusBadRequest, apierror.CodeInvalidRequest, "Resource ID is required")
 			return
 		}
 
@@ -191,9 +159,23 @@ func CheckOwnership(c *gin.Context, resourceUserID string) bool {
 
 func MustCheckOwnership(c *gin.Context, resourceUserID string) {
 	if !CheckOwnership(c, resourceUserID) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "You don't have permission to access this resource",
-		})
-		c.Abort()
+		apierror.Abort(c, http.StatusForbidden, apierror.CodeForbidden, "You don't have permission to access this resource")
+	}
+}
+
+// HasRole reports whether the authenticated user has any of roles.
+func HasRole(c *gin.Context, roles ...string) bool {
+	userRoles, exists := GetUserRolesFromContext(c)
+	if !exists {
+		return false
+	}
+
+	for _, userRole := range userRoles {
+		for _, role := range roles {
+			if strings.EqualFold(userRole.Name, role) {
+				return true
+			}
+		}
 	}
+	return false
 }
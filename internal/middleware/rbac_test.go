@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	viewer := &domain.Role{ID: "role-1", Name: "viewer", Permissions: []byte(`["users.read"]`)}
+	admin := &domain.Role{ID: "role-2", Name: "admin", Permissions: []byte(`["*"]`)}
+	corrupt := &domain.Role{ID: "role-3", Name: "corrupt", Permissions: []byte(`["users.write"`)}
+
+	tests := []struct {
+		name       string
+		roles      []*domain.Role
+		required   []string
+		wantStatus int
+		wantLog    bool
+	}{
+		{"granted", []*domain.Role{viewer}, []string{"users.read"}, http.StatusOK, false},
+		{"wildcard", []*domain.Role{admin}, []string{"users.write"}, http.StatusOK, false},
+		{"missing", []*domain.Role{viewer}, []string{"users.write"}, http.StatusForbidden, false},
+		{"no roles", nil, []string{"users.read"}, http.StatusForbidden, false},
+		{"malformed role is denied", []*domain.Role{corrupt}, []string{"users.write"}, http.StatusForbidden, true},
+		{"other roles still apply", []*domain.Role{corrupt, viewer}, []string{"users.read"}, http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			prev := log.Writer()
+			log.SetOutput(&logs)
+			t.Cleanup(func() { log.SetOutput(prev) })
+
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				c.Set("user", &domain.User{ID: "user-1"})
+				if tt.roles != nil {
+					c.Set("user_roles", tt.roles)
+				}
+			}, RequirePermission(tt.required...), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if logged := strings.Contains(logs.String(), "corrupt (role-3)"); logged != tt.wantLog {
+				t.Errorf("logged %q, want the malformed role logged: %v", logs.String(), tt.wantLog)
+			}
+		})
+	}
+}
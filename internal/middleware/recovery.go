@@ -2,22 +2,21 @@ package middleware
 
 import (
 	"log"
-	"net/http"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/monitoring"
 	"github.com/gin-gonic/gin"
 )
 
+// Recovery turns a panic into an internal error response and reports it,
+// with the panicking stack, to the error tracker.
 func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
 				log.Printf("PANIC: %v", err)
 
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-				})
-
-				c.Abort()
+				apierror.AbortWithError(c, monitoring.NewPanicError(err))
 			}
 		}()
 
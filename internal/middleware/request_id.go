@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs so they can't bloat logs
+const maxRequestIDLength = 128
+
+// RequestID propagates the client's X-Request-ID, or generates one, and
+// echoes it on the response so a request can be traced across services.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		ctx := reqctx.WithRequestID(c.Request.Context(), id)
+		if route := c.FullPath(); route != "" {
+			ctx = reqctx.WithRoute(ctx, route)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
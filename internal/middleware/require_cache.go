@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireCache answers 503 while up is unset, for routes that cannot work
+// without Redis, such as issuing and rotating refresh tokens. It guards
+// servers that started with redis.allow_degraded_start before Redis came up.
+func RequireCache(up *atomic.Bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !up.Load() {
+			c.Header("Retry-After", "30")
+			apierror.Abort(c, http.StatusServiceUnavailable, apierror.CodeUnavailable, "Temporarily unavailable, try again shortly")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+// tenantIDPattern matches tenant IDs, which double as DNS labels
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Tenant resolves the tenant of each request from the configured header, or
+// else the subdomain of tenancy.base_domain, and stores it on the request
+// context where the repositories scope their queries by it. Requests without
+// a valid tenant are rejected. When tenancy is disabled it does nothing.
+func Tenant(cfg config.TenancyConfig) gin.HandlerFunc {
+	baseDomain := strings.ToLower(strings.Trim(cfg.BaseDomain, "."))
+
+	return func(c *gin.Context) {
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		tenantID := strings.ToLower(strings.TrimSpace(c.GetHeader(cfg.Header)))
+		if tenantID == "" && baseDomain != "" {
+			tenantID = subdomain(c.Request.Host, baseDomain)
+		}
+
+		if tenantID == "" {
+			apierror.Abort(c, http.StatusBadRequest, apierror.CodeTenantRequired, "Tenant is required")
+			return
+		}
+		if !tenantIDPattern.MatchString(tenantID) {
+			apierror.Abort(c, http.StatusBadRequest, apierror.CodeInvalidTenant, "Invalid tenant")
+			return
+		}
+
+		c.Set("tenant_id", tenantID)
+		c.Request = c.Request.WithContext(reqctx.WithTenantID(c.Request.Context(), tenantID))
+
+		c.Next()
+	}
+}
+
+// subdomain returns the label directly below baseDomain in host, or "" if
+// host isn't a single-level subdomain of it.
+func subdomain(host, baseDomain string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	label, ok := strings.CutSuffix(host, "."+baseDomain)
+	if !ok || label == "" || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
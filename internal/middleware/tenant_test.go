@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	enabled := config.TenancyConfig{Enabled: true, Header: "X-Tenant-ID", BaseDomain: "umkmai.id"}
+
+	tests := []struct {
+		name       string
+		cfg        config.TenancyConfig
+		host       string
+		header     string
+		wantStatus int
+		wantTenant string
+		wantCode   apierror.Code
+	}{
+		{"disabled", config.TenancyConfig{Header: "X-Tenant-ID"}, "warung.umkmai.id", "toko", http.StatusOK, "", ""},
+		{"header", enabled, "api.example.com", "Toko-Budi", http.StatusOK, "toko-budi", ""},
+		{"subdomain", enabled, "warung.umkmai.id:8080", "", http.StatusOK, "warung", ""},
+		{"header wins over subdomain", enabled, "warung.umkmai.id", "toko", http.StatusOK, "toko", ""},
+		{"base domain itself", enabled, "umkmai.id", "", http.StatusBadRequest, "", apierror.CodeTenantRequired},
+		{"nested subdomain", enabled, "a.warung.umkmai.id", "", http.StatusBadRequest, "", apierror.CodeTenantRequired},
+		{"other domain", enabled, "warung.example.com", "", http.StatusBadRequest, "", apierror.CodeTenantRequired},
+		{"invalid header", enabled, "api.example.com", "toko_budi", http.StatusBadRequest, "", apierror.CodeInvalidTenant},
+		{"leading hyphen", enabled, "api.example.com", "-toko", http.StatusBadRequest, "", apierror.CodeInvalidTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", Tenant(tt.cfg), func(c *gin.Context) {
+				tenantID, _ := reqctx.TenantID(c.Request.Context())
+				c.JSON(http.StatusOK, gin.H{"tenant_id": tenantID})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tt.host
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var body struct {
+					TenantID string `json:"tenant_id"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				if body.TenantID != tt.wantTenant {
+					t.Errorf("tenant = %q, want %q", body.TenantID, tt.wantTenant)
+				}
+				return
+			}
+
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/lifecycle"
+	"github.com/gin-gonic/gin"
+)
+
+// TrackRequests records every request in tracker while it is handled.
+func TrackRequests(tracker *lifecycle.RequestTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		done := tracker.Start(lifecycle.ActiveRequest{
+			Method:    c.Request.Method,
+			Route:     route,
+			RequestID: c.GetString("request_id"),
+			StartedAt: time.Now(),
+		})
+		defer done()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/lifecycle"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTrackRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := lifecycle.NewRequestTracker()
+
+	var seen []lifecycle.ActiveRequest
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("request_id", "req-1")
+		c.Next()
+	}, TrackRequests(tracker))
+	router.GET("/api/v1/users/:id", func(c *gin.Context) {
+		seen = tracker.Active()
+		c.Status(http.StatusOK)
+	})
+	router.NoRoute(func(c *gin.Context) {
+		seen = tracker.Active()
+		c.Status(http.StatusNotFound)
+	})
+
+	tests := []struct {
+		path      string
+		wantRoute string
+	}{
+		{"/api/v1/users/42", "/api/v1/users/:id"},
+		{"/nope", "/nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			seen = nil
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if len(seen) != 1 {
+				t.Fatalf("Active() during the request = %+v, want one request", seen)
+			}
+			if seen[0].Method != http.MethodGet || seen[0].Route != tt.wantRoute || seen[0].RequestID != "req-1" {
+				t.Errorf("Active() = %+v, want GET %s of req-1", seen[0], tt.wantRoute)
+			}
+			if active := tracker.Active(); len(active) != 0 {
+				t.Errorf("Active() after the request = %+v, want none", active)
+			}
+		})
+	}
+}
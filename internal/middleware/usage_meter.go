@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/apierror"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	"github.com/gin-gonic/gin"
+)
+
+// Headers of the monthly quota, kept apart from the daily ones since a
+// route can have both
+const (
+	MonthlyQuotaLimitHeader     = "X-Monthly-Quota-Limit"
+	MonthlyQuotaRemainingHeader = "X-Monthly-Quota-Remaining"
+	MonthlyQuotaResetHeader     = "X-Monthly-Quota-Reset"
+)
+
+// MeterRequests counts every request of an authenticated user as an API
+// request. It runs before authentication and counts once the handler is
+// done, so it can sit on a whole route group.
+func MeterRequests(usageUC usage.UsageUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		user, ok := GetUserFromContext(c)
+		if !ok {
+			return
+		}
+		if _, err := usageUC.IncrUsage(c.Request.Context(), user.ID, domain.UsageAPIRequests); err != nil {
+			log.Printf("Failed to meter request of user %s: %v", user.ID, err)
+		}
+	}
+}
+
+// Meter counts a successful request as one use of category by the user.
+// Must run after AuthMiddleware.
+func Meter(usageUC usage.UsageUseCase, category string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		user, ok := GetUserFromContext(c)
+		if !ok {
+			return
+		}
+		if _, err := usageUC.IncrUsage(c.Request.Context(), user.ID, category); err != nil {
+			log.Printf("Failed to meter %s of user %s: %v", category, user.ID, err)
+		}
+	}
+}
+
+// MonthlyQuota enforces the monthly per-user limit of a usage category,
+// taken from quota for the user's roles. Every allowed request counts;
+// once the limit is reached requests are rejected with 429 until the month
+// ends. Like Quota it lets requests through when the cache is unavailable.
+// Must run after AuthMiddleware.
+func MonthlyQuota(usageUC usage.UsageUseCase, category string, quota config.QuotaConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := GetUserFromContext(c)
+		if !exists {
+			apierror.Abort(c, http.StatusUnauthorized, apierror.CodeUnauthenticated, "Authentication required")
+			return
+		}
+
+		limit := quota.LimitFor(roleNames(c))
+
+		result, err := usageUC.ConsumeMonthlyQuota(c.Request.Context(), user.ID, category, limit)
+		if err != nil {
+			log.Printf("Monthly quota check for %s failed, allowing request: %v", category, err)
+			c.Next()
+			return
+		}
+
+		if limit > 0 {
+			c.Header(MonthlyQuotaLimitHeader, strconv.FormatInt(result.Limit, 10))
+			c.Header(MonthlyQuotaRemainingHeader, strconv.FormatInt(result.Remaining, 10))
+			c.Header(MonthlyQuotaResetHeader, strconv.FormatInt(result.ResetAt.Unix(), 10))
+		}
+
+		if !result.Allowed {
+			retryAfter := int(time.Until(result.ResetAt).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			apierror.Abort(c, http.StatusTooManyRequests, apierror.CodeQuotaExceeded, "Monthly quota exceeded",
+				fmt.Sprintf("%s is limited to %d a month, resetting at %s", category, result.Limit, result.ResetAt.UTC().Format(time.RFC3339)))
+			return
+		}
+
+		c.Next()
+	}
+}
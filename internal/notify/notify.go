@@ -0,0 +1,210 @@
+// Package notify pushes real-time events to users. Events are published on a
+// per-user cache channel, so they reach the user's streams on any replica, and
+// the most recent ones are kept in a short list for clients that reconnect.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+const defaultReplayTTL = 24 * time.Hour
+
+// openStreams counts subscriptions that have not been closed yet, so leaked
+// streams show up in /debug/vars
+var openStreams = expvar.NewInt("notification_streams")
+
+// Event is a notification delivered to a user. ID increases per user and is
+// what clients send back as Last-Event-ID.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Data      any       `json:"data,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notifier sends events to a user's open streams.
+type Notifier interface {
+	// Notify assigns event an ID and delivers it. Users without an open
+	// stream get it on their next connect while it is in the replay buffer.
+	Notify(ctx context.Context, userID string, event Event) error
+}
+
+// Service is the cache-backed Notifier and the source of user streams.
+type Service struct {
+	cache      cache.Cache
+	keyBuilder *cache.CacheKeyBuilder
+	cfg        config.NotificationsConfig
+	clock      clock.Clock
+}
+
+func New(c cache.Cache, kb *cache.CacheKeyBuilder, cfg config.NotificationsConfig, clk clock.Clock) *Service {
+	if cfg.ReplayTTL <= 0 {
+		cfg.ReplayTTL = defaultReplayTTL
+	}
+
+	return &Service{
+		cache:      c,
+		keyBuilder: kb,
+		cfg:        cfg,
+		clock:      clk,
+	}
+}
+
+func (s *Service) Notify(ctx context.Context, userID string, event Event) error {
+	seq, err := s.cache.Increment(ctx, s.keyBuilder.NotificationSeq(userID))
+	if err != nil {
+		return err
+	}
+	event.ID = strconv.FormatInt(seq, 10)
+	event.CreatedAt = s.clock.Now().UTC()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s notification: %w", event.Type, err)
+	}
+
+	if s.cfg.ReplaySize > 0 {
+		key := s.keyBuilder.NotificationReplay(userID)
+		if err := s.cache.LPush(ctx, key, payload); err != nil {
+			return err
+		}
+		if err := s.cache.LTrim(ctx, key, 0, s.cfg.ReplaySize-1); err != nil {
+			return err
+		}
+		if err := s.cache.Expire(ctx, key, s.cfg.ReplayTTL); err != nil {
+			return err
+		}
+	}
+
+	return s.cache.Publish(ctx, s.keyBuilder.NotificationChannel(userID), payload)
+}
+
+// Stream is a user's open subscription.
+type Stream struct {
+	// Replay holds buffered events newer than the Last-Event-ID the stream
+	// was opened with, oldest first
+	Replay []Event
+
+	sub    cache.Subscription
+	events chan Event
+	done   chan struct{}
+	once   sync.Once
+	// after is the newest ID already in Replay; live events up to it are
+	// duplicates published while the buffer was read
+	after int64
+}
+
+// Subscribe opens a stream of userID's events. lastEventID is the ID of the
+// last event the client saw, or empty for a fresh connect. The stream must be
+// closed.
+func (s *Service) Subscribe(ctx context.Context, userID, lastEventID string) (*Stream, error) {
+	after, _ := strconv.ParseInt(lastEventID, 10, 64)
+
+	// Subscribe before reading the buffer so nothing published in between is
+	// lost
+	sub, err := s.cache.Subscribe(ctx, s.keyBuilder.NotificationChannel(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	replay, err := s.replay(ctx, userID, after)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+	if n := len(replay); n > 0 {
+		after = eventSeq(replay[n-1])
+	}
+
+	st := &Stream{
+		Replay: replay,
+		sub:    sub,
+		events: make(chan Event),
+		done:   make(chan struct{}),
+		after:  after,
+	}
+	openStreams.Add(1)
+	go st.forward()
+
+	return st, nil
+}
+
+func (s *Service) replay(ctx context.Context, userID string, after int64) ([]Event, error) {
+	if s.cfg.ReplaySize <= 0 {
+		return nil, nil
+	}
+
+	raw, err := s.cache.LRange(ctx, s.keyBuilder.NotificationReplay(userID), 0, s.cfg.ReplaySize-1)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(raw))
+	for _, item := range raw {
+		var event Event
+		if err := json.Unmarshal([]byte(item), &event); err != nil {
+			log.Printf("[notify] skipping unreadable buffered event for user %s: %v", userID, err)
+			continue
+		}
+		if eventSeq(event) > after {
+			events = append(events, event)
+		}
+	}
+	// The buffer is newest first
+	slices.Reverse(events)
+
+	return events, nil
+}
+
+func (st *Stream) forward() {
+	defer close(st.events)
+	for msg := range st.sub.Messages() {
+		var event Event
+		if err := json.Unmarshal([]byte(msg), &event); err != nil {
+			log.Printf("[notify] skipping unreadable event: %v", err)
+			continue
+		}
+		if eventSeq(event) <= st.after {
+			continue
+		}
+
+		select {
+		case st.events <- event:
+		case <-st.done:
+			return
+		}
+	}
+}
+
+// Events delivers live events; it is closed when the subscription ends.
+func (st *Stream) Events() <-chan Event {
+	return st.events
+}
+
+// Close ends the stream. It is safe to call more than once.
+func (st *Stream) Close() error {
+	var err error
+	st.once.Do(func() {
+		close(st.done)
+		openStreams.Add(-1)
+		err = st.sub.Close()
+	})
+	return err
+}
+
+func eventSeq(event Event) int64 {
+	seq, _ := strconv.ParseInt(event.ID, 10, 64)
+	return seq
+}
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+func newTestService(t *testing.T, replaySize int64) *Service {
+	t.Helper()
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+
+	cfg := config.NotificationsConfig{ReplaySize: replaySize}
+	return New(c, cache.NewCacheKeyBuilder("test"), cfg, clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+}
+
+func ids(events []Event) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.ID
+	}
+	return out
+}
+
+// next waits for the stream's next live event.
+func next(t *testing.T, st *Stream) Event {
+	t.Helper()
+	select {
+	case event, ok := <-st.Events():
+		if !ok {
+			t.Fatal("stream closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event delivered")
+	}
+	return Event{}
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name        string
+		replaySize  int64
+		lastEventID string
+		want        []string
+	}{
+		{"fresh connect gets the buffer", 2, "", []string{"2", "3"}},
+		{"reconnect gets what it missed", 2, "2", []string{"3"}},
+		{"reconnect up to date", 2, "3", []string{}},
+		{"missed more than the buffer", 2, "0", []string{"2", "3"}},
+		{"unknown ID", 5, "bogus", []string{"1", "2", "3"}},
+		{"replay disabled", 0, "", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, tt.replaySize)
+			ctx := context.Background()
+			for _, typ := range []string{"import.completed", "ai.generated", "import.failed"} {
+				if err := s.Notify(ctx, "user-1", Event{Type: typ}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			st, err := s.Subscribe(ctx, "user-1", tt.lastEventID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer st.Close()
+
+			if got := ids(st.Replay); !slices.Equal(got, tt.want) {
+				t.Errorf("replayed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiveEvents(t *testing.T) {
+	s := newTestService(t, 10)
+	ctx := context.Background()
+
+	if err := s.Notify(ctx, "user-1", Event{Type: "import.completed"}); err != nil {
+		t.Fatal(err)
+	}
+	st, err := s.Subscribe(ctx, "user-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	other, err := s.Subscribe(ctx, "user-2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	if err := s.Notify(ctx, "user-1", Event{Type: "ai.generated", Data: map[string]string{"job": "42"}}); err != nil {
+		t.Fatal(err)
+	}
+	event := next(t, st)
+	if event.ID != "2" || event.Type != "ai.generated" || !event.CreatedAt.Equal(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("event = %+v, want ai.generated with ID 2", event)
+	}
+
+	// Events only reach their own user
+	select {
+	case event := <-other.Events():
+		t.Errorf("user-2 got %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStreamClose(t *testing.T) {
+	s := newTestService(t, 10)
+	before := openStreams.Value()
+
+	st, err := s.Subscribe(context.Background(), "user-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := openStreams.Value(); got != before+1 {
+		t.Errorf("open streams = %d, want %d", got, before+1)
+	}
+
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if got := openStreams.Value(); got != before {
+		t.Errorf("open streams after Close = %d, want %d", got, before)
+	}
+
+	select {
+	case _, ok := <-st.Events():
+		if ok {
+			t.Error("event delivered after Close")
+		}
+	case <-time.After(time.Second):
+		t.Error("Events() not closed after Close")
+	}
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+)
+
+// maxAuditLogs bounds the audit entries kept; the oldest are dropped first
+const maxAuditLogs = 10000
+
+type AuditLogRepository struct {
+	store *Store
+}
+
+func NewAuditLogRepository(store *Store) repository.AuditLogRepository {
+	return &AuditLogRepository{store: store}
+}
+
+func (r *AuditLogRepository) Create(ctx context.Context, log *domain.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if log.ID == "" {
+		log.ID = uuid.NewString()
+	}
+	log.CreatedAt = time.Now()
+
+	entry := *log
+	r.store.auditLogs = append(r.store.auditLogs, &entry)
+	if len(r.store.auditLogs) > maxAuditLogs {
+		r.store.auditLogs = r.store.auditLogs[len(r.store.auditLogs)-maxAuditLogs:]
+	}
+	return nil
+}
+
+// AuditLogs returns the audit entries recorded so far, oldest first.
+func (s *Store) AuditLogs() []domain.AuditLog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	logs := make([]domain.AuditLog, len(s.auditLogs))
+	for i, log := range s.auditLogs {
+		logs[i] = *log
+	}
+	return logs
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(*testing.T) repotest.Repos {
+		store := NewStore()
+		return repotest.Repos{Users: NewUserRepository(store), Roles: NewRoleRepository(store)}
+	})
+}
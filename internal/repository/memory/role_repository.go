@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+)
+
+type RoleRepository struct {
+	store *Store
+}
+
+func NewRoleRepository(store *Store) repository.RoleRepository {
+	return &RoleRepository{store: store}
+}
+
+// find returns the role with id visible with ctx. The caller holds the
+// store lock.
+func (r *RoleRepository) find(ctx context.Context, id string) *domain.Role {
+	role, ok := r.store.roles[id]
+	if !ok || !inSharedTenant(ctx, role.TenantID) {
+		return nil
+	}
+	return role
+}
+
+func (r *RoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Role names are unique across tenants
+	for _, existing := range r.store.roles {
+		if existing.Name == role.Name {
+			return fmt.Errorf("failed to create role: name %s is taken", role.Name)
+		}
+	}
+	if role.ID == "" {
+		role.ID = uuid.NewString()
+	}
+	if role.TenantID == nil {
+		role.TenantID = tenantOf(ctx)
+	}
+	if role.Permissions == nil {
+		role.Permissions = []byte("[]")
+	}
+	now := time.Now()
+	role.CreatedAt = now
+	role.UpdatedAt = now
+
+	r.store.roles[role.ID] = copyRole(role)
+	return nil
+}
+
+func (r *RoleRepository) FindByID(ctx context.Context, id string) (*domain.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	role := r.find(ctx, id)
+	if role == nil {
+		return nil, repository.ErrRoleNotFound
+	}
+	return copyRole(role), nil
+}
+
+func (r *RoleRepository) FindByName(ctx context.Context, name string) (*domain.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for id, role := range r.store.roles {
+		if role.Name == name && r.find(ctx, id) != nil {
+			return copyRole(role), nil
+		}
+	}
+	return nil, repository.ErrRoleNotFound
+}
+
+func (r *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.roles[role.ID]
+	if !ok {
+		return repository.ErrRoleNotFound
+	}
+	role.CreatedAt = existing.CreatedAt
+	role.UpdatedAt = time.Now()
+	r.store.roles[role.ID] = copyRole(role)
+	return nil
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Tenants can't delete the shared roles
+	role, ok := r.store.roles[id]
+	if !ok || !inTenant(ctx, role.TenantID) {
+		return repository.ErrRoleNotFound
+	}
+	delete(r.store.roles, id)
+	for key := range r.store.userRoles {
+		if key.roleID == id {
+			delete(r.store.userRoles, key)
+		}
+	}
+	return nil
+}
+
+func (r *RoleRepository) List(ctx context.Context) ([]*domain.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roles := []*domain.Role{}
+	for id, role := range r.store.roles {
+		if r.find(ctx, id) != nil {
+			roles = append(roles, copyRole(role))
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r *RoleRepository) AssignToUser(ctx context.Context, userID, roleID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Mirror the foreign keys of user_roles; assigning a role twice is a
+	// no-op, as in Postgres
+	if _, ok := r.store.users[userID]; !ok {
+		return fmt.Errorf("failed to assign role to user: unknown user %s", userID)
+	}
+	if _, ok := r.store.roles[roleID]; !ok {
+		return fmt.Errorf("failed to assign role to user: unknown role %s", roleID)
+	}
+	r.store.userRoles[userRoleKey{userID: userID, roleID: roleID}] = struct{}{}
+	return nil
+}
+
+func (r *RoleRepository) RemoveFromUser(ctx context.Context, userID, roleID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := userRoleKey{userID: userID, roleID: roleID}
+	if _, ok := r.store.userRoles[key]; !ok {
+		return repository.ErrRoleAssignmentNotFound
+	}
+	delete(r.store.userRoles, key)
+	return nil
+}
+
+func (r *RoleRepository) GetUserRoles(ctx context.Context, userID string) ([]*domain.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roles := []*domain.Role{}
+	for key := range r.store.userRoles {
+		if key.userID != userID {
+			continue
+		}
+		if role := r.find(ctx, key.roleID); role != nil {
+			roles = append(roles, copyRole(role))
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// CountUsers counts users that aren't deleted, limited to the tenant on ctx.
+func (r *RoleRepository) CountUsers(ctx context.Context) (map[string]int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for key := range r.store.userRoles {
+		row, ok := r.store.users[key.userID]
+		if !ok || row.user.DeletedAt.Valid || !inTenant(ctx, row.user.TenantID) {
+			continue
+		}
+		counts[key.roleID]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,78 @@
+// Package memory implements the user, role and audit log repositories with
+// maps, for running the API without Postgres (database.driver: memory) and
+// for tests. They follow the Postgres repositories closely: users are
+// soft-deleted, emails are unique, and lookups are limited to the tenant on
+// the context.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+// Store holds the tables the repositories share; role lookups join users
+// and user roles like the SQL they replace.
+type Store struct {
+	mu        sync.RWMutex
+	users     map[string]*userRow
+	roles     map[string]*domain.Role
+	userRoles map[userRoleKey]struct{}
+	auditLogs []*domain.AuditLog
+	// seq orders rows created in the same instant
+	seq int64
+}
+
+type userRow struct {
+	user *domain.User
+	seq  int64
+}
+
+type userRoleKey struct {
+	userID string
+	roleID string
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:     make(map[string]*userRow),
+		roles:     make(map[string]*domain.Role),
+		userRoles: make(map[userRoleKey]struct{}),
+	}
+}
+
+// inTenant reports whether a record of tenantID is visible with ctx. Without
+// a tenant on ctx everything is.
+func inTenant(ctx context.Context, tenantID *string) bool {
+	want, ok := reqctx.TenantID(ctx)
+	return !ok || (tenantID != nil && *tenantID == want)
+}
+
+// inSharedTenant is inTenant that also lets through records shared by every
+// tenant, such as the built-in roles.
+func inSharedTenant(ctx context.Context, tenantID *string) bool {
+	return tenantID == nil || inTenant(ctx, tenantID)
+}
+
+// tenantOf returns the tenant a new record created with ctx belongs to.
+func tenantOf(ctx context.Context) *string {
+	tenantID, ok := reqctx.TenantID(ctx)
+	if !ok {
+		return nil
+	}
+	return &tenantID
+}
+
+// copyUser returns a copy callers can change without touching the store.
+func copyUser(u *domain.User) *domain.User {
+	c := *u
+	c.Roles = nil
+	return &c
+}
+
+func copyRole(r *domain.Role) *domain.Role {
+	c := *r
+	return &c
+}
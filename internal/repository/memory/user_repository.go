@@ -0,0 +1,302 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &UserRepository{store: store}
+}
+
+// find returns the live user with id visible with ctx. The caller holds
+// the store lock.
+func (r *UserRepository) find(ctx context.Context, id string) *userRow {
+	row, ok := r.store.users[id]
+	if !ok || row.user.DeletedAt.Valid || !inTenant(ctx, row.user.TenantID) {
+		return nil
+	}
+	return row
+}
+
+// emailTaken reports whether another user, deleted or not, has email; the
+// unique constraint in Postgres spans every row and tenant.
+func (r *UserRepository) emailTaken(email, exceptID string) bool {
+	for id, row := range r.store.users {
+		if id != exceptID && row.user.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.emailTaken(user.Email, "") {
+		return fmt.Errorf("failed to create user: %w", domain.ErrEmailTaken)
+	}
+	if user.ID == "" {
+		user.ID = uuid.NewString()
+	} else if _, ok := r.store.users[user.ID]; ok {
+		return fmt.Errorf("failed to create user: duplicate id %s", user.ID)
+	}
+	if user.TenantID == nil {
+		user.TenantID = tenantOf(ctx)
+	}
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	r.store.seq++
+	r.store.users[user.ID] = &userRow{user: copyUser(user), seq: r.store.seq}
+	return nil
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	row := r.find(ctx, id)
+	if row == nil {
+		return nil, repository.ErrUserNotFound
+	}
+	return copyUser(row.user), nil
+}
+
+func (r *UserRepository) FindByIDWithRoles(ctx context.Context, id string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	row := r.find(ctx, id)
+	if row == nil {
+		return nil, repository.ErrUserNotFound
+	}
+	return r.withRoles(row.user), nil
+}
+
+// withRoles copies user with their roles attached. The caller holds the
+// store lock.
+func (r *UserRepository) withRoles(user *domain.User) *domain.User {
+	u := copyUser(user)
+	u.Roles = []domain.Role{}
+	for key := range r.store.userRoles {
+		if key.userID != user.ID {
+			continue
+		}
+		if role, ok := r.store.roles[key.roleID]; ok {
+			u.Roles = append(u.Roles, *role)
+		}
+	}
+	sort.Slice(u.Roles, func(i, j int) bool { return u.Roles[i].Name < u.Roles[j].Name })
+	return u
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for id, row := range r.store.users {
+		if row.user.Email == email && r.find(ctx, id) != nil {
+			return copyUser(row.user), nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.users[user.ID]
+	if !ok || row.user.DeletedAt.Valid {
+		return repository.ErrUserNotFound
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return fmt.Errorf("failed to update user: %w", domain.ErrEmailTaken)
+	}
+	user.CreatedAt = row.user.CreatedAt
+	user.UpdatedAt = time.Now()
+	row.user = copyUser(user)
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row := r.find(ctx, id)
+	if row == nil {
+		return repository.ErrUserNotFound
+	}
+	row.user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for id, row := range r.store.users {
+		if row.user.DeletedAt.Valid && row.user.DeletedAt.Time.Before(before) {
+			delete(r.store.users, id)
+			for key := range r.store.userRoles {
+				if key.userID == id {
+					delete(r.store.userRoles, key)
+				}
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *UserRepository) AvatarURLsInUse(ctx context.Context, urls []string) (map[string]bool, error) {
+	return r.inUse(urls, func(u *domain.User) *string { return u.AvatarURL }), nil
+}
+
+func (r *UserRepository) AvatarKeysInUse(ctx context.Context, keys []string) (map[string]bool, error) {
+	return r.inUse(keys, func(u *domain.User) *string { return u.AvatarKey }), nil
+}
+
+// inUse reports which of values field holds for some user, including
+// soft-deleted ones.
+func (r *UserRepository) inUse(values []string, field func(*domain.User) *string) map[string]bool {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(values))
+	for _, v := range values {
+		wanted[v] = true
+	}
+	inUse := make(map[string]bool, len(values))
+	for _, row := range r.store.users {
+		if v := field(row.user); v != nil && wanted[*v] {
+			inUse[*v] = true
+		}
+	}
+	return inUse
+}
+
+func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*domain.User, int64, error) {
+	return r.list(ctx, limit, offset, false)
+}
+
+func (r *UserRepository) ListWithRoles(ctx context.Context, limit, offset int) ([]*domain.User, int64, error) {
+	return r.list(ctx, limit, offset, true)
+}
+
+func (r *UserRepository) Count(ctx context.Context) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return int64(len(r.live(ctx))), nil
+}
+
+// live returns the users visible with ctx, newest first. The caller holds
+// the store lock.
+func (r *UserRepository) live(ctx context.Context) []*userRow {
+	var rows []*userRow
+	for id, row := range r.store.users {
+		if r.find(ctx, id) != nil {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].user.CreatedAt.Equal(rows[j].user.CreatedAt) {
+			return rows[i].user.CreatedAt.After(rows[j].user.CreatedAt)
+		}
+		return rows[i].seq > rows[j].seq
+	})
+	return rows
+}
+
+// list pages through the users like LIMIT and OFFSET would; a negative
+// limit means no limit.
+func (r *UserRepository) list(ctx context.Context, limit, offset int, withRoles bool) ([]*domain.User, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rows := r.live(ctx)
+	total := int64(len(rows))
+
+	offset = min(max(offset, 0), len(rows))
+	rows = rows[offset:]
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+
+	users := make([]*domain.User, 0, len(rows))
+	for _, row := range rows {
+		if withRoles {
+			users = append(users, r.withRoles(row.user))
+		} else {
+			users = append(users, copyUser(row.user))
+		}
+	}
+	return users, total, nil
+}
+
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	_, err := r.FindByEmail(ctx, email)
+	return err == nil, nil
+}
+
+func (r *UserRepository) BulkDelete(ctx context.Context, ids []string, atomic bool) ([]*domain.User, error) {
+	now := time.Now()
+	return r.bulkApply(ctx, ids, atomic, func(row *userRow) {
+		row.user.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	})
+}
+
+func (r *UserRepository) BulkDeactivate(ctx context.Context, ids []string, atomic bool) ([]*domain.User, error) {
+	return r.bulkApply(ctx, ids, atomic, func(row *userRow) {
+		row.user.IsActive = false
+	})
+}
+
+// bulkApply applies fn to the users of ids that exist and returns them as
+// they were before. In atomic mode nothing changes when any ID is unknown,
+// and repository.ErrBatchIncomplete is returned with the users found.
+func (r *UserRepository) bulkApply(ctx context.Context, ids []string, atomic bool, fn func(row *userRow)) ([]*domain.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var rows []*userRow
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if row := r.find(ctx, id); row != nil {
+			rows = append(rows, row)
+		}
+	}
+
+	users := make([]*domain.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, copyUser(row.user))
+	}
+	if atomic && len(rows) != len(ids) {
+		return users, repository.ErrBatchIncomplete
+	}
+	for _, row := range rows {
+		fn(row)
+	}
+	return users, nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type BusinessRepository struct {
+	db *gorm.DB
+}
+
+func NewBusinessRepository(db *gorm.DB) repository.BusinessRepository {
+	return &BusinessRepository{db: db}
+}
+
+func (r *BusinessRepository) Create(ctx context.Context, business *domain.Business) error {
+	if err := r.db.WithContext(ctx).Create(business).Error; err != nil {
+		return fmt.Errorf("failed to create business: %w", err)
+	}
+	return nil
+}
+
+func (r *BusinessRepository) FindByID(ctx context.Context, id string) (*domain.Business, error) {
+	var business domain.Business
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&business).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrBusinessNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+
+	return &business, nil
+}
+
+func (r *BusinessRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Business, error) {
+	var businesses []*domain.Business
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&businesses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list businesses: %w", err)
+	}
+	return businesses, nil
+}
+
+func (r *BusinessRepository) Update(ctx context.Context, business *domain.Business) error {
+	result := r.db.WithContext(ctx).Save(business)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update business: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrBusinessNotFound
+	}
+	return nil
+}
+
+func (r *BusinessRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.Business{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete business: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrBusinessNotFound
+	}
+	return nil
+}
+
+func (r *BusinessRepository) Count(ctx context.Context, userID string) (int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Business{})
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to count businesses: %w", err)
+	}
+	return total, nil
+}
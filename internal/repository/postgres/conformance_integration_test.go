@@ -0,0 +1,68 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/repotest"
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// migrationsDir is the repository's migrations directory, relative to this
+// package.
+const migrationsDir = "../../../migrations"
+
+// newTestDB starts Postgres in a container and applies the migrations. The
+// test is skipped with -short or when Docker isn't available.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping Postgres integration test in short mode")
+	}
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	ctr, err := tcpostgres.Run(ctx, "postgres:16-alpine",
+		tcpostgres.WithDatabase("backend_test"),
+		tcpostgres.WithUsername("backend"),
+		tcpostgres.WithPassword("backend"),
+		tcpostgres.BasicWaitStrategies(),
+	)
+	testcontainers.CleanupContainer(t, ctr)
+	if err != nil {
+		t.Fatalf("failed to start Postgres: %v", err)
+	}
+
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(gormpostgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to Postgres: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+
+	migrator, err := database.NewMigrator(db, migrationsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	db := newTestDB(t)
+	repotest.Run(t, func(*testing.T) repotest.Repos {
+		return repotest.Repos{Users: NewUserRepository(db), Roles: NewRoleRepository(db)}
+	})
+}
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/database"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the SQLSTATE of a unique constraint violation
+const uniqueViolation = "23505"
+
+// queryError describes a failed query. When the database could not be
+// reached the error also matches domain.ErrUnavailable, so callers can
+// tell an outage apart from a missing row or a rejected query.
+func queryError(action string, err error) error {
+	if database.IsUnavailable(err) {
+		return fmt.Errorf("failed to %s: %w: %w", action, domain.ErrUnavailable, err)
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// isUniqueViolation reports whether err violates a unique constraint on
+// column. The constraint is matched by name, which contains the column both
+// in the migrations (uq_users_email) and in auto-migrated schemas
+// (idx_users_email).
+func isUniqueViolation(err error, column string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && strings.Contains(pgErr.ConstraintName, column)
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type FileRepository struct {
+	db *gorm.DB
+}
+
+func NewFileRepository(db *gorm.DB) repository.FileRepository {
+	return &FileRepository{db: db}
+}
+
+func (r *FileRepository) Create(ctx context.Context, file *domain.File) error {
+	if err := r.db.WithContext(ctx).Create(file).Error; err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	return nil
+}
+
+func (r *FileRepository) FindByID(ctx context.Context, id string) (*domain.File, error) {
+	var file domain.File
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&file).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find file: %w", err)
+	}
+
+	return &file, nil
+}
+
+func (r *FileRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&domain.File{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete file: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrFileNotFound
+	}
+	return nil
+}
+
+func (r *FileRepository) StoragePathsInUse(ctx context.Context, paths []string) (map[string]bool, error) {
+	inUse := make(map[string]bool, len(paths))
+	if len(paths) == 0 {
+		return inUse, nil
+	}
+
+	var found []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&domain.File{}).
+		Where("storage_path IN ?", paths).
+		Pluck("storage_path", &found).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up file paths: %w", err)
+	}
+
+	for _, p := range found {
+		inUse[p] = true
+	}
+	return inUse, nil
+}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type GenerationRepository struct {
+	db *gorm.DB
+}
+
+func NewGenerationRepository(db *gorm.DB) repository.GenerationRepository {
+	return &GenerationRepository{db: db}
+}
+
+func (r *GenerationRepository) Create(ctx context.Context, generation *domain.Generation) error {
+	if err := r.db.WithContext(ctx).Create(generation).Error; err != nil {
+		return fmt.Errorf("failed to create generation: %w", err)
+	}
+	return nil
+}
+
+func (r *GenerationRepository) FindByID(ctx context.Context, id string) (*domain.Generation, error) {
+	var generation domain.Generation
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&generation).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrGenerationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find generation: %w", err)
+	}
+
+	return &generation, nil
+}
@@ -3,11 +3,12 @@ package postgres
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RoleRepository struct {
@@ -18,22 +19,31 @@ func NewRoleRepository(db *gorm.DB) repository.RoleRepository {
 	return &RoleRepository{db: db}
 }
 
+// scoped starts a query limited to the roles of the tenant on ctx and the
+// shared roles, if there is a tenant.
+func (r *RoleRepository) scoped(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Scopes(sharedTenantScope(ctx))
+}
+
 func (r *RoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	if role.TenantID == nil {
+		role.TenantID = tenantOf(ctx)
+	}
 	if err := r.db.WithContext(ctx).Create(role).Error; err != nil {
-		return fmt.Errorf("failed to create role: %w", err)
+		return queryError("create role", err)
 	}
 	return nil
 }
 
 func (r *RoleRepository) FindByID(ctx context.Context, id string) (*domain.Role, error) {
 	var role domain.Role
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&role).Error
+	err := r.scoped(ctx).Where("id = ?", id).First(&role).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("role not found")
+		return nil, repository.ErrRoleNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to find role: %w", err)
+		return nil, queryError("find role", err)
 	}
 
 	return &role, nil
@@ -41,13 +51,13 @@ func (r *RoleRepository) FindByID(ctx context.Context, id string) (*domain.Role,
 
 func (r *RoleRepository) FindByName(ctx context.Context, name string) (*domain.Role, error) {
 	var role domain.Role
-	err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error
+	err := r.scoped(ctx).Where("name = ?", name).First(&role).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("role not found")
+		return nil, repository.ErrRoleNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to find role: %w", err)
+		return nil, queryError("find role", err)
 	}
 
 	return &role, nil
@@ -56,30 +66,31 @@ func (r *RoleRepository) FindByName(ctx context.Context, name string) (*domain.R
 func (r *RoleRepository) Update(ctx context.Context, role *domain.Role) error {
 	result := r.db.WithContext(ctx).Save(role)
 	if result.Error != nil {
-		return fmt.Errorf("failed to update role: %w", result.Error)
+		return queryError("update role", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("role not found")
+		return repository.ErrRoleNotFound
 	}
 	return nil
 }
 
 func (r *RoleRepository) Delete(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Delete(&domain.Role{}, "id = ?", id)
+	// Tenants can't delete the shared roles
+	result := r.db.WithContext(ctx).Scopes(tenantScope(ctx)).Delete(&domain.Role{}, "id = ?", id)
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete role: %w", result.Error)
+		return queryError("delete role", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("role not found")
+		return repository.ErrRoleNotFound
 	}
 	return nil
 }
 
 func (r *RoleRepository) List(ctx context.Context) ([]*domain.Role, error) {
 	var roles []*domain.Role
-	err := r.db.WithContext(ctx).Order("name ASC").Find(&roles).Error
+	err := r.scoped(ctx).Order("name ASC").Find(&roles).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to list roles: %w", err)
+		return nil, queryError("list roles", err)
 	}
 	return roles, nil
 }
@@ -90,8 +101,10 @@ func (r *RoleRepository) AssignToUser(ctx context.Context, userID, roleID string
 		RoleID: roleID,
 	}
 
-	if err := r.db.WithContext(ctx).Create(userRole).Error; err != nil {
-		return fmt.Errorf("failed to assign role to user: %w", err)
+	// DO NOTHING makes assigning a role the user already has a no-op
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(userRole).Error
+	if err != nil {
+		return queryError("assign role to user", err)
 	}
 
 	return nil
@@ -103,10 +116,10 @@ func (r *RoleRepository) RemoveFromUser(ctx context.Context, userID, roleID stri
 		Delete(&domain.UserRole{})
 
 	if result.Error != nil {
-		return fmt.Errorf("failed to remove role from user: %w", result.Error)
+		return queryError("remove role from user", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user role assignment not found")
+		return repository.ErrRoleAssignmentNotFound
 	}
 
 	return nil
@@ -115,14 +128,38 @@ func (r *RoleRepository) RemoveFromUser(ctx context.Context, userID, roleID stri
 func (r *RoleRepository) GetUserRoles(ctx context.Context, userID string) ([]*domain.Role, error) {
 	var roles []*domain.Role
 
-	err := r.db.WithContext(ctx).
+	err := r.scoped(ctx).
 		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
 		Where("user_roles.user_id = ?", userID).
 		Find(&roles).Error
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user roles: %w", err)
+		return nil, queryError("get user roles", err)
 	}
 
 	return roles, nil
 }
+
+func (r *RoleRepository) CountUsers(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		RoleID string
+		Users  int64
+	}
+
+	query := r.db.WithContext(ctx).
+		Table("user_roles").
+		Select("user_roles.role_id, COUNT(DISTINCT user_roles.user_id) AS users").
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL")
+	if tenantID, ok := reqctx.TenantID(ctx); ok {
+		query = query.Where("users.tenant_id = ?", tenantID)
+	}
+	if err := query.Group("user_roles.role_id").Scan(&rows).Error; err != nil {
+		return nil, queryError("count role users", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.RoleID] = row.Users
+	}
+	return counts, nil
+}
@@ -0,0 +1,89 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRoleCountUsers(t *testing.T) {
+	db := newTestDB(t)
+	counter := &queryCounter{Interface: logger.Discard}
+	roles := NewRoleRepository(db.Session(&gorm.Session{Logger: counter}))
+	users := NewUserRepository(db)
+	ctx := context.Background()
+
+	newRole := func(name string) *domain.Role {
+		role := &domain.Role{Name: name, Permissions: datatypes.JSON(`["users:read"]`)}
+		if err := roles.Create(ctx, role); err != nil {
+			t.Fatal(err)
+		}
+		return role
+	}
+	owner, staff, unused := newRole("count-owner"), newRole("count-staff"), newRole("count-unused")
+
+	newUser := func(i int, tenant string, assigned ...*domain.Role) *domain.User {
+		user := &domain.User{Email: fmt.Sprintf("count-%d@example.com", i), Name: "Count", PasswordHash: "hash", IsActive: true, TenantID: &tenant}
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		for _, role := range assigned {
+			if err := roles.AssignToUser(ctx, user.ID, role.ID); err != nil {
+				t.Fatal(err)
+			}
+			// Assigning twice doesn't count twice
+			if err := roles.AssignToUser(ctx, user.ID, role.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return user
+	}
+	newUser(0, "warung", owner, staff)
+	newUser(1, "warung", staff)
+	newUser(2, "toko", staff)
+	deleted := newUser(3, "toko", owner)
+	if err := users.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]int64
+	}{
+		// Deleted users and roles nobody has are left out
+		{"all tenants", ctx, map[string]int64{owner.ID: 1, staff.ID: 3}},
+		{"one tenant", reqctx.WithTenantID(ctx, "toko"), map[string]int64{staff.ID: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter.n.Store(0)
+			got, err := roles.CountUsers(tt.ctx)
+			if err != nil {
+				t.Fatalf("CountUsers() error = %v", err)
+			}
+			if queries := counter.n.Load(); queries != 1 {
+				t.Errorf("CountUsers() ran %d queries, want 1", queries)
+			}
+			// The migrations seed an admin, so only this test's roles are
+			// compared
+			ours := maps.Clone(got)
+			maps.DeleteFunc(ours, func(id string, _ int64) bool {
+				return id != owner.ID && id != staff.ID && id != unused.ID
+			})
+			if !maps.Equal(ours, tt.want) {
+				t.Errorf("CountUsers() = %v, want %v", ours, tt.want)
+			}
+		})
+	}
+}
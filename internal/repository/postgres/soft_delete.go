@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// softDeleteRepository implements the queries shared by models with a
+// gorm.DeletedAt field, so a repository only writes the ones specific to
+// its model. Deleting sets deleted_at, the other lookups skip such rows,
+// and ListUnscoped and Restore reach them again. Every query is limited by
+// scope, such as tenantScope, and failures to find a row return notFound.
+type softDeleteRepository[T any] struct {
+	db       *gorm.DB
+	name     string
+	notFound error
+	scope    func(ctx context.Context) func(*gorm.DB) *gorm.DB
+}
+
+// newSoftDeleteRepository builds the base for model T; name describes the
+// model in errors, e.g. "user".
+func newSoftDeleteRepository[T any](db *gorm.DB, name string, notFound error, scope func(context.Context) func(*gorm.DB) *gorm.DB) softDeleteRepository[T] {
+	return softDeleteRepository[T]{db: db, name: name, notFound: notFound, scope: scope}
+}
+
+func (r softDeleteRepository[T]) scoped(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Scopes(r.scope(ctx))
+}
+
+func (r softDeleteRepository[T]) Create(ctx context.Context, model *T) error {
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return queryError("create "+r.name, err)
+	}
+	return nil
+}
+
+func (r softDeleteRepository[T]) FindByID(ctx context.Context, id string) (*T, error) {
+	var model T
+	err := r.scoped(ctx).Where("id = ?", id).First(&model).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, r.notFound
+	}
+	if err != nil {
+		return nil, queryError("find "+r.name, err)
+	}
+
+	return &model, nil
+}
+
+// Delete soft-deletes the row; it returns notFound if it is already deleted.
+func (r softDeleteRepository[T]) Delete(ctx context.Context, id string) error {
+	result := r.scoped(ctx).Delete(new(T), "id = ?", id)
+	if result.Error != nil {
+		return queryError("delete "+r.name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return r.notFound
+	}
+	return nil
+}
+
+// Restore undoes Delete; it returns notFound unless the row is deleted.
+func (r softDeleteRepository[T]) Restore(ctx context.Context, id string) error {
+	result := r.scoped(ctx).Unscoped().Model(new(T)).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return queryError("restore "+r.name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return r.notFound
+	}
+	return nil
+}
+
+// List returns a page of the rows that aren't deleted, newest first, and
+// how many there are in total.
+func (r softDeleteRepository[T]) List(ctx context.Context, limit, offset int) ([]*T, int64, error) {
+	return r.list(ctx, limit, offset, false)
+}
+
+// ListUnscoped is List including the deleted rows.
+func (r softDeleteRepository[T]) ListUnscoped(ctx context.Context, limit, offset int) ([]*T, int64, error) {
+	return r.list(ctx, limit, offset, true)
+}
+
+func (r softDeleteRepository[T]) list(ctx context.Context, limit, offset int, unscoped bool) ([]*T, int64, error) {
+	query := func() *gorm.DB {
+		db := r.scoped(ctx)
+		if unscoped {
+			db = db.Unscoped()
+		}
+		return db
+	}
+
+	var total int64
+	if err := query().Model(new(T)).Count(&total).Error; err != nil {
+		return nil, 0, queryError("count "+r.name+"s", err)
+	}
+
+	var models []*T
+	err := query().Limit(limit).Offset(offset).Order("created_at DESC").Find(&models).Error
+	if err != nil {
+		return nil, 0, queryError("list "+r.name+"s", err)
+	}
+
+	return models, total, nil
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testNote is a sample model for the soft-delete base, standing in for the
+// repositories still to come.
+type testNote struct {
+	ID        string `gorm:"primaryKey"`
+	TenantID  *string
+	Body      string
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+var errNoteNotFound = errors.New("note not found")
+
+// newSQLiteDB opens an in-memory SQLite database. The soft-delete base only
+// issues portable queries, so it is tested here without a Postgres container.
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each connection to :memory: is a separate database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestSoftDeleteRepository(t *testing.T) {
+	db := newSQLiteDB(t)
+	if err := db.AutoMigrate(&testNote{}); err != nil {
+		t.Fatal(err)
+	}
+	repo := newSoftDeleteRepository[testNote](db, "note", errNoteNotFound, tenantScope)
+
+	tenantA := reqctx.WithTenantID(context.Background(), "tenant-a")
+	tenantB := reqctx.WithTenantID(context.Background(), "tenant-b")
+	a := "tenant-a"
+	base := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"n1", "n2", "n3"} {
+		note := &testNote{ID: id, TenantID: &a, Body: "note " + id, CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := repo.Create(tenantA, note); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each step runs against the state the previous ones left
+	steps := []struct {
+		name    string
+		ctx     context.Context
+		run     func(ctx context.Context) error
+		wantErr error
+	}{
+		{"find", tenantA, func(ctx context.Context) error { _, err := repo.FindByID(ctx, "n1"); return err }, nil},
+		{"find in another tenant", tenantB, func(ctx context.Context) error { _, err := repo.FindByID(ctx, "n1"); return err }, errNoteNotFound},
+		{"delete in another tenant", tenantB, func(ctx context.Context) error { return repo.Delete(ctx, "n1") }, errNoteNotFound},
+		{"restore a live row", tenantA, func(ctx context.Context) error { return repo.Restore(ctx, "n1") }, errNoteNotFound},
+		{"delete", tenantA, func(ctx context.Context) error { return repo.Delete(ctx, "n1") }, nil},
+		{"find deleted", tenantA, func(ctx context.Context) error { _, err := repo.FindByID(ctx, "n1"); return err }, errNoteNotFound},
+		{"delete again", tenantA, func(ctx context.Context) error { return repo.Delete(ctx, "n1") }, errNoteNotFound},
+		{"restore in another tenant", tenantB, func(ctx context.Context) error { return repo.Restore(ctx, "n1") }, errNoteNotFound},
+		{"restore", tenantA, func(ctx context.Context) error { return repo.Restore(ctx, "n1") }, nil},
+		{"find restored", tenantA, func(ctx context.Context) error { _, err := repo.FindByID(ctx, "n1"); return err }, nil},
+		{"delete missing", tenantA, func(ctx context.Context) error { return repo.Delete(ctx, "missing") }, errNoteNotFound},
+		{"delete for listing", tenantA, func(ctx context.Context) error { return repo.Delete(ctx, "n2") }, nil},
+	}
+	for _, step := range steps {
+		if err := step.run(step.ctx); !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", step.name, err, step.wantErr)
+		}
+	}
+
+	lists := []struct {
+		name      string
+		ctx       context.Context
+		list      func(ctx context.Context, limit, offset int) ([]*testNote, int64, error)
+		limit     int
+		offset    int
+		wantIDs   []string
+		wantTotal int64
+	}{
+		{"list skips deleted rows", tenantA, repo.List, 10, 0, []string{"n3", "n1"}, 2},
+		{"list pages", tenantA, repo.List, 1, 1, []string{"n1"}, 2},
+		{"unscoped includes deleted rows", tenantA, repo.ListUnscoped, 10, 0, []string{"n3", "n2", "n1"}, 3},
+		{"unscoped stays in the tenant", tenantB, repo.ListUnscoped, 10, 0, nil, 0},
+	}
+	for _, tt := range lists {
+		t.Run(tt.name, func(t *testing.T) {
+			notes, total, err := tt.list(tt.ctx, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", total, tt.wantTotal)
+			}
+			var ids []string
+			for _, note := range notes {
+				ids = append(ids, note.ID)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
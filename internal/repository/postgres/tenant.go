@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tenantScope restricts a query to the tenant on ctx. Without a tenant, as
+// in single-tenant deployments and background jobs, the query is unchanged.
+func tenantScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tenantID, ok := reqctx.TenantID(ctx)
+		if !ok {
+			return db
+		}
+		return db.Where(clause.Eq{Column: tenantColumn, Value: tenantID})
+	}
+}
+
+// sharedTenantScope is tenantScope for records that may also be shared by
+// every tenant, marked by a NULL tenant_id, such as the built-in roles.
+func sharedTenantScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tenantID, ok := reqctx.TenantID(ctx)
+		if !ok {
+			return db
+		}
+		return db.Where(clause.Or(
+			clause.Eq{Column: tenantColumn, Value: tenantID},
+			clause.Eq{Column: tenantColumn, Value: nil},
+		))
+	}
+}
+
+var tenantColumn = clause.Column{Table: clause.CurrentTable, Name: "tenant_id"}
+
+// tenantOf returns the tenant a new record created with ctx belongs to.
+func tenantOf(ctx context.Context) *string {
+	tenantID, ok := reqctx.TenantID(ctx)
+	if !ok {
+		return nil
+	}
+	return &tenantID
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UsageRecordRepository struct {
+	db *gorm.DB
+}
+
+func NewUsageRecordRepository(db *gorm.DB) repository.UsageRecordRepository {
+	return &UsageRecordRepository{db: db}
+}
+
+func (r *UsageRecordRepository) Save(ctx context.Context, records []*domain.UsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "period"}, {Name: "category"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "count"}, Value: gorm.Expr("GREATEST(usage_records.count, EXCLUDED.count)")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("EXCLUDED.updated_at")},
+		},
+	}).Create(&records).Error
+	if err != nil {
+		return queryError("save usage records", err)
+	}
+	return nil
+}
+
+func (r *UsageRecordRepository) ListByUser(ctx context.Context, userID, period string) ([]*domain.UsageRecord, error) {
+	var records []*domain.UsageRecord
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND period = ?", userID, period).
+		Order("category").
+		Find(&records).Error
+	if err != nil {
+		return nil, queryError("list usage records", err)
+	}
+	return records, nil
+}
@@ -4,36 +4,51 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// UserRepository gets FindByID, List and Delete from softDeleteRepository,
+// limited to the tenant on ctx. Update and the maintenance queries stay
+// unscoped: updates only ever apply to users loaded through a scoped
+// lookup, and jobs span every tenant.
 type UserRepository struct {
+	softDeleteRepository[domain.User]
 	db *gorm.DB
 }
 
 func NewUserRepository(db *gorm.DB) repository.UserRepository {
-	return &UserRepository{db: db}
+	return &UserRepository{
+		softDeleteRepository: newSoftDeleteRepository[domain.User](db, "user", repository.ErrUserNotFound, tenantScope),
+		db:                   db,
+	}
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
-	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+	if user.TenantID == nil {
+		user.TenantID = tenantOf(ctx)
 	}
-	return nil
+	err := r.softDeleteRepository.Create(ctx, user)
+	if isUniqueViolation(err, "email") {
+		return fmt.Errorf("failed to create user: %w", domain.ErrEmailTaken)
+	}
+	return err
 }
 
-func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+// FindByIDWithRoles loads the user and their roles in one round trip per table.
+func (r *UserRepository) FindByIDWithRoles(ctx context.Context, id string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
+	err := r.scoped(ctx).Preload("Roles").Where("id = ?", id).First(&user).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("user not found")
+		return nil, repository.ErrUserNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to find user: %w", err)
+		return nil, queryError("find user", err)
 	}
 
 	return &user, nil
@@ -41,66 +56,201 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User,
 
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := r.scoped(ctx).Where("email = ?", email).First(&user).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("user not found")
+		return nil, repository.ErrUserNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to find user: %w", err)
+		return nil, queryError("find user", err)
 	}
 
 	return &user, nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
-	result := r.db.WithContext(ctx).Save(user)
+	// Roles are managed through the role repository, never by saving a user
+	result := r.db.WithContext(ctx).Omit(clause.Associations).Save(user)
+	if isUniqueViolation(result.Error, "email") {
+		return fmt.Errorf("failed to update user: %w", domain.ErrEmailTaken)
+	}
 	if result.Error != nil {
-		return fmt.Errorf("failed to update user: %w", result.Error)
+		return queryError("update user", result.Error)
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return repository.ErrUserNotFound
 	}
 	return nil
 }
 
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	result := r.db.WithContext(ctx).Delete(&domain.User{}, "id = ?", id)
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&domain.User{})
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete user: %w", result.Error)
+		return 0, queryError("purge deleted users", result.Error)
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found")
+	return result.RowsAffected, nil
+}
+
+func (r *UserRepository) AvatarURLsInUse(ctx context.Context, urls []string) (map[string]bool, error) {
+	inUse := make(map[string]bool, len(urls))
+	if len(urls) == 0 {
+		return inUse, nil
 	}
-	return nil
+
+	var found []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&domain.User{}).
+		Where("avatar_url IN ?", urls).
+		Pluck("avatar_url", &found).Error
+	if err != nil {
+		return nil, queryError("look up avatar urls", err)
+	}
+
+	for _, u := range found {
+		inUse[u] = true
+	}
+	return inUse, nil
 }
 
-func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*domain.User, int64, error) {
+func (r *UserRepository) AvatarKeysInUse(ctx context.Context, keys []string) (map[string]bool, error) {
+	inUse := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return inUse, nil
+	}
+
+	var found []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&domain.User{}).
+		Where("avatar_key IN ?", keys).
+		Pluck("avatar_key", &found).Error
+	if err != nil {
+		return nil, queryError("look up avatar keys", err)
+	}
+
+	for _, k := range found {
+		inUse[k] = true
+	}
+	return inUse, nil
+}
+
+// ListWithRoles lists users with their roles preloaded, so the query count
+// does not grow with the page size.
+func (r *UserRepository) ListWithRoles(ctx context.Context, limit, offset int) ([]*domain.User, int64, error) {
 	var users []*domain.User
 	var total int64
 
-	if err := r.db.WithContext(ctx).Model(&domain.User{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	if err := r.scoped(ctx).Model(&domain.User{}).Count(&total).Error; err != nil {
+		return nil, 0, queryError("count users", err)
 	}
 
-	err := r.db.WithContext(ctx).
+	err := r.scoped(ctx).
+		Preload("Roles").
 		Limit(limit).
 		Offset(offset).
 		Order("created_at DESC").
 		Find(&users).Error
 
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+		return nil, 0, queryError("list users", err)
 	}
 
 	return users, total, nil
 }
 
+func (r *UserRepository) Count(ctx context.Context) (int64, error) {
+	var total int64
+	if err := r.scoped(ctx).Model(&domain.User{}).Count(&total).Error; err != nil {
+		return 0, queryError("count users", err)
+	}
+	return total, nil
+}
+
 func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&domain.User{}).Where("email = ?", email).Count(&count).Error
+	err := r.scoped(ctx).Model(&domain.User{}).Where("email = ?", email).Count(&count).Error
 	if err != nil {
-		return false, fmt.Errorf("failed to check user existence: %w", err)
+		return false, queryError("check user existence", err)
 	}
 	return count > 0, nil
 }
+
+// BulkDelete soft-deletes the users in ids and returns the users that were
+// affected.
+func (r *UserRepository) BulkDelete(ctx context.Context, ids []string, atomic bool) ([]*domain.User, error) {
+	return r.bulkApply(ctx, ids, atomic, "delete users", map[string]any{
+		"deleted_at": gorm.Expr("CURRENT_TIMESTAMP"),
+	})
+}
+
+// BulkDeactivate marks the users in ids as inactive and returns the users
+// that were affected.
+func (r *UserRepository) BulkDeactivate(ctx context.Context, ids []string, atomic bool) ([]*domain.User, error) {
+	return r.bulkApply(ctx, ids, atomic, "deactivate users", map[string]any{
+		"is_active": false,
+	})
+}
+
+// bulkApply applies updates to the users in ids that aren't deleted, in one
+// UPDATE ... RETURNING statement, and returns the rows it changed; an ID
+// without a returned row is unknown. In atomic mode the whole batch is rolled
+// back with repository.ErrBatchIncomplete if any ID is unknown; the users
+// that matched are still returned so callers can report the missing ones.
+// Otherwise, if the statement fails, every row is retried on its own, and the
+// rows that fail again are reported in repository.BatchRowErrors next to the
+// users that changed.
+func (r *UserRepository) bulkApply(ctx context.Context, ids []string, atomic bool, op string, updates map[string]any) ([]*domain.User, error) {
+	apply := func(tx *gorm.DB, ids []string) ([]*domain.User, error) {
+		var users []*domain.User
+		err := tx.Model(&users).Scopes(tenantScope(ctx)).
+			Clauses(clause.Returning{}).
+			Where("id IN ?", ids).
+			Updates(updates).Error
+		if err != nil {
+			return nil, queryError(op, err)
+		}
+		return users, nil
+	}
+
+	if atomic {
+		var users []*domain.User
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			if users, err = apply(tx, ids); err != nil {
+				return err
+			}
+			if len(users) != len(ids) {
+				return repository.ErrBatchIncomplete
+			}
+			return nil
+		})
+		if errors.Is(err, repository.ErrBatchIncomplete) {
+			return users, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		return users, nil
+	}
+
+	users, err := apply(r.db.WithContext(ctx), ids)
+	if err == nil {
+		return users, nil
+	}
+
+	// The statement changed nothing, so going row by row can't apply a row
+	// twice; it only keeps one bad row from failing the others
+	users = nil
+	failed := repository.BatchRowErrors{}
+	for _, id := range ids {
+		row, err := apply(r.db.WithContext(ctx), []string{id})
+		if err != nil {
+			failed[id] = err
+			continue
+		}
+		users = append(users, row...)
+	}
+	if len(failed) > 0 {
+		return users, failed
+	}
+	return users, nil
+}
@@ -0,0 +1,163 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// queryCounter is a GORM logger that counts the statements it traces.
+type queryCounter struct {
+	logger.Interface
+	n atomic.Int64
+}
+
+func (q *queryCounter) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	q.n.Add(1)
+}
+
+func TestUserRolesPreload(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.SetupJoinTable(&domain.User{}, "Roles", &domain.UserRole{}); err != nil {
+		t.Fatal(err)
+	}
+
+	counter := &queryCounter{Interface: logger.Discard}
+	repo := NewUserRepository(db.Session(&gorm.Session{Logger: counter}))
+	roles := NewRoleRepository(db)
+	ctx := context.Background()
+
+	admin := &domain.Role{Name: "preload-admin", Permissions: datatypes.JSON("[]")}
+	editor := &domain.Role{Name: "preload-editor", Permissions: datatypes.JSON("[]")}
+	for _, role := range []*domain.Role{admin, editor} {
+		if err := roles.Create(ctx, role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range 12 {
+		user := &domain.User{Email: fmt.Sprintf("preload-%d@example.com", i), Name: "Preload", PasswordHash: "hash", IsActive: true}
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if err := roles.AssignToUser(ctx, user.ID, admin.ID); err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 0 {
+			if err := roles.AssignToUser(ctx, user.ID, editor.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// count, users, user_roles and roles, however many users are on the page
+	const maxQueries = 4
+
+	tests := []struct {
+		name  string
+		limit int
+	}{
+		{"small page", 2},
+		{"large page", 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter.n.Store(0)
+			users, total, err := repo.ListWithRoles(ctx, tt.limit, 0)
+			if err != nil {
+				t.Fatalf("ListWithRoles() error = %v", err)
+			}
+			if queries := counter.n.Load(); queries > maxQueries {
+				t.Errorf("ListWithRoles(%d) ran %d queries, want at most %d", tt.limit, queries, maxQueries)
+			}
+			if total != 12 || len(users) != tt.limit {
+				t.Fatalf("ListWithRoles(%d) = %d users of %d, want %d of 12", tt.limit, len(users), total, tt.limit)
+			}
+			for _, u := range users {
+				if len(u.Roles) == 0 || u.Roles[0].ID == "" || u.Roles[0].Name == "" {
+					t.Errorf("user %s has roles %+v, want them loaded", u.Email, u.Roles)
+				}
+			}
+		})
+	}
+
+	users, _, err := repo.List(ctx, 12, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.n.Store(0)
+	user, err := repo.FindByIDWithRoles(ctx, users[0].ID)
+	if err != nil {
+		t.Fatalf("FindByIDWithRoles() error = %v", err)
+	}
+	if queries := counter.n.Load(); queries > maxQueries-1 {
+		t.Errorf("FindByIDWithRoles() ran %d queries, want at most %d", queries, maxQueries-1)
+	}
+	if len(user.Roles) == 0 {
+		t.Errorf("FindByIDWithRoles() has no roles")
+	}
+
+	// List leaves the association alone
+	for _, u := range users {
+		if u.Roles != nil {
+			t.Errorf("List() loaded roles for %s", u.Email)
+		}
+	}
+}
+
+func TestUserBulkDeactivate(t *testing.T) {
+	db := newTestDB(t)
+	counter := &queryCounter{Interface: logger.Discard}
+	repo := NewUserRepository(db.Session(&gorm.Session{Logger: counter}))
+	ctx := context.Background()
+
+	var ids []string
+	for i := range 3 {
+		user := &domain.User{Email: fmt.Sprintf("bulk-%d@example.com", i), Name: "Bulk", PasswordHash: "hash", IsActive: true}
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, user.ID)
+	}
+	if err := repo.Delete(ctx, ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	unknown := "00000000-0000-4000-8000-000000000000"
+
+	// Unknown and deleted IDs fail the atomic batch as a whole
+	_, err := repo.BulkDeactivate(ctx, []string{ids[0], unknown}, true)
+	if !errors.Is(err, repository.ErrBatchIncomplete) {
+		t.Fatalf("atomic BulkDeactivate() = %v, want ErrBatchIncomplete", err)
+	}
+	if user, err := repo.FindByID(ctx, ids[0]); err != nil || !user.IsActive {
+		t.Fatalf("user after the rolled back batch = %+v, %v, want active", user, err)
+	}
+
+	// Otherwise they are left out, and the rest changes in one statement
+	counter.n.Store(0)
+	users, err := repo.BulkDeactivate(ctx, []string{ids[0], ids[1], ids[2], unknown}, false)
+	if err != nil {
+		t.Fatalf("BulkDeactivate() = %v", err)
+	}
+	if queries := counter.n.Load(); queries != 1 {
+		t.Errorf("BulkDeactivate() ran %d queries, want 1", queries)
+	}
+	got := map[string]bool{}
+	for _, user := range users {
+		got[user.ID] = user.IsActive
+	}
+	if len(got) != 2 || got[ids[0]] || got[ids[1]] {
+		t.Fatalf("BulkDeactivate() returned %v, want the first two users inactive", got)
+	}
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) repository.WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	if err := r.db.WithContext(ctx).Create(webhook).Error; err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) FindByID(ctx context.Context, id string) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&webhook).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context, userID string) ([]*domain.Webhook, error) {
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var webhooks []*domain.Webhook
+	if err := query.Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) ListActiveForEvent(ctx context.Context, eventType string) ([]*domain.Webhook, error) {
+	events, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event type: %w", err)
+	}
+
+	var webhooks []*domain.Webhook
+	err = r.db.WithContext(ctx).
+		Where("is_active = ? AND events @> ?::jsonb", true, string(events)).
+		Find(&webhooks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks for %s: %w", eventType, err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	result := r.db.WithContext(ctx).Save(webhook)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.Webhook{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// RecordFailure updates the counter in a single statement so concurrent
+// failures can't lose an increment.
+func (r *WebhookRepository) RecordFailure(ctx context.Context, id string, disableAfter int) (*domain.Webhook, error) {
+	updates := map[string]any{
+		"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+		"updated_at":           gorm.Expr("CURRENT_TIMESTAMP"),
+	}
+	if disableAfter > 0 {
+		disable := gorm.Expr("consecutive_failures + 1 >= ?", disableAfter)
+		updates["is_active"] = gorm.Expr("is_active AND NOT (?)", disable)
+		updates["disabled_at"] = gorm.Expr("CASE WHEN is_active AND (?) THEN CURRENT_TIMESTAMP ELSE disabled_at END", disable)
+	}
+
+	var webhook domain.Webhook
+	result := r.db.WithContext(ctx).Model(&webhook).
+		Clauses(clause.Returning{}).
+		Where("id = ?", id).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to record webhook failure: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, repository.ErrWebhookNotFound
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) ResetFailures(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Model(&domain.Webhook{}).
+		Where("id = ? AND consecutive_failures > 0", id).
+		Update("consecutive_failures", 0).Error
+	if err != nil {
+		return fmt.Errorf("failed to reset webhook failures: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		Create(delivery)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// Already recorded by an earlier attempt at dispatching the event
+	err := r.db.WithContext(ctx).
+		Where("webhook_id = ? AND event_id = ?", delivery.WebhookID, delivery.EventID).
+		First(delivery).Error
+	if err != nil {
+		return fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) FindDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&delivery).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	result := r.db.WithContext(ctx).Save(delivery)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, int64, error) {
+	var deliveries []*domain.WebhookDelivery
+	var total int64
+
+	err := r.db.WithContext(ctx).Model(&domain.WebhookDelivery{}).
+		Where("webhook_id = ?", webhookID).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	err = r.db.WithContext(ctx).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+func (r *WebhookRepository) PruneDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status <> ? AND created_at < ?", domain.DeliveryPending, before).
+		Delete(&domain.WebhookDelivery{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune webhook deliveries: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
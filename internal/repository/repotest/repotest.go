@@ -0,0 +1,335 @@
+// Package repotest is a conformance suite for the repository
+// implementations. The Postgres and in-memory repositories both run it, so
+// behavior that mocks can't show, such as soft-delete scoping and unique
+// constraints, stays the same in both.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+	"gorm.io/datatypes"
+)
+
+// Repos are the repositories under test, sharing one store.
+type Repos struct {
+	Users repository.UserRepository
+	Roles repository.RoleRepository
+}
+
+// Run runs the suite. newRepos may return the same store for every call;
+// the suite only relies on the rows it creates itself and counts relative
+// to what was there before, so seeded rows don't get in the way.
+func Run(t *testing.T, newRepos func(t *testing.T) Repos) {
+	t.Run("UserCreate", func(t *testing.T) { testUserCreate(t, newRepos(t)) })
+	t.Run("UserFindByEmail", func(t *testing.T) { testUserFindByEmail(t, newRepos(t)) })
+	t.Run("UserListTotals", func(t *testing.T) { testUserListTotals(t, newRepos(t)) })
+	t.Run("UserSoftDelete", func(t *testing.T) { testUserSoftDelete(t, newRepos(t)) })
+	t.Run("RoleAssignToUser", func(t *testing.T) { testRoleAssignToUser(t, newRepos(t)) })
+	t.Run("TenantIsolation", func(t *testing.T) { testTenantIsolation(t, newRepos(t)) })
+}
+
+var seq atomic.Int64
+
+// uniqueEmail returns an address no other test in the run uses.
+func uniqueEmail(name string) string {
+	return fmt.Sprintf("%s-%d@example.com", name, seq.Add(1))
+}
+
+func createUser(t *testing.T, repos Repos, email string) *domain.User {
+	t.Helper()
+	user := &domain.User{Email: email, Name: "Test User", PasswordHash: "hash", IsActive: true}
+	if err := repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create(%s) = %v", email, err)
+	}
+	return user
+}
+
+func testUserCreate(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	email := uniqueEmail("create")
+	user := createUser(t, repos, email)
+
+	if user.ID == "" {
+		t.Fatal("Create() left the ID empty")
+	}
+	got, err := repos.Users.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID() = %v", err)
+	}
+	if got.Email != email || !got.IsActive {
+		t.Errorf("FindByID() = %+v", got)
+	}
+
+	deleted := createUser(t, repos, uniqueEmail("deleted"))
+	if err := repos.Users.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		email string
+	}{
+		{"same email", email},
+		{"email of a deleted user", deleted.Email},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dup := &domain.User{Email: tt.email, Name: "Duplicate", PasswordHash: "hash", IsActive: true}
+			if err := repos.Users.Create(ctx, dup); !errors.Is(err, domain.ErrEmailTaken) {
+				t.Fatalf("Create() = %v, want domain.ErrEmailTaken", err)
+			}
+		})
+	}
+}
+
+func testUserFindByEmail(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	email := uniqueEmail("find")
+	user := createUser(t, repos, email)
+
+	tests := []struct {
+		name    string
+		email   string
+		wantErr error
+	}{
+		{"exact", email, nil},
+		{"different case", strings.ToUpper(email), repository.ErrUserNotFound},
+		{"unknown", uniqueEmail("unknown"), repository.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repos.Users.FindByEmail(ctx, tt.email)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FindByEmail(%s) = %v, want %v", tt.email, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.ID != user.ID {
+				t.Fatalf("FindByEmail(%s) found %s, want %s", tt.email, got.ID, user.ID)
+			}
+		})
+	}
+}
+
+func testUserListTotals(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	before, err := repos.Users.Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		createUser(t, repos, uniqueEmail("list"))
+	}
+	deleted := createUser(t, repos, uniqueEmail("list"))
+	if err := repos.Users.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	total := before + 3
+
+	tests := []struct {
+		name    string
+		limit   int
+		offset  int
+		wantLen int
+	}{
+		{"first page", 2, 0, 2},
+		{"last page", 2, int(total) - 1, 1},
+		{"past the end", 2, int(total), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, list := range map[string]func(context.Context, int, int) ([]*domain.User, int64, error){
+				"List":          repos.Users.List,
+				"ListWithRoles": repos.Users.ListWithRoles,
+			} {
+				users, got, err := list(ctx, tt.limit, tt.offset)
+				if err != nil {
+					t.Fatalf("%s() = %v", name, err)
+				}
+				if got != total {
+					t.Errorf("%s() total = %d, want %d", name, got, total)
+				}
+				if len(users) != tt.wantLen {
+					t.Errorf("%s() returned %d users, want %d", name, len(users), tt.wantLen)
+				}
+				for _, u := range users {
+					if u.ID == deleted.ID {
+						t.Errorf("%s() returned a deleted user", name)
+					}
+				}
+			}
+		})
+	}
+
+	if count, err := repos.Users.Count(ctx); err != nil || count != total {
+		t.Errorf("Count() = %d, %v, want %d", count, err, total)
+	}
+}
+
+func testUserSoftDelete(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	email := uniqueEmail("delete")
+	user := createUser(t, repos, email)
+
+	if exists, err := repos.Users.ExistsByEmail(ctx, email); err != nil || !exists {
+		t.Fatalf("ExistsByEmail() before delete = %v, %v, want true", exists, err)
+	}
+	if err := repos.Users.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Delete() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"FindByID", func() error { _, err := repos.Users.FindByID(ctx, user.ID); return err }},
+		{"FindByIDWithRoles", func() error { _, err := repos.Users.FindByIDWithRoles(ctx, user.ID); return err }},
+		{"FindByEmail", func() error { _, err := repos.Users.FindByEmail(ctx, email); return err }},
+		{"Delete again", func() error { return repos.Users.Delete(ctx, user.ID) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, repository.ErrUserNotFound) {
+				t.Fatalf("%s() = %v, want repository.ErrUserNotFound", tt.name, err)
+			}
+		})
+	}
+
+	if exists, err := repos.Users.ExistsByEmail(ctx, email); err != nil || exists {
+		t.Errorf("ExistsByEmail() after delete = %v, %v, want false", exists, err)
+	}
+}
+
+func testRoleAssignToUser(t *testing.T, repos Repos) {
+	ctx := context.Background()
+	user := createUser(t, repos, uniqueEmail("roles"))
+	role := &domain.Role{Name: fmt.Sprintf("conformance-%d", seq.Add(1)), Permissions: datatypes.JSON("[]")}
+	if err := repos.Roles.Create(ctx, role); err != nil {
+		t.Fatalf("Create(role) = %v", err)
+	}
+
+	for i := range 2 {
+		if err := repos.Roles.AssignToUser(ctx, user.ID, role.ID); err != nil {
+			t.Fatalf("AssignToUser() #%d = %v", i+1, err)
+		}
+	}
+
+	roles, err := repos.Roles.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserRoles() = %v", err)
+	}
+	if len(roles) != 1 || roles[0].ID != role.ID {
+		t.Fatalf("GetUserRoles() = %d roles, want only %s", len(roles), role.Name)
+	}
+	withRoles, err := repos.Users.FindByIDWithRoles(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByIDWithRoles() = %v", err)
+	}
+	if len(withRoles.Roles) != 1 {
+		t.Fatalf("FindByIDWithRoles() has %d roles, want 1", len(withRoles.Roles))
+	}
+
+	if err := repos.Roles.RemoveFromUser(ctx, user.ID, role.ID); err != nil {
+		t.Fatalf("RemoveFromUser() = %v", err)
+	}
+	if err := repos.Roles.RemoveFromUser(ctx, user.ID, role.ID); !errors.Is(err, repository.ErrRoleAssignmentNotFound) {
+		t.Fatalf("second RemoveFromUser() = %v, want repository.ErrRoleAssignmentNotFound", err)
+	}
+}
+
+func testTenantIsolation(t *testing.T, repos Repos) {
+	n := seq.Add(1)
+	ctxA := reqctx.WithTenantID(context.Background(), fmt.Sprintf("tenant-a-%d", n))
+	ctxB := reqctx.WithTenantID(context.Background(), fmt.Sprintf("tenant-b-%d", n))
+	unscoped := context.Background()
+
+	userA := &domain.User{Email: uniqueEmail("tenant-a"), Name: "Tenant A", PasswordHash: "hash", IsActive: true}
+	if err := repos.Users.Create(ctxA, userA); err != nil {
+		t.Fatal(err)
+	}
+	if userA.TenantID == nil || *userA.TenantID != fmt.Sprintf("tenant-a-%d", n) {
+		t.Fatalf("Create() tenant = %v, want the tenant on the context", userA.TenantID)
+	}
+	shared := &domain.Role{Name: fmt.Sprintf("shared-%d", n), Permissions: datatypes.JSON("[]")}
+	if err := repos.Roles.Create(unscoped, shared); err != nil {
+		t.Fatal(err)
+	}
+	own := &domain.Role{Name: fmt.Sprintf("tenant-a-%d", n), Permissions: datatypes.JSON("[]")}
+	if err := repos.Roles.Create(ctxA, own); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		wantUser    bool
+		wantOwnRole bool
+		wantCount   int64
+	}{
+		{"same tenant", ctxA, true, true, 1},
+		{"other tenant", ctxB, false, false, 0},
+		{"no tenant", unscoped, true, true, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantUserErr := repository.ErrUserNotFound
+			if tt.wantUser {
+				wantUserErr = nil
+			}
+			if _, err := repos.Users.FindByID(tt.ctx, userA.ID); !errors.Is(err, wantUserErr) {
+				t.Errorf("FindByID() = %v, want %v", err, wantUserErr)
+			}
+			if _, err := repos.Users.FindByEmail(tt.ctx, userA.Email); !errors.Is(err, wantUserErr) {
+				t.Errorf("FindByEmail() = %v, want %v", err, wantUserErr)
+			}
+			if exists, err := repos.Users.ExistsByEmail(tt.ctx, userA.Email); err != nil || exists != tt.wantUser {
+				t.Errorf("ExistsByEmail() = %v, %v, want %v", exists, err, tt.wantUser)
+			}
+			// Counts without a tenant span every tenant and the seeded rows
+			if tt.wantCount >= 0 {
+				if count, err := repos.Users.Count(tt.ctx); err != nil || count != tt.wantCount {
+					t.Errorf("Count() = %d, %v, want %d", count, err, tt.wantCount)
+				}
+				if users, total, err := repos.Users.List(tt.ctx, 10, 0); err != nil || total != tt.wantCount || int64(len(users)) != tt.wantCount {
+					t.Errorf("List() = %d users of %d, %v, want %d", len(users), total, err, tt.wantCount)
+				}
+			}
+
+			if _, err := repos.Roles.FindByName(tt.ctx, shared.Name); err != nil {
+				t.Errorf("FindByName(shared) = %v, want the shared role", err)
+			}
+			wantRoleErr := repository.ErrRoleNotFound
+			if tt.wantOwnRole {
+				wantRoleErr = nil
+			}
+			if _, err := repos.Roles.FindByID(tt.ctx, own.ID); !errors.Is(err, wantRoleErr) {
+				t.Errorf("FindByID(tenant role) = %v, want %v", err, wantRoleErr)
+			}
+		})
+	}
+
+	// Another tenant can't delete the user, and no tenant can delete the
+	// shared role
+	if err := repos.Users.Delete(ctxB, userA.ID); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Delete() from another tenant = %v, want repository.ErrUserNotFound", err)
+	}
+	if _, err := repos.Users.FindByID(ctxA, userA.ID); err != nil {
+		t.Errorf("FindByID() after another tenant's Delete = %v", err)
+	}
+	if err := repos.Roles.Delete(ctxA, shared.ID); !errors.Is(err, repository.ErrRoleNotFound) {
+		t.Errorf("Delete(shared role) from a tenant = %v, want repository.ErrRoleNotFound", err)
+	}
+	if err := repos.Roles.Delete(ctxB, own.ID); !errors.Is(err, repository.ErrRoleNotFound) {
+		t.Errorf("Delete(tenant role) from another tenant = %v, want repository.ErrRoleNotFound", err)
+	}
+	if err := repos.Roles.Delete(ctxA, own.ID); err != nil {
+		t.Errorf("Delete(tenant role) from its tenant = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+// Package reqctx carries request-scoped values on a context.Context so code
+// outside the HTTP layer (use cases, clients, queues) can read them.
+package reqctx
+
+import "context"
+
+type contextKey string
+
+const (
+	userIDKey    contextKey = "user_id"
+	requestIDKey contextKey = "request_id"
+	tenantIDKey  contextKey = "tenant_id"
+	routeKey     contextKey = "route"
+)
+
+// WithUserID returns a copy of ctx carrying the authenticated user's ID.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the authenticated user's ID, if any.
+func UserID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(userIDKey).(string)
+	return id, ok && id != ""
+}
+
+// WithRequestID returns a copy of ctx carrying the request's correlation ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request's correlation ID, if any.
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok && id != ""
+}
+
+// WithTenantID returns a copy of ctx carrying the tenant the request belongs to.
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantIDKey, tenantID)
+}
+
+// TenantID returns the request's tenant, if tenancy is enabled.
+func TenantID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantIDKey).(string)
+	return id, ok && id != ""
+}
+
+// WithRoute returns a copy of ctx carrying the matched route pattern, such
+// as /api/v1/users/:id.
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+// Route returns the route pattern the request matched, if any.
+func Route(ctx context.Context) (string, bool) {
+	route, ok := ctx.Value(routeKey).(string)
+	return route, ok && route != ""
+}
@@ -0,0 +1,316 @@
+// Package scheduler runs periodic maintenance jobs in-process. Every replica
+// runs the scheduler; a lock in the cache makes sure a job only runs on one
+// of them at a time.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/google/uuid"
+)
+
+const defaultTimeout = 5 * time.Minute
+
+var (
+	// ErrJobNotFound is returned when no job is registered under a name
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrJobRunning is returned when the job is already running, here or on
+	// another replica
+	ErrJobRunning = errors.New("job is already running")
+
+	// ErrStopped is returned by Trigger once the scheduler is stopping
+	ErrStopped = errors.New("scheduler is stopped")
+)
+
+// Func is the work of a job. ctx is cancelled when the job's timeout passes.
+type Func func(ctx context.Context) error
+
+// Status describes a job and its last run on any replica.
+type Status struct {
+	Name string `json:"name"`
+	// Interval is how often the job runs; empty means it only runs when triggered
+	Interval   string     `json:"interval,omitempty" example:"24h0m0s"`
+	Timeout    string     `json:"timeout" example:"5m0s"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	DurationMs int64      `json:"duration_ms"`
+	LastError  string     `json:"last_error,omitempty"`
+	// Instance identifies the replica that ran the job last
+	Instance string `json:"instance,omitempty"`
+}
+
+type lastRun struct {
+	RunAt      time.Time `json:"run_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	Instance   string    `json:"instance"`
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	timeout  time.Duration
+	run      Func
+}
+
+type Scheduler struct {
+	cache      cache.Cache
+	keyBuilder *cache.CacheKeyBuilder
+	clock      clock.Clock
+	instance   string
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	stopped bool
+
+	// ctx is cancelled when Stop gives up waiting, aborting in-flight jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func New(c cache.Cache, kb *cache.CacheKeyBuilder, clk clock.Clock) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	hostname, _ := os.Hostname()
+
+	return &Scheduler{
+		cache:      c,
+		keyBuilder: kb,
+		clock:      clk,
+		instance:   fmt.Sprintf("%s/%s", hostname, uuid.NewString()[:8]),
+		jobs:       make(map[string]*job),
+		ctx:        ctx,
+		cancel:     cancel,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Register adds a job scheduled according to cfg. A zero interval registers
+// the job for manual runs only. Register must be called before Start.
+func (s *Scheduler) Register(name string, cfg config.JobConfig, run Func) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = &job{
+		name:     name,
+		interval: cfg.Interval,
+		timeout:  cfg.Timeout,
+		run:      run,
+	}
+}
+
+// Start runs every job with an interval in the background. The first run of
+// each job happens one interval after Start.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.interval <= 0 {
+			continue
+		}
+		s.wg.Add(1)
+		go s.loop(j)
+		log.Printf("[jobs] %s scheduled every %v", j.name, j.interval)
+	}
+}
+
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if !s.lock(j) {
+				continue
+			}
+			s.execute(j)
+		}
+	}
+}
+
+// Trigger starts a job in the background right away.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	stopped := s.stopped
+	if ok && !stopped {
+		s.wg.Add(1)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return ErrJobNotFound
+	}
+	if stopped {
+		return ErrStopped
+	}
+
+	if !s.lock(j) {
+		s.wg.Done()
+		return ErrJobRunning
+	}
+	go func() {
+		defer s.wg.Done()
+		s.execute(j)
+	}()
+	return nil
+}
+
+// lock claims j for this replica. Jobs that outlive their timeout keep the
+// lock for one more timeout at most.
+func (s *Scheduler) lock(j *job) bool {
+	ok, err := s.cache.SetNX(s.ctx, s.keyBuilder.JobLock(j.name), s.instance, 2*j.timeout)
+	if err != nil {
+		log.Printf("[jobs] %s skipped, failed to take lock: %v", j.name, err)
+		return false
+	}
+	return ok
+}
+
+func (s *Scheduler) unlock(j *job) {
+	key := s.keyBuilder.JobLock(j.name)
+
+	// Only release the lock if it is still ours; it may have expired and been
+	// taken by another replica
+	holder, err := s.cache.Get(context.Background(), key)
+	if err != nil || holder != s.instance {
+		return
+	}
+	if err := s.cache.Delete(context.Background(), key); err != nil {
+		log.Printf("[jobs] failed to release lock of %s: %v", j.name, err)
+	}
+}
+
+// execute runs a locked job and records the outcome.
+func (s *Scheduler) execute(j *job) {
+	defer s.unlock(j)
+
+	start := s.clock.Now()
+	err := s.runSafely(j)
+	duration := s.clock.Now().Sub(start)
+
+	run := lastRun{
+		RunAt:      start.UTC(),
+		DurationMs: duration.Milliseconds(),
+		Instance:   s.instance,
+	}
+	if err != nil {
+		run.Error = err.Error()
+		log.Printf("[jobs] %s failed after %v: %v", j.name, duration, err)
+	} else {
+		log.Printf("[jobs] %s finished in %v", j.name, duration)
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		log.Printf("[jobs] failed to encode run of %s: %v", j.name, err)
+		return
+	}
+	if err := s.cache.HSet(context.Background(), s.keyBuilder.JobRuns(), j.name, data); err != nil {
+		log.Printf("[jobs] failed to record run of %s: %v", j.name, err)
+	}
+}
+
+func (s *Scheduler) runSafely(j *job) (err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, j.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[jobs] %s panicked: %v\n%s", j.name, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return j.run(ctx)
+}
+
+// Status returns every registered job with its last recorded run, sorted by
+// name.
+func (s *Scheduler) Status(ctx context.Context) ([]Status, error) {
+	runs, err := s.cache.HGetAll(ctx, s.keyBuilder.JobRuns())
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	statuses := make([]Status, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		status := Status{
+			Name:    j.name,
+			Timeout: j.timeout.String(),
+		}
+		if j.interval > 0 {
+			status.Interval = j.interval.String()
+		}
+
+		if raw, ok := runs[j.name]; ok {
+			var run lastRun
+			if err := json.Unmarshal([]byte(raw), &run); err != nil {
+				log.Printf("Skipping unreadable run of job %s: %v", j.name, err)
+			} else {
+				status.LastRunAt = &run.RunAt
+				status.DurationMs = run.DurationMs
+				status.LastError = run.Error
+				status.Instance = run.Instance
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses, nil
+}
+
+// Stop stops scheduling new runs and waits for running jobs to finish. If
+// ctx expires first, running jobs are cancelled and ctx's error returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	close(s.stop)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+func TestTriggerLocksAcrossReplicas(t *testing.T) {
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+	kb := cache.NewCacheKeyBuilder("test")
+	clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var replicas []*Scheduler
+	for range 2 {
+		s := New(c, kb, clk)
+		s.Register("cleanup", config.JobConfig{Timeout: time.Minute}, func(ctx context.Context) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		})
+		replicas = append(replicas, s)
+		t.Cleanup(func() { s.Stop(context.Background()) })
+	}
+
+	if err := replicas[0].Trigger("cleanup"); err != nil {
+		t.Fatalf("Trigger() = %v", err)
+	}
+	<-started
+
+	tests := []struct {
+		name    string
+		replica *Scheduler
+	}{
+		{"same replica", replicas[0]},
+		{"other replica", replicas[1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.replica.Trigger("cleanup"); !errors.Is(err, ErrJobRunning) {
+				t.Fatalf("Trigger() while running = %v, want ErrJobRunning", err)
+			}
+		})
+	}
+
+	close(release)
+	if err := replicas[0].Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The finished run released the lock for every replica
+	if err := replicas[1].Trigger("cleanup"); err != nil {
+		t.Fatalf("Trigger() after the run = %v", err)
+	}
+	<-started
+
+	if err := replicas[1].Trigger("unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Trigger(unknown) = %v, want ErrJobNotFound", err)
+	}
+	if err := replicas[0].Trigger("cleanup"); !errors.Is(err, ErrStopped) {
+		t.Errorf("Trigger() after Stop = %v, want ErrStopped", err)
+	}
+}
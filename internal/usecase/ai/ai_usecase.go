@@ -0,0 +1,215 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mlclient"
+	"github.com/Elysian-Rebirth/backend-go/internal/notify"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	"github.com/google/uuid"
+)
+
+// Metered features. Requests are counted by the quota middleware; the use
+// case counts tokens.
+const (
+	FeatureChat                      = "ai_chat"
+	FeatureChatTokens                = "ai_chat_tokens"
+	FeatureBusinessDescription       = "ai_business_description"
+	FeatureBusinessDescriptionTokens = "ai_business_description_tokens"
+)
+
+// EventChatCompleted is sent to the user's notification stream when a reply
+// is complete, so other open tabs can refresh the conversation.
+const EventChatCompleted = "ai.chat.completed"
+
+type AIUseCase interface {
+	// Chat sends the user's message with the conversation's history and
+	// calls onDelta with each piece of the reply as it arrives. An error
+	// from onDelta, or ctx being cancelled, aborts the upstream request.
+	Chat(ctx context.Context, req ChatRequest, onDelta func(delta string) error) (*ChatResult, error)
+
+	// GenerateBusinessDescription writes a description of the user's
+	// business. Every call is recorded as a generation; an identical request
+	// shortly after returns the same result.
+	GenerateBusinessDescription(ctx context.Context, req BusinessDescriptionRequest) (*GenerationResult, error)
+
+	// RegenerateBusinessDescription writes a new version of one of the
+	// user's earlier descriptions, guided by their feedback.
+	RegenerateBusinessDescription(ctx context.Context, req RegenerateRequest) (*GenerationResult, error)
+}
+
+type ChatRequest struct {
+	UserID string
+	// ConversationID continues an existing conversation; empty starts a new one
+	ConversationID string
+	Message        string
+}
+
+type ChatResult struct {
+	ConversationID string              `json:"conversation_id"`
+	Reply          string              `json:"reply"`
+	Usage          mlclient.TokenUsage `json:"usage"`
+}
+
+type aiUseCase struct {
+	ml             *mlclient.Client
+	usageUC        usage.UsageUseCase
+	generationRepo repository.GenerationRepository
+	cache          cache.Cache
+	keyBuilder     *cache.CacheKeyBuilder
+	notifier       notify.Notifier
+	cfg            config.ChatConfig
+	genCfg         config.GenerationConfig
+	blockedWords   map[string]bool
+}
+
+// NewAIUseCase creates the AI use case; ml may be nil when no ML service is
+// configured, in which case every call fails with ErrUnavailable.
+func NewAIUseCase(ml *mlclient.Client, usageUC usage.UsageUseCase, generationRepo repository.GenerationRepository, c cache.Cache, kb *cache.CacheKeyBuilder, notifier notify.Notifier, cfg config.MLConfig) AIUseCase {
+	blocked := make(map[string]bool, len(cfg.Generation.BlockedWords))
+	for _, word := range cfg.Generation.BlockedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			blocked[word] = true
+		}
+	}
+
+	return &aiUseCase{
+		ml:             ml,
+		usageUC:        usageUC,
+		generationRepo: generationRepo,
+		cache:          c,
+		keyBuilder:     kb,
+		notifier:       notifier,
+		cfg:            cfg.Chat,
+		genCfg:         cfg.Generation,
+		blockedWords:   blocked,
+	}
+}
+
+func (uc *aiUseCase) Chat(ctx context.Context, req ChatRequest, onDelta func(string) error) (*ChatResult, error) {
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return nil, ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(message) > uc.cfg.MaxMessageLength {
+		return nil, ErrMessageTooLong
+	}
+
+	conversationID := req.ConversationID
+	if conversationID == "" {
+		conversationID = uuid.NewString()
+	} else if _, err := uuid.Parse(conversationID); err != nil {
+		return nil, ErrInvalidConversation
+	}
+
+	if uc.ml == nil {
+		return nil, ErrUnavailable
+	}
+
+	history, err := uc.loadHistory(ctx, req.UserID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	history = append(history, mlclient.ChatMessage{Role: mlclient.RoleUser, Content: message})
+
+	stream, err := uc.ml.ChatStream(ctx, mlclient.ChatRequest{
+		ConversationID: conversationID,
+		Messages:       history,
+		UserID:         req.UserID,
+	})
+	if err != nil {
+		return nil, translateMLError(err)
+	}
+	defer stream.Close()
+
+	result := &ChatResult{ConversationID: conversationID}
+	var reply strings.Builder
+	for {
+		chunk, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, translateMLError(err)
+		}
+
+		if chunk.Delta != "" {
+			reply.WriteString(chunk.Delta)
+			if err := onDelta(chunk.Delta); err != nil {
+				return nil, err
+			}
+		}
+		if chunk.Usage != nil {
+			result.Usage = *chunk.Usage
+		}
+	}
+	result.Reply = reply.String()
+
+	tokens := int64(result.Usage.PromptTokens + result.Usage.CompletionTokens)
+	if tokens > 0 {
+		if _, err := uc.usageUC.AddUsage(ctx, req.UserID, FeatureChatTokens, tokens); err != nil {
+			log.Printf("Failed to count chat tokens for user %s: %v", req.UserID, err)
+		}
+	}
+
+	// The reply has already been delivered, so failing to remember it only
+	// costs the next turn some context
+	history = append(history, mlclient.ChatMessage{Role: mlclient.RoleAssistant, Content: result.Reply})
+	if err := uc.saveHistory(ctx, req.UserID, conversationID, history); err != nil {
+		log.Printf("Failed to save conversation %s: %v", conversationID, err)
+	}
+
+	event := notify.Event{
+		Type: EventChatCompleted,
+		Data: map[string]string{"conversation_id": conversationID},
+	}
+	if err := uc.notifier.Notify(ctx, req.UserID, event); err != nil {
+		log.Printf("Failed to notify user %s of chat reply: %v", req.UserID, err)
+	}
+
+	return result, nil
+}
+
+// loadHistory returns the stored messages of a conversation; an unknown or
+// expired conversation starts empty.
+func (uc *aiUseCase) loadHistory(ctx context.Context, userID, conversationID string) ([]mlclient.ChatMessage, error) {
+	raw, err := uc.cache.Get(ctx, uc.keyBuilder.AIConversation(userID, conversationID))
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var history []mlclient.ChatMessage
+	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+		log.Printf("Discarding unreadable conversation %s: %v", conversationID, err)
+		return nil, nil
+	}
+	return history, nil
+}
+
+// saveHistory keeps the most recent HistorySize messages. Concurrent turns
+// in the same conversation overwrite each other; the frontend sends one at
+// a time.
+func (uc *aiUseCase) saveHistory(ctx context.Context, userID, conversationID string, history []mlclient.ChatMessage) error {
+	if len(history) > uc.cfg.HistorySize {
+		history = history[len(history)-uc.cfg.HistorySize:]
+	}
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+	return uc.cache.Set(ctx, uc.keyBuilder.AIConversation(userID, conversationID), data, uc.cfg.HistoryTTL)
+}
@@ -0,0 +1,378 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mlclient"
+	"github.com/google/uuid"
+)
+
+// Input limits for business descriptions, in characters
+const (
+	MaxBusinessNameLength = 100
+	MaxCategoryLength     = 50
+	MaxProducts           = 10
+	MaxProductLength      = 100
+	MaxFeedbackLength     = 500
+)
+
+// Tones and languages a business description can be written in
+var (
+	BusinessTones     = []string{"friendly", "professional", "casual", "persuasive"}
+	BusinessLanguages = []string{"id", "en"}
+)
+
+const (
+	defaultTone     = "friendly"
+	defaultLanguage = "id"
+)
+
+var languageNames = map[string]string{
+	"id": "Bahasa Indonesia",
+	"en": "English",
+}
+
+type BusinessDescriptionRequest struct {
+	UserID       string
+	BusinessName string
+	Category     string
+	Products     []string
+	// Tone defaults to friendly and Language to id
+	Tone     string
+	Language string
+}
+
+type RegenerateRequest struct {
+	UserID       string
+	GenerationID string
+	// Feedback tells the model what to change; it may be empty
+	Feedback string
+}
+
+type GenerationResult struct {
+	GenerationID string              `json:"generation_id"`
+	Text         string              `json:"text"`
+	Usage        mlclient.TokenUsage `json:"usage"`
+	// Cached is set when the text was reused from an identical earlier
+	// request rather than generated
+	Cached bool `json:"-"`
+}
+
+// businessInput is the normalized request, stored with each generation and
+// hashed to recognise repeated requests
+type businessInput struct {
+	BusinessName string   `json:"business_name"`
+	Category     string   `json:"category"`
+	Products     []string `json:"products"`
+	Tone         string   `json:"tone"`
+	Language     string   `json:"language"`
+}
+
+func (uc *aiUseCase) GenerateBusinessDescription(ctx context.Context, req BusinessDescriptionRequest) (*GenerationResult, error) {
+	input, err := normalizeBusinessInput(req)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode generation input: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	hash := hex.EncodeToString(sum[:])
+
+	if uc.hasBlockedWord(input.BusinessName, input.Category, strings.Join(input.Products, " ")) {
+		uc.record(ctx, &domain.Generation{
+			UserID:    req.UserID,
+			Kind:      domain.GenerationBusinessDescription,
+			Input:     raw,
+			InputHash: hash,
+			Status:    domain.GenerationRejected,
+			Error:     ErrInappropriateInput.Error(),
+		})
+		return nil, ErrInappropriateInput
+	}
+
+	if cached, ok := uc.cachedGeneration(ctx, req.UserID, hash); ok {
+		cached.Cached = true
+		return cached, nil
+	}
+
+	messages := []mlclient.ChatMessage{
+		{Role: mlclient.RoleSystem, Content: businessSystemPrompt(input)},
+		{Role: mlclient.RoleUser, Content: businessUserPrompt(input)},
+	}
+	result, err := uc.generate(ctx, &domain.Generation{
+		UserID:    req.UserID,
+		Kind:      domain.GenerationBusinessDescription,
+		Input:     raw,
+		InputHash: hash,
+	}, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.cacheGeneration(ctx, req.UserID, hash, result)
+	return result, nil
+}
+
+func (uc *aiUseCase) RegenerateBusinessDescription(ctx context.Context, req RegenerateRequest) (*GenerationResult, error) {
+	feedback := strings.TrimSpace(req.Feedback)
+	if utf8.RuneCountInString(feedback) > MaxFeedbackLength {
+		return nil, ErrInvalidBusinessInput
+	}
+	if _, err := uuid.Parse(req.GenerationID); err != nil {
+		return nil, ErrGenerationNotFound
+	}
+
+	parent, err := uc.generationRepo.FindByID(ctx, req.GenerationID)
+	if errors.Is(err, repository.ErrGenerationNotFound) {
+		return nil, ErrGenerationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Other users' generations are reported as missing rather than forbidden
+	if parent.UserID != req.UserID || parent.Kind != domain.GenerationBusinessDescription {
+		return nil, ErrGenerationNotFound
+	}
+	if parent.Status != domain.GenerationSucceeded {
+		return nil, ErrNotRegenerable
+	}
+
+	var input businessInput
+	if err := json.Unmarshal(parent.Input, &input); err != nil {
+		return nil, fmt.Errorf("failed to decode generation input: %w", err)
+	}
+
+	generation := &domain.Generation{
+		UserID:    req.UserID,
+		Kind:      domain.GenerationBusinessDescription,
+		Input:     parent.Input,
+		InputHash: parent.InputHash,
+		ParentID:  &parent.ID,
+	}
+	if uc.hasBlockedWord(feedback) {
+		generation.Status = domain.GenerationRejected
+		generation.Error = ErrInappropriateInput.Error()
+		uc.record(ctx, generation)
+		return nil, ErrInappropriateInput
+	}
+
+	instruction := "Write a different version."
+	if feedback != "" {
+		instruction = "Rewrite it taking this feedback into account: " + feedback
+	}
+	messages := []mlclient.ChatMessage{
+		{Role: mlclient.RoleSystem, Content: businessSystemPrompt(input)},
+		{Role: mlclient.RoleUser, Content: businessUserPrompt(input)},
+		{Role: mlclient.RoleAssistant, Content: parent.Output},
+		{Role: mlclient.RoleUser, Content: instruction},
+	}
+	return uc.generate(ctx, generation, messages)
+}
+
+// generate calls the model and records the outcome in generation. Empty
+// output and output containing a blocked word are recorded but not returned.
+// The ML response cache is bypassed: descriptions are meant to vary, and a
+// cached unusable answer would fail every retry. Double submits are absorbed
+// by the per-user cache instead.
+func (uc *aiUseCase) generate(ctx context.Context, generation *domain.Generation, messages []mlclient.ChatMessage) (*GenerationResult, error) {
+	if uc.ml == nil {
+		return nil, ErrUnavailable
+	}
+
+	temperature := uc.genCfg.Temperature
+	start := time.Now()
+	resp, err := uc.ml.Chat(ctx, mlclient.ChatRequest{
+		Messages:    messages,
+		MaxTokens:   uc.genCfg.MaxTokens,
+		Temperature: &temperature,
+		UserID:      generation.UserID,
+		NoCache:     true,
+	})
+	generation.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		generation.Status = domain.GenerationFailed
+		generation.Error = err.Error()
+		uc.record(ctx, generation)
+		return nil, translateMLError(err)
+	}
+
+	generation.PromptTokens = resp.Usage.PromptTokens
+	generation.CompletionTokens = resp.Usage.CompletionTokens
+	if tokens := int64(resp.Usage.PromptTokens + resp.Usage.CompletionTokens); tokens > 0 {
+		if _, err := uc.usageUC.AddUsage(ctx, generation.UserID, FeatureBusinessDescriptionTokens, tokens); err != nil {
+			log.Printf("Failed to count generation tokens for user %s: %v", generation.UserID, err)
+		}
+	}
+
+	text := strings.TrimSpace(resp.Message.Content)
+	generation.Output = text
+	switch {
+	case text == "":
+		generation.Status = domain.GenerationFailed
+		generation.Error = "empty output"
+	case uc.hasBlockedWord(text):
+		generation.Status = domain.GenerationRejected
+		generation.Error = "output contains a blocked word"
+	default:
+		generation.Status = domain.GenerationSucceeded
+	}
+
+	if err := uc.generationRepo.Create(ctx, generation); err != nil {
+		// Without a record the result can't be regenerated, but the text
+		// itself is fine to return
+		log.Printf("Failed to record generation for user %s: %v", generation.UserID, err)
+	}
+	if generation.Status != domain.GenerationSucceeded {
+		return nil, ErrNoUsableOutput
+	}
+
+	return &GenerationResult{
+		GenerationID: generation.ID,
+		Text:         text,
+		Usage:        resp.Usage,
+	}, nil
+}
+
+// record stores a generation that never produced output; failures are only
+// logged since the caller is already returning an error of its own.
+func (uc *aiUseCase) record(ctx context.Context, generation *domain.Generation) {
+	if err := uc.generationRepo.Create(ctx, generation); err != nil {
+		log.Printf("Failed to record generation for user %s: %v", generation.UserID, err)
+	}
+}
+
+func (uc *aiUseCase) cachedGeneration(ctx context.Context, userID, hash string) (*GenerationResult, bool) {
+	if uc.genCfg.CacheTTL <= 0 {
+		return nil, false
+	}
+
+	raw, err := uc.cache.Get(ctx, uc.keyBuilder.AIGeneration(userID, hash))
+	if err != nil {
+		if !errors.Is(err, cache.ErrKeyNotFound) {
+			log.Printf("Failed to read cached generation: %v", err)
+		}
+		return nil, false
+	}
+
+	var result GenerationResult
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+func (uc *aiUseCase) cacheGeneration(ctx context.Context, userID, hash string, result *GenerationResult) {
+	if uc.genCfg.CacheTTL <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	if err := uc.cache.Set(ctx, uc.keyBuilder.AIGeneration(userID, hash), data, uc.genCfg.CacheTTL); err != nil {
+		log.Printf("Failed to cache generation: %v", err)
+	}
+}
+
+// hasBlockedWord reports whether any of texts contains a blocked word as a
+// whole word, ignoring case.
+func (uc *aiUseCase) hasBlockedWord(texts ...string) bool {
+	if len(uc.blockedWords) == 0 {
+		return false
+	}
+	for _, text := range texts {
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		for _, word := range words {
+			if uc.blockedWords[word] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeBusinessInput trims the request, applies defaults and enforces
+// the input limits.
+func normalizeBusinessInput(req BusinessDescriptionRequest) (businessInput, error) {
+	input := businessInput{
+		BusinessName: strings.TrimSpace(req.BusinessName),
+		Category:     strings.TrimSpace(req.Category),
+		Tone:         strings.ToLower(strings.TrimSpace(req.Tone)),
+		Language:     strings.ToLower(strings.TrimSpace(req.Language)),
+		Products:     []string{},
+	}
+	for _, product := range req.Products {
+		if product = strings.TrimSpace(product); product != "" {
+			input.Products = append(input.Products, product)
+		}
+	}
+	if input.Tone == "" {
+		input.Tone = defaultTone
+	}
+	if input.Language == "" {
+		input.Language = defaultLanguage
+	}
+
+	switch {
+	case input.BusinessName == "", input.Category == "":
+		return input, ErrInvalidBusinessInput
+	case utf8.RuneCountInString(input.BusinessName) > MaxBusinessNameLength,
+		utf8.RuneCountInString(input.Category) > MaxCategoryLength,
+		len(input.Products) > MaxProducts:
+		return input, ErrInvalidBusinessInput
+	case !contains(BusinessTones, input.Tone), !contains(BusinessLanguages, input.Language):
+		return input, ErrInvalidBusinessInput
+	}
+	for _, product := range input.Products {
+		if utf8.RuneCountInString(product) > MaxProductLength {
+			return input, ErrInvalidBusinessInput
+		}
+	}
+	return input, nil
+}
+
+func businessSystemPrompt(input businessInput) string {
+	return fmt.Sprintf("You are a copywriter for small Indonesian businesses (UMKM). "+
+		"Write a business description of two short paragraphs, at most 120 words, in %s, with a %s tone. "+
+		"Use only the details given; do not invent prices, addresses or awards. "+
+		"Reply with the description only, without a title, quotes or commentary.",
+		languageNames[input.Language], input.Tone)
+}
+
+func businessUserPrompt(input businessInput) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Business name: %s\n", input.BusinessName)
+	fmt.Fprintf(&b, "Category: %s\n", input.Category)
+	if len(input.Products) > 0 {
+		fmt.Fprintf(&b, "Products: %s\n", strings.Join(input.Products, ", "))
+	}
+	return b.String()
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
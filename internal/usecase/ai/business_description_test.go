@@ -0,0 +1,375 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mlclient"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/usage"
+	"github.com/google/uuid"
+)
+
+// fakeML stands in for the ML service's /chat endpoint, answering every
+// call with status and content and keeping the requests.
+type fakeML struct {
+	mu       sync.Mutex
+	status   int
+	content  string
+	requests []mlclient.ChatRequest
+}
+
+func (f *fakeML) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req mlclient.ChatRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+	if f.status != 0 && f.status != http.StatusOK {
+		w.WriteHeader(f.status)
+		w.Write([]byte(`{"detail":"model failed"}`))
+		return
+	}
+	json.NewEncoder(w).Encode(mlclient.ChatResponse{
+		Message: mlclient.ChatMessage{Role: mlclient.RoleAssistant, Content: f.content},
+		Usage:   mlclient.TokenUsage{PromptTokens: 40, CompletionTokens: 60},
+	})
+}
+
+func (f *fakeML) calls() []mlclient.ChatRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]mlclient.ChatRequest(nil), f.requests...)
+}
+
+// memGenerations is an in-memory repository.GenerationRepository.
+type memGenerations struct {
+	mu          sync.Mutex
+	generations []*domain.Generation
+}
+
+func (r *memGenerations) Create(ctx context.Context, g *domain.Generation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g.ID = uuid.NewString()
+	copied := *g
+	r.generations = append(r.generations, &copied)
+	return nil
+}
+
+func (r *memGenerations) FindByID(ctx context.Context, id string) (*domain.Generation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, g := range r.generations {
+		if g.ID == id {
+			copied := *g
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrGenerationNotFound
+}
+
+func (r *memGenerations) all() []*domain.Generation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*domain.Generation(nil), r.generations...)
+}
+
+// tokenCounter is a usage.UsageUseCase that only counts added usage.
+type tokenCounter struct {
+	usage.UsageUseCase
+	mu     sync.Mutex
+	tokens map[string]int64
+}
+
+func (u *tokenCounter) AddUsage(ctx context.Context, userID, feature string, n int64) (*usage.Usage, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.tokens[feature] += n
+	return &usage.Usage{}, nil
+}
+
+type testAI struct {
+	uc          *aiUseCase
+	ml          *fakeML
+	generations *memGenerations
+	usage       *tokenCounter
+}
+
+func newTestAI(t *testing.T) *testAI {
+	t.Helper()
+	ml := &fakeML{content: "  Warung Bu Sri menyajikan nasi uduk hangat setiap pagi.  "}
+	srv := httptest.NewServer(ml)
+	t.Cleanup(srv.Close)
+
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+
+	cfg := config.MLConfig{
+		ServiceURL: srv.URL,
+		Timeout:    time.Second,
+		Generation: config.GenerationConfig{
+			MaxTokens:    300,
+			Temperature:  0.7,
+			CacheTTL:     time.Minute,
+			BlockedWords: []string{"Bodoh"},
+		},
+	}
+	client := mlclient.New(cfg, clock.New())
+	ta := &testAI{
+		ml:          ml,
+		generations: &memGenerations{},
+		usage:       &tokenCounter{tokens: make(map[string]int64)},
+	}
+	ta.uc = NewAIUseCase(client, ta.usage, ta.generations, c, cache.NewCacheKeyBuilder("test"), nil, cfg).(*aiUseCase)
+	return ta
+}
+
+func validBusiness() BusinessDescriptionRequest {
+	return BusinessDescriptionRequest{
+		UserID:       "user-1",
+		BusinessName: "Warung Bu Sri",
+		Category:     "Kuliner",
+		Products:     []string{"Nasi uduk", "Es teh"},
+	}
+}
+
+func TestGenerateBusinessDescription(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(req *BusinessDescriptionRequest)
+		mlStatus   int
+		mlContent  string
+		wantErr    error
+		wantCalls  int
+		wantStatus string // of the recorded generation, if one is recorded
+	}{
+		{"success", func(*BusinessDescriptionRequest) {}, 200, "", nil, 1, domain.GenerationSucceeded},
+		{"english and professional", func(r *BusinessDescriptionRequest) { r.Language, r.Tone = "EN", "Professional" }, 200, "", nil, 1, domain.GenerationSucceeded},
+
+		{"missing name", func(r *BusinessDescriptionRequest) { r.BusinessName = "   " }, 200, "", ErrInvalidBusinessInput, 0, ""},
+		{"missing category", func(r *BusinessDescriptionRequest) { r.Category = "" }, 200, "", ErrInvalidBusinessInput, 0, ""},
+		{"name too long", func(r *BusinessDescriptionRequest) { r.BusinessName = strings.Repeat("é", MaxBusinessNameLength+1) }, 200, "", ErrInvalidBusinessInput, 0, ""},
+		{"name at the limit", func(r *BusinessDescriptionRequest) { r.BusinessName = strings.Repeat("é", MaxBusinessNameLength) }, 200, "", nil, 1, domain.GenerationSucceeded},
+		{"too many products", func(r *BusinessDescriptionRequest) { r.Products = slices.Repeat([]string{"Produk"}, MaxProducts+1) }, 200, "", ErrInvalidBusinessInput, 0, ""},
+		{"blank products are dropped", func(r *BusinessDescriptionRequest) { r.Products = append(make([]string, MaxProducts+1), "Kopi") }, 200, "", nil, 1, domain.GenerationSucceeded},
+		{"product too long", func(r *BusinessDescriptionRequest) { r.Products = []string{strings.Repeat("x", MaxProductLength+1)} }, 200, "", ErrInvalidBusinessInput, 0, ""},
+		{"unknown tone", func(r *BusinessDescriptionRequest) { r.Tone = "angry" }, 200, "", ErrInvalidBusinessInput, 0, ""},
+		{"unknown language", func(r *BusinessDescriptionRequest) { r.Language = "fr" }, 200, "", ErrInvalidBusinessInput, 0, ""},
+
+		{"blocked word in the input", func(r *BusinessDescriptionRequest) { r.Products = []string{"Kopi BODOH"} }, 200, "", ErrInappropriateInput, 0, domain.GenerationRejected},
+		{"blocked word inside another word", func(r *BusinessDescriptionRequest) { r.BusinessName = "Kebodohan Cafe" }, 200, "", nil, 1, domain.GenerationSucceeded},
+		{"empty output", func(*BusinessDescriptionRequest) {}, 200, " \n ", ErrNoUsableOutput, 1, domain.GenerationFailed},
+		{"blocked word in the output", func(*BusinessDescriptionRequest) {}, 200, "Jangan jadi bodoh, beli di sini!", ErrNoUsableOutput, 1, domain.GenerationRejected},
+		{"model rejects the request", func(*BusinessDescriptionRequest) {}, 422, "", ErrInvalidInput, 1, domain.GenerationFailed},
+		{"service down", func(*BusinessDescriptionRequest) {}, 503, "", ErrUnavailable, 1, domain.GenerationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAI(t)
+			ta.ml.status = tt.mlStatus
+			if tt.mlContent != "" {
+				ta.ml.content = tt.mlContent
+			}
+			req := validBusiness()
+			tt.modify(&req)
+
+			result, err := ta.uc.GenerateBusinessDescription(context.Background(), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateBusinessDescription() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(ta.ml.calls()); got != tt.wantCalls {
+				t.Errorf("ML service called %d times, want %d", got, tt.wantCalls)
+			}
+
+			generations := ta.generations.all()
+			if tt.wantStatus == "" {
+				if len(generations) != 0 {
+					t.Errorf("recorded %d generations, want none", len(generations))
+				}
+				return
+			}
+			if len(generations) != 1 || generations[0].Status != tt.wantStatus || generations[0].UserID != "user-1" {
+				t.Fatalf("recorded %+v, want one %s generation", generations, tt.wantStatus)
+			}
+			if tt.wantErr != nil {
+				if generations[0].Error == "" {
+					t.Error("failed generation recorded without an error")
+				}
+				return
+			}
+
+			if result.Text != "Warung Bu Sri menyajikan nasi uduk hangat setiap pagi." || result.GenerationID != generations[0].ID || result.Cached {
+				t.Errorf("result = %+v, want the trimmed text of generation %s", result, generations[0].ID)
+			}
+			if got := ta.usage.tokens[FeatureBusinessDescriptionTokens]; got != 100 {
+				t.Errorf("counted %d tokens, want 100", got)
+			}
+		})
+	}
+}
+
+func TestBusinessDescriptionPrompt(t *testing.T) {
+	ta := newTestAI(t)
+	if _, err := ta.uc.GenerateBusinessDescription(context.Background(), validBusiness()); err != nil {
+		t.Fatal(err)
+	}
+
+	req := ta.ml.calls()[0]
+	if len(req.Messages) != 2 || req.Messages[0].Role != mlclient.RoleSystem || req.Messages[1].Role != mlclient.RoleUser {
+		t.Fatalf("messages = %+v, want a system and a user message", req.Messages)
+	}
+	// Defaults apply
+	if system := req.Messages[0].Content; !strings.Contains(system, "Bahasa Indonesia") || !strings.Contains(system, "friendly") {
+		t.Errorf("system prompt = %q, want Bahasa Indonesia and a friendly tone", system)
+	}
+	want := "Business name: Warung Bu Sri\nCategory: Kuliner\nProducts: Nasi uduk, Es teh\n"
+	if req.Messages[1].Content != want {
+		t.Errorf("user prompt = %q, want %q", req.Messages[1].Content, want)
+	}
+	if req.MaxTokens != 300 || req.Temperature == nil || *req.Temperature != 0.7 || req.UserID != "user-1" {
+		t.Errorf("request = %+v, want the generation settings", req)
+	}
+}
+
+func TestBusinessDescriptionCache(t *testing.T) {
+	ta := newTestAI(t)
+	ctx := context.Background()
+
+	first, err := ta.uc.GenerateBusinessDescription(ctx, validBusiness())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A double click, with the input spelled slightly differently
+	again := validBusiness()
+	again.BusinessName = "  Warung Bu Sri "
+	again.Tone, again.Language = "FRIENDLY", "id"
+	second, err := ta.uc.GenerateBusinessDescription(ctx, again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Cached || second.GenerationID != first.GenerationID || second.Text != first.Text {
+		t.Errorf("second result = %+v, want the first one from the cache", second)
+	}
+	if got := len(ta.ml.calls()); got != 1 {
+		t.Errorf("ML service called %d times, want 1", got)
+	}
+
+	// Other users and other inputs aren't served from it
+	other := validBusiness()
+	other.UserID = "user-2"
+	changed := validBusiness()
+	changed.Category = "Minuman"
+	for _, req := range []BusinessDescriptionRequest{other, changed} {
+		if result, err := ta.uc.GenerateBusinessDescription(ctx, req); err != nil || result.Cached {
+			t.Errorf("GenerateBusinessDescription(%+v) = %+v, %v, want a fresh generation", req, result, err)
+		}
+	}
+	if got := len(ta.ml.calls()); got != 3 {
+		t.Errorf("ML service called %d times, want 3", got)
+	}
+
+	// Unusable output is never cached
+	ta.ml.content = ""
+	failing := validBusiness()
+	failing.BusinessName = "Toko Baru"
+	for range 2 {
+		if _, err := ta.uc.GenerateBusinessDescription(ctx, failing); !errors.Is(err, ErrNoUsableOutput) {
+			t.Fatalf("GenerateBusinessDescription() error = %v, want %v", err, ErrNoUsableOutput)
+		}
+	}
+	if got := len(ta.ml.calls()); got != 5 {
+		t.Errorf("ML service called %d times, want 5", got)
+	}
+}
+
+func TestRegenerateBusinessDescription(t *testing.T) {
+	ta := newTestAI(t)
+	ctx := context.Background()
+
+	parent, err := ta.uc.GenerateBusinessDescription(ctx, validBusiness())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ta.ml.content = ""
+	failed := validBusiness()
+	failed.BusinessName = "Toko Gagal"
+	ta.uc.GenerateBusinessDescription(ctx, failed)
+	failedID := ta.generations.all()[1].ID
+	ta.ml.content = "Versi baru yang lebih singkat."
+
+	tests := []struct {
+		name         string
+		userID       string
+		generationID string
+		feedback     string
+		wantErr      error
+	}{
+		{"with feedback", "user-1", parent.GenerationID, "Lebih singkat", nil},
+		{"without feedback", "user-1", parent.GenerationID, "  ", nil},
+		{"another user's generation", "user-2", parent.GenerationID, "", ErrGenerationNotFound},
+		{"unknown generation", "user-1", uuid.NewString(), "", ErrGenerationNotFound},
+		{"malformed ID", "user-1", "not-a-uuid", "", ErrGenerationNotFound},
+		{"generation without output", "user-1", failedID, "", ErrNotRegenerable},
+		{"feedback too long", "user-1", parent.GenerationID, strings.Repeat("a", MaxFeedbackLength+1), ErrInvalidBusinessInput},
+		{"blocked word in the feedback", "user-1", parent.GenerationID, "jangan bodoh", ErrInappropriateInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := len(ta.ml.calls())
+			result, err := ta.uc.RegenerateBusinessDescription(ctx, RegenerateRequest{UserID: tt.userID, GenerationID: tt.generationID, Feedback: tt.feedback})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RegenerateBusinessDescription() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if got := len(ta.ml.calls()); got != calls {
+					t.Errorf("ML service called %d times, want none", got-calls)
+				}
+				return
+			}
+
+			if result.Text != "Versi baru yang lebih singkat." || result.GenerationID == parent.GenerationID {
+				t.Errorf("result = %+v, want a new generation", result)
+			}
+			record, _ := ta.generations.FindByID(ctx, result.GenerationID)
+			if record.ParentID == nil || *record.ParentID != parent.GenerationID {
+				t.Errorf("ParentID = %v, want %s", record.ParentID, parent.GenerationID)
+			}
+
+			// The model sees its earlier answer and what to change
+			messages := ta.ml.calls()[calls].Messages
+			if len(messages) != 4 || messages[2].Content != parent.Text {
+				t.Fatalf("messages = %+v, want the earlier answer as the third", messages)
+			}
+			if feedback := strings.TrimSpace(tt.feedback); feedback != "" && !strings.Contains(messages[3].Content, feedback) {
+				t.Errorf("instruction = %q, want the feedback", messages[3].Content)
+			}
+		})
+	}
+}
+
+func TestGenerateWithoutMLService(t *testing.T) {
+	c := cache.NewMemoryCache()
+	defer c.Close()
+	uc := NewAIUseCase(nil, &tokenCounter{tokens: map[string]int64{}}, &memGenerations{}, c, cache.NewCacheKeyBuilder("test"), nil, config.MLConfig{})
+
+	if _, err := uc.GenerateBusinessDescription(context.Background(), validBusiness()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("GenerateBusinessDescription() error = %v, want %v", err, ErrUnavailable)
+	}
+}
@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"errors"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mlclient"
+)
+
+var (
+	// ErrUnavailable means the ML service is down or its circuit breaker is
+	// open. Handlers respond 503 right away rather than retrying.
+	ErrUnavailable = errors.New("AI temporarily unavailable")
+
+	// ErrInvalidInput means the ML service rejected the request
+	ErrInvalidInput = errors.New("AI request rejected")
+
+	// ErrEmptyMessage means the chat message was blank
+	ErrEmptyMessage = errors.New("message is empty")
+
+	// ErrMessageTooLong means the chat message exceeds ml.chat.max_message_length
+	ErrMessageTooLong = errors.New("message is too long")
+
+	// ErrInvalidConversation means the conversation ID is malformed
+	ErrInvalidConversation = errors.New("invalid conversation id")
+
+	// ErrInvalidBusinessInput means a business description request is
+	// missing a field, exceeds a length limit or names an unknown tone or
+	// language
+	ErrInvalidBusinessInput = errors.New("invalid business details")
+
+	// ErrInappropriateInput means the request contains a blocked word
+	ErrInappropriateInput = errors.New("input contains inappropriate language")
+
+	// ErrNoUsableOutput means the model replied with nothing, or with text
+	// that was withheld for containing a blocked word
+	ErrNoUsableOutput = errors.New("no usable output generated")
+
+	// ErrGenerationNotFound means the generation doesn't exist or belongs
+	// to another user
+	ErrGenerationNotFound = errors.New("generation not found")
+
+	// ErrNotRegenerable means the generation produced no text to revise
+	ErrNotRegenerable = errors.New("generation cannot be regenerated")
+)
+
+// translateMLError maps ML client failures onto the use case's errors, so
+// handlers never depend on the client's error types.
+func translateMLError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mlclient.ErrRejected):
+		return errors.Join(ErrInvalidInput, err)
+	case errors.Is(err, mlclient.ErrUnavailable):
+		return errors.Join(ErrUnavailable, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mlclient"
+)
+
+func TestTranslateMLError(t *testing.T) {
+	other := errors.New("encode failed")
+
+	tests := []struct {
+		name    string
+		err     error
+		want    error
+		notWant error
+	}{
+		{"nil", nil, nil, nil},
+		{"circuit open", mlclient.ErrCircuitOpen, ErrUnavailable, ErrInvalidInput},
+		{"service down", fmt.Errorf("chat: %w", mlclient.ErrUnavailable), ErrUnavailable, ErrInvalidInput},
+		{"input rejected", fmt.Errorf("chat: %w", mlclient.ErrRejected), ErrInvalidInput, ErrUnavailable},
+		{"unrelated", other, other, ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translateMLError(tt.err)
+			if tt.err == nil {
+				if got != nil {
+					t.Fatalf("translateMLError(nil) = %v", got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("translateMLError() = %v, want the original error kept", got)
+			}
+			if tt.want != nil && !errors.Is(got, tt.want) {
+				t.Errorf("translateMLError() = %v, want %v", got, tt.want)
+			}
+			if tt.notWant != nil && errors.Is(got, tt.notWant) {
+				t.Errorf("translateMLError() = %v, should not match %v", got, tt.notWant)
+			}
+		})
+	}
+}
@@ -2,14 +2,26 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/mail"
-	"regexp"
 	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
 	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+)
+
+const (
+	// refreshRotationGrace is how long a rotated refresh token is remembered
+	// as such, so racing refresh requests are told it was rotated instead of
+	// that it is invalid.
+	refreshRotationGrace = 30 * time.Second
+	rotationPending      = "pending"
+	rotationDone         = "rotated"
 )
 
 type AuthUseCase interface {
@@ -33,7 +45,9 @@ type LoginRequest struct {
 type AuthResponse struct {
 	AccessToken  string
 	RefreshToken string
-	User         *domain.User
+	// RefreshExpiresAt is when RefreshToken runs out unless it is used
+	RefreshExpiresAt time.Time
+	User             *domain.User
 }
 
 type authUseCase struct {
@@ -42,6 +56,12 @@ type authUseCase struct {
 	jwtSvc      *JWTService
 	cache       cache.Cache
 	keyBuilder  *cache.CacheKeyBuilder
+	sessions    *SessionStore
+	publisher   queue.Publisher
+	// emailDomains decides which email domains may register
+	emailDomains *EmailDomainPolicy
+	names        *NameSanitizer
+	clock        clock.Clock
 }
 
 func NewAuthUseCase(
@@ -50,25 +70,43 @@ func NewAuthUseCase(
 	js *JWTService,
 	c cache.Cache,
 	kb *cache.CacheKeyBuilder,
+	sessions *SessionStore,
+	publisher queue.Publisher,
+	emailDomains *EmailDomainPolicy,
+	names *NameSanitizer,
+	clk clock.Clock,
 ) AuthUseCase {
 	return &authUseCase{
-		userRepo:    repo,
-		passwordSvc: ps,
-		jwtSvc:      js,
-		cache:       c,
-		keyBuilder:  kb,
+		userRepo:     repo,
+		passwordSvc:  ps,
+		jwtSvc:       js,
+		cache:        c,
+		keyBuilder:   kb,
+		sessions:     sessions,
+		publisher:    publisher,
+		emailDomains: emailDomains,
+		names:        names,
+		clock:        clk,
 	}
 }
 
 func (uc *authUseCase) Register(ctx context.Context, req RegisterRequest) (*AuthResponse, error) {
 	_, err := mail.ParseAddress(req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("invalid email format: %w", err)
+		return nil, domain.NewValidationError("Invalid email format")
+	}
+
+	if !emailPattern.MatchString(req.Email) {
+		return nil, domain.NewValidationError("Invalid email format")
 	}
 
-	emailRegex := regexp.MustCompile(`^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$`)
-	if !emailRegex.MatchString(req.Email) {
-		return nil, fmt.Errorf("invalid email format: does not match required pattern")
+	if !uc.emailDomains.Allows(req.Email) {
+		return nil, domain.ErrEmailDomainNotAllowed
+	}
+
+	name, err := uc.names.Sanitize(req.Name)
+	if err != nil {
+		return nil, err
 	}
 
 	exists, err := uc.userRepo.ExistsByEmail(ctx, req.Email)
@@ -76,11 +114,11 @@ func (uc *authUseCase) Register(ctx context.Context, req RegisterRequest) (*Auth
 		return nil, err
 	}
 	if exists {
-		return nil, fmt.Errorf("email already registered")
+		return nil, domain.ErrEmailTaken
 	}
 
 	if len(req.Password) < 8 {
-		return nil, fmt.Errorf("password must be at least 8 characters")
+		return nil, domain.NewValidationError("Password must be at least 8 characters")
 	}
 
 	hashedPass, err := uc.passwordSvc.HashPassword(req.Password)
@@ -90,7 +128,7 @@ func (uc *authUseCase) Register(ctx context.Context, req RegisterRequest) (*Auth
 
 	user := &domain.User{
 		Email:        req.Email,
-		Name:         req.Name,
+		Name:         name,
 		PasswordHash: hashedPass,
 		IsActive:     true,
 	}
@@ -99,6 +137,12 @@ func (uc *authUseCase) Register(ctx context.Context, req RegisterRequest) (*Auth
 		return nil, err
 	}
 
+	// The account exists at this point, so a broker outage must not fail the registration
+	event := domain.UserRegisteredEvent{UserID: user.ID, Email: user.Email, Name: user.Name}
+	if err := uc.publisher.Publish(ctx, domain.EventUserRegistered, event); err != nil {
+		log.Printf("Failed to publish %s for user %s: %v", domain.EventUserRegistered, user.ID, err)
+	}
+
 	accessToken, err := uc.jwtSvc.GenerateAccessToken(user.ID, user.Email)
 	if err != nil {
 		return nil, err
@@ -109,20 +153,24 @@ func (uc *authUseCase) Register(ctx context.Context, req RegisterRequest) (*Auth
 		return nil, err
 	}
 
-	refreshKey := uc.keyBuilder.RefreshToken(refreshToken)
-	if err := uc.cache.Set(ctx, refreshKey, user.ID, 7*time.Hour*24); err != nil {
+	session, err := uc.sessions.Add(ctx, user.ID, refreshToken)
+	if err != nil {
 		return nil, err
 	}
 
 	return &AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		User:             user,
 	}, nil
 }
 
 func (uc *authUseCase) Login(ctx context.Context, req LoginRequest) (*AuthResponse, error) {
 	user, err := uc.userRepo.FindByEmail(ctx, req.Email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, domain.ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +179,11 @@ func (uc *authUseCase) Login(ctx context.Context, req LoginRequest) (*AuthRespon
 		return nil, err
 	}
 
+	// Checked after the password so disabled accounts can't be probed
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+
 	accessToken, err := uc.jwtSvc.GenerateAccessToken(user.ID, user.Email)
 	if err != nil {
 		return nil, err
@@ -141,12 +194,12 @@ func (uc *authUseCase) Login(ctx context.Context, req LoginRequest) (*AuthRespon
 		return nil, err
 	}
 
-	refreshKey := uc.keyBuilder.RefreshToken(refreshToken)
-	if err := uc.cache.Set(ctx, refreshKey, user.ID, 7*time.Hour*24); err != nil {
+	session, err := uc.sessions.Add(ctx, user.ID, refreshToken)
+	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now()
+	now := uc.clock.Now()
 	user.LastLoginAt = &now
 	if err := uc.userRepo.Update(ctx, user); err != nil {
 		return nil, err
@@ -155,55 +208,174 @@ func (uc *authUseCase) Login(ctx context.Context, req LoginRequest) (*AuthRespon
 	user.PasswordHash = ""
 
 	return &AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		User:             user,
 	}, nil
 }
 
 func (uc *authUseCase) RefreshToken(ctx context.Context, refreshToken string) (*AuthResponse, error) {
-	refreshKey := uc.keyBuilder.RefreshToken(refreshToken)
-	userID, err := uc.cache.Get(ctx, refreshKey)
+	rotationKey := uc.keyBuilder.For(ctx).RefreshTokenRotation(refreshToken)
+
+	// Claim the rotation before touching the token, so a concurrent request
+	// presenting the same token finds the claim instead of racing this one
+	claimed, err := uc.cache.SetNX(ctx, rotationKey, rotationPending, refreshRotationGrace)
+	if err != nil {
+		return nil, sessionStoreError(err)
+	}
+	if !claimed {
+		return nil, uc.rotationState(ctx, rotationKey)
+	}
+
+	// The token stays in the store until the new one is saved, so a failure
+	// on the way leaves it usable for a retry
+	session, err := uc.sessions.Get(ctx, refreshToken)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		uc.clearRotation(ctx, rotationKey)
+		return nil, ErrInvalidRefreshToken
+	}
 	if err != nil {
+		uc.clearRotation(ctx, rotationKey)
+		return nil, sessionStoreError(err)
+	}
+	userID := session.UserID
+
+	// The token's cache expiry already ends idle sessions; this also catches
+	// a session that crossed a limit between the lookup and now
+	if err := uc.sessions.Check(session); err != nil {
+		uc.dropRefreshToken(ctx, userID, refreshToken)
+		uc.clearRotation(ctx, rotationKey)
 		return nil, err
 	}
 
 	user, err := uc.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		uc.dropRefreshToken(ctx, userID, refreshToken)
+		uc.clearRotation(ctx, rotationKey)
+		return nil, ErrInvalidRefreshToken
+	}
 	if err != nil {
+		uc.clearRotation(ctx, rotationKey)
 		return nil, err
 	}
+	if !user.IsActive {
+		uc.dropRefreshToken(ctx, userID, refreshToken)
+		uc.clearRotation(ctx, rotationKey)
+		return nil, ErrAccountDisabled
+	}
 
 	newAccessToken, err := uc.jwtSvc.GenerateAccessToken(user.ID, user.Email)
 	if err != nil {
+		uc.clearRotation(ctx, rotationKey)
 		return nil, err
 	}
 
 	newRefreshToken, err := uc.jwtSvc.GenerateRefreshToken(user.ID)
 	if err != nil {
+		uc.clearRotation(ctx, rotationKey)
 		return nil, err
 	}
 
-	if err := uc.cache.Delete(ctx, refreshKey); err != nil {
+	renewed, err := uc.sessions.Renew(ctx, session, newRefreshToken)
+	if errors.Is(err, ErrSessionExpired) {
+		uc.dropRefreshToken(ctx, userID, refreshToken)
+		uc.clearRotation(ctx, rotationKey)
 		return nil, err
 	}
+	if err != nil {
+		uc.clearRotation(ctx, rotationKey)
+		return nil, sessionStoreError(err)
+	}
 
-	newRefreshKey := uc.keyBuilder.RefreshToken(newRefreshToken)
-	if err := uc.cache.Set(ctx, newRefreshKey, user.ID, 7*time.Hour*24); err != nil {
-		return nil, err
+	// A missing token means the session was revoked while this request ran;
+	// the revocation wins over the token just issued
+	_, err = uc.sessions.Consume(ctx, refreshToken)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		uc.dropRefreshToken(ctx, userID, newRefreshToken)
+		uc.clearRotation(ctx, rotationKey)
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		log.Printf("Failed to consume rotated refresh token: %v", err)
+	}
+	if err := uc.sessions.Remove(ctx, userID, refreshToken); err != nil {
+		log.Printf("Failed to remove refresh token from session index: %v", err)
+	}
+
+	// Only the fact that the token was rotated is kept, never the new
+	// token: whoever else presents the old one gets an error, not a session
+	if err := uc.cache.Set(ctx, rotationKey, rotationDone, refreshRotationGrace); err != nil {
+		log.Printf("Failed to record refresh token rotation: %v", err)
 	}
 
 	user.PasswordHash = ""
 
 	return &AuthResponse{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
-		User:         user,
+		AccessToken:      newAccessToken,
+		RefreshToken:     newRefreshToken,
+		RefreshExpiresAt: renewed.ExpiresAt,
+		User:             user,
 	}, nil
 }
 
+// rotationState explains why a refresh token could not be claimed: another
+// request is rotating it (ErrRefreshInProgress) or rotated it within the
+// grace window (ErrRefreshTokenRotated). Either way the caller should pick
+// up the token the other request was given rather than log the user out.
+func (uc *authUseCase) rotationState(ctx context.Context, rotationKey string) error {
+	marker, err := uc.cache.Get(ctx, rotationKey)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		// The claim was released between the two calls, i.e. the rotation
+		// failed. The token may have outlived the failure, which only a new
+		// attempt can tell
+		return ErrRefreshInProgress
+	}
+	if err != nil {
+		// Not an outage answer: the other request may have rotated the token
+		// already, and a 503 would tell the client to keep it
+		log.Printf("Failed to read refresh token rotation marker: %v", err)
+		return ErrRefreshInProgress
+	}
+	if marker == rotationPending {
+		return ErrRefreshInProgress
+	}
+	return ErrRefreshTokenRotated
+}
+
+// sessionStoreError marks a failure of the cache sessions live in as an
+// outage: no refresh can succeed without it, and the client should retry
+// rather than discard its token. Only use it where the token is still valid.
+func sessionStoreError(err error) error {
+	return fmt.Errorf("session store: %w: %w", domain.ErrUnavailable, err)
+}
+
+// dropRefreshToken deletes a refresh token that can no longer be used.
+func (uc *authUseCase) dropRefreshToken(ctx context.Context, userID, refreshToken string) {
+	if _, err := uc.sessions.Consume(ctx, refreshToken); err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+		log.Printf("Failed to delete refresh token: %v", err)
+	}
+	if err := uc.sessions.Remove(ctx, userID, refreshToken); err != nil {
+		log.Printf("Failed to remove refresh token from session index: %v", err)
+	}
+}
+
+func (uc *authUseCase) clearRotation(ctx context.Context, rotationKey string) {
+	if err := uc.cache.Delete(ctx, rotationKey); err != nil {
+		log.Printf("Failed to clear refresh token rotation marker: %v", err)
+	}
+}
+
 func (uc *authUseCase) Logout(ctx context.Context, refreshToken string) error {
-	refreshKey := fmt.Sprintf("refresh_token:%s", refreshToken)
-	if err := uc.cache.Delete(ctx, refreshKey); err != nil {
+	session, err := uc.sessions.Consume(ctx, refreshToken)
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to logout: %w", err)
+	}
+
+	if err := uc.sessions.Remove(ctx, session.UserID, refreshToken); err != nil {
 		return fmt.Errorf("failed to logout: %w", err)
 	}
 	return nil
@@ -0,0 +1,647 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/memory"
+)
+
+const (
+	testEmail    = "owner@example.com"
+	testPassword = "correct horse battery"
+)
+
+var testJWTConfig = config.JWTConfig{
+	Secret:             "test-secret-that-is-long-enough-to-sign",
+	AccessTokenExpiry:  15 * time.Minute,
+	RefreshTokenExpiry: 7 * 24 * time.Hour,
+	Issuer:             "test",
+}
+
+type testAuth struct {
+	uc       AuthUseCase
+	cache    *cache.MemoryCache
+	sessions *SessionStore
+	clock    *clock.Mock
+	user     *domain.User
+}
+
+// newTestAuth builds the auth use case on in-memory stores with one active
+// user who can log in with testEmail and testPassword.
+func newTestAuth(t *testing.T, cfg config.JWTConfig) *testAuth {
+	t.Helper()
+	return newTestAuthWith(t, cfg, config.SecurityConfig{})
+}
+
+// newTestAuthWith is newTestAuth with the registration and name rules of
+// security.
+func newTestAuthWith(t *testing.T, cfg config.JWTConfig, security config.SecurityConfig) *testAuth {
+	t.Helper()
+
+	clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+	kb := cache.NewCacheKeyBuilder("test")
+
+	passwords := NewPasswordService()
+	hash, err := passwords.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := memory.NewUserRepository(memory.NewStore())
+	user := &domain.User{Email: testEmail, Name: "Owner", PasswordHash: hash, IsActive: true}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	jwtSvc, err := NewJWTService(cfg, clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := NewSessionStore(c, kb, cfg, clk)
+
+	return &testAuth{
+		uc: NewAuthUseCase(users, passwords, jwtSvc, c, kb, sessions, queue.NoopPublisher{},
+			NewEmailDomainPolicy(security.Registration), NewNameSanitizer(security.Names), clk),
+		cache:    c,
+		sessions: sessions,
+		clock:    clk,
+		user:     user,
+	}
+}
+
+func (ta *testAuth) login(t *testing.T) *AuthResponse {
+	t.Helper()
+	res, err := ta.uc.Login(context.Background(), LoginRequest{Email: testEmail, Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login() = %v", err)
+	}
+	return res
+}
+
+func TestLoginIssuesDistinctRefreshTokens(t *testing.T) {
+	ta := newTestAuth(t, testJWTConfig)
+
+	// The clock doesn't move, so both tokens carry the same iat and exp
+	first := ta.login(t)
+	second := ta.login(t)
+	if first.RefreshToken == second.RefreshToken {
+		t.Fatal("two logins in the same second got the same refresh token")
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare runs before the old token is presented again and returns
+		// the error that is expected then
+		prepare func(t *testing.T, ta *testAuth, old string) error
+	}{
+		{
+			name:    "reuse within the grace window",
+			prepare: func(*testing.T, *testAuth, string) error { return ErrRefreshTokenRotated },
+		},
+		{
+			name: "reuse after the grace window",
+			prepare: func(t *testing.T, ta *testAuth, old string) error {
+				key := cache.NewCacheKeyBuilder("test").RefreshTokenRotation(old)
+				if err := ta.cache.Delete(context.Background(), key); err != nil {
+					t.Fatal(err)
+				}
+				return ErrInvalidRefreshToken
+			},
+		},
+		{
+			name: "reuse while another rotation is pending",
+			prepare: func(t *testing.T, ta *testAuth, old string) error {
+				key := cache.NewCacheKeyBuilder("test").RefreshTokenRotation(old)
+				if err := ta.cache.Set(context.Background(), key, rotationPending, time.Minute); err != nil {
+					t.Fatal(err)
+				}
+				return ErrRefreshInProgress
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAuth(t, testJWTConfig)
+			ctx := context.Background()
+			old := ta.login(t).RefreshToken
+
+			rotated, err := ta.uc.RefreshToken(ctx, old)
+			if err != nil {
+				t.Fatalf("RefreshToken() = %v", err)
+			}
+			if rotated.RefreshToken == old {
+				t.Fatal("refresh token was not rotated")
+			}
+
+			want := tt.prepare(t, ta, old)
+			res, err := ta.uc.RefreshToken(ctx, old)
+			if !errors.Is(err, want) {
+				t.Fatalf("RefreshToken(old) = %v, want %v", err, want)
+			}
+			if res != nil {
+				t.Fatal("old refresh token yielded a session")
+			}
+
+			// The new token is unaffected by the replay
+			if _, err := ta.uc.RefreshToken(ctx, rotated.RefreshToken); err != nil {
+				t.Fatalf("RefreshToken(new) = %v", err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenConcurrent(t *testing.T) {
+	ta := newTestAuth(t, testJWTConfig)
+	old := ta.login(t).RefreshToken
+
+	const callers = 20
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		results = make([]*AuthResponse, callers)
+		errs    = make([]error, callers)
+	)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			results[i], errs[i] = ta.uc.RefreshToken(context.Background(), old)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	var winners []string
+	for i := range callers {
+		switch {
+		case errs[i] == nil:
+			winners = append(winners, results[i].RefreshToken)
+		case errors.Is(errs[i], ErrRefreshInProgress), errors.Is(errs[i], ErrRefreshTokenRotated):
+			if results[i] != nil {
+				t.Errorf("caller %d got a session along with %v", i, errs[i])
+			}
+		default:
+			t.Errorf("caller %d: unexpected error %v", i, errs[i])
+		}
+	}
+
+	if len(winners) != 1 {
+		t.Fatalf("%d callers rotated the token, want exactly 1", len(winners))
+	}
+	if _, err := ta.sessions.Get(context.Background(), winners[0]); err != nil {
+		t.Fatalf("new refresh token has no session: %v", err)
+	}
+}
+
+// flakyUsers fails the first FindByID, like a database dropping a
+// connection, and then answers normally.
+type flakyUsers struct {
+	repository.UserRepository
+	failed bool
+}
+
+func (r *flakyUsers) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	if !r.failed {
+		r.failed = true
+		return nil, errors.New("connection reset by peer")
+	}
+	return r.UserRepository.FindByID(ctx, id)
+}
+
+func TestRefreshTokenRetryAfterFailure(t *testing.T) {
+	ta := newTestAuth(t, testJWTConfig)
+	ctx := context.Background()
+	old := ta.login(t).RefreshToken
+
+	uc := *ta.uc.(*authUseCase)
+	uc.userRepo = &flakyUsers{UserRepository: uc.userRepo}
+
+	if _, err := uc.RefreshToken(ctx, old); err == nil {
+		t.Fatal("RefreshToken() succeeded while the database was failing")
+	}
+
+	// The failed attempt neither spent the token nor left a claim behind
+	res, err := uc.RefreshToken(ctx, old)
+	if err != nil {
+		t.Fatalf("RefreshToken() retry = %v", err)
+	}
+	if _, err := ta.sessions.Get(ctx, res.RefreshToken); err != nil {
+		t.Fatalf("new refresh token has no session: %v", err)
+	}
+	if _, err := ta.sessions.Get(ctx, old); !errors.Is(err, cache.ErrKeyNotFound) {
+		t.Fatalf("old refresh token after rotation = %v, want cache.ErrKeyNotFound", err)
+	}
+}
+
+// downCache fails every SetNX, as Redis does while unreachable.
+type downCache struct {
+	cache.Cache
+}
+
+func (downCache) SetNX(context.Context, string, any, time.Duration) (bool, error) {
+	return false, errors.New("dial tcp: connection refused")
+}
+
+// downUsers fails every FindByID, as the Postgres repository does while the
+// database is unreachable.
+type downUsers struct {
+	repository.UserRepository
+}
+
+func (downUsers) FindByID(context.Context, string) (*domain.User, error) {
+	return nil, fmt.Errorf("failed to find user: %w", domain.ErrUnavailable)
+}
+
+func TestRefreshTokenReportsStoreOutage(t *testing.T) {
+	tests := []struct {
+		name   string
+		outage func(uc *authUseCase)
+	}{
+		{"cache down", func(uc *authUseCase) { uc.cache = downCache{uc.cache} }},
+		{"database down", func(uc *authUseCase) { uc.userRepo = downUsers{uc.userRepo} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAuth(t, testJWTConfig)
+			old := ta.login(t).RefreshToken
+
+			uc := *ta.uc.(*authUseCase)
+			tt.outage(&uc)
+
+			_, err := uc.RefreshToken(context.Background(), old)
+			if !errors.Is(err, domain.ErrUnavailable) {
+				t.Fatalf("RefreshToken() = %v, want domain.ErrUnavailable", err)
+			}
+
+			// The token survives the outage
+			if _, err := ta.uc.RefreshToken(context.Background(), old); err != nil {
+				t.Fatalf("RefreshToken() after the outage = %v", err)
+			}
+		})
+	}
+}
+
+// lostMarkerCache finds every rotation claimed but cannot read the marker,
+// as when Redis fails between the two calls.
+type lostMarkerCache struct {
+	cache.Cache
+}
+
+func (lostMarkerCache) SetNX(context.Context, string, any, time.Duration) (bool, error) {
+	return false, nil
+}
+
+func (lostMarkerCache) Get(context.Context, string) (string, error) {
+	return "", errors.New("i/o timeout")
+}
+
+func TestRefreshTokenUnknownRotationState(t *testing.T) {
+	ta := newTestAuth(t, testJWTConfig)
+	old := ta.login(t).RefreshToken
+
+	uc := *ta.uc.(*authUseCase)
+	uc.cache = lostMarkerCache{ta.cache}
+
+	// The other request may have rotated the token, so this is no outage
+	// the client could ride out by keeping it
+	_, err := uc.RefreshToken(context.Background(), old)
+	if !errors.Is(err, ErrRefreshInProgress) {
+		t.Fatalf("RefreshToken() = %v, want ErrRefreshInProgress", err)
+	}
+}
+
+func TestLoginRefusesDisabledAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		active   bool
+		password string
+		wantErr  error
+	}{
+		{"active", true, testPassword, nil},
+		{"disabled", false, testPassword, ErrAccountDisabled},
+		// Without the password a disabled account looks like any other
+		{"disabled with wrong password", false, "wrong password", domain.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAuth(t, testJWTConfig)
+			ctx := context.Background()
+			users := ta.uc.(*authUseCase).userRepo
+
+			user, err := users.FindByID(ctx, ta.user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			user.IsActive = tt.active
+			if err := users.Update(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := ta.uc.Login(ctx, LoginRequest{Email: testEmail, Password: tt.password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && res != nil {
+				t.Fatal("refused login returned tokens")
+			}
+		})
+	}
+}
+
+func TestRegisterEmailDomains(t *testing.T) {
+	security := config.SecurityConfig{Registration: config.RegistrationConfig{
+		AllowedDomains:  []string{"umkm.co.id", "mailinator.com"},
+		DeniedDomains:   []string{"blocked.umkm.co.id"},
+		BlockDisposable: true,
+	}}
+
+	tests := []struct {
+		name    string
+		email   string
+		wantErr error
+	}{
+		{"allowed", "shop@umkm.co.id", nil},
+		{"allowed subdomain", "shop@mail.umkm.co.id", nil},
+		{"not on the allowlist", "shop@example.com", domain.ErrEmailDomainNotAllowed},
+		{"denied", "shop@blocked.umkm.co.id", domain.ErrEmailDomainNotAllowed},
+		{"disposable", "shop@mailinator.com", domain.ErrEmailDomainNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAuthWith(t, testJWTConfig, security)
+			_, err := ta.uc.Register(context.Background(), RegisterRequest{Email: tt.email, Name: "Toko Budi", Password: testPassword})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Register(%s) = %v, want %v", tt.email, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterSanitizesName(t *testing.T) {
+	security := config.SecurityConfig{Names: config.NameConfig{BlockedWords: []string{"scam"}}}
+
+	tests := []struct {
+		name     string
+		input    string
+		wantName string
+	}{
+		{"trimmed and collapsed", "  Toko   Budi ", "Toko Budi"},
+		{"too short", "J", ""},
+		{"too long", strings.Repeat("a", MaxNameLength+1), ""},
+		{"blocked word", "Scam Shop", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAuthWith(t, testJWTConfig, security)
+			res, err := ta.uc.Register(context.Background(), RegisterRequest{Email: "shop@example.com", Name: tt.input, Password: testPassword})
+			if tt.wantName == "" {
+				var validationErr *domain.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Register() = %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Register() = %v", err)
+			}
+			if res.User.Name != tt.wantName {
+				t.Errorf("stored name = %q, want %q", res.User.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      RegisterRequest
+		wantErr  error
+		wantType bool
+	}{
+		{"new account", RegisterRequest{Email: "shop@example.com", Name: "Toko Budi", Password: testPassword}, nil, false},
+		{"email taken", RegisterRequest{Email: testEmail, Name: "Toko Budi", Password: testPassword}, domain.ErrEmailTaken, false},
+		{"invalid email", RegisterRequest{Email: "not-an-email", Name: "Toko Budi", Password: testPassword}, nil, true},
+		{"short password", RegisterRequest{Email: "shop@example.com", Name: "Toko Budi", Password: "short"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAuth(t, testJWTConfig)
+			ctx := context.Background()
+
+			res, err := ta.uc.Register(ctx, tt.req)
+			if tt.wantType {
+				var validationErr *domain.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Register() = %v, want a validation error", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Register() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// The refresh token is stored and indexed under the new user
+			session, err := ta.sessions.Get(ctx, res.RefreshToken)
+			if err != nil || session.UserID != res.User.ID {
+				t.Fatalf("stored session = %+v, %v, want one for %s", session, err, res.User.ID)
+			}
+			index, err := ta.cache.SMembers(ctx, cache.NewCacheKeyBuilder("test").UserSessions(res.User.ID))
+			if err != nil || len(index) != 1 || index[0] != res.RefreshToken {
+				t.Errorf("session index = %v, %v, want the refresh token", index, err)
+			}
+			if !res.RefreshExpiresAt.Equal(ta.clock.Now().Add(testJWTConfig.RefreshTokenExpiry)) {
+				t.Errorf("RefreshExpiresAt = %v", res.RefreshExpiresAt)
+			}
+
+			// The new account can log in
+			if _, err := ta.uc.Login(ctx, LoginRequest{Email: tt.req.Email, Password: tt.req.Password}); err != nil {
+				t.Errorf("Login() after Register = %v", err)
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	ta := newTestAuth(t, testJWTConfig)
+	ctx := context.Background()
+	indexKey := cache.NewCacheKeyBuilder("test").UserSessions(ta.user.ID)
+
+	current := ta.login(t).RefreshToken
+	other := ta.login(t).RefreshToken
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"current session", current},
+		{"already logged out", current},
+		{"unknown token", "not-a-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ta.uc.Logout(ctx, tt.token); err != nil {
+				t.Fatalf("Logout() = %v", err)
+			}
+		})
+	}
+
+	if _, err := ta.uc.RefreshToken(ctx, current); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("RefreshToken() after Logout = %v, want ErrInvalidRefreshToken", err)
+	}
+	index, err := ta.cache.SMembers(ctx, indexKey)
+	if err != nil || len(index) != 1 || index[0] != other {
+		t.Errorf("session index = %v, %v, want only the other session", index, err)
+	}
+	// Logging out one device leaves the others signed in
+	if _, err := ta.uc.RefreshToken(ctx, other); err != nil {
+		t.Errorf("RefreshToken() of the other session = %v", err)
+	}
+}
+
+func TestRefreshTokenIdleExpiry(t *testing.T) {
+	cfg := testJWTConfig
+	cfg.RefreshIdleExpiry = time.Hour
+	cfg.RefreshAbsoluteExpiry = 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		idle    time.Duration
+		wantErr error
+	}{
+		{"used in time", 59 * time.Minute, nil},
+		{"idle for the whole window", time.Hour, ErrSessionExpired},
+		{"idle for a day", 24 * time.Hour, ErrSessionExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestAuth(t, cfg)
+			ctx := context.Background()
+			token := ta.login(t).RefreshToken
+
+			ta.clock.Advance(tt.idle)
+			_, err := ta.uc.RefreshToken(ctx, token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshToken() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			// The expired session is gone, not left to be retried
+			if _, err := ta.uc.RefreshToken(ctx, token); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("RefreshToken() again = %v, want %v", err, ErrInvalidRefreshToken)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenSlidingExpiry(t *testing.T) {
+	// A daily user of the mobile app: each refresh buys another day, but
+	// the session still ends a week after login
+	cfg := testJWTConfig
+	cfg.RefreshIdleExpiry = 24 * time.Hour
+	cfg.RefreshAbsoluteExpiry = 7 * 24 * time.Hour
+
+	ta := newTestAuth(t, cfg)
+	ctx := context.Background()
+	login := ta.clock.Now()
+	res := ta.login(t)
+	if want := login.Add(24 * time.Hour); !res.RefreshExpiresAt.Equal(want) {
+		t.Fatalf("RefreshExpiresAt after login = %s, want %s", res.RefreshExpiresAt, want)
+	}
+
+	tests := []struct {
+		name string
+		// at is the time of the refresh, from login
+		at time.Duration
+		// wantExpiresAt is from login; 0 expects ErrSessionExpired
+		wantExpiresAt time.Duration
+	}{
+		{"refresh 1", 20 * time.Hour, 44 * time.Hour},
+		{"refresh 2", 40 * time.Hour, 64 * time.Hour},
+		{"refresh 3", 60 * time.Hour, 84 * time.Hour},
+		{"refresh 4", 80 * time.Hour, 104 * time.Hour},
+		{"refresh 5", 100 * time.Hour, 124 * time.Hour},
+		{"refresh 6", 120 * time.Hour, 144 * time.Hour},
+		{"refresh 7", 140 * time.Hour, 164 * time.Hour},
+		{"capped by the absolute limit", 160 * time.Hour, 168 * time.Hour},
+		{"past the absolute limit", 169 * time.Hour, 0},
+	}
+
+	// Each refresh rotates the token the previous one returned
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta.clock.Set(login.Add(tt.at))
+			rotated, err := ta.uc.RefreshToken(ctx, res.RefreshToken)
+			if tt.wantExpiresAt == 0 {
+				if !errors.Is(err, ErrSessionExpired) {
+					t.Fatalf("RefreshToken() = %v, want %v", err, ErrSessionExpired)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RefreshToken() = %v", err)
+			}
+			res = rotated
+
+			if want := login.Add(tt.wantExpiresAt); !res.RefreshExpiresAt.Equal(want) {
+				t.Errorf("RefreshExpiresAt = %s, want %s", res.RefreshExpiresAt, want)
+			}
+			// The stored token lives exactly as long as it can be used
+			ttl, err := ta.cache.TTL(ctx, cache.NewCacheKeyBuilder("test").RefreshToken(res.RefreshToken))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.wantExpiresAt - tt.at; ttl > want || ttl < want-time.Second {
+				t.Errorf("cache TTL = %s, want %s", ttl, want)
+			}
+		})
+	}
+}
+
+func TestLoginRecordsLastLogin(t *testing.T) {
+	ta := newTestAuth(t, testJWTConfig)
+
+	tests := []struct {
+		name  string
+		after time.Duration
+	}{
+		{"first login", 0},
+		{"later login", 36 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta.clock.Advance(tt.after)
+			res := ta.login(t)
+			if res.User.LastLoginAt == nil || !res.User.LastLoginAt.Equal(ta.clock.Now()) {
+				t.Errorf("LastLoginAt = %v, want %s", res.User.LastLoginAt, ta.clock.Now())
+			}
+		})
+	}
+}
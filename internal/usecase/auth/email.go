@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidEmail means the address can't be parsed or isn't a plain
+// mailbox address
+var ErrInvalidEmail = errors.New("invalid email format")
+
+var emailPattern = regexp.MustCompile(`^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$`)
+
+// NormalizeEmail trims and lower-cases email after checking it against the
+// same rules as registration.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !emailPattern.MatchString(email) {
+		return "", ErrInvalidEmail
+	}
+
+	return email, nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	_ "embed"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+//go:embed disposable_domains.txt
+var disposableDomainList string
+
+// EmailDomainPolicy decides which email domains may register. A listed
+// domain covers its subdomains, and denials win over the allowlist.
+type EmailDomainPolicy struct {
+	allowed map[string]bool
+	denied  map[string]bool
+}
+
+func NewEmailDomainPolicy(cfg config.RegistrationConfig) *EmailDomainPolicy {
+	p := &EmailDomainPolicy{
+		allowed: domainSet(cfg.AllowedDomains),
+		denied:  domainSet(cfg.DeniedDomains),
+	}
+	if cfg.BlockDisposable {
+		for _, line := range strings.Split(disposableDomainList, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				p.denied[normalizeDomain(line)] = true
+			}
+		}
+	}
+	return p
+}
+
+// Allows reports whether email's domain may register. email is expected to
+// have been validated already.
+func (p *EmailDomainPolicy) Allows(email string) bool {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+	domain := normalizeDomain(email[at+1:])
+
+	if matchDomain(p.denied, domain) {
+		return false
+	}
+	return len(p.allowed) == 0 || matchDomain(p.allowed, domain)
+}
+
+// matchDomain reports whether domain or one of its parent domains is in set.
+func matchDomain(set map[string]bool, domain string) bool {
+	for {
+		if set[domain] {
+			return true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			return false
+		}
+		domain = parent
+	}
+}
+
+func domainSet(domains []string) map[string]bool {
+	set := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		if d = normalizeDomain(d); d != "" {
+			set[d] = true
+		}
+	}
+	return set
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
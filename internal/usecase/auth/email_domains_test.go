@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+func TestEmailDomainPolicy(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   config.RegistrationConfig
+		email string
+		want  bool
+	}{
+		{"no lists", config.RegistrationConfig{}, "owner@anything.example", true},
+		{"disposable allowed when not blocked", config.RegistrationConfig{}, "owner@mailinator.com", true},
+
+		{"allowed domain", config.RegistrationConfig{AllowedDomains: []string{"umkm.co.id"}}, "owner@umkm.co.id", true},
+		{"subdomain of an allowed domain", config.RegistrationConfig{AllowedDomains: []string{"umkm.co.id"}}, "owner@mail.umkm.co.id", true},
+		{"domain outside the allowlist", config.RegistrationConfig{AllowedDomains: []string{"umkm.co.id"}}, "owner@example.com", false},
+		{"lookalike of an allowed domain", config.RegistrationConfig{AllowedDomains: []string{"umkm.co.id"}}, "owner@notumkm.co.id", false},
+		{"allowlist entry normalized", config.RegistrationConfig{AllowedDomains: []string{" UMKM.co.id. "}}, "owner@umkm.co.id", true},
+		{"email domain normalized", config.RegistrationConfig{AllowedDomains: []string{"umkm.co.id"}}, "owner@UMKM.Co.Id.", true},
+
+		{"denied domain", config.RegistrationConfig{DeniedDomains: []string{"competitor.com"}}, "owner@competitor.com", false},
+		{"subdomain of a denied domain", config.RegistrationConfig{DeniedDomains: []string{"competitor.com"}}, "owner@eu.competitor.com", false},
+		{"domain outside the denylist", config.RegistrationConfig{DeniedDomains: []string{"competitor.com"}}, "owner@example.com", true},
+		{"denial wins over the allowlist", config.RegistrationConfig{AllowedDomains: []string{"umkm.co.id"}, DeniedDomains: []string{"spam.umkm.co.id"}}, "owner@spam.umkm.co.id", false},
+
+		{"disposable domain", config.RegistrationConfig{BlockDisposable: true}, "owner@mailinator.com", false},
+		{"disposable domain in another case", config.RegistrationConfig{BlockDisposable: true}, "owner@YopMail.com", false},
+		{"subdomain of a disposable domain", config.RegistrationConfig{BlockDisposable: true}, "owner@x.mailinator.com", false},
+		{"regular domain with disposables blocked", config.RegistrationConfig{BlockDisposable: true}, "owner@gmail.com", true},
+		{"disposable domain on the allowlist", config.RegistrationConfig{AllowedDomains: []string{"mailinator.com"}, BlockDisposable: true}, "owner@mailinator.com", false},
+
+		{"no domain", config.RegistrationConfig{}, "owner", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewEmailDomainPolicy(tt.cfg).Allows(tt.email); got != tt.want {
+				t.Errorf("Allows(%s) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
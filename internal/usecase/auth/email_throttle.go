@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+// Kinds of email counted separately by EmailThrottle.
+const (
+	EmailKindVerification  = "verification"
+	EmailKindPasswordReset = "password_reset"
+)
+
+// ErrEmailThrottled means the recipient used up its budget for that kind of
+// email; errors.As with *EmailThrottledError gives the time until the next
+// allowed send.
+var ErrEmailThrottled = errors.New("too many emails sent to this address")
+
+type EmailThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *EmailThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrEmailThrottled, e.RetryAfter)
+}
+
+func (e *EmailThrottledError) Is(target error) bool {
+	return target == ErrEmailThrottled
+}
+
+// EmailThrottle enforces the per-recipient send budgets of
+// config.EmailThrottleConfig. It is called by the use cases that send mail
+// rather than by the HTTP layer, so every path to an email shares one budget.
+//
+// Like the rate limit middleware it counts in fixed windows and lets the
+// email through when the cache is unavailable.
+type EmailThrottle struct {
+	cache      cache.Cache
+	keyBuilder *cache.CacheKeyBuilder
+	limits     map[string][]config.EmailSendLimit
+	clock      clock.Clock
+}
+
+func NewEmailThrottle(c cache.Cache, kb *cache.CacheKeyBuilder, cfg config.EmailThrottleConfig, clk clock.Clock) *EmailThrottle {
+	return &EmailThrottle{
+		cache:      c,
+		keyBuilder: kb,
+		limits: map[string][]config.EmailSendLimit{
+			EmailKindVerification:  cfg.Verification,
+			EmailKindPasswordReset: cfg.PasswordReset,
+		},
+		clock: clk,
+	}
+}
+
+// Allow counts one email of kind to email, which must already be
+// normalized, and returns an *EmailThrottledError instead if that would go
+// over any of the kind's limits. A refused email isn't counted.
+func (t *EmailThrottle) Allow(ctx context.Context, kind, email string) error {
+	limits := t.limits[kind]
+	now := t.clock.Now()
+
+	var counted []string
+	var retryAfter time.Duration
+	for _, limit := range limits {
+		start := now.Truncate(limit.Window)
+		key := t.keyBuilder.EmailBudget(kind, email, limit.Window, start.Unix())
+
+		count, err := t.cache.Increment(ctx, key)
+		if err != nil {
+			log.Printf("Email budget check for %s failed, allowing email: %v", kind, err)
+			continue
+		}
+		counted = append(counted, key)
+		if count == 1 {
+			if err := t.cache.Expire(ctx, key, limit.Window); err != nil {
+				log.Printf("Failed to set expiry on email budget counter %s: %v", key, err)
+			}
+		}
+
+		if count > int64(limit.Max) {
+			if wait := start.Add(limit.Window).Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter == 0 {
+		return nil
+	}
+
+	for _, key := range counted {
+		if _, err := t.cache.Decrement(ctx, key); err != nil {
+			log.Printf("Failed to release email budget %s: %v", key, err)
+		}
+	}
+	return &EmailThrottledError{RetryAfter: retryAfter}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+func TestEmailThrottle(t *testing.T) {
+	hourly := []config.EmailSendLimit{{Max: 3, Window: time.Hour}}
+
+	// send is one email; wantRetry is how long the refusal asks to wait, 0
+	// when the email is allowed
+	type send struct {
+		advance   time.Duration
+		kind      string
+		email     string
+		wantRetry time.Duration
+	}
+	tests := []struct {
+		name  string
+		cfg   config.EmailThrottleConfig
+		sends []send
+	}{
+		{"within the budget", config.EmailThrottleConfig{Verification: hourly}, []send{
+			{}, {}, {},
+			{wantRetry: time.Hour},
+		}},
+		{"budget resets at the window boundary", config.EmailThrottleConfig{Verification: hourly}, []send{
+			{}, {}, {},
+			{advance: time.Hour - time.Second, wantRetry: time.Second},
+			{advance: time.Second},
+		}},
+		{"longer window outlasts the shorter one", config.EmailThrottleConfig{Verification: []config.EmailSendLimit{
+			{Max: 2, Window: time.Hour},
+			{Max: 3, Window: 24 * time.Hour},
+		}}, []send{
+			{}, {},
+			{advance: time.Hour},
+			{wantRetry: 11 * time.Hour},
+		}},
+		{"refused emails are not counted", config.EmailThrottleConfig{Verification: []config.EmailSendLimit{
+			{Max: 1, Window: time.Hour},
+			{Max: 2, Window: 24 * time.Hour},
+		}}, []send{
+			{},
+			{wantRetry: time.Hour},
+			{advance: time.Hour},
+			// Both limits are over, the longer wait wins
+			{wantRetry: 11 * time.Hour},
+		}},
+		{"kinds have separate budgets", config.EmailThrottleConfig{
+			Verification:  []config.EmailSendLimit{{Max: 1, Window: time.Hour}},
+			PasswordReset: []config.EmailSendLimit{{Max: 1, Window: time.Hour}},
+		}, []send{
+			{},
+			{kind: EmailKindPasswordReset},
+			{wantRetry: time.Hour},
+			{kind: EmailKindPasswordReset, wantRetry: time.Hour},
+		}},
+		{"recipients have separate budgets", config.EmailThrottleConfig{Verification: []config.EmailSendLimit{{Max: 1, Window: time.Hour}}}, []send{
+			{},
+			{email: "other@example.com"},
+			{wantRetry: time.Hour},
+		}},
+		{"kind without limits", config.EmailThrottleConfig{Verification: hourly}, []send{
+			{kind: EmailKindPasswordReset}, {kind: EmailKindPasswordReset}, {kind: EmailKindPasswordReset},
+			{kind: EmailKindPasswordReset}, {kind: EmailKindPasswordReset},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.NewMemoryCache()
+			t.Cleanup(func() { c.Close() })
+			clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+			throttle := NewEmailThrottle(c, cache.NewCacheKeyBuilder("test"), tt.cfg, clk)
+
+			for i, s := range tt.sends {
+				clk.Advance(s.advance)
+				kind, email := s.kind, s.email
+				if kind == "" {
+					kind = EmailKindVerification
+				}
+				if email == "" {
+					email = "owner@example.com"
+				}
+
+				err := throttle.Allow(context.Background(), kind, email)
+				if s.wantRetry == 0 {
+					if err != nil {
+						t.Fatalf("send %d: %v, want it allowed", i, err)
+					}
+					continue
+				}
+				var throttled *EmailThrottledError
+				if !errors.As(err, &throttled) || !errors.Is(err, ErrEmailThrottled) {
+					t.Fatalf("send %d: err = %v, want it throttled", i, err)
+				}
+				if throttled.RetryAfter != s.wantRetry {
+					t.Errorf("send %d: retry after %s, want %s", i, throttled.RetryAfter, s.wantRetry)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+var (
+	// ErrInvalidRefreshToken means the refresh token is unknown, expired or already rotated
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+	// ErrAccountDisabled means the credentials are valid but the account has
+	// been deactivated
+	ErrAccountDisabled = domain.ErrAccountDisabled
+
+	// ErrSessionExpired means the refresh token belonged to a session that was
+	// idle for too long or reached its absolute lifetime; the user has to log
+	// in again
+	ErrSessionExpired = errors.New("session expired")
+
+	// ErrRefreshInProgress means a concurrent request is rotating the same refresh
+	// token; the client should retry shortly
+	ErrRefreshInProgress = errors.New("refresh token rotation in progress")
+
+	// ErrRefreshTokenRotated means another request rotated the refresh token
+	// moments ago. The new token went to that request only; the client should
+	// continue with it rather than treat the session as lost
+	ErrRefreshTokenRotated = errors.New("refresh token already rotated")
+)
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+)
+
+const (
+	defaultJWKSCacheTTL = time.Hour
+	// jwksMinRefresh limits refetches triggered by unknown key IDs, so
+	// tokens with made-up kids can't make us hammer the issuer
+	jwksMinRefresh = 30 * time.Second
+	jwksTimeout    = 5 * time.Second
+	maxJWKSSize    = 1 << 20
+)
+
+// ErrUnknownKey is returned when the issuer has no key with the token's kid
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// jwksCache holds the signing keys an issuer publishes at its JWKS URL. Keys
+// are refetched when the cache expires, or early when a token names a key
+// the cache doesn't know, which is how issuers roll out rotated keys.
+type jwksCache struct {
+	url        string
+	ttl        time.Duration
+	httpClient *http.Client
+	clock      clock.Clock
+
+	// mu is held while fetching, so concurrent misses wait for one request
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func newJWKSCache(url string, ttl time.Duration, clk clock.Clock) *jwksCache {
+	if ttl <= 0 {
+		ttl = defaultJWKSCacheTTL
+	}
+	return &jwksCache{
+		url:        url,
+		ttl:        ttl,
+		httpClient: &http.Client{Timeout: jwksTimeout},
+		clock:      clk,
+	}
+}
+
+// Key returns the key with the given ID. An empty kid matches the only key
+// of a single-key set.
+func (c *jwksCache) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	stale := c.keys == nil || now.Sub(c.fetchedAt) >= c.ttl
+	if !stale {
+		if key, ok := c.lookup(kid); ok {
+			return key, nil
+		}
+		// A kid we haven't seen may be a freshly rotated key
+		stale = now.Sub(c.fetchedAt) >= jwksMinRefresh
+	}
+
+	if stale {
+		keys, err := c.fetch(ctx)
+		if err != nil {
+			if c.keys == nil {
+				return nil, err
+			}
+			// Keep using the keys we have rather than failing every login
+			log.Printf("Failed to refresh JWKS from %s, using cached keys: %v", c.url, err)
+		} else {
+			c.keys = keys
+		}
+		c.fetchedAt = now
+	}
+
+	if key, ok := c.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+func (c *jwksCache) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517). Only RSA and EC
+// signing keys are supported.
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set, as served at a JWKS URL.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (c *jwksCache) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS url: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set JWKSet
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			log.Printf("Skipping JWKS key %q from %s: %v", k.Kid, c.url, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k JWK) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// newJWK describes the public half of a signing key.
+func newJWK(kid, alg string, key crypto.PublicKey) (JWK, error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kid: kid,
+			Kty: "RSA",
+			Use: "sig",
+			Alg: alg,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+
+	case *ecdsa.PublicKey:
+		// Coordinates are padded to the curve size, as RFC 7518 requires
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kid: kid,
+			Kty: "EC",
+			Use: "sig",
+			Alg: alg,
+			Crv: key.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key parameter: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
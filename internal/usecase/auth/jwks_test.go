@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testSigningKeys returns an active RSA key and a retired EC key, PEM encoded
+// as they appear in jwt.signing_keys.
+func testSigningKeys(t *testing.T) []config.SigningKeyConfig {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []config.SigningKeyConfig{
+		{ID: "rsa-2026", PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))},
+		{ID: "ec-2025", PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))},
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	keys := testSigningKeys(t)
+
+	tests := []struct {
+		name        string
+		signingKeys []config.SigningKeyConfig
+		wantKids    []string
+		wantAlgs    []string
+	}{
+		{"shared secret", nil, nil, nil},
+		{"active and retired keys", keys, []string{"rsa-2026", "ec-2025"}, []string{"RS256", "ES256"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testJWTConfig
+			cfg.SigningKeys = tt.signingKeys
+			svc, err := NewJWTService(cfg, clock.NewMock(now))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			set, err := svc.JWKS()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(set.Keys) != len(tt.wantKids) {
+				t.Fatalf("JWKS() has %d keys, want %d", len(set.Keys), len(tt.wantKids))
+			}
+
+			// An empty set is still served as a list, never as null
+			raw, err := json.Marshal(set)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded map[string][]map[string]any
+			if err := json.Unmarshal(raw, &decoded); err != nil {
+				t.Fatalf("JWKS is not a key set: %s", raw)
+			}
+			if decoded["keys"] == nil {
+				t.Fatalf("keys = null, want a list: %s", raw)
+			}
+
+			for i, jwk := range set.Keys {
+				if jwk.Kid != tt.wantKids[i] || jwk.Alg != tt.wantAlgs[i] || jwk.Use != "sig" {
+					t.Errorf("key %d = kid %q alg %q use %q, want %q %q sig", i, jwk.Kid, jwk.Alg, jwk.Use, tt.wantKids[i], tt.wantAlgs[i])
+				}
+				for _, private := range []string{"d", "p", "q", "dp", "dq", "qi"} {
+					if _, ok := decoded["keys"][i][private]; ok {
+						t.Errorf("key %q publishes private parameter %q", jwk.Kid, private)
+					}
+				}
+				// The published key parses back to the signing key's public half
+				pub, err := jwk.publicKey()
+				if err != nil {
+					t.Fatalf("key %q: %v", jwk.Kid, err)
+				}
+				want := svc.signingKeys[jwk.Kid].public().(interface{ Equal(crypto.PublicKey) bool })
+				if !want.Equal(pub) {
+					t.Errorf("key %q does not match its signing key", jwk.Kid)
+				}
+			}
+		})
+	}
+}
+
+func TestJWKSCoversIssuedTokens(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cfg := testJWTConfig
+	cfg.SigningKeys = testSigningKeys(t)
+	svc, err := NewJWTService(cfg, clock.NewMock(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := svc.JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		issue func() (string, error)
+	}{
+		{"access token", func() (string, error) { return svc.GenerateAccessToken("user-1", "owner@example.com") }},
+		{"refresh token", func() (string, error) { return svc.GenerateRefreshToken("user-1") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.issue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			kid, _ := parsed.Header["kid"].(string)
+			if kid != "rsa-2026" {
+				t.Fatalf("kid = %q, want the active key rsa-2026", kid)
+			}
+
+			// Another service verifies the token with nothing but the JWKS
+			for _, jwk := range set.Keys {
+				if jwk.Kid != kid {
+					continue
+				}
+				pub, err := jwk.publicKey()
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = jwt.ParseWithClaims(token, &Claims{}, func(*jwt.Token) (any, error) { return pub, nil },
+					jwt.WithValidMethods([]string{jwk.Alg}), jwt.WithTimeFunc(func() time.Time { return now }))
+				if err != nil {
+					t.Fatalf("token does not verify against the JWKS: %v", err)
+				}
+				return
+			}
+			t.Fatalf("kid %q is not in the JWKS", kid)
+		})
+	}
+}
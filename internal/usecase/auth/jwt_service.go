@@ -3,10 +3,11 @@ package auth
 import (
 	"errors"
 	"fmt"
-	"time"
 
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
 	"github.com/Elysian-Rebirth/backend-go/internal/config"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type Claims struct {
@@ -15,52 +16,65 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// JWTService issues and validates this service's tokens, and validates
+// tokens from the external issuers listed in jwt.trusted_issuers.
 type JWTService struct {
-	cfg config.JWTConfig
+	cfg         config.JWTConfig
+	clock       clock.Clock
+	trusted     map[string]*trustedIssuer
+	signingKeys map[string]*signingKey
+	// activeKey signs new tokens; nil signs with the shared secret
+	activeKey *signingKey
 }
 
-func NewJWTService(cfg config.JWTConfig) *JWTService {
-	return &JWTService{
-		cfg: cfg,
+func NewJWTService(cfg config.JWTConfig, clk clock.Clock) (*JWTService, error) {
+	s := &JWTService{
+		cfg:   cfg,
+		clock: clk,
 	}
+	if err := s.loadSigningKeys(); err != nil {
+		return nil, err
+	}
+	if err := s.loadTrustedIssuers(); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 func (s *JWTService) GenerateAccessToken(userID, email string) (string, error) {
+	now := s.clock.Now()
 	claims := &Claims{
 		UserID: userID,
 		Email:  email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.cfg.AccessTokenExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.cfg.AccessTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    s.cfg.Issuer,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.cfg.Secret))
+	return s.sign(claims)
 }
 
 func (s *JWTService) GenerateRefreshToken(userID string) (string, error) {
+	now := s.clock.Now()
 	claims := &Claims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.cfg.RefreshTokenExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			// A random ID keeps two tokens issued to the same user in the
+			// same second from being identical
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.cfg.RefreshTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    s.cfg.Issuer,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.cfg.Secret))
+	return s.sign(claims)
 }
 
 func (s *JWTService) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.cfg.Secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.verificationKey, jwt.WithTimeFunc(s.clock.Now))
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
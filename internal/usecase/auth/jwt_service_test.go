@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	issuedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		issue   func(s *JWTService) (string, error)
+		after   time.Duration
+		wantErr bool
+	}{
+		{"fresh access token", func(s *JWTService) (string, error) { return s.GenerateAccessToken("user-1", testEmail) }, 0, false},
+		{"access token just before expiry", func(s *JWTService) (string, error) { return s.GenerateAccessToken("user-1", testEmail) }, 15*time.Minute - time.Second, false},
+		{"access token at expiry", func(s *JWTService) (string, error) { return s.GenerateAccessToken("user-1", testEmail) }, 15 * time.Minute, true},
+		{"refresh token after a day", func(s *JWTService) (string, error) { return s.GenerateRefreshToken("user-1") }, 24 * time.Hour, false},
+		{"refresh token just before expiry", func(s *JWTService) (string, error) { return s.GenerateRefreshToken("user-1") }, 7*24*time.Hour - time.Second, false},
+		{"refresh token at expiry", func(s *JWTService) (string, error) { return s.GenerateRefreshToken("user-1") }, 7 * 24 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewMock(issuedAt)
+			svc, err := NewJWTService(testJWTConfig, clk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := tt.issue(svc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			clk.Advance(tt.after)
+			claims, err := svc.ValidateToken(token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ValidateToken() accepted a token %s after issue", tt.after)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateToken() = %v", err)
+			}
+			if !claims.IssuedAt.Time.Equal(issuedAt) || claims.UserID != "user-1" {
+				t.Errorf("claims = iat %s user %q, want %s user-1", claims.IssuedAt.Time, claims.UserID, issuedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+// Display name length limits, in characters
+const (
+	MinNameLength = 2
+	MaxNameLength = 100
+)
+
+// NameSanitizer cleans up user display names before they are stored. It is
+// used on registration and on profile updates, so both follow the same rules.
+type NameSanitizer struct {
+	blockedWords map[string]bool
+}
+
+func NewNameSanitizer(cfg config.NameConfig) *NameSanitizer {
+	blocked := make(map[string]bool, len(cfg.BlockedWords))
+	for _, word := range cfg.BlockedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			blocked[word] = true
+		}
+	}
+	return &NameSanitizer{blockedWords: blocked}
+}
+
+// Sanitize trims name and collapses runs of spaces inside it, then checks
+// the result. Control and invisible formatting characters, names outside
+// the length limits and names containing a blocked word are rejected with a
+// domain.ValidationError.
+func (s *NameSanitizer) Sanitize(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	for _, r := range name {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return "", domain.NewValidationError("Name contains invalid characters")
+		}
+	}
+	name = strings.Join(strings.Fields(name), " ")
+
+	if n := utf8.RuneCountInString(name); n < MinNameLength || n > MaxNameLength {
+		return "", domain.NewValidationError(fmt.Sprintf("Name must be between %d and %d characters", MinNameLength, MaxNameLength))
+	}
+
+	if len(s.blockedWords) > 0 {
+		words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		for _, word := range words {
+			if s.blockedWords[word] {
+				return "", domain.NewValidationError("Name contains a word that is not allowed")
+			}
+		}
+	}
+
+	return name, nil
+}
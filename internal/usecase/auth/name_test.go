@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+func TestSanitizeName(t *testing.T) {
+	names := NewNameSanitizer(config.NameConfig{BlockedWords: []string{" Bodoh ", "scam"}})
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"unchanged", "Toko Budi", "Toko Budi", false},
+		{"trimmed", "  Toko Budi \t", "Toko Budi", false},
+		{"inner spaces collapsed", "Toko   Budi  Jaya", "Toko Budi Jaya", false},
+		{"non-ASCII letters", "Warung Bu Siti – Café", "Warung Bu Siti – Café", false},
+		{"shortest", "Jo", "Jo", false},
+		{"longest", strings.Repeat("a", MaxNameLength), strings.Repeat("a", MaxNameLength), false},
+		{"length counted in characters", strings.Repeat("é", MaxNameLength), strings.Repeat("é", MaxNameLength), false},
+		{"too short", "J", "", true},
+		{"too short after trimming", "  J  ", "", true},
+		{"empty", "", "", true},
+		{"too long", strings.Repeat("a", MaxNameLength+1), "", true},
+		{"control character", "Toko\x00Budi", "", true},
+		{"newline inside", "Toko\nBudi", "", true},
+		{"invisible formatting character", "Toko\u200bBudi", "", true},
+		{"blocked word", "Toko Bodoh", "", true},
+		{"blocked word in another case", "Toko SCAM Jaya", "", true},
+		{"blocked word between punctuation", "Toko-bodoh!", "", true},
+		{"blocked word inside another word", "Scampi House", "Scampi House", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := names.Sanitize(tt.input)
+			if tt.wantErr {
+				var validationErr *domain.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Sanitize(%q) = %q, %v, want a validation error", tt.input, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Sanitize(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
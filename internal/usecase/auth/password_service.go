@@ -3,6 +3,8 @@ package auth
 import (
 	"errors"
 	"fmt"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -29,7 +31,7 @@ func (s *PasswordService) ComparePassword(hashedPassword, password string) error
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	if err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			return errors.New("invalid password")
+			return domain.ErrInvalidCredentials
 		}
 		return fmt.Errorf("password verification failed: %w", err)
 	}
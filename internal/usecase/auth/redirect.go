@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+var ErrRedirectNotAllowed = errors.New("redirect target is not allowed")
+
+// RedirectValidator checks client-supplied redirect targets against the
+// configured allowlist before they are embedded in emails or responses.
+// Every flow that builds a client-facing link should go through it.
+type RedirectValidator struct {
+	origins    map[string]bool
+	wildcards  []string
+	defaultURL string
+}
+
+func NewRedirectValidator(cfg config.SecurityConfig) *RedirectValidator {
+	v := &RedirectValidator{
+		origins:    make(map[string]bool),
+		defaultURL: cfg.DefaultRedirectURL,
+	}
+
+	for _, entry := range cfg.RedirectAllowedOrigins {
+		entry = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(entry), "/"))
+		if strings.HasPrefix(entry, "*.") {
+			v.wildcards = append(v.wildcards, entry[1:])
+			continue
+		}
+		v.origins[entry] = true
+	}
+
+	return v
+}
+
+// Validate returns the normalized target if its origin is allowed. Relative
+// paths are resolved against the default redirect URL; an empty target yields
+// the default.
+func (v *RedirectValidator) Validate(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		if v.defaultURL == "" {
+			return "", ErrRedirectNotAllowed
+		}
+		return v.defaultURL, nil
+	}
+
+	// browsers treat backslashes like slashes, so "/\evil.com" is protocol-relative
+	if strings.ContainsAny(target, "\\\r\n\t") {
+		return "", ErrRedirectNotAllowed
+	}
+
+	if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") {
+		if v.defaultURL == "" {
+			return "", ErrRedirectNotAllowed
+		}
+		base, err := url.Parse(v.defaultURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid default redirect url: %w", err)
+		}
+		ref, err := url.Parse(target)
+		if err != nil {
+			return "", ErrRedirectNotAllowed
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" || u.User != nil {
+		return "", ErrRedirectNotAllowed
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", ErrRedirectNotAllowed
+	}
+
+	if !v.allowed(u) {
+		return "", ErrRedirectNotAllowed
+	}
+
+	return u.String(), nil
+}
+
+// Resolve is like Validate but falls back to the default redirect URL when
+// the target is rejected.
+func (v *RedirectValidator) Resolve(target string) string {
+	resolved, err := v.Validate(target)
+	if err != nil {
+		return v.defaultURL
+	}
+	return resolved
+}
+
+func (v *RedirectValidator) allowed(u *url.URL) bool {
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+	if v.origins[origin] {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if v.origins[host] {
+		return true
+	}
+	for _, suffix := range v.wildcards {
+		if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+			return u.Scheme == "https"
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+)
+
+func TestRedirectValidator(t *testing.T) {
+	const home = "https://app.umkm.id"
+	cfg := config.SecurityConfig{
+		DefaultRedirectURL:     home,
+		RedirectAllowedOrigins: []string{"https://app.umkm.id/", " partner.example.com", "*.Toko.id"},
+	}
+
+	tests := []struct {
+		name   string
+		cfg    config.SecurityConfig
+		target string
+		// want is empty when the target must be rejected
+		want string
+	}{
+		{"allowed origin", cfg, "https://app.umkm.id/dashboard?tab=1", "https://app.umkm.id/dashboard?tab=1"},
+		{"allowed host on any scheme", cfg, "http://partner.example.com/callback", "http://partner.example.com/callback"},
+		{"allowed subdomain", cfg, "https://budi.toko.id/orders", "https://budi.toko.id/orders"},
+		{"relative path", cfg, "/account/reset-password?step=2", "https://app.umkm.id/account/reset-password?step=2"},
+		{"empty target", cfg, "  ", home},
+
+		{"other host", cfg, "https://evil.com/", ""},
+		{"allowed host as a prefix", cfg, "https://app.umkm.id.evil.com/", ""},
+		{"allowed host as userinfo", cfg, "https://app.umkm.id@evil.com/", ""},
+		{"look-alike of a wildcard domain", cfg, "https://evil-toko.id/", ""},
+		{"wildcard apex", cfg, "https://toko.id/", ""},
+		{"wildcard over plain http", cfg, "http://budi.toko.id/", ""},
+		{"scheme downgrade", cfg, "http://app.umkm.id/", ""},
+		{"protocol relative", cfg, "//evil.com/path", ""},
+		{"backslash", cfg, "/\\evil.com", ""},
+		{"header injection", cfg, "https://app.umkm.id/\r\nSet-Cookie: a=b", ""},
+		{"script", cfg, "javascript:alert(1)", ""},
+		{"data", cfg, "data:text/html,<script>alert(1)</script>", ""},
+
+		{"empty without a default", config.SecurityConfig{}, "", ""},
+		{"relative without a default", config.SecurityConfig{}, "/account", ""},
+		{"nothing allowed", config.SecurityConfig{DefaultRedirectURL: home}, "https://app.umkm.id/x", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewRedirectValidator(tt.cfg)
+
+			got, err := v.Validate(tt.target)
+			if tt.want == "" {
+				if !errors.Is(err, ErrRedirectNotAllowed) {
+					t.Fatalf("Validate(%q) = %q, %v, want it rejected", tt.target, got, err)
+				}
+				// Resolve falls back to the default instead
+				if resolved := v.Resolve(tt.target); resolved != tt.cfg.DefaultRedirectURL {
+					t.Errorf("Resolve(%q) = %q, want the default %q", tt.target, resolved, tt.cfg.DefaultRedirectURL)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Validate(%q) = %q, %v, want %q", tt.target, got, err, tt.want)
+			}
+			if resolved := v.Resolve(tt.target); resolved != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.target, resolved, tt.want)
+			}
+		})
+	}
+}
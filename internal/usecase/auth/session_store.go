@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+// SessionStore keeps refresh tokens in the cache together with a per-user
+// index of the tokens issued to them, so every session of a user can be found
+// and revoked at once.
+//
+// A session starts at login and is carried from token to token as they are
+// rotated. It ends once it goes idle for longer than the idle expiry or
+// reaches its absolute expiry, whichever comes first.
+type SessionStore struct {
+	cache       cache.Cache
+	keyBuilder  *cache.CacheKeyBuilder
+	clock       clock.Clock
+	idleTTL     time.Duration
+	absoluteTTL time.Duration
+	accessTTL   time.Duration
+}
+
+// Session is the metadata stored with a refresh token.
+type Session struct {
+	UserID string `json:"user_id"`
+	// StartedAt is when the user logged in; rotation keeps it
+	StartedAt  time.Time `json:"started_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// ExpiresAt is when the current token runs out unless it is refreshed
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewSessionStore(c cache.Cache, kb *cache.CacheKeyBuilder, cfg config.JWTConfig, clk clock.Clock) *SessionStore {
+	idleTTL := cfg.RefreshIdleExpiry
+	if idleTTL <= 0 {
+		idleTTL = cfg.RefreshTokenExpiry
+	}
+	return &SessionStore{
+		cache:       c,
+		keyBuilder:  kb,
+		clock:       clk,
+		idleTTL:     idleTTL,
+		absoluteTTL: cfg.RefreshAbsoluteExpiry,
+		accessTTL:   cfg.AccessTokenExpiry,
+	}
+}
+
+// Add starts a session for the user with a refresh token and records the
+// token in the user's index.
+func (s *SessionStore) Add(ctx context.Context, userID, refreshToken string) (*Session, error) {
+	now := s.clock.Now()
+	session := &Session{UserID: userID, StartedAt: now, LastUsedAt: now}
+	if err := s.save(ctx, session, refreshToken); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Renew continues session, which Consume returned for the previous token,
+// with a new refresh token. It returns ErrSessionExpired if the session
+// ran out in the meantime.
+func (s *SessionStore) Renew(ctx context.Context, session *Session, refreshToken string) (*Session, error) {
+	if err := s.Check(session); err != nil {
+		return nil, err
+	}
+	renewed := *session
+	renewed.LastUsedAt = s.clock.Now()
+	if err := s.save(ctx, &renewed, refreshToken); err != nil {
+		return nil, err
+	}
+	return &renewed, nil
+}
+
+// Get returns the session of a refresh token without using the token up,
+// or cache.ErrKeyNotFound.
+func (s *SessionStore) Get(ctx context.Context, refreshToken string) (*Session, error) {
+	raw, err := s.cache.Get(ctx, s.keyBuilder.For(ctx).RefreshToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(raw)
+}
+
+// Consume takes a refresh token out of the store and returns its session,
+// or cache.ErrKeyNotFound if the token is unknown, expired or already used.
+// The caller removes it from the user's index.
+func (s *SessionStore) Consume(ctx context.Context, refreshToken string) (*Session, error) {
+	raw, err := s.cache.GetDel(ctx, s.keyBuilder.For(ctx).RefreshToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(raw)
+}
+
+func (s *SessionStore) decode(raw string) (*Session, error) {
+	// Tokens stored before sessions had metadata hold just the user ID;
+	// they are treated as sessions that start now
+	if !strings.HasPrefix(raw, "{") {
+		now := s.clock.Now()
+		return &Session{UserID: raw, StartedAt: now, LastUsedAt: now, ExpiresAt: now.Add(s.idleTTL)}, nil
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(raw), &session); err != nil {
+		return nil, fmt.Errorf("invalid session data: %w", err)
+	}
+	return &session, nil
+}
+
+// Check returns ErrSessionExpired if the session was idle for too long or
+// reached its absolute lifetime.
+func (s *SessionStore) Check(session *Session) error {
+	if s.ttl(session) <= 0 {
+		return ErrSessionExpired
+	}
+	return nil
+}
+
+// ttl is how long a token issued for session now may live: the idle
+// window, cut short by the session's absolute expiry.
+func (s *SessionStore) ttl(session *Session) time.Duration {
+	now := s.clock.Now()
+	ttl := session.LastUsedAt.Add(s.idleTTL).Sub(now)
+	if s.absoluteTTL > 0 {
+		if remaining := session.StartedAt.Add(s.absoluteTTL).Sub(now); remaining < ttl {
+			ttl = remaining
+		}
+	}
+	return ttl
+}
+
+func (s *SessionStore) save(ctx context.Context, session *Session, refreshToken string) error {
+	ttl := s.ttl(session)
+	if ttl <= 0 {
+		return ErrSessionExpired
+	}
+	session.ExpiresAt = s.clock.Now().Add(ttl)
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	if err := s.cache.Set(ctx, s.keyBuilder.For(ctx).RefreshToken(refreshToken), data, ttl); err != nil {
+		return err
+	}
+
+	indexKey := s.keyBuilder.For(ctx).UserSessions(session.UserID)
+	if err := s.cache.SAdd(ctx, indexKey, refreshToken); err != nil {
+		return err
+	}
+	// No token outlives the idle window, so neither does the index
+	if err := s.cache.Expire(ctx, indexKey, s.idleTTL); err != nil {
+		return err
+	}
+
+	// Logging in regularly keeps the index alive, so drop the tokens that
+	// expired in the meantime
+	if _, err := s.Prune(ctx, session.UserID); err != nil {
+		log.Printf("Failed to prune sessions of user %s: %v", session.UserID, err)
+	}
+	return nil
+}
+
+// Prune removes tokens that expired or were consumed from the user's index
+// and returns how many were removed.
+func (s *SessionStore) Prune(ctx context.Context, userID string) (int, error) {
+	indexKey := s.keyBuilder.For(ctx).UserSessions(userID)
+	tokens, err := s.cache.SMembers(ctx, indexKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, len(tokens))
+	for i, token := range tokens {
+		keys[i] = s.keyBuilder.For(ctx).RefreshToken(token)
+	}
+	exists, err := s.cache.ExistsMap(ctx, keys...)
+	if err != nil {
+		return 0, err
+	}
+
+	var stale []any
+	for i, token := range tokens {
+		if !exists[keys[i]] {
+			stale = append(stale, token)
+		}
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+	if err := s.cache.SRem(ctx, indexKey, stale...); err != nil {
+		return 0, err
+	}
+	return len(stale), nil
+}
+
+// Remove drops a refresh token from the user's index. The token key itself is
+// expected to be consumed by the caller.
+func (s *SessionStore) Remove(ctx context.Context, userID, refreshToken string) error {
+	return s.cache.SRem(ctx, s.keyBuilder.For(ctx).UserSessions(userID), refreshToken)
+}
+
+// RevokeAll deletes every refresh token issued to the user and returns how
+// many were still valid.
+func (s *SessionStore) RevokeAll(ctx context.Context, userID string) (int, error) {
+	indexKey := s.keyBuilder.For(ctx).UserSessions(userID)
+	tokens, err := s.cache.SMembers(ctx, indexKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		keys = append(keys, s.keyBuilder.For(ctx).RefreshToken(token))
+	}
+
+	// Tokens that already expired or were rotated are still listed in the
+	// index, so only the ones that exist count as revoked.
+	active, err := s.cache.Exists(ctx, keys...)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.cache.Delete(ctx, append(keys, indexKey)...); err != nil {
+		return 0, err
+	}
+
+	return int(active), nil
+}
+
+// RevokeAccessTokens invalidates every access token issued to the user up to
+// now. The marker only has to outlive the longest-lived access token.
+func (s *SessionStore) RevokeAccessTokens(ctx context.Context, userID string) error {
+	now := s.clock.Now().Unix()
+	return s.cache.Set(ctx, s.keyBuilder.For(ctx).AccessTokensRevokedAt(userID), now, s.accessTTL)
+}
+
+// IsAccessTokenRevoked reports whether the token was issued before the user's
+// access tokens were last revoked.
+func (s *SessionStore) IsAccessTokenRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	raw, err := s.cache.Get(ctx, s.keyBuilder.For(ctx).AccessTokensRevokedAt(claims.UserID))
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	revokedAt, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid revocation marker: %w", err)
+	}
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+
+	return claims.IssuedAt.Unix() <= revokedAt, nil
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+func newTestSessions(t *testing.T, cfg config.JWTConfig) (*SessionStore, *cache.MemoryCache, *clock.Mock) {
+	t.Helper()
+	clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+	return NewSessionStore(c, cache.NewCacheKeyBuilder("test"), cfg, clk), c, clk
+}
+
+func TestPruneSessions(t *testing.T) {
+	ctx := context.Background()
+	sessions, c, _ := newTestSessions(t, testJWTConfig)
+
+	tokens := []string{"token-1", "token-2", "token-3"}
+	for _, token := range tokens {
+		if _, err := sessions.Add(ctx, "user-1", token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Consumed without being taken out of the index, as a crash between
+	// the two steps would leave it
+	if _, err := sessions.Consume(ctx, "token-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	indexKey := cache.NewCacheKeyBuilder("test").UserSessions("user-1")
+	tests := []struct {
+		name       string
+		wantPruned int
+		wantIndex  []string
+	}{
+		{"drops the consumed token", 1, []string{"token-1", "token-3"}},
+		{"nothing left to drop", 0, []string{"token-1", "token-3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pruned, err := sessions.Prune(ctx, "user-1")
+			if err != nil || pruned != tt.wantPruned {
+				t.Fatalf("Prune() = %d, %v, want %d", pruned, err, tt.wantPruned)
+			}
+			index, err := c.SMembers(ctx, indexKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(index)
+			if !slices.Equal(index, tt.wantIndex) {
+				t.Errorf("index = %v, want %v", index, tt.wantIndex)
+			}
+		})
+	}
+
+	if pruned, err := sessions.Prune(ctx, "user-without-sessions"); err != nil || pruned != 0 {
+		t.Errorf("Prune() without sessions = %d, %v, want 0", pruned, err)
+	}
+}
+
+func TestSessionsScopedByTenant(t *testing.T) {
+	sessions, _, _ := newTestSessions(t, testJWTConfig)
+	ctxA := reqctx.WithTenantID(context.Background(), "warung")
+	ctxB := reqctx.WithTenantID(context.Background(), "toko")
+
+	// The same user ID and token in two tenants are separate sessions
+	if _, err := sessions.Add(ctxA, "user-1", "token"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{"other tenant", ctxB, cache.ErrKeyNotFound},
+		{"no tenant", context.Background(), cache.ErrKeyNotFound},
+		{"same tenant", ctxA, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sessions.Get(tt.ctx, "token"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if revoked, err := sessions.RevokeAll(ctxB, "user-1"); err != nil || revoked != 0 {
+		t.Errorf("RevokeAll() in another tenant = %d, %v, want 0", revoked, err)
+	}
+	if _, err := sessions.Consume(ctxA, "token"); err != nil {
+		t.Errorf("Consume() after another tenant's RevokeAll = %v", err)
+	}
+}
+
+// every returns n steps of d.
+func every(d time.Duration, n int) []time.Duration {
+	steps := make([]time.Duration, n)
+	for i := range steps {
+		steps[i] = d
+	}
+	return steps
+}
+
+func TestSessionExpiry(t *testing.T) {
+	cfg := config.JWTConfig{RefreshTokenExpiry: 7 * 24 * time.Hour, RefreshIdleExpiry: time.Hour, RefreshAbsoluteExpiry: 24 * time.Hour}
+	noAbsolute := cfg
+	noAbsolute.RefreshAbsoluteExpiry = 0
+	noIdle := cfg
+	noIdle.RefreshTokenExpiry = 2 * time.Hour
+	noIdle.RefreshIdleExpiry = 0
+
+	tests := []struct {
+		name string
+		cfg  config.JWTConfig
+		// renewAfter waits each duration in turn and renews the session
+		renewAfter []time.Duration
+		wantErr    error
+		// wantExpiresAt is measured from login
+		wantExpiresAt time.Duration
+	}{
+		{"new session", cfg, nil, nil, time.Hour},
+		{"renewed within the idle window", cfg, []time.Duration{59 * time.Minute}, nil, 119 * time.Minute},
+		{"idle for the whole window", cfg, []time.Duration{time.Hour}, ErrSessionExpired, 0},
+		{"idle after some renewals", cfg, []time.Duration{30 * time.Minute, 30 * time.Minute, 2 * time.Hour}, ErrSessionExpired, 0},
+		{"renewed regularly", cfg, every(50*time.Minute, 6), nil, 6 * time.Hour},
+		{"absolute limit cuts the last token short", cfg, every(30*time.Minute, 47), nil, 24 * time.Hour},
+		{"absolute limit reached", cfg, every(30*time.Minute, 48), ErrSessionExpired, 0},
+		{"no absolute limit", noAbsolute, every(30*time.Minute, 60), nil, 31 * time.Hour},
+		{"idle window defaults to the refresh token expiry", noIdle, []time.Duration{119 * time.Minute}, nil, 239 * time.Minute},
+		{"idle past the refresh token expiry", noIdle, []time.Duration{2 * time.Hour}, ErrSessionExpired, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sessions, _, clk := newTestSessions(t, tt.cfg)
+			login := clk.Now()
+
+			session, err := sessions.Add(ctx, "user-1", "token-0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, wait := range tt.renewAfter {
+				clk.Advance(wait)
+				session, err = sessions.Renew(ctx, session, fmt.Sprintf("token-%d", i+1))
+				if err != nil {
+					break
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := session.ExpiresAt.Sub(login); got != tt.wantExpiresAt {
+				t.Errorf("expires %s after login, want %s", got, tt.wantExpiresAt)
+			}
+			if !session.StartedAt.Equal(login) {
+				t.Errorf("StartedAt = %s, want the login time %s", session.StartedAt, login)
+			}
+			if err := sessions.Check(session); err != nil {
+				t.Errorf("Check() = %v on a live session", err)
+			}
+		})
+	}
+}
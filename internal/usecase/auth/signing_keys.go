@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is one of jwt.signing_keys. Tokens signed with it carry its ID
+// as kid, which is how validation and other services find the public key.
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+func (k *signingKey) public() crypto.PublicKey {
+	return k.private.Public()
+}
+
+func (s *JWTService) loadSigningKeys() error {
+	s.signingKeys = make(map[string]*signingKey, len(s.cfg.SigningKeys))
+	for i, cfg := range s.cfg.SigningKeys {
+		if _, ok := s.signingKeys[cfg.ID]; ok {
+			return fmt.Errorf("duplicate signing key id %q", cfg.ID)
+		}
+
+		pemData := []byte(cfg.PrivateKey)
+		if cfg.PrivateKeyFile != "" {
+			data, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return fmt.Errorf("failed to read signing key %q: %w", cfg.ID, err)
+			}
+			pemData = data
+		}
+
+		key, err := parseSigningKey(cfg.ID, pemData)
+		if err != nil {
+			return err
+		}
+		s.signingKeys[cfg.ID] = key
+		if i == 0 {
+			s.activeKey = key
+		}
+	}
+	return nil
+}
+
+func parseSigningKey(id string, pemData []byte) (*signingKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemData); err == nil {
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("signing key %q: RSA keys must be at least 2048 bits", id)
+		}
+		return &signingKey{id: id, method: jwt.SigningMethodRS256, private: key}, nil
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %q is not a PEM encoded RSA or EC private key", id)
+	}
+	var method jwt.SigningMethod
+	switch key.Curve.Params().Name {
+	case "P-256":
+		method = jwt.SigningMethodES256
+	case "P-384":
+		method = jwt.SigningMethodES384
+	case "P-521":
+		method = jwt.SigningMethodES512
+	default:
+		return nil, fmt.Errorf("signing key %q uses unsupported curve %s", id, key.Curve.Params().Name)
+	}
+	return &signingKey{id: id, method: method, private: key}, nil
+}
+
+// sign signs claims with the active signing key, or with the shared secret
+// when no signing keys are configured.
+func (s *JWTService) sign(claims jwt.Claims) (string, error) {
+	if s.activeKey == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.cfg.Secret))
+	}
+
+	token := jwt.NewWithClaims(s.activeKey.method, claims)
+	token.Header["kid"] = s.activeKey.id
+	return token.SignedString(s.activeKey.private)
+}
+
+// verificationKey picks the key a local token is checked with. Tokens signed
+// with the shared secret stay valid alongside signing keys, so switching to
+// asymmetric signing doesn't log everyone out.
+func (s *JWTService) verificationKey(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return []byte(s.cfg.Secret), nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.signingKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+		}
+		if key.method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+		}
+		return key.public(), nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+}
+
+// JWKS returns the public halves of the signing keys, active and retired, so
+// other services can verify our tokens. It is empty when tokens are signed
+// with the shared secret.
+func (s *JWTService) JWKS() (JWKSet, error) {
+	set := JWKSet{Keys: make([]JWK, 0, len(s.cfg.SigningKeys))}
+	for _, cfg := range s.cfg.SigningKeys {
+		key := s.signingKeys[cfg.ID]
+		jwk, err := newJWK(key.id, key.method.Alg(), key.public())
+		if err != nil {
+			return JWKSet{}, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// externalMethods are the algorithms accepted from trusted issuers. HMAC is
+// left out: a shared secret can't prove who issued a token.
+var externalMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// ErrUntrustedIssuer is returned for tokens whose issuer is not configured
+// in jwt.trusted_issuers
+var ErrUntrustedIssuer = errors.New("token issuer is not trusted")
+
+// ErrEmailNotVerified is returned for tokens whose email_verified claim is
+// missing or false
+var ErrEmailNotVerified = errors.New("token email is not verified")
+
+// ExternalClaims are the claims read from a trusted issuer's token. The user
+// is matched to a local account by email, so the issuer has to vouch for
+// the address with email_verified.
+type ExternalClaims struct {
+	Email         string    `json:"email"`
+	EmailVerified claimBool `json:"email_verified"`
+	jwt.RegisteredClaims
+}
+
+// claimBool is a boolean claim. Some providers, Cognito among them, send
+// booleans as the strings "true" and "false".
+type claimBool bool
+
+func (b *claimBool) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*b = claimBool(v)
+	case string:
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid boolean claim %q", v)
+		}
+		*b = claimBool(parsed)
+	case nil:
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean claim %s", data)
+	}
+	return nil
+}
+
+type trustedIssuer struct {
+	cfg       config.TrustedIssuerConfig
+	publicKey crypto.PublicKey
+	jwks      *jwksCache
+}
+
+func (s *JWTService) loadTrustedIssuers() error {
+	s.trusted = make(map[string]*trustedIssuer, len(s.cfg.TrustedIssuers))
+	for _, cfg := range s.cfg.TrustedIssuers {
+		if cfg.Issuer == s.cfg.Issuer {
+			return fmt.Errorf("trusted issuer %q is the local issuer", cfg.Issuer)
+		}
+
+		issuer := &trustedIssuer{cfg: cfg}
+		if cfg.PublicKey != "" {
+			key, err := parsePublicKey([]byte(cfg.PublicKey))
+			if err != nil {
+				return fmt.Errorf("invalid public key for trusted issuer %q: %w", cfg.Issuer, err)
+			}
+			issuer.publicKey = key
+		} else {
+			issuer.jwks = newJWKSCache(cfg.JWKSURL, cfg.JWKSCacheTTL, s.clock)
+		}
+		s.trusted[cfg.Issuer] = issuer
+	}
+	return nil
+}
+
+// IsLocalToken reports whether token claims to be issued by this service.
+// The claim is not verified; it only decides how the token is validated.
+func (s *JWTService) IsLocalToken(tokenString string) bool {
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, &claims); err != nil {
+		return true
+	}
+	return claims.Issuer == "" || claims.Issuer == s.cfg.Issuer
+}
+
+// ValidateExternalToken validates a token from one of the trusted issuers,
+// selecting the verification key by the token's issuer and kid.
+func (s *JWTService) ValidateExternalToken(ctx context.Context, tokenString string) (*ExternalClaims, error) {
+	var unverified jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, &unverified); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	issuer, ok := s.trusted[unverified.Issuer]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUntrustedIssuer, unverified.Issuer)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(externalMethods),
+		jwt.WithIssuer(issuer.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.clock.Now),
+	}
+	if issuer.cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(issuer.cfg.Audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &ExternalClaims{}, func(token *jwt.Token) (any, error) {
+		if issuer.publicKey != nil {
+			return issuer.publicKey, nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		return issuer.jwks.Key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*ExternalClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	if claims.Email == "" {
+		return nil, errors.New("token has no email claim")
+	}
+	// Otherwise anyone able to sign up at the issuer with someone else's
+	// address would be let in as that user
+	if !claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	return claims, nil
+}
+
+func parsePublicKey(pemData []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pemData); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseECPublicKeyFromPEM(pemData)
+	if err != nil {
+		return nil, errors.New("expected a PEM encoded RSA or EC public key")
+	}
+	return key, nil
+}
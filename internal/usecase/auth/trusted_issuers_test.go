@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestValidateExternalTokenRequiresVerifiedEmail(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testJWTConfig
+	cfg.TrustedIssuers = []config.TrustedIssuerConfig{{
+		Issuer:    "https://idp.example.com",
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	svc, err := NewJWTService(cfg, clock.NewMock(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		verified any
+		wantErr  error
+	}{
+		{"verified", true, nil},
+		{"verified as string", "true", nil},
+		{"unverified", false, ErrEmailNotVerified},
+		{"unverified as string", "false", ErrEmailNotVerified},
+		{"claim missing", nil, ErrEmailNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{
+				"iss":   "https://idp.example.com",
+				"sub":   "idp-user-1",
+				"email": "owner@example.com",
+				"exp":   now.Add(time.Hour).Unix(),
+			}
+			if tt.verified != nil {
+				claims["email_verified"] = tt.verified
+			}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := svc.ValidateExternalToken(context.Background(), token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ValidateExternalToken() = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateExternalToken() = %v", err)
+			}
+			if got.Email != "owner@example.com" {
+				t.Errorf("email = %q", got.Email)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+// Verification purposes. A token issued for one purpose can't be consumed
+// for another.
+const (
+	VerificationEmailChange   = "email_change"
+	VerificationPasswordReset = "password_reset"
+)
+
+const (
+	// verificationClaimTTL bounds how long ConsumeOnce holds a token while
+	// the change it authorizes is applied
+	verificationClaimTTL = time.Minute
+
+	// Values of the used marker of a token
+	verificationClaimed = "claimed"
+	verificationUsed    = "used"
+)
+
+var (
+	// ErrInvalidVerificationToken means the token is unknown, expired, already
+	// used or superseded by a newer one
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+	// ErrVerificationTokenUsed means ConsumeOnce already redeemed the token,
+	// or is redeeming it for a concurrent request
+	ErrVerificationTokenUsed = errors.New("verification token already used")
+)
+
+// VerificationStore issues single-use tokens for links sent by email. Only a
+// hash of the token is stored, and each subject (usually a user ID) has at
+// most one outstanding token per purpose.
+type VerificationStore struct {
+	cache      cache.Cache
+	keyBuilder *cache.CacheKeyBuilder
+}
+
+type verificationEntry struct {
+	Subject string          `json:"subject"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func NewVerificationStore(c cache.Cache, kb *cache.CacheKeyBuilder) *VerificationStore {
+	return &VerificationStore{
+		cache:      c,
+		keyBuilder: kb,
+	}
+}
+
+// Issue stores payload under a new token valid for ttl and returns the token.
+// Any token previously issued to subject for the same purpose stops working.
+func (s *VerificationStore) Issue(ctx context.Context, purpose, subject string, payload any, ttl time.Duration) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode verification payload: %w", err)
+	}
+	entry, err := json.Marshal(verificationEntry{Subject: subject, Payload: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode verification payload: %w", err)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	hash := hashToken(token)
+
+	pendingKey := s.keyBuilder.PendingVerification(purpose, subject)
+	previous, err := s.cache.GetDel(ctx, pendingKey)
+	if err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+		return "", err
+	}
+	if previous != "" {
+		if err := s.cache.Delete(ctx, s.keyBuilder.VerificationToken(purpose, previous)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.cache.Set(ctx, s.keyBuilder.VerificationToken(purpose, hash), entry, ttl); err != nil {
+		return "", err
+	}
+	if err := s.cache.Set(ctx, pendingKey, hash, ttl); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Consume redeems token and decodes its payload into dest. A token can only
+// be consumed once.
+func (s *VerificationStore) Consume(ctx context.Context, purpose, token string, dest any) error {
+	if token == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	raw, err := s.cache.GetDel(ctx, s.keyBuilder.VerificationToken(purpose, hashToken(token)))
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return ErrInvalidVerificationToken
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.redeem(ctx, purpose, raw, dest)
+}
+
+// ConsumeOnce redeems token like Consume, but only once apply, given the
+// decoded payload in dest, succeeded. If apply fails the token stays valid,
+// so the user can try again with the same link. A redeemed token is
+// remembered for remember, and using it again fails with
+// ErrVerificationTokenUsed instead of ErrInvalidVerificationToken.
+//
+// Of concurrent calls with the same token only one gets to apply: each first
+// claims the token with SETNX, and the others fail with
+// ErrVerificationTokenUsed.
+func (s *VerificationStore) ConsumeOnce(ctx context.Context, purpose, token string, dest any, remember time.Duration, apply func() error) error {
+	if token == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	hash := hashToken(token)
+	usedKey := s.keyBuilder.VerificationUsed(purpose, hash)
+	tokenKey := s.keyBuilder.VerificationToken(purpose, hash)
+
+	// The claim is short-lived, so a crash during apply doesn't lock the
+	// token until it expires
+	claimed, err := s.cache.SetNX(ctx, usedKey, verificationClaimed, verificationClaimTTL)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrVerificationTokenUsed
+	}
+	release := func() error {
+		return s.cache.Delete(ctx, usedKey)
+	}
+
+	raw, err := s.cache.Get(ctx, tokenKey)
+	if err != nil {
+		// Unknown tokens must not be reported as used later
+		if relErr := release(); relErr != nil {
+			return relErr
+		}
+		if errors.Is(err, cache.ErrKeyNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	var entry verificationEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return errors.Join(fmt.Errorf("failed to decode verification payload: %w", err), release())
+	}
+	if err := json.Unmarshal(entry.Payload, dest); err != nil {
+		return errors.Join(fmt.Errorf("failed to decode verification payload: %w", err), release())
+	}
+
+	if err := apply(); err != nil {
+		return errors.Join(err, release())
+	}
+
+	// Applied: from here on the token is spent whatever else fails
+	if err := s.cache.Set(ctx, usedKey, verificationUsed, remember); err != nil {
+		log.Printf("Failed to remember used %s token: %v", purpose, err)
+	}
+	if err := s.cache.Delete(ctx, tokenKey, s.keyBuilder.PendingVerification(purpose, entry.Subject)); err != nil {
+		log.Printf("Failed to remove used %s token: %v", purpose, err)
+	}
+	return nil
+}
+
+// redeem finishes consuming a token whose entry was raw: it clears the
+// subject's pending token and decodes the payload into dest.
+func (s *VerificationStore) redeem(ctx context.Context, purpose, raw string, dest any) error {
+	var entry verificationEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return fmt.Errorf("failed to decode verification payload: %w", err)
+	}
+	if err := s.cache.Delete(ctx, s.keyBuilder.PendingVerification(purpose, entry.Subject)); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(entry.Payload, dest); err != nil {
+		return fmt.Errorf("failed to decode verification payload: %w", err)
+	}
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
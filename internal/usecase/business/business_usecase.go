@@ -0,0 +1,196 @@
+package business
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+type BusinessUseCase interface {
+	// Create adds a business owned by req.OwnerID, unless they already own
+	// the maximum number
+	Create(ctx context.Context, req CreateRequest) (*domain.Business, error)
+	Get(ctx context.Context, id string) (*domain.Business, error)
+	ListByOwner(ctx context.Context, ownerID string) ([]*domain.Business, error)
+	// Update changes the fields set in req
+	Update(ctx context.Context, id string, req UpdateRequest) (*domain.Business, error)
+	Delete(ctx context.Context, id string) error
+	// Count returns the number of businesses across all users
+	Count(ctx context.Context) (int64, error)
+	// MaxPerUser is how many businesses a user may own
+	MaxPerUser() int
+}
+
+type CreateRequest struct {
+	OwnerID     string
+	Name        string
+	Category    string
+	Description string
+	Address     string
+	Phone       string
+	LogoURL     *string
+}
+
+type UpdateRequest struct {
+	Name        *string
+	Category    *string
+	Description *string
+	Address     *string
+	Phone       *string
+	// LogoURL set to an empty string removes the logo
+	LogoURL *string
+}
+
+type businessUseCase struct {
+	businessRepo repository.BusinessRepository
+	cache        cache.Cache
+	keyBuilder   *cache.CacheKeyBuilder
+	cfg          config.BusinessConfig
+}
+
+func NewBusinessUseCase(repo repository.BusinessRepository, c cache.Cache, kb *cache.CacheKeyBuilder, cfg config.BusinessConfig) BusinessUseCase {
+	return &businessUseCase{
+		businessRepo: repo,
+		cache:        c,
+		keyBuilder:   kb,
+		cfg:          cfg,
+	}
+}
+
+func (uc *businessUseCase) Create(ctx context.Context, req CreateRequest) (*domain.Business, error) {
+	// Concurrent creates can overshoot the limit by a few; it is a product
+	// limit rather than a security boundary
+	owned, err := uc.businessRepo.Count(ctx, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if owned >= int64(uc.cfg.MaxPerUser) {
+		return nil, ErrLimitReached
+	}
+
+	business := &domain.Business{
+		UserID:      req.OwnerID,
+		Name:        strings.TrimSpace(req.Name),
+		Category:    strings.TrimSpace(req.Category),
+		Description: strings.TrimSpace(req.Description),
+		Address:     strings.TrimSpace(req.Address),
+		Phone:       strings.TrimSpace(req.Phone),
+		LogoURL:     nonEmpty(req.LogoURL),
+	}
+	if err := uc.businessRepo.Create(ctx, business); err != nil {
+		return nil, err
+	}
+
+	return business, nil
+}
+
+// Get reads through the cache. A cache failure falls back to the database.
+func (uc *businessUseCase) Get(ctx context.Context, id string) (*domain.Business, error) {
+	key := uc.keyBuilder.For(ctx).Business(id)
+	if uc.cfg.CacheTTL > 0 {
+		raw, err := uc.cache.Get(ctx, key)
+		if err == nil {
+			var business domain.Business
+			if err := json.Unmarshal([]byte(raw), &business); err == nil {
+				return &business, nil
+			}
+		} else if !errors.Is(err, cache.ErrKeyNotFound) {
+			log.Printf("Failed to read cached business %s: %v", id, err)
+		}
+	}
+
+	business, err := uc.businessRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.cfg.CacheTTL > 0 {
+		if data, err := json.Marshal(business); err == nil {
+			if err := uc.cache.Set(ctx, key, data, uc.cfg.CacheTTL); err != nil {
+				log.Printf("Failed to cache business %s: %v", id, err)
+			}
+		}
+	}
+	return business, nil
+}
+
+func (uc *businessUseCase) ListByOwner(ctx context.Context, ownerID string) ([]*domain.Business, error) {
+	return uc.businessRepo.ListByUser(ctx, ownerID)
+}
+
+func (uc *businessUseCase) Update(ctx context.Context, id string, req UpdateRequest) (*domain.Business, error) {
+	business, err := uc.businessRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		business.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Category != nil {
+		business.Category = strings.TrimSpace(*req.Category)
+	}
+	if req.Description != nil {
+		business.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Address != nil {
+		business.Address = strings.TrimSpace(*req.Address)
+	}
+	if req.Phone != nil {
+		business.Phone = strings.TrimSpace(*req.Phone)
+	}
+	if req.LogoURL != nil {
+		business.LogoURL = nonEmpty(req.LogoURL)
+	}
+
+	if err := uc.businessRepo.Update(ctx, business); err != nil {
+		return nil, err
+	}
+	uc.invalidate(ctx, id)
+
+	return business, nil
+}
+
+func (uc *businessUseCase) Delete(ctx context.Context, id string) error {
+	if err := uc.businessRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	uc.invalidate(ctx, id)
+	return nil
+}
+
+func (uc *businessUseCase) Count(ctx context.Context) (int64, error) {
+	return uc.businessRepo.Count(ctx, "")
+}
+
+func (uc *businessUseCase) MaxPerUser() int {
+	return uc.cfg.MaxPerUser
+}
+
+// invalidate drops the cached copy of a business. A failure leaves a stale
+// copy until it expires, so it is only logged.
+func (uc *businessUseCase) invalidate(ctx context.Context, id string) {
+	if err := uc.cache.Delete(ctx, uc.keyBuilder.For(ctx).Business(id)); err != nil {
+		log.Printf("Failed to invalidate cache for business %s: %v", id, err)
+	}
+}
+
+// nonEmpty returns nil for a nil or blank string, so an empty logo URL is
+// stored as no logo.
+func nonEmpty(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
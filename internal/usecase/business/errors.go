@@ -0,0 +1,9 @@
+package business
+
+import "errors"
+
+var (
+	// ErrLimitReached means the user already owns businesses.max_per_user
+	// businesses
+	ErrLimitReached = errors.New("business limit reached")
+)
@@ -0,0 +1,207 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/imaging"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAvatarTimeout = 10 * time.Second
+	avatarContentType    = "image/jpeg"
+)
+
+// avatarVariantKey matches the key of one size of an uploaded avatar,
+// capturing the avatar's key prefix
+var avatarVariantKey = regexp.MustCompile(`^(avatars/[^/]+/[^/_]+)_\d+\.jpg$`)
+
+type AvatarUseCase interface {
+	// Upload decodes the image in req.Body, stores a square JPEG for each
+	// configured size and makes the largest the user's avatar. The previous
+	// uploaded avatar is removed.
+	Upload(ctx context.Context, req AvatarUploadRequest) (*AvatarResult, error)
+}
+
+type AvatarUploadRequest struct {
+	User *domain.User
+	Body io.Reader
+}
+
+type AvatarVariant struct {
+	Size int
+	URL  string
+}
+
+type AvatarResult struct {
+	User *domain.User
+	// Variants are ordered from largest to smallest
+	Variants []AvatarVariant
+}
+
+type avatarUseCase struct {
+	userRepo repository.UserRepository
+	storage  storage.ObjectStorage
+	cfg      config.AvatarConfig
+	// slots bounds concurrent processing, and with it the memory held by
+	// decoded images
+	slots chan struct{}
+}
+
+func NewAvatarUseCase(userRepo repository.UserRepository, store storage.ObjectStorage, cfg config.AvatarConfig) AvatarUseCase {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultAvatarTimeout
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 1
+	}
+	cfg.Sizes = slices.Clone(cfg.Sizes)
+	slices.SortFunc(cfg.Sizes, func(a, b int) int { return b - a })
+
+	return &avatarUseCase{
+		userRepo: userRepo,
+		storage:  store,
+		cfg:      cfg,
+		slots:    make(chan struct{}, cfg.MaxConcurrent),
+	}
+}
+
+func (uc *avatarUseCase) Upload(ctx context.Context, req AvatarUploadRequest) (*AvatarResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.cfg.Timeout)
+	defer cancel()
+
+	counter := &limitedReader{r: req.Body, remaining: uc.cfg.MaxFileSize}
+	data, err := io.ReadAll(counter)
+	if err != nil {
+		if errors.Is(err, ErrFileTooLarge) {
+			return nil, ErrFileTooLarge
+		}
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	select {
+	case uc.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ErrProcessingTimeout
+	}
+
+	// Decoding can't be interrupted, so it runs apart from the request and
+	// the request gives up when the budget runs out. The slot is held until
+	// the work actually finishes.
+	type processed struct {
+		variants map[int][]byte
+		err      error
+	}
+	done := make(chan processed, 1)
+	go func() {
+		defer func() { <-uc.slots }()
+		variants, err := uc.process(data)
+		done <- processed{variants: variants, err: err}
+	}()
+
+	var variants map[int][]byte
+	select {
+	case p := <-done:
+		if p.err != nil {
+			return nil, p.err
+		}
+		variants = p.variants
+	case <-ctx.Done():
+		return nil, ErrProcessingTimeout
+	}
+
+	prefix := fmt.Sprintf("avatars/%s/%s", req.User.ID, uuid.NewString())
+	result := &AvatarResult{User: req.User}
+	for _, size := range uc.cfg.Sizes {
+		key := avatarKey(prefix, size)
+		if err := uc.storage.Put(ctx, key, bytes.NewReader(variants[size]), storage.ObjectMeta{ContentType: avatarContentType}); err != nil {
+			uc.removeAvatar(prefix)
+			return nil, err
+		}
+		result.Variants = append(result.Variants, AvatarVariant{Size: size, URL: uc.storage.URL(key)})
+	}
+
+	previousURL, previous := req.User.AvatarURL, req.User.AvatarKey
+	avatarURL := result.Variants[0].URL
+	req.User.AvatarURL = &avatarURL
+	req.User.AvatarKey = &prefix
+	if err := uc.userRepo.Update(ctx, req.User); err != nil {
+		req.User.AvatarURL, req.User.AvatarKey = previousURL, previous
+		uc.removeAvatar(prefix)
+		return nil, err
+	}
+
+	if previous != nil {
+		uc.removeAvatar(*previous)
+	}
+	return result, nil
+}
+
+// process decodes data and encodes each configured size.
+func (uc *avatarUseCase) process(data []byte) (map[int][]byte, error) {
+	img, err := imaging.Decode(data, uc.cfg.MaxPixels)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return nil, ErrFileTypeNotAllowed
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return nil, ErrImageTooLarge
+	case err != nil:
+		return nil, ErrInvalidImage
+	}
+
+	variants := make(map[int][]byte, len(uc.cfg.Sizes))
+	for _, size := range uc.cfg.Sizes {
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, imaging.Square(img, size), uc.cfg.Quality); err != nil {
+			return nil, err
+		}
+		variants[size] = buf.Bytes()
+	}
+	return variants, nil
+}
+
+// removeAvatar deletes every stored variant of an avatar. It runs detached
+// from the request context and only logs failures; leftovers are picked up
+// by the orphan cleanup.
+func (uc *avatarUseCase) removeAvatar(prefix string) {
+	ctx := context.Background()
+	objects, err := uc.storage.List(ctx, prefix+"_", "", 100)
+	if err != nil {
+		log.Printf("Failed to list avatar %s for removal: %v", prefix, err)
+		return
+	}
+	for _, obj := range objects {
+		if err := uc.storage.Delete(ctx, obj.Key); err != nil {
+			log.Printf("Failed to remove avatar %s: %v", obj.Key, err)
+		}
+	}
+}
+
+func avatarKey(prefix string, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", prefix, size)
+}
+
+// avatarPrefix returns the key prefix shared by the variants of an uploaded
+// avatar, if key is one of them.
+func avatarPrefix(key string) (string, bool) {
+	m := avatarVariantKey.FindStringSubmatch(key)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
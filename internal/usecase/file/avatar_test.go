@@ -0,0 +1,199 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+)
+
+var testAvatarConfig = config.AvatarConfig{
+	MaxFileSize:   64 << 10,
+	MaxPixels:     1000 * 1000,
+	Sizes:         []int{64, 256},
+	Quality:       85,
+	Timeout:       5 * time.Second,
+	MaxConcurrent: 2,
+}
+
+// avatarUsers is a repository.UserRepository that only records updates.
+type avatarUsers struct {
+	repository.UserRepository
+	updates   int
+	failWrite bool
+}
+
+func (r *avatarUsers) Update(ctx context.Context, user *domain.User) error {
+	if r.failWrite {
+		return domain.ErrUnavailable
+	}
+	r.updates++
+	return nil
+}
+
+func encodedPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func animatedGIF(t *testing.T) []byte {
+	t.Helper()
+	palette := color.Palette{color.White, color.Black}
+	anim := &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 100, 100), palette),
+			image.NewPaletted(image.Rect(0, 0, 100, 100), palette),
+		},
+		Delay: []int{10, 10},
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAvatarUpload(t *testing.T) {
+	valid := encodedPNG(t, 400, 300)
+
+	tests := []struct {
+		name    string
+		body    []byte
+		wantErr error
+	}{
+		{"png", valid, nil},
+		{"animated gif", animatedGIF(t), nil},
+		{"corrupt", valid[:len(valid)/2], ErrInvalidImage},
+		{"not an image", []byte("<html><script>alert(1)</script></html>"), ErrFileTypeNotAllowed},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"/>`), ErrFileTypeNotAllowed},
+		{"too many pixels", encodedPNG(t, 2000, 1000), ErrImageTooLarge},
+		{"file too large", append(bytes.Clone(valid), make([]byte, 64<<10)...), ErrFileTooLarge},
+		{"empty", nil, ErrEmptyFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStorage()
+			users := &avatarUsers{}
+			uc := NewAvatarUseCase(users, store, testAvatarConfig)
+			user := &domain.User{ID: "user-1"}
+
+			result, err := uc.Upload(context.Background(), AvatarUploadRequest{User: user, Body: bytes.NewReader(tt.body)})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Upload() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if store.len() != 0 || users.updates != 0 || user.AvatarURL != nil {
+					t.Errorf("rejected upload stored %d objects and updated the user %d times", store.len(), users.updates)
+				}
+				return
+			}
+
+			// Largest first, and the avatar is the largest
+			if len(result.Variants) != 2 || result.Variants[0].Size != 256 || result.Variants[1].Size != 64 {
+				t.Fatalf("variants = %+v, want 256 and 64", result.Variants)
+			}
+			if user.AvatarURL == nil || *user.AvatarURL != result.Variants[0].URL || users.updates != 1 {
+				t.Errorf("AvatarURL = %v, want %s", user.AvatarURL, result.Variants[0].URL)
+			}
+
+			for _, v := range result.Variants {
+				key := strings.TrimPrefix(v.URL, "https://files.umkm.id/")
+				obj, ok := store.object(key)
+				if !ok {
+					t.Fatalf("variant %s not stored", key)
+				}
+				if obj.meta.ContentType != "image/jpeg" {
+					t.Errorf("%s content type = %q, want image/jpeg", key, obj.meta.ContentType)
+				}
+				img, err := jpeg.Decode(bytes.NewReader(obj.data))
+				if err != nil {
+					t.Fatalf("%s is not a JPEG: %v", key, err)
+				}
+				if got := img.Bounds().Size(); got != image.Pt(v.Size, v.Size) {
+					t.Errorf("%s is %v, want %dx%d", key, got, v.Size, v.Size)
+				}
+				if prefix, ok := avatarPrefix(key); !ok || prefix != *user.AvatarKey {
+					t.Errorf("key %s doesn't belong to avatar %s", key, *user.AvatarKey)
+				}
+			}
+		})
+	}
+}
+
+func TestAvatarReplace(t *testing.T) {
+	store := newMemStorage()
+	users := &avatarUsers{}
+	uc := NewAvatarUseCase(users, store, testAvatarConfig)
+	user := &domain.User{ID: "user-1"}
+	ctx := context.Background()
+
+	if _, err := uc.Upload(ctx, AvatarUploadRequest{User: user, Body: bytes.NewReader(encodedPNG(t, 100, 100))}); err != nil {
+		t.Fatal(err)
+	}
+	first := *user.AvatarKey
+
+	if _, err := uc.Upload(ctx, AvatarUploadRequest{User: user, Body: bytes.NewReader(encodedPNG(t, 120, 80))}); err != nil {
+		t.Fatal(err)
+	}
+	if *user.AvatarKey == first {
+		t.Fatal("second upload reused the first avatar's key")
+	}
+
+	// Only the new avatar's variants are left
+	objects, _ := store.List(ctx, "avatars/", "", 100)
+	if len(objects) != 2 {
+		t.Fatalf("stored %d objects, want 2", len(objects))
+	}
+	for _, obj := range objects {
+		if !strings.HasPrefix(obj.Key, *user.AvatarKey+"_") {
+			t.Errorf("%s left behind", obj.Key)
+		}
+	}
+
+	// A failed update keeps the current avatar and removes the new one
+	users.failWrite = true
+	current := *user.AvatarKey
+	_, err := uc.Upload(ctx, AvatarUploadRequest{User: user, Body: bytes.NewReader(encodedPNG(t, 100, 100))})
+	if !errors.Is(err, domain.ErrUnavailable) {
+		t.Fatalf("Upload() error = %v, want %v", err, domain.ErrUnavailable)
+	}
+	if *user.AvatarKey != current || store.len() != 2 {
+		t.Errorf("avatar = %s with %d objects, want %s kept with 2", *user.AvatarKey, store.len(), current)
+	}
+}
+
+func TestAvatarTimeout(t *testing.T) {
+	cfg := testAvatarConfig
+	cfg.Timeout = time.Nanosecond
+	cfg.MaxConcurrent = 1
+	store := newMemStorage()
+	uc := NewAvatarUseCase(&avatarUsers{}, store, cfg)
+
+	_, err := uc.Upload(context.Background(), AvatarUploadRequest{User: &domain.User{ID: "user-1"}, Body: bytes.NewReader(encodedPNG(t, 800, 800))})
+	if !errors.Is(err, ErrProcessingTimeout) {
+		t.Fatalf("Upload() error = %v, want %v", err, ErrProcessingTimeout)
+	}
+	if store.len() != 0 {
+		t.Errorf("timed out upload stored %d objects", store.len())
+	}
+}
@@ -0,0 +1,140 @@
+package file
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// sniffLen is how much of the body http.DetectContentType looks at.
+const sniffLen = 512
+
+// contentTypes lists, per allowed extension, the MIME types accepted both as
+// the declared Content-Type and as the sniffed one. The first is the type the
+// file is stored with. Sniffing can't tell CSV or JSON from plain text, so
+// those accept text/plain as well. Markup that browsers execute (HTML, XML
+// and SVG, which can carry scripts) is never accepted, whatever the allowlist
+// says.
+var contentTypes = map[string][]string{
+	".pdf":  {"application/pdf"},
+	".csv":  {"text/csv", "text/plain", "application/vnd.ms-excel"},
+	".json": {"application/json", "text/plain"},
+	".txt":  {"text/plain"},
+	".png":  {"image/png"},
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".gif":  {"image/gif"},
+	".webp": {"image/webp"},
+}
+
+// inlineTypes are the stored types browsers may display in place. Anything
+// else is served as an attachment, so even a file that slipped through as
+// something else is downloaded rather than rendered on our origin.
+var inlineTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// markupSignatures are the openings of documents browsers treat as HTML or
+// run scripts from. http.DetectContentType only spots them at the very start
+// of the body; text uploads are rejected if one appears anywhere in the
+// sniffed bytes.
+var markupSignatures = [][]byte{
+	[]byte("<!doctype"),
+	[]byte("<html"),
+	[]byte("<head"),
+	[]byte("<body"),
+	[]byte("<script"),
+	[]byte("<iframe"),
+	[]byte("<svg"),
+	[]byte("<?xml"),
+	[]byte("<object"),
+	[]byte("<embed"),
+	[]byte("<meta"),
+}
+
+// checkContent validates an upload with extension ext against the type the
+// client declared and the first bytes of its content, and returns the type
+// to store it with. The declared type may be empty; it is otherwise required
+// to agree with the extension, as is the sniffed type.
+func checkContent(ext, declared string, head []byte) (string, error) {
+	accepted, ok := contentTypes[ext]
+	if !ok {
+		return "", ErrFileTypeNotAllowed
+	}
+
+	if declared := mediaType(declared); declared != "" && declared != "application/octet-stream" {
+		if !contains(accepted, declared) {
+			return "", ErrFileTypeNotAllowed
+		}
+	}
+
+	sniffed := mediaType(http.DetectContentType(head))
+	if !contains(accepted, sniffed) {
+		return "", ErrFileTypeNotAllowed
+	}
+
+	if strings.HasPrefix(sniffed, "text/") {
+		if containsMarkup(head) {
+			return "", ErrFileTypeNotAllowed
+		}
+		if ext == ".json" && !looksLikeJSON(head) {
+			return "", ErrFileTypeNotAllowed
+		}
+	}
+
+	return accepted[0], nil
+}
+
+// contentDisposition returns the Content-Disposition a file of the given
+// stored type is served with.
+func contentDisposition(contentType, filename string) string {
+	if inlineTypes[contentType] {
+		return ""
+	}
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+	if disposition == "" {
+		return "attachment"
+	}
+	return disposition
+}
+
+func containsMarkup(head []byte) bool {
+	lower := bytes.ToLower(head)
+	for _, sig := range markupSignatures {
+		if bytes.Contains(lower, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeJSON checks that the content opens an object or array, the only
+// top-level values worth uploading as a file.
+func looksLikeJSON(head []byte) bool {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(mt)
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckContent(t *testing.T) {
+	png := pngBody(64)
+	jpeg := []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00")
+	gif := []byte("GIF89a\x01\x00\x01\x00")
+	webp := []byte("RIFF\x24\x00\x00\x00WEBPVP8 ")
+	pdf := []byte("%PDF-1.7\n")
+
+	tests := []struct {
+		name     string
+		ext      string
+		declared string
+		head     []byte
+		wantErr  bool
+		wantType string
+	}{
+		{"png", ".png", "image/png", png, false, "image/png"},
+		{"jpeg as .jpg", ".jpg", "image/jpeg", jpeg, false, "image/jpeg"},
+		{"jpeg as .jpeg", ".jpeg", "", jpeg, false, "image/jpeg"},
+		{"gif", ".gif", "image/gif", gif, false, "image/gif"},
+		{"webp", ".webp", "image/webp", webp, false, "image/webp"},
+		{"pdf", ".pdf", "application/pdf", pdf, false, "application/pdf"},
+		{"declared type with parameters", ".txt", "text/plain; charset=utf-8", []byte("hello"), false, "text/plain"},
+		{"declared type in upper case", ".png", "IMAGE/PNG", png, false, "image/png"},
+		{"csv declared by Excel", ".csv", "application/vnd.ms-excel", []byte("sku,qty\nA1,4\n"), false, "text/csv"},
+		{"json object", ".json", "application/json", []byte(`{"sku":"A1"}`), false, "application/json"},
+		{"unparseable declared type", ".png", "image/", png, false, "image/png"},
+		{"json array after a BOM", ".json", "", []byte("\xef\xbb\xbf\n [1,2]"), false, "application/json"},
+
+		// Content that doesn't match the extension or declared type
+		{"html named .png", ".png", "image/png", []byte("<html><script>alert(1)</script></html>"), true, ""},
+		{"html declared as png", ".png", "image/png", []byte("<!DOCTYPE html><p>hi"), true, ""},
+		{"png declared as pdf", ".png", "application/pdf", png, true, ""},
+		{"jpeg named .png", ".png", "image/png", jpeg, true, ""},
+		{"pdf named .txt", ".txt", "text/plain", pdf, true, ""},
+		{"executable named .pdf", ".pdf", "", []byte("MZ\x90\x00\x03\x00"), true, ""},
+		{"zip named .csv", ".csv", "text/csv", []byte("PK\x03\x04\x14\x00"), true, ""},
+		{"json that isn't an object", ".json", "application/json", []byte(`"just a string"`), true, ""},
+
+		// Markup browsers would execute
+		{"svg", ".svg", "image/svg+xml", []byte(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"/>`), true, ""},
+		{"svg named .txt", ".txt", "text/plain", []byte(`<svg onload="alert(1)"/>`), true, ""},
+		{"xml named .txt", ".txt", "text/plain", []byte(`<?xml version="1.0"?><x/>`), true, ""},
+		{"html", ".html", "text/html", []byte("<html></html>"), true, ""},
+		{"script after leading text", ".txt", "text/plain", []byte("notes\n\n<SCRIPT>alert(1)</SCRIPT>"), true, ""},
+		{"iframe in a csv", ".csv", "text/csv", []byte("name\n<iframe src=//evil>"), true, ""},
+		{"html in json", ".json", "application/json", []byte(`{"x":"<body onload=alert(1)>"}`), true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkContent(tt.ext, tt.declared, tt.head)
+			if tt.wantErr {
+				if !errors.Is(err, ErrFileTypeNotAllowed) {
+					t.Fatalf("checkContent() = %q, %v, want %v", got, err, ErrFileTypeNotAllowed)
+				}
+				return
+			}
+			if err != nil || got != tt.wantType {
+				t.Errorf("checkContent() = %q, %v, want %q", got, err, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		contentType string
+		filename    string
+		want        string
+	}{
+		{"image/png", "logo.png", ""},
+		{"image/webp", "logo.webp", ""},
+		{"application/pdf", "invoice.pdf", "attachment; filename=invoice.pdf"},
+		{"text/plain", "my notes.txt", `attachment; filename="my notes.txt"`},
+		{"application/json", "data.json", "attachment; filename=data.json"},
+		{"text/csv", "laporan\u00a0bulan.csv", "attachment; filename*=utf-8''laporan%C2%A0bulan.csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got := contentDisposition(tt.contentType, tt.filename); got != tt.want {
+				t.Errorf("contentDisposition(%q, %q) = %q, want %q", tt.contentType, tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadStoresAsAttachment(t *testing.T) {
+	tests := []struct {
+		filename string
+		body     []byte
+		want     string
+	}{
+		{"logo.png", pngBody(64), ""},
+		{"notes.txt", []byte("hello"), "attachment"},
+		{"invoice.pdf", []byte("%PDF-1.7\n"), "attachment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			store := newMemStorage()
+			uc := NewFileUseCase(newMemFileRepo(), store, testUploadConfig)
+
+			file, err := uc.Upload(context.Background(), UploadRequest{OwnerID: "owner-1", Filename: tt.filename, Body: bytes.NewReader(tt.body)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj, _ := store.object(file.StoragePath)
+			if got := obj.meta.ContentDisposition; !strings.HasPrefix(got, tt.want) || (tt.want == "") != (got == "") {
+				t.Errorf("Content-Disposition = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
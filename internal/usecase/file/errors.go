@@ -0,0 +1,26 @@
+package file
+
+import "errors"
+
+var (
+	// ErrFileTooLarge means the upload exceeded upload.max_file_size
+	ErrFileTooLarge = errors.New("file exceeds the maximum allowed size")
+
+	// ErrFileTypeNotAllowed means the extension, declared type or detected
+	// content isn't on the upload allowlist
+	ErrFileTypeNotAllowed = errors.New("file type not allowed")
+
+	// ErrEmptyFile means the upload had no content
+	ErrEmptyFile = errors.New("file is empty")
+
+	// ErrInvalidImage means an image upload could not be decoded
+	ErrInvalidImage = errors.New("invalid image")
+
+	// ErrImageTooLarge means the image's dimensions exceed
+	// upload.avatar.max_pixels
+	ErrImageTooLarge = errors.New("image dimensions exceed the maximum allowed")
+
+	// ErrProcessingTimeout means the image could not be processed within
+	// upload.avatar.timeout
+	ErrProcessingTimeout = errors.New("image processing timed out")
+)
@@ -0,0 +1,175 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
+	"github.com/google/uuid"
+)
+
+type FileUseCase interface {
+	// Upload streams req.Body to object storage and records the file. Nothing
+	// is kept if the upload is rejected part way through.
+	Upload(ctx context.Context, req UploadRequest) (*domain.File, error)
+
+	GetByID(ctx context.Context, id string) (*domain.File, error)
+
+	// Delete removes the stored object and the file record
+	Delete(ctx context.Context, id string) error
+
+	// URL returns the address a stored file can be fetched from
+	URL(file *domain.File) string
+}
+
+type UploadRequest struct {
+	OwnerID  string
+	Filename string
+	// ContentType is the type declared by the client, if any
+	ContentType string
+	Body        io.Reader
+}
+
+type fileUseCase struct {
+	fileRepo repository.FileRepository
+	storage  storage.ObjectStorage
+	cfg      config.UploadConfig
+}
+
+func NewFileUseCase(fileRepo repository.FileRepository, store storage.ObjectStorage, cfg config.UploadConfig) FileUseCase {
+	return &fileUseCase{
+		fileRepo: fileRepo,
+		storage:  store,
+		cfg:      cfg,
+	}
+}
+
+func (uc *fileUseCase) Upload(ctx context.Context, req UploadRequest) (*domain.File, error) {
+	filename := path.Base(filepath.ToSlash(req.Filename))
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !uc.extensionAllowed(ext) {
+		return nil, ErrFileTypeNotAllowed
+	}
+
+	body := bufio.NewReaderSize(req.Body, sniffLen)
+	head, err := body.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if len(head) == 0 {
+		return nil, ErrEmptyFile
+	}
+	contentType, err := checkContent(ext, req.ContentType, head)
+	if err != nil {
+		return nil, err
+	}
+
+	counter := &limitedReader{r: body, remaining: uc.cfg.MaxFileSize}
+	key := fmt.Sprintf("uploads/%s/%s%s", req.OwnerID, uuid.NewString(), ext)
+	meta := storage.ObjectMeta{
+		ContentType:        contentType,
+		ContentDisposition: contentDisposition(contentType, filename),
+	}
+
+	if err := uc.storage.Put(ctx, key, counter, meta); err != nil {
+		// Put is expected to abort partial uploads itself; this catches
+		// backends that commit what they received before the error
+		uc.removeObject(key)
+		if counter.remaining < 0 || errors.Is(err, ErrFileTooLarge) {
+			return nil, ErrFileTooLarge
+		}
+		return nil, err
+	}
+
+	file := &domain.File{
+		UserID:           req.OwnerID,
+		Filename:         path.Base(key),
+		OriginalFilename: filename,
+		FileSize:         counter.read,
+		MimeType:         contentType,
+		StoragePath:      key,
+		StorageProvider:  "s3",
+	}
+	if err := uc.fileRepo.Create(ctx, file); err != nil {
+		uc.removeObject(key)
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (uc *fileUseCase) GetByID(ctx context.Context, id string) (*domain.File, error) {
+	return uc.fileRepo.FindByID(ctx, id)
+}
+
+func (uc *fileUseCase) Delete(ctx context.Context, id string) error {
+	file, err := uc.fileRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.storage.Delete(ctx, file.StoragePath); err != nil {
+		return err
+	}
+
+	return uc.fileRepo.Delete(ctx, file.ID)
+}
+
+func (uc *fileUseCase) URL(file *domain.File) string {
+	return uc.storage.URL(file.StoragePath)
+}
+
+func (uc *fileUseCase) extensionAllowed(ext string) bool {
+	if _, known := contentTypes[ext]; !known {
+		return false
+	}
+	for _, allowed := range uc.cfg.AllowedFileTypes {
+		if strings.EqualFold(allowed, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeObject cleans up after a failed upload. It runs detached from the
+// request context, which is usually what got cancelled.
+func (uc *fileUseCase) removeObject(key string) {
+	if err := uc.storage.Delete(context.Background(), key); err != nil && !errors.Is(err, storage.ErrNotConfigured) {
+		log.Printf("Failed to remove partial upload %s: %v", key, err)
+	}
+}
+
+// limitedReader fails with ErrFileTooLarge once more than remaining bytes
+// are read, so an oversized upload is aborted mid-stream.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	read      int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrFileTooLarge
+	}
+	// Read one byte past the limit so hitting it exactly isn't an error
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return 0, ErrFileTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,380 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
+	"github.com/google/uuid"
+)
+
+// memStorage is an in-memory storage.ObjectStorage.
+type memStorage struct {
+	mu      sync.Mutex
+	objects map[string]memObject
+	// keepPartial stores what was read before a failed Put, like a backend
+	// that commits partial uploads
+	keepPartial bool
+}
+
+type memObject struct {
+	data     []byte
+	meta     storage.ObjectMeta
+	modified time.Time
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{objects: make(map[string]memObject)}
+}
+
+func (s *memStorage) Put(ctx context.Context, key string, r io.Reader, meta storage.ObjectMeta) error {
+	data, err := io.ReadAll(r)
+	if err != nil && !s.keepPartial {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = memObject{data: data, meta: meta, modified: time.Now()}
+	return err
+}
+
+func (s *memStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memStorage) URL(key string) string {
+	return "https://files.umkm.id/" + key
+}
+
+func (s *memStorage) List(ctx context.Context, prefix, after string, limit int) ([]storage.ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var infos []storage.ObjectInfo
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, prefix) && key > after {
+			infos = append(infos, storage.ObjectInfo{Key: key, Size: int64(len(obj.data)), LastModified: obj.modified})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	if len(infos) > limit {
+		infos = infos[:limit]
+	}
+	return infos, nil
+}
+
+func (s *memStorage) object(key string) (memObject, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	return obj, ok
+}
+
+func (s *memStorage) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+// memFileRepo is an in-memory repository.FileRepository.
+type memFileRepo struct {
+	mu    sync.Mutex
+	files map[string]*domain.File
+	// failCreate makes Create fail, as when the database is down
+	failCreate bool
+}
+
+func newMemFileRepo() *memFileRepo {
+	return &memFileRepo{files: make(map[string]*domain.File)}
+}
+
+func (r *memFileRepo) Create(ctx context.Context, file *domain.File) error {
+	if r.failCreate {
+		return domain.ErrUnavailable
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file.ID = uuid.NewString()
+	copied := *file
+	r.files[file.ID] = &copied
+	return nil
+}
+
+func (r *memFileRepo) FindByID(ctx context.Context, id string) (*domain.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	file, ok := r.files[id]
+	if !ok {
+		return nil, repository.ErrFileNotFound
+	}
+	copied := *file
+	return &copied, nil
+}
+
+func (r *memFileRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.files[id]; !ok {
+		return repository.ErrFileNotFound
+	}
+	delete(r.files, id)
+	return nil
+}
+
+func (r *memFileRepo) StoragePathsInUse(ctx context.Context, paths []string) (map[string]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inUse := make(map[string]bool)
+	for _, file := range r.files {
+		inUse[file.StoragePath] = true
+	}
+	found := make(map[string]bool)
+	for _, p := range paths {
+		if inUse[p] {
+			found[p] = true
+		}
+	}
+	return found, nil
+}
+
+func (r *memFileRepo) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.files)
+}
+
+var testUploadConfig = config.UploadConfig{
+	MaxFileSize:      1024,
+	AllowedFileTypes: []string{".png", ".pdf", ".csv", ".txt", ".json"},
+}
+
+// pngHeader is the signature http.DetectContentType recognizes as PNG.
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A")
+
+func pngBody(size int) []byte {
+	return append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, size-len(pngHeader))...)
+}
+
+func TestUpload(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		body        []byte
+		keepPartial bool
+		wantErr     error
+		wantType    string
+	}{
+		{"png", "logo.png", "image/png", pngBody(200), false, nil, "image/png"},
+		{"no declared type", "logo.PNG", "", pngBody(200), false, nil, "image/png"},
+		{"octet-stream declared", "logo.png", "application/octet-stream", pngBody(200), false, nil, "image/png"},
+		{"csv sniffed as text", "stock.csv", "text/csv", []byte("sku,qty\nA1,4\n"), false, nil, "text/csv"},
+		{"pdf", "invoice.pdf", "application/pdf", []byte("%PDF-1.7\n..."), false, nil, "application/pdf"},
+		{"exactly the limit", "logo.png", "image/png", pngBody(1024), false, nil, "image/png"},
+		{"path in filename", "../../etc/logo.png", "image/png", pngBody(200), false, nil, "image/png"},
+
+		{"oversized", "logo.png", "image/png", pngBody(1025), false, ErrFileTooLarge, ""},
+		{"oversized on a backend keeping partial objects", "logo.png", "image/png", pngBody(4096), true, ErrFileTooLarge, ""},
+		{"spoofed declared type", "invoice.pdf", "image/png", []byte("%PDF-1.7\n..."), false, ErrFileTypeNotAllowed, ""},
+		{"content does not match the extension", "logo.png", "image/png", []byte("%PDF-1.7\n..."), false, ErrFileTypeNotAllowed, ""},
+		{"executable renamed", "tool.png", "", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00"), false, ErrFileTypeNotAllowed, ""},
+		{"extension not allowed", "logo.gif", "image/gif", []byte("GIF89a..."), false, ErrFileTypeNotAllowed, ""},
+		{"unknown extension", "run.sh", "text/plain", []byte("echo hi"), false, ErrFileTypeNotAllowed, ""},
+		{"no extension", "README", "text/plain", []byte("hello"), false, ErrFileTypeNotAllowed, ""},
+		{"empty", "notes.txt", "text/plain", nil, false, ErrEmptyFile, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStorage()
+			store.keepPartial = tt.keepPartial
+			repo := newMemFileRepo()
+			uc := NewFileUseCase(repo, store, testUploadConfig)
+
+			file, err := uc.Upload(context.Background(), UploadRequest{
+				OwnerID:     "owner-1",
+				Filename:    tt.filename,
+				ContentType: tt.contentType,
+				Body:        bytes.NewReader(tt.body),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Upload() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				// Nothing is left behind by a rejected upload
+				if n := store.len(); n != 0 {
+					t.Errorf("%d objects stored, want none", n)
+				}
+				if n := repo.len(); n != 0 {
+					t.Errorf("%d files recorded, want none", n)
+				}
+				return
+			}
+
+			if file.FileSize != int64(len(tt.body)) || file.MimeType != tt.wantType || file.UserID != "owner-1" {
+				t.Errorf("file = %d bytes of %s for %s, want %d bytes of %s for owner-1", file.FileSize, file.MimeType, file.UserID, len(tt.body), tt.wantType)
+			}
+			if !strings.HasPrefix(file.StoragePath, "uploads/owner-1/") || strings.Contains(file.StoragePath, "..") {
+				t.Errorf("StoragePath = %q, want it under uploads/owner-1/", file.StoragePath)
+			}
+			obj, ok := store.object(file.StoragePath)
+			if !ok || !bytes.Equal(obj.data, tt.body) || obj.meta.ContentType != tt.wantType {
+				t.Errorf("stored object = %v %s, want the uploaded body as %s", ok, obj.meta.ContentType, tt.wantType)
+			}
+			if _, err := repo.FindByID(context.Background(), file.ID); err != nil {
+				t.Errorf("file was not recorded: %v", err)
+			}
+		})
+	}
+}
+
+// countingReader serves an endless body and counts what was read of it.
+type countingReader struct {
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	if r.n == 0 && len(p) >= len(pngHeader) {
+		copy(p, pngHeader)
+	}
+	r.n += int64(len(p))
+	return len(p), nil
+}
+
+func TestUploadAbortsMidStream(t *testing.T) {
+	store := newMemStorage()
+	uc := NewFileUseCase(newMemFileRepo(), store, testUploadConfig)
+
+	body := &countingReader{}
+	_, err := uc.Upload(context.Background(), UploadRequest{OwnerID: "owner-1", Filename: "huge.png", Body: body})
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("Upload() error = %v, want %v", err, ErrFileTooLarge)
+	}
+	// The endless body is abandoned shortly after the limit
+	if body.n > testUploadConfig.MaxFileSize+64*1024 {
+		t.Errorf("read %d bytes of an endless body, want the upload stopped near the %d byte limit", body.n, testUploadConfig.MaxFileSize)
+	}
+}
+
+func TestUploadRecordFails(t *testing.T) {
+	store := newMemStorage()
+	repo := newMemFileRepo()
+	repo.failCreate = true
+	uc := NewFileUseCase(repo, store, testUploadConfig)
+
+	_, err := uc.Upload(context.Background(), UploadRequest{OwnerID: "owner-1", Filename: "logo.png", Body: bytes.NewReader(pngBody(200))})
+	if !errors.Is(err, domain.ErrUnavailable) {
+		t.Fatalf("Upload() error = %v, want %v", err, domain.ErrUnavailable)
+	}
+	if n := store.len(); n != 0 {
+		t.Errorf("%d objects stored without a file record, want none", n)
+	}
+}
+
+func TestUploadConcurrent(t *testing.T) {
+	store := newMemStorage()
+	repo := newMemFileRepo()
+	uc := NewFileUseCase(repo, store, testUploadConfig)
+
+	const uploads = 32
+	var wg sync.WaitGroup
+	errs := make(chan error, uploads)
+	paths := make(chan string, uploads)
+	for i := range uploads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := pngBody(100 + i)
+			// Every other upload is oversized and must not disturb the rest
+			if i%2 == 1 {
+				body = pngBody(2048)
+			}
+			file, err := uc.Upload(context.Background(), UploadRequest{
+				OwnerID:  fmt.Sprintf("owner-%d", i%4),
+				Filename: "logo.png",
+				Body:     bytes.NewReader(body),
+			})
+			if i%2 == 1 {
+				if !errors.Is(err, ErrFileTooLarge) {
+					errs <- fmt.Errorf("oversized upload %d: err = %v", i, err)
+				}
+				return
+			}
+			if err != nil {
+				errs <- fmt.Errorf("upload %d: %w", i, err)
+				return
+			}
+			paths <- file.StoragePath
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(paths)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	seen := make(map[string]bool)
+	for p := range paths {
+		if seen[p] {
+			t.Errorf("two uploads share storage path %s", p)
+		}
+		seen[p] = true
+	}
+	if len(seen) != uploads/2 || store.len() != uploads/2 || repo.len() != uploads/2 {
+		t.Errorf("%d paths, %d objects and %d records, want %d of each", len(seen), store.len(), repo.len(), uploads/2)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	store := newMemStorage()
+	repo := newMemFileRepo()
+	uc := NewFileUseCase(repo, store, testUploadConfig)
+	ctx := context.Background()
+
+	file, err := uc.Upload(ctx, UploadRequest{OwnerID: "owner-1", Filename: "logo.png", Body: bytes.NewReader(pngBody(200))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := uc.URL(file); got != "https://files.umkm.id/"+file.StoragePath {
+		t.Errorf("URL() = %q", got)
+	}
+
+	if err := uc.Delete(ctx, file.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok := store.object(file.StoragePath); ok {
+		t.Error("object still stored after Delete()")
+	}
+	if _, err := uc.GetByID(ctx, file.ID); !errors.Is(err, repository.ErrFileNotFound) {
+		t.Errorf("GetByID() after Delete() error = %v, want %v", err, repository.ErrFileNotFound)
+	}
+	if err := uc.Delete(ctx, file.ID); !errors.Is(err, repository.ErrFileNotFound) {
+		t.Errorf("second Delete() error = %v, want %v", err, repository.ErrFileNotFound)
+	}
+}
+
+func TestUploadUnconfiguredStorage(t *testing.T) {
+	uc := NewFileUseCase(newMemFileRepo(), storage.Unconfigured{}, testUploadConfig)
+
+	_, err := uc.Upload(context.Background(), UploadRequest{OwnerID: "owner-1", Filename: "logo.png", Body: bytes.NewReader(pngBody(200))})
+	if !errors.Is(err, storage.ErrNotConfigured) {
+		t.Errorf("Upload() error = %v, want %v", err, storage.ErrNotConfigured)
+	}
+}
@@ -0,0 +1,223 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/storage"
+)
+
+const (
+	defaultOrphanPageSize = 500
+	defaultOrphanBudget   = 2 * time.Minute
+)
+
+// orphanMetrics is published under /debug/vars when that endpoint is served.
+var orphanMetrics = expvar.NewMap("storage_orphan_cleanup")
+
+// OrphanReport summarises one cleanup run.
+type OrphanReport struct {
+	Scanned int
+	Orphans int
+	Deleted int
+	// Bytes is the size of the orphans found, or of those deleted when
+	// deletion is enabled
+	Bytes  int64
+	DryRun bool
+	// Complete is false when the run stopped at its budget
+	Complete bool
+}
+
+// OrphanCleaner removes stored objects that neither a file record nor a
+// user's avatar refers to. It walks the configured prefixes page by page and
+// remembers where it stopped, so a run cut short by its budget is resumed by
+// the next one.
+type OrphanCleaner struct {
+	fileRepo   repository.FileRepository
+	userRepo   repository.UserRepository
+	storage    storage.ObjectStorage
+	cache      cache.Cache
+	keyBuilder *cache.CacheKeyBuilder
+	cfg        config.OrphanCleanupConfig
+	clock      clock.Clock
+}
+
+func NewOrphanCleaner(
+	fileRepo repository.FileRepository,
+	userRepo repository.UserRepository,
+	objectStorage storage.ObjectStorage,
+	c cache.Cache,
+	kb *cache.CacheKeyBuilder,
+	cfg config.OrphanCleanupConfig,
+	clk clock.Clock,
+) *OrphanCleaner {
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = defaultOrphanPageSize
+	}
+	if cfg.Budget <= 0 {
+		cfg.Budget = defaultOrphanBudget
+	}
+
+	return &OrphanCleaner{
+		fileRepo:   fileRepo,
+		userRepo:   userRepo,
+		storage:    objectStorage,
+		cache:      c,
+		keyBuilder: kb,
+		cfg:        cfg,
+		clock:      clk,
+	}
+}
+
+// Run is the scheduler job. Without object storage there is nothing to do.
+func (c *OrphanCleaner) Run(ctx context.Context) error {
+	report, err := c.Clean(ctx)
+	if errors.Is(err, storage.ErrNotConfigured) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	verb, removed := "deleted", report.Deleted
+	if report.DryRun {
+		verb, removed = "would delete", report.Orphans
+	}
+	log.Printf("Orphaned uploads: scanned %d objects, found %d orphans, %s %d (%d bytes), complete %v",
+		report.Scanned, report.Orphans, verb, removed, report.Bytes, report.Complete)
+	return nil
+}
+
+// Clean scans for orphans until every prefix is done or the budget runs out.
+func (c *OrphanCleaner) Clean(ctx context.Context) (*OrphanReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Budget)
+	defer cancel()
+
+	report := &OrphanReport{DryRun: !c.cfg.Delete, Complete: true}
+	for _, prefix := range c.cfg.Prefixes {
+		done, err := c.cleanPrefix(ctx, prefix, report)
+		if err != nil {
+			return nil, err
+		}
+		if !done {
+			report.Complete = false
+			break
+		}
+	}
+
+	orphanMetrics.Add("runs", 1)
+	orphanMetrics.Add("objects_scanned", int64(report.Scanned))
+	orphanMetrics.Add("orphans_found", int64(report.Orphans))
+	orphanMetrics.Add("objects_deleted", int64(report.Deleted))
+	if !report.DryRun {
+		orphanMetrics.Add("bytes_reclaimed", report.Bytes)
+	}
+	return report, nil
+}
+
+// cleanPrefix processes pages of prefix from its saved cursor and reports
+// whether it reached the end of the listing.
+func (c *OrphanCleaner) cleanPrefix(ctx context.Context, prefix string, report *OrphanReport) (bool, error) {
+	cursorKey := c.keyBuilder.OrphanCleanupCursor(prefix)
+	cursor, err := c.cache.Get(ctx, cursorKey)
+	if err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+		return false, err
+	}
+
+	cutoff := c.clock.Now().Add(-c.cfg.MinAge)
+	for {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+
+		objects, err := c.storage.List(ctx, prefix, cursor, c.cfg.PageSize)
+		if ctx.Err() != nil {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if len(objects) == 0 {
+			// Start over from the beginning next time
+			return true, c.cache.Delete(ctx, cursorKey)
+		}
+
+		if err := c.cleanPage(ctx, objects, cutoff, report); err != nil {
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			return false, err
+		}
+
+		cursor = objects[len(objects)-1].Key
+		if err := c.cache.Set(ctx, cursorKey, cursor, 0); err != nil {
+			return false, err
+		}
+	}
+}
+
+func (c *OrphanCleaner) cleanPage(ctx context.Context, objects []storage.ObjectInfo, cutoff time.Time, report *OrphanReport) error {
+	keys := make([]string, 0, len(objects))
+	urls := make([]string, 0, len(objects))
+	// Only the largest variant of an uploaded avatar is the avatar URL, so
+	// variants are matched by the prefix they share
+	prefixes := make([]string, len(objects))
+	for i, obj := range objects {
+		keys = append(keys, obj.Key)
+		urls = append(urls, c.storage.URL(obj.Key))
+		prefixes[i], _ = avatarPrefix(obj.Key)
+	}
+
+	files, err := c.fileRepo.StoragePathsInUse(ctx, keys)
+	if err != nil {
+		return err
+	}
+	avatars, err := c.userRepo.AvatarURLsInUse(ctx, urls)
+	if err != nil {
+		return err
+	}
+	uploadedAvatars, err := c.userRepo.AvatarKeysInUse(ctx, nonEmpty(prefixes))
+	if err != nil {
+		return err
+	}
+
+	report.Scanned += len(objects)
+	for i, obj := range objects {
+		if files[obj.Key] || avatars[urls[i]] || uploadedAvatars[prefixes[i]] || obj.LastModified.After(cutoff) {
+			continue
+		}
+
+		report.Orphans++
+		if report.DryRun {
+			log.Printf("Orphaned upload %s (%d bytes, modified %s) would be deleted",
+				obj.Key, obj.Size, obj.LastModified.Format(time.RFC3339))
+			report.Bytes += obj.Size
+			continue
+		}
+
+		if err := c.storage.Delete(ctx, obj.Key); err != nil {
+			log.Printf("Failed to delete orphaned upload %s: %v", obj.Key, err)
+			continue
+		}
+		report.Deleted++
+		report.Bytes += obj.Size
+	}
+	return nil
+}
+
+func nonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
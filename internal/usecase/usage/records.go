@@ -0,0 +1,148 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+// flushKey identifies one user's monthly hash.
+type flushKey struct {
+	period string
+	userID string
+}
+
+// restore adds the stored count of a feature to a monthly counter that just
+// started from zero. That is usually the first use of the month, with
+// nothing stored yet, but after the cache lost its counters it keeps the
+// quota from starting over. Only the caller that created the counter gets
+// here, so the stored count is added once.
+func (uc *usageUseCase) restore(ctx context.Context, key, userID, period, feature string, count int64) int64 {
+	if uc.records == nil {
+		return count
+	}
+
+	stored, err := uc.records.ListByUser(ctx, userID, period)
+	if err != nil {
+		log.Printf("Failed to restore %s usage of user %s: %v", feature, userID, err)
+		return count
+	}
+	for _, record := range stored {
+		if record.Category != feature || record.Count <= 0 {
+			continue
+		}
+		restored, err := uc.cache.HIncrBy(ctx, key, feature, record.Count)
+		if err != nil {
+			log.Printf("Failed to restore %s usage of user %s: %v", feature, userID, err)
+			return count
+		}
+		return restored
+	}
+	return count
+}
+
+// Flush writes totals rather than increments, and a record keeps the
+// higher of its stored and flushed count. Flushing the same counters twice,
+// from two instances or after a restart, therefore never counts anything
+// twice. The previous month is flushed too, so uses counted just before the
+// month turned aren't lost.
+func (uc *usageUseCase) Flush(ctx context.Context) error {
+	if uc.records == nil {
+		return nil
+	}
+
+	_, monthly := uc.windows()
+	start := monthly.resetAt.AddDate(0, -1, 0)
+	periods := []string{start.AddDate(0, -1, 0).Format("2006-01"), monthly.period}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	// Months no longer flushed need no memory of what was written
+	for key := range uc.flushed {
+		if key.period != periods[0] && key.period != periods[1] {
+			delete(uc.flushed, key)
+		}
+	}
+
+	var errs []error
+	for _, period := range periods {
+		if err := uc.flushPeriod(ctx, period); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (uc *usageUseCase) flushPeriod(ctx context.Context, period string) error {
+	users, err := uc.cache.SMembers(ctx, uc.keyBuilder.UsageUsers(period))
+	if err != nil {
+		return err
+	}
+
+	flushed := 0
+	for _, userID := range users {
+		changed, err := uc.flushUser(ctx, userID, period)
+		if err != nil {
+			return fmt.Errorf("failed to flush usage of user %s: %w", userID, err)
+		}
+		if changed {
+			flushed++
+		}
+	}
+
+	if flushed > 0 {
+		log.Printf("Flushed %s usage of %d users", period, flushed)
+	}
+	return nil
+}
+
+// flushUser saves the user's counts that differ from the last flush and
+// reports whether there were any.
+func (uc *usageUseCase) flushUser(ctx context.Context, userID, period string) (bool, error) {
+	live, err := uc.cache.HGetAll(ctx, uc.keyBuilder.UsageMonth(userID, period))
+	if errors.Is(err, cache.ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	key := flushKey{period: period, userID: userID}
+	last := uc.flushed[key]
+
+	now := uc.clock.Now()
+	counts := make(map[string]int64, len(live))
+	var records []*domain.UsageRecord
+	for feature, raw := range live {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		counts[feature] = n
+		if prev, ok := last[feature]; ok && prev == n {
+			continue
+		}
+		records = append(records, &domain.UsageRecord{
+			UserID:    userID,
+			Period:    period,
+			Category:  feature,
+			Count:     n,
+			UpdatedAt: now,
+		})
+	}
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	if err := uc.records.Save(ctx, records); err != nil {
+		return false, err
+	}
+	uc.flushed[key] = counts
+	return true, nil
+}
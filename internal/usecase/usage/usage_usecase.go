@@ -0,0 +1,349 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+// Windows usage is counted in. Both start at midnight UTC, so a counter
+// resets by its key expiring at the window boundary.
+//
+// The daily counters are plain keys, one per user and feature. The monthly
+// ones are a hash per user and month, with a field per feature; Flush
+// copies them to usage records so they survive losing the cache.
+const (
+	WindowDaily   = "daily"
+	WindowMonthly = "monthly"
+)
+
+// expirySlack keeps a counter around slightly past its window so a read at
+// the boundary never races the expiry.
+const expirySlack = time.Minute
+
+// monthRetention keeps a month's counters for a while after the month
+// ends, so the last flush of the month can still read them.
+const monthRetention = 7 * 24 * time.Hour
+
+type UsageUseCase interface {
+	// IncrUsage counts one use of feature by the user in every window
+	IncrUsage(ctx context.Context, userID, feature string) (*Usage, error)
+
+	// AddUsage counts n units of feature, such as tokens, in every window
+	AddUsage(ctx context.Context, userID, feature string, n int64) (*Usage, error)
+
+	// ConsumeQuota counts one use of feature unless the user already reached
+	// limit today. A limit of 0 means unlimited.
+	ConsumeQuota(ctx context.Context, userID, feature string, limit int64) (*QuotaResult, error)
+
+	// ConsumeMonthlyQuota counts one use of feature unless the user already
+	// reached limit this month. A limit of 0 means unlimited.
+	ConsumeMonthlyQuota(ctx context.Context, userID, feature string, limit int64) (*QuotaResult, error)
+
+	// GetUsage returns the user's current counts for every feature they used
+	// this month. Features in monthlyLimits are listed even when unused,
+	// with their limit and what remains of it.
+	GetUsage(ctx context.Context, userID string, monthlyLimits map[string]int64) ([]Usage, error)
+
+	// Flush copies the monthly counts that changed since the last flush to
+	// the usage records
+	Flush(ctx context.Context) error
+}
+
+// Usage is a user's count for one feature in the current windows.
+type Usage struct {
+	Feature string `json:"feature"`
+	Daily   int64  `json:"daily"`
+	Monthly int64  `json:"monthly"`
+	// MonthlyLimit is the user's monthly quota of the feature; 0 is unlimited
+	MonthlyLimit int64 `json:"monthly_limit"`
+	// MonthlyRemaining is omitted for unlimited features
+	MonthlyRemaining *int64    `json:"monthly_remaining,omitempty"`
+	DailyResetsAt    time.Time `json:"daily_resets_at"`
+	MonthlyResetsAt  time.Time `json:"monthly_resets_at"`
+}
+
+// QuotaResult describes a quota check. Remaining is 0 when Limit is 0
+// (unlimited).
+type QuotaResult struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	ResetAt   time.Time
+}
+
+type usageUseCase struct {
+	cache      cache.Cache
+	keyBuilder *cache.CacheKeyBuilder
+	records    repository.UsageRecordRepository
+	clock      clock.Clock
+
+	// flushed holds the monthly counts last written to the records, so a
+	// flush skips users whose usage didn't change
+	mu      sync.Mutex
+	flushed map[flushKey]map[string]int64
+}
+
+// NewUsageUseCase creates the usage use case. records may be nil, e.g.
+// without a database, in which case the counts only live in the cache.
+func NewUsageUseCase(c cache.Cache, kb *cache.CacheKeyBuilder, records repository.UsageRecordRepository, clk clock.Clock) UsageUseCase {
+	return &usageUseCase{
+		cache:      c,
+		keyBuilder: kb,
+		records:    records,
+		clock:      clk,
+		flushed:    make(map[flushKey]map[string]int64),
+	}
+}
+
+// window identifies one counting period, e.g. daily 2026-10-16.
+type window struct {
+	name    string
+	period  string
+	resetAt time.Time
+}
+
+func (uc *usageUseCase) windows() (daily, monthly window) {
+	now := uc.clock.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	daily = window{name: WindowDaily, period: day.Format("2006-01-02"), resetAt: day.AddDate(0, 0, 1)}
+	monthly = window{name: WindowMonthly, period: month.Format("2006-01"), resetAt: month.AddDate(0, 1, 0)}
+	return daily, monthly
+}
+
+func (uc *usageUseCase) IncrUsage(ctx context.Context, userID, feature string) (*Usage, error) {
+	return uc.AddUsage(ctx, userID, feature, 1)
+}
+
+func (uc *usageUseCase) AddUsage(ctx context.Context, userID, feature string, n int64) (*Usage, error) {
+	daily, monthly := uc.windows()
+
+	usage := &Usage{
+		Feature:         feature,
+		DailyResetsAt:   daily.resetAt,
+		MonthlyResetsAt: monthly.resetAt,
+	}
+
+	var err error
+	if usage.Daily, err = uc.incr(ctx, userID, feature, daily, n); err != nil {
+		return nil, err
+	}
+	if usage.Monthly, err = uc.incrMonthly(ctx, userID, feature, monthly, n); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// ConsumeQuota relies on INCR returning a distinct value to every concurrent
+// caller: exactly limit callers see a count within the limit, so the quota
+// can't be overrun. Rejected calls are taken back out so they don't count.
+func (uc *usageUseCase) ConsumeQuota(ctx context.Context, userID, feature string, limit int64) (*QuotaResult, error) {
+	daily, monthly := uc.windows()
+	result := &QuotaResult{Limit: limit, ResetAt: daily.resetAt}
+
+	count, err := uc.incr(ctx, userID, feature, daily, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && count > limit {
+		key := uc.keyBuilder.Usage(userID, feature, daily.name, daily.period)
+		if _, err := uc.cache.Decrement(ctx, key); err != nil {
+			return nil, fmt.Errorf("failed to release %s usage: %w", feature, err)
+		}
+		return result, nil
+	}
+
+	if _, err := uc.incrMonthly(ctx, userID, feature, monthly, 1); err != nil {
+		return nil, err
+	}
+
+	result.Allowed = true
+	if limit > 0 {
+		result.Remaining = limit - count
+	}
+	return result, nil
+}
+
+// ConsumeMonthlyQuota works like ConsumeQuota on the monthly counter.
+func (uc *usageUseCase) ConsumeMonthlyQuota(ctx context.Context, userID, feature string, limit int64) (*QuotaResult, error) {
+	daily, monthly := uc.windows()
+	result := &QuotaResult{Limit: limit, ResetAt: monthly.resetAt}
+
+	count, err := uc.incrMonthly(ctx, userID, feature, monthly, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && count > limit {
+		key := uc.keyBuilder.UsageMonth(userID, monthly.period)
+		if _, err := uc.cache.HIncrBy(ctx, key, feature, -1); err != nil {
+			return nil, fmt.Errorf("failed to release %s usage: %w", feature, err)
+		}
+		return result, nil
+	}
+
+	if _, err := uc.incr(ctx, userID, feature, daily, 1); err != nil {
+		return nil, err
+	}
+
+	result.Allowed = true
+	if limit > 0 {
+		result.Remaining = limit - count
+	}
+	return result, nil
+}
+
+// incrMonthly adds n to the feature's field of the user's monthly hash. The
+// first use of a feature in the month sets the expiry, lists the user for
+// Flush and restores the stored count, so those cost nothing on later uses.
+func (uc *usageUseCase) incrMonthly(ctx context.Context, userID, feature string, monthly window, n int64) (int64, error) {
+	key := uc.keyBuilder.UsageMonth(userID, monthly.period)
+
+	count, err := uc.cache.HIncrBy(ctx, key, feature, n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count %s usage: %w", feature, err)
+	}
+	if count != n {
+		return count, nil
+	}
+
+	ttl := monthly.resetAt.Sub(uc.clock.Now()) + monthRetention
+	if err := uc.cache.Expire(ctx, key, ttl); err != nil {
+		return 0, err
+	}
+	usersKey := uc.keyBuilder.UsageUsers(monthly.period)
+	if err := uc.cache.SAdd(ctx, usersKey, userID); err != nil {
+		return 0, err
+	}
+	if err := uc.cache.Expire(ctx, usersKey, ttl); err != nil {
+		return 0, err
+	}
+
+	return uc.restore(ctx, key, userID, monthly.period, feature, count), nil
+}
+
+// incr adds n to one counter. INCRBY is atomic, so concurrent requests never
+// lose a count; the first increment of a window sets the expiry.
+func (uc *usageUseCase) incr(ctx context.Context, userID, feature string, w window, n int64) (int64, error) {
+	key := uc.keyBuilder.Usage(userID, feature, w.name, w.period)
+
+	count, err := uc.cache.IncrementBy(ctx, key, n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count %s usage: %w", feature, err)
+	}
+	if count == n {
+		if err := uc.cache.Expire(ctx, key, uc.ttl(w)); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+func (uc *usageUseCase) ttl(w window) time.Duration {
+	return w.resetAt.Sub(uc.clock.Now()) + expirySlack
+}
+
+func (uc *usageUseCase) GetUsage(ctx context.Context, userID string, monthlyLimits map[string]int64) ([]Usage, error) {
+	daily, monthly := uc.windows()
+
+	counts, err := uc.monthlyCounts(ctx, userID, monthly.period)
+	if err != nil {
+		return nil, err
+	}
+	for feature := range monthlyLimits {
+		if _, ok := counts[feature]; !ok {
+			counts[feature] = 0
+		}
+	}
+	if len(counts) == 0 {
+		return []Usage{}, nil
+	}
+
+	features := make([]string, 0, len(counts))
+	for feature := range counts {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	keys := make([]string, 0, len(features))
+	for _, feature := range features {
+		keys = append(keys, uc.keyBuilder.Usage(userID, feature, daily.name, daily.period))
+	}
+
+	values, err := uc.cache.MGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]Usage, 0, len(features))
+	for i, feature := range features {
+		u := Usage{
+			Feature:         feature,
+			Daily:           parseCount(values[i]),
+			Monthly:         counts[feature],
+			MonthlyLimit:    monthlyLimits[feature],
+			DailyResetsAt:   daily.resetAt,
+			MonthlyResetsAt: monthly.resetAt,
+		}
+		if u.MonthlyLimit > 0 {
+			remaining := max(u.MonthlyLimit-u.Monthly, 0)
+			u.MonthlyRemaining = &remaining
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, nil
+}
+
+// monthlyCounts reads the user's monthly hash. A count is the higher of the
+// live and the stored one, so it stays right after the cache lost its
+// counters.
+func (uc *usageUseCase) monthlyCounts(ctx context.Context, userID, period string) (map[string]int64, error) {
+	live, err := uc.cache.HGetAll(ctx, uc.keyBuilder.UsageMonth(userID, period))
+	if err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(live))
+	for feature, raw := range live {
+		n, _ := strconv.ParseInt(raw, 10, 64)
+		counts[feature] = n
+	}
+
+	if uc.records != nil {
+		stored, err := uc.records.ListByUser(ctx, userID, period)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range stored {
+			if record.Count > counts[record.Category] {
+				counts[record.Category] = record.Count
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// parseCount reads an MGET value; missing keys come back as nil.
+func parseCount(v any) int64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
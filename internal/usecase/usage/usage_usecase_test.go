@@ -0,0 +1,357 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+)
+
+// memoryRecords keeps usage records like the Postgres repository: a saved
+// count only replaces a lower one.
+type memoryRecords struct {
+	mu     sync.Mutex
+	counts map[flushKey]map[string]int64
+	saves  int
+}
+
+func newMemoryRecords() *memoryRecords {
+	return &memoryRecords{counts: make(map[flushKey]map[string]int64)}
+}
+
+func (r *memoryRecords) Save(_ context.Context, records []*domain.UsageRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.saves++
+	for _, record := range records {
+		key := flushKey{period: record.Period, userID: record.UserID}
+		if r.counts[key] == nil {
+			r.counts[key] = make(map[string]int64)
+		}
+		r.counts[key][record.Category] = max(r.counts[key][record.Category], record.Count)
+	}
+	return nil
+}
+
+func (r *memoryRecords) ListByUser(_ context.Context, userID, period string) ([]*domain.UsageRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []*domain.UsageRecord
+	for category, count := range r.counts[flushKey{period: period, userID: userID}] {
+		records = append(records, &domain.UsageRecord{UserID: userID, Period: period, Category: category, Count: count})
+	}
+	return records, nil
+}
+
+func (r *memoryRecords) count(userID, period, category string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[flushKey{period: period, userID: userID}][category]
+}
+
+func newTestCache(t *testing.T) cache.Cache {
+	t.Helper()
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func newTestUsage(c cache.Cache, records *memoryRecords, clk clock.Clock) UsageUseCase {
+	if records == nil {
+		return NewUsageUseCase(c, cache.NewCacheKeyBuilder("test"), nil, clk)
+	}
+	return NewUsageUseCase(c, cache.NewCacheKeyBuilder("test"), records, clk)
+}
+
+func TestUsageWindowsReset(t *testing.T) {
+	tests := []struct {
+		name        string
+		advance     time.Duration
+		wantDaily   int64
+		wantMonthly int64
+	}{
+		{"same day", time.Hour, 3, 3},
+		{"next day", 24 * time.Hour, 1, 3},
+		{"next month", 31 * 24 * time.Hour, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+			uc := newTestUsage(newTestCache(t), nil, clk)
+			ctx := context.Background()
+
+			for range 2 {
+				if _, err := uc.IncrUsage(ctx, "user-1", "chat"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			clk.Advance(tt.advance)
+
+			got, err := uc.IncrUsage(ctx, "user-1", "chat")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Daily != tt.wantDaily || got.Monthly != tt.wantMonthly {
+				t.Fatalf("daily, monthly = %d, %d, want %d, %d", got.Daily, got.Monthly, tt.wantDaily, tt.wantMonthly)
+			}
+		})
+	}
+}
+
+func TestUsageWindowBoundaries(t *testing.T) {
+	tests := []struct {
+		name             string
+		now              time.Time
+		wantDailyReset   time.Time
+		wantMonthlyReset time.Time
+	}{
+		{
+			name:             "mid month",
+			now:              time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+			wantDailyReset:   time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+			wantMonthlyReset: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:             "last second of the month",
+			now:              time.Date(2026, 10, 31, 23, 59, 59, 0, time.UTC),
+			wantDailyReset:   time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			wantMonthlyReset: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:             "end of year",
+			now:              time.Date(2026, 12, 31, 8, 0, 0, 0, time.UTC),
+			wantDailyReset:   time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantMonthlyReset: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:             "windows follow UTC, not local time",
+			now:              time.Date(2026, 10, 18, 1, 0, 0, 0, time.FixedZone("WIB", 7*60*60)),
+			wantDailyReset:   time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+			wantMonthlyReset: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCache(t)
+			uc := newTestUsage(c, nil, clock.NewMock(tt.now))
+			ctx := context.Background()
+
+			got, err := uc.IncrUsage(ctx, "user-1", "chat")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.DailyResetsAt.Equal(tt.wantDailyReset) || !got.MonthlyResetsAt.Equal(tt.wantMonthlyReset) {
+				t.Errorf("resets at %v, %v, want %v, %v", got.DailyResetsAt, got.MonthlyResetsAt, tt.wantDailyReset, tt.wantMonthlyReset)
+			}
+
+			// The counters expire when their window ends, so they reset
+			// without a job clearing them
+			kb := cache.NewCacheKeyBuilder("test")
+			daily := tt.now.UTC().Format("2006-01-02")
+			month := tt.now.UTC().Format("2006-01")
+			ttls := []struct {
+				key  string
+				want time.Duration
+			}{
+				{kb.Usage("user-1", "chat", WindowDaily, daily), tt.wantDailyReset.Sub(tt.now) + expirySlack},
+				{kb.UsageMonth("user-1", month), tt.wantMonthlyReset.Sub(tt.now) + monthRetention},
+			}
+			for _, ttl := range ttls {
+				got, err := c.TTL(ctx, ttl.key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got > ttl.want || got < ttl.want-time.Minute {
+					t.Errorf("TTL(%s) = %v, want %v", ttl.key, got, ttl.want)
+				}
+			}
+		})
+	}
+}
+
+func TestConsumeQuotaConcurrent(t *testing.T) {
+	tests := []struct {
+		name    string
+		consume func(UsageUseCase) (*QuotaResult, error)
+	}{
+		{"daily", func(uc UsageUseCase) (*QuotaResult, error) {
+			return uc.ConsumeQuota(context.Background(), "user-1", "chat", 5)
+		}},
+		{"monthly", func(uc UsageUseCase) (*QuotaResult, error) {
+			return uc.ConsumeMonthlyQuota(context.Background(), "user-1", domain.UsageAICalls, 5)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newTestUsage(newTestCache(t), nil, clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+
+			const callers = 20
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				allowed int
+			)
+			for range callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					result, err := tt.consume(uc)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if result.Allowed {
+						mu.Lock()
+						allowed++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			if allowed != 5 {
+				t.Fatalf("%d of %d calls allowed, want 5", allowed, callers)
+			}
+			// Rejected calls aren't counted
+			usage, err := uc.GetUsage(context.Background(), "user-1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(usage) != 1 || usage[0].Daily != 5 || usage[0].Monthly != 5 {
+				t.Fatalf("usage = %+v, want 5 uses in both windows", usage)
+			}
+		})
+	}
+}
+
+func TestGetUsageListsLimits(t *testing.T) {
+	uc := newTestUsage(newTestCache(t), nil, clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)))
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := uc.IncrUsage(ctx, "user-1", domain.UsageAICalls); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage, err := uc.GetUsage(ctx, "user-1", map[string]int64{domain.UsageAICalls: 2, domain.UsageUploads: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		feature       string
+		wantMonthly   int64
+		wantRemaining int64
+	}{
+		{domain.UsageAICalls, 3, 0},
+		{domain.UsageUploads, 0, 10},
+	}
+	if len(usage) != len(tests) {
+		t.Fatalf("got %d features, want %d", len(usage), len(tests))
+	}
+	for i, tt := range tests {
+		u := usage[i]
+		if u.Feature != tt.feature || u.Monthly != tt.wantMonthly || u.MonthlyRemaining == nil || *u.MonthlyRemaining != tt.wantRemaining {
+			t.Errorf("usage[%d] = %+v, want %s with %d used and %d remaining", i, u, tt.feature, tt.wantMonthly, tt.wantRemaining)
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	const period = "2026-10"
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	record := func(t *testing.T, uc UsageUseCase, n int) {
+		t.Helper()
+		for range n {
+			if _, err := uc.IncrUsage(context.Background(), "user-1", domain.UsageAICalls); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	flush := func(t *testing.T, uc UsageUseCase) {
+		t.Helper()
+		if err := uc.Flush(context.Background()); err != nil {
+			t.Fatalf("Flush() = %v", err)
+		}
+	}
+
+	t.Run("unchanged counts aren't written again", func(t *testing.T) {
+		records := newMemoryRecords()
+		uc := newTestUsage(newTestCache(t), records, clock.NewMock(start))
+
+		record(t, uc, 3)
+		flush(t, uc)
+		flush(t, uc)
+		if records.saves != 1 {
+			t.Fatalf("%d saves, want 1", records.saves)
+		}
+		if got := records.count("user-1", period, domain.UsageAICalls); got != 3 {
+			t.Fatalf("stored count = %d, want 3", got)
+		}
+	})
+
+	t.Run("restart with the cache intact", func(t *testing.T) {
+		records := newMemoryRecords()
+		c := newTestCache(t)
+		clk := clock.NewMock(start)
+
+		before := newTestUsage(c, records, clk)
+		record(t, before, 3)
+		flush(t, before)
+
+		// A new instance has no memory of the last flush and writes the
+		// same totals again
+		after := newTestUsage(c, records, clk)
+		record(t, after, 1)
+		flush(t, after)
+		if got := records.count("user-1", period, domain.UsageAICalls); got != 4 {
+			t.Fatalf("stored count = %d, want 4", got)
+		}
+	})
+
+	t.Run("restart with the cache lost", func(t *testing.T) {
+		records := newMemoryRecords()
+		clk := clock.NewMock(start)
+
+		before := newTestUsage(newTestCache(t), records, clk)
+		record(t, before, 3)
+		flush(t, before)
+
+		after := newTestUsage(newTestCache(t), records, clk)
+		got, err := after.IncrUsage(context.Background(), "user-1", domain.UsageAICalls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Monthly != 4 {
+			t.Fatalf("monthly count after losing the cache = %d, want 4", got.Monthly)
+		}
+		flush(t, after)
+		if got := records.count("user-1", period, domain.UsageAICalls); got != 4 {
+			t.Fatalf("stored count = %d, want 4", got)
+		}
+	})
+
+	t.Run("previous month after the turn", func(t *testing.T) {
+		records := newMemoryRecords()
+		clk := clock.NewMock(time.Date(2026, 10, 31, 23, 59, 0, 0, time.UTC))
+		uc := newTestUsage(newTestCache(t), records, clk)
+
+		record(t, uc, 2)
+		clk.Advance(time.Hour)
+		flush(t, uc)
+		if got := records.count("user-1", period, domain.UsageAICalls); got != 2 {
+			t.Fatalf("stored October count = %d, want 2", got)
+		}
+	})
+}
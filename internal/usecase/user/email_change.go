@@ -0,0 +1,162 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mail"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+)
+
+type EmailChangeRequest struct {
+	UserID    string
+	NewEmail  string
+	IPAddress string
+	UserAgent string
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token     string
+	IPAddress string
+	UserAgent string
+}
+
+// emailChange is the payload stored with the confirmation token. OldEmail
+// guards against the token outliving another change of the same account.
+type emailChange struct {
+	UserID   string `json:"user_id"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+}
+
+// RequestEmailChange sends a confirmation link to the new address. The
+// account keeps its current email until the link is used, and requesting
+// again invalidates the previous link.
+func (uc *userUseCase) RequestEmailChange(ctx context.Context, req EmailChangeRequest) error {
+	if !uc.emailChange.Enabled {
+		return ErrEmailChangeDisabled
+	}
+
+	newEmail, err := auth.NormalizeEmail(req.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return ErrEmailUnchanged
+	}
+
+	exists, err := uc.userRepo.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrEmailTaken
+	}
+
+	if err := uc.emailThrottle.Allow(ctx, auth.EmailKindVerification, newEmail); err != nil {
+		return err
+	}
+
+	confirmURL, err := uc.links.Validate(uc.emailChange.ConfirmURL)
+	if err != nil {
+		return fmt.Errorf("invalid email change confirm url: %w", err)
+	}
+
+	token, err := uc.verifications.Issue(ctx, auth.VerificationEmailChange, user.ID, emailChange{
+		UserID:   user.ID,
+		OldEmail: user.Email,
+		NewEmail: newEmail,
+	}, uc.emailChange.TokenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to issue email change token: %w", err)
+	}
+
+	link, err := withToken(confirmURL, token)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.mailer.Enqueue(ctx, newEmail, mail.EmailChangeData{
+		Name:       user.Name,
+		NewEmail:   newEmail,
+		ConfirmURL: link,
+		ExpiresIn:  uc.emailChange.TokenTTL,
+	}); err != nil {
+		return fmt.Errorf("failed to send email change confirmation: %w", err)
+	}
+
+	uc.writeAudit(ctx, "user.email_change_requested", &user.ID, user.ID, req.IPAddress, req.UserAgent, map[string]any{
+		"new_email": newEmail,
+	})
+
+	return nil
+}
+
+// ConfirmEmailChange applies the change a token was issued for and marks the
+// new address as verified.
+func (uc *userUseCase) ConfirmEmailChange(ctx context.Context, req ConfirmEmailChangeRequest) (*domain.User, error) {
+	if !uc.emailChange.Enabled {
+		return nil, ErrEmailChangeDisabled
+	}
+
+	var change emailChange
+	if err := uc.verifications.Consume(ctx, auth.VerificationEmailChange, req.Token, &change); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, change.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email != change.OldEmail {
+		return nil, auth.ErrInvalidVerificationToken
+	}
+
+	// The address may have been registered while the link was pending
+	exists, err := uc.userRepo.ExistsByEmail(ctx, change.NewEmail)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrEmailTaken
+	}
+
+	// Drop the cache entries under the old address before it changes
+	uc.invalidateUser(ctx, user)
+
+	now := uc.clock.Now()
+	user.Email = change.NewEmail
+	user.EmailVerifiedAt = &now
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	uc.writeAudit(ctx, "user.email_changed", &user.ID, user.ID, req.IPAddress, req.UserAgent, map[string]any{
+		"old_email": change.OldEmail,
+		"new_email": change.NewEmail,
+	})
+	uc.publish(ctx, domain.EventUserVerified, domain.UserVerifiedEvent{UserID: user.ID, Email: user.Email})
+
+	return user, nil
+}
+
+func withToken(target, token string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid confirm url: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
@@ -0,0 +1,244 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mail"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+)
+
+// recordingMailer keeps enqueued mail instead of sending it.
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []sentMail
+}
+
+type sentMail struct {
+	to   string
+	data mail.TemplateData
+}
+
+func (m *recordingMailer) Enqueue(ctx context.Context, to string, data mail.TemplateData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentMail{to: to, data: data})
+	return nil
+}
+
+func (m *recordingMailer) Requeue(ctx context.Context, job *mail.Job) error { return nil }
+
+func (m *recordingMailer) last(t *testing.T) sentMail {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		t.Fatal("no mail was sent")
+	}
+	return m.sent[len(m.sent)-1]
+}
+
+// enableEmailChange turns the flow on with a mailer the test can read links
+// from.
+func enableEmailChange(tu *testUsers) *recordingMailer {
+	mailer := &recordingMailer{}
+	tu.uc.mailer = mailer
+	tu.uc.links = auth.NewRedirectValidator(config.SecurityConfig{
+		DefaultRedirectURL:     "https://app.umkm.id",
+		RedirectAllowedOrigins: []string{"https://app.umkm.id"},
+	})
+	tu.uc.emailChange = config.EmailChangeConfig{
+		Enabled:    true,
+		TokenTTL:   time.Hour,
+		ConfirmURL: "/account/confirm-email",
+	}
+	return mailer
+}
+
+// confirmToken returns the token from the link in the last mail sent.
+func confirmToken(t *testing.T, mailer *recordingMailer) string {
+	t.Helper()
+	data, ok := mailer.last(t).data.(mail.EmailChangeData)
+	if !ok {
+		t.Fatalf("last mail is %T, want mail.EmailChangeData", mailer.last(t).data)
+	}
+	link, err := url.Parse(data.ConfirmURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Host != "app.umkm.id" || link.Path != "/account/confirm-email" {
+		t.Errorf("confirm link = %s, want it on the configured page", data.ConfirmURL)
+	}
+	return link.Query().Get("token")
+}
+
+func TestRequestEmailChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled bool
+		newEmail string
+		wantErr  error
+		// wantTo is the normalized address the link is sent to
+		wantTo string
+	}{
+		{"pending change", false, "budi.new@example.com", nil, "budi.new@example.com"},
+		{"normalized", false, "  Budi.New@Example.COM ", nil, "budi.new@example.com"},
+		{"taken", false, "siti@example.com", ErrEmailTaken, ""},
+		{"taken in another case", false, "SITI@example.com", ErrEmailTaken, ""},
+		{"unchanged", false, "Budi@Example.com", ErrEmailUnchanged, ""},
+		{"invalid", false, "not-an-email", auth.ErrInvalidEmail, ""},
+		{"disabled", true, "budi.new@example.com", ErrEmailChangeDisabled, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := newTestUsers(t)
+			mailer := enableEmailChange(tu)
+			tu.uc.emailChange.Enabled = !tt.disabled
+			user, _ := tu.addUser(t, "budi@example.com")
+			tu.addUser(t, "siti@example.com")
+			ctx := context.Background()
+
+			err := tu.uc.RequestEmailChange(ctx, EmailChangeRequest{UserID: user.ID, NewEmail: tt.newEmail})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RequestEmailChange() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// The old address stays active until the link is used
+			current, err := tu.uc.userRepo.FindByID(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if current.Email != "budi@example.com" || current.EmailVerifiedAt != nil {
+				t.Errorf("user = %s verified %v, want the old email unchanged", current.Email, current.EmailVerifiedAt)
+			}
+
+			if tt.wantTo == "" {
+				if len(mailer.sent) != 0 {
+					t.Errorf("sent %d mails, want none", len(mailer.sent))
+				}
+				return
+			}
+			if got := mailer.last(t).to; got != tt.wantTo {
+				t.Errorf("link sent to %q, want %q", got, tt.wantTo)
+			}
+			if confirmToken(t, mailer) == "" {
+				t.Error("confirm link has no token")
+			}
+		})
+	}
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	tu := newTestUsers(t)
+	mailer := enableEmailChange(tu)
+	user, _ := tu.addUser(t, "budi@example.com")
+	ctx := context.Background()
+
+	if err := tu.uc.RequestEmailChange(ctx, EmailChangeRequest{UserID: user.ID, NewEmail: "budi.old-link@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	superseded := confirmToken(t, mailer)
+	if err := tu.uc.RequestEmailChange(ctx, EmailChangeRequest{UserID: user.ID, NewEmail: "budi.new@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	token := confirmToken(t, mailer)
+
+	if _, err := tu.uc.ConfirmEmailChange(ctx, ConfirmEmailChangeRequest{Token: superseded}); !errors.Is(err, auth.ErrInvalidVerificationToken) {
+		t.Errorf("ConfirmEmailChange() with a superseded link error = %v, want %v", err, auth.ErrInvalidVerificationToken)
+	}
+
+	tu.clock.Advance(10 * time.Minute)
+	changed, err := tu.uc.ConfirmEmailChange(ctx, ConfirmEmailChangeRequest{Token: token})
+	if err != nil {
+		t.Fatalf("ConfirmEmailChange() error = %v", err)
+	}
+	if changed.Email != "budi.new@example.com" {
+		t.Errorf("Email = %q, want the new address", changed.Email)
+	}
+	if changed.EmailVerifiedAt == nil || !changed.EmailVerifiedAt.Equal(tu.clock.Now()) {
+		t.Errorf("EmailVerifiedAt = %v, want %v", changed.EmailVerifiedAt, tu.clock.Now())
+	}
+
+	if _, err := tu.uc.userRepo.FindByEmail(ctx, "budi@example.com"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("FindByEmail(old) error = %v, want %v", err, repository.ErrUserNotFound)
+	}
+	if found, err := tu.uc.userRepo.FindByEmail(ctx, "budi.new@example.com"); err != nil || found.ID != user.ID {
+		t.Errorf("FindByEmail(new) = %v, %v, want the user", found, err)
+	}
+
+	if _, err := tu.uc.ConfirmEmailChange(ctx, ConfirmEmailChangeRequest{Token: token}); !errors.Is(err, auth.ErrInvalidVerificationToken) {
+		t.Errorf("ConfirmEmailChange() reusing the link error = %v, want %v", err, auth.ErrInvalidVerificationToken)
+	}
+}
+
+func TestConfirmEmailChangeRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		// between runs after the link is sent and before it is used
+		between func(t *testing.T, tu *testUsers)
+		wantErr error
+	}{
+		{
+			name: "address registered meanwhile",
+			between: func(t *testing.T, tu *testUsers) {
+				tu.addUser(t, "budi.new@example.com")
+			},
+			wantErr: ErrEmailTaken,
+		},
+		{
+			name: "email changed another way",
+			between: func(t *testing.T, tu *testUsers) {
+				ctx := context.Background()
+				user, err := tu.uc.userRepo.FindByEmail(ctx, "budi@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+				user.Email = "budi.other@example.com"
+				if err := tu.uc.userRepo.Update(ctx, user); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: auth.ErrInvalidVerificationToken,
+		},
+		{
+			name: "disabled",
+			between: func(t *testing.T, tu *testUsers) {
+				tu.uc.emailChange.Enabled = false
+			},
+			wantErr: ErrEmailChangeDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := newTestUsers(t)
+			mailer := enableEmailChange(tu)
+			user, _ := tu.addUser(t, "budi@example.com")
+			ctx := context.Background()
+
+			if err := tu.uc.RequestEmailChange(ctx, EmailChangeRequest{UserID: user.ID, NewEmail: "budi.new@example.com"}); err != nil {
+				t.Fatal(err)
+			}
+			token := confirmToken(t, mailer)
+			tt.between(t, tu)
+
+			if _, err := tu.uc.ConfirmEmailChange(ctx, ConfirmEmailChangeRequest{Token: token}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmEmailChange() error = %v, want %v", err, tt.wantErr)
+			}
+			current, err := tu.uc.userRepo.FindByID(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if current.Email == "budi.new@example.com" {
+				t.Error("email was changed")
+			}
+		})
+	}
+}
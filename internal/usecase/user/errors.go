@@ -0,0 +1,21 @@
+package user
+
+import (
+	"errors"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+)
+
+var (
+	// ErrEmailChangeDisabled means email changes are turned off in the config
+	ErrEmailChangeDisabled = errors.New("email change is disabled")
+
+	// ErrEmailTaken means another account already uses the requested address
+	ErrEmailTaken = domain.ErrEmailTaken
+
+	// ErrEmailUnchanged means the requested address is the current one
+	ErrEmailUnchanged = errors.New("new email is the same as the current one")
+
+	// ErrPasswordResetDisabled means password resets are turned off in the config
+	ErrPasswordResetDisabled = errors.New("password reset is disabled")
+)
@@ -0,0 +1,175 @@
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mail"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+)
+
+// minPasswordLength matches the rule applied at registration
+const minPasswordLength = 8
+
+type PasswordResetRequest struct {
+	Email     string
+	IPAddress string
+	UserAgent string
+}
+
+type ResetPasswordRequest struct {
+	Token       string
+	NewPassword string
+	IPAddress   string
+	UserAgent   string
+}
+
+// passwordReset is the payload stored with the reset token. PasswordHash
+// fingerprints the password the token was issued against, so the token
+// stops working once the password changed some other way.
+type passwordReset struct {
+	UserID       string `json:"user_id"`
+	PasswordHash string `json:"password_hash"`
+}
+
+// RequestPasswordReset emails a reset link to the account with email, if
+// there is an active one. It succeeds either way so the response doesn't
+// reveal which addresses are registered; requesting again invalidates the
+// previous link. Too many requests for one address fail with
+// auth.ErrEmailThrottled.
+func (uc *userUseCase) RequestPasswordReset(ctx context.Context, req PasswordResetRequest) error {
+	if !uc.passwordReset.Enabled {
+		return ErrPasswordResetDisabled
+	}
+
+	email, err := auth.NormalizeEmail(req.Email)
+	if err != nil {
+		return err
+	}
+
+	// Counted before the lookup, so being throttled doesn't reveal whether
+	// the address has an account
+	if err := uc.emailThrottle.Allow(ctx, auth.EmailKindPasswordReset, email); err != nil {
+		return err
+	}
+
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	resetURL, err := uc.links.Validate(uc.passwordReset.ResetURL)
+	if err != nil {
+		return fmt.Errorf("invalid password reset url: %w", err)
+	}
+
+	token, err := uc.verifications.Issue(ctx, auth.VerificationPasswordReset, user.ID, passwordReset{
+		UserID:       user.ID,
+		PasswordHash: fingerprint(user.PasswordHash),
+	}, uc.passwordReset.TokenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to issue password reset token: %w", err)
+	}
+
+	link, err := withToken(resetURL, token)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.mailer.Enqueue(ctx, user.Email, mail.ResetPasswordData{
+		Name:      user.Name,
+		ResetURL:  link,
+		ExpiresIn: uc.passwordReset.TokenTTL,
+	}); err != nil {
+		return fmt.Errorf("failed to send password reset link: %w", err)
+	}
+
+	uc.writeAudit(ctx, "user.password_reset_requested", &user.ID, "", req.IPAddress, req.UserAgent, nil)
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user
+// out everywhere. A token works once: reusing it fails with
+// auth.ErrVerificationTokenUsed, even when two requests race.
+func (uc *userUseCase) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	if !uc.passwordReset.Enabled {
+		return ErrPasswordResetDisabled
+	}
+
+	// Checked before the token is spent, so a weak password can be retried
+	if len(req.NewPassword) < minPasswordLength {
+		return domain.NewValidationError(fmt.Sprintf("Password must be at least %d characters", minPasswordLength))
+	}
+
+	remember := uc.passwordReset.UsedTokenTTL
+	if remember <= 0 {
+		remember = uc.passwordReset.TokenTTL
+	}
+
+	// The token is only spent once the new password is stored; a failure
+	// before that leaves the link usable
+	var (
+		reset passwordReset
+		user  *domain.User
+	)
+	err := uc.verifications.ConsumeOnce(ctx, auth.VerificationPasswordReset, req.Token, &reset, remember, func() error {
+		var err error
+		user, err = uc.userRepo.FindByID(ctx, reset.UserID)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return auth.ErrInvalidVerificationToken
+		}
+		if err != nil {
+			return err
+		}
+		if !user.IsActive || fingerprint(user.PasswordHash) != reset.PasswordHash {
+			return auth.ErrInvalidVerificationToken
+		}
+
+		hash, err := uc.passwords.HashPassword(req.NewPassword)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = hash
+		return uc.userRepo.Update(ctx, user)
+	})
+	if errors.Is(err, auth.ErrVerificationTokenUsed) {
+		// The payload is gone with the token, so the entry can't name the user
+		uc.writeAudit(ctx, "user.password_reset_replayed", nil, "", req.IPAddress, req.UserAgent, nil)
+	}
+	if err != nil {
+		return err
+	}
+	uc.invalidateUser(ctx, user)
+
+	revoked, err := uc.sessions.RevokeAll(ctx, user.ID)
+	if err != nil {
+		log.Printf("Failed to revoke sessions of user %s after password reset: %v", user.ID, err)
+	}
+	if err := uc.sessions.RevokeAccessTokens(ctx, user.ID); err != nil {
+		log.Printf("Failed to revoke access tokens of user %s after password reset: %v", user.ID, err)
+	}
+
+	uc.writeAudit(ctx, "user.password_reset", &user.ID, user.ID, req.IPAddress, req.UserAgent, map[string]any{
+		"revoked_sessions": revoked,
+	})
+
+	return nil
+}
+
+func fingerprint(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+)
+
+// failingUpdateRepo fails every Update, like a database that went away
+// between the lookup and the write.
+type failingUpdateRepo struct {
+	repository.UserRepository
+}
+
+func (failingUpdateRepo) Update(context.Context, *domain.User) error {
+	return domain.ErrUnavailable
+}
+
+// issueReset creates a user and a reset token for them, as
+// RequestPasswordReset would.
+func issueReset(t *testing.T, tu *testUsers) (*domain.User, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	tu.uc.passwordReset = config.PasswordResetConfig{Enabled: true, TokenTTL: time.Hour}
+	user, _ := tu.addUser(t, "reset@example.com")
+	user.PasswordHash = "old-hash"
+	if err := tu.uc.userRepo.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tu.uc.verifications.Issue(ctx, auth.VerificationPasswordReset, user.ID, passwordReset{
+		UserID:       user.ID,
+		PasswordHash: fingerprint(user.PasswordHash),
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user, token
+}
+
+func TestResetPasswordKeepsTokenUntilApplied(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		failUpdate  bool
+		wantErr     error
+		wantChanged bool
+	}{
+		{"success", "new password 1", false, nil, true},
+		{"too short", "short", false, &domain.ValidationError{}, false},
+		{"update fails", "new password 1", true, domain.ErrUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := newTestUsers(t)
+			user, token := issueReset(t, tu)
+			ctx := context.Background()
+
+			uc := *tu.uc
+			if tt.failUpdate {
+				uc.userRepo = failingUpdateRepo{tu.uc.userRepo}
+			}
+			err := uc.ResetPassword(ctx, ResetPasswordRequest{Token: token, NewPassword: tt.password})
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("ResetPassword() = %v", err)
+				}
+			case *domain.ValidationError:
+				if !errors.As(err, &want) {
+					t.Fatalf("ResetPassword() = %v, want a validation error", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("ResetPassword() = %v, want %v", err, want)
+				}
+			}
+
+			stored, err := tu.uc.userRepo.FindByID(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed := stored.PasswordHash != "old-hash"; changed != tt.wantChanged {
+				t.Fatalf("password changed = %v, want %v", changed, tt.wantChanged)
+			}
+
+			// A spent token is reported as used; an unspent one still works
+			err = tu.uc.ResetPassword(ctx, ResetPasswordRequest{Token: token, NewPassword: "another password"})
+			if tt.wantChanged && !errors.Is(err, auth.ErrVerificationTokenUsed) {
+				t.Fatalf("second ResetPassword() = %v, want ErrVerificationTokenUsed", err)
+			}
+			if !tt.wantChanged && err != nil {
+				t.Fatalf("retry with the same token = %v, want success", err)
+			}
+		})
+	}
+}
+
+func TestResetPasswordConcurrent(t *testing.T) {
+	tu := newTestUsers(t)
+	_, token := issueReset(t, tu)
+
+	const callers = 5
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, callers)
+	)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = tu.uc.ResetPassword(context.Background(), ResetPasswordRequest{Token: token, NewPassword: "new password 1"})
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, auth.ErrVerificationTokenUsed):
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d resets succeeded with one token, want 1", succeeded)
+	}
+}
+
+func TestRequestPasswordResetThrottled(t *testing.T) {
+	tu := newTestUsers(t)
+	tu.uc.passwordReset = config.PasswordResetConfig{Enabled: true, TokenTTL: time.Hour}
+	tu.uc.emailThrottle = auth.NewEmailThrottle(tu.uc.cache, tu.uc.keyBuilder, config.EmailThrottleConfig{
+		PasswordReset: []config.EmailSendLimit{{Max: 2, Window: time.Hour}},
+	}, tu.clock)
+
+	// Unregistered, so nothing is sent, but the requests still count
+	steps := []struct {
+		advance time.Duration
+		email   string
+		wantErr error
+	}{
+		{0, "nobody@example.com", nil},
+		{0, " Nobody@Example.COM ", nil},
+		{0, "NOBODY@example.com", auth.ErrEmailThrottled},
+		{0, "someone@example.com", nil},
+		{time.Hour, "nobody@example.com", nil},
+	}
+	for i, step := range steps {
+		tu.clock.Advance(step.advance)
+		err := tu.uc.RequestPasswordReset(context.Background(), PasswordResetRequest{Email: step.email})
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("request %d for %q: err = %v, want %v", i, step.email, err, step.wantErr)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/mail"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	"github.com/go-playground/validator/v10"
+)
+
+// MaxBulkSize caps the number of IDs accepted by a single bulk operation.
+const MaxBulkSize = 100
+
+const (
+	BulkActionDelete     = "delete"
+	BulkActionDeactivate = "deactivate"
+)
+
+type UserUseCase interface {
+	Bulk(ctx context.Context, req BulkRequest) (*BulkResult, error)
+	RevokeSessions(ctx context.Context, req RevokeSessionsRequest) (*RevokeSessionsResult, error)
+	RequestEmailChange(ctx context.Context, req EmailChangeRequest) error
+	ConfirmEmailChange(ctx context.Context, req ConfirmEmailChangeRequest) (*domain.User, error)
+	RequestPasswordReset(ctx context.Context, req PasswordResetRequest) error
+	ResetPassword(ctx context.Context, req ResetPasswordRequest) error
+	DeleteAccount(ctx context.Context, req DeleteAccountRequest) error
+	// PurgeDeleted permanently removes users deleted more than retention ago
+	PurgeDeleted(ctx context.Context, retention time.Duration) error
+}
+
+type DeleteAccountRequest struct {
+	UserID    string
+	IPAddress string
+	UserAgent string
+}
+
+type BulkRequest struct {
+	ActorID   string
+	Action    string
+	IDs       []string
+	Atomic    bool
+	IPAddress string
+	UserAgent string
+}
+
+type BulkFailure struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type BulkResult struct {
+	Processed []string
+	Failed    []BulkFailure
+	// Aborted is set when an atomic batch was rejected and nothing was changed.
+	Aborted bool
+}
+
+type RevokeSessionsRequest struct {
+	ActorID string
+	UserID  string
+	// RevokeAccessTokens also rejects access tokens issued before now, instead
+	// of letting them run until expiry
+	RevokeAccessTokens bool
+	IPAddress          string
+	UserAgent          string
+}
+
+type RevokeSessionsResult struct {
+	RevokedSessions     int
+	AccessTokensRevoked bool
+}
+
+type userUseCase struct {
+	userRepo      repository.UserRepository
+	auditRepo     repository.AuditLogRepository
+	sessions      *auth.SessionStore
+	verifications *auth.VerificationStore
+	emailThrottle *auth.EmailThrottle
+	passwords     *auth.PasswordService
+	links         *auth.RedirectValidator
+	mailer        mail.Mailer
+	publisher     queue.Publisher
+	cache         cache.Cache
+	keyBuilder    *cache.CacheKeyBuilder
+	emailChange   config.EmailChangeConfig
+	passwordReset config.PasswordResetConfig
+	clock         clock.Clock
+	validate      *validator.Validate
+}
+
+func NewUserUseCase(
+	repo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	sessions *auth.SessionStore,
+	verifications *auth.VerificationStore,
+	emailThrottle *auth.EmailThrottle,
+	passwords *auth.PasswordService,
+	links *auth.RedirectValidator,
+	mailer mail.Mailer,
+	publisher queue.Publisher,
+	c cache.Cache,
+	kb *cache.CacheKeyBuilder,
+	emailChange config.EmailChangeConfig,
+	passwordReset config.PasswordResetConfig,
+	clk clock.Clock,
+) UserUseCase {
+	return &userUseCase{
+		userRepo:      repo,
+		auditRepo:     auditRepo,
+		sessions:      sessions,
+		verifications: verifications,
+		emailThrottle: emailThrottle,
+		passwords:     passwords,
+		links:         links,
+		mailer:        mailer,
+		publisher:     publisher,
+		cache:         c,
+		keyBuilder:    kb,
+		emailChange:   emailChange,
+		passwordReset: passwordReset,
+		clock:         clk,
+		validate:      validator.New(),
+	}
+}
+
+func (uc *userUseCase) Bulk(ctx context.Context, req BulkRequest) (*BulkResult, error) {
+	if req.Action != BulkActionDelete && req.Action != BulkActionDeactivate {
+		return nil, fmt.Errorf("unsupported bulk action: %s", req.Action)
+	}
+	if len(req.IDs) == 0 {
+		return nil, fmt.Errorf("at least one id is required")
+	}
+	if len(req.IDs) > MaxBulkSize {
+		return nil, fmt.Errorf("batch size %d exceeds the maximum of %d", len(req.IDs), MaxBulkSize)
+	}
+
+	result := &BulkResult{
+		Processed: []string{},
+		Failed:    []BulkFailure{},
+	}
+
+	seen := make(map[string]bool, len(req.IDs))
+	ids := make([]string, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		switch {
+		case uc.validate.Var(id, "uuid") != nil:
+			result.Failed = append(result.Failed, BulkFailure{ID: id, Reason: "invalid id"})
+		case id == req.ActorID:
+			result.Failed = append(result.Failed, BulkFailure{ID: id, Reason: "cannot modify own account"})
+		default:
+			ids = append(ids, id)
+		}
+	}
+
+	if req.Atomic && len(result.Failed) > 0 {
+		result.Aborted = true
+		return result, nil
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var users []*domain.User
+	var err error
+	if req.Action == BulkActionDelete {
+		users, err = uc.userRepo.BulkDelete(ctx, ids, req.Atomic)
+	} else {
+		users, err = uc.userRepo.BulkDeactivate(ctx, ids, req.Atomic)
+	}
+	var rowErrs repository.BatchRowErrors
+	if errors.As(err, &rowErrs) && len(users) == 0 {
+		// Nothing changed, e.g. the database is down; report that as such
+		return nil, err
+	}
+	if err != nil && rowErrs == nil && !errors.Is(err, repository.ErrBatchIncomplete) {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(users))
+	for _, u := range users {
+		found[u.ID] = true
+	}
+	for _, id := range ids {
+		if rowErr, ok := rowErrs[id]; ok {
+			log.Printf("Bulk %s of user %s failed: %v", req.Action, id, rowErr)
+			result.Failed = append(result.Failed, BulkFailure{ID: id, Reason: "could not be changed"})
+			continue
+		}
+		if !found[id] {
+			result.Failed = append(result.Failed, BulkFailure{ID: id, Reason: "user not found"})
+		}
+	}
+
+	if errors.Is(err, repository.ErrBatchIncomplete) {
+		result.Aborted = true
+		return result, nil
+	}
+
+	for _, u := range users {
+		result.Processed = append(result.Processed, u.ID)
+		uc.invalidateUser(ctx, u)
+		uc.endSessions(ctx, u.ID)
+		if req.Action == BulkActionDelete {
+			uc.publish(ctx, domain.EventUserDeleted, domain.UserDeletedEvent{UserID: u.ID, ActorID: req.ActorID})
+		} else {
+			uc.publish(ctx, domain.EventUserDeactivated, domain.UserDeactivatedEvent{UserID: u.ID, ActorID: req.ActorID})
+		}
+	}
+
+	uc.audit(ctx, req, result)
+
+	return result, nil
+}
+
+func (uc *userUseCase) RevokeSessions(ctx context.Context, req RevokeSessionsRequest) (*RevokeSessionsResult, error) {
+	if _, err := uc.userRepo.FindByID(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+
+	revoked, err := uc.sessions.RevokeAll(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	result := &RevokeSessionsResult{RevokedSessions: revoked}
+	if req.RevokeAccessTokens {
+		if err := uc.sessions.RevokeAccessTokens(ctx, req.UserID); err != nil {
+			return nil, fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+		result.AccessTokensRevoked = true
+	}
+
+	uc.writeAudit(ctx, "user.revoke_sessions", &req.UserID, req.ActorID, req.IPAddress, req.UserAgent, map[string]any{
+		"revoked_sessions":      result.RevokedSessions,
+		"access_tokens_revoked": result.AccessTokensRevoked,
+	})
+
+	return result, nil
+}
+
+// DeleteAccount soft-deletes the user's own account.
+func (uc *userUseCase) DeleteAccount(ctx context.Context, req DeleteAccountRequest) error {
+	user, err := uc.userRepo.FindByID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.userRepo.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+	uc.invalidateUser(ctx, user)
+	uc.endSessions(ctx, user.ID)
+
+	uc.writeAudit(ctx, "user.deleted", &user.ID, user.ID, req.IPAddress, req.UserAgent, map[string]any{
+		"email": user.Email,
+	})
+	uc.publish(ctx, domain.EventUserDeleted, domain.UserDeletedEvent{UserID: user.ID, ActorID: user.ID})
+
+	return nil
+}
+
+func (uc *userUseCase) PurgeDeleted(ctx context.Context, retention time.Duration) error {
+	purged, err := uc.userRepo.PurgeDeleted(ctx, uc.clock.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.Printf("Purged %d users deleted more than %v ago", purged, retention)
+	}
+	return nil
+}
+
+// publish sends a lifecycle event. The change it describes is already
+// committed, so a broker outage is logged rather than failing the request.
+func (uc *userUseCase) publish(ctx context.Context, eventType string, event any) {
+	if err := uc.publisher.Publish(ctx, eventType, event); err != nil {
+		log.Printf("Failed to publish %s: %v", eventType, err)
+	}
+}
+
+// invalidateUser drops cached copies of a user.
+func (uc *userUseCase) invalidateUser(ctx context.Context, u *domain.User) {
+	keys := []string{uc.keyBuilder.For(ctx).UserByID(u.ID), uc.keyBuilder.For(ctx).UserByEmail(u.Email)}
+	if err := uc.cache.Delete(ctx, keys...); err != nil {
+		log.Printf("Failed to invalidate cache for user %s: %v", u.ID, err)
+	}
+}
+
+// endSessions revokes the refresh tokens and outstanding access tokens of
+// a user who was deleted or deactivated, so nothing issued before keeps
+// working, not even a token read from a stale cache. The change itself is
+// committed, so failures are logged.
+func (uc *userUseCase) endSessions(ctx context.Context, userID string) {
+	if _, err := uc.sessions.RevokeAll(ctx, userID); err != nil {
+		log.Printf("Failed to revoke sessions of user %s: %v", userID, err)
+	}
+	if err := uc.sessions.RevokeAccessTokens(ctx, userID); err != nil {
+		log.Printf("Failed to revoke access tokens of user %s: %v", userID, err)
+	}
+}
+
+func (uc *userUseCase) audit(ctx context.Context, req BulkRequest, result *BulkResult) {
+	uc.writeAudit(ctx, "user.bulk_"+req.Action, nil, req.ActorID, req.IPAddress, req.UserAgent, map[string]any{
+		"atomic":    req.Atomic,
+		"requested": req.IDs,
+		"processed": result.Processed,
+		"failed":    result.Failed,
+	})
+}
+
+func (uc *userUseCase) writeAudit(ctx context.Context, action string, entityID *string, actorID, ip, userAgent string, details map[string]any) {
+	changes, err := json.Marshal(details)
+	if err != nil {
+		log.Printf("Failed to encode audit changes: %v", err)
+		return
+	}
+
+	entry := &domain.AuditLog{
+		Action:     action,
+		EntityType: "user",
+		EntityID:   entityID,
+		Changes:    changes,
+	}
+	if actorID != "" {
+		entry.UserID = &actorID
+	}
+	if ip != "" {
+		entry.IPAddress = &ip
+	}
+	if userAgent != "" {
+		entry.UserAgent = &userAgent
+	}
+
+	if err := uc.auditRepo.Create(ctx, entry); err != nil {
+		log.Printf("Failed to write audit log for %s: %v", entry.Action, err)
+	}
+}
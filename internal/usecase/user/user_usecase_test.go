@@ -0,0 +1,375 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/cache"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+	"github.com/Elysian-Rebirth/backend-go/internal/repository/memory"
+	"github.com/Elysian-Rebirth/backend-go/internal/usecase/auth"
+	"github.com/go-playground/validator/v10"
+)
+
+type testUsers struct {
+	uc       *userUseCase
+	store    *memory.Store
+	sessions *auth.SessionStore
+	clock    *clock.Mock
+}
+
+// newTestUsers builds the user use case on in-memory stores.
+func newTestUsers(t *testing.T) *testUsers {
+	t.Helper()
+
+	clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	c := cache.NewMemoryCache()
+	t.Cleanup(func() { c.Close() })
+	kb := cache.NewCacheKeyBuilder("test")
+	store := memory.NewStore()
+
+	sessions := auth.NewSessionStore(c, kb, config.JWTConfig{
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+	}, clk)
+
+	return &testUsers{
+		uc: &userUseCase{
+			userRepo:      memory.NewUserRepository(store),
+			auditRepo:     memory.NewAuditLogRepository(store),
+			sessions:      sessions,
+			verifications: auth.NewVerificationStore(c, kb),
+			emailThrottle: auth.NewEmailThrottle(c, kb, config.EmailThrottleConfig{}, clk),
+			passwords:     auth.NewPasswordService(),
+			publisher:     queue.NoopPublisher{},
+			cache:         c,
+			keyBuilder:    kb,
+			clock:         clk,
+			validate:      validator.New(),
+		},
+		store:    store,
+		sessions: sessions,
+		clock:    clk,
+	}
+}
+
+// addUser creates an active user with one session, whose refresh token is
+// returned.
+func (tu *testUsers) addUser(t *testing.T, email string) (*domain.User, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &domain.User{Email: email, Name: "User", IsActive: true}
+	if err := tu.uc.userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	token := "refresh-" + user.ID
+	if _, err := tu.sessions.Add(ctx, user.ID, token); err != nil {
+		t.Fatal(err)
+	}
+	return user, token
+}
+
+// signedOut reports whether the user's refresh token and access tokens
+// issued until now stopped working.
+func (tu *testUsers) signedOut(t *testing.T, userID, refreshToken string) bool {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := tu.sessions.Get(ctx, refreshToken)
+	if err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+		t.Fatal(err)
+	}
+	sessionGone := errors.Is(err, cache.ErrKeyNotFound)
+
+	// Without an iat the token counts as issued before any revocation
+	claims := &auth.Claims{UserID: userID}
+	revoked, err := tu.sessions.IsAccessTokenRevoked(ctx, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sessionGone && revoked
+}
+
+func TestBulkEndsSessions(t *testing.T) {
+	tests := []struct {
+		name          string
+		action        string
+		atomic        bool
+		unknown       bool
+		wantProcessed bool
+	}{
+		{"deactivate", BulkActionDeactivate, false, false, true},
+		{"delete", BulkActionDelete, false, false, true},
+		{"deactivate with unknown id", BulkActionDeactivate, false, true, true},
+		{"atomic with unknown id", BulkActionDelete, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := newTestUsers(t)
+			target, targetToken := tu.addUser(t, "target@example.com")
+			bystander, bystanderToken := tu.addUser(t, "bystander@example.com")
+
+			ids := []string{target.ID}
+			if tt.unknown {
+				ids = append(ids, "00000000-0000-4000-8000-000000000000")
+			}
+			result, err := tu.uc.Bulk(context.Background(), BulkRequest{
+				ActorID: "actor",
+				Action:  tt.action,
+				IDs:     ids,
+				Atomic:  tt.atomic,
+			})
+			if err != nil {
+				t.Fatalf("Bulk() = %v", err)
+			}
+
+			if got := slices.Contains(result.Processed, target.ID); got != tt.wantProcessed {
+				t.Fatalf("target processed = %v, want %v", got, tt.wantProcessed)
+			}
+			if got := tu.signedOut(t, target.ID, targetToken); got != tt.wantProcessed {
+				t.Errorf("target signed out = %v, want %v", got, tt.wantProcessed)
+			}
+			if tu.signedOut(t, bystander.ID, bystanderToken) {
+				t.Error("a user outside the batch was signed out")
+			}
+		})
+	}
+}
+
+func TestDeleteAccountEndsSessions(t *testing.T) {
+	tu := newTestUsers(t)
+	user, token := tu.addUser(t, "leaving@example.com")
+
+	if err := tu.uc.DeleteAccount(context.Background(), DeleteAccountRequest{UserID: user.ID}); err != nil {
+		t.Fatalf("DeleteAccount() = %v", err)
+	}
+	if !tu.signedOut(t, user.ID, token) {
+		t.Error("deleted account is still signed in")
+	}
+}
+
+// newTestLogin returns the auth use case on tu's stores, with a user who can
+// log in with email and the password "correct horse battery".
+func (tu *testUsers) newTestLogin(t *testing.T, email string) (auth.AuthUseCase, *auth.JWTService, *domain.User) {
+	t.Helper()
+
+	passwords := auth.NewPasswordService()
+	hash, err := passwords.HashPassword("correct horse battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &domain.User{Email: email, Name: "User", PasswordHash: hash, IsActive: true}
+	if err := tu.uc.userRepo.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	jwtSvc, err := auth.NewJWTService(config.JWTConfig{
+		Secret:             "test-secret-that-is-long-enough-to-sign",
+		AccessTokenExpiry:  15 * time.Minute,
+		RefreshTokenExpiry: 24 * time.Hour,
+	}, tu.clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := auth.NewAuthUseCase(tu.uc.userRepo, passwords, jwtSvc, tu.uc.cache, tu.uc.keyBuilder, tu.sessions, nil, nil, nil, tu.clock)
+	return uc, jwtSvc, user
+}
+
+func TestRevokeSessions(t *testing.T) {
+	tests := []struct {
+		name               string
+		logins             int
+		revokeAccessTokens bool
+	}{
+		{"refresh tokens only", 2, false},
+		{"with access tokens", 2, true},
+		{"no sessions", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tu := newTestUsers(t)
+			authUC, jwtSvc, target := tu.newTestLogin(t, "target@example.com")
+			_, bystanderToken := tu.addUser(t, "bystander@example.com")
+
+			var logins []*auth.AuthResponse
+			for range tt.logins {
+				res, err := authUC.Login(ctx, auth.LoginRequest{Email: target.Email, Password: "correct horse battery"})
+				if err != nil {
+					t.Fatalf("Login() = %v", err)
+				}
+				logins = append(logins, res)
+			}
+
+			result, err := tu.uc.RevokeSessions(ctx, RevokeSessionsRequest{
+				ActorID:            "admin",
+				UserID:             target.ID,
+				RevokeAccessTokens: tt.revokeAccessTokens,
+			})
+			if err != nil {
+				t.Fatalf("RevokeSessions() = %v", err)
+			}
+			if result.RevokedSessions != tt.logins || result.AccessTokensRevoked != tt.revokeAccessTokens {
+				t.Errorf("RevokeSessions() = %+v, want %d sessions, access tokens %v", result, tt.logins, tt.revokeAccessTokens)
+			}
+
+			for i, res := range logins {
+				if _, err := authUC.RefreshToken(ctx, res.RefreshToken); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+					t.Errorf("RefreshToken() of login %d = %v, want ErrInvalidRefreshToken", i+1, err)
+				}
+				claims, err := jwtSvc.ValidateToken(res.AccessToken)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if revoked, err := tu.sessions.IsAccessTokenRevoked(ctx, claims); err != nil || revoked != tt.revokeAccessTokens {
+					t.Errorf("access token of login %d revoked = %v, %v, want %v", i+1, revoked, err, tt.revokeAccessTokens)
+				}
+			}
+			if _, err := tu.sessions.Get(ctx, bystanderToken); err != nil {
+				t.Errorf("bystander's session = %v, want it kept", err)
+			}
+
+			// Signing in again afterwards works as usual
+			tu.clock.Advance(time.Second)
+			res, err := authUC.Login(ctx, auth.LoginRequest{Email: target.Email, Password: "correct horse battery"})
+			if err != nil {
+				t.Fatalf("Login() after revocation = %v", err)
+			}
+			claims, err := jwtSvc.ValidateToken(res.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if revoked, err := tu.sessions.IsAccessTokenRevoked(ctx, claims); err != nil || revoked {
+				t.Errorf("new access token revoked = %v, %v, want false", revoked, err)
+			}
+			if _, err := authUC.RefreshToken(ctx, res.RefreshToken); err != nil {
+				t.Errorf("RefreshToken() after signing in again = %v", err)
+			}
+
+			logs := tu.store.AuditLogs()
+			if len(logs) == 0 || logs[len(logs)-1].Action != "user.revoke_sessions" {
+				t.Errorf("audit log = %+v, want a user.revoke_sessions entry", logs)
+			}
+		})
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		tu := newTestUsers(t)
+		_, err := tu.uc.RevokeSessions(context.Background(), RevokeSessionsRequest{
+			ActorID: "admin",
+			UserID:  "00000000-0000-4000-8000-000000000000",
+		})
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			t.Fatalf("RevokeSessions() = %v, want ErrUserNotFound", err)
+		}
+	})
+}
+
+// rowFailingRepo fails bulk changes of the IDs in failing, as the Postgres
+// repository reports rows whose transaction failed.
+type rowFailingRepo struct {
+	repository.UserRepository
+	failing map[string]error
+}
+
+func (r *rowFailingRepo) BulkDeactivate(ctx context.Context, ids []string, atomic bool) ([]*domain.User, error) {
+	var rest []string
+	failed := repository.BatchRowErrors{}
+	for _, id := range ids {
+		if err, ok := r.failing[id]; ok {
+			failed[id] = err
+		} else {
+			rest = append(rest, id)
+		}
+	}
+
+	var users []*domain.User
+	if len(rest) > 0 {
+		var err error
+		if users, err = r.UserRepository.BulkDeactivate(ctx, rest, atomic); err != nil {
+			return nil, err
+		}
+	}
+	if len(failed) > 0 {
+		return users, failed
+	}
+	return users, nil
+}
+
+func TestBulkReportsFailedRows(t *testing.T) {
+	tu := newTestUsers(t)
+	ok, _ := tu.addUser(t, "ok@example.com")
+	broken, _ := tu.addUser(t, "broken@example.com")
+	unknown := "00000000-0000-4000-8000-000000000000"
+
+	tests := []struct {
+		name    string
+		failing map[string]error
+		wantErr error
+		want    map[string]string
+	}{
+		{
+			name:    "one row fails",
+			failing: map[string]error{broken.ID: errors.New("deadlock detected")},
+			want: map[string]string{
+				ok.ID:     "",
+				broken.ID: "could not be changed",
+				unknown:   "user not found",
+			},
+		},
+		{
+			name: "every row fails",
+			failing: map[string]error{
+				ok.ID:     domain.ErrUnavailable,
+				broken.ID: domain.ErrUnavailable,
+				unknown:   domain.ErrUnavailable,
+			},
+			wantErr: domain.ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := *tu.uc
+			uc.userRepo = &rowFailingRepo{UserRepository: tu.uc.userRepo, failing: tt.failing}
+
+			result, err := uc.Bulk(context.Background(), BulkRequest{
+				ActorID: "actor",
+				Action:  BulkActionDeactivate,
+				IDs:     []string{ok.ID, broken.ID, unknown},
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Bulk() = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Bulk() = %v", err)
+			}
+
+			got := map[string]string{}
+			for _, id := range result.Processed {
+				got[id] = ""
+			}
+			for _, failure := range result.Failed {
+				got[failure.ID] = failure.Reason
+			}
+			for id, reason := range tt.want {
+				if r, ok := got[id]; !ok || r != reason {
+					t.Errorf("%s: got %q (reported %v), want %q", id, r, ok, reason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+// JobType is the message type single deliveries are queued under.
+const JobType = "webhook.deliver"
+
+// Headers sent with every delivery. The signature is
+// "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">" keyed
+// with the webhook's secret; receivers should reject stale timestamps.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	defaultDeliveryTimeout = 10 * time.Second
+
+	// maxErrorLength bounds the response excerpt stored with a failed attempt
+	maxErrorLength = 500
+)
+
+type deliveryJob struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+// Envelope is the JSON body POSTed to webhook targets. RequestID is the
+// ID of the API request that raised the event, when there was one; being
+// part of the body it is covered by the signature.
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	RequestID string          `json:"request_id,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func (uc *webhookUseCase) Dispatch(ctx context.Context, msg *queue.Message) error {
+	webhooks, err := uc.webhookRepo.ListActiveForEvent(ctx, msg.Type)
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		delivery := &domain.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   msg.ID,
+			EventType: msg.Type,
+			Payload:   []byte(msg.Payload),
+			Status:    domain.DeliveryPending,
+		}
+		if err := uc.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+
+		// A redelivered event finds its deliveries already recorded; pending
+		// ones are queued again and Deliver skips any that already went out
+		if delivery.Status != domain.DeliveryPending {
+			continue
+		}
+		if err := uc.enqueue(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (uc *webhookUseCase) enqueue(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if err := uc.publisher.Publish(ctx, JobType, deliveryJob{DeliveryID: delivery.ID}); err != nil {
+		return fmt.Errorf("failed to queue webhook delivery %s: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// Deliver makes one attempt at a delivery. Returning an error lets the
+// consumer retry it with backoff.
+func (uc *webhookUseCase) Deliver(ctx context.Context, msg *queue.Message) error {
+	delivery, webhook, err := uc.loadJob(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == domain.DeliverySucceeded {
+		return nil
+	}
+	if !webhook.IsActive {
+		uc.finish(ctx, delivery, domain.DeliveryFailed, "webhook is inactive")
+		return nil
+	}
+
+	// The consumer restores the request ID the job was queued under, which
+	// Dispatch carried over from the event
+	envelope := Envelope{
+		ID:        delivery.EventID,
+		Type:      delivery.EventType,
+		CreatedAt: delivery.CreatedAt.UTC(),
+		Data:      json.RawMessage(delivery.Payload),
+	}
+	if id, ok := reqctx.RequestID(ctx); ok {
+		envelope.RequestID = id
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("%w: failed to encode webhook payload: %v", queue.ErrPermanent, err)
+	}
+
+	now := uc.clock.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	status, sendErr := uc.send(ctx, webhook, delivery, body, now)
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	if sendErr == nil {
+		delivery.Error = nil
+		delivery.Status = domain.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		if err := uc.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+			log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+		}
+		if err := uc.webhookRepo.ResetFailures(ctx, webhook.ID); err != nil {
+			log.Printf("Failed to reset failures of webhook %s: %v", webhook.ID, err)
+		}
+		return nil
+	}
+
+	message := sendErr.Error()
+	delivery.Error = &message
+	if err := uc.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+	}
+	return sendErr
+}
+
+// send POSTs body to the webhook and returns the response status, if any.
+func (uc *webhookUseCase) send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery, body []byte, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "umkmai-webhooks/1")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, "t="+timestamp+",v1="+sign(webhook.Secret, timestamp, body))
+
+	resp, err := uc.httpClient.Do(req)
+	if errors.Is(err, ErrForbiddenTarget) {
+		// The target's address won't become public by trying again
+		return 0, fmt.Errorf("%w: %v", queue.ErrPermanent, err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, excerpt)
+	}
+	return resp.StatusCode, nil
+}
+
+// DeliveryFailed marks a delivery that ran out of retries as failed and
+// counts it against the webhook, which is disabled after
+// webhooks.disable_after failures in a row.
+func (uc *webhookUseCase) DeliveryFailed(ctx context.Context, msg *queue.Message, cause error) {
+	delivery, _, err := uc.loadJob(ctx, msg)
+	if err != nil {
+		log.Printf("Failed to load dead-lettered webhook delivery: %v", err)
+		return
+	}
+	if delivery.Status == domain.DeliverySucceeded {
+		return
+	}
+
+	uc.finish(ctx, delivery, domain.DeliveryFailed, cause.Error())
+
+	webhook, err := uc.webhookRepo.RecordFailure(ctx, delivery.WebhookID, uc.cfg.DisableAfter)
+	if err != nil {
+		log.Printf("Failed to record failure of webhook %s: %v", delivery.WebhookID, err)
+		return
+	}
+	if !webhook.IsActive && webhook.DisabledAt != nil && webhook.ConsecutiveFailures == uc.cfg.DisableAfter {
+		log.Printf("Webhook %s disabled after %d failed deliveries", webhook.ID, webhook.ConsecutiveFailures)
+	}
+}
+
+func (uc *webhookUseCase) loadJob(ctx context.Context, msg *queue.Message) (*domain.WebhookDelivery, *domain.Webhook, error) {
+	var job deliveryJob
+	if err := json.Unmarshal(msg.Payload, &job); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", queue.ErrPermanent, err)
+	}
+
+	delivery, err := uc.webhookRepo.FindDelivery(ctx, job.DeliveryID)
+	if errors.Is(err, repository.ErrDeliveryNotFound) {
+		return nil, nil, fmt.Errorf("%w: %v", queue.ErrPermanent, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	webhook, err := uc.webhookRepo.FindByID(ctx, delivery.WebhookID)
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		return nil, nil, fmt.Errorf("%w: %v", queue.ErrPermanent, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return delivery, webhook, nil
+}
+
+func (uc *webhookUseCase) finish(ctx context.Context, delivery *domain.WebhookDelivery, status, reason string) {
+	delivery.Status = status
+	if reason != "" {
+		delivery.Error = &reason
+	}
+	if err := uc.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+	"github.com/Elysian-Rebirth/backend-go/internal/reqctx"
+)
+
+// deliveryRepo holds one webhook and its pending delivery.
+type deliveryRepo struct {
+	repository.WebhookRepository
+	webhook  *domain.Webhook
+	delivery *domain.WebhookDelivery
+}
+
+func (r *deliveryRepo) FindByID(context.Context, string) (*domain.Webhook, error) {
+	return r.webhook, nil
+}
+
+func (r *deliveryRepo) FindDelivery(context.Context, string) (*domain.WebhookDelivery, error) {
+	return r.delivery, nil
+}
+
+func (r *deliveryRepo) UpdateDelivery(context.Context, *domain.WebhookDelivery) error { return nil }
+
+func (r *deliveryRepo) ResetFailures(context.Context, string) error { return nil }
+
+func TestDeliverRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requestID string
+	}{
+		{"request ID from context", reqctx.WithRequestID(context.Background(), "req-42"), "req-42"},
+		{"no request ID", context.Background(), ""},
+		{"empty request ID", reqctx.WithRequestID(context.Background(), ""), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var signature string
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				signature = r.Header.Get(SignatureHeader)
+			}))
+			defer target.Close()
+
+			clk := clock.NewMock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+			repo := &deliveryRepo{
+				webhook: &domain.Webhook{ID: "wh-1", URL: target.URL, Secret: "whsec", IsActive: true},
+				delivery: &domain.WebhookDelivery{
+					ID: "dl-1", WebhookID: "wh-1", EventID: "ev-1", EventType: "user.registered",
+					Payload: []byte(`{"user_id":"u-1"}`), Status: domain.DeliveryPending, CreatedAt: clk.Now(),
+				},
+			}
+			uc := NewWebhookUseCase(repo, queue.NoopPublisher{}, config.WebhookConfig{AllowPrivateTargets: true}, clk)
+
+			msg, err := queue.NewMessage(context.Background(), JobType, deliveryJob{DeliveryID: "dl-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := uc.Deliver(tt.ctx, msg); err != nil {
+				t.Fatal(err)
+			}
+
+			var envelope map[string]json.RawMessage
+			if err := json.Unmarshal(body, &envelope); err != nil {
+				t.Fatalf("body %s is not JSON: %v", body, err)
+			}
+			raw, ok := envelope["request_id"]
+			if tt.requestID == "" {
+				if ok {
+					t.Errorf("envelope carries request_id %s, want none", raw)
+				}
+			} else if string(raw) != `"`+tt.requestID+`"` {
+				t.Errorf("request_id = %s, want %q", raw, tt.requestID)
+			}
+			if !bytes.Equal(envelope["data"], []byte(`{"user_id":"u-1"}`)) {
+				t.Errorf("data = %s", envelope["data"])
+			}
+
+			// The request ID is in the signed body
+			timestamp := strings.TrimPrefix(strings.Split(signature, ",")[0], "t=")
+			if want := "t=" + timestamp + ",v1=" + sign("whsec", timestamp, body); signature != want {
+				t.Errorf("signature = %s, want %s", signature, want)
+			}
+		})
+	}
+}
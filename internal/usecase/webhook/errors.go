@@ -0,0 +1,23 @@
+package webhook
+
+import "errors"
+
+var (
+	// ErrInvalidURL means the target URL is not an absolute http(s) URL,
+	// uses http while insecure URLs are not allowed, or points at a
+	// loopback, private or link-local address
+	ErrInvalidURL = errors.New("invalid webhook url")
+
+	// ErrForbiddenTarget means a delivery was about to connect to a
+	// non-public address
+	ErrForbiddenTarget = errors.New("webhook target address not allowed")
+
+	// ErrInvalidEvents means no events, or an unknown event type, were given
+	ErrInvalidEvents = errors.New("invalid webhook events")
+
+	// ErrLimitReached means the user already has webhooks.max_per_user webhooks
+	ErrLimitReached = errors.New("webhook limit reached")
+
+	// ErrWebhookInactive means the webhook is switched off
+	ErrWebhookInactive = errors.New("webhook is inactive")
+)
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// netip doesn't count as private but is just as internal.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublicAddr reports whether deliveries may go to addr. Loopback,
+// private (RFC 1918, fc00::/7), link-local (169.254.0.0/16 with the cloud
+// metadata endpoints, fe80::/10), unspecified and multicast addresses are
+// internal to the network the server runs in and are refused.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// checkTarget is a net.Dialer Control hook refusing connections to
+// non-public addresses. It sees the address after name resolution, so a
+// host name that resolves, or later re-resolves, to an internal address is
+// caught as well.
+func checkTarget(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrForbiddenTarget, err)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+	}
+	return nil
+}
+
+// newDeliveryClient returns the HTTP client deliveries are sent with. With
+// allowPrivate off it can only connect to public addresses.
+func newDeliveryClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = checkTarget
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// Through a proxy the dialer would only ever see the proxy's address
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// A redirect could point a delivery somewhere the owner never
+		// registered, so the first response is final
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Elysian-Rebirth/backend-go/internal/clock"
+	"github.com/Elysian-Rebirth/backend-go/internal/config"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain"
+	"github.com/Elysian-Rebirth/backend-go/internal/domain/repository"
+	"github.com/Elysian-Rebirth/backend-go/internal/infrastructure/queue"
+)
+
+// secretPrefix marks webhook signing secrets so they are recognisable if
+// they leak into logs or code.
+const secretPrefix = "whsec_"
+
+type WebhookUseCase interface {
+	// Create registers a webhook. The returned webhook's Secret is the only
+	// time the signing secret is revealed.
+	Create(ctx context.Context, req CreateRequest) (*domain.Webhook, error)
+	// List returns the user's webhooks; an empty ownerID lists every user's
+	List(ctx context.Context, ownerID string) ([]*domain.Webhook, error)
+	Get(ctx context.Context, id string) (*domain.Webhook, error)
+	// Update changes the fields set in req. Reactivating a webhook clears
+	// its failure count.
+	Update(ctx context.Context, id string, req UpdateRequest) (*domain.Webhook, error)
+	Delete(ctx context.Context, id string) error
+	// PruneDeliveries removes finished deliveries older than retention
+	PruneDeliveries(ctx context.Context, retention time.Duration) error
+
+	ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, int64, error)
+	// Redeliver queues a delivery of webhookID to be sent again
+	Redeliver(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error)
+
+	// Dispatch is the queue handler for lifecycle events: it records a
+	// delivery for every subscribed webhook and queues it
+	Dispatch(ctx context.Context, msg *queue.Message) error
+	// Deliver is the queue handler that sends a single delivery
+	Deliver(ctx context.Context, msg *queue.Message) error
+	// DeliveryFailed is the dead-letter handler for deliveries that ran out
+	// of retries
+	DeliveryFailed(ctx context.Context, msg *queue.Message, err error)
+}
+
+type CreateRequest struct {
+	OwnerID string
+	URL     string
+	Events  []string
+}
+
+type UpdateRequest struct {
+	URL      *string
+	Events   []string
+	IsActive *bool
+}
+
+type webhookUseCase struct {
+	webhookRepo repository.WebhookRepository
+	publisher   queue.Publisher
+	httpClient  *http.Client
+	cfg         config.WebhookConfig
+	clock       clock.Clock
+}
+
+func NewWebhookUseCase(repo repository.WebhookRepository, publisher queue.Publisher, cfg config.WebhookConfig, clk clock.Clock) WebhookUseCase {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultDeliveryTimeout
+	}
+
+	return &webhookUseCase{
+		webhookRepo: repo,
+		publisher:   publisher,
+		httpClient:  newDeliveryClient(cfg.Timeout, cfg.AllowPrivateTargets),
+		cfg:         cfg,
+		clock:       clk,
+	}
+}
+
+func (uc *webhookUseCase) Create(ctx context.Context, req CreateRequest) (*domain.Webhook, error) {
+	if err := uc.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.webhookRepo.List(ctx, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= uc.cfg.MaxPerUser {
+		return nil, ErrLimitReached
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &domain.Webhook{
+		UserID:   req.OwnerID,
+		URL:      req.URL,
+		Secret:   secret,
+		Events:   events,
+		IsActive: true,
+	}
+	if err := uc.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (uc *webhookUseCase) List(ctx context.Context, ownerID string) ([]*domain.Webhook, error) {
+	return uc.webhookRepo.List(ctx, ownerID)
+}
+
+func (uc *webhookUseCase) Get(ctx context.Context, id string) (*domain.Webhook, error) {
+	return uc.webhookRepo.FindByID(ctx, id)
+}
+
+func (uc *webhookUseCase) Update(ctx context.Context, id string, req UpdateRequest) (*domain.Webhook, error) {
+	webhook, err := uc.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := uc.validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		events, err := normalizeEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = events
+	}
+	if req.IsActive != nil {
+		if *req.IsActive && !webhook.IsActive {
+			webhook.ConsecutiveFailures = 0
+			webhook.DisabledAt = nil
+		}
+		webhook.IsActive = *req.IsActive
+	}
+
+	if err := uc.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (uc *webhookUseCase) Delete(ctx context.Context, id string) error {
+	return uc.webhookRepo.Delete(ctx, id)
+}
+
+func (uc *webhookUseCase) ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*domain.WebhookDelivery, int64, error) {
+	return uc.webhookRepo.ListDeliveries(ctx, webhookID, limit, offset)
+}
+
+func (uc *webhookUseCase) Redeliver(ctx context.Context, webhookID, deliveryID string) (*domain.WebhookDelivery, error) {
+	delivery, err := uc.webhookRepo.FindDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.WebhookID != webhookID {
+		return nil, repository.ErrDeliveryNotFound
+	}
+
+	webhook, err := uc.webhookRepo.FindByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.IsActive {
+		return nil, ErrWebhookInactive
+	}
+
+	delivery.Status = domain.DeliveryPending
+	if err := uc.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	if err := uc.enqueue(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+func (uc *webhookUseCase) PruneDeliveries(ctx context.Context, retention time.Duration) error {
+	pruned, err := uc.webhookRepo.PruneDeliveries(ctx, uc.clock.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		log.Printf("Pruned %d webhook deliveries older than %v", pruned, retention)
+	}
+	return nil
+}
+
+func (uc *webhookUseCase) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+
+	// Host names are checked when deliveries connect, as they may resolve
+	// differently by then; literal addresses can be refused right away
+	if !uc.cfg.AllowPrivateTargets {
+		host := u.Hostname()
+		if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+			return ErrInvalidURL
+		}
+		if addr, err := netip.ParseAddr(host); err == nil && !isPublicAddr(addr) {
+			return ErrInvalidURL
+		}
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if uc.cfg.AllowInsecureURLs {
+			return nil
+		}
+	}
+	return ErrInvalidURL
+}
+
+// normalizeEvents checks every event is known and drops duplicates.
+func normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, ErrInvalidEvents
+	}
+
+	normalized := make([]string, 0, len(events))
+	for _, event := range events {
+		if !slices.Contains(domain.WebhookEvents, event) {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidEvents, event)
+		}
+		if !slices.Contains(normalized, event) {
+			normalized = append(normalized, event)
+		}
+	}
+	return normalized, nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
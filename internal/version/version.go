@@ -0,0 +1,84 @@
+// Package version reports which build of the server is running. Its
+// variables are meant to be set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/Elysian-Rebirth/backend-go/internal/version.Commit=$(git rev-parse HEAD)"
+//
+// Builds without ldflags report "dev", or the VCS details Go embedded. The
+// package also keeps when the server started, for uptime reporting.
+package version
+
+import (
+	"expvar"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	Version   = "dev"
+	Commit    = "dev"
+	BuildTime = "dev"
+)
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build info, falling back to the VCS settings recorded by
+// the Go toolchain for anything not set with ldflags.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "dev":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildTime == "dev":
+				info.BuildTime = s.Value
+			}
+		}
+	}
+	return info
+}
+
+func (i Info) String() string {
+	return fmt.Sprintf("version %s (commit %s, built %s, %s)", i.Version, i.Commit, i.BuildTime, i.GoVersion)
+}
+
+// startedAt defaults to when the process started, until SetStartTime is
+// called
+var startedAt atomic.Int64
+
+// SetStartTime records when the server started serving.
+func SetStartTime(t time.Time) {
+	startedAt.Store(t.UnixNano())
+}
+
+// StartTime returns when the server started serving.
+func StartTime() time.Time {
+	return time.Unix(0, startedAt.Load())
+}
+
+// Uptime returns how long the server has been up.
+func Uptime() time.Duration {
+	return time.Since(StartTime())
+}
+
+func init() {
+	SetStartTime(time.Now())
+
+	// Published as build_info under /debug/vars when that endpoint is served
+	expvar.Publish("build_info", expvar.Func(func() any { return Get() }))
+}
@@ -0,0 +1,57 @@
+package version
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	old := [3]string{Version, Commit, BuildTime}
+	t.Cleanup(func() { Version, Commit, BuildTime = old[0], old[1], old[2] })
+
+	tests := []struct {
+		name                   string
+		version, commit, built string
+		want                   Info
+	}{
+		// Test binaries carry no VCS settings, so the defaults show through
+		{"without ldflags", "dev", "dev", "dev", Info{Version: "dev", Commit: "dev", BuildTime: "dev", GoVersion: runtime.Version()}},
+		{"injected", "v1.4.2", "9f2c1e7", "2026-10-17T08:00:00Z", Info{Version: "v1.4.2", Commit: "9f2c1e7", BuildTime: "2026-10-17T08:00:00Z", GoVersion: runtime.Version()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Version, Commit, BuildTime = tt.version, tt.commit, tt.built
+			if got := Get(); got != tt.want {
+				t.Errorf("Get() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	Version, Commit, BuildTime = "v1.4.2", "9f2c1e7", "2026-10-17T08:00:00Z"
+	if got := Get().String(); !strings.HasPrefix(got, "version v1.4.2 (commit 9f2c1e7, built 2026-10-17T08:00:00Z, go") {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestUptime(t *testing.T) {
+	old := StartTime()
+	t.Cleanup(func() { SetStartTime(old) })
+
+	started := time.Now().Add(-time.Hour)
+	SetStartTime(started)
+	if !StartTime().Equal(started) {
+		t.Errorf("StartTime() = %v, want %v", StartTime(), started)
+	}
+	if up := Uptime(); up < time.Hour || up > time.Hour+time.Minute {
+		t.Errorf("Uptime() = %v, want about an hour", up)
+	}
+
+	first := Uptime()
+	time.Sleep(time.Millisecond)
+	if second := Uptime(); second <= first {
+		t.Errorf("Uptime() went from %v to %v, want it to increase", first, second)
+	}
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN avatar_key VARCHAR(500);
+
+CREATE INDEX idx_users_avatar_key ON users(avatar_key) WHERE avatar_key IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_avatar_key;
+
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_key;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE generations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    input JSONB NOT NULL,
+    input_hash VARCHAR(64) NOT NULL,
+    parent_id UUID,
+    output TEXT,
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    prompt_tokens INTEGER DEFAULT 0 NOT NULL,
+    completion_tokens INTEGER DEFAULT 0 NOT NULL,
+    latency_ms BIGINT DEFAULT 0 NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    CONSTRAINT fk_generations_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_generations_parent FOREIGN KEY (parent_id)
+        REFERENCES generations(id) ON DELETE SET NULL
+);
+
+-- Indexes
+CREATE INDEX idx_generations_user_id ON generations(user_id);
+CREATE INDEX idx_generations_kind_created ON generations(kind, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS generations;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE businesses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    description TEXT,
+    address VARCHAR(500),
+    phone VARCHAR(30),
+    logo_url VARCHAR(2048),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+
+    CONSTRAINT fk_businesses_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Indexes
+CREATE INDEX idx_businesses_user_id ON businesses(user_id);
+CREATE INDEX idx_businesses_deleted_at ON businesses(deleted_at);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_businesses_updated_at
+    BEFORE UPDATE ON businesses
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_businesses_updated_at ON businesses;
+DROP TABLE IF EXISTS businesses;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Emails and role names stay unique across tenants
+ALTER TABLE users ADD COLUMN tenant_id VARCHAR(63);
+ALTER TABLE roles ADD COLUMN tenant_id VARCHAR(63);
+
+CREATE INDEX idx_users_tenant_id ON users(tenant_id);
+CREATE INDEX idx_roles_tenant_id ON roles(tenant_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_roles_tenant_id;
+DROP INDEX IF EXISTS idx_users_tenant_id;
+ALTER TABLE roles DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE usage_records (
+    user_id UUID NOT NULL,
+    period VARCHAR(7) NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    count BIGINT DEFAULT 0 NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (user_id, period, category),
+    CONSTRAINT fk_usage_records_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Indexes
+CREATE INDEX idx_usage_records_period ON usage_records(period);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS usage_records;
+-- +goose StatementEnd